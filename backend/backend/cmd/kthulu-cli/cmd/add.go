@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -168,9 +169,9 @@ func runAddModule(module string, integrations []string, compliance string, force
 		OutputPath:   currentDir,
 		Features:     plan.RequiredModules,
 		Enterprise:   compliance != "",
-		Database:     detectDatabase(currentDir),
-		Frontend:     detectFrontend(currentDir),
-		Auth:         detectAuth(currentDir),
+		Database:     generator.DetectDatabase(currentDir),
+		Frontend:     generator.DetectFrontend(currentDir),
+		Auth:         generator.DetectAuth(currentDir),
 		CustomValues: make(map[string]string),
 	}
 
@@ -185,9 +186,14 @@ func runAddModule(module string, integrations []string, compliance string, force
 		config.CustomValues["integration_"+integration] = "true"
 	}
 
-	// Generate only the specific module
-	if err := generateSpecificModule(config, module, templateGenerator); err != nil {
-		return fmt.Errorf("error generating module: %w", err)
+	// Wire the module into a generated project, or fall back to plain module files
+	if err := templateGenerator.AddModuleToProject(currentDir, module); err != nil {
+		if !errors.Is(err, generator.ErrNotGeneratedProject) {
+			return fmt.Errorf("error adding module: %w", err)
+		}
+		if err := generateSpecificModule(config, module, templateGenerator); err != nil {
+			return fmt.Errorf("error generating module: %w", err)
+		}
 	}
 
 	// Step 9: Update project configuration
@@ -224,53 +230,6 @@ func isKthuluProject(dir string) bool {
 	return true
 }
 
-func detectDatabase(dir string) string {
-	// Try to detect database type from go.mod
-	goModPath := filepath.Join(dir, "go.mod")
-	if content, err := os.ReadFile(goModPath); err == nil {
-		contentStr := string(content)
-		if strings.Contains(contentStr, "gorm.io/driver/postgres") {
-			return "postgres"
-		}
-		if strings.Contains(contentStr, "gorm.io/driver/mysql") {
-			return "mysql"
-		}
-		if strings.Contains(contentStr, "gorm.io/driver/sqlite") {
-			return "sqlite"
-		}
-	}
-	return "sqlite" // default
-}
-
-func detectFrontend(dir string) string {
-	// Check for frontend directories/files
-	if _, err := os.Stat(filepath.Join(dir, "frontend", "package.json")); err == nil {
-		return "react"
-	}
-	if _, err := os.Stat(filepath.Join(dir, "templates")); err == nil {
-		return "templ"
-	}
-	if _, err := os.Stat(filepath.Join(dir, "cmd", "desktop")); err == nil {
-		return "fyne"
-	}
-	return "none"
-}
-
-func detectAuth(dir string) string {
-	// Try to detect auth type from existing modules
-	authPath := filepath.Join(dir, "internal", "modules", "auth")
-	if _, err := os.Stat(authPath); err == nil {
-		return "jwt"
-	}
-
-	oauthPath := filepath.Join(dir, "internal", "modules", "oauthsso")
-	if _, err := os.Stat(oauthPath); err == nil {
-		return "oauth"
-	}
-
-	return "jwt" // default
-}
-
 func displayDependencyPlan(moduleName string, plan *resolver.ResolutionPlan) {
 	fmt.Printf("\n📊 Dependency Resolution Plan:\n")
 	fmt.Printf("   Primary module:    %s\n", moduleName)
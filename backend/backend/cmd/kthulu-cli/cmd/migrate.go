@@ -9,6 +9,8 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/pmaojo/kthulu-go/backend/core"
+	// Register the Go migrations applied alongside the SQL ones
+	_ "github.com/pmaojo/kthulu-go/backend/migrations"
 )
 
 // migrateCmd represents the migrate command group
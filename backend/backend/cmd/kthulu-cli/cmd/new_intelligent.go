@@ -130,6 +130,7 @@ var (
 	newOutputPath    string
 	newDryRun        bool
 	newInteractive   bool
+	newTemplateDir   string
 )
 
 const (
@@ -148,6 +149,7 @@ func init() {
 	newCmd.Flags().StringVarP(&newOutputPath, "output", "o", "", "Output directory (default: current directory)")
 	newCmd.Flags().BoolVar(&newDryRun, "dry-run", false, "Show what would be generated without creating files")
 	newCmd.Flags().BoolVar(&newInteractive, "interactive", false, "Interactive project configuration")
+	newCmd.Flags().StringVar(&newTemplateDir, "template-dir", "", "Directory with custom .tmpl files overriding built-in templates")
 
 	rootCmd.AddCommand(newCmd)
 }
@@ -198,7 +200,11 @@ func runNewProjectIntelligent(cmd *cobra.Command, args []string) {
 	displayGenerationPlan(structure)
 
 	// Step 7: Write files (unless dry-run)
-	if newDryRun {
+	if config.DryRun {
+		if err := templateGenerator.WriteProject(structure); err != nil {
+			fmt.Printf("❌ Error planning project: %v\n", err)
+			os.Exit(1)
+		}
 		fmt.Println("\n🔍 Dry run completed - no files were created")
 		return
 	}
@@ -306,6 +312,8 @@ func buildProjectConfig(projectName string) (*generator.GeneratorConfig, error)
 		Enterprise:    template.Enterprise,
 		Observability: false,
 		CustomValues:  make(map[string]string),
+		DryRun:        newDryRun,
+		TemplateDir:   newTemplateDir,
 	}
 
 	if newModulePath != "" {
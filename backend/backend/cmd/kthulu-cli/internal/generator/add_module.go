@@ -0,0 +1,215 @@
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// ErrNotGeneratedProject is returned when a project lacks the markers written
+// by GenerateProject and cannot be updated incrementally.
+var ErrNotGeneratedProject = errors.New("project was not generated by kthulu")
+
+var (
+	projectMarkerPattern  = regexp.MustCompile(`(?m)^// @kthulu:project:(.*)$`)
+	featuresMarkerPattern = regexp.MustCompile(`(?m)^// @kthulu:features:(.*)$`)
+	invokeParamsPattern   = regexp.MustCompile(`fx\.Invoke\(func\(lc fx\.Lifecycle,?([^)]*)\) \{`)
+	goModModulePattern    = regexp.MustCompile(`(?m)^module\s+(\S+)`)
+)
+
+const mainFilePath = "cmd/server/main.go"
+
+// AddModuleToProject generates a module inside an already generated project
+// and wires it, together with any missing dependencies, into main.go and go.mod.
+// The project is generated with the configuration it was created with, read
+// back from the project itself; the generator's own configuration is left
+// untouched.
+func (g *TemplateGenerator) AddModuleToProject(projectPath, moduleName string) error {
+	mainPath := filepath.Join(projectPath, mainFilePath)
+	mainContent, err := os.ReadFile(mainPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("%w: %s not found", ErrNotGeneratedProject, mainFilePath)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", mainPath, err)
+	}
+	main := string(mainContent)
+
+	marker := featuresMarkerPattern.FindStringSubmatch(main)
+	if marker == nil {
+		return fmt.Errorf("%w: missing @kthulu:features marker in %s", ErrNotGeneratedProject, mainFilePath)
+	}
+	features := splitFeatures(marker[1])
+	for _, feature := range features {
+		if feature == moduleName {
+			return fmt.Errorf("module '%s' is already part of the project", moduleName)
+		}
+	}
+
+	goModPath := filepath.Join(projectPath, "go.mod")
+	goModContent, err := os.ReadFile(goModPath)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", goModPath, err)
+	}
+
+	config := g.loadProjectConfig(projectPath, main, string(goModContent), append(features, moduleName))
+	// Generate with a copy of the generator bound to the project configuration
+	project := &TemplateGenerator{resolver: g.resolver, templates: g.templates, config: config}
+
+	plan, err := g.resolver.ResolveDependencies(config.Features)
+	if err != nil {
+		return fmt.Errorf("failed to resolve dependencies: %w", err)
+	}
+
+	// Only modules that are not wired into main.go yet need to be generated
+	var added []string
+	for _, module := range plan.InstallOrder {
+		if !strings.Contains(main, moduleProviderLine(module)) {
+			added = append(added, module)
+		}
+	}
+
+	structure := &ProjectStructure{RootPath: projectPath}
+	for _, module := range added {
+		start := len(structure.Files)
+		if err := project.generateModuleFiles(module, structure); err != nil {
+			return fmt.Errorf("failed to generate module '%s': %w", module, err)
+		}
+		if err := project.applyTemplates(structure.Files[start:], module); err != nil {
+			return err
+		}
+	}
+
+	main, err = project.wireModules(main, added, config.Features)
+	if err != nil {
+		return fmt.Errorf("failed to update %s: %w", mainFilePath, err)
+	}
+	structure.Files = append(structure.Files, GeneratedFile{Path: mainFilePath, Content: main, Overwrite: true})
+
+	goMod, changed, err := project.mergeGoModRequires(string(goModContent))
+	if err != nil {
+		return fmt.Errorf("failed to update go.mod: %w", err)
+	}
+	if changed {
+		structure.Files = append(structure.Files, GeneratedFile{Path: "go.mod", Content: goMod, Overwrite: true})
+	}
+
+	if err := validateGoFiles(structure); err != nil {
+		return fmt.Errorf("updated project is invalid: %w", err)
+	}
+
+	return project.WriteProject(structure)
+}
+
+// wireModules adds the imports, providers, fx.Invoke parameters and routes of
+// the given modules to a generated main.go and refreshes its features marker.
+func (g *TemplateGenerator) wireModules(main string, modules, features []string) (string, error) {
+	main = featuresMarkerPattern.ReplaceAllLiteralString(main, "// @kthulu:features:"+strings.Join(features, ","))
+	if len(modules) == 0 {
+		return main, nil
+	}
+
+	var imports, providers, routes, params []string
+	for _, module := range modules {
+		imports = append(imports, g.moduleImportLines(module)...)
+		providers = append(providers, moduleProviderLine(module))
+		routes = append(routes, moduleRouteLines(module)...)
+		params = append(params, moduleInvokeParam(module))
+	}
+
+	// Imports go at the end of the import block
+	importStart := strings.Index(main, "import (\n")
+	if importStart < 0 {
+		return "", errors.New("import block not found")
+	}
+	importEnd := strings.Index(main[importStart:], "\n)\n")
+	if importEnd < 0 {
+		return "", errors.New("unterminated import block")
+	}
+	importEnd += importStart + 1
+	main = main[:importEnd] + strings.Join(imports, "\n") + "\n" + main[importEnd:]
+
+	// Providers follow the last existing module provider inside fx.New
+	appStart := strings.Index(main, "fx.New(")
+	if appStart < 0 {
+		return "", errors.New("fx.New call not found")
+	}
+	insertAt := strings.Index(main[appStart:], "\n") + appStart + 1
+	if last := strings.LastIndex(main, ".Providers(),\n"); last > appStart {
+		insertAt = last + len(".Providers(),\n")
+	}
+	main = main[:insertAt] + strings.Join(providers, "\n") + "\n" + main[insertAt:]
+
+	// Module services are injected through the fx.Invoke parameters
+	loc := invokeParamsPattern.FindStringSubmatchIndex(main)
+	if loc == nil {
+		return "", errors.New("fx.Invoke lifecycle hook not found")
+	}
+	existing := strings.TrimSpace(main[loc[2]:loc[3]])
+	if existing != "" {
+		params = append([]string{existing}, params...)
+	}
+	invoke := fmt.Sprintf("fx.Invoke(func(lc fx.Lifecycle, %s) {", strings.Join(params, ", "))
+	main = main[:loc[0]] + invoke + main[loc[1]:]
+
+	// Routes are registered before the HTTP server is built
+	serverAt := strings.Index(main, "server := builder(router, grace)")
+	if serverAt < 0 {
+		return "", errors.New("server construction not found")
+	}
+	main = main[:serverAt] + strings.TrimLeft(strings.Join(routes, "\n"), "\t") + "\n\n" + main[serverAt:]
+
+	return main, nil
+}
+
+// mergeGoModRequires adds the require lines of the current configuration that
+// are missing from an existing go.mod. It reports whether go.mod changed.
+func (g *TemplateGenerator) mergeGoModRequires(goMod string) (string, bool, error) {
+	present := make(map[string]bool)
+	for _, line := range strings.Split(goMod, "\n") {
+		fields := strings.Fields(strings.TrimPrefix(strings.TrimSpace(line), "require "))
+		if len(fields) >= 2 {
+			present[fields[0]] = true
+		}
+	}
+
+	generated, err := g.generateGoMod()
+	if err != nil {
+		return "", false, err
+	}
+
+	var missing []string
+	for _, line := range strings.Split(generated, "\n") {
+		if !strings.HasPrefix(line, "\t") {
+			continue
+		}
+		if fields := strings.Fields(line); len(fields) == 2 && !present[fields[0]] {
+			missing = append(missing, line)
+		}
+	}
+	if len(missing) == 0 {
+		return goMod, false, nil
+	}
+
+	block := strings.Index(goMod, "require (\n")
+	if block < 0 {
+		return strings.TrimRight(goMod, "\n") + "\n\nrequire (\n" + strings.Join(missing, "\n") + "\n)\n", true, nil
+	}
+	end := strings.Index(goMod[block:], "\n)") + block + 1
+	return goMod[:end] + strings.Join(missing, "\n") + "\n" + goMod[end:], true, nil
+}
+
+// splitFeatures parses the comma-separated value of the features marker
+func splitFeatures(value string) []string {
+	var features []string
+	for _, feature := range strings.Split(value, ",") {
+		if feature = strings.TrimSpace(feature); feature != "" {
+			features = append(features, feature)
+		}
+	}
+	return features
+}
@@ -0,0 +1,159 @@
+package generator
+
+import (
+	"errors"
+	goparser "go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeFixtureProject generates and writes a project with the given features
+func writeFixtureProject(t *testing.T, features ...string) string {
+	t.Helper()
+	projectPath := t.TempDir()
+
+	g := newTestGenerator()
+	structure, err := g.GenerateProject(&GeneratorConfig{
+		ProjectName:  "demo",
+		OutputPath:   projectPath,
+		Frontend:     "none",
+		Database:     "sqlite",
+		Features:     features,
+		CustomValues: map[string]string{"module_path": "example.com/demo"},
+	})
+	if err != nil {
+		t.Fatalf("generate fixture: %v", err)
+	}
+	if err := g.WriteProject(structure); err != nil {
+		t.Fatalf("write fixture: %v", err)
+	}
+	return projectPath
+}
+
+func readProjectFile(t *testing.T, projectPath, name string) string {
+	t.Helper()
+	content, err := os.ReadFile(filepath.Join(projectPath, name))
+	if err != nil {
+		t.Fatalf("read %s: %v", name, err)
+	}
+	return string(content)
+}
+
+func TestAddModuleToProjectWiresModule(t *testing.T) {
+	projectPath := writeFixtureProject(t, "user")
+
+	g := newTestGenerator()
+	if err := g.AddModuleToProject(projectPath, "product"); err != nil {
+		t.Fatalf("add module: %v", err)
+	}
+	if g.config.OutputPath != "" || len(g.config.Features) != 0 {
+		t.Fatalf("adding a module should leave the generator configuration untouched, got %+v", g.config)
+	}
+
+	main := readProjectFile(t, projectPath, mainFilePath)
+	if _, err := goparser.ParseFile(token.NewFileSet(), mainFilePath, main, 0); err != nil {
+		t.Fatalf("updated main.go does not parse: %v\n%s", err, main)
+	}
+	for _, want := range []string{
+		"// @kthulu:features:user,product\n",
+		`productHandlers "example.com/demo/internal/adapters/http/modules/product/handlers"`,
+		"product.Providers(),",
+		"organization.Providers(),",
+		"productService productDomain.ProductService",
+		"productHandler.RegisterRoutes(apiRouter)",
+	} {
+		if !strings.Contains(main, want) {
+			t.Fatalf("main.go missing %q:\n%s", want, main)
+		}
+	}
+	if strings.Count(main, "user.Providers(),") != 1 {
+		t.Fatalf("existing module should not be wired twice:\n%s", main)
+	}
+
+	if _, err := os.Stat(filepath.Join(projectPath, "internal/adapters/http/modules/product/module.go")); err != nil {
+		t.Fatalf("expected product module files: %v", err)
+	}
+}
+
+func TestLoadProjectConfigReadsGeneratedProject(t *testing.T) {
+	projectPath := t.TempDir()
+	g := newTestGenerator()
+	structure, err := g.GenerateProject(&GeneratorConfig{
+		ProjectName:  "demo",
+		OutputPath:   projectPath,
+		Frontend:     "fyne",
+		Database:     "postgres",
+		Features:     []string{"user"},
+		Enterprise:   true,
+		CustomValues: map[string]string{"module_path": "example.com/demo"},
+	})
+	if err != nil {
+		t.Fatalf("generate fixture: %v", err)
+	}
+	if err := g.WriteProject(structure); err != nil {
+		t.Fatalf("write fixture: %v", err)
+	}
+
+	main := readProjectFile(t, projectPath, mainFilePath)
+	goMod := readProjectFile(t, projectPath, "go.mod")
+	config := newTestGenerator().loadProjectConfig(projectPath, main, goMod, []string{"user", "product"})
+	if config.ProjectName != "demo" || config.CustomValues["module_path"] != "example.com/demo" {
+		t.Fatalf("expected the project name and module path of the project, got %+v", config)
+	}
+	if config.Database != "postgres" || config.Frontend != "fyne" || !config.Enterprise {
+		t.Fatalf("expected the options the project was generated with, got %+v", config)
+	}
+}
+
+func TestAddModuleToProjectRejectsExistingModule(t *testing.T) {
+	projectPath := writeFixtureProject(t, "user")
+
+	err := newTestGenerator().AddModuleToProject(projectPath, "user")
+	if err == nil || !strings.Contains(err.Error(), "already part of the project") {
+		t.Fatalf("expected duplicate module error, got %v", err)
+	}
+}
+
+func TestAddModuleToProjectRequiresMarker(t *testing.T) {
+	projectPath := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(projectPath, "cmd/server"), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(projectPath, mainFilePath), []byte("package main\n"), 0o644); err != nil {
+		t.Fatalf("write main: %v", err)
+	}
+
+	err := newTestGenerator().AddModuleToProject(projectPath, "product")
+	if !errors.Is(err, ErrNotGeneratedProject) {
+		t.Fatalf("expected ErrNotGeneratedProject, got %v", err)
+	}
+}
+
+func TestAddModuleToProjectRequiresMain(t *testing.T) {
+	err := newTestGenerator().AddModuleToProject(t.TempDir(), "product")
+	if !errors.Is(err, ErrNotGeneratedProject) {
+		t.Fatalf("expected ErrNotGeneratedProject without main.go, got %v", err)
+	}
+}
+
+func TestMergeGoModRequiresAddsMissingDependencies(t *testing.T) {
+	g := newTestGenerator()
+	g.config = &GeneratorConfig{ProjectName: "demo", Frontend: "fyne"}
+
+	goMod, changed, err := g.mergeGoModRequires("module demo\n\ngo 1.21\n\nrequire (\n\tgo.uber.org/fx v1.21.0\n)\n")
+	if err != nil {
+		t.Fatalf("merge go.mod: %v", err)
+	}
+	if !changed {
+		t.Fatalf("expected go.mod to change")
+	}
+	if !strings.Contains(goMod, "\tgo.uber.org/fx v1.21.0\n") || strings.Contains(goMod, "go.uber.org/fx v1.20.0") {
+		t.Fatalf("existing requirement should be kept as is:\n%s", goMod)
+	}
+	if !strings.Contains(goMod, "\tfyne.io/fyne/v2 v2.4.3\n") {
+		t.Fatalf("expected fyne requirement to be added:\n%s", goMod)
+	}
+}
@@ -0,0 +1,90 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// TemplateData is the data passed to user-supplied templates
+type TemplateData struct {
+	ProjectName  string
+	ModulePath   string
+	Database     string
+	Frontend     string
+	Auth         string
+	Features     []string
+	Enterprise   bool
+	CustomValues map[string]string
+	Module       string // module being generated, empty for project-level files
+	Path         string // path of the generated file
+	Builtin      string // content the built-in generator would produce
+}
+
+// LoadTemplates loads every .tmpl file under dir, keyed by file name
+// (e.g. "main.go.tmpl"), so it overrides the built-in generator for files
+// with a matching GeneratedFile.Template.
+func (g *TemplateGenerator) LoadTemplates(dir string) error {
+	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || filepath.Ext(path) != ".tmpl" {
+			return nil
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read template %s: %w", path, err)
+		}
+
+		name := d.Name()
+		tmpl, err := template.New(name).Funcs(template.FuncMap{
+			"capitalize": Capitalize,
+			"pluralize":  Pluralize,
+			"lower":      strings.ToLower,
+		}).Parse(string(content))
+		if err != nil {
+			return fmt.Errorf("failed to parse template %s: %w", path, err)
+		}
+
+		g.templates[name] = tmpl
+		return nil
+	})
+}
+
+// applyTemplates renders the custom template of each file that has one,
+// leaving files without a matching template untouched.
+func (g *TemplateGenerator) applyTemplates(files []GeneratedFile, module string) error {
+	for i := range files {
+		tmpl, exists := g.templates[files[i].Template]
+		if !exists {
+			continue
+		}
+
+		data := TemplateData{
+			ProjectName:  g.config.ProjectName,
+			ModulePath:   g.modulePath(),
+			Database:     g.config.Database,
+			Frontend:     g.config.Frontend,
+			Auth:         g.config.Auth,
+			Features:     g.config.Features,
+			Enterprise:   g.config.Enterprise,
+			CustomValues: g.config.CustomValues,
+			Module:       module,
+			Path:         files[i].Path,
+			Builtin:      files[i].Content,
+		}
+
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, data); err != nil {
+			return fmt.Errorf("failed to render template %s for %s: %w", files[i].Template, files[i].Path, err)
+		}
+		files[i].Content = buf.String()
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DetectDatabase returns the database of a project from the GORM driver its
+// go.mod requires, defaulting to sqlite. Generated projects always require the
+// sqlite driver for tests, so the other drivers take precedence.
+func DetectDatabase(dir string) string {
+	if content, err := os.ReadFile(filepath.Join(dir, "go.mod")); err == nil {
+		return databaseFromGoMod(string(content))
+	}
+	return "sqlite"
+}
+
+func databaseFromGoMod(goMod string) string {
+	for _, database := range []string{"postgres", "mysql", "sqlite"} {
+		if strings.Contains(goMod, "gorm.io/driver/"+database) {
+			return database
+		}
+	}
+	return "sqlite"
+}
+
+// DetectFrontend returns the frontend of a project from its layout
+func DetectFrontend(dir string) string {
+	if exists(filepath.Join(dir, "frontend", "package.json")) {
+		return "react"
+	}
+	if exists(filepath.Join(dir, "templates")) {
+		return "templ"
+	}
+	if exists(filepath.Join(dir, "cmd", "desktop")) {
+		return "fyne"
+	}
+	return "none"
+}
+
+// DetectAuth returns the authentication of a project from its modules,
+// defaulting to jwt
+func DetectAuth(dir string) string {
+	if exists(filepath.Join(dir, "internal", "modules", "auth")) {
+		return "jwt"
+	}
+	if exists(filepath.Join(dir, "internal", "modules", "oauthsso")) {
+		return "oauth"
+	}
+	return "jwt"
+}
+
+// loadProjectConfig rebuilds the configuration a project was generated with
+// from its main.go markers, its go.mod and its layout. Custom templates and
+// dependency versions aren't recorded in the project, so those are taken
+// from the generator's own configuration.
+func (g *TemplateGenerator) loadProjectConfig(projectPath, main, goMod string, features []string) *GeneratorConfig {
+	config := &GeneratorConfig{
+		OutputPath:   projectPath,
+		Features:     features,
+		Database:     databaseFromGoMod(goMod),
+		Frontend:     DetectFrontend(projectPath),
+		Auth:         DetectAuth(projectPath),
+		Enterprise:   exists(filepath.Join(projectPath, "internal", "compliance")),
+		CustomValues: make(map[string]string),
+	}
+	if g.config != nil {
+		config.TemplateDir = g.config.TemplateDir
+		config.DependencyVersions = g.config.DependencyVersions
+		for key, value := range g.config.CustomValues {
+			config.CustomValues[key] = value
+		}
+	}
+	if m := goModModulePattern.FindStringSubmatch(goMod); m != nil {
+		config.CustomValues["module_path"] = m[1]
+	}
+	if m := projectMarkerPattern.FindStringSubmatch(main); m != nil {
+		config.ProjectName = strings.TrimSpace(m[1])
+	}
+	return config
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -2,9 +2,12 @@ package generator
 
 import (
 	"fmt"
+	goparser "go/parser"
+	"go/token"
 	"os"
 	"path"
 	"path/filepath"
+	"sort"
 	"strings"
 	"text/template"
 
@@ -29,6 +32,28 @@ type GeneratorConfig struct {
 	Enterprise    bool              `json:"enterprise"`    // enterprise features
 	Observability bool              `json:"observability"` // monitoring
 	CustomValues  map[string]string `json:"custom_values"` // custom template values
+	DryRun        bool              `json:"dry_run"`       // plan only, never touch the filesystem
+	TemplateDir   string            `json:"template_dir"`  // directory with custom .tmpl overrides
+
+	// DependencyVersions overrides the go.mod version of a module path,
+	// e.g. {"go.uber.org/fx": "v1.22.0"}. Unset paths use defaultDependencyVersions.
+	DependencyVersions map[string]string `json:"dependency_versions,omitempty"`
+}
+
+// defaultDependencyVersions are the go.mod versions used for generated projects
+// unless GeneratorConfig.DependencyVersions overrides them.
+var defaultDependencyVersions = map[string]string{
+	"go.uber.org/fx":                      "v1.20.0",
+	"github.com/gorilla/mux":              "v1.8.0",
+	"gorm.io/gorm":                        "v1.25.5",
+	"gorm.io/driver/sqlite":               "v1.5.4",
+	"gorm.io/driver/postgres":             "v1.5.4",
+	"gorm.io/driver/mysql":                "v1.5.4",
+	"github.com/golang-jwt/jwt/v5":        "v5.2.0",
+	"github.com/prometheus/client_golang": "v1.17.0",
+	"go.opentelemetry.io/otel":            "v1.21.0",
+	"github.com/gorilla/websocket":        "v1.5.0",
+	"fyne.io/fyne/v2":                     "v2.4.3",
 }
 
 // modulePath returns the module import path for the generated project.
@@ -102,6 +127,12 @@ func (g *TemplateGenerator) GenerateProject(config *GeneratorConfig) (*ProjectSt
 
 	g.config = config
 
+	if config.TemplateDir != "" {
+		if err := g.LoadTemplates(config.TemplateDir); err != nil {
+			return nil, fmt.Errorf("failed to load custom templates: %w", err)
+		}
+	}
+
 	// Step 1: Resolve dependencies
 	plan, err := g.resolver.ResolveDependencies(config.Features)
 	if err != nil {
@@ -126,15 +157,23 @@ func (g *TemplateGenerator) GenerateProject(config *GeneratorConfig) (*ProjectSt
 	if err := g.generateBaseStructure(structure); err != nil {
 		return nil, fmt.Errorf("failed to generate base structure: %w", err)
 	}
+	if err := g.applyTemplates(structure.Files, ""); err != nil {
+		return nil, err
+	}
 
 	// Step 4: Generate module files
 	for _, module := range plan.InstallOrder {
+		start := len(structure.Files)
 		if err := g.generateModuleFiles(module, structure); err != nil {
 			return nil, fmt.Errorf("failed to generate module '%s': %w", module, err)
 		}
+		if err := g.applyTemplates(structure.Files[start:], module); err != nil {
+			return nil, err
+		}
 	}
 
 	// Step 5: Generate frontend if requested
+	start := len(structure.Files)
 	if config.Frontend != "none" {
 		if err := g.generateFrontend(structure); err != nil {
 			return nil, fmt.Errorf("failed to generate frontend: %w", err)
@@ -150,6 +189,14 @@ func (g *TemplateGenerator) GenerateProject(config *GeneratorConfig) (*ProjectSt
 	if err := g.generateBuildScripts(structure); err != nil {
 		return nil, fmt.Errorf("failed to generate build scripts: %w", err)
 	}
+	if err := g.applyTemplates(structure.Files[start:], ""); err != nil {
+		return nil, err
+	}
+
+	// Step 8: Validate generated Go sources
+	if err := validateGoFiles(structure); err != nil {
+		return nil, fmt.Errorf("generated project is invalid: %w", err)
+	}
 
 	fmt.Printf("✅ Project generated successfully: %d files, %d directories\n",
 		len(structure.Files), len(structure.Directories))
@@ -157,6 +204,21 @@ func (g *TemplateGenerator) GenerateProject(config *GeneratorConfig) (*ProjectSt
 	return structure, nil
 }
 
+// validateGoFiles parses every generated .go file so malformed templates are
+// reported before anything is written to disk.
+func validateGoFiles(structure *ProjectStructure) error {
+	fset := token.NewFileSet()
+	for _, file := range structure.Files {
+		if filepath.Ext(file.Path) != ".go" {
+			continue
+		}
+		if _, err := goparser.ParseFile(fset, file.Path, file.Content, goparser.AllErrors); err != nil {
+			return fmt.Errorf("failed to parse %s: %w", file.Path, err)
+		}
+	}
+	return nil
+}
+
 // generateBaseStructure generates the base project structure
 func (g *TemplateGenerator) generateBaseStructure(structure *ProjectStructure) error {
 	baseDirs := []string{
@@ -209,10 +271,14 @@ func (g *TemplateGenerator) generateBaseStructure(structure *ProjectStructure) e
 	structure.Files = append(structure.Files, mainTestFile)
 
 	// Generate go.mod
+	goMod, err := g.generateGoMod()
+	if err != nil {
+		return err
+	}
 	goModFile := GeneratedFile{
 		Path:     "go.mod",
 		Template: "go.mod.tmpl",
-		Content:  g.generateGoMod(),
+		Content:  goMod,
 	}
 	structure.Files = append(structure.Files, goModFile)
 
@@ -332,11 +398,13 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
 	"os/signal"
+	"sync/atomic"
 	"syscall"
 	"time"
 
@@ -347,21 +415,51 @@ import (
 %s
 )
 
+const (
+// defaultShutdownGracePeriod is how long shutdown waits for in-flight
+// requests unless SERVER_SHUTDOWN_GRACE_PERIOD says otherwise
+defaultShutdownGracePeriod = 30 * time.Second
+// stopHookMargin leaves room after draining requests for the other stop hooks
+stopHookMargin = 15 * time.Second
+)
+
 type httpServer interface {
 Start() error
 Shutdown(context.Context) error
 }
 
+// inFlightTracker counts the requests currently being served
+type inFlightTracker struct {
+active atomic.Int64
+}
+
+func (t *inFlightTracker) middleware(next http.Handler) http.Handler {
+return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+t.active.Add(1)
+defer t.active.Add(-1)
+next.ServeHTTP(w, r)
+})
+}
+
+func (t *inFlightTracker) count() int64 {
+return t.active.Load()
+}
+
 type realHTTPServer struct {
-server *http.Server
+server  *http.Server
+tracker *inFlightTracker
+grace   time.Duration
 }
 
-func newHTTPServer(handler http.Handler) httpServer {
+func newHTTPServer(handler http.Handler, grace time.Duration) httpServer {
+tracker := &inFlightTracker{}
 return &realHTTPServer{
 server: &http.Server{
 Addr:    ":8080",
-Handler: handler,
+Handler: tracker.middleware(handler),
 },
+tracker: tracker,
+grace:   grace,
 }
 }
 
@@ -372,10 +470,28 @@ return err
 return nil
 }
 
+// Shutdown stops accepting connections and waits up to the grace period for
+// in-flight requests to finish. Requests still running after that are cut
+// off by force-closing the server.
 func (s *realHTTPServer) Shutdown(ctx context.Context) error {
-return s.server.Shutdown(ctx)
+shutdownCtx, cancel := context.WithTimeout(ctx, s.grace)
+defer cancel()
+
+log.Println("draining in-flight requests:", s.tracker.count(), "grace period:", s.grace)
+err := s.server.Shutdown(shutdownCtx)
+if err == nil {
+return nil
+}
+
+log.Println("grace period expired, force closing with requests in flight:", s.tracker.count())
+if closeErr := s.server.Close(); closeErr != nil {
+return errors.Join(err, closeErr)
+}
+return err
 }
 
+// noopHTTPServer stands in for the server in test mode; it never listens,
+// so there is nothing to drain on shutdown
 type noopHTTPServer struct{}
 
 func (n *noopHTTPServer) Start() error {
@@ -386,11 +502,24 @@ func (n *noopHTTPServer) Shutdown(context.Context) error {
 return nil
 }
 
-var serverBuilder = func(handler http.Handler) httpServer {
+var serverBuilder = func(handler http.Handler, grace time.Duration) httpServer {
 if os.Getenv("KTHULU_TEST_MODE") == "1" {
 return &noopHTTPServer{}
 }
-return newHTTPServer(handler)
+return newHTTPServer(handler, grace)
+}
+
+// shutdownGracePeriod reads the grace period from SERVER_SHUTDOWN_GRACE_PERIOD
+func shutdownGracePeriod() (time.Duration, error) {
+value := os.Getenv("SERVER_SHUTDOWN_GRACE_PERIOD")
+if value == "" {
+return defaultShutdownGracePeriod, nil
+}
+grace, err := time.ParseDuration(value)
+if err != nil {
+return 0, fmt.Errorf("invalid SERVER_SHUTDOWN_GRACE_PERIOD: %%w", err)
+}
+return grace, nil
 }
 
 func main() {
@@ -399,11 +528,18 @@ log.Fatal("Failed to start application:", err)
 }
 }
 
-func runApplication(ctx context.Context, builder func(http.Handler) httpServer) error {
+func runApplication(ctx context.Context, builder func(http.Handler, time.Duration) httpServer) error {
+grace, err := shutdownGracePeriod()
+if err != nil {
+return err
+}
+
 ctx, stop := signal.NotifyContext(ctx, syscall.SIGINT, syscall.SIGTERM)
 defer stop()
 
 app := fx.New(
+fx.StopTimeout(grace+stopHookMargin),
+
 // Core providers
 core.CoreRepositoryProviders(),
 
@@ -416,7 +552,7 @@ apiRouter := router.PathPrefix("/api/v1").Subrouter()
 
 %s
 
-server := builder(router)
+server := builder(router, grace)
 
 lc.Append(fx.Hook{
 OnStart: func(context.Context) error {
@@ -440,7 +576,7 @@ return err
 
 <-ctx.Done()
 
-shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+shutdownCtx, cancel := context.WithTimeout(context.Background(), grace+stopHookMargin)
 defer cancel()
 
 return app.Stop(shutdownCtx)
@@ -466,33 +602,23 @@ return router
 }
 
 // generateGoMod generates the go.mod file
-func (g *TemplateGenerator) generateGoMod() string {
+func (g *TemplateGenerator) generateGoMod() (string, error) {
 	modulePath := g.modulePath()
-	depSet := make(map[string]struct{})
-	var deps []string
-	addDep := func(dep string) {
-		dep = strings.TrimSpace(dep)
-		if dep == "" {
-			return
-		}
-		if _, exists := depSet[dep]; exists {
-			return
-		}
-		depSet[dep] = struct{}{}
-		deps = append(deps, "\t"+dep)
-	}
 
-	addDep("go.uber.org/fx v1.20.0")
-	addDep("github.com/gorilla/mux v1.8.0")
-	addDep("gorm.io/gorm v1.25.5")
-	addDep(fmt.Sprintf("gorm.io/driver/%s v1.5.4", g.config.Database))
-	addDep("gorm.io/driver/sqlite v1.5.4")
-	addDep("github.com/golang-jwt/jwt/v5 v5.2.0")
+	modules := []string{
+		"go.uber.org/fx",
+		"github.com/gorilla/mux",
+		"gorm.io/gorm",
+	}
+	if database := strings.TrimSpace(g.config.Database); database != "" {
+		modules = append(modules, "gorm.io/driver/"+database)
+	}
+	modules = append(modules, "gorm.io/driver/sqlite", "github.com/golang-jwt/jwt/v5")
+	modules = append(modules, g.generateDependencies()...)
 
-	if extra := strings.Split(strings.TrimSpace(g.generateDependencies()), "\n"); len(extra) > 0 {
-		for _, dep := range extra {
-			addDep(strings.TrimSpace(dep))
-		}
+	requires, err := g.requireLines(modules)
+	if err != nil {
+		return "", err
 	}
 
 	return fmt.Sprintf(`module %s
@@ -502,7 +628,42 @@ go 1.21
 require (
 %s
 )
-`, modulePath, strings.Join(deps, "\n"))
+`, modulePath, strings.Join(requires, "\n")), nil
+}
+
+// requireLines turns module paths into go.mod require lines. Blank entries and
+// repeated paths are dropped; paths without a known version are an error.
+func (g *TemplateGenerator) requireLines(modules []string) ([]string, error) {
+	seen := make(map[string]struct{}, len(modules))
+	lines := make([]string, 0, len(modules))
+	for _, module := range modules {
+		module = strings.TrimSpace(module)
+		if module == "" {
+			continue
+		}
+		if _, exists := seen[module]; exists {
+			continue
+		}
+		seen[module] = struct{}{}
+
+		version := g.dependencyVersion(module)
+		if version == "" {
+			return nil, fmt.Errorf("no version known for dependency %s, set one in dependency_versions", module)
+		}
+		lines = append(lines, fmt.Sprintf("\t%s %s", module, version))
+	}
+	return lines, nil
+}
+
+// dependencyVersion returns the configured version for a module path,
+// falling back to defaultDependencyVersions.
+func (g *TemplateGenerator) dependencyVersion(module string) string {
+	if g.config != nil {
+		if version := strings.TrimSpace(g.config.DependencyVersions[module]); version != "" {
+			return version
+		}
+	}
+	return defaultDependencyVersions[module]
 }
 
 // generateReadme generates the README.md file
@@ -662,12 +823,7 @@ func (g *TemplateGenerator) generateModuleImports() string {
 	// Use resolved dependencies, not just initial features
 	plan, _ := g.resolver.ResolveDependencies(g.config.Features)
 	for _, module := range plan.RequiredModules {
-		moduleBase := g.moduleImportPath("internal/adapters/http/modules", module)
-		domainImport := g.moduleImportPath("internal/adapters/http/modules", module, "domain")
-		handlersImport := g.moduleImportPath("internal/adapters/http/modules", module, "handlers")
-		imports = append(imports, fmt.Sprintf(` "%s"`, moduleBase))
-		imports = append(imports, fmt.Sprintf(` %sDomain "%s"`, module, domainImport))
-		imports = append(imports, fmt.Sprintf(` %sHandlers "%s"`, module, handlersImport))
+		imports = append(imports, g.moduleImportLines(module)...)
 	}
 	return strings.Join(imports, "\n")
 }
@@ -676,7 +832,7 @@ func (g *TemplateGenerator) generateModuleProviders() string {
 	var providers []string
 	plan, _ := g.resolver.ResolveDependencies(g.config.Features)
 	for _, module := range plan.RequiredModules {
-		providers = append(providers, fmt.Sprintf("\t\t%s.Providers(),", module))
+		providers = append(providers, moduleProviderLine(module))
 	}
 	return strings.Join(providers, "\n")
 }
@@ -685,9 +841,7 @@ func (g *TemplateGenerator) generateModuleRoutes() string {
 	var routes []string
 	plan, _ := g.resolver.ResolveDependencies(g.config.Features)
 	for _, module := range plan.RequiredModules {
-		routes = append(routes, fmt.Sprintf(`	// %s routes`, module))
-		routes = append(routes, fmt.Sprintf(`	%sHandler := %sHandlers.New%sHandler(%sService)`, module, module, Capitalize(module), module))
-		routes = append(routes, fmt.Sprintf(`	%sHandler.RegisterRoutes(apiRouter)`, module))
+		routes = append(routes, moduleRouteLines(module)...)
 	}
 	return strings.Join(routes, "\n")
 }
@@ -696,26 +850,61 @@ func (g *TemplateGenerator) generateInvokeParams() string {
 	var params []string
 	plan, _ := g.resolver.ResolveDependencies(g.config.Features)
 	for _, module := range plan.RequiredModules {
-		params = append(params, fmt.Sprintf(`%sService %sDomain.%sService`, module, module, Capitalize(module)))
+		params = append(params, moduleInvokeParam(module))
 	}
 	return strings.Join(params, ", ")
 }
 
-func (g *TemplateGenerator) generateDependencies() string {
+// moduleImportLines returns the main.go imports needed to wire a module
+func (g *TemplateGenerator) moduleImportLines(module string) []string {
+	moduleBase := g.moduleImportPath("internal/adapters/http/modules", module)
+	domainImport := g.moduleImportPath("internal/adapters/http/modules", module, "domain")
+	handlersImport := g.moduleImportPath("internal/adapters/http/modules", module, "handlers")
+	return []string{
+		fmt.Sprintf(` "%s"`, moduleBase),
+		fmt.Sprintf(` %sDomain "%s"`, module, domainImport),
+		fmt.Sprintf(` %sHandlers "%s"`, module, handlersImport),
+	}
+}
+
+// moduleProviderLine returns the fx provider entry of a module in main.go
+func moduleProviderLine(module string) string {
+	return fmt.Sprintf("\t\t%s.Providers(),", module)
+}
+
+// moduleRouteLines returns the route registration of a module in main.go
+func moduleRouteLines(module string) []string {
+	return []string{
+		fmt.Sprintf(`	// %s routes`, module),
+		fmt.Sprintf(`	%sHandler := %sHandlers.New%sHandler(%sService)`, module, module, Capitalize(module), module),
+		fmt.Sprintf(`	%sHandler.RegisterRoutes(apiRouter)`, module),
+	}
+}
+
+// moduleInvokeParam returns the fx.Invoke parameter carrying a module's service
+func moduleInvokeParam(module string) string {
+	return fmt.Sprintf(`%sService %sDomain.%sService`, module, module, Capitalize(module))
+}
+
+// generateDependencies returns the extra module paths required by the selected features
+func (g *TemplateGenerator) generateDependencies() []string {
 	deps := []string{}
 
 	if g.config.Enterprise {
 		deps = append(deps,
-			"\tgithub.com/prometheus/client_golang v1.17.0",
-			"\tgo.opentelemetry.io/otel v1.21.0",
+			"github.com/prometheus/client_golang",
+			"go.opentelemetry.io/otel",
 		)
 	}
 
-	if g.config.Frontend == "react" {
-		deps = append(deps, "\tgithub.com/gorilla/websocket v1.5.0")
+	switch g.config.Frontend {
+	case "react":
+		deps = append(deps, "github.com/gorilla/websocket")
+	case "fyne":
+		deps = append(deps, "fyne.io/fyne/v2")
 	}
 
-	return strings.Join(deps, "\n")
+	return deps
 }
 
 func (g *TemplateGenerator) generateFeatureList() string {
@@ -1028,10 +1217,63 @@ func (h *%sHandler) List(w http.ResponseWriter, r *http.Request) {
 // Additional methods for frontend, configuration, and build scripts
 func (g *TemplateGenerator) generateFrontend(structure *ProjectStructure) error {
 	// Implementation for frontend generation based on config.Frontend
-	// React, Templ, Fyne, etc.
+	switch g.config.Frontend {
+	case "react", "templ":
+		// No Go sources are generated for these frontends yet
+	case "fyne":
+		structure.Directories = append(structure.Directories, "cmd/desktop")
+		structure.Files = append(structure.Files, GeneratedFile{
+			Path:     "cmd/desktop/main.go",
+			Template: "desktop.go.tmpl",
+			Content:  g.generateDesktopMain(structure.Dependencies),
+		})
+	}
 	return nil
 }
 
+// generateDesktopMain generates a Fyne desktop entrypoint listing the project modules
+func (g *TemplateGenerator) generateDesktopMain(modules []string) string {
+	sorted := append([]string{}, modules...)
+	sort.Strings(sorted)
+
+	items := make([]string, 0, len(sorted))
+	for _, module := range sorted {
+		items = append(items, fmt.Sprintf("\t\t%q,", module))
+	}
+
+	return fmt.Sprintf(`// @kthulu:frontend:fyne
+package main
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/app"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+)
+
+func main() {
+	modules := []string{
+%s
+	}
+
+	a := app.New()
+	w := a.NewWindow(%q)
+
+	list := widget.NewList(
+		func() int { return len(modules) },
+		func() fyne.CanvasObject { return widget.NewLabel("") },
+		func(id widget.ListItemID, item fyne.CanvasObject) {
+			item.(*widget.Label).SetText(modules[id])
+		},
+	)
+
+	w.SetContent(container.NewBorder(widget.NewLabel("Modules"), nil, nil, nil, list))
+	w.Resize(fyne.NewSize(480, 360))
+	w.ShowAndRun()
+}
+`, strings.Join(items, "\n"), g.config.ProjectName)
+}
+
 func (g *TemplateGenerator) generateConfiguration(structure *ProjectStructure) error {
 	// Generate docker-compose.yml
 	dockerComposeFile := GeneratedFile{
@@ -1087,6 +1329,8 @@ package main
 
 import (
 "context"
+"errors"
+"net"
 "net/http"
 "net/http/httptest"
 "testing"
@@ -1117,7 +1361,7 @@ srv := &testHTTPServer{}
 errCh := make(chan error, 1)
 
 go func() {
-errCh <- runApplication(ctx, func(http.Handler) httpServer {
+errCh <- runApplication(ctx, func(http.Handler, time.Duration) httpServer {
 return srv
 })
 }()
@@ -1139,6 +1383,57 @@ t.Fatalf("server lifecycle not executed: started=%v shutdown=%v", srv.started, s
 }
 }
 
+// serveSlowly serves a handler taking delay on a local listener, starts a
+// request to it and returns the server once the request is in flight
+func serveSlowly(t *testing.T, delay, grace time.Duration) (*realHTTPServer, <-chan error) {
+t.Helper()
+started := make(chan struct{})
+srv := newHTTPServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+close(started)
+time.Sleep(delay)
+w.WriteHeader(http.StatusOK)
+}), grace).(*realHTTPServer)
+
+ln, err := net.Listen("tcp", "127.0.0.1:0")
+if err != nil {
+t.Fatalf("listen: %v", err)
+}
+go srv.server.Serve(ln)
+
+reqErr := make(chan error, 1)
+go func() {
+resp, err := http.Get("http://" + ln.Addr().String())
+if err == nil {
+resp.Body.Close()
+}
+reqErr <- err
+}()
+<-started
+return srv, reqErr
+}
+
+func TestShutdownWaitsForInFlightRequest(t *testing.T) {
+srv, reqErr := serveSlowly(t, 50*time.Millisecond, time.Second)
+
+if err := srv.Shutdown(context.Background()); err != nil {
+t.Fatalf("expected the request to finish within the grace period, got %v", err)
+}
+if err := <-reqErr; err != nil {
+t.Fatalf("expected the in-flight request to complete, got %v", err)
+}
+}
+
+func TestShutdownForceClosesAfterGrace(t *testing.T) {
+srv, reqErr := serveSlowly(t, time.Second, 20*time.Millisecond)
+
+if err := srv.Shutdown(context.Background()); !errors.Is(err, context.DeadlineExceeded) {
+t.Fatalf("expected the grace period to expire, got %v", err)
+}
+if err := <-reqErr; err == nil {
+t.Fatal("expected the request to be cut off")
+}
+}
+
 func TestSetupRoutesHealth(t *testing.T) {
 handler := setupRoutes()
 req := httptest.NewRequest(http.MethodGet, "/health", nil)
@@ -1448,6 +1743,11 @@ t.Fatalf("expected 204 got %%d", deleteRec.Code)
 
 // WriteProject writes the generated project to disk
 func (g *TemplateGenerator) WriteProject(structure *ProjectStructure) error {
+	if g.config != nil && g.config.DryRun {
+		g.printPlannedFiles(structure)
+		return nil
+	}
+
 	fmt.Printf("📁 Writing project to: %s\n", structure.RootPath)
 
 	// Create directories
@@ -1488,3 +1788,22 @@ func (g *TemplateGenerator) WriteProject(structure *ProjectStructure) error {
 	fmt.Printf("🎉 Project generated successfully!\n")
 	return nil
 }
+
+// printPlannedFiles lists the directories and files WriteProject would create
+// without touching the filesystem
+func (g *TemplateGenerator) printPlannedFiles(structure *ProjectStructure) {
+	fmt.Printf("🔍 Dry run: planned project at %s\n", structure.RootPath)
+
+	for _, dir := range structure.Directories {
+		fmt.Printf("  📁 %s/\n", dir)
+	}
+
+	total := 0
+	for _, file := range structure.Files {
+		total += len(file.Content)
+		fmt.Printf("  📄 %s (%d bytes)\n", file.Path, len(file.Content))
+	}
+
+	fmt.Printf("🔍 %d directories, %d files, %d bytes - nothing written\n",
+		len(structure.Directories), len(structure.Files), total)
+}
@@ -0,0 +1,228 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/pmaojo/kthulu-go/backend/cmd/kthulu-cli/internal/resolver"
+	"github.com/pmaojo/kthulu-go/backend/internal/adapters/cli/parser"
+)
+
+func newTestGenerator() *TemplateGenerator {
+	analysis := &parser.ProjectAnalysis{Modules: map[string]*parser.Module{}}
+	return NewTemplateGenerator(resolver.NewDependencyResolver(analysis))
+}
+
+func TestWriteProjectDryRunCreatesNothing(t *testing.T) {
+	outputPath := filepath.Join(t.TempDir(), "demo")
+	g := newTestGenerator()
+
+	structure, err := g.GenerateProject(&GeneratorConfig{
+		ProjectName:  "demo",
+		OutputPath:   outputPath,
+		Frontend:     "none",
+		Database:     "sqlite",
+		Auth:         "jwt",
+		Features:     []string{"user"},
+		CustomValues: map[string]string{},
+		DryRun:       true,
+	})
+	if err != nil {
+		t.Fatalf("generate project: %v", err)
+	}
+	if len(structure.Files) == 0 {
+		t.Fatalf("expected planned files in dry run")
+	}
+
+	if err := g.WriteProject(structure); err != nil {
+		t.Fatalf("write project: %v", err)
+	}
+	if _, err := os.Stat(outputPath); !os.IsNotExist(err) {
+		t.Fatalf("dry run should not create %s, stat err: %v", outputPath, err)
+	}
+}
+
+func TestWriteProjectWritesFiles(t *testing.T) {
+	outputPath := t.TempDir()
+	g := newTestGenerator()
+	g.config = &GeneratorConfig{}
+
+	structure := &ProjectStructure{
+		RootPath:    outputPath,
+		Directories: []string{"cmd"},
+		Files:       []GeneratedFile{{Path: "cmd/main.go", Content: "package main\n", Overwrite: true}},
+	}
+	if err := g.WriteProject(structure); err != nil {
+		t.Fatalf("write project: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(outputPath, "cmd", "main.go")); err != nil {
+		t.Fatalf("expected file to be written: %v", err)
+	}
+}
+
+func TestRequireLinesDedupesAndSkipsBlanks(t *testing.T) {
+	g := newTestGenerator()
+
+	got, err := g.requireLines([]string{"gorm.io/gorm", "", "   ", " gorm.io/gorm ", "go.uber.org/fx"})
+	if err != nil {
+		t.Fatalf("require lines: %v", err)
+	}
+
+	want := []string{"\tgorm.io/gorm v1.25.5", "\tgo.uber.org/fx v1.20.0"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+}
+
+func TestRequireLinesRejectsUnknownDependencies(t *testing.T) {
+	g := newTestGenerator()
+	g.config = &GeneratorConfig{DependencyVersions: map[string]string{"example.com/pinned": "v0.3.0"}}
+
+	if _, err := g.requireLines([]string{"gorm.io/gorm", "example.com/unknown"}); err == nil || !strings.Contains(err.Error(), "example.com/unknown") {
+		t.Fatalf("expected the unknown dependency to be reported, got %v", err)
+	}
+	got, err := g.requireLines([]string{"example.com/pinned"})
+	if err != nil || !reflect.DeepEqual(got, []string{"\texample.com/pinned v0.3.0"}) {
+		t.Fatalf("expected the configured version to be used, got %q, %v", got, err)
+	}
+}
+
+func TestGenerateGoModUsesVersionOverrides(t *testing.T) {
+	g := newTestGenerator()
+	g.config = &GeneratorConfig{
+		ProjectName:        "demo",
+		Database:           "sqlite",
+		DependencyVersions: map[string]string{"go.uber.org/fx": "v1.22.0"},
+	}
+
+	goMod, err := g.generateGoMod()
+	if err != nil {
+		t.Fatalf("generate go.mod: %v", err)
+	}
+
+	if !strings.Contains(goMod, "\tgo.uber.org/fx v1.22.0\n") {
+		t.Fatalf("expected overridden fx version:\n%s", goMod)
+	}
+	if strings.Count(goMod, "gorm.io/driver/sqlite") != 1 {
+		t.Fatalf("expected sqlite driver exactly once:\n%s", goMod)
+	}
+	for _, line := range strings.Split(goMod, "\n") {
+		if strings.HasPrefix(line, "\t") && len(strings.Fields(line)) != 2 {
+			t.Fatalf("malformed require line %q", line)
+		}
+	}
+}
+
+func TestValidateGoFilesReportsBrokenFile(t *testing.T) {
+	structure := &ProjectStructure{Files: []GeneratedFile{
+		{Path: "README.md", Content: "not go {"},
+		{Path: "cmd/server/main.go", Content: "package main\n\nfunc main() {}\n"},
+		{Path: "internal/modules.go", Content: "package internal\n\nimport (\n user \"demo/user\n)\n"},
+	}}
+
+	err := validateGoFiles(structure)
+	if err == nil {
+		t.Fatalf("expected broken template to be reported")
+	}
+	if !strings.Contains(err.Error(), "internal/modules.go") {
+		t.Fatalf("expected offending path in error, got %q", err.Error())
+	}
+}
+
+func TestGenerateProjectUsesCustomTemplate(t *testing.T) {
+	templateDir := t.TempDir()
+	custom := "package main\n\n// custom main for {{.ProjectName}}\nfunc main() {}\n"
+	if err := os.WriteFile(filepath.Join(templateDir, "main.go.tmpl"), []byte(custom), 0o644); err != nil {
+		t.Fatalf("write template: %v", err)
+	}
+
+	g := newTestGenerator()
+	structure, err := g.GenerateProject(&GeneratorConfig{
+		ProjectName:  "demo",
+		OutputPath:   t.TempDir(),
+		Frontend:     "none",
+		Database:     "sqlite",
+		Features:     []string{"user"},
+		CustomValues: map[string]string{},
+		TemplateDir:  templateDir,
+	})
+	if err != nil {
+		t.Fatalf("generate project: %v", err)
+	}
+
+	files := make(map[string]string, len(structure.Files))
+	for _, file := range structure.Files {
+		files[file.Path] = file.Content
+	}
+	if !strings.Contains(files["cmd/server/main.go"], "// custom main for demo") {
+		t.Fatalf("expected custom main.go, got:\n%s", files["cmd/server/main.go"])
+	}
+	if !strings.HasPrefix(files["go.mod"], "module demo") {
+		t.Fatalf("expected built-in go.mod fallback, got:\n%s", files["go.mod"])
+	}
+}
+
+func TestGenerateProjectFyneFrontend(t *testing.T) {
+	g := newTestGenerator()
+	structure, err := g.GenerateProject(&GeneratorConfig{
+		ProjectName:  "demo",
+		OutputPath:   t.TempDir(),
+		Frontend:     "fyne",
+		Database:     "sqlite",
+		Features:     []string{"user"},
+		CustomValues: map[string]string{},
+	})
+	if err != nil {
+		t.Fatalf("generate project: %v", err)
+	}
+
+	files := make(map[string]string, len(structure.Files))
+	for _, file := range structure.Files {
+		files[file.Path] = file.Content
+	}
+	desktop, ok := files["cmd/desktop/main.go"]
+	if !ok {
+		t.Fatalf("expected cmd/desktop/main.go to be generated")
+	}
+	if !strings.Contains(desktop, `"user",`) {
+		t.Fatalf("expected desktop window to list modules:\n%s", desktop)
+	}
+	if !strings.Contains(files["go.mod"], "\tfyne.io/fyne/v2 v2.4.3\n") {
+		t.Fatalf("expected fyne dependency in go.mod:\n%s", files["go.mod"])
+	}
+}
+
+func TestGenerateProjectMainDrainsOnShutdown(t *testing.T) {
+	g := newTestGenerator()
+	structure, err := g.GenerateProject(&GeneratorConfig{
+		ProjectName:  "demo",
+		OutputPath:   t.TempDir(),
+		Frontend:     "none",
+		Database:     "sqlite",
+		Features:     []string{"user"},
+		CustomValues: map[string]string{},
+	})
+	if err != nil {
+		t.Fatalf("generate project: %v", err)
+	}
+
+	var main string
+	for _, file := range structure.Files {
+		if file.Path == "cmd/server/main.go" {
+			main = file.Content
+		}
+	}
+	for _, want := range []string{
+		`os.Getenv("SERVER_SHUTDOWN_GRACE_PERIOD")`,
+		"Handler: tracker.middleware(handler)",
+		"s.server.Close()",
+		"fx.StopTimeout(grace+stopHookMargin)",
+	} {
+		if !strings.Contains(main, want) {
+			t.Fatalf("expected generated main.go to contain %q:\n%s", want, main)
+		}
+	}
+}
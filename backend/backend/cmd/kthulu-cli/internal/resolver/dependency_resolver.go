@@ -1,6 +1,7 @@
 package resolver
 
 import (
+	"errors"
 	"fmt"
 	"sort"
 	"strings"
@@ -8,6 +9,9 @@ import (
 	"github.com/pmaojo/kthulu-go/backend/internal/adapters/cli/parser"
 )
 
+// ErrCircularDependency is returned when the module dependency graph contains a cycle
+var ErrCircularDependency = errors.New("circular dependency detected")
+
 // DependencyResolver resolves module dependencies intelligently
 type DependencyResolver struct {
 	modules      map[string]*parser.Module
@@ -100,20 +104,25 @@ func (r *DependencyResolver) ResolveDependencies(requestedModules []string) (*Re
 		plan.RequiredModules = append(plan.RequiredModules, module)
 	}
 
-	// Step 2: Calculate installation order (topological sort)
+	// Step 2: Reject circular dependencies before ordering
+	if cycle := r.findCycle(plan.RequiredModules); cycle != nil {
+		return nil, fmt.Errorf("%w: %s", ErrCircularDependency, strings.Join(cycle, " -> "))
+	}
+
+	// Step 3: Calculate installation order (topological sort)
 	installOrder, err := r.calculateInstallOrder(plan.RequiredModules)
 	if err != nil {
 		return nil, fmt.Errorf("failed to calculate install order: %w", err)
 	}
 	plan.InstallOrder = installOrder
 
-	// Step 3: Detect conflicts
+	// Step 4: Detect conflicts
 	r.detectConflicts(plan)
 
-	// Step 4: Generate recommendations
+	// Step 5: Generate recommendations
 	r.generateRecommendations(requestedModules, plan)
 
-	// Step 5: Suggest optional modules
+	// Step 6: Suggest optional modules
 	r.suggestOptionalModules(plan)
 
 	fmt.Printf("✅ Resolution complete: %d required, %d optional, %d conflicts\n",
@@ -195,15 +204,64 @@ func (r *DependencyResolver) calculateInstallOrder(modules []string) ([]string,
 
 	// Check for cycles
 	if len(result) != len(modules) {
-		return nil, fmt.Errorf("circular dependency detected")
+		return nil, ErrCircularDependency
 	}
 
 	return result, nil
 }
 
+// findCycle returns the first dependency cycle reachable from the given
+// modules as a path that starts and ends with the same module, or nil when
+// the graph is acyclic. Modules are visited in sorted order so the reported
+// cycle is deterministic.
+func (r *DependencyResolver) findCycle(modules []string) []string {
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+
+	state := make(map[string]int)
+	var path []string
+
+	var visit func(module string) []string
+	visit = func(module string) []string {
+		switch state[module] {
+		case visiting:
+			for i, m := range path {
+				if m == module {
+					return append(append([]string{}, path[i:]...), module)
+				}
+			}
+		case done:
+			return nil
+		}
+
+		state[module] = visiting
+		path = append(path, module)
+		for _, dep := range r.rules[module] {
+			if cycle := visit(dep); cycle != nil {
+				return cycle
+			}
+		}
+		path = path[:len(path)-1]
+		state[module] = done
+		return nil
+	}
+
+	sorted := append([]string{}, modules...)
+	sort.Strings(sorted)
+	for _, module := range sorted {
+		if cycle := visit(module); cycle != nil {
+			return cycle
+		}
+	}
+	return nil
+}
+
 // detectConflicts detects various types of conflicts
 func (r *DependencyResolver) detectConflicts(plan *ResolutionPlan) {
-	// Check for circular dependencies (already handled in topological sort)
+	// Circular dependencies are rejected before the topological sort
 
 	// Check for incompatible modules
 	r.checkIncompatibleModules(plan)
@@ -0,0 +1,69 @@
+package resolver
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/pmaojo/kthulu-go/backend/internal/adapters/cli/parser"
+)
+
+func newTestResolver(rules map[string][]string) *DependencyResolver {
+	r := NewDependencyResolver(&parser.ProjectAnalysis{Modules: map[string]*parser.Module{}})
+	if rules != nil {
+		r.rules = rules
+	}
+	return r
+}
+
+func TestResolveDependenciesDetectsCycle(t *testing.T) {
+	r := newTestResolver(map[string][]string{
+		"a": {"b"},
+		"b": {"c"},
+		"c": {"a"},
+	})
+
+	_, err := r.ResolveDependencies([]string{"a"})
+	if !errors.Is(err, ErrCircularDependency) {
+		t.Fatalf("expected circular dependency error, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "a -> b -> c -> a") {
+		t.Fatalf("expected cycle path in error, got %q", err.Error())
+	}
+}
+
+func TestResolveDependenciesDetectsSelfCycle(t *testing.T) {
+	r := newTestResolver(map[string][]string{
+		"a": {"b"},
+		"b": {"b"},
+	})
+
+	_, err := r.ResolveDependencies([]string{"a"})
+	if err == nil || !strings.Contains(err.Error(), "b -> b") {
+		t.Fatalf("expected self cycle b -> b, got %v", err)
+	}
+}
+
+func TestResolveDependenciesInstallOrderIsTopological(t *testing.T) {
+	r := newTestResolver(nil)
+
+	plan, err := r.ResolveDependencies([]string{"invoice", "calendar"})
+	if err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	if len(plan.InstallOrder) != len(plan.RequiredModules) {
+		t.Fatalf("install order %v does not cover required modules %v", plan.InstallOrder, plan.RequiredModules)
+	}
+
+	position := make(map[string]int, len(plan.InstallOrder))
+	for i, module := range plan.InstallOrder {
+		position[module] = i
+	}
+	for _, module := range plan.InstallOrder {
+		for _, dep := range r.rules[module] {
+			if position[dep] > position[module] {
+				t.Fatalf("%s installed before its dependency %s: %v", module, dep, plan.InstallOrder)
+			}
+		}
+	}
+}
@@ -20,10 +20,12 @@ var AuthModule = fx.Options(
 	// HTTP handlers
 	fx.Provide(
 		adapterhttp.NewAuthHandler,
+		adapterhttp.NewAuthEventHandler,
 	),
 
 	// Register routes
-	fx.Invoke(func(handler *adapterhttp.AuthHandler, registry *RouteRegistry) {
-		registry.Register(handler)
+	fx.Invoke(func(handler *adapterhttp.AuthHandler, events *adapterhttp.AuthEventHandler, registry *RouteRegistry) {
+		registry.RegisterModule("auth", handler)
+		registry.RegisterModule("auth", events)
 	}),
 )
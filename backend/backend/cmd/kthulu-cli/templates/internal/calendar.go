@@ -12,7 +12,10 @@ import (
 var CalendarModule = fx.Options(
 	// Use cases
 	fx.Provide(
-		usecase.NewCalendarUseCase,
+		fx.Annotate(
+			usecase.NewCalendarUseCase,
+			fx.ParamTags(``, ``, `optional:"true"`, `optional:"true"`),
+		),
 	),
 
 	// HTTP handlers
@@ -22,6 +25,6 @@ var CalendarModule = fx.Options(
 
 	// Register routes
 	fx.Invoke(func(handler *adapterhttp.CalendarHandler, registry *RouteRegistry) {
-		registry.Register(handler)
+		registry.RegisterModule("calendar", handler)
 	}),
 )
@@ -29,6 +29,6 @@ var ContactModule = fx.Options(
 
 	// Register routes
 	fx.Invoke(func(handler *adapterhttp.ContactHandler, registry *RouteRegistry) {
-		registry.Register(handler)
+		registry.RegisterModule("contacts", handler)
 	}),
 )
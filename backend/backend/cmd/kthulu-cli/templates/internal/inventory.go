@@ -22,6 +22,6 @@ var InventoryModule = fx.Options(
 
 	// Register routes
 	fx.Invoke(func(handler *adapterhttp.InventoryHandler, registry *RouteRegistry) {
-		registry.Register(handler)
+		registry.RegisterModule("inventory", handler)
 	}),
 )
@@ -4,7 +4,9 @@ package modules
 import (
 	"go.uber.org/fx"
 
+	"github.com/pmaojo/kthulu-go/backend/core"
 	adapterhttp "github.com/pmaojo/kthulu-go/backend/internal/adapters/http"
+	"github.com/pmaojo/kthulu-go/backend/internal/domain/money"
 	"github.com/pmaojo/kthulu-go/backend/internal/usecase"
 )
 
@@ -13,6 +15,7 @@ import (
 var InvoiceModule = fx.Options(
 	// Use cases
 	fx.Provide(
+		newRoundingPolicy,
 		usecase.NewInvoiceUseCase,
 	),
 
@@ -23,6 +26,15 @@ var InvoiceModule = fx.Options(
 
 	// Register routes
 	fx.Invoke(func(handler *adapterhttp.InvoiceHandler, registry *RouteRegistry) {
-		registry.Register(handler)
+		registry.RegisterModule("invoices", handler)
 	}),
 )
+
+// newRoundingPolicy builds the invoice rounding policy from the configuration
+func newRoundingPolicy(cfg *core.Config) (money.Policy, error) {
+	mode, err := money.ParseRoundingMode(cfg.Money.RoundingMode)
+	if err != nil {
+		return money.Policy{}, err
+	}
+	return money.NewPolicy(mode, cfg.Money.CurrencyPrecision)
+}
@@ -41,5 +41,5 @@ func provideRouter(storage fosite.Storage, uc *usecase.OAuthUseCase) *modulesoau
 }
 
 func registerRoutes(rr *RouteRegistry, r *modulesoauth.Router) {
-	rr.Register(r)
+	rr.RegisterModule("oauth-sso", r)
 }
@@ -23,6 +23,6 @@ var OrganizationModule = fx.Options(
 
 	// Register routes
 	fx.Invoke(func(handler *adapterhttp.OrganizationHandler, registry *RouteRegistry) {
-		registry.Register(handler)
+		registry.RegisterModule("org", handler)
 	}),
 )
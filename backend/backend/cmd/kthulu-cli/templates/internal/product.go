@@ -23,6 +23,6 @@ var ProductModule = fx.Options(
 
 	// Register routes
 	fx.Invoke(func(handler *adapterhttp.ProductHandler, registry *RouteRegistry) {
-		registry.Register(handler)
+		registry.RegisterModule("products", handler)
 	}),
 )
@@ -1,12 +1,17 @@
 package modules
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
 	"github.com/go-chi/chi/v5"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
 	"go.uber.org/zap"
+
+	"github.com/pmaojo/kthulu-go/backend/internal/adapters/http/middleware"
 )
 
 type mockRegistrar struct{ called bool }
@@ -49,8 +54,49 @@ func TestRouteRegistry_Register_NilRegistrar(t *testing.T) {
 
 func TestRouteRegistry_RegisterAllRoutes_NilRegistrar(t *testing.T) {
 	rr := NewRouteRegistry(zap.NewNop())
-	rr.registrars = append(rr.registrars, nil)
+	rr.registrars = append(rr.registrars, moduleRegistrar{module: coreModule})
+
+	router := chi.NewRouter()
+	rr.RegisterAllRoutes(router)
+}
+
+func TestRouteRegistry_RegisterAllRoutes_LabelsMetricsWithModule(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+
+	rr := NewRouteRegistry(zap.NewNop())
+	rr.RegisterModule("contacts", &mockRegistrar{})
 
 	router := chi.NewRouter()
+	router.Use(middleware.MetricsMiddleware(provider))
 	rr.RegisterAllRoutes(router)
+
+	req := httptest.NewRequest(http.MethodGet, "/test", nil)
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatalf("collect metrics: %v", err)
+	}
+
+	var count int64
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name != "http_server_requests_total" {
+				continue
+			}
+			sum, ok := m.Data.(metricdata.Sum[int64])
+			if !ok {
+				t.Fatalf("unexpected data type %T", m.Data)
+			}
+			for _, dp := range sum.DataPoints {
+				if module, ok := dp.Attributes.Value("module"); ok && module.AsString() == "contacts" {
+					count += dp.Value
+				}
+			}
+		}
+	}
+	if count != 1 {
+		t.Fatalf("expected one request labeled module=contacts, got %d", count)
+	}
 }
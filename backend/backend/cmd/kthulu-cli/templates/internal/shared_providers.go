@@ -2,13 +2,21 @@
 package modules
 
 import (
+	"database/sql"
 	"sort"
 
 	"go.uber.org/fx"
 
+	"github.com/pmaojo/kthulu-go/backend/core"
+	"github.com/pmaojo/kthulu-go/backend/internal/infrastructure/cache"
 	"github.com/pmaojo/kthulu-go/backend/internal/infrastructure/db"
+	"github.com/pmaojo/kthulu-go/backend/internal/infrastructure/eventreminder"
 	"github.com/pmaojo/kthulu-go/backend/internal/infrastructure/notifier"
+	"github.com/pmaojo/kthulu-go/backend/internal/infrastructure/outbox"
+	"github.com/pmaojo/kthulu-go/backend/internal/infrastructure/pdf"
 	"github.com/pmaojo/kthulu-go/backend/internal/infrastructure/storage"
+	"github.com/pmaojo/kthulu-go/backend/internal/infrastructure/tokencleanup"
+	"github.com/pmaojo/kthulu-go/backend/internal/infrastructure/webhook"
 	"github.com/pmaojo/kthulu-go/backend/internal/domain/repository"
 )
 
@@ -19,13 +27,23 @@ const (
 	providerPermissionRepo   = "permission-repo"
 	providerRefreshTokenRepo = "refresh-token-repo"
 	providerTokenStorage     = "token-storage"
+	providerTokenDenylist    = "access-token-denylist"
+	providerAuthEventRepo    = "auth-event-repo"
+	providerTokenCleanup     = "refresh-token-cleanup"
 	providerOrganizationRepo = "organization-repo"
 	providerContactRepo      = "contact-repo"
 	providerProductRepo      = "product-repo"
 	providerInvoiceRepo      = "invoice-repo"
 	providerInventoryRepo    = "inventory-repo"
 	providerCalendarRepo     = "calendar-repo"
+	providerEventReminders   = "event-reminders"
 	providerNotification     = "notification"
+	providerAuditLog         = "audit-log"
+	providerWebhooks         = "webhooks"
+	providerInvoicePDF       = "invoice-pdf"
+	providerUnitOfWork       = "unit-of-work"
+	providerBlobStore        = "blob-store"
+	providerPaymentWebhooks  = "payment-webhook-events"
 )
 
 // providerFactories maps provider identifiers to their Fx option constructors.
@@ -35,26 +53,39 @@ var providerFactories = map[string]func() fx.Option{
 	providerPermissionRepo:   PermissionRepositoryProviders,
 	providerRefreshTokenRepo: RefreshTokenRepositoryProviders,
 	providerTokenStorage:     TokenStorageProviders,
+	providerTokenDenylist:    AccessTokenDenylistProviders,
+	providerAuthEventRepo:    AuthEventRepositoryProviders,
+	providerTokenCleanup:     RefreshTokenCleanupProviders,
 	providerOrganizationRepo: OrganizationRepositoryProviders,
 	providerContactRepo:      ContactRepositoryProviders,
 	providerProductRepo:      ProductRepositoryProviders,
 	providerInvoiceRepo:      InvoiceRepositoryProviders,
 	providerInventoryRepo:    InventoryRepositoryProviders,
 	providerCalendarRepo:     CalendarRepositoryProviders,
+	providerEventReminders:   EventReminderProviders,
 	providerNotification:     NotificationProviders,
+	providerAuditLog:         AuditLogProviders,
+	providerWebhooks:         WebhookProviders,
+	providerInvoicePDF:       InvoicePDFProviders,
+	providerUnitOfWork:       UnitOfWorkProviders,
+	providerBlobStore:        BlobStoreProviders,
+	providerPaymentWebhooks:  PaymentWebhookEventRepositoryProviders,
 }
 
 // moduleProviderMap declares the repositories required by each builtin module.
 var moduleProviderMap = map[string][]string{
-	"auth":         {providerUserRepo, providerRoleRepo, providerRefreshTokenRepo, providerTokenStorage, providerNotification},
-	"user":         {providerUserRepo, providerRoleRepo},
+	"auth":         {providerUserRepo, providerRoleRepo, providerRefreshTokenRepo, providerTokenStorage, providerTokenDenylist, providerAuthEventRepo, providerTokenCleanup, providerNotification},
+	"user":         {providerUserRepo, providerRoleRepo, providerTokenDenylist},
 	"access":       {providerUserRepo, providerRoleRepo, providerPermissionRepo},
-	"organization": {providerOrganizationRepo, providerUserRepo, providerNotification},
-	"contact":      {providerContactRepo},
-	"product":      {providerProductRepo},
-	"invoice":      {providerInvoiceRepo},
-	"inventory":    {providerInventoryRepo, providerProductRepo, providerUserRepo},
-	"calendar":     {providerCalendarRepo, providerUserRepo},
+	"organization": {providerOrganizationRepo, providerUserRepo, providerNotification, providerBlobStore},
+	"contact":      {providerContactRepo, providerOrganizationRepo, providerCalendarRepo},
+	"product":      {providerProductRepo, providerOrganizationRepo, providerAuditLog},
+	"invoice":      {providerInvoiceRepo, providerContactRepo, providerOrganizationRepo, providerProductRepo, providerAuditLog, providerWebhooks, providerInvoicePDF, providerNotification, providerUnitOfWork, providerCalendarRepo},
+	"inventory":    {providerInventoryRepo, providerProductRepo, providerUserRepo, providerAuditLog},
+	"calendar":     {providerCalendarRepo, providerUserRepo, providerOrganizationRepo, providerContactRepo, providerInvoiceRepo, providerEventReminders, providerNotification},
+	"graphql":      {providerOrganizationRepo},
+	"grpc":         {providerOrganizationRepo},
+	"payments":     {providerInvoiceRepo, providerOrganizationRepo, providerPaymentWebhooks},
 }
 
 // CoreRepositoryProviders returns fx.Options for the core repositories.
@@ -66,6 +97,8 @@ func CoreRepositoryProviders() fx.Option {
 		RoleRepositoryProviders(),
 		RefreshTokenRepositoryProviders(),
 		TokenStorageProviders(),
+		AccessTokenDenylistProviders(),
+		AuthEventRepositoryProviders(),
 	)
 }
 
@@ -82,6 +115,12 @@ func SharedRepositoryProviders() fx.Option {
 		InventoryRepositoryProviders(),
 		CalendarRepositoryProviders(),
 		NotificationProviders(),
+		AuditLogProviders(),
+		WebhookProviders(),
+		InvoicePDFProviders(),
+		UnitOfWorkProviders(),
+		BlobStoreProviders(),
+		PaymentWebhookEventRepositoryProviders(),
 	)
 }
 
@@ -133,6 +172,11 @@ func RefreshTokenRepositoryProviders() fx.Option {
 	)
 }
 
+// RefreshTokenCleanupProviders runs the job deleting expired refresh tokens.
+func RefreshTokenCleanupProviders() fx.Option {
+	return tokencleanup.Module
+}
+
 // TokenStorageProviders exposes the token storage implementation.
 func TokenStorageProviders() fx.Option {
 	return fx.Options(
@@ -147,6 +191,30 @@ func TokenStorageProviders() fx.Option {
 	)
 }
 
+// AccessTokenDenylistProviders exposes the access token denylist implementation.
+func AccessTokenDenylistProviders() fx.Option {
+	return fx.Options(
+		fx.Provide(
+			fx.Annotate(
+				storage.NewMemoryAccessTokenDenylist,
+				fx.As(new(repository.AccessTokenDenylist)),
+			),
+		),
+	)
+}
+
+// AuthEventRepositoryProviders exposes the authentication event repository implementation.
+func AuthEventRepositoryProviders() fx.Option {
+	return fx.Options(
+		fx.Provide(
+			fx.Annotate(
+				db.NewAuthEventRepository,
+				fx.As(new(repository.AuthEventRepository)),
+			),
+		),
+	)
+}
+
 // OrganizationRepositoryProviders exposes organization-related repositories.
 func OrganizationRepositoryProviders() fx.Option {
 	return fx.Options(
@@ -179,30 +247,73 @@ func ContactRepositoryProviders() fx.Option {
 	)
 }
 
-// ProductRepositoryProviders exposes the product repository implementation.
+// ProductRepositoryProviders exposes the product repository implementation,
+// behind a read-through cache when PRODUCT_CACHE_TTL is set.
 func ProductRepositoryProviders() fx.Option {
 	return fx.Options(
 		fx.Provide(
 			fx.Annotate(
-				db.NewProductRepository,
-				fx.As(new(repository.ProductRepository)),
+				newProductRepository,
+				fx.ParamTags(``, ``, ``, `optional:"true"`, `optional:"true"`, `optional:"true"`),
 			),
 		),
 	)
 }
 
+// newProductRepository creates the product repository, wrapped with the
+// shared cache when product caching is enabled. Without a shared cache the
+// products are cached in memory.
+func newProductRepository(cfg *core.Config, sqlDB *sql.DB, logger core.Logger, audit repository.AuditLogger, replica *core.ReadReplica, shared repository.Cache) repository.ProductRepository {
+	products := db.NewProductRepository(sqlDB, logger, audit, replica)
+	if cfg.Cache.ProductTTL <= 0 {
+		return products
+	}
+	if shared == nil {
+		shared = cache.NewMemoryCache()
+	}
+	return cache.NewProductRepository(products, shared, cfg.Cache.ProductTTL, logger)
+}
+
 // InvoiceRepositoryProviders exposes the invoice repository implementation.
 func InvoiceRepositoryProviders() fx.Option {
 	return fx.Options(
 		fx.Provide(
 			fx.Annotate(
 				db.NewInvoiceRepository,
+				fx.ParamTags(``, ``, `optional:"true"`, `optional:"true"`),
 				fx.As(new(repository.InvoiceRepository)),
 			),
 		),
 	)
 }
 
+// PaymentWebhookEventRepositoryProviders exposes the store of processed
+// payment gateway webhook events.
+func PaymentWebhookEventRepositoryProviders() fx.Option {
+	return fx.Options(
+		fx.Provide(
+			fx.Annotate(
+				db.NewPaymentWebhookEventRepository,
+				fx.As(new(repository.PaymentWebhookEventRepository)),
+			),
+		),
+	)
+}
+
+// UnitOfWorkProviders exposes the unit of work for use cases that write
+// through several repositories in one transaction.
+func UnitOfWorkProviders() fx.Option {
+	return fx.Options(
+		fx.Provide(
+			fx.Annotate(
+				db.NewUnitOfWork,
+				fx.ParamTags(``, ``, ``, `optional:"true"`),
+				fx.As(new(repository.UnitOfWork)),
+			),
+		),
+	)
+}
+
 // InventoryRepositoryProviders exposes the inventory repository implementation.
 func InventoryRepositoryProviders() fx.Option {
 	return fx.Options(
@@ -227,6 +338,58 @@ func CalendarRepositoryProviders() fx.Option {
 	)
 }
 
+// EventReminderProviders runs the job emailing due calendar event reminders.
+func EventReminderProviders() fx.Option {
+	return eventreminder.Module
+}
+
+// AuditLogProviders exposes the DB-backed audit logger used by mutating repositories.
+func AuditLogProviders() fx.Option {
+	return fx.Options(
+		fx.Provide(
+			fx.Annotate(
+				db.NewAuditLogger,
+				fx.As(new(repository.AuditLogger)),
+			),
+		),
+	)
+}
+
+// WebhookProviders exposes the webhook repository, the dispatcher that
+// publishes domain events to organization webhooks and the outbox relay
+// feeding it.
+func WebhookProviders() fx.Option {
+	return fx.Options(
+		fx.Provide(
+			fx.Annotate(
+				db.NewWebhookRepository,
+				fx.As(new(repository.WebhookRepository)),
+			),
+			fx.Annotate(
+				db.NewOutboxRepository,
+				fx.As(new(repository.OutboxRepository)),
+			),
+		),
+		webhook.Module,
+		outbox.Module,
+	)
+}
+
+// InvoicePDFProviders exposes the renderer used to send invoices as PDFs.
+func InvoicePDFProviders() fx.Option {
+	return fx.Options(
+		fx.Provide(pdf.NewInvoiceRenderer),
+	)
+}
+
+// BlobStoreProviders exposes the blob store selected by the storage
+// configuration.
+func BlobStoreProviders() fx.Option {
+	return fx.Options(
+		fx.Provide(storage.NewBlobStore),
+	)
+}
+
 // NotificationProviders exposes notification infrastructure implementations.
 func NotificationProviders() fx.Option {
 	return notifier.NotifierModule
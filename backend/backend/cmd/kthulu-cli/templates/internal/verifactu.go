@@ -36,6 +36,6 @@ var VerifactuModule = fx.Options(
 
 	// Register routes
 	fx.Invoke(func(handler *adapterhttp.VerifactuHandler, registry *RouteRegistry) {
-		registry.Register(handler)
+		registry.RegisterModule("verifactu", handler)
 	}),
 )
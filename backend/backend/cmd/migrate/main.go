@@ -7,14 +7,19 @@ import (
 	"log"
 	"os"
 	"strconv"
+	"time"
 
 	"github.com/pmaojo/kthulu-go/backend/core"
+	// Register the Go migrations applied alongside the SQL ones
+	_ "github.com/pmaojo/kthulu-go/backend/migrations"
 )
 
 func main() {
 	var (
 		action  = flag.String("action", "up", "Migration action: up, down, reset, status, version")
 		version = flag.String("version", "", "Target version for migration (optional)")
+		verbose = flag.Bool("verbose", false, "List every migration with its applied state (status action)")
+		dryRun  = flag.Bool("dry-run", false, "Print the SQL that would run without executing it (up action)")
 	)
 	flag.Parse()
 
@@ -44,7 +49,21 @@ func main() {
 	// Execute migration action
 	switch *action {
 	case "up":
-		if err := core.Migrate(db, zapLogger); err != nil {
+		if *dryRun {
+			pending, err := core.MigrateDryRun(db, zapLogger)
+			if err != nil {
+				logger.Fatal("Migration dry run failed", "error", err)
+			}
+			fmt.Printf("%d pending migrations (dry run, nothing applied)\n", len(pending))
+			for _, migration := range pending {
+				fmt.Printf("\n-- %s\n", migration.Name)
+				for _, stmt := range migration.Statements {
+					fmt.Println(stmt)
+				}
+			}
+			break
+		}
+		if err := core.MigrateWithLockTimeout(db, cfg.Database.MigrationLockTimeout, zapLogger); err != nil {
 			logger.Fatal("Migration failed", "error", err)
 		}
 	case "down":
@@ -61,6 +80,19 @@ func main() {
 			logger.Fatal("Failed to get migration status", "error", err)
 		}
 		fmt.Printf("Current database version: %d\n", version)
+		if *verbose {
+			states, err := core.GetMigrationDetails(db)
+			if err != nil {
+				logger.Fatal("Failed to get migration details", "error", err)
+			}
+			for _, state := range states {
+				appliedAt := "pending"
+				if state.Applied {
+					appliedAt = "applied " + state.AppliedAt.Format(time.RFC3339)
+				}
+				fmt.Printf("  %-40s %s\n", state.Name, appliedAt)
+			}
+		}
 	case "version":
 		if *version == "" {
 			logger.Fatal("Version parameter is required for version action")
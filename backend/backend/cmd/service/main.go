@@ -44,7 +44,6 @@ package main
 import (
 	"context"
 	"database/sql"
-	"encoding/hex"
 	"flag"
 	"fmt"
 	"net/http"
@@ -63,15 +62,21 @@ import (
 	"github.com/pmaojo/kthulu-go/backend/core"
 	"github.com/pmaojo/kthulu-go/backend/core/metrics"
 	"github.com/pmaojo/kthulu-go/backend/internal/adapters/http/middleware"
-	db "github.com/pmaojo/kthulu-go/backend/internal/infrastructure/db"
 	"github.com/pmaojo/kthulu-go/backend/internal/adapters/http/modules"
 	flagcfg "github.com/pmaojo/kthulu-go/backend/internal/adapters/http/modules/flags"
 	vf "github.com/pmaojo/kthulu-go/backend/internal/adapters/http/modules/verifactu"
+	"github.com/pmaojo/kthulu-go/backend/internal/domain/repository"
+	"github.com/pmaojo/kthulu-go/backend/internal/infrastructure/cache"
+	"github.com/pmaojo/kthulu-go/backend/internal/infrastructure/dbmonitor"
 	"github.com/pmaojo/kthulu-go/backend/internal/infrastructure/observability"
+	// Register the Go migrations applied alongside the SQL ones
+	_ "github.com/pmaojo/kthulu-go/backend/migrations"
 )
 
-// newRouter constructs the application's HTTP router with middleware.
-func newRouter(p struct {
+// routerParams are the dependencies of newRouter. Metrics is optional and
+// falls back to a no-op provider; without a cache idempotency keys are
+// ignored.
+type routerParams struct {
 	fx.In
 	RouteRegistry *modules.RouteRegistry
 	DB            *sql.DB
@@ -79,9 +84,16 @@ func newRouter(p struct {
 	Config        *core.Config
 	TokenManager  core.TokenManager
 	Flags         flagcfg.HeaderConfig
-	Metrics       *metrics.PrometheusMetrics
-}) chi.Router {
+	Metrics       *metrics.PrometheusMetrics `optional:"true"`
+	Cache         repository.Cache           `optional:"true"`
+}
+
+// newRouter constructs the application's HTTP router with middleware.
+func newRouter(p routerParams) chi.Router {
 	r := chi.NewRouter()
+	if p.Metrics == nil {
+		p.Metrics = metrics.NewNoopMetrics()
+	}
 
 	allowedOrigins := []string{
 		"http://localhost:5173",
@@ -104,8 +116,9 @@ func newRouter(p struct {
 					w.Header().Set("Access-Control-Allow-Origin", origin)
 					w.Header().Set("Vary", "Origin")
 					w.Header().Set("Access-Control-Allow-Credentials", "true")
-					w.Header().Set("Access-Control-Allow-Headers", "Accept, Authorization, Content-Type, X-CSRF-Token")
+					w.Header().Set("Access-Control-Allow-Headers", "Accept, Authorization, Content-Type, Idempotency-Key, If-None-Match, X-CSRF-Token")
 					w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
+					w.Header().Set("Access-Control-Expose-Headers", "ETag, Idempotent-Replayed, Link, X-Total-Count, X-Page")
 
 					if req.Method == http.MethodOptions {
 						w.WriteHeader(http.StatusNoContent)
@@ -120,19 +133,33 @@ func newRouter(p struct {
 	r.Use(middleware.TraceIDMiddleware)
 	r.Use(middleware.JWTTraceMiddleware(p.TokenManager))
 	r.Use(chimiddleware.RequestID)
-	r.Use(middleware.LoggingMiddleware(p.Logger))
-	if p.Metrics != nil {
-		r.Use(middleware.MetricsMiddleware(p.Metrics.Provider))
-	}
+	r.Use(middleware.LoggingMiddlewareWithOptions(p.Logger, middleware.LoggingOptions{
+		LogRequestBodies: p.Config.Logging.LogRequestBodies,
+		Redactor:         middleware.NewBodyRedactor(p.Config.Logging.RedactFields...),
+	}))
+	r.Use(middleware.MetricsMiddleware(p.Metrics.Provider))
 	r.Use(middleware.RecoveryMiddleware(p.Logger))
-	r.Use(middleware.AdvancedHealthMiddleware(p.DB, p.Logger, p.Config.Version))
+	r.Use(middleware.AdvancedHealthMiddlewareWithOptions(p.DB, p.Logger, p.Config.Version, middleware.HealthCheckOptions{
+		Query:   p.Config.Database.HealthCheckQuery,
+		Timeout: p.Config.Database.HealthCheckTimeout,
+	}))
 	r.Use(middleware.FlagsMiddleware(p.Flags))
 	limiter := rate.NewLimiter(rate.Limit(p.Config.RateLimit.RequestsPerSecond), p.Config.RateLimit.Burst)
 	r.Use(middleware.RateLimitMiddleware(limiter))
-	r.Use(chimiddleware.Compress(5))
+	r.Use(middleware.CompressMiddleware(middleware.CompressionOptions{Level: 5}))
+	// Inside compression so stored responses are kept uncompressed
+	var idempotency *middleware.IdempotencyStore
+	if p.Cache != nil && p.Config.Cache.IdempotencyTTL > 0 {
+		idempotency = middleware.NewIdempotencyStore(p.Cache, p.Config.Cache.IdempotencyTTL)
+	}
+	r.Use(middleware.IdempotencyMiddleware(idempotency))
+	// Answer HEAD with the GET routes so list headers can be fetched alone
+	r.Use(chimiddleware.GetHead)
 
 	// Expose Prometheus metrics endpoint before module routes
-	r.Handle("/metrics", p.Metrics.Handler)
+	if p.Metrics.Enabled() {
+		r.Handle("/metrics", p.Metrics.Handler)
+	}
 
 	// Register all routes from modules
 	p.RouteRegistry.RegisterAllRoutes(r)
@@ -140,7 +167,7 @@ func newRouter(p struct {
 }
 
 // newHTTPServer prepares the HTTP server instance with configuration.
-func newHTTPServer(r chi.Router, cfg *core.Config, logger observability.Logger) *http.Server {
+func newHTTPServer(r chi.Router, tracker *middleware.InFlightTracker, cfg *core.Config, logger observability.Logger) *http.Server {
 	// Validate configuration
 	if cfg.Server.Addr == "" {
 		logger.Fatal("Server address not configured")
@@ -148,7 +175,7 @@ func newHTTPServer(r chi.Router, cfg *core.Config, logger observability.Logger)
 
 	server := &http.Server{
 		Addr:         cfg.Server.Addr,
-		Handler:      r,
+		Handler:      tracker.Middleware(r),
 		ReadTimeout:  cfg.Server.ReadTimeout,
 		WriteTimeout: cfg.Server.WriteTimeout,
 		IdleTimeout:  cfg.Server.IdleTimeout,
@@ -159,6 +186,7 @@ func newHTTPServer(r chi.Router, cfg *core.Config, logger observability.Logger)
 		zap.Duration("read_timeout", server.ReadTimeout),
 		zap.Duration("write_timeout", server.WriteTimeout),
 		zap.Duration("idle_timeout", server.IdleTimeout),
+		zap.Duration("shutdown_grace_period", cfg.Server.ShutdownGracePeriod),
 	)
 
 	return server
@@ -176,8 +204,8 @@ func validateStartup(db *sql.DB, cfg *core.Config, logger observability.Logger)
 	logger.Info("Database connection validated")
 
 	// Validate critical configuration
-	if cfg.JWT.Secret == "" {
-		return fmt.Errorf("JWT secret not configured")
+	if err := validateJWT(cfg); err != nil {
+		return err
 	}
 
 	if cfg.Server.Addr == "" {
@@ -188,11 +216,37 @@ func validateStartup(db *sql.DB, cfg *core.Config, logger observability.Logger)
 	return nil
 }
 
+// validateJWT checks the signing material the configured algorithm needs:
+// a shared secret for HS256 and a parseable private key for RS256. Refresh
+// tokens are always signed with HS256 and need their own secret.
+func validateJWT(cfg *core.Config) error {
+	switch cfg.JWT.Algorithm {
+	case "", core.JWTAlgorithmHS256:
+		if cfg.JWT.Secret == "" {
+			return fmt.Errorf("JWT secret not configured")
+		}
+	case core.JWTAlgorithmRS256:
+		if cfg.JWT.PrivateKey == "" {
+			return fmt.Errorf("JWT private key not configured for %s", core.JWTAlgorithmRS256)
+		}
+		if _, err := core.NewJWT(cfg); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unsupported JWT algorithm %q", cfg.JWT.Algorithm)
+	}
+
+	if cfg.JWT.RefreshSecret == "" {
+		return fmt.Errorf("JWT refresh secret not configured")
+	}
+	return nil
+}
+
 // registerHooks wires server lifecycle to Fx with proper logging and graceful shutdown.
-func registerHooks(lc fx.Lifecycle, srv *http.Server, db *sql.DB, logger observability.Logger) {
+func registerHooks(lc fx.Lifecycle, srv *http.Server, tracker *middleware.InFlightTracker, db *sql.DB, replica *core.ReadReplica, cfg *core.Config, logger observability.Logger) {
 	lc.Append(fx.Hook{
 		OnStart: func(ctx context.Context) error {
-			if err := core.Migrate(db, observability.GetZapLogger(logger)); err != nil {
+			if err := core.MigrateWithLockTimeout(db, cfg.Database.MigrationLockTimeout, observability.GetZapLogger(logger)); err != nil {
 				logger.Error("Database migration failed", zap.Error(err))
 				return err
 			}
@@ -221,18 +275,10 @@ func registerHooks(lc fx.Lifecycle, srv *http.Server, db *sql.DB, logger observa
 		OnStop: func(ctx context.Context) error {
 			logger.Info("Initiating graceful shutdown")
 
-			// Create a context with timeout for shutdown
-			shutdownCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
-			defer cancel()
-
-			// Shutdown HTTP server gracefully
+			// Shutdown HTTP server gracefully, force closing after the grace period
 			logger.Info("Shutting down HTTP server")
-			if err := srv.Shutdown(shutdownCtx); err != nil {
+			if err := shutdownServer(ctx, srv, tracker, cfg.Server.ShutdownGracePeriod, logger); err != nil {
 				logger.Error("Failed to shutdown HTTP server gracefully", zap.Error(err))
-				// Force close if graceful shutdown fails
-				if closeErr := srv.Close(); closeErr != nil {
-					logger.Error("Failed to force close HTTP server", zap.Error(closeErr))
-				}
 				return err
 			}
 			logger.Info("HTTP server shutdown completed")
@@ -244,6 +290,12 @@ func registerHooks(lc fx.Lifecycle, srv *http.Server, db *sql.DB, logger observa
 				return err
 			}
 			logger.Info("Database connection closed")
+			if replica != nil {
+				if err := core.CloseDB(replica.DB, observability.GetZapLogger(logger)); err != nil {
+					logger.Error("Failed to close read replica connection", zap.Error(err))
+					return err
+				}
+			}
 
 			logger.Info("Graceful shutdown completed successfully")
 			return nil
@@ -269,26 +321,24 @@ func run() error {
 	registry := modules.NewRegistry()
 	modules.RegisterBuiltinModules(registry)
 
-	// Only load the modules needed for the current web UI experience
-	coreModules := []string{"projects", "templates", "modules", "static", "health"}
-
+	// Load the default web UI modules plus any extras requested via KTHULU_MODULES
 	builder := modules.NewModuleSetBuilder(registry)
-	for _, moduleName := range coreModules {
-		if _, ok := registry.GetModule(moduleName); ok {
-			builder.WithModule(moduleName)
-		} else {
-			fmt.Printf("Module not found: %s\n", moduleName)
-		}
+	for _, moduleName := range selectModules(registry, defaultModules, os.Getenv(modulesEnv), os.Stdout) {
+		builder.WithModule(moduleName)
 	}
 
 	moduleSet := builder.Build()
 
 	app := fx.New(
+		// Leave room after draining requests for the remaining stop hooks
+		fx.StopTimeout(cfg.Server.ShutdownGracePeriod+15*time.Second),
 		fx.Supply(cfg),
 		fx.Supply(moduleSet),
 
 		core.Module,
 		observability.Module,
+		dbmonitor.Module,
+		cache.Module,
 		modules.FlagsModule,
 
 		moduleSet.Build([]string{}),
@@ -296,6 +346,7 @@ func run() error {
 
 		fx.Provide(
 			newRouter,
+			middleware.NewInFlightTracker,
 			newHTTPServer,
 		),
 
@@ -312,7 +363,7 @@ func run() error {
 			}
 			lc.Append(fx.Hook{OnStart: func(ctx context.Context) error {
 				if params.Config.VerifactuMode == "real-time" {
-					return params.Repo.SetLiveMode(ctx, time.Now().Year(), true)
+					return params.Repo.SetLiveMode(ctx, time.Now().Year(), true, "startup:real-time")
 				}
 				return nil
 			}})
@@ -341,12 +392,19 @@ func main() {
 	if len(os.Args) > 1 && os.Args[1] == "verifactu" && len(os.Args) > 2 && os.Args[2] == "export" {
 		fs := flag.NewFlagSet("export", flag.ExitOnError)
 		orgID := fs.Int("org", 0, "Organization ID")
+		format := fs.String("format", "zip", "Export format: xml or zip")
+		dryRun := fs.Bool("dry-run", false, "Print an export summary without writing the file")
 		_ = fs.Parse(os.Args[3:])
 		if *orgID == 0 {
 			fmt.Fprintln(os.Stderr, "--org is required")
 			os.Exit(1)
 		}
-		if err := exportVerifactu(*orgID); err != nil {
+		if *format != "zip" && *format != "xml" {
+			fmt.Fprintln(os.Stderr, "--format must be xml or zip")
+			os.Exit(1)
+		}
+		opts := verifactuExportOptions{OrgID: *orgID, Format: *format, DryRun: *dryRun}
+		if err := exportVerifactu(opts); err != nil {
 			fmt.Fprintln(os.Stderr, err)
 			os.Exit(1)
 		}
@@ -356,41 +414,3 @@ func main() {
 		os.Exit(1)
 	}
 }
-
-// exportVerifactu performs the export use case from the CLI.
-func exportVerifactu(orgID int) error {
-	ctx := context.Background()
-	cfg, err := core.NewConfig()
-	if err != nil {
-		return err
-	}
-	logger, err := observability.NewLogger(cfg)
-	if err != nil {
-		return err
-	}
-	defer logger.Sync()
-
-	zapLogger := observability.GetZapLogger(logger)
-
-	dbConn, err := core.NewDB(cfg, zapLogger)
-	if err != nil {
-		return err
-	}
-	defer core.CloseDB(dbConn, zapLogger)
-
-	repo := db.NewVerifactuRepository(dbConn)
-	signer := vf.NewHMACSigner([]byte(os.Getenv("VERIFACTU_SIGN_KEY")))
-	svc := vf.NewService(repo, signer, cfg.VerifactuSIFCode, cfg.VerifactuMode)
-
-	data, sig, err := svc.ExportRecords(ctx, orgID)
-	if err != nil {
-		return err
-	}
-
-	file := fmt.Sprintf("verifactu_%d.zip", orgID)
-	if err := os.WriteFile(file, data, 0o644); err != nil {
-		return err
-	}
-	fmt.Fprintf(os.Stdout, "exported %s signature %s\n", file, hex.EncodeToString(sig))
-	return nil
-}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+
+	"github.com/pmaojo/kthulu-go/backend/core"
+)
+
+func TestValidateJWTChecksTheMaterialOfTheAlgorithm(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	privateKey := string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}))
+
+	for name, tc := range map[string]struct {
+		jwt   core.JWTConfig
+		valid bool
+	}{
+		"hs256 with secret":      {core.JWTConfig{Algorithm: core.JWTAlgorithmHS256, Secret: "s", RefreshSecret: "r"}, true},
+		"default with secret":    {core.JWTConfig{Secret: "s", RefreshSecret: "r"}, true},
+		"hs256 without secret":   {core.JWTConfig{Algorithm: core.JWTAlgorithmHS256, RefreshSecret: "r"}, false},
+		"rs256 with key":         {core.JWTConfig{Algorithm: core.JWTAlgorithmRS256, PrivateKey: privateKey, RefreshSecret: "r"}, true},
+		"rs256 without key":      {core.JWTConfig{Algorithm: core.JWTAlgorithmRS256, Secret: "s", RefreshSecret: "r"}, false},
+		"rs256 with invalid key": {core.JWTConfig{Algorithm: core.JWTAlgorithmRS256, PrivateKey: "not a key", RefreshSecret: "r"}, false},
+		"without refresh secret": {core.JWTConfig{Secret: "s"}, false},
+		"unknown algorithm":      {core.JWTConfig{Algorithm: "ES256", Secret: "s", RefreshSecret: "r"}, false},
+	} {
+		err := validateJWT(&core.Config{JWT: tc.jwt})
+		if tc.valid && err != nil {
+			t.Errorf("%s: unexpected error %v", name, err)
+		}
+		if !tc.valid && err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/pmaojo/kthulu-go/backend/internal/adapters/http/modules"
+)
+
+// modulesEnv lists extra modules to enable on top of the defaults.
+const modulesEnv = "KTHULU_MODULES"
+
+// defaultModules are the modules needed for the current web UI experience.
+var defaultModules = []string{"projects", "templates", "modules", "static", "health"}
+
+// moduleLookup is the subset of the module registry used to validate names.
+type moduleLookup interface {
+	GetModule(name string) (modules.Module, bool)
+}
+
+// selectModules merges the defaults with the comma-separated extra modules
+// and drops any name that is not registered. Unknown names are reported on
+// out. The result keeps the defaults first and contains no duplicates.
+func selectModules(registry moduleLookup, defaults []string, extra string, out io.Writer) []string {
+	names := append([]string{}, defaults...)
+	for _, name := range strings.Split(extra, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+
+	seen := make(map[string]bool, len(names))
+	selected := make([]string, 0, len(names))
+	for _, name := range names {
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		if _, ok := registry.GetModule(name); !ok {
+			fmt.Fprintf(out, "Module not found: %s\n", name)
+			continue
+		}
+		selected = append(selected, name)
+	}
+
+	return selected
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/pmaojo/kthulu-go/backend/internal/adapters/http/modules"
+)
+
+type fakeRegistry map[string]bool
+
+func (f fakeRegistry) GetModule(name string) (modules.Module, bool) {
+	if !f[name] {
+		return modules.Module{}, false
+	}
+	return modules.Module{Name: name}, true
+}
+
+func TestSelectModulesMergesExtras(t *testing.T) {
+	registry := fakeRegistry{"health": true, "static": true, "contacts": true, "invoices": true}
+	out := new(bytes.Buffer)
+
+	got := selectModules(registry, []string{"health", "static"}, " contacts, invoices ,,health", out)
+
+	want := []string{"health", "static", "contacts", "invoices"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	if out.Len() != 0 {
+		t.Fatalf("unexpected output: %s", out.String())
+	}
+}
+
+func TestSelectModulesSkipsUnknown(t *testing.T) {
+	registry := fakeRegistry{"health": true}
+	out := new(bytes.Buffer)
+
+	got := selectModules(registry, []string{"health", "projects"}, "bogus", out)
+
+	if !reflect.DeepEqual(got, []string{"health"}) {
+		t.Fatalf("expected only health, got %v", got)
+	}
+	for _, name := range []string{"projects", "bogus"} {
+		if !strings.Contains(out.String(), "Module not found: "+name) {
+			t.Fatalf("expected %s to be reported, got %q", name, out.String())
+		}
+	}
+}
+
+func TestSelectModulesWithoutEnv(t *testing.T) {
+	registry := fakeRegistry{"health": true, "static": true}
+
+	got := selectModules(registry, []string{"health", "static"}, "", new(bytes.Buffer))
+
+	if !reflect.DeepEqual(got, []string{"health", "static"}) {
+		t.Fatalf("expected defaults, got %v", got)
+	}
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+
+	"github.com/pmaojo/kthulu-go/backend/core"
+	"github.com/pmaojo/kthulu-go/backend/internal/adapters/http/modules"
+	"github.com/pmaojo/kthulu-go/backend/internal/infrastructure/observability"
+)
+
+// pingRoutes serves GET /ping
+type pingRoutes struct{}
+
+func (pingRoutes) RegisterRoutes(r chi.Router) {
+	r.Get("/ping", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+func TestNewRouterWithMetricsDisabled(t *testing.T) {
+	db, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	cfg := &core.Config{
+		JWT:           core.JWTConfig{Secret: "secret"},
+		RateLimit:     core.RateLimitConfig{RequestsPerSecond: 100, Burst: 100},
+		Observability: core.ObservabilityConfig{MetricsExporter: core.MetricsExporterNone},
+	}
+	tokens, err := core.NewJWT(cfg)
+	if err != nil {
+		t.Fatalf("jwt: %v", err)
+	}
+	disabled, err := observability.NewMetricsProvider(cfg)
+	if err != nil {
+		t.Fatalf("metrics provider: %v", err)
+	}
+	if disabled.Enabled() {
+		t.Fatalf("expected METRICS_EXPORTER=none to disable metrics")
+	}
+
+	for name, m := range map[string]*routerParams{
+		"nil metrics":  {},
+		"noop metrics": {Metrics: disabled},
+	} {
+		registry := modules.NewRouteRegistry(zap.NewNop())
+		registry.Register(pingRoutes{})
+		m.RouteRegistry = registry
+		m.DB = db
+		m.Logger = observability.NewLoggerFromZap(zap.NewNop())
+		m.Config = cfg
+		m.TokenManager = tokens
+		router := newRouter(*m)
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/ping", nil))
+		if rr.Code != http.StatusNoContent {
+			t.Fatalf("%s: expected 204 from /ping, got %d", name, rr.Code)
+		}
+
+		rr = httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+		if rr.Code != http.StatusNotFound {
+			t.Fatalf("%s: expected /metrics to be absent, got %d", name, rr.Code)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/pmaojo/kthulu-go/backend/internal/adapters/http/middleware"
+	"github.com/pmaojo/kthulu-go/backend/internal/infrastructure/observability"
+)
+
+// shutdownServer stops accepting connections and waits up to grace for
+// in-flight requests to finish. Requests still running after that are cut
+// off by force-closing the server.
+func shutdownServer(ctx context.Context, srv *http.Server, tracker *middleware.InFlightTracker, grace time.Duration, logger observability.Logger) error {
+	shutdownCtx, cancel := context.WithTimeout(ctx, grace)
+	defer cancel()
+
+	logger.Info("Draining in-flight requests",
+		zap.Duration("grace_period", grace),
+		zap.Int64("in_flight", tracker.Count()),
+	)
+
+	err := srv.Shutdown(shutdownCtx)
+	if err == nil {
+		return nil
+	}
+
+	logger.Warn("Grace period expired, force closing HTTP server",
+		zap.Int64("in_flight", tracker.Count()),
+		zap.Error(err),
+	)
+	if closeErr := srv.Close(); closeErr != nil {
+		return errors.Join(err, closeErr)
+	}
+	return err
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/pmaojo/kthulu-go/backend/internal/adapters/http/middleware"
+	"github.com/pmaojo/kthulu-go/backend/internal/infrastructure/observability"
+)
+
+// startSlowServer serves a handler that takes delay to respond and returns
+// once a request to it is in flight.
+func startSlowServer(t *testing.T, delay time.Duration) (*http.Server, *middleware.InFlightTracker, <-chan error) {
+	t.Helper()
+
+	tracker := middleware.NewInFlightTracker()
+	release := make(chan struct{})
+	srv := &http.Server{Handler: tracker.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(delay):
+			w.WriteHeader(http.StatusOK)
+		case <-release:
+		}
+	}))}
+	t.Cleanup(func() { close(release) })
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	go srv.Serve(ln)
+
+	result := make(chan error, 1)
+	go func() {
+		resp, err := http.Get("http://" + ln.Addr().String())
+		if err == nil {
+			resp.Body.Close()
+		}
+		result <- err
+	}()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for tracker.Count() == 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("request never reached the handler")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	return srv, tracker, result
+}
+
+func TestShutdownServerWaitsForInFlightRequest(t *testing.T) {
+	srv, tracker, result := startSlowServer(t, 200*time.Millisecond)
+
+	if err := shutdownServer(context.Background(), srv, tracker, 2*time.Second, observability.NewNopLogger()); err != nil {
+		t.Fatalf("expected clean shutdown, got %v", err)
+	}
+	if err := <-result; err != nil {
+		t.Fatalf("in-flight request should complete, got %v", err)
+	}
+	if n := tracker.Count(); n != 0 {
+		t.Fatalf("expected no requests in flight, got %d", n)
+	}
+}
+
+func TestShutdownServerForceClosesAfterGrace(t *testing.T) {
+	srv, tracker, result := startSlowServer(t, time.Minute)
+
+	start := time.Now()
+	err := shutdownServer(context.Background(), srv, tracker, 100*time.Millisecond, observability.NewNopLogger())
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected grace period to expire, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("shutdown took %s, expected it to stop after the grace period", elapsed)
+	}
+	if err := <-result; err == nil {
+		t.Fatalf("expected the slow request to be cut off")
+	}
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/pmaojo/kthulu-go/backend/core"
+	vf "github.com/pmaojo/kthulu-go/backend/internal/adapters/http/modules/verifactu"
+	"github.com/pmaojo/kthulu-go/backend/internal/domain/repository"
+	db "github.com/pmaojo/kthulu-go/backend/internal/infrastructure/db"
+	"github.com/pmaojo/kthulu-go/backend/internal/infrastructure/observability"
+	"github.com/pmaojo/kthulu-go/backend/internal/infrastructure/storage"
+)
+
+// verifactuExportOptions holds the flags of the `verifactu export` subcommand.
+type verifactuExportOptions struct {
+	OrgID  int
+	Format string
+	DryRun bool
+}
+
+// exportVerifactu performs the export use case from the CLI.
+func exportVerifactu(opts verifactuExportOptions) error {
+	ctx := context.Background()
+	cfg, err := core.NewConfig()
+	if err != nil {
+		return err
+	}
+	logger, err := observability.NewLogger(cfg)
+	if err != nil {
+		return err
+	}
+	defer logger.Sync()
+
+	zapLogger := observability.GetZapLogger(logger)
+
+	dbConn, err := core.NewDB(cfg, zapLogger)
+	if err != nil {
+		return err
+	}
+	defer core.CloseDB(dbConn, zapLogger)
+
+	repo := db.NewVerifactuRepository(dbConn)
+	signer := vf.NewHMACSigner([]byte(os.Getenv("VERIFACTU_SIGN_KEY")))
+	svc := vf.NewService(repo, signer, cfg.VerifactuSIFCode, cfg.VerifactuMode)
+
+	blobs, err := storage.NewBlobStore(cfg)
+	if err != nil {
+		return err
+	}
+
+	return runVerifactuExport(ctx, svc, blobs, opts, os.Stdout)
+}
+
+// runVerifactuExport stores the requested export in blobs and reports the
+// result on out. In dry-run mode the export is generated and summarized but
+// neither the file nor the export chain hash is stored.
+func runVerifactuExport(ctx context.Context, svc *vf.Service, blobs repository.BlobStore, opts verifactuExportOptions, out io.Writer) error {
+	file := fmt.Sprintf("verifactu/verifactu_%d.%s", opts.OrgID, opts.Format)
+
+	if opts.DryRun {
+		data, sig, err := svc.PreviewRecords(ctx, opts.OrgID)
+		if err != nil {
+			return err
+		}
+		summary, err := vf.SummarizeExport(data)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "dry run: %s not written\n", file)
+		fmt.Fprintf(out, "records: %d\n", summary.Records)
+		if summary.Records > 0 {
+			fmt.Fprintf(out, "date range: %s - %s\n", summary.From.Format(time.RFC3339), summary.To.Format(time.RFC3339))
+		}
+		fmt.Fprintf(out, "signature: %s\n", hex.EncodeToString(sig))
+		return nil
+	}
+
+	if opts.Format == "xml" {
+		data, err := svc.ExportXML(ctx, opts.OrgID)
+		if err != nil {
+			return err
+		}
+		location, err := blobs.Put(ctx, file, "application/xml", data)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "exported %s\n", location)
+		return nil
+	}
+
+	data, sig, err := svc.ExportRecords(ctx, opts.OrgID)
+	if err != nil {
+		return err
+	}
+
+	location, err := blobs.Put(ctx, file, "application/zip", data)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "exported %s signature %s\n", location, hex.EncodeToString(sig))
+	return nil
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	vf "github.com/pmaojo/kthulu-go/backend/internal/adapters/http/modules/verifactu"
+	"github.com/pmaojo/kthulu-go/backend/internal/infrastructure/storage"
+)
+
+type exportRepo struct {
+	records     []*vf.Record
+	chainHashes []string
+}
+
+func (r *exportRepo) GetRecordByID(ctx context.Context, id int) (*vf.Record, error) {
+	return nil, nil
+}
+
+func (r *exportRepo) CreateRecord(ctx context.Context, record *vf.Record) error {
+	record.ID = len(r.records) + 1
+	r.records = append(r.records, record)
+	return nil
+}
+
+func (r *exportRepo) ListRecordsByOrganization(ctx context.Context, orgID int) ([]*vf.Record, error) {
+	return r.records, nil
+}
+
+func (r *exportRepo) GetLastHash(ctx context.Context, orgID int) (string, error) {
+	if len(r.records) == 0 {
+		return "", nil
+	}
+	return r.records[len(r.records)-1].Hash, nil
+}
+
+func (r *exportRepo) GetLiveMode(ctx context.Context, year int) (bool, error) {
+	return false, nil
+}
+
+func (r *exportRepo) SetLiveMode(ctx context.Context, year int, live bool, triggeredBy string) error {
+	return nil
+}
+
+func (r *exportRepo) GetLiveModeStatus(ctx context.Context, year int) (*vf.LiveModeStatus, error) {
+	return &vf.LiveModeStatus{Year: year}, nil
+}
+
+func (r *exportRepo) SaveExportChainHash(ctx context.Context, orgID int, hash string) error {
+	r.chainHashes = append(r.chainHashes, hash)
+	return nil
+}
+
+func newExportService(t *testing.T) (*vf.Service, *exportRepo) {
+	t.Helper()
+	repo := &exportRepo{}
+	created := time.Date(2024, 3, 15, 10, 30, 0, 0, time.UTC)
+	for i := 0; i < 2; i++ {
+		rec := &vf.Record{InvoiceID: i + 1, OrganizationID: 1, RecordType: "alta", SIFCode: "AA", Hash: "h", CreatedAt: created.Add(time.Duration(i) * time.Hour)}
+		if err := repo.CreateRecord(context.Background(), rec); err != nil {
+			t.Fatalf("create record: %v", err)
+		}
+	}
+	return vf.NewService(repo, vf.NewHMACSigner([]byte("key")), "AA", "queued"), repo
+}
+
+func TestRunVerifactuExportDryRunWritesNoFile(t *testing.T) {
+	dir := t.TempDir()
+	out := new(bytes.Buffer)
+
+	opts := verifactuExportOptions{OrgID: 1, Format: "zip", DryRun: true}
+	blobs := storage.NewLocalBlobStore(dir, "http://localhost/uploads", "")
+	svc, repo := newExportService(t)
+	if err := runVerifactuExport(context.Background(), svc, blobs, opts, out); err != nil {
+		t.Fatalf("dry run export: %v", err)
+	}
+	if len(repo.chainHashes) != 0 {
+		t.Fatalf("dry run should not persist the chain hash, saved %v", repo.chainHashes)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("read dir: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Fatalf("dry run should not write files, found %d", len(entries))
+	}
+
+	summary := out.String()
+	for _, want := range []string{"records: 2", "2024-03-15T10:30:00Z - 2024-03-15T11:30:00Z", "signature: "} {
+		if !strings.Contains(summary, want) {
+			t.Fatalf("summary missing %q:\n%s", want, summary)
+		}
+	}
+}
+
+func TestRunVerifactuExportWritesZip(t *testing.T) {
+	dir := t.TempDir()
+	out := new(bytes.Buffer)
+
+	opts := verifactuExportOptions{OrgID: 1, Format: "zip"}
+	blobs := storage.NewLocalBlobStore(dir, "http://localhost/uploads", "")
+	svc, repo := newExportService(t)
+	if err := runVerifactuExport(context.Background(), svc, blobs, opts, out); err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	if len(repo.chainHashes) != 1 {
+		t.Fatalf("expected the export to persist the chain hash, saved %v", repo.chainHashes)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "verifactu", "verifactu_1.zip")); err != nil {
+		t.Fatalf("expected export file: %v", err)
+	}
+	if !strings.Contains(out.String(), "exported http://localhost/uploads/verifactu/verifactu_1.zip") {
+		t.Fatalf("expected the export location in the output:\n%s", out.String())
+	}
+}
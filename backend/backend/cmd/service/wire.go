@@ -11,28 +11,17 @@ import (
 	"go.uber.org/fx"
 
 	"github.com/pmaojo/kthulu-go/backend/core"
-	"github.com/pmaojo/kthulu-go/backend/core/metrics"
-	"github.com/pmaojo/kthulu-go/backend/internal/adapters/http/modules"
-	flagcfg "github.com/pmaojo/kthulu-go/backend/internal/adapters/http/modules/flags"
+	"github.com/pmaojo/kthulu-go/backend/internal/adapters/http/middleware"
 	"github.com/pmaojo/kthulu-go/backend/internal/infrastructure/observability"
 )
 
-type routerProviderType = func(p struct {
-	fx.In
-	RouteRegistry *modules.RouteRegistry
-	DB            *sql.DB
-	Logger        observability.Logger
-	Config        *core.Config
-	TokenManager  core.TokenManager
-	Flags         flagcfg.HeaderConfig
-	Metrics       *metrics.PrometheusMetrics
-}) chi.Router
+type routerProviderType = func(p routerParams) chi.Router
 
-type httpServerProviderType = func(r chi.Router, cfg *core.Config, logger observability.Logger) *http.Server
+type httpServerProviderType = func(r chi.Router, tracker *middleware.InFlightTracker, cfg *core.Config, logger observability.Logger) *http.Server
 
 type validateStartupFuncType = func(db *sql.DB, cfg *core.Config, logger observability.Logger) error
 
-type registerHooksFuncType = func(lc fx.Lifecycle, srv *http.Server, db *sql.DB, logger observability.Logger)
+type registerHooksFuncType = func(lc fx.Lifecycle, srv *http.Server, tracker *middleware.InFlightTracker, db *sql.DB, replica *core.ReadReplica, cfg *core.Config, logger observability.Logger)
 
 var (
 	routerProviderValue      routerProviderType      = newRouter
@@ -12,9 +12,7 @@ import (
 
 	"github.com/go-chi/chi/v5"
 	"github.com/pmaojo/kthulu-go/backend/core"
-	"github.com/pmaojo/kthulu-go/backend/core/metrics"
-	"github.com/pmaojo/kthulu-go/backend/internal/adapters/http/modules"
-	"github.com/pmaojo/kthulu-go/backend/internal/adapters/http/modules/flags"
+	"github.com/pmaojo/kthulu-go/backend/internal/adapters/http/middleware"
 	"github.com/pmaojo/kthulu-go/backend/internal/infrastructure/observability"
 	"go.uber.org/fx"
 )
@@ -45,23 +43,13 @@ var (
 
 // wire.go:
 
-type routerProviderType = func(p struct {
-	fx.In
+type routerProviderType = func(p routerParams) chi.Router
 
-	RouteRegistry *modules.RouteRegistry
-	DB            *sql.DB
-	Logger        observability.Logger
-	Config        *core.Config
-	TokenManager  core.TokenManager
-	Flags         flags.HeaderConfig
-	Metrics       *metrics.PrometheusMetrics
-}) chi.Router
-
-type httpServerProviderType = func(r chi.Router, cfg *core.Config, logger observability.Logger) *http.Server
+type httpServerProviderType = func(r chi.Router, tracker *middleware.InFlightTracker, cfg *core.Config, logger observability.Logger) *http.Server
 
 type validateStartupFuncType = func(db *sql.DB, cfg *core.Config, logger observability.Logger) error
 
-type registerHooksFuncType = func(lc fx.Lifecycle, srv *http.Server, db *sql.DB, logger observability.Logger)
+type registerHooksFuncType = func(lc fx.Lifecycle, srv *http.Server, tracker *middleware.InFlightTracker, db *sql.DB, replica *core.ReadReplica, cfg *core.Config, logger observability.Logger)
 
 var (
 	routerProviderValue      routerProviderType      = newRouter
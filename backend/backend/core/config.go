@@ -21,6 +21,22 @@ type DatabaseConfig struct {
 	MaxOpenConns    int
 	MaxIdleConns    int
 	ConnMaxLifetime time.Duration
+	// MigrationLockTimeout bounds how long startup waits for another instance's migrations
+	MigrationLockTimeout time.Duration
+	// ReplicaURL optionally points list and stats queries at a read replica
+	// reachable with the same driver
+	ReplicaURL string
+	// HealthCheckQuery is the probe the health endpoints run against the
+	// database; a successful ping alone doesn't prove the database answers
+	HealthCheckQuery string
+	// HealthCheckTimeout bounds the probe; a slower database is unhealthy
+	HealthCheckTimeout time.Duration
+	// MonitorInterval is how often the connection monitor probes the
+	// database; zero disables it
+	MonitorInterval time.Duration
+	// StatementTimeout bounds repository statements whose context has no
+	// deadline; zero leaves them unbounded
+	StatementTimeout time.Duration
 }
 
 // ServerConfig holds HTTP server configuration
@@ -29,23 +45,53 @@ type ServerConfig struct {
 	ReadTimeout  time.Duration
 	WriteTimeout time.Duration
 	IdleTimeout  time.Duration
+	// ShutdownGracePeriod bounds how long shutdown waits for in-flight requests
+	ShutdownGracePeriod time.Duration
 }
 
+// GRPCConfig holds gRPC server configuration
+type GRPCConfig struct {
+	Addr string
+}
+
+// JWT access token signing algorithms
+const (
+	JWTAlgorithmHS256 = "HS256" // shared secret, the default
+	JWTAlgorithmRS256 = "RS256" // RSA key pair, verifiable by other services via JWKS
+)
+
 // JWTConfig holds JWT token configuration
 type JWTConfig struct {
 	Secret          string
 	RefreshSecret   string
 	AccessTokenTTL  time.Duration
 	RefreshTokenTTL time.Duration
+	// Per-role overrides keyed by role name; roles without one use the TTLs above
+	RoleAccessTokenTTLs  map[string]time.Duration
+	RoleRefreshTokenTTLs map[string]time.Duration
+	Algorithm            string
+	PrivateKey           string // PEM encoded RSA key, required for RS256
+	KeyID                string // "kid" header, derived from the key when empty
+	// RefreshTokenCleanupInterval is how often expired refresh tokens are
+	// deleted; zero disables the cleanup job
+	RefreshTokenCleanupInterval time.Duration
 }
 
+// SMTP transport security modes
+const (
+	SMTPTLSStartTLS = "starttls" // upgrade a plain connection, failing if the server cannot
+	SMTPTLSImplicit = "tls"      // connect over TLS from the start (usually port 465)
+	SMTPTLSNone     = "none"     // plain connection, for local relays and development
+)
+
 // SMTPConfig holds email notification configuration
 type SMTPConfig struct {
 	Host     string
 	Port     int
-	Username string
+	Username string // empty disables SMTP authentication
 	Password string
 	From     string
+	TLS      string
 	Enabled  bool
 }
 
@@ -66,13 +112,17 @@ type SentryConfig struct {
 
 // ObservabilityConfig holds tracing and metrics configuration.
 // TraceExporter selects the tracing backend: "stdout" (default) or "jaeger".
-// MetricsExporter selects the metrics backend (e.g., "prometheus").
+// MetricsExporter selects the metrics backend: "prometheus" (default) or
+// "none" to disable metrics.
 type ObservabilityConfig struct {
 	TraceSampleRate float64
 	TraceExporter   string
 	MetricsExporter string
 }
 
+// MetricsExporterNone disables metrics collection and the /metrics endpoint
+const MetricsExporterNone = "none"
+
 // RateLimitConfig holds configuration for request rate limiting.
 // Defaults: RequestsPerSecond=10, Burst=20.
 type RateLimitConfig struct {
@@ -82,11 +132,108 @@ type RateLimitConfig struct {
 	Burst int
 }
 
+// LoggingConfig holds HTTP request logging options
+type LoggingConfig struct {
+	// LogRequestBodies adds JSON and form request bodies to the request log
+	LogRequestBodies bool
+	// RedactFields are masked in logged bodies in addition to the defaults
+	RedactFields []string
+}
+
+// MoneyConfig holds how invoice amounts are rounded
+type MoneyConfig struct {
+	// RoundingMode is "half-up" (default) or "half-even" (banker's rounding)
+	RoundingMode string
+	// CurrencyPrecision overrides the number of decimals of ISO currency codes
+	CurrencyPrecision map[string]int
+}
+
+// ExchangeRatesConfig configures the rates foreign-currency invoices default to
+type ExchangeRatesConfig struct {
+	// URL of a Frankfurter-compatible rates API; empty disables the lookup
+	// and invoices keep an exchange rate of 1 unless one is given
+	URL string
+	// BaseCurrency is the currency invoice amounts are converted to
+	BaseCurrency string
+}
+
+// TaxConfig configures how invoice lines are taxed
+type TaxConfig struct {
+	// Calculator is "flat" (default), which charges each line its own tax
+	// rate, or "eu", which applies EU VAT reverse-charge rules
+	Calculator string
+	// CategoryRates are the reduced rates of product categories for the
+	// "eu" calculator
+	CategoryRates map[string]float64
+}
+
+// PaymentsConfig configures online invoice payments
+type PaymentsConfig struct {
+	Stripe StripeConfig
+}
+
+// StripeConfig holds the Stripe API credentials
+type StripeConfig struct {
+	SecretKey string
+	// WebhookSecret verifies the signature of Stripe webhook deliveries
+	WebhookSecret string
+	// APIURL defaults to https://api.stripe.com
+	APIURL string
+}
+
+// CalendarConfig configures the calendar background jobs
+type CalendarConfig struct {
+	// ReminderInterval is how often due event reminders are sent; zero
+	// disables the reminder job
+	ReminderInterval time.Duration
+}
+
+// StorageConfig configures the blob store used for uploads and exports
+type StorageConfig struct {
+	// Driver is "local" (default) or "s3"
+	Driver string
+	// LocalDir is the directory the local driver writes blobs to
+	LocalDir string
+	// PublicURL is the base URL blobs are served from. It defaults to the
+	// bucket URL for the s3 driver.
+	PublicURL string
+	// SigningKey signs the URLs handed out by the local driver
+	SigningKey string
+	S3         S3Config
+}
+
+// S3Config configures the S3-compatible blob store
+type S3Config struct {
+	// Endpoint defaults to https://s3.<region>.amazonaws.com
+	Endpoint        string
+	Region          string
+	Bucket          string
+	AccessKeyID     string
+	SecretAccessKey string
+	// PathStyle addresses the bucket as <endpoint>/<bucket>, as most
+	// S3-compatible servers such as MinIO expect, instead of as a subdomain
+	PathStyle bool
+}
+
+// CacheConfig configures the read-through caches in front of repositories
+type CacheConfig struct {
+	// Driver is "memory" (default) or "redis"
+	Driver    string
+	RedisAddr string
+	// ProductTTL is how long product reads are cached; zero disables the
+	// product cache
+	ProductTTL time.Duration
+	// IdempotencyTTL is how long responses are replayed for a repeated
+	// Idempotency-Key; zero disables idempotency keys
+	IdempotencyTTL time.Duration
+}
+
 // Config holds application-wide configuration values.
 type Config struct {
 	Version          string
 	Database         DatabaseConfig
 	Server           ServerConfig
+	GRPC             GRPCConfig
 	JWT              JWTConfig
 	SMTP             SMTPConfig
 	FeatureFlags     FeatureFlagConfig
@@ -98,6 +245,14 @@ type Config struct {
 	VerifactuSIFCode string // Two-character SIF code for VeriFactu
 	VerifactuMode    string
 	RateLimit        RateLimitConfig
+	Money            MoneyConfig
+	ExchangeRates    ExchangeRatesConfig
+	Tax              TaxConfig
+	Payments         PaymentsConfig
+	Storage          StorageConfig
+	Cache            CacheConfig
+	Logging          LoggingConfig
+	Calendar         CalendarConfig
 }
 
 const databaseURLEnv = "DATABASE_URL"
@@ -171,6 +326,107 @@ func NewConfig() (*Config, error) {
 		Burst:             burst,
 	}
 
+	// Money rounding configuration
+	currencyPrecision, err := parseCurrencyPrecision(os.Getenv("MONEY_CURRENCY_PRECISION"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid MONEY_CURRENCY_PRECISION: %w", err)
+	}
+	config.Money = MoneyConfig{
+		RoundingMode:      getEnvWithDefault("MONEY_ROUNDING_MODE", "half-up"),
+		CurrencyPrecision: currencyPrecision,
+	}
+	config.ExchangeRates = ExchangeRatesConfig{
+		URL:          os.Getenv("EXCHANGE_RATES_URL"),
+		BaseCurrency: strings.ToUpper(getEnvWithDefault("EXCHANGE_RATES_BASE_CURRENCY", "EUR")),
+	}
+
+	// Tax calculation configuration
+	categoryRates, err := parseTaxCategoryRates(os.Getenv("TAX_CATEGORY_RATES"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid TAX_CATEGORY_RATES: %w", err)
+	}
+	config.Tax = TaxConfig{
+		Calculator:    strings.ToLower(getEnvWithDefault("TAX_CALCULATOR", "flat")),
+		CategoryRates: categoryRates,
+	}
+	if config.Tax.Calculator != "flat" && config.Tax.Calculator != "eu" {
+		return nil, fmt.Errorf("invalid TAX_CALCULATOR %q: must be flat or eu", config.Tax.Calculator)
+	}
+
+	// Online payment configuration
+	config.Payments = PaymentsConfig{Stripe: StripeConfig{
+		SecretKey:     os.Getenv("STRIPE_SECRET_KEY"),
+		WebhookSecret: os.Getenv("STRIPE_WEBHOOK_SECRET"),
+		APIURL:        getEnvWithDefault("STRIPE_API_URL", "https://api.stripe.com"),
+	}}
+
+	// Calendar reminder job configuration
+	reminderInterval, err := time.ParseDuration(getEnvWithDefault("CALENDAR_REMINDER_INTERVAL", "1m"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid CALENDAR_REMINDER_INTERVAL: %w", err)
+	}
+	if reminderInterval < 0 {
+		return nil, errors.New("invalid CALENDAR_REMINDER_INTERVAL: must not be negative")
+	}
+	config.Calendar = CalendarConfig{ReminderInterval: reminderInterval}
+
+	// Request logging configuration
+	logRequestBodies, _ := strconv.ParseBool(getEnvWithDefault("LOG_REQUEST_BODIES", "false"))
+	config.Logging = LoggingConfig{LogRequestBodies: logRequestBodies}
+	for _, field := range strings.Split(os.Getenv("LOG_REDACT_FIELDS"), ",") {
+		if field = strings.TrimSpace(field); field != "" {
+			config.Logging.RedactFields = append(config.Logging.RedactFields, field)
+		}
+	}
+
+	// Blob storage configuration
+	s3PathStyle, _ := strconv.ParseBool(getEnvWithDefault("STORAGE_S3_PATH_STYLE", "false"))
+	config.Storage = StorageConfig{
+		Driver:     strings.ToLower(getEnvWithDefault("STORAGE_DRIVER", "local")),
+		LocalDir:   getEnvWithDefault("STORAGE_LOCAL_DIR", "./uploads"),
+		PublicURL:  strings.TrimSuffix(os.Getenv("STORAGE_PUBLIC_URL"), "/"),
+		SigningKey: os.Getenv("STORAGE_SIGNING_KEY"),
+		S3: S3Config{
+			Endpoint:        strings.TrimSuffix(os.Getenv("STORAGE_S3_ENDPOINT"), "/"),
+			Region:          getEnvWithDefault("STORAGE_S3_REGION", "us-east-1"),
+			Bucket:          os.Getenv("STORAGE_S3_BUCKET"),
+			AccessKeyID:     os.Getenv("STORAGE_S3_ACCESS_KEY_ID"),
+			SecretAccessKey: os.Getenv("STORAGE_S3_SECRET_ACCESS_KEY"),
+			PathStyle:       s3PathStyle,
+		},
+	}
+	switch config.Storage.Driver {
+	case "local":
+		if config.Storage.PublicURL == "" {
+			config.Storage.PublicURL = "http://localhost:8080/uploads"
+		}
+	case "s3":
+		if config.Storage.S3.Bucket == "" || config.Storage.S3.AccessKeyID == "" || config.Storage.S3.SecretAccessKey == "" {
+			return nil, errors.New("STORAGE_S3_BUCKET, STORAGE_S3_ACCESS_KEY_ID and STORAGE_S3_SECRET_ACCESS_KEY are required when using the s3 storage driver")
+		}
+	default:
+		return nil, fmt.Errorf("unsupported storage driver: %s (supported: local, s3)", config.Storage.Driver)
+	}
+
+	// Cache configuration
+	productCacheTTL, err := time.ParseDuration(getEnvWithDefault("PRODUCT_CACHE_TTL", "0s"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid PRODUCT_CACHE_TTL: %w", err)
+	}
+	idempotencyTTL, err := time.ParseDuration(getEnvWithDefault("IDEMPOTENCY_KEY_TTL", "24h"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid IDEMPOTENCY_KEY_TTL: %w", err)
+	}
+	config.Cache = CacheConfig{
+		Driver:         strings.ToLower(getEnvWithDefault("CACHE_DRIVER", "memory")),
+		RedisAddr:      getEnvWithDefault("REDIS_ADDR", "localhost:6379"),
+		ProductTTL:     productCacheTTL,
+		IdempotencyTTL: idempotencyTTL,
+	}
+	if config.Cache.Driver != "memory" && config.Cache.Driver != "redis" {
+		return nil, fmt.Errorf("unsupported cache driver: %s (supported: memory, redis)", config.Cache.Driver)
+	}
+
 	// Database configuration - Optimal: SQLite by default
 	dbDriver := getEnvWithDefault("DB_DRIVER", "sqlite")
 	var dbURL string
@@ -202,12 +458,40 @@ func NewConfig() (*Config, error) {
 		return nil, fmt.Errorf("invalid DB_CONN_MAX_LIFETIME: %w", err)
 	}
 
+	migrationLockTimeout, err := time.ParseDuration(getEnvWithDefault("DB_MIGRATION_LOCK_TIMEOUT", DefaultMigrationLockTimeout.String()))
+	if err != nil {
+		return nil, fmt.Errorf("invalid DB_MIGRATION_LOCK_TIMEOUT: %w", err)
+	}
+
+	healthCheckTimeout, err := time.ParseDuration(getEnvWithDefault("DB_HEALTH_CHECK_TIMEOUT", DefaultHealthCheckTimeout.String()))
+	if err != nil {
+		return nil, fmt.Errorf("invalid DB_HEALTH_CHECK_TIMEOUT: %w", err)
+	}
+
+	monitorInterval, err := time.ParseDuration(getEnvWithDefault("DB_MONITOR_INTERVAL", "30s"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid DB_MONITOR_INTERVAL: %w", err)
+	}
+
+	statementTimeout, err := time.ParseDuration(getEnvWithDefault("DB_STATEMENT_TIMEOUT", DefaultStatementTimeout.String()))
+	if err != nil {
+		return nil, fmt.Errorf("invalid DB_STATEMENT_TIMEOUT: %w", err)
+	}
+
 	config.Database = DatabaseConfig{
 		URL:             dbURL,
 		Driver:          dbDriver,
 		MaxOpenConns:    maxOpenConns,
 		MaxIdleConns:    maxIdleConns,
 		ConnMaxLifetime: connMaxLifetime,
+
+		MigrationLockTimeout: migrationLockTimeout,
+		ReplicaURL:           os.Getenv("DATABASE_REPLICA_URL"),
+
+		HealthCheckQuery:   getEnvWithDefault("DB_HEALTH_CHECK_QUERY", DefaultHealthCheckQuery),
+		HealthCheckTimeout: healthCheckTimeout,
+		MonitorInterval:    monitorInterval,
+		StatementTimeout:   statementTimeout,
 	}
 
 	// Server configuration
@@ -226,17 +510,50 @@ func NewConfig() (*Config, error) {
 		return nil, fmt.Errorf("invalid SERVER_IDLE_TIMEOUT: %w", err)
 	}
 
+	shutdownGracePeriod, err := time.ParseDuration(getEnvWithDefault("SERVER_SHUTDOWN_GRACE_PERIOD", "30s"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid SERVER_SHUTDOWN_GRACE_PERIOD: %w", err)
+	}
+
 	config.Server = ServerConfig{
 		Addr:         getEnvWithDefault("HTTP_ADDR", ":8080"),
 		ReadTimeout:  readTimeout,
 		WriteTimeout: writeTimeout,
 		IdleTimeout:  idleTimeout,
+
+		ShutdownGracePeriod: shutdownGracePeriod,
+	}
+
+	// gRPC configuration, used when the grpc module is loaded
+	config.GRPC = GRPCConfig{
+		Addr: getEnvWithDefault("GRPC_ADDR", ":9090"),
 	}
 
 	// JWT configuration
+	jwtAlgorithm := strings.ToUpper(getEnvWithDefault("JWT_ALGORITHM", JWTAlgorithmHS256))
 	jwtSecret := os.Getenv("JWT_SECRET")
-	if jwtSecret == "" {
-		return nil, errors.New("JWT_SECRET is required")
+	// Single-line env files carry the PEM key with escaped newlines
+	jwtPrivateKey := strings.ReplaceAll(os.Getenv("JWT_PRIVATE_KEY"), `\n`, "\n")
+	switch jwtAlgorithm {
+	case JWTAlgorithmHS256:
+		if jwtSecret == "" {
+			return nil, errors.New("JWT_SECRET is required")
+		}
+	case JWTAlgorithmRS256:
+		if jwtPrivateKey == "" {
+			if path := os.Getenv("JWT_PRIVATE_KEY_FILE"); path != "" {
+				pem, err := os.ReadFile(path)
+				if err != nil {
+					return nil, fmt.Errorf("failed to read JWT_PRIVATE_KEY_FILE: %w", err)
+				}
+				jwtPrivateKey = string(pem)
+			}
+		}
+		if jwtPrivateKey == "" {
+			return nil, errors.New("JWT_PRIVATE_KEY or JWT_PRIVATE_KEY_FILE is required for RS256")
+		}
+	default:
+		return nil, fmt.Errorf("invalid JWT_ALGORITHM %q: expected HS256 or RS256", jwtAlgorithm)
 	}
 
 	jwtRefreshSecret := os.Getenv("JWT_REFRESH_SECRET")
@@ -254,11 +571,36 @@ func NewConfig() (*Config, error) {
 		return nil, fmt.Errorf("invalid JWT_REFRESH_TOKEN_TTL: %w", err)
 	}
 
+	roleAccessTokenTTLs, err := parseRoleTokenTTLs(os.Getenv("JWT_ROLE_ACCESS_TOKEN_TTLS"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid JWT_ROLE_ACCESS_TOKEN_TTLS: %w", err)
+	}
+
+	roleRefreshTokenTTLs, err := parseRoleTokenTTLs(os.Getenv("JWT_ROLE_REFRESH_TOKEN_TTLS"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid JWT_ROLE_REFRESH_TOKEN_TTLS: %w", err)
+	}
+
+	refreshTokenCleanupInterval, err := time.ParseDuration(getEnvWithDefault("JWT_REFRESH_TOKEN_CLEANUP_INTERVAL", "1h"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid JWT_REFRESH_TOKEN_CLEANUP_INTERVAL: %w", err)
+	}
+	if refreshTokenCleanupInterval < 0 {
+		return nil, errors.New("invalid JWT_REFRESH_TOKEN_CLEANUP_INTERVAL: must not be negative")
+	}
+
 	config.JWT = JWTConfig{
-		Secret:          jwtSecret,
-		RefreshSecret:   jwtRefreshSecret,
-		AccessTokenTTL:  accessTokenTTL,
-		RefreshTokenTTL: refreshTokenTTL,
+		Secret:               jwtSecret,
+		RefreshSecret:        jwtRefreshSecret,
+		AccessTokenTTL:       accessTokenTTL,
+		RefreshTokenTTL:      refreshTokenTTL,
+		RoleAccessTokenTTLs:  roleAccessTokenTTLs,
+		RoleRefreshTokenTTLs: roleRefreshTokenTTLs,
+		Algorithm:            jwtAlgorithm,
+		PrivateKey:           jwtPrivateKey,
+		KeyID:                os.Getenv("JWT_KEY_ID"),
+
+		RefreshTokenCleanupInterval: refreshTokenCleanupInterval,
 	}
 
 	// SMTP configuration
@@ -274,6 +616,7 @@ func NewConfig() (*Config, error) {
 		Username: os.Getenv("SMTP_USERNAME"),
 		Password: os.Getenv("SMTP_PASSWORD"),
 		From:     getEnvWithDefault("SMTP_FROM", "noreply@kthulu.local"),
+		TLS:      strings.ToLower(getEnvWithDefault("SMTP_TLS", SMTPTLSStartTLS)),
 		Enabled:  smtpEnabled,
 	}
 
@@ -282,11 +625,13 @@ func NewConfig() (*Config, error) {
 		if config.SMTP.Host == "" {
 			return nil, errors.New("SMTP_HOST is required when SMTP is enabled")
 		}
-		if config.SMTP.Username == "" {
-			return nil, errors.New("SMTP_USERNAME is required when SMTP is enabled")
+		if config.SMTP.Username != "" && config.SMTP.Password == "" {
+			return nil, errors.New("SMTP_PASSWORD is required when SMTP_USERNAME is set")
 		}
-		if config.SMTP.Password == "" {
-			return nil, errors.New("SMTP_PASSWORD is required when SMTP is enabled")
+		switch config.SMTP.TLS {
+		case SMTPTLSStartTLS, SMTPTLSImplicit, SMTPTLSNone:
+		default:
+			return nil, fmt.Errorf("invalid SMTP_TLS %q: expected starttls, tls or none", config.SMTP.TLS)
 		}
 	}
 
@@ -294,6 +639,73 @@ func NewConfig() (*Config, error) {
 }
 
 // getEnvWithDefault returns the value of the environment variable or a default value
+// parseCurrencyPrecision parses a comma-separated list of CODE:decimals pairs
+func parseCurrencyPrecision(value string) (map[string]int, error) {
+	precision := make(map[string]int)
+	for _, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		currency, decimals, ok := strings.Cut(pair, ":")
+		if !ok {
+			return nil, fmt.Errorf("expected CODE:decimals, got %q", pair)
+		}
+		n, err := strconv.Atoi(strings.TrimSpace(decimals))
+		if err != nil {
+			return nil, fmt.Errorf("invalid decimals for %s: %w", currency, err)
+		}
+		precision[strings.ToUpper(strings.TrimSpace(currency))] = n
+	}
+	return precision, nil
+}
+
+// parseTaxCategoryRates parses a comma-separated list of category:rate
+// pairs, with rates as fractions such as 0.04
+func parseTaxCategoryRates(value string) (map[string]float64, error) {
+	rates := make(map[string]float64)
+	for _, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		category, rate, ok := strings.Cut(pair, ":")
+		if !ok {
+			return nil, fmt.Errorf("expected category:rate, got %q", pair)
+		}
+		r, err := strconv.ParseFloat(strings.TrimSpace(rate), 64)
+		if err != nil || r < 0 || r > 1 {
+			return nil, fmt.Errorf("invalid rate for %s: must be between 0 and 1", category)
+		}
+		rates[strings.ToLower(strings.TrimSpace(category))] = r
+	}
+	return rates, nil
+}
+
+// parseRoleTokenTTLs parses a comma-separated list of role:duration pairs
+func parseRoleTokenTTLs(value string) (map[string]time.Duration, error) {
+	ttls := make(map[string]time.Duration)
+	for _, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		role, duration, ok := strings.Cut(pair, ":")
+		if !ok {
+			return nil, fmt.Errorf("expected role:duration, got %q", pair)
+		}
+		ttl, err := time.ParseDuration(strings.TrimSpace(duration))
+		if err != nil {
+			return nil, fmt.Errorf("invalid duration for %s: %w", role, err)
+		}
+		if ttl <= 0 {
+			return nil, fmt.Errorf("duration for %s must be positive", role)
+		}
+		ttls[strings.ToLower(strings.TrimSpace(role))] = ttl
+	}
+	return ttls, nil
+}
+
 func getEnvWithDefault(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
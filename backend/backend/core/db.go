@@ -5,6 +5,7 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"sync/atomic"
 	"time"
 
 	_ "github.com/jackc/pgx/v5/stdlib"
@@ -50,6 +51,7 @@ func NewDB(cfg *Config, logger *zap.Logger) (*sql.DB, error) {
 		db.SetMaxIdleConns(cfg.Database.MaxIdleConns)
 	}
 	db.SetConnMaxLifetime(cfg.Database.ConnMaxLifetime)
+	SetStatementTimeout(cfg.Database.StatementTimeout)
 
 	// Test the connection with retry logic
 	if err := connectWithRetry(db, logger, 5, 2*time.Second); err != nil {
@@ -61,6 +63,72 @@ func NewDB(cfg *Config, logger *zap.Logger) (*sql.DB, error) {
 	return db, nil
 }
 
+// DefaultStatementTimeout bounds statements run without a deadline until
+// NewDB applies the configured timeout
+const DefaultStatementTimeout = 30 * time.Second
+
+var statementTimeout atomic.Int64
+
+func init() {
+	statementTimeout.Store(int64(DefaultStatementTimeout))
+}
+
+// SetStatementTimeout sets the timeout WithStatementTimeout applies. Zero or
+// less leaves statements unbounded.
+func SetStatementTimeout(timeout time.Duration) {
+	statementTimeout.Store(int64(timeout))
+}
+
+// WithStatementTimeout bounds ctx by the statement timeout unless it already
+// has a deadline, so a runaway query can't hold a connection indefinitely.
+// Repositories call it at the top of their methods:
+//
+//	ctx, cancel := core.WithStatementTimeout(ctx)
+//	defer cancel()
+func WithStatementTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	timeout := time.Duration(statementTimeout.Load())
+	if _, ok := ctx.Deadline(); ok || timeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// WithStatementStartTimeout bounds ctx by the statement timeout until started
+// is called, for queries whose rows are streamed for longer than that. A query
+// that hasn't returned its first rows in time is cancelled; once it has, only
+// the caller's ctx ends it.
+func WithStatementStartTimeout(ctx context.Context) (_ context.Context, started func(), cancel context.CancelFunc) {
+	timeout := time.Duration(statementTimeout.Load())
+	if _, ok := ctx.Deadline(); ok || timeout <= 0 {
+		return ctx, func() {}, func() {}
+	}
+	ctx, cancel = context.WithCancel(ctx)
+	timer := time.AfterFunc(timeout, cancel)
+	return ctx, func() { timer.Stop() }, cancel
+}
+
+// ReadReplica is a read-only connection repositories send reporting queries
+// to. It is nil unless DATABASE_REPLICA_URL is set.
+type ReadReplica struct {
+	*sql.DB
+}
+
+// NewReadReplica opens the configured read replica with the primary's driver
+// and pool settings, or returns nil when no replica is configured.
+func NewReadReplica(cfg *Config, logger *zap.Logger) (*ReadReplica, error) {
+	if cfg.Database.ReplicaURL == "" {
+		return nil, nil
+	}
+
+	replicaCfg := *cfg
+	replicaCfg.Database.URL = cfg.Database.ReplicaURL
+	db, err := NewDB(&replicaCfg, logger.With(zap.String("role", "replica")))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open read replica: %w", err)
+	}
+	return &ReadReplica{DB: db}, nil
+}
+
 // HealthCheck performs a database health check
 func HealthCheck(db *sql.DB) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -69,6 +137,35 @@ func HealthCheck(db *sql.DB) error {
 	return db.PingContext(ctx)
 }
 
+// Defaults for the database health probe
+const (
+	DefaultHealthCheckQuery   = "SELECT 1"
+	DefaultHealthCheckTimeout = 2 * time.Second
+)
+
+// HealthProbe runs query against the database and fails when it doesn't
+// answer within timeout. Unlike a ping it needs a connection able to execute
+// statements.
+func HealthProbe(db *sql.DB, query string, timeout time.Duration) error {
+	if query == "" {
+		query = DefaultHealthCheckQuery
+	}
+	if timeout <= 0 {
+		timeout = DefaultHealthCheckTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	rows, err := db.QueryContext(ctx, query)
+	if err == nil {
+		err = rows.Close()
+	}
+	if err != nil && ctx.Err() == context.DeadlineExceeded {
+		return fmt.Errorf("database health probe timed out after %s", timeout)
+	}
+	return err
+}
+
 // HealthCheckDetailed performs a detailed database health check with metrics
 func HealthCheckDetailed(db *sql.DB, logger *zap.Logger) error {
 	start := time.Now()
@@ -2,7 +2,9 @@
 package core
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 
 	"gorm.io/driver/postgres"
@@ -46,6 +48,55 @@ func NewGormDB(sqlDB *sql.DB, cfg *Config) (*gorm.DB, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to create GORM database: %w", err)
 	}
+	if err := RegisterStatementTimeout(gormDB); err != nil {
+		return nil, fmt.Errorf("failed to register GORM statement timeout: %w", err)
+	}
 
 	return gormDB, nil
 }
+
+const statementTimeoutKey = "kthulu:statement_timeout"
+
+// boundedStatement is the context a statement had before its timeout was
+// applied, restored once it finishes
+type boundedStatement struct {
+	parent context.Context
+	cancel context.CancelFunc
+}
+
+// RegisterStatementTimeout bounds the statements GORM repositories run with
+// WithStatementTimeout. Row queries are left alone: their rows are read after
+// the callbacks return, so cancelling then would cut the read short.
+func RegisterStatementTimeout(gormDB *gorm.DB) error {
+	before := func(db *gorm.DB) {
+		parent := db.Statement.Context
+		ctx, cancel := WithStatementTimeout(parent)
+		db.Statement.Context = ctx
+		db.InstanceSet(statementTimeoutKey, boundedStatement{parent: parent, cancel: cancel})
+	}
+	after := func(db *gorm.DB) {
+		if value, ok := db.InstanceGet(statementTimeoutKey); ok {
+			// Chained queries reuse the statement, so the next one must not
+			// inherit the cancelled context
+			bounded := value.(boundedStatement)
+			bounded.cancel()
+			db.Statement.Context = bounded.parent
+		}
+	}
+
+	const start, end = "kthulu:statement_timeout_start", "kthulu:statement_timeout_end"
+	callbacks := gormDB.Callback()
+	// Writes start before their transaction so it is bounded too
+	return errors.Join(
+		callbacks.Create().Before("gorm:begin_transaction").Register(start, before),
+		callbacks.Create().After("gorm:commit_or_rollback_transaction").Register(end, after),
+		callbacks.Update().Before("gorm:begin_transaction").Register(start, before),
+		callbacks.Update().After("gorm:commit_or_rollback_transaction").Register(end, after),
+		callbacks.Delete().Before("gorm:begin_transaction").Register(start, before),
+		callbacks.Delete().After("gorm:commit_or_rollback_transaction").Register(end, after),
+		callbacks.Query().Before("gorm:query").Register(start, before),
+		callbacks.Query().After("gorm:query").Register(end, after),
+		callbacks.Raw().Before("gorm:raw").Register(start, before),
+		callbacks.Raw().After("gorm:raw").Register(end, after),
+	)
+}
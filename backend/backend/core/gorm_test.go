@@ -0,0 +1,50 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestRegisterStatementTimeout(t *testing.T) {
+	gormDB, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	if err := RegisterStatementTimeout(gormDB); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	SetStatementTimeout(50 * time.Millisecond)
+	t.Cleanup(func() { SetStatementTimeout(DefaultStatementTimeout) })
+
+	// An endless recursive query runs until it is interrupted
+	var count int64
+	start := time.Now()
+	err = gormDB.Raw("WITH RECURSIVE n(x) AS (SELECT 1 UNION ALL SELECT x + 1 FROM n) SELECT count(*) FROM n").Find(&count).Error
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the query to be cancelled, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Fatalf("expected the query to stop at the timeout, took %s", elapsed)
+	}
+
+	// Chained queries share a statement; each gets a fresh timeout
+	if err := gormDB.Exec("CREATE TABLE items (id INTEGER PRIMARY KEY)").Error; err != nil {
+		t.Fatalf("create table: %v", err)
+	}
+	query := gormDB.Table("items").Where("id > ?", 0)
+	if err := query.Count(&count).Error; err != nil {
+		t.Fatalf("count: %v", err)
+	}
+	// Outlive the first query's timeout
+	time.Sleep(60 * time.Millisecond)
+	var ids []int64
+	if err := query.Pluck("id", &ids).Error; err != nil {
+		t.Fatalf("expected the chained query to run, got %v", err)
+	}
+}
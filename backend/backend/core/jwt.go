@@ -2,8 +2,14 @@
 package core
 
 import (
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"math/big"
+	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -17,29 +23,90 @@ type TokenManager interface {
 	ValidateRefreshToken(token string) (jwt.MapClaims, error)
 	GetAccessTokenTTL() time.Duration
 	GetRefreshTokenTTL() time.Duration
+	// AccessTokenTTLForRole and RefreshTokenTTLForRole return the TTL
+	// configured for a role, falling back to the global TTL.
+	AccessTokenTTLForRole(role string) time.Duration
+	RefreshTokenTTLForRole(role string) time.Duration
+	// PublicKeySet returns the keys other services can verify access tokens
+	// with. It is empty when access tokens are signed with a shared secret.
+	PublicKeySet() JSONWebKeySet
+}
+
+// JSONWebKey is the public part of an RSA signing key (RFC 7517).
+type JSONWebKey struct {
+	Kty string `json:"kty"`
+	Use string `json:"use,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	Kid string `json:"kid,omitempty"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+// JSONWebKeySet is the document served at /.well-known/jwks.json.
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
 }
 
 type jwtManager struct {
-	accessSecret    []byte
+	accessMethod    jwt.SigningMethod
+	accessSignKey   interface{}
+	accessVerifyKey interface{}
+	accessKeyID     string
+	publicKeys      JSONWebKeySet
 	refreshSecret   []byte
 	accessTokenTTL  time.Duration
 	refreshTokenTTL time.Duration
+	roleAccessTTLs  map[string]time.Duration
+	roleRefreshTTLs map[string]time.Duration
 }
 
 // NewJWT constructs a JWT token manager using the application's JWT configuration.
-func NewJWT(cfg *Config) TokenManager {
-	return &jwtManager{
-		accessSecret:    []byte(cfg.JWT.Secret),
+// Access tokens are signed with HS256 unless RS256 is configured; refresh tokens
+// are only ever validated by this service and always use HS256.
+func NewJWT(cfg *Config) (TokenManager, error) {
+	m := &jwtManager{
+		accessMethod:    jwt.SigningMethodHS256,
+		accessSignKey:   []byte(cfg.JWT.Secret),
+		accessVerifyKey: []byte(cfg.JWT.Secret),
+		publicKeys:      JSONWebKeySet{Keys: []JSONWebKey{}},
 		refreshSecret:   []byte(cfg.JWT.RefreshSecret),
 		accessTokenTTL:  cfg.JWT.AccessTokenTTL,
 		refreshTokenTTL: cfg.JWT.RefreshTokenTTL,
+		roleAccessTTLs:  lowerKeys(cfg.JWT.RoleAccessTokenTTLs),
+		roleRefreshTTLs: lowerKeys(cfg.JWT.RoleRefreshTokenTTLs),
 	}
+
+	switch cfg.JWT.Algorithm {
+	case "", JWTAlgorithmHS256:
+	case JWTAlgorithmRS256:
+		key, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(cfg.JWT.PrivateKey))
+		if err != nil {
+			return nil, fmt.Errorf("invalid JWT private key: %w", err)
+		}
+		jwk := rsaPublicJWK(&key.PublicKey)
+		if cfg.JWT.KeyID != "" {
+			jwk.Kid = cfg.JWT.KeyID
+		}
+
+		m.accessMethod = jwt.SigningMethodRS256
+		m.accessSignKey = key
+		m.accessVerifyKey = &key.PublicKey
+		m.accessKeyID = jwk.Kid
+		m.publicKeys = JSONWebKeySet{Keys: []JSONWebKey{jwk}}
+	default:
+		return nil, fmt.Errorf("unsupported JWT algorithm %q", cfg.JWT.Algorithm)
+	}
+
+	return m, nil
 }
 
-// SignAccessToken creates a signed JWT access token string for the provided claims using HS256.
+// SignAccessToken creates a signed JWT access token string for the provided claims.
 func (j *jwtManager) SignAccessToken(claims jwt.Claims) (string, error) {
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(j.accessSecret)
+	token := jwt.NewWithClaims(j.accessMethod, claims)
+	if j.accessKeyID != "" {
+		token.Header["kid"] = j.accessKeyID
+	}
+	return token.SignedString(j.accessSignKey)
 }
 
 // SignRefreshToken creates a signed JWT refresh token string for the provided claims using HS256.
@@ -50,12 +117,12 @@ func (j *jwtManager) SignRefreshToken(claims jwt.Claims) (string, error) {
 
 // ValidateAccessToken parses and validates an access token string, returning its MapClaims.
 func (j *jwtManager) ValidateAccessToken(tokenStr string) (jwt.MapClaims, error) {
-	return j.validateToken(tokenStr, j.accessSecret)
+	return j.validateToken(tokenStr, j.accessMethod, j.accessVerifyKey)
 }
 
 // ValidateRefreshToken parses and validates a refresh token string, returning its MapClaims.
 func (j *jwtManager) ValidateRefreshToken(tokenStr string) (jwt.MapClaims, error) {
-	return j.validateToken(tokenStr, j.refreshSecret)
+	return j.validateToken(tokenStr, jwt.SigningMethodHS256, j.refreshSecret)
 }
 
 // GetAccessTokenTTL returns the configured access token time-to-live duration.
@@ -68,13 +135,36 @@ func (j *jwtManager) GetRefreshTokenTTL() time.Duration {
 	return j.refreshTokenTTL
 }
 
-// validateToken is a helper method to validate tokens with the provided secret.
-func (j *jwtManager) validateToken(tokenStr string, secret []byte) (jwt.MapClaims, error) {
+// AccessTokenTTLForRole returns the access token TTL configured for role, or
+// the global access token TTL.
+func (j *jwtManager) AccessTokenTTLForRole(role string) time.Duration {
+	if ttl, ok := j.roleAccessTTLs[strings.ToLower(role)]; ok {
+		return ttl
+	}
+	return j.accessTokenTTL
+}
+
+// RefreshTokenTTLForRole returns the refresh token TTL configured for role, or
+// the global refresh token TTL.
+func (j *jwtManager) RefreshTokenTTLForRole(role string) time.Duration {
+	if ttl, ok := j.roleRefreshTTLs[strings.ToLower(role)]; ok {
+		return ttl
+	}
+	return j.refreshTokenTTL
+}
+
+// PublicKeySet returns the public access token keys, empty in HS256 mode.
+func (j *jwtManager) PublicKeySet() JSONWebKeySet {
+	return j.publicKeys
+}
+
+// validateToken is a helper method to validate tokens signed with method using the provided key.
+func (j *jwtManager) validateToken(tokenStr string, method jwt.SigningMethod, key interface{}) (jwt.MapClaims, error) {
 	token, err := jwt.Parse(tokenStr, func(t *jwt.Token) (interface{}, error) {
-		if t.Method != jwt.SigningMethodHS256 {
+		if t.Method != method {
 			return nil, fmt.Errorf("unexpected signing method: %s", t.Method.Alg())
 		}
-		return secret, nil
+		return key, nil
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse token: %w", err)
@@ -87,3 +177,33 @@ func (j *jwtManager) validateToken(tokenStr string, secret []byte) (jwt.MapClaim
 
 	return claims, nil
 }
+
+// rsaPublicJWK encodes key as a JWK whose key ID is its RFC 7638 thumbprint.
+func rsaPublicJWK(key *rsa.PublicKey) JSONWebKey {
+	jwk := JSONWebKey{
+		Kty: "RSA",
+		Use: "sig",
+		Alg: JWTAlgorithmRS256,
+		N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}
+
+	// The thumbprint hashes the required members in lexicographic order
+	thumbprint, _ := json.Marshal(struct {
+		E   string `json:"e"`
+		Kty string `json:"kty"`
+		N   string `json:"n"`
+	}{jwk.E, jwk.Kty, jwk.N})
+	sum := sha256.Sum256(thumbprint)
+	jwk.Kid = base64.RawURLEncoding.EncodeToString(sum[:])
+	return jwk
+}
+
+// lowerKeys copies ttls with role names lowercased, for case-insensitive lookups.
+func lowerKeys(ttls map[string]time.Duration) map[string]time.Duration {
+	lowered := make(map[string]time.Duration, len(ttls))
+	for role, ttl := range ttls {
+		lowered[strings.ToLower(role)] = ttl
+	}
+	return lowered
+}
@@ -0,0 +1,114 @@
+package core
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func testRSAKeyPEM(t *testing.T) (*rsa.PrivateKey, string) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	block := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	return key, string(block)
+}
+
+func testJWTConfig(algorithm, privateKey string) *Config {
+	return &Config{JWT: JWTConfig{
+		Secret:          "access-secret",
+		RefreshSecret:   "refresh-secret",
+		AccessTokenTTL:  15 * time.Minute,
+		RefreshTokenTTL: time.Hour,
+		Algorithm:       algorithm,
+		PrivateKey:      privateKey,
+	}}
+}
+
+func TestJWTManagerRS256SignsAndValidatesAccessTokens(t *testing.T) {
+	key, keyPEM := testRSAKeyPEM(t)
+	tokens, err := NewJWT(testJWTConfig(JWTAlgorithmRS256, keyPEM))
+	if err != nil {
+		t.Fatalf("new jwt: %v", err)
+	}
+
+	signed, err := tokens.SignAccessToken(jwt.MapClaims{"sub": 42, "exp": time.Now().Add(time.Minute).Unix()})
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	claims, err := tokens.ValidateAccessToken(signed)
+	if err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+	if claims["sub"] != float64(42) {
+		t.Fatalf("unexpected claims %v", claims)
+	}
+
+	// Other services only need the public key to verify the token
+	keys := tokens.PublicKeySet().Keys
+	if len(keys) != 1 || keys[0].Alg != "RS256" || keys[0].Kid == "" {
+		t.Fatalf("unexpected key set %+v", keys)
+	}
+	parsed, err := jwt.Parse(signed, func(token *jwt.Token) (interface{}, error) {
+		if token.Header["kid"] != keys[0].Kid {
+			t.Fatalf("expected kid %q, got %v", keys[0].Kid, token.Header["kid"])
+		}
+		return &key.PublicKey, nil
+	}, jwt.WithValidMethods([]string{"RS256"}))
+	if err != nil || !parsed.Valid {
+		t.Fatalf("public key verification failed: %v", err)
+	}
+}
+
+func TestJWTManagerRS256RejectsSharedSecretTokens(t *testing.T) {
+	_, keyPEM := testRSAKeyPEM(t)
+	tokens, err := NewJWT(testJWTConfig(JWTAlgorithmRS256, keyPEM))
+	if err != nil {
+		t.Fatalf("new jwt: %v", err)
+	}
+
+	forged, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"sub": 1}).SignedString([]byte("access-secret"))
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	if _, err := tokens.ValidateAccessToken(forged); err == nil {
+		t.Fatalf("expected HS256 access token to be rejected in RS256 mode")
+	}
+
+	// Refresh tokens keep using the shared refresh secret
+	refresh, err := tokens.SignRefreshToken(jwt.MapClaims{"sub": 1})
+	if err != nil {
+		t.Fatalf("sign refresh: %v", err)
+	}
+	if _, err := tokens.ValidateRefreshToken(refresh); err != nil {
+		t.Fatalf("validate refresh: %v", err)
+	}
+}
+
+func TestNewJWTDefaultsToHS256(t *testing.T) {
+	tokens, err := NewJWT(testJWTConfig("", ""))
+	if err != nil {
+		t.Fatalf("new jwt: %v", err)
+	}
+	signed, err := tokens.SignAccessToken(jwt.MapClaims{"sub": 1})
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	if _, err := tokens.ValidateAccessToken(signed); err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+	if keys := tokens.PublicKeySet().Keys; len(keys) != 0 {
+		t.Fatalf("expected no public keys for HS256, got %+v", keys)
+	}
+
+	if _, err := NewJWT(testJWTConfig(JWTAlgorithmRS256, "not a key")); err == nil {
+		t.Fatalf("expected error for invalid private key")
+	}
+}
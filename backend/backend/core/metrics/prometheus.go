@@ -40,3 +40,14 @@ func NewPrometheusMetrics() (*PrometheusMetrics, error) {
 		Handler:  handler,
 	}, nil
 }
+
+// NewNoopMetrics returns metrics that record nothing and expose no scrape
+// endpoint, for when metrics are disabled.
+func NewNoopMetrics() *PrometheusMetrics {
+	return &PrometheusMetrics{Provider: sdkmetric.NewMeterProvider()}
+}
+
+// Enabled reports whether metrics are exported and Handler can be served
+func (m *PrometheusMetrics) Enabled() bool {
+	return m != nil && m.Handler != nil
+}
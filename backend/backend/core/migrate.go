@@ -2,18 +2,48 @@
 package core
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/pressly/goose/v3"
 	"go.uber.org/zap"
 )
 
+// DefaultMigrationLockTimeout bounds how long Migrate waits for another
+// instance to finish migrating.
+const DefaultMigrationLockTimeout = 5 * time.Minute
+
+// ErrMigrationLockTimeout is returned when the migration lock could not be acquired in time
+var ErrMigrationLockTimeout = errors.New("timed out waiting for migration lock")
+
+const (
+	// migrationLockID is the Postgres advisory lock key ("kthulu" in hex)
+	migrationLockID           int64 = 0x6b7468756c75
+	migrationLockPollInterval       = 200 * time.Millisecond
+)
+
 // Migrate applies all pending database migrations.
 // It uses goose to manage database schema evolution.
 func Migrate(db *sql.DB, logger *zap.Logger) error {
+	return MigrateWithLockTimeout(db, DefaultMigrationLockTimeout, logger)
+}
+
+// MigrateWithLockTimeout applies all pending migrations while holding a
+// database-wide migration lock, so concurrent instances migrate one at a
+// time. It waits at most timeout for the lock.
+func MigrateWithLockTimeout(db *sql.DB, timeout time.Duration, logger *zap.Logger) error {
+	unlock, err := acquireMigrationLock(db, timeout, logger)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
 	dir := filepath.Join("migrations")
 
 	logger.Info("Starting database migrations", zap.String("directory", dir))
@@ -55,6 +85,280 @@ func Migrate(db *sql.DB, logger *zap.Logger) error {
 	return nil
 }
 
+// acquireMigrationLock takes the migration lock and returns its release
+// function. Postgres uses a session advisory lock; SQLite, which has no
+// advisory locks, uses a single-row lock table.
+func acquireMigrationLock(db *sql.DB, timeout time.Duration, logger *zap.Logger) (func(), error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if driverName := fmt.Sprintf("%T", db.Driver()); strings.Contains(strings.ToLower(driverName), "sqlite") {
+		return acquireSQLiteMigrationLock(ctx, db, timeout, logger)
+	}
+	return acquirePostgresMigrationLock(ctx, db, timeout, logger)
+}
+
+func acquirePostgresMigrationLock(ctx context.Context, db *sql.DB, timeout time.Duration, logger *zap.Logger) (func(), error) {
+	// Advisory locks belong to a session, so keep one connection for lock and unlock
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire migration lock: %w", err)
+	}
+
+	err = waitForMigrationLock(ctx, timeout, logger, func() (bool, error) {
+		var locked bool
+		err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", migrationLockID).Scan(&locked)
+		return locked, err
+	})
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return func() {
+		if _, err := conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", migrationLockID); err != nil {
+			logger.Warn("Failed to release migration lock", zap.Error(err))
+		}
+		conn.Close()
+	}, nil
+}
+
+// acquireSQLiteMigrationLock takes the single-row lock, stamped with the
+// time in milliseconds. The holder refreshes the stamp while it migrates, so
+// a stamp older than timeout was left by an instance that died and the lock
+// is taken over.
+func acquireSQLiteMigrationLock(ctx context.Context, db *sql.DB, timeout time.Duration, logger *zap.Logger) (func(), error) {
+	if _, err := db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS kthulu_migration_lock (
+		id INTEGER PRIMARY KEY,
+		locked_at BIGINT NOT NULL
+	)`); err != nil {
+		return nil, fmt.Errorf("failed to create migration lock table: %w", err)
+	}
+
+	err := waitForMigrationLock(ctx, timeout, logger, func() (bool, error) {
+		now := time.Now()
+		res, err := db.ExecContext(ctx,
+			"INSERT INTO kthulu_migration_lock (id, locked_at) VALUES (1, ?) ON CONFLICT (id) DO NOTHING",
+			now.UnixMilli(),
+		)
+		if err != nil {
+			return false, err
+		}
+		if n, err := res.RowsAffected(); err != nil || n == 1 {
+			return n == 1, err
+		}
+
+		res, err = db.ExecContext(ctx,
+			"UPDATE kthulu_migration_lock SET locked_at = ? WHERE id = 1 AND locked_at < ?",
+			now.UnixMilli(), now.Add(-timeout).UnixMilli(),
+		)
+		if err != nil {
+			return false, err
+		}
+		n, err := res.RowsAffected()
+		if n == 1 {
+			logger.Warn("Took over a stale migration lock", zap.Duration("timeout", timeout))
+		}
+		return n == 1, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w (delete the kthulu_migration_lock row if no migration is running)", err)
+	}
+
+	// Keep the stamp fresh so waiting instances don't take the lock over
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(max(timeout/3, migrationLockPollInterval))
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				if _, err := db.Exec("UPDATE kthulu_migration_lock SET locked_at = ? WHERE id = 1", time.Now().UnixMilli()); err != nil {
+					logger.Warn("Failed to refresh migration lock", zap.Error(err))
+				}
+			}
+		}
+	}()
+
+	return func() {
+		close(stop)
+		<-done
+		if _, err := db.Exec("DELETE FROM kthulu_migration_lock WHERE id = 1"); err != nil {
+			logger.Warn("Failed to release migration lock", zap.Error(err))
+		}
+	}, nil
+}
+
+// waitForMigrationLock polls tryLock until it succeeds or ctx expires
+func waitForMigrationLock(ctx context.Context, timeout time.Duration, logger *zap.Logger, tryLock func() (bool, error)) error {
+	waiting := false
+	for {
+		locked, err := tryLock()
+		if err != nil && ctx.Err() == nil {
+			return fmt.Errorf("failed to acquire migration lock: %w", err)
+		}
+		if locked {
+			if waiting {
+				logger.Info("Acquired migration lock")
+			}
+			return nil
+		}
+
+		if !waiting {
+			logger.Info("Waiting for another instance to finish migrating", zap.Duration("timeout", timeout))
+			waiting = true
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%w after %s", ErrMigrationLockTimeout, timeout)
+		case <-time.After(migrationLockPollInterval):
+		}
+	}
+}
+
+// PendingMigration is a migration Migrate would apply, with its Up statements
+type PendingMigration struct {
+	Version    int64    `json:"version"`
+	Name       string   `json:"name"`
+	Statements []string `json:"statements"`
+}
+
+// MigrateDryRun reports the migrations and SQL statements Migrate would apply
+// without executing them. The goose version table is only read, never created.
+func MigrateDryRun(db *sql.DB, logger *zap.Logger) ([]PendingMigration, error) {
+	dir := filepath.Join("migrations")
+
+	logger.Info("Planning database migrations", zap.String("directory", dir))
+
+	dialect := "postgres"
+	if driverName := fmt.Sprintf("%T", db.Driver()); strings.Contains(strings.ToLower(driverName), "sqlite") {
+		dialect = "sqlite3"
+	}
+
+	if err := goose.SetDialect(dialect); err != nil {
+		return nil, fmt.Errorf("failed to set goose dialect: %w", err)
+	}
+
+	migrations, err := goose.CollectMigrations(dir, 0, goose.MaxVersion)
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect migrations: %w", err)
+	}
+
+	var currentVersion int64
+	exists, err := versionTableExists(context.Background(), db, dialect)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		applied, err := appliedMigrations(context.Background(), db)
+		if err != nil {
+			return nil, err
+		}
+		for version := range applied {
+			if version > currentVersion {
+				currentVersion = version
+			}
+		}
+	}
+
+	var pending []PendingMigration
+	for _, m := range migrations {
+		if m.Version <= currentVersion {
+			continue
+		}
+
+		migration := PendingMigration{Version: m.Version, Name: filepath.Base(m.Source)}
+		if filepath.Ext(m.Source) == ".sql" {
+			content, err := os.ReadFile(m.Source)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read migration %s: %w", m.Source, err)
+			}
+			migration.Statements = parseUpStatements(string(content))
+		}
+		pending = append(pending, migration)
+	}
+
+	logger.Info("Database migration plan ready",
+		zap.Int64("current_version", currentVersion),
+		zap.Int("pending", len(pending)),
+	)
+
+	return pending, nil
+}
+
+// versionTableExists reports whether the goose version table has been created
+func versionTableExists(ctx context.Context, db *sql.DB, dialect string) (bool, error) {
+	var exists bool
+	var err error
+	if dialect == "sqlite3" {
+		err = db.QueryRowContext(ctx, "SELECT COUNT(*) > 0 FROM sqlite_master WHERE type = 'table' AND name = ?", goose.TableName()).Scan(&exists)
+	} else {
+		err = db.QueryRowContext(ctx, "SELECT to_regclass($1) IS NOT NULL", goose.TableName()).Scan(&exists)
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to check migration version table: %w", err)
+	}
+	return exists, nil
+}
+
+// parseUpStatements extracts the statements of the Up section of a goose SQL
+// migration, honouring StatementBegin/StatementEnd blocks.
+func parseUpStatements(content string) []string {
+	var (
+		statements []string
+		buf        strings.Builder
+		inUp       bool
+		inBlock    bool
+	)
+
+	flush := func() {
+		if stmt := strings.TrimSpace(buf.String()); stmt != "" {
+			statements = append(statements, stmt)
+		}
+		buf.Reset()
+	}
+
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "-- +goose ") {
+			switch strings.TrimSpace(strings.TrimPrefix(trimmed, "-- +goose ")) {
+			case "Up":
+				inUp = true
+			case "Down":
+				flush()
+				return statements
+			case "StatementBegin":
+				inBlock = true
+			case "StatementEnd":
+				inBlock = false
+				flush()
+			}
+			continue
+		}
+
+		if !inUp {
+			continue
+		}
+		if buf.Len() == 0 && (trimmed == "" || strings.HasPrefix(trimmed, "--")) {
+			continue
+		}
+
+		buf.WriteString(line)
+		buf.WriteString("\n")
+		if !inBlock && strings.HasSuffix(trimmed, ";") {
+			flush()
+		}
+	}
+
+	flush()
+	return statements
+}
+
 // MigrateDown rolls back the last migration.
 func MigrateDown(db *sql.DB, logger *zap.Logger) error {
 	dir := filepath.Join("migrations")
@@ -97,7 +401,15 @@ func MigrateDown(db *sql.DB, logger *zap.Logger) error {
 	return nil
 }
 
-// MigrateToVersion migrates to a specific version
+// ErrUnknownMigrationVersion is returned when a target version is not part of the migration set
+var ErrUnknownMigrationVersion = errors.New("unknown migration version")
+
+// ErrMigrationGap is returned when reaching a target version would skip required migrations
+var ErrMigrationGap = errors.New("migration gap")
+
+// MigrateToVersion migrates up or down to a specific version. The target must
+// exist in the migration set (0 rolls back everything) and every migration
+// between the current and target version must be applicable.
 func MigrateToVersion(db *sql.DB, version int64, logger *zap.Logger) error {
 	dir := filepath.Join("migrations")
 
@@ -115,14 +427,49 @@ func MigrateToVersion(db *sql.DB, version int64, logger *zap.Logger) error {
 		return fmt.Errorf("failed to set goose dialect: %w", err)
 	}
 
+	migrations, err := goose.CollectMigrations(dir, 0, goose.MaxVersion)
+	if err != nil {
+		return fmt.Errorf("failed to collect migrations: %w", err)
+	}
+
 	currentVersion, err := goose.GetDBVersion(db)
 	if err != nil {
-		logger.Warn("Could not get current database version", zap.Error(err))
+		return fmt.Errorf("failed to get database version: %w", err)
+	}
+
+	applied, err := appliedMigrations(context.Background(), db)
+	if err != nil {
+		return err
 	}
 
-	if err := goose.UpTo(db, dir, version); err != nil {
+	known := make([]int64, 0, len(migrations))
+	for _, m := range migrations {
+		known = append(known, m.Version)
+	}
+
+	direction, err := planMigrationTo(known, applied, currentVersion, version)
+	if err != nil {
+		logger.Error("Refusing to migrate to version",
+			zap.Int64("current_version", currentVersion),
+			zap.Int64("target_version", version),
+			zap.Error(err),
+		)
+		return err
+	}
+
+	switch direction {
+	case migrateUp:
+		err = goose.UpTo(db, dir, version)
+	case migrateDown:
+		err = goose.DownTo(db, dir, version)
+	default:
+		logger.Info("Database already at target version", zap.Int64("version", version))
+		return nil
+	}
+	if err != nil {
 		logger.Error("Migration to version failed",
 			zap.Int64("target_version", version),
+			zap.String("direction", direction),
 			zap.Error(err),
 		)
 		return fmt.Errorf("failed to migrate to version %d: %w", version, err)
@@ -131,11 +478,86 @@ func MigrateToVersion(db *sql.DB, version int64, logger *zap.Logger) error {
 	logger.Info("Migration to version completed successfully",
 		zap.Int64("previous_version", currentVersion),
 		zap.Int64("target_version", version),
+		zap.String("direction", direction),
 	)
 
 	return nil
 }
 
+const (
+	migrateUp   = "up"
+	migrateDown = "down"
+	migrateNone = "none"
+)
+
+// planMigrationTo works out whether reaching target from current is an up or
+// down move. known holds the versions of the migration set and applied the
+// versions recorded as applied in the database.
+func planMigrationTo(known []int64, applied map[int64]time.Time, current, target int64) (string, error) {
+	knownSet := make(map[int64]bool, len(known))
+	for _, v := range known {
+		knownSet[v] = true
+	}
+
+	if target != 0 && !knownSet[target] {
+		return "", fmt.Errorf("%w: %d is not in the migration set", ErrUnknownMigrationVersion, target)
+	}
+
+	switch {
+	case target > current:
+		for _, v := range known {
+			if v <= current {
+				if _, ok := applied[v]; !ok {
+					return "", fmt.Errorf("%w: migration %d is pending below current version %d, apply it before migrating up", ErrMigrationGap, v, current)
+				}
+			}
+		}
+		return migrateUp, nil
+	case target < current:
+		for v := range applied {
+			if v > target && !knownSet[v] {
+				return "", fmt.Errorf("%w: applied migration %d has no migration file, cannot roll back to %d", ErrMigrationGap, v, target)
+			}
+		}
+		return migrateDown, nil
+	default:
+		return migrateNone, nil
+	}
+}
+
+// appliedMigrations returns the versions currently applied according to the
+// goose version table, with the time each one was applied.
+func appliedMigrations(ctx context.Context, db *sql.DB) (map[int64]time.Time, error) {
+	rows, err := db.QueryContext(ctx, fmt.Sprintf("SELECT version_id, is_applied, tstamp FROM %s ORDER BY id", goose.TableName()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list applied migrations: %w", err)
+	}
+	defer rows.Close()
+
+	applied := make(map[int64]time.Time)
+	for rows.Next() {
+		var (
+			version   int64
+			isApplied bool
+			appliedAt time.Time
+		)
+		if err := rows.Scan(&version, &isApplied, &appliedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan applied migration: %w", err)
+		}
+		// Later rows supersede earlier ones for the same version
+		if isApplied && version > 0 {
+			applied[version] = appliedAt
+		} else {
+			delete(applied, version)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list applied migrations: %w", err)
+	}
+
+	return applied, nil
+}
+
 // GetMigrationStatus returns the current migration status
 func GetMigrationStatus(db *sql.DB, logger *zap.Logger) (int64, error) {
 	if err := goose.SetDialect("postgres"); err != nil {
@@ -152,6 +574,83 @@ func GetMigrationStatus(db *sql.DB, logger *zap.Logger) (int64, error) {
 	return version, nil
 }
 
+// CurrentMigrationVersion returns the highest applied migration version, or 0
+// on a pristine database. Unlike GetMigrationStatus it never creates the goose
+// version table.
+func CurrentMigrationVersion(ctx context.Context, db *sql.DB) (int64, error) {
+	dialect := "postgres"
+	if driverName := fmt.Sprintf("%T", db.Driver()); strings.Contains(strings.ToLower(driverName), "sqlite") {
+		dialect = "sqlite3"
+	}
+
+	exists, err := versionTableExists(ctx, db, dialect)
+	if err != nil || !exists {
+		return 0, err
+	}
+	applied, err := appliedMigrations(ctx, db)
+	if err != nil {
+		return 0, err
+	}
+
+	var current int64
+	for version := range applied {
+		if version > current {
+			current = version
+		}
+	}
+	return current, nil
+}
+
+// MigrationState describes one migration of the migration set and whether it is applied
+type MigrationState struct {
+	Version   int64      `json:"version"`
+	Name      string     `json:"name"`
+	Applied   bool       `json:"applied"`
+	AppliedAt *time.Time `json:"applied_at,omitempty"`
+}
+
+// GetMigrationDetails returns every known migration, in version order, with
+// its applied or pending state.
+func GetMigrationDetails(db *sql.DB) ([]MigrationState, error) {
+	dir := filepath.Join("migrations")
+
+	dialect := "postgres"
+	if driverName := fmt.Sprintf("%T", db.Driver()); strings.Contains(strings.ToLower(driverName), "sqlite") {
+		dialect = "sqlite3"
+	}
+
+	if err := goose.SetDialect(dialect); err != nil {
+		return nil, fmt.Errorf("failed to set goose dialect: %w", err)
+	}
+
+	migrations, err := goose.CollectMigrations(dir, 0, goose.MaxVersion)
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect migrations: %w", err)
+	}
+
+	// Make sure the version table exists on a pristine database
+	if _, err := goose.EnsureDBVersion(db); err != nil {
+		return nil, fmt.Errorf("failed to get database version: %w", err)
+	}
+
+	applied, err := appliedMigrations(context.Background(), db)
+	if err != nil {
+		return nil, err
+	}
+
+	states := make([]MigrationState, 0, len(migrations))
+	for _, m := range migrations {
+		state := MigrationState{Version: m.Version, Name: filepath.Base(m.Source)}
+		if appliedAt, ok := applied[m.Version]; ok {
+			state.Applied = true
+			state.AppliedAt = &appliedAt
+		}
+		states = append(states, state)
+	}
+
+	return states, nil
+}
+
 // ValidateMigrations checks if all migrations are valid
 func ValidateMigrations(logger *zap.Logger) error {
 	dir := filepath.Join("migrations")
@@ -0,0 +1,316 @@
+package core
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/pressly/goose/v3"
+	"go.uber.org/zap"
+)
+
+// setupMigrationsDir creates three migrations in a temporary working
+// directory and returns an in-memory SQLite database.
+func setupMigrationsDir(t *testing.T) *sql.DB {
+	t.Helper()
+
+	dir := t.TempDir()
+	migrations := map[string]string{
+		"0001_create_a.sql": "-- +goose Up\nCREATE TABLE a (id INTEGER);\n-- +goose Down\nDROP TABLE a;\n",
+		"0002_create_b.sql": "-- +goose Up\nCREATE TABLE b (id INTEGER);\n-- +goose Down\nDROP TABLE b;\n",
+		"0003_create_c.sql": "-- +goose Up\nCREATE TABLE c (id INTEGER);\n-- +goose Down\nDROP TABLE c;\n",
+	}
+	if err := os.Mkdir(filepath.Join(dir, "migrations"), 0o755); err != nil {
+		t.Fatalf("mkdir migrations: %v", err)
+	}
+	for name, content := range migrations {
+		if err := os.WriteFile(filepath.Join(dir, "migrations", name), []byte(content), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+	t.Chdir(dir)
+
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func dbVersion(t *testing.T, db *sql.DB) int64 {
+	t.Helper()
+	version, err := goose.GetDBVersion(db)
+	if err != nil {
+		t.Fatalf("get db version: %v", err)
+	}
+	return version
+}
+
+func TestMigrateToVersionUpThenDown(t *testing.T) {
+	db := setupMigrationsDir(t)
+	logger := zap.NewNop()
+
+	if err := MigrateToVersion(db, 3, logger); err != nil {
+		t.Fatalf("migrate up: %v", err)
+	}
+	if v := dbVersion(t, db); v != 3 {
+		t.Fatalf("expected version 3, got %d", v)
+	}
+
+	if err := MigrateToVersion(db, 1, logger); err != nil {
+		t.Fatalf("migrate down: %v", err)
+	}
+	if v := dbVersion(t, db); v != 1 {
+		t.Fatalf("expected version 1, got %d", v)
+	}
+	if _, err := db.Exec("SELECT id FROM b"); err == nil {
+		t.Fatalf("expected table b to be dropped")
+	}
+	if _, err := db.Exec("SELECT id FROM a"); err != nil {
+		t.Fatalf("expected table a to remain: %v", err)
+	}
+}
+
+func TestMigrateToVersionRejectsUnknownVersion(t *testing.T) {
+	db := setupMigrationsDir(t)
+
+	err := MigrateToVersion(db, 7, zap.NewNop())
+	if !errors.Is(err, ErrUnknownMigrationVersion) {
+		t.Fatalf("expected ErrUnknownMigrationVersion, got %v", err)
+	}
+	if v := dbVersion(t, db); v != 0 {
+		t.Fatalf("database should be untouched, got version %d", v)
+	}
+}
+
+func TestPlanMigrationToRejectsGaps(t *testing.T) {
+	known := []int64{1, 2, 3}
+
+	// Version 2 was never applied although the database is at 3
+	if _, err := planMigrationTo(append(known, 4), map[int64]time.Time{1: {}, 3: {}}, 3, 4); !errors.Is(err, ErrMigrationGap) {
+		t.Fatalf("expected gap when migrating up over pending migration, got %v", err)
+	}
+
+	// Version 5 is applied but its migration file is gone
+	if _, err := planMigrationTo(known, map[int64]time.Time{1: {}, 2: {}, 3: {}, 5: {}}, 5, 1); !errors.Is(err, ErrMigrationGap) {
+		t.Fatalf("expected gap when rolling back unknown migration, got %v", err)
+	}
+}
+
+func TestGetMigrationDetailsReportsAppliedAndPending(t *testing.T) {
+	db := setupMigrationsDir(t)
+
+	if err := MigrateToVersion(db, 2, zap.NewNop()); err != nil {
+		t.Fatalf("migrate up: %v", err)
+	}
+
+	states, err := GetMigrationDetails(db)
+	if err != nil {
+		t.Fatalf("get migration details: %v", err)
+	}
+	if len(states) != 3 {
+		t.Fatalf("expected 3 migrations, got %d", len(states))
+	}
+
+	for i, state := range states {
+		if state.Version != int64(i+1) {
+			t.Fatalf("expected version %d at position %d, got %d", i+1, i, state.Version)
+		}
+		wantApplied := state.Version <= 2
+		if state.Applied != wantApplied {
+			t.Fatalf("migration %s: expected applied=%v, got %v", state.Name, wantApplied, state.Applied)
+		}
+		if wantApplied && (state.AppliedAt == nil || state.AppliedAt.IsZero()) {
+			t.Fatalf("migration %s: expected applied-at timestamp", state.Name)
+		}
+		if !wantApplied && state.AppliedAt != nil {
+			t.Fatalf("pending migration %s should have no timestamp", state.Name)
+		}
+	}
+	if states[2].Name != "0003_create_c.sql" {
+		t.Fatalf("unexpected migration name %q", states[2].Name)
+	}
+}
+
+func TestMigrateDryRunReportsPendingWithoutApplying(t *testing.T) {
+	db := setupMigrationsDir(t)
+
+	if err := MigrateToVersion(db, 1, zap.NewNop()); err != nil {
+		t.Fatalf("migrate up: %v", err)
+	}
+	var rowsBefore int
+	if err := db.QueryRow("SELECT COUNT(*) FROM " + goose.TableName()).Scan(&rowsBefore); err != nil {
+		t.Fatalf("count version rows: %v", err)
+	}
+
+	pending, err := MigrateDryRun(db, zap.NewNop())
+	if err != nil {
+		t.Fatalf("dry run: %v", err)
+	}
+	if len(pending) != 2 || pending[0].Version != 2 || pending[1].Version != 3 {
+		t.Fatalf("expected migrations 2 and 3 pending, got %+v", pending)
+	}
+	if len(pending[0].Statements) != 1 || pending[0].Statements[0] != "CREATE TABLE b (id INTEGER);" {
+		t.Fatalf("unexpected statements %q", pending[0].Statements)
+	}
+
+	var rowsAfter int
+	if err := db.QueryRow("SELECT COUNT(*) FROM " + goose.TableName()).Scan(&rowsAfter); err != nil {
+		t.Fatalf("count version rows: %v", err)
+	}
+	if rowsAfter != rowsBefore {
+		t.Fatalf("dry run changed the version table: %d -> %d rows", rowsBefore, rowsAfter)
+	}
+	if _, err := db.Exec("SELECT id FROM b"); err == nil {
+		t.Fatalf("dry run should not create table b")
+	}
+}
+
+func TestMigrateDryRunOnPristineDatabase(t *testing.T) {
+	db := setupMigrationsDir(t)
+
+	pending, err := MigrateDryRun(db, zap.NewNop())
+	if err != nil {
+		t.Fatalf("dry run: %v", err)
+	}
+	if len(pending) != 3 {
+		t.Fatalf("expected all migrations pending, got %d", len(pending))
+	}
+
+	exists, err := versionTableExists(context.Background(), db, "sqlite3")
+	if err != nil {
+		t.Fatalf("check version table: %v", err)
+	}
+	if exists {
+		t.Fatalf("dry run should not create the version table")
+	}
+}
+
+func TestParseUpStatements(t *testing.T) {
+	content := `-- +goose Up
+-- create the table
+CREATE TABLE t (
+    id INTEGER
+);
+INSERT INTO t VALUES (1);
+
+-- +goose StatementBegin
+CREATE TRIGGER trg AFTER INSERT ON t BEGIN
+    UPDATE t SET id = id;
+END;
+-- +goose StatementEnd
+
+-- +goose Down
+DROP TABLE t;
+`
+	stmts := parseUpStatements(content)
+	if len(stmts) != 3 {
+		t.Fatalf("expected 3 statements, got %d: %q", len(stmts), stmts)
+	}
+	if stmts[0] != "CREATE TABLE t (\n    id INTEGER\n);" {
+		t.Fatalf("unexpected first statement %q", stmts[0])
+	}
+	if stmts[2] != "CREATE TRIGGER trg AFTER INSERT ON t BEGIN\n    UPDATE t SET id = id;\nEND;" {
+		t.Fatalf("unexpected trigger statement %q", stmts[2])
+	}
+}
+
+func TestMigrateConcurrentCallsApplyOnce(t *testing.T) {
+	setupMigrationsDir(t)
+	dsn := "file:" + filepath.Join(t.TempDir(), "race.db") + "?_pragma=busy_timeout(5000)"
+
+	// Two handles on the same file simulate two instances starting together
+	instances := make([]*sql.DB, 2)
+	for i := range instances {
+		db, err := sql.Open("sqlite", dsn)
+		if err != nil {
+			t.Fatalf("open db: %v", err)
+		}
+		t.Cleanup(func() { db.Close() })
+		instances[i] = db
+	}
+
+	errs := make(chan error, len(instances))
+	for _, db := range instances {
+		go func(db *sql.DB) {
+			errs <- MigrateWithLockTimeout(db, 10*time.Second, zap.NewNop())
+		}(db)
+	}
+	for range instances {
+		if err := <-errs; err != nil {
+			t.Fatalf("concurrent migrate: %v", err)
+		}
+	}
+
+	var applied int
+	if err := instances[0].QueryRow("SELECT COUNT(*) FROM " + goose.TableName() + " WHERE version_id > 0").Scan(&applied); err != nil {
+		t.Fatalf("count applied migrations: %v", err)
+	}
+	if applied != 3 {
+		t.Fatalf("expected each migration applied once, got %d version rows", applied)
+	}
+
+	var locks int
+	if err := instances[0].QueryRow("SELECT COUNT(*) FROM kthulu_migration_lock").Scan(&locks); err != nil {
+		t.Fatalf("count locks: %v", err)
+	}
+	if locks != 0 {
+		t.Fatalf("migration lock should be released, found %d rows", locks)
+	}
+}
+
+func TestMigrateLockTimeout(t *testing.T) {
+	db := setupMigrationsDir(t)
+
+	unlock, err := acquireMigrationLock(db, time.Second, zap.NewNop())
+	if err != nil {
+		t.Fatalf("acquire lock: %v", err)
+	}
+	defer unlock()
+
+	err = MigrateWithLockTimeout(db, 300*time.Millisecond, zap.NewNop())
+	if !errors.Is(err, ErrMigrationLockTimeout) {
+		t.Fatalf("expected lock timeout, got %v", err)
+	}
+}
+
+func TestMigrateTakesOverStaleSQLiteLock(t *testing.T) {
+	db := setupMigrationsDir(t)
+
+	// An instance that died mid-migration left its lock behind
+	if _, err := db.Exec(`CREATE TABLE kthulu_migration_lock (id INTEGER PRIMARY KEY, locked_at BIGINT NOT NULL)`); err != nil {
+		t.Fatalf("create lock table: %v", err)
+	}
+	if _, err := db.Exec("INSERT INTO kthulu_migration_lock (id, locked_at) VALUES (1, ?)", time.Now().Add(-time.Hour).UnixMilli()); err != nil {
+		t.Fatalf("insert stale lock: %v", err)
+	}
+
+	if err := MigrateWithLockTimeout(db, time.Second, zap.NewNop()); err != nil {
+		t.Fatalf("expected the stale lock to be taken over, got %v", err)
+	}
+	if version := dbVersion(t, db); version != 3 {
+		t.Fatalf("expected all migrations applied, got version %d", version)
+	}
+}
+
+func TestSQLiteMigrationLockStaysFreshWhileHeld(t *testing.T) {
+	db := setupMigrationsDir(t)
+
+	unlock, err := acquireMigrationLock(db, 300*time.Millisecond, zap.NewNop())
+	if err != nil {
+		t.Fatalf("acquire lock: %v", err)
+	}
+	defer unlock()
+
+	// A waiter outlasting the holder's timeout must not steal a live lock
+	time.Sleep(500 * time.Millisecond)
+	if _, err := acquireMigrationLock(db, 300*time.Millisecond, zap.NewNop()); !errors.Is(err, ErrMigrationLockTimeout) {
+		t.Fatalf("expected a live lock to be kept, got %v", err)
+	}
+}
@@ -8,6 +8,7 @@ import "go.uber.org/fx"
 var Module = fx.Options(
 	fx.Provide(
 		NewDB,            // Provides *sql.DB
+		NewReadReplica,   // Provides *ReadReplica (nil without DATABASE_REPLICA_URL)
 		NewGormDB,        // Provides *gorm.DB (wraps sql.DB)
 		NewZapLogger,     // Provides *zap.Logger - this is what most handlers need
 		NewLogger,        // Provides core.Logger interface (wraps zap)
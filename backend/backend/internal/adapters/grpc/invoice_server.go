@@ -0,0 +1,356 @@
+// @kthulu:module:grpc
+package adaptergrpc
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/pmaojo/kthulu-go/backend/internal/adapters/grpc/invoicepb"
+	"github.com/pmaojo/kthulu-go/backend/internal/domain"
+	"github.com/pmaojo/kthulu-go/backend/internal/domain/repository"
+	"github.com/pmaojo/kthulu-go/backend/internal/usecase"
+)
+
+// InvoiceServer serves the invoice gRPC service from the invoice use case
+type InvoiceServer struct {
+	invoicepb.UnimplementedInvoiceServiceServer
+
+	invoices  *usecase.InvoiceUseCase
+	orgUsers  repository.OrganizationUserRepository
+	validator *validator.Validate
+	logger    *zap.Logger
+}
+
+// NewInvoiceServer creates an invoice gRPC service
+func NewInvoiceServer(invoices *usecase.InvoiceUseCase, orgUsers repository.OrganizationUserRepository, logger *zap.Logger) *InvoiceServer {
+	return &InvoiceServer{
+		invoices:  invoices,
+		orgUsers:  orgUsers,
+		validator: validator.New(),
+		logger:    logger,
+	}
+}
+
+// CreateInvoice creates an invoice on behalf of the authenticated user
+func (s *InvoiceServer) CreateInvoice(ctx context.Context, req *invoicepb.CreateInvoiceRequest) (*invoicepb.Invoice, error) {
+	if err := authorize(ctx, s.orgUsers, req.GetOrganizationId(), s.logger); err != nil {
+		return nil, err
+	}
+
+	create := usecase.CreateInvoiceRequest{
+		OrganizationID:  uint(req.GetOrganizationId()),
+		ContactID:       uint(req.GetContactId()),
+		Type:            domain.InvoiceType(req.GetType()),
+		Currency:        req.GetCurrency(),
+		IssueDate:       fromTimestamp(req.GetIssueDate()),
+		DueDate:         fromOptionalTimestamp(req.GetDueDate()),
+		PaymentTerms:    req.GetPaymentTerms(),
+		Notes:           req.GetNotes(),
+		TermsConditions: req.GetTermsConditions(),
+		CreatedBy:       userID(ctx),
+	}
+	for _, item := range req.GetItems() {
+		create.Items = append(create.Items, usecase.CreateInvoiceItemRequest{
+			ProductID:        optionalID(item.ProductId),
+			ProductVariantID: optionalID(item.ProductVariantId),
+			Description:      item.GetDescription(),
+			Quantity:         item.GetQuantity(),
+			UnitPrice:        item.GetUnitPrice(),
+			DiscountPercent:  item.GetDiscountPercent(),
+			DiscountAmount:   item.GetDiscountAmount(),
+			TaxRate:          item.GetTaxRate(),
+			LineTotal:        item.LineTotal,
+		})
+	}
+	if err := s.validator.Struct(create); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "validation failed: %v", err)
+	}
+
+	invoice, err := s.invoices.CreateInvoice(ctx, create)
+	if err != nil {
+		return nil, s.toStatus(err)
+	}
+	return toInvoice(invoice), nil
+}
+
+// GetInvoice returns an invoice, with its items and payments when asked for
+func (s *InvoiceServer) GetInvoice(ctx context.Context, req *invoicepb.GetInvoiceRequest) (*invoicepb.Invoice, error) {
+	if err := authorize(ctx, s.orgUsers, req.GetOrganizationId(), s.logger); err != nil {
+		return nil, err
+	}
+	organizationID, invoiceID := uint(req.GetOrganizationId()), uint(req.GetInvoiceId())
+
+	invoice, err := s.invoices.GetInvoice(ctx, organizationID, invoiceID)
+	if err != nil {
+		return nil, s.toStatus(err)
+	}
+	resp := toInvoice(invoice)
+
+	if req.GetIncludeItems() {
+		items, err := s.invoices.GetInvoiceItems(ctx, organizationID, invoiceID)
+		if err != nil {
+			return nil, s.toStatus(err)
+		}
+		resp.Items = make([]*invoicepb.InvoiceItem, 0, len(items))
+		for _, item := range items {
+			resp.Items = append(resp.Items, toInvoiceItem(item))
+		}
+	}
+	if req.GetIncludePayments() {
+		payments, err := s.invoices.GetInvoicePayments(ctx, organizationID, invoiceID)
+		if err != nil {
+			return nil, s.toStatus(err)
+		}
+		resp.Payments = make([]*invoicepb.Payment, 0, len(payments))
+		for _, payment := range payments {
+			resp.Payments = append(resp.Payments, toPayment(payment))
+		}
+	}
+	return resp, nil
+}
+
+// GetInvoiceByNumber returns the invoice with the given number
+func (s *InvoiceServer) GetInvoiceByNumber(ctx context.Context, req *invoicepb.GetInvoiceByNumberRequest) (*invoicepb.Invoice, error) {
+	if err := authorize(ctx, s.orgUsers, req.GetOrganizationId(), s.logger); err != nil {
+		return nil, err
+	}
+	if req.GetInvoiceNumber() == "" {
+		return nil, status.Error(codes.InvalidArgument, "invoice_number is required")
+	}
+
+	invoice, err := s.invoices.GetInvoiceByNumber(ctx, uint(req.GetOrganizationId()), req.GetInvoiceNumber())
+	if err != nil {
+		return nil, s.toStatus(err)
+	}
+	return toInvoice(invoice), nil
+}
+
+// UpdateInvoice replaces the editable fields of an invoice
+func (s *InvoiceServer) UpdateInvoice(ctx context.Context, req *invoicepb.UpdateInvoiceRequest) (*invoicepb.Invoice, error) {
+	if err := authorize(ctx, s.orgUsers, req.GetOrganizationId(), s.logger); err != nil {
+		return nil, err
+	}
+
+	update := usecase.UpdateInvoiceRequest{
+		ContactID:       uint(req.GetContactId()),
+		DueDate:         fromOptionalTimestamp(req.GetDueDate()),
+		PaymentTerms:    req.GetPaymentTerms(),
+		Notes:           req.GetNotes(),
+		TermsConditions: req.GetTermsConditions(),
+	}
+	if err := s.validator.Struct(update); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "validation failed: %v", err)
+	}
+
+	invoice, err := s.invoices.UpdateInvoice(ctx, uint(req.GetOrganizationId()), uint(req.GetInvoiceId()), update)
+	if err != nil {
+		return nil, s.toStatus(err)
+	}
+	return toInvoice(invoice), nil
+}
+
+// DeleteInvoice deletes an invoice
+func (s *InvoiceServer) DeleteInvoice(ctx context.Context, req *invoicepb.DeleteInvoiceRequest) (*invoicepb.DeleteInvoiceResponse, error) {
+	if err := authorize(ctx, s.orgUsers, req.GetOrganizationId(), s.logger); err != nil {
+		return nil, err
+	}
+	if err := s.invoices.DeleteInvoice(ctx, uint(req.GetOrganizationId()), uint(req.GetInvoiceId())); err != nil {
+		return nil, s.toStatus(err)
+	}
+	return &invoicepb.DeleteInvoiceResponse{}, nil
+}
+
+// ListInvoices returns a page of the organization's invoices
+func (s *InvoiceServer) ListInvoices(ctx context.Context, req *invoicepb.ListInvoicesRequest) (*invoicepb.ListInvoicesResponse, error) {
+	if err := authorize(ctx, s.orgUsers, req.GetOrganizationId(), s.logger); err != nil {
+		return nil, err
+	}
+
+	filters := repository.InvoiceFilters{
+		ContactID:       optionalID(req.ContactId),
+		Search:          req.GetSearch(),
+		Page:            int(req.GetPage()),
+		PageSize:        int(req.GetPageSize()),
+		IncludeItems:    req.GetIncludeItems(),
+		IncludePayments: req.GetIncludePayments(),
+	}
+	if req.GetType() != "" {
+		invoiceType := domain.InvoiceType(req.GetType())
+		filters.Type = &invoiceType
+	}
+	if req.GetStatus() != "" {
+		invoiceStatus := domain.InvoiceStatus(req.GetStatus())
+		filters.Status = &invoiceStatus
+	}
+
+	list, err := s.invoices.ListInvoices(ctx, uint(req.GetOrganizationId()), filters)
+	if err != nil {
+		return nil, s.toStatus(err)
+	}
+	resp := &invoicepb.ListInvoicesResponse{
+		Invoices:   make([]*invoicepb.Invoice, 0, len(list.Data)),
+		Page:       int32(list.Page),
+		PageSize:   int32(list.PageSize),
+		Total:      list.Total,
+		TotalPages: list.TotalPages,
+	}
+	for _, invoice := range list.Data {
+		resp.Invoices = append(resp.Invoices, toInvoice(invoice))
+	}
+	return resp, nil
+}
+
+// GetInvoiceStats returns the organization's invoice statistics
+func (s *InvoiceServer) GetInvoiceStats(ctx context.Context, req *invoicepb.GetInvoiceStatsRequest) (*invoicepb.InvoiceStats, error) {
+	if err := authorize(ctx, s.orgUsers, req.GetOrganizationId(), s.logger); err != nil {
+		return nil, err
+	}
+
+	stats, err := s.invoices.GetInvoiceStats(ctx, uint(req.GetOrganizationId()))
+	if err != nil {
+		return nil, s.toStatus(err)
+	}
+	return &invoicepb.InvoiceStats{
+		TotalInvoices:       stats.TotalInvoices,
+		DraftInvoices:       stats.DraftInvoices,
+		SentInvoices:        stats.SentInvoices,
+		PaidInvoices:        stats.PaidInvoices,
+		OverdueInvoices:     stats.OverdueInvoices,
+		CanceledInvoices:    stats.CancelledInvoices,
+		TotalRevenue:        stats.TotalRevenue,
+		PaidRevenue:         stats.PaidRevenue,
+		OutstandingAmount:   stats.OutstandingAmount,
+		OverdueAmount:       stats.OverdueAmount,
+		AverageInvoiceValue: stats.AverageInvoiceValue,
+		AveragePaymentTime:  stats.AveragePaymentTime,
+	}, nil
+}
+
+// toStatus maps use case errors to gRPC status codes, hiding unexpected ones
+// from the caller
+func (s *InvoiceServer) toStatus(err error) error {
+	switch {
+	case errors.Is(err, domain.ErrInvoiceNotFound), errors.Is(err, domain.ErrContactNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, domain.ErrInvoiceAlreadyExists):
+		return status.Error(codes.AlreadyExists, err.Error())
+	case errors.Is(err, domain.ErrInvoiceNotEditable), errors.Is(err, domain.ErrIllegalStatusTransition):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, domain.ErrInvalidLineItem),
+		errors.Is(err, domain.ErrInvalidInvoiceType),
+		errors.Is(err, domain.ErrInvalidInvoiceStatus),
+		errors.Is(err, domain.ErrInvalidInvoiceField),
+		errors.Is(err, domain.ErrInvalidAmount):
+		return status.Error(codes.InvalidArgument, err.Error())
+	default:
+		s.logger.Error("Invoice gRPC call failed", zap.Error(err))
+		return status.Error(codes.Internal, "internal error")
+	}
+}
+
+func toInvoice(invoice *domain.Invoice) *invoicepb.Invoice {
+	resp := &invoicepb.Invoice{
+		Id:              uint64(invoice.ID),
+		OrganizationId:  uint64(invoice.OrganizationID),
+		ContactId:       uint64(invoice.ContactID),
+		InvoiceNumber:   invoice.InvoiceNumber,
+		Type:            string(invoice.Type),
+		Status:          string(invoice.Status),
+		Currency:        invoice.Currency,
+		ExchangeRate:    invoice.ExchangeRate,
+		Subtotal:        invoice.Subtotal,
+		TaxAmount:       invoice.TaxAmount,
+		DiscountAmount:  invoice.DiscountAmount,
+		TotalAmount:     invoice.TotalAmount,
+		PaidAmount:      invoice.PaidAmount,
+		BalanceDue:      invoice.BalanceDue,
+		IssueDate:       timestamppb.New(invoice.IssueDate),
+		PaymentTerms:    invoice.PaymentTerms,
+		Notes:           invoice.Notes,
+		TermsConditions: invoice.TermsConditions,
+		CreatedBy:       uint64(invoice.CreatedBy),
+		CreatedAt:       timestamppb.New(invoice.CreatedAt),
+		UpdatedAt:       timestamppb.New(invoice.UpdatedAt),
+	}
+	if invoice.DueDate != nil {
+		resp.DueDate = timestamppb.New(*invoice.DueDate)
+	}
+	for i := range invoice.Items {
+		resp.Items = append(resp.Items, toInvoiceItem(&invoice.Items[i]))
+	}
+	for i := range invoice.Payments {
+		resp.Payments = append(resp.Payments, toPayment(&invoice.Payments[i]))
+	}
+	return resp
+}
+
+func toInvoiceItem(item *domain.InvoiceItem) *invoicepb.InvoiceItem {
+	return &invoicepb.InvoiceItem{
+		Id:               uint64(item.ID),
+		InvoiceId:        uint64(item.InvoiceID),
+		ProductId:        protoID(item.ProductID),
+		ProductVariantId: protoID(item.ProductVariantID),
+		Description:      item.Description,
+		Quantity:         item.Quantity,
+		UnitPrice:        item.UnitPrice,
+		DiscountPercent:  item.DiscountPercent,
+		DiscountAmount:   item.DiscountAmount,
+		TaxRate:          item.TaxRate,
+		TaxAmount:        item.TaxAmount,
+		LineTotal:        item.LineTotal,
+		SortOrder:        int32(item.SortOrder),
+	}
+}
+
+func toPayment(payment *domain.Payment) *invoicepb.Payment {
+	return &invoicepb.Payment{
+		Id:              uint64(payment.ID),
+		InvoiceId:       uint64(payment.InvoiceID),
+		PaymentMethod:   string(payment.PaymentMethod),
+		ReferenceNumber: payment.ReferenceNumber,
+		Amount:          payment.Amount,
+		Currency:        payment.Currency,
+		PaymentDate:     timestamppb.New(payment.PaymentDate),
+		Notes:           payment.Notes,
+	}
+}
+
+// fromTimestamp converts a timestamp to a time, leaving unset ones zero so the
+// validator reports them as missing
+func fromTimestamp(ts *timestamppb.Timestamp) time.Time {
+	if ts == nil {
+		return time.Time{}
+	}
+	return ts.AsTime()
+}
+
+func fromOptionalTimestamp(ts *timestamppb.Timestamp) *time.Time {
+	if ts == nil {
+		return nil
+	}
+	t := ts.AsTime()
+	return &t
+}
+
+func optionalID(id *uint64) *uint {
+	if id == nil {
+		return nil
+	}
+	v := uint(*id)
+	return &v
+}
+
+func protoID(id *uint) *uint64 {
+	if id == nil {
+		return nil
+	}
+	v := uint64(*id)
+	return &v
+}
@@ -0,0 +1,185 @@
+package adaptergrpc
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/pmaojo/kthulu-go/backend/core"
+	"github.com/pmaojo/kthulu-go/backend/internal/adapters/grpc/invoicepb"
+	"github.com/pmaojo/kthulu-go/backend/internal/domain"
+	"github.com/pmaojo/kthulu-go/backend/internal/domain/money"
+	"github.com/pmaojo/kthulu-go/backend/internal/domain/repository"
+	"github.com/pmaojo/kthulu-go/backend/internal/usecase"
+)
+
+// memoryInvoiceRepository keeps invoices and their items in memory
+type memoryInvoiceRepository struct {
+	repository.InvoiceRepository
+
+	invoices map[uint]*domain.Invoice
+	items    map[uint][]*domain.InvoiceItem
+}
+
+func (m *memoryInvoiceRepository) GenerateInvoiceNumber(ctx context.Context, organizationID uint, invoiceType domain.InvoiceType) (string, error) {
+	return fmt.Sprintf("INV-%04d", len(m.invoices)+1), nil
+}
+
+func (m *memoryInvoiceRepository) Create(ctx context.Context, invoice *domain.Invoice) error {
+	invoice.ID = uint(len(m.invoices) + 1)
+	m.invoices[invoice.ID] = invoice
+	return nil
+}
+
+func (m *memoryInvoiceRepository) CreateItem(ctx context.Context, item *domain.InvoiceItem) error {
+	item.ID = uint(len(m.items[item.InvoiceID]) + 1)
+	m.items[item.InvoiceID] = append(m.items[item.InvoiceID], item)
+	return nil
+}
+
+func (m *memoryInvoiceRepository) Update(ctx context.Context, invoice *domain.Invoice) error {
+	m.invoices[invoice.ID] = invoice
+	return nil
+}
+
+func (m *memoryInvoiceRepository) GetByID(ctx context.Context, organizationID, invoiceID uint) (*domain.Invoice, error) {
+	invoice, ok := m.invoices[invoiceID]
+	if !ok || invoice.OrganizationID != organizationID {
+		return nil, domain.ErrInvoiceNotFound
+	}
+	return invoice, nil
+}
+
+func (m *memoryInvoiceRepository) GetItemsByInvoiceID(ctx context.Context, invoiceID uint) ([]*domain.InvoiceItem, error) {
+	return m.items[invoiceID], nil
+}
+
+type memoryUnitOfWork struct {
+	invoices *memoryInvoiceRepository
+}
+
+func (u memoryUnitOfWork) Do(ctx context.Context, fn func(repos repository.TxRepositories) error) error {
+	return fn(repository.TxRepositories{Invoices: u.invoices})
+}
+
+// memberships lets user 1 into organization 1 only
+type memberships struct {
+	repository.OrganizationUserRepository
+}
+
+func (memberships) IsUserInOrganization(ctx context.Context, organizationID, userID uint) (bool, error) {
+	return organizationID == 1 && userID == 1, nil
+}
+
+// newTestClient serves the gRPC server over an in-memory connection and
+// returns a client for it along with an access token for user 1
+func newTestClient(t *testing.T) (invoicepb.InvoiceServiceClient, string) {
+	t.Helper()
+	tokens, err := core.NewJWT(&core.Config{JWT: core.JWTConfig{Secret: "access-secret", RefreshSecret: "refresh-secret"}})
+	if err != nil {
+		t.Fatalf("new jwt: %v", err)
+	}
+	token, err := tokens.SignAccessToken(jwt.MapClaims{"sub": 1, "exp": time.Now().Add(time.Minute).Unix()})
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+
+	invoices := &memoryInvoiceRepository{invoices: map[uint]*domain.Invoice{}, items: map[uint][]*domain.InvoiceItem{}}
+	logger := zap.NewNop()
+	srv := NewServer(ServerParams{
+		InvoiceUC:    usecase.NewInvoiceUseCase(invoices, memoryUnitOfWork{invoices}, nil, nil, nil, nil, nil, nil, nil, nil, nil, money.DefaultPolicy(), core.NewLoggerFromZap(logger)),
+		OrgUsers:     memberships{},
+		TokenManager: tokens,
+		Logger:       logger,
+	})
+
+	lis := bufconn.Listen(1 << 20)
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return invoicepb.NewInvoiceServiceClient(conn), token
+}
+
+func withToken(token string) context.Context {
+	return metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+token)
+}
+
+func TestInvoiceServer_CreateAndGet(t *testing.T) {
+	client, token := newTestClient(t)
+	ctx := withToken(token)
+
+	productID := uint64(5)
+	created, err := client.CreateInvoice(ctx, &invoicepb.CreateInvoiceRequest{
+		OrganizationId: 1,
+		ContactId:      10,
+		Type:           "invoice",
+		Currency:       "EUR",
+		IssueDate:      timestamppb.New(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)),
+		Items: []*invoicepb.CreateInvoiceItem{
+			{ProductId: &productID, Description: "Widget", Quantity: 2, UnitPrice: 50, TaxRate: 0.21},
+		},
+	})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if created.GetInvoiceNumber() != "INV-0001" || created.GetCreatedBy() != 1 || created.GetTotalAmount() != 121 {
+		t.Fatalf("unexpected invoice %+v", created)
+	}
+
+	got, err := client.GetInvoice(ctx, &invoicepb.GetInvoiceRequest{OrganizationId: 1, InvoiceId: created.GetId(), IncludeItems: true})
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if got.GetInvoiceNumber() != "INV-0001" || !got.GetIssueDate().AsTime().Equal(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected invoice %+v", got)
+	}
+	if len(got.GetItems()) != 1 || got.GetItems()[0].GetProductId() != 5 || got.GetItems()[0].GetLineTotal() != 121 {
+		t.Fatalf("unexpected items %+v", got.GetItems())
+	}
+}
+
+func TestInvoiceServer_Errors(t *testing.T) {
+	client, token := newTestClient(t)
+
+	for name, tc := range map[string]struct {
+		ctx  context.Context
+		req  *invoicepb.GetInvoiceRequest
+		code codes.Code
+	}{
+		"no token":        {context.Background(), &invoicepb.GetInvoiceRequest{OrganizationId: 1, InvoiceId: 1}, codes.Unauthenticated},
+		"invalid token":   {withToken("nope"), &invoicepb.GetInvoiceRequest{OrganizationId: 1, InvoiceId: 1}, codes.Unauthenticated},
+		"no organization": {withToken(token), &invoicepb.GetInvoiceRequest{InvoiceId: 1}, codes.InvalidArgument},
+		"not a member":    {withToken(token), &invoicepb.GetInvoiceRequest{OrganizationId: 2, InvoiceId: 1}, codes.PermissionDenied},
+		"missing invoice": {withToken(token), &invoicepb.GetInvoiceRequest{OrganizationId: 1, InvoiceId: 99}, codes.NotFound},
+	} {
+		if _, err := client.GetInvoice(tc.ctx, tc.req); status.Code(err) != tc.code {
+			t.Fatalf("%s: expected %s, got %v", name, tc.code, err)
+		}
+	}
+
+	// Requests are validated before reaching the use case
+	_, err := client.CreateInvoice(withToken(token), &invoicepb.CreateInvoiceRequest{OrganizationId: 1, ContactId: 10, Type: "receipt", Currency: "EUR", IssueDate: timestamppb.Now()})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("expected InvalidArgument for an unknown type, got %v", err)
+	}
+}
@@ -0,0 +1,10 @@
+package adaptergrpc
+
+import "go.uber.org/fx"
+
+// Module provides the gRPC server for Fx and serves it alongside the HTTP
+// server.
+var Module = fx.Options(
+	fx.Provide(NewServer),
+	fx.Invoke(registerHooks),
+)
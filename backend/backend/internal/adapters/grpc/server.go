@@ -0,0 +1,147 @@
+// @kthulu:module:grpc
+package adaptergrpc
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/pmaojo/kthulu-go/backend/core"
+	"github.com/pmaojo/kthulu-go/backend/internal/adapters/grpc/invoicepb"
+	"github.com/pmaojo/kthulu-go/backend/internal/domain/repository"
+	"github.com/pmaojo/kthulu-go/backend/internal/usecase"
+)
+
+type contextKey string
+
+// userIDKey is the context key for the authenticated user's ID
+const userIDKey contextKey = "userID"
+
+// ServerParams holds the dependencies of the gRPC server. Services whose use
+// cases aren't loaded are not registered.
+type ServerParams struct {
+	fx.In
+	InvoiceUC    *usecase.InvoiceUseCase `optional:"true"`
+	OrgUsers     repository.OrganizationUserRepository
+	TokenManager core.TokenManager
+	Denylist     repository.AccessTokenDenylist `optional:"true"`
+	Logger       *zap.Logger
+}
+
+// NewServer creates the gRPC server with the services of the loaded modules.
+// Every call must carry an access token in its "authorization" metadata.
+func NewServer(p ServerParams) *grpc.Server {
+	srv := grpc.NewServer(grpc.UnaryInterceptor(authInterceptor(p.TokenManager, p.Denylist, p.Logger)))
+	if p.InvoiceUC != nil {
+		invoicepb.RegisterInvoiceServiceServer(srv, NewInvoiceServer(p.InvoiceUC, p.OrgUsers, p.Logger))
+	}
+	return srv
+}
+
+// authInterceptor validates the bearer token of each call and adds the user
+// ID to the context, also exposing it as the audit actor
+func authInterceptor(tokenManager core.TokenManager, denylist repository.AccessTokenDenylist, logger *zap.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		values := md.Get("authorization")
+		if len(values) == 0 {
+			return nil, status.Error(codes.Unauthenticated, "missing access token")
+		}
+		tokenStr, ok := strings.CutPrefix(values[0], "Bearer ")
+		if !ok {
+			return nil, status.Error(codes.Unauthenticated, "invalid authorization metadata")
+		}
+
+		claims, err := tokenManager.ValidateAccessToken(tokenStr)
+		if err != nil {
+			logger.Warn("Invalid access token", zap.String("method", info.FullMethod), zap.Error(err))
+			return nil, status.Error(codes.Unauthenticated, "invalid access token")
+		}
+
+		// Reject tokens revoked before their expiry
+		revoked, err := repository.IsAccessTokenRevoked(ctx, denylist, claims)
+		if err != nil {
+			logger.Error("Failed to check access token revocation", zap.Error(err))
+			return nil, status.Error(codes.Unauthenticated, "invalid access token")
+		}
+		if revoked {
+			return nil, status.Error(codes.Unauthenticated, "invalid access token")
+		}
+
+		sub, ok := claims["sub"].(float64)
+		if !ok {
+			return nil, status.Error(codes.Unauthenticated, "invalid access token")
+		}
+		userID := uint(sub)
+		ctx = context.WithValue(ctx, userIDKey, userID)
+		ctx = repository.ContextWithActor(ctx, userID)
+		return handler(ctx, req)
+	}
+}
+
+// userID returns the ID of the user authenticated by authInterceptor
+func userID(ctx context.Context) uint {
+	id, _ := ctx.Value(userIDKey).(uint)
+	return id
+}
+
+// authorize checks that the caller is a member of the organization a request
+// targets
+func authorize(ctx context.Context, orgUsers repository.OrganizationUserRepository, organizationID uint64, logger *zap.Logger) error {
+	if organizationID == 0 {
+		return status.Error(codes.InvalidArgument, "organization_id is required")
+	}
+	member, err := orgUsers.IsUserInOrganization(ctx, uint(organizationID), userID(ctx))
+	if err != nil {
+		logger.Error("Failed to check organization membership", zap.Error(err))
+		return status.Error(codes.Internal, "internal error")
+	}
+	if !member {
+		return status.Error(codes.PermissionDenied, "not a member of the organization")
+	}
+	return nil
+}
+
+// registerHooks serves gRPC on the configured address for the lifetime of the
+// application
+func registerHooks(lc fx.Lifecycle, srv *grpc.Server, cfg *core.Config, logger *zap.Logger) {
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			lis, err := net.Listen("tcp", cfg.GRPC.Addr)
+			if err != nil {
+				return err
+			}
+			logger.Info("Starting gRPC server", zap.String("addr", cfg.GRPC.Addr))
+			go func() {
+				if err := srv.Serve(lis); err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+					logger.Error("gRPC server failed", zap.Error(err))
+				}
+			}()
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			// Let in-flight calls finish, force closing if the stop deadline
+			// passes first
+			done := make(chan struct{})
+			go func() {
+				srv.GracefulStop()
+				close(done)
+			}()
+			select {
+			case <-done:
+			case <-ctx.Done():
+				srv.Stop()
+			}
+			logger.Info("gRPC server stopped")
+			return nil
+		},
+	})
+}
@@ -0,0 +1,93 @@
+// @kthulu:module:auth
+package adapterhttp
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+
+	"github.com/pmaojo/kthulu-go/backend/core"
+	"github.com/pmaojo/kthulu-go/backend/internal/adapters/http/middleware"
+	"github.com/pmaojo/kthulu-go/backend/internal/domain"
+	"github.com/pmaojo/kthulu-go/backend/internal/domain/repository"
+	"github.com/pmaojo/kthulu-go/backend/internal/usecase"
+)
+
+// AuthEventHandler exposes the authentication event log to administrators.
+type AuthEventHandler struct {
+	auth         *usecase.AuthUseCase
+	tokenManager core.TokenManager
+	denylist     repository.AccessTokenDenylist
+	roles        repository.RoleRepository
+	log          *zap.SugaredLogger
+}
+
+// NewAuthEventHandler constructs AuthEventHandler with required dependencies.
+func NewAuthEventHandler(auth *usecase.AuthUseCase, tokenManager core.TokenManager, denylist repository.AccessTokenDenylist, roles repository.RoleRepository, logger *zap.Logger) *AuthEventHandler {
+	return &AuthEventHandler{
+		auth:         auth,
+		tokenManager: tokenManager,
+		denylist:     denylist,
+		roles:        roles,
+		log:          logger.Sugar(),
+	}
+}
+
+// RegisterRoutes attaches auth event routes to the router.
+func (h *AuthEventHandler) RegisterRoutes(r chi.Router) {
+	r.Group(func(r chi.Router) {
+		r.Use(middleware.RequireAuth(h.tokenManager, h.denylist))
+		r.Use(middleware.RequireRole(h.roles, domain.RoleAdmin))
+		r.Get("/auth/events", instrumentHandler("auth.listEvents", h.listEvents))
+	})
+}
+
+// listEvents godoc
+// @Summary List authentication events
+// @Description Returns login, confirmation and token refresh attempts, newest first
+// @Tags Authentication
+// @Produce json
+// @Security BearerAuth
+// @Param userId query int false "Only events of this user"
+// @Param email query string false "Only events for this email"
+// @Param type query string false "Only events of this type" Enums(login_succeeded, login_failed, confirm_succeeded, confirm_failed, refresh_succeeded, refresh_failed)
+// @Param page query int false "Page number" default(1)
+// @Param pageSize query int false "Page size" default(20)
+// @Success 200 {object} usecase.AuthEventListResponse "Events retrieved successfully"
+// @Failure 401 {object} map[string]string "Unauthorized - invalid or missing token"
+// @Failure 403 {object} map[string]string "Administrator role required"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /auth/events [get]
+func (h *AuthEventHandler) listEvents(w http.ResponseWriter, r *http.Request) {
+	// Use context-aware logger
+	logger := middleware.GetSugaredLogger(r.Context())
+
+	// Parse filters
+	query := r.URL.Query()
+	filters := repository.AuthEventFilters{
+		Email: query.Get("email"),
+		Type:  domain.AuthEventType(query.Get("type")),
+	}
+	if userID, err := strconv.ParseUint(query.Get("userId"), 10, 32); err == nil {
+		filters.UserID = uint(userID)
+	}
+	filters.Page, filters.PageSize = parsePagination(r, repository.DefaultPageSize)
+
+	response, err := h.auth.ListAuthEvents(r.Context(), filters)
+	if err != nil {
+		logger.Errorw("Failed to list auth events", "error", err)
+		http.Error(w, "Failed to list auth events", http.StatusInternalServerError)
+		return
+	}
+
+	setPaginationHeaders(w, r, response.Page, response.PageSize, response.Total)
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		logger.Errorw("Failed to encode auth events response", "error", err)
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
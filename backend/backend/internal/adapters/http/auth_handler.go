@@ -3,13 +3,16 @@ package adapterhttp
 import (
 	"context"
 	"encoding/json"
+	"net"
 	"net/http"
+	"strings"
 
 	"github.com/go-chi/chi/v5"
 	"go.uber.org/zap"
 
 	"github.com/pmaojo/kthulu-go/backend/internal/adapters/http/middleware"
 	"github.com/pmaojo/kthulu-go/backend/internal/domain"
+	"github.com/pmaojo/kthulu-go/backend/internal/domain/repository"
 	"github.com/pmaojo/kthulu-go/backend/internal/usecase"
 )
 
@@ -106,11 +109,13 @@ func (h *AuthHandler) login(w http.ResponseWriter, r *http.Request) {
 		Password: req.Password,
 	}
 
-	response, err := h.auth.Login(r.Context(), loginReq)
+	response, err := h.auth.Login(withClientInfo(r), loginReq)
 	if err != nil {
 		logger.Errorw("Login failed", "email", req.Email, "error", err)
 		if err == domain.ErrUserNotFound || err == domain.ErrUserNotConfirmed {
 			w.WriteHeader(http.StatusUnauthorized)
+		} else if err == domain.ErrUserDisabled {
+			w.WriteHeader(http.StatusForbidden)
 		} else {
 			w.WriteHeader(http.StatusInternalServerError)
 		}
@@ -209,7 +214,7 @@ func (h *AuthHandler) refresh(w http.ResponseWriter, r *http.Request) {
 		RefreshToken: req.RefreshToken,
 	}
 
-	response, err := h.auth.Refresh(r.Context(), refreshReq)
+	response, err := h.auth.Refresh(withClientInfo(r), refreshReq)
 	if err != nil {
 		logger.Errorw("Token refresh failed", "error", err)
 		if err == domain.ErrTokenExpired || err == domain.ErrInvalidToken {
@@ -239,6 +244,7 @@ func (h *AuthHandler) refresh(w http.ResponseWriter, r *http.Request) {
 // @Param request body confirmRequest true "Email confirmation details"
 // @Success 200 {object} usecase.AuthResponse "Email confirmed successfully"
 // @Failure 400 {object} map[string]string "Invalid confirmation code"
+// @Failure 403 {object} map[string]string "User account is disabled"
 // @Failure 404 {object} map[string]string "User not found"
 // @Failure 500 {object} map[string]string "Internal server error"
 // @Router /auth/confirm [post]
@@ -261,11 +267,13 @@ func (h *AuthHandler) confirm(w http.ResponseWriter, r *http.Request) {
 		ConfirmationCode: req.ConfirmationCode,
 	}
 
-	response, err := h.auth.Confirm(r.Context(), confirmReq)
+	response, err := h.auth.Confirm(withClientInfo(r), confirmReq)
 	if err != nil {
 		logger.Errorw("Email confirmation failed", "email", req.Email, "error", err)
 		if err == domain.ErrUserNotFound {
 			w.WriteHeader(http.StatusNotFound)
+		} else if err == domain.ErrUserDisabled {
+			w.WriteHeader(http.StatusForbidden)
 		} else {
 			w.WriteHeader(http.StatusBadRequest)
 		}
@@ -284,7 +292,7 @@ func (h *AuthHandler) confirm(w http.ResponseWriter, r *http.Request) {
 
 // logout godoc
 // @Summary Logout user
-// @Description Invalidates the user's refresh token
+// @Description Invalidates the user's refresh token and revokes the bearer access token, if any
 // @Tags Authentication
 // @Accept json
 // @Param request body logoutRequest true "Refresh token to invalidate"
@@ -307,6 +315,7 @@ func (h *AuthHandler) logout(w http.ResponseWriter, r *http.Request) {
 	// Convert to use case request
 	logoutReq := usecase.LogoutRequest{
 		RefreshToken: req.RefreshToken,
+		AccessToken:  strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "),
 	}
 
 	err := h.auth.Logout(r.Context(), logoutReq)
@@ -359,3 +368,16 @@ func (h *AuthHandler) resendConfirmation(w http.ResponseWriter, r *http.Request)
 
 	w.WriteHeader(http.StatusNoContent)
 }
+
+// withClientInfo returns the request context carrying the client address and
+// user agent, so that authentication events can record them.
+func withClientInfo(r *http.Request) context.Context {
+	ip := r.RemoteAddr
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		ip = host
+	}
+	return repository.ContextWithClientInfo(r.Context(), repository.ClientInfo{
+		IPAddress: ip,
+		UserAgent: r.UserAgent(),
+	})
+}
@@ -0,0 +1,44 @@
+// @kthulu:module:org
+package adapterhttp
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/pmaojo/kthulu-go/backend/core"
+	"github.com/pmaojo/kthulu-go/backend/internal/domain/repository"
+)
+
+// BlobHandler serves the blobs of a store that serves them itself, like the
+// local one, at the path of the storage public URL, so the URLs the store
+// hands out resolve against this service
+type BlobHandler struct {
+	path  string
+	blobs http.Handler
+}
+
+// NewBlobHandler creates a blob handler. Nothing is served for stores that
+// aren't an http.Handler, such as S3, or when the public URL has no path.
+func NewBlobHandler(cfg *core.Config, blobs repository.BlobStore) (*BlobHandler, error) {
+	handler, ok := blobs.(http.Handler)
+	if !ok {
+		return &BlobHandler{}, nil
+	}
+	publicURL, err := url.Parse(cfg.Storage.PublicURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid storage public URL: %w", err)
+	}
+	return &BlobHandler{path: strings.TrimSuffix(publicURL.Path, "/"), blobs: handler}, nil
+}
+
+// RegisterRoutes mounts the blob store below the public URL path
+func (h *BlobHandler) RegisterRoutes(r chi.Router) {
+	if h.blobs == nil || h.path == "" {
+		return
+	}
+	r.Handle(h.path+"/*", http.StripPrefix(h.path, h.blobs))
+}
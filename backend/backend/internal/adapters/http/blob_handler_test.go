@@ -0,0 +1,36 @@
+package adapterhttp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/pmaojo/kthulu-go/backend/core"
+	"github.com/pmaojo/kthulu-go/backend/internal/infrastructure/storage"
+)
+
+func TestBlobHandlerServesLocalBlobsAtThePublicURL(t *testing.T) {
+	cfg := &core.Config{Storage: core.StorageConfig{PublicURL: "http://localhost:8080/uploads"}}
+	store := storage.NewLocalBlobStore(t.TempDir(), cfg.Storage.PublicURL, "")
+	blobURL, err := store.Put(context.Background(), "public/organizations/7/logo.png", "image/png", []byte("png"))
+	if err != nil {
+		t.Fatalf("put: %v", err)
+	}
+
+	handler, err := NewBlobHandler(cfg, store)
+	if err != nil {
+		t.Fatalf("new blob handler: %v", err)
+	}
+	router := chi.NewRouter()
+	handler.RegisterRoutes(router)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, strings.TrimPrefix(blobURL, "http://localhost:8080"), nil))
+	if w.Code != http.StatusOK || w.Body.String() != "png" {
+		t.Fatalf("expected the blob at %s, got %d %q", blobURL, w.Code, w.Body.String())
+	}
+}
@@ -3,14 +3,19 @@ package adapterhttp
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
 	"time"
 
 	"github.com/go-chi/chi/v5"
+	"go.uber.org/fx"
 	"go.uber.org/zap"
 
+	"github.com/pmaojo/kthulu-go/backend/core"
+	"github.com/pmaojo/kthulu-go/backend/internal/adapters/http/middleware"
 	"github.com/pmaojo/kthulu-go/backend/internal/domain"
+	"github.com/pmaojo/kthulu-go/backend/internal/domain/repository"
 	"github.com/pmaojo/kthulu-go/backend/internal/usecase"
 )
 
@@ -19,15 +24,29 @@ var _ = domain.Calendar{}
 
 // CalendarHandler handles calendar-related HTTP requests
 type CalendarHandler struct {
-	calendarUC *usecase.CalendarUseCase
-	logger     *zap.Logger
+	calendarUC   *usecase.CalendarUseCase
+	orgUsers     repository.OrganizationUserRepository
+	tokenManager core.TokenManager
+	denylist     repository.AccessTokenDenylist
+	logger       *zap.Logger
 }
 
-// NewCalendarHandler creates a new calendar handler
-func NewCalendarHandler(calendarUC *usecase.CalendarUseCase, logger *zap.Logger) *CalendarHandler {
+// NewCalendarHandler creates a new calendar handler. The denylist is optional
+// since the calendar module is loaded without the auth repositories.
+func NewCalendarHandler(p struct {
+	fx.In
+	CalendarUC   *usecase.CalendarUseCase
+	OrgUsers     repository.OrganizationUserRepository
+	TokenManager core.TokenManager
+	Denylist     repository.AccessTokenDenylist `optional:"true"`
+	Logger       *zap.Logger
+}) *CalendarHandler {
 	return &CalendarHandler{
-		calendarUC: calendarUC,
-		logger:     logger,
+		calendarUC:   p.CalendarUC,
+		orgUsers:     p.OrgUsers,
+		tokenManager: p.TokenManager,
+		denylist:     p.Denylist,
+		logger:       p.Logger,
 	}
 }
 
@@ -43,8 +62,14 @@ func (h *CalendarHandler) RegisterRoutes(r chi.Router) {
 
 		// Event routes
 		r.Route("/events", func(r chi.Router) {
-			r.Post("/", h.CreateEvent)
+			// Events are created by a member of the organization owning the
+			// records they are linked to
+			r.With(
+				middleware.RequireAuth(h.tokenManager, h.denylist),
+				middleware.OrganizationMiddleware(h.orgUsers),
+			).Post("/", h.CreateEvent)
 			r.Get("/{id}", h.GetEvent)
+			r.Get("/{id}/occurrences", h.ExpandOccurrences)
 			r.Get("/", h.ListEvents)
 		})
 
@@ -56,6 +81,12 @@ func (h *CalendarHandler) RegisterRoutes(r chi.Router) {
 
 		// Utility routes
 		r.Get("/business-day/{date}", h.IsBusinessDay)
+
+		// The export is scoped to the caller's calendars
+		r.Group(func(r chi.Router) {
+			r.Use(middleware.RequireAuth(h.tokenManager, h.denylist))
+			r.Get("/export.ics", h.ExportICalendar)
+		})
 	})
 
 	h.logger.Info("Calendar routes registered")
@@ -145,15 +176,7 @@ func (h *CalendarHandler) ListCalendars(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
-	if page < 1 {
-		page = 1
-	}
-
-	pageSize, _ := strconv.Atoi(r.URL.Query().Get("pageSize"))
-	if pageSize < 1 || pageSize > 100 {
-		pageSize = 10
-	}
+	page, pageSize := parsePagination(r, 10)
 
 	req := usecase.ListCalendarsRequest{
 		OwnerID:  uint(ownerID),
@@ -168,19 +191,24 @@ func (h *CalendarHandler) ListCalendars(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	setPaginationHeaders(w, r, response.Page, response.PageSize, int64(response.Total))
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(response)
 }
 
 // CreateEvent godoc
 // @Summary Create a new event
-// @Description Creates a new event in a calendar
+// @Description Creates a new event in a calendar, optionally linked to a contact or invoice of the organization
 // @Tags Calendar
 // @Accept json
 // @Produce json
+// @Security BearerAuth
+// @Param X-Organization-ID header int true "Organization ID"
 // @Param request body usecase.CreateEventRequest true "Event details"
 // @Success 201 {object} domain.Event "Event created successfully"
 // @Failure 400 {object} map[string]string "Invalid request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Not a member of the organization"
 // @Failure 500 {object} map[string]string "Internal server error"
 // @Router /api/calendar/events [post]
 func (h *CalendarHandler) CreateEvent(w http.ResponseWriter, r *http.Request) {
@@ -190,8 +218,18 @@ func (h *CalendarHandler) CreateEvent(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Invalid request body", http.StatusBadRequest)
 		return
 	}
+	organizationID, err := getOrganizationIDFromContext(r.Context())
+	if err != nil {
+		http.Error(w, "Organization context required", http.StatusBadRequest)
+		return
+	}
+	req.OrganizationID = organizationID
 
 	event, err := h.calendarUC.CreateEvent(r.Context(), req)
+	if errors.Is(err, domain.ErrInvalidRecurrenceRule) || errors.Is(err, domain.ErrInvalidEventLink) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 	if err != nil {
 		h.logger.Error("Failed to create event", zap.Error(err))
 		http.Error(w, "Failed to create event", http.StatusInternalServerError)
@@ -232,6 +270,50 @@ func (h *CalendarHandler) GetEvent(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(event)
 }
 
+// ExpandOccurrences godoc
+// @Summary List event occurrences
+// @Description Expands a recurring event into the occurrences overlapping a time window of at most a year
+// @Tags Calendar
+// @Produce json
+// @Param id path int true "Event ID"
+// @Param from query string true "Window start (RFC3339 format)"
+// @Param to query string true "Window end (RFC3339 format)"
+// @Success 200 {array} domain.EventOccurrence "Occurrences"
+// @Failure 400 {object} map[string]string "Invalid request"
+// @Failure 404 {object} map[string]string "Event not found"
+// @Router /api/calendar/events/{id}/occurrences [get]
+func (h *CalendarHandler) ExpandOccurrences(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
+	if err != nil {
+		http.Error(w, "Invalid event ID", http.StatusBadRequest)
+		return
+	}
+
+	from, err := time.Parse(time.RFC3339, r.URL.Query().Get("from"))
+	if err != nil {
+		http.Error(w, "from must be an RFC3339 time", http.StatusBadRequest)
+		return
+	}
+	to, err := time.Parse(time.RFC3339, r.URL.Query().Get("to"))
+	if err != nil {
+		http.Error(w, "to must be an RFC3339 time", http.StatusBadRequest)
+		return
+	}
+
+	occurrences, err := h.calendarUC.ExpandOccurrences(r.Context(), uint(id), from, to)
+	switch {
+	case errors.Is(err, domain.ErrInvalidOccurrenceWindow), errors.Is(err, domain.ErrInvalidRecurrenceRule):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case err != nil:
+		http.Error(w, "Event not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(occurrences)
+}
+
 // ListEvents godoc
 // @Summary List events
 // @Description Retrieves a paginated list of events for a calendar
@@ -258,15 +340,7 @@ func (h *CalendarHandler) ListEvents(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
-	if page < 1 {
-		page = 1
-	}
-
-	pageSize, _ := strconv.Atoi(r.URL.Query().Get("pageSize"))
-	if pageSize < 1 || pageSize > 100 {
-		pageSize = 10
-	}
+	page, pageSize := parsePagination(r, 10)
 
 	req := usecase.ListEventsRequest{
 		CalendarID: uint(calendarID),
@@ -294,10 +368,55 @@ func (h *CalendarHandler) ListEvents(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	setPaginationHeaders(w, r, response.Page, response.PageSize, int64(response.Total))
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(response)
 }
 
+// ExportICalendar godoc
+// @Summary Export calendar as iCalendar
+// @Description Exports the events of the caller's calendars, or of one of them, as an RFC 5545 .ics file for external calendar apps
+// @Tags Calendar
+// @Produce text/calendar
+// @Security BearerAuth
+// @Param calendarId query int false "Only export this calendar"
+// @Success 200 {string} string "iCalendar file"
+// @Failure 400 {object} map[string]string "Invalid request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 404 {object} map[string]string "Calendar not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/calendar/export.ics [get]
+func (h *CalendarHandler) ExportICalendar(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var calendarID uint64
+	if calendarIDStr := r.URL.Query().Get("calendarId"); calendarIDStr != "" {
+		if calendarID, err = strconv.ParseUint(calendarIDStr, 10, 32); err != nil || calendarID == 0 {
+			http.Error(w, "Invalid calendarId", http.StatusBadRequest)
+			return
+		}
+	}
+
+	ics, err := h.calendarUC.ExportICalendar(r.Context(), userID, uint(calendarID))
+	if errors.Is(err, domain.ErrCalendarNotFound) {
+		http.Error(w, "Calendar not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		h.logger.Error("Failed to export calendar", zap.Uint("userId", userID), zap.Error(err))
+		http.Error(w, "Failed to export calendar", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="calendar.ics"`)
+	w.Write(ics)
+}
+
 // GetAvailableSlots godoc
 // @Summary Get available appointment slots
 // @Description Retrieves available appointment slots for a calendar on a specific date
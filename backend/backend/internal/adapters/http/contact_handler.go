@@ -4,11 +4,15 @@ package adapterhttp
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-playground/validator/v10"
+	"go.uber.org/fx"
 
 	"github.com/pmaojo/kthulu-go/backend/core"
 	"github.com/pmaojo/kthulu-go/backend/internal/adapters/http/middleware"
@@ -19,29 +23,46 @@ import (
 
 // ContactHandler handles HTTP requests for contact management
 type ContactHandler struct {
-	contactUC *usecase.ContactUseCase
-	validator *validator.Validate
-	logger    core.Logger
+	contactUC    *usecase.ContactUseCase
+	orgUsers     repository.OrganizationUserRepository
+	tokenManager core.TokenManager
+	denylist     repository.AccessTokenDenylist
+	validator    *validator.Validate
+	logger       core.Logger
 }
 
 // NewContactHandler creates a new ContactHandler
-func NewContactHandler(
-	contactUC *usecase.ContactUseCase,
-	logger core.Logger,
-) *ContactHandler {
+func NewContactHandler(p struct {
+	fx.In
+	ContactUC    *usecase.ContactUseCase
+	OrgUsers     repository.OrganizationUserRepository
+	TokenManager core.TokenManager
+	Denylist     repository.AccessTokenDenylist `optional:"true"`
+	Logger       core.Logger
+}) *ContactHandler {
 	return &ContactHandler{
-		contactUC: contactUC,
-		validator: validator.New(),
-		logger:    logger,
+		contactUC:    p.ContactUC,
+		orgUsers:     p.OrgUsers,
+		tokenManager: p.TokenManager,
+		denylist:     p.Denylist,
+		validator:    validator.New(),
+		logger:       p.Logger,
 	}
 }
 
-// RegisterRoutes registers contact routes
+// RegisterRoutes registers contact routes. They are served to members of the
+// organization named by the X-Organization-ID header.
 func (h *ContactHandler) RegisterRoutes(r chi.Router) {
 	r.Route("/contacts", func(r chi.Router) {
+		r.Use(middleware.RequireAuth(h.tokenManager, h.denylist))
+		r.Use(middleware.OrganizationMiddleware(h.orgUsers))
+
 		r.Post("/", h.CreateContact)
 		r.Get("/", h.ListContacts)
 		r.Get("/stats", h.GetContactStats)
+		r.Get("/stats/trend", h.GetContactStatsTrend)
+		r.Get("/duplicates", h.FindPotentialDuplicates)
+		r.Get("/export", h.ExportContacts)
 
 		r.Route("/{contactId}", func(r chi.Router) {
 			r.Get("/", h.GetContact)
@@ -49,6 +70,7 @@ func (h *ContactHandler) RegisterRoutes(r chi.Router) {
 			r.Delete("/", h.DeleteContact)
 			r.Patch("/status", h.SetContactStatus)
 			r.Post("/convert-to-customer", h.ConvertLeadToCustomer)
+			r.Get("/events", h.ListContactEvents)
 
 			// Address management
 			r.Post("/addresses", h.AddContactAddress)
@@ -90,28 +112,28 @@ func (h *ContactHandler) CreateContact(w http.ResponseWriter, r *http.Request) {
 	// Get organization ID from context (set by middleware)
 	organizationID, err := getOrganizationIDFromContext(ctx)
 	if err != nil {
-		h.writeErrorResponse(w, http.StatusBadRequest, "Invalid organization ID", err)
+		h.writeErrorResponse(w, r, http.StatusBadRequest, "Invalid organization ID", err)
 		return
 	}
 
 	var req usecase.CreateContactRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		h.writeErrorResponse(w, http.StatusBadRequest, "Invalid request body", err)
+		h.writeErrorResponse(w, r, http.StatusBadRequest, "Invalid request body", err)
 		return
 	}
 
 	if err := h.validator.Struct(&req); err != nil {
-		h.writeErrorResponse(w, http.StatusBadRequest, "Validation failed", err)
+		h.writeErrorResponse(w, r, http.StatusBadRequest, "Validation failed", err)
 		return
 	}
 
 	contact, err := h.contactUC.CreateContact(ctx, organizationID, req)
 	if err != nil {
 		if err == domain.ErrContactAlreadyExists {
-			h.writeErrorResponse(w, http.StatusConflict, "Contact already exists", err)
+			h.writeErrorResponse(w, r, http.StatusConflict, "Contact already exists", err)
 			return
 		}
-		h.writeErrorResponse(w, http.StatusInternalServerError, "Failed to create contact", err)
+		h.writeErrorResponse(w, r, http.StatusInternalServerError, "Failed to create contact", err)
 		return
 	}
 
@@ -125,7 +147,9 @@ func (h *ContactHandler) CreateContact(w http.ResponseWriter, r *http.Request) {
 // @Produce json
 // @Param X-Organization-ID header string true "Organization ID"
 // @Param contactId path int true "Contact ID"
+// @Param If-None-Match header string false "ETag from a previous response"
 // @Success 200 {object} domain.Contact
+// @Success 304 "Not modified"
 // @Failure 400 {object} ErrorResponse
 // @Failure 401 {object} ErrorResponse
 // @Failure 404 {object} ErrorResponse
@@ -137,26 +161,29 @@ func (h *ContactHandler) GetContact(w http.ResponseWriter, r *http.Request) {
 
 	organizationID, err := getOrganizationIDFromContext(ctx)
 	if err != nil {
-		h.writeErrorResponse(w, http.StatusBadRequest, "Invalid organization ID", err)
+		h.writeErrorResponse(w, r, http.StatusBadRequest, "Invalid organization ID", err)
 		return
 	}
 
 	contactID, err := strconv.ParseUint(chi.URLParam(r, "contactId"), 10, 32)
 	if err != nil {
-		h.writeErrorResponse(w, http.StatusBadRequest, "Invalid contact ID", err)
+		h.writeErrorResponse(w, r, http.StatusBadRequest, "Invalid contact ID", err)
 		return
 	}
 
 	contact, err := h.contactUC.GetContact(ctx, organizationID, uint(contactID))
 	if err != nil {
 		if err == domain.ErrContactNotFound {
-			h.writeErrorResponse(w, http.StatusNotFound, "Contact not found", err)
+			h.writeErrorResponse(w, r, http.StatusNotFound, "Contact not found", err)
 			return
 		}
-		h.writeErrorResponse(w, http.StatusInternalServerError, "Failed to get contact", err)
+		h.writeErrorResponse(w, r, http.StatusInternalServerError, "Failed to get contact", err)
 		return
 	}
 
+	if checkNotModified(w, r, resourceETag(contact.ID, contact.UpdatedAt)) {
+		return
+	}
 	h.writeJSONResponse(w, http.StatusOK, contact)
 }
 
@@ -182,38 +209,38 @@ func (h *ContactHandler) UpdateContact(w http.ResponseWriter, r *http.Request) {
 
 	organizationID, err := getOrganizationIDFromContext(ctx)
 	if err != nil {
-		h.writeErrorResponse(w, http.StatusBadRequest, "Invalid organization ID", err)
+		h.writeErrorResponse(w, r, http.StatusBadRequest, "Invalid organization ID", err)
 		return
 	}
 
 	contactID, err := strconv.ParseUint(chi.URLParam(r, "contactId"), 10, 32)
 	if err != nil {
-		h.writeErrorResponse(w, http.StatusBadRequest, "Invalid contact ID", err)
+		h.writeErrorResponse(w, r, http.StatusBadRequest, "Invalid contact ID", err)
 		return
 	}
 
 	var req usecase.UpdateContactRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		h.writeErrorResponse(w, http.StatusBadRequest, "Invalid request body", err)
+		h.writeErrorResponse(w, r, http.StatusBadRequest, "Invalid request body", err)
 		return
 	}
 
 	if err := h.validator.Struct(&req); err != nil {
-		h.writeErrorResponse(w, http.StatusBadRequest, "Validation failed", err)
+		h.writeErrorResponse(w, r, http.StatusBadRequest, "Validation failed", err)
 		return
 	}
 
 	contact, err := h.contactUC.UpdateContact(ctx, organizationID, uint(contactID), req)
 	if err != nil {
 		if err == domain.ErrContactNotFound {
-			h.writeErrorResponse(w, http.StatusNotFound, "Contact not found", err)
+			h.writeErrorResponse(w, r, http.StatusNotFound, "Contact not found", err)
 			return
 		}
 		if err == domain.ErrContactAlreadyExists {
-			h.writeErrorResponse(w, http.StatusConflict, "Contact with email already exists", err)
+			h.writeErrorResponse(w, r, http.StatusConflict, "Contact with email already exists", err)
 			return
 		}
-		h.writeErrorResponse(w, http.StatusInternalServerError, "Failed to update contact", err)
+		h.writeErrorResponse(w, r, http.StatusInternalServerError, "Failed to update contact", err)
 		return
 	}
 
@@ -238,22 +265,22 @@ func (h *ContactHandler) DeleteContact(w http.ResponseWriter, r *http.Request) {
 
 	organizationID, err := getOrganizationIDFromContext(ctx)
 	if err != nil {
-		h.writeErrorResponse(w, http.StatusBadRequest, "Invalid organization ID", err)
+		h.writeErrorResponse(w, r, http.StatusBadRequest, "Invalid organization ID", err)
 		return
 	}
 
 	contactID, err := strconv.ParseUint(chi.URLParam(r, "contactId"), 10, 32)
 	if err != nil {
-		h.writeErrorResponse(w, http.StatusBadRequest, "Invalid contact ID", err)
+		h.writeErrorResponse(w, r, http.StatusBadRequest, "Invalid contact ID", err)
 		return
 	}
 
 	if err := h.contactUC.DeleteContact(ctx, organizationID, uint(contactID)); err != nil {
 		if err == domain.ErrContactNotFound {
-			h.writeErrorResponse(w, http.StatusNotFound, "Contact not found", err)
+			h.writeErrorResponse(w, r, http.StatusNotFound, "Contact not found", err)
 			return
 		}
-		h.writeErrorResponse(w, http.StatusInternalServerError, "Failed to delete contact", err)
+		h.writeErrorResponse(w, r, http.StatusInternalServerError, "Failed to delete contact", err)
 		return
 	}
 
@@ -284,7 +311,7 @@ func (h *ContactHandler) ListContacts(w http.ResponseWriter, r *http.Request) {
 
 	organizationID, err := getOrganizationIDFromContext(ctx)
 	if err != nil {
-		h.writeErrorResponse(w, http.StatusBadRequest, "Invalid organization ID", err)
+		h.writeErrorResponse(w, r, http.StatusBadRequest, "Invalid organization ID", err)
 		return
 	}
 
@@ -292,10 +319,11 @@ func (h *ContactHandler) ListContacts(w http.ResponseWriter, r *http.Request) {
 
 	response, err := h.contactUC.ListContacts(ctx, organizationID, filters)
 	if err != nil {
-		h.writeErrorResponse(w, http.StatusInternalServerError, "Failed to list contacts", err)
+		h.writeErrorResponse(w, r, http.StatusInternalServerError, "Failed to list contacts", err)
 		return
 	}
 
+	setPaginationHeaders(w, r, response.Page, response.PageSize, response.Total)
 	h.writeJSONResponse(w, http.StatusOK, response)
 }
 
@@ -319,13 +347,13 @@ func (h *ContactHandler) SetContactStatus(w http.ResponseWriter, r *http.Request
 
 	organizationID, err := getOrganizationIDFromContext(ctx)
 	if err != nil {
-		h.writeErrorResponse(w, http.StatusBadRequest, "Invalid organization ID", err)
+		h.writeErrorResponse(w, r, http.StatusBadRequest, "Invalid organization ID", err)
 		return
 	}
 
 	contactID, err := strconv.ParseUint(chi.URLParam(r, "contactId"), 10, 32)
 	if err != nil {
-		h.writeErrorResponse(w, http.StatusBadRequest, "Invalid contact ID", err)
+		h.writeErrorResponse(w, r, http.StatusBadRequest, "Invalid contact ID", err)
 		return
 	}
 
@@ -333,16 +361,16 @@ func (h *ContactHandler) SetContactStatus(w http.ResponseWriter, r *http.Request
 		Active bool `json:"active"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		h.writeErrorResponse(w, http.StatusBadRequest, "Invalid request body", err)
+		h.writeErrorResponse(w, r, http.StatusBadRequest, "Invalid request body", err)
 		return
 	}
 
 	if err := h.contactUC.SetContactActive(ctx, organizationID, uint(contactID), req.Active); err != nil {
 		if err == domain.ErrContactNotFound {
-			h.writeErrorResponse(w, http.StatusNotFound, "Contact not found", err)
+			h.writeErrorResponse(w, r, http.StatusNotFound, "Contact not found", err)
 			return
 		}
-		h.writeErrorResponse(w, http.StatusInternalServerError, "Failed to update contact status", err)
+		h.writeErrorResponse(w, r, http.StatusInternalServerError, "Failed to update contact status", err)
 		return
 	}
 
@@ -368,23 +396,23 @@ func (h *ContactHandler) ConvertLeadToCustomer(w http.ResponseWriter, r *http.Re
 
 	organizationID, err := getOrganizationIDFromContext(ctx)
 	if err != nil {
-		h.writeErrorResponse(w, http.StatusBadRequest, "Invalid organization ID", err)
+		h.writeErrorResponse(w, r, http.StatusBadRequest, "Invalid organization ID", err)
 		return
 	}
 
 	contactID, err := strconv.ParseUint(chi.URLParam(r, "contactId"), 10, 32)
 	if err != nil {
-		h.writeErrorResponse(w, http.StatusBadRequest, "Invalid contact ID", err)
+		h.writeErrorResponse(w, r, http.StatusBadRequest, "Invalid contact ID", err)
 		return
 	}
 
 	contact, err := h.contactUC.ConvertLeadToCustomer(ctx, organizationID, uint(contactID))
 	if err != nil {
 		if err == domain.ErrContactNotFound {
-			h.writeErrorResponse(w, http.StatusNotFound, "Contact not found", err)
+			h.writeErrorResponse(w, r, http.StatusNotFound, "Contact not found", err)
 			return
 		}
-		h.writeErrorResponse(w, http.StatusBadRequest, "Failed to convert lead", err)
+		h.writeErrorResponse(w, r, http.StatusBadRequest, "Failed to convert lead", err)
 		return
 	}
 
@@ -408,19 +436,182 @@ func (h *ContactHandler) GetContactStats(w http.ResponseWriter, r *http.Request)
 
 	organizationID, err := getOrganizationIDFromContext(ctx)
 	if err != nil {
-		h.writeErrorResponse(w, http.StatusBadRequest, "Invalid organization ID", err)
+		h.writeErrorResponse(w, r, http.StatusBadRequest, "Invalid organization ID", err)
 		return
 	}
 
 	stats, err := h.contactUC.GetContactStats(ctx, organizationID)
 	if err != nil {
-		h.writeErrorResponse(w, http.StatusInternalServerError, "Failed to get contact stats", err)
+		h.writeErrorResponse(w, r, http.StatusInternalServerError, "Failed to get contact stats", err)
 		return
 	}
 
 	h.writeJSONResponse(w, http.StatusOK, stats)
 }
 
+// FindPotentialDuplicates reports likely duplicate contacts
+// @Summary Find potential duplicate contacts
+// @Description Group contacts that share an email or phone number, or whose names nearly match. Only groups of two or more contacts are returned.
+// @Tags @kthulu:module:contacts
+// @Produce json
+// @Param X-Organization-ID header string true "Organization ID"
+// @Success 200 {array} domain.DuplicateGroup
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /contacts/duplicates [get]
+func (h *ContactHandler) FindPotentialDuplicates(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	organizationID, err := getOrganizationIDFromContext(ctx)
+	if err != nil {
+		h.writeErrorResponse(w, r, http.StatusBadRequest, "Invalid organization ID", err)
+		return
+	}
+
+	groups, err := h.contactUC.FindPotentialDuplicates(ctx, organizationID)
+	if err != nil {
+		h.writeErrorResponse(w, r, http.StatusInternalServerError, "Failed to find duplicate contacts", err)
+		return
+	}
+
+	h.writeJSONResponse(w, http.StatusOK, groups)
+}
+
+// ExportContacts exports the contacts matching the list filters
+// @Summary Export contacts
+// @Description Stream the contacts matching the list filters, with their addresses and phones, as concatenated vCard 3.0 entries
+// @Tags @kthulu:module:contacts
+// @Produce text/vcard
+// @Param X-Organization-ID header string true "Organization ID"
+// @Param format query string false "Export format (vcard)"
+// @Param type query string false "Filter by contact type"
+// @Param isActive query bool false "Filter by active status"
+// @Param search query string false "Search in name, email, company"
+// @Param sortBy query string false "Sort by field"
+// @Param sortOrder query string false "Sort order (asc, desc)"
+// @Success 200 {file} file
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /contacts/export [get]
+func (h *ContactHandler) ExportContacts(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	organizationID, err := getOrganizationIDFromContext(ctx)
+	if err != nil {
+		h.writeErrorResponse(w, r, http.StatusBadRequest, "Invalid organization ID", err)
+		return
+	}
+
+	if format := r.URL.Query().Get("format"); format != "" && format != "vcard" {
+		h.writeErrorResponse(w, r, http.StatusBadRequest, "Unsupported export format", fmt.Errorf("format %q is not supported", format))
+		return
+	}
+
+	filters := h.parseContactFilters(r)
+
+	w.Header().Set("Content-Type", "text/vcard; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="contacts.vcf"`)
+	w.WriteHeader(http.StatusOK)
+
+	// Entries are written as they are fetched; once the status line is out an
+	// error can only cut the export short.
+	vw := domain.NewVCardWriter(w)
+	err = h.contactUC.ExportContacts(ctx, organizationID, filters, vw.Write)
+	if flushErr := vw.Flush(); err == nil {
+		err = flushErr
+	}
+	if err != nil {
+		h.logger.Error("Failed to export contacts", map[string]interface{}{"error": err})
+	}
+}
+
+// ListContactEvents lists the calendar events linked to a contact
+// @Summary List contact events
+// @Description List the calendar events, such as meetings, linked to a contact
+// @Tags @kthulu:module:contacts
+// @Produce json
+// @Param X-Organization-ID header string true "Organization ID"
+// @Param contactId path int true "Contact ID"
+// @Success 200 {array} domain.Event
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /contacts/{contactId}/events [get]
+func (h *ContactHandler) ListContactEvents(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	organizationID, err := getOrganizationIDFromContext(ctx)
+	if err != nil {
+		h.writeErrorResponse(w, r, http.StatusBadRequest, "Invalid organization ID", err)
+		return
+	}
+
+	contactID, err := strconv.ParseUint(chi.URLParam(r, "contactId"), 10, 32)
+	if err != nil {
+		h.writeErrorResponse(w, r, http.StatusBadRequest, "Invalid contact ID", err)
+		return
+	}
+
+	events, err := h.contactUC.ListContactEvents(ctx, organizationID, uint(contactID))
+	if err != nil {
+		if err == domain.ErrContactNotFound {
+			h.writeErrorResponse(w, r, http.StatusNotFound, "Contact not found", err)
+			return
+		}
+		h.writeErrorResponse(w, r, http.StatusInternalServerError, "Failed to list contact events", err)
+		return
+	}
+
+	h.writeJSONResponse(w, http.StatusOK, events)
+}
+
+// GetContactStatsTrend retrieves the monthly contact trend
+// @Summary Get contact statistics trend
+// @Description Get the contacts created per month, by type, between two months (inclusive). Defaults to the last 12 months.
+// @Tags @kthulu:module:contacts
+// @Produce json
+// @Param X-Organization-ID header string true "Organization ID"
+// @Param from query string false "First month (YYYY-MM)"
+// @Param to query string false "Last month (YYYY-MM)"
+// @Success 200 {object} repository.ContactStatsTrend
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /contacts/stats/trend [get]
+func (h *ContactHandler) GetContactStatsTrend(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	organizationID, err := getOrganizationIDFromContext(ctx)
+	if err != nil {
+		h.writeErrorResponse(w, r, http.StatusBadRequest, "Invalid organization ID", err)
+		return
+	}
+
+	from, to, err := parseTrendPeriod(r, time.Now())
+	if err != nil {
+		h.writeErrorResponse(w, r, http.StatusBadRequest, "Invalid period", err)
+		return
+	}
+
+	trend, err := h.contactUC.GetContactStatsTrend(ctx, organizationID, from, to)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidStatsPeriod) {
+			h.writeErrorResponse(w, r, http.StatusBadRequest, "Invalid period", err)
+			return
+		}
+		h.writeErrorResponse(w, r, http.StatusInternalServerError, "Failed to get contact stats trend", err)
+		return
+	}
+
+	h.writeJSONResponse(w, http.StatusOK, trend)
+}
+
 // AddContactAddress adds an address to a contact
 // @Summary Add contact address
 // @Description Add an address to a contact
@@ -442,34 +633,34 @@ func (h *ContactHandler) AddContactAddress(w http.ResponseWriter, r *http.Reques
 
 	organizationID, err := getOrganizationIDFromContext(ctx)
 	if err != nil {
-		h.writeErrorResponse(w, http.StatusBadRequest, "Invalid organization ID", err)
+		h.writeErrorResponse(w, r, http.StatusBadRequest, "Invalid organization ID", err)
 		return
 	}
 
 	contactID, err := strconv.ParseUint(chi.URLParam(r, "contactId"), 10, 32)
 	if err != nil {
-		h.writeErrorResponse(w, http.StatusBadRequest, "Invalid contact ID", err)
+		h.writeErrorResponse(w, r, http.StatusBadRequest, "Invalid contact ID", err)
 		return
 	}
 
 	var req usecase.CreateAddressRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		h.writeErrorResponse(w, http.StatusBadRequest, "Invalid request body", err)
+		h.writeErrorResponse(w, r, http.StatusBadRequest, "Invalid request body", err)
 		return
 	}
 
 	if err := h.validator.Struct(&req); err != nil {
-		h.writeErrorResponse(w, http.StatusBadRequest, "Validation failed", err)
+		h.writeErrorResponse(w, r, http.StatusBadRequest, "Validation failed", err)
 		return
 	}
 
 	address, err := h.contactUC.AddContactAddress(ctx, organizationID, uint(contactID), req)
 	if err != nil {
 		if err == domain.ErrContactNotFound {
-			h.writeErrorResponse(w, http.StatusNotFound, "Contact not found", err)
+			h.writeErrorResponse(w, r, http.StatusNotFound, "Contact not found", err)
 			return
 		}
-		h.writeErrorResponse(w, http.StatusInternalServerError, "Failed to add address", err)
+		h.writeErrorResponse(w, r, http.StatusInternalServerError, "Failed to add address", err)
 		return
 	}
 
@@ -497,34 +688,34 @@ func (h *ContactHandler) AddContactPhone(w http.ResponseWriter, r *http.Request)
 
 	organizationID, err := getOrganizationIDFromContext(ctx)
 	if err != nil {
-		h.writeErrorResponse(w, http.StatusBadRequest, "Invalid organization ID", err)
+		h.writeErrorResponse(w, r, http.StatusBadRequest, "Invalid organization ID", err)
 		return
 	}
 
 	contactID, err := strconv.ParseUint(chi.URLParam(r, "contactId"), 10, 32)
 	if err != nil {
-		h.writeErrorResponse(w, http.StatusBadRequest, "Invalid contact ID", err)
+		h.writeErrorResponse(w, r, http.StatusBadRequest, "Invalid contact ID", err)
 		return
 	}
 
 	var req usecase.CreatePhoneRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		h.writeErrorResponse(w, http.StatusBadRequest, "Invalid request body", err)
+		h.writeErrorResponse(w, r, http.StatusBadRequest, "Invalid request body", err)
 		return
 	}
 
 	if err := h.validator.Struct(&req); err != nil {
-		h.writeErrorResponse(w, http.StatusBadRequest, "Validation failed", err)
+		h.writeErrorResponse(w, r, http.StatusBadRequest, "Validation failed", err)
 		return
 	}
 
 	phone, err := h.contactUC.AddContactPhone(ctx, organizationID, uint(contactID), req)
 	if err != nil {
 		if err == domain.ErrContactNotFound {
-			h.writeErrorResponse(w, http.StatusNotFound, "Contact not found", err)
+			h.writeErrorResponse(w, r, http.StatusNotFound, "Contact not found", err)
 			return
 		}
-		h.writeErrorResponse(w, http.StatusInternalServerError, "Failed to add phone", err)
+		h.writeErrorResponse(w, r, http.StatusInternalServerError, "Failed to add phone", err)
 		return
 	}
 
@@ -536,40 +727,40 @@ func (h *ContactHandler) UpdateContactAddress(w http.ResponseWriter, r *http.Req
 
 	organizationID, err := getOrganizationIDFromContext(ctx)
 	if err != nil {
-		h.writeErrorResponse(w, http.StatusBadRequest, "Invalid organization ID", err)
+		h.writeErrorResponse(w, r, http.StatusBadRequest, "Invalid organization ID", err)
 		return
 	}
 
 	contactID, err := strconv.ParseUint(chi.URLParam(r, "contactId"), 10, 32)
 	if err != nil {
-		h.writeErrorResponse(w, http.StatusBadRequest, "Invalid contact ID", err)
+		h.writeErrorResponse(w, r, http.StatusBadRequest, "Invalid contact ID", err)
 		return
 	}
 
 	addressID, err := strconv.ParseUint(chi.URLParam(r, "addressId"), 10, 32)
 	if err != nil {
-		h.writeErrorResponse(w, http.StatusBadRequest, "Invalid address ID", err)
+		h.writeErrorResponse(w, r, http.StatusBadRequest, "Invalid address ID", err)
 		return
 	}
 
 	var req usecase.UpdateAddressRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		h.writeErrorResponse(w, http.StatusBadRequest, "Invalid request body", err)
+		h.writeErrorResponse(w, r, http.StatusBadRequest, "Invalid request body", err)
 		return
 	}
 
 	if err := h.validator.Struct(&req); err != nil {
-		h.writeErrorResponse(w, http.StatusBadRequest, "Validation failed", err)
+		h.writeErrorResponse(w, r, http.StatusBadRequest, "Validation failed", err)
 		return
 	}
 
 	address, err := h.contactUC.UpdateContactAddress(ctx, organizationID, uint(contactID), uint(addressID), req)
 	if err != nil {
 		if err == domain.ErrContactNotFound || err == domain.ErrAddressNotFound {
-			h.writeErrorResponse(w, http.StatusNotFound, "Address not found", err)
+			h.writeErrorResponse(w, r, http.StatusNotFound, "Address not found", err)
 			return
 		}
-		h.writeErrorResponse(w, http.StatusInternalServerError, "Failed to update address", err)
+		h.writeErrorResponse(w, r, http.StatusInternalServerError, "Failed to update address", err)
 		return
 	}
 
@@ -581,28 +772,28 @@ func (h *ContactHandler) DeleteContactAddress(w http.ResponseWriter, r *http.Req
 
 	organizationID, err := getOrganizationIDFromContext(ctx)
 	if err != nil {
-		h.writeErrorResponse(w, http.StatusBadRequest, "Invalid organization ID", err)
+		h.writeErrorResponse(w, r, http.StatusBadRequest, "Invalid organization ID", err)
 		return
 	}
 
 	contactID, err := strconv.ParseUint(chi.URLParam(r, "contactId"), 10, 32)
 	if err != nil {
-		h.writeErrorResponse(w, http.StatusBadRequest, "Invalid contact ID", err)
+		h.writeErrorResponse(w, r, http.StatusBadRequest, "Invalid contact ID", err)
 		return
 	}
 
 	addressID, err := strconv.ParseUint(chi.URLParam(r, "addressId"), 10, 32)
 	if err != nil {
-		h.writeErrorResponse(w, http.StatusBadRequest, "Invalid address ID", err)
+		h.writeErrorResponse(w, r, http.StatusBadRequest, "Invalid address ID", err)
 		return
 	}
 
 	if err := h.contactUC.DeleteContactAddress(ctx, organizationID, uint(contactID), uint(addressID)); err != nil {
 		if err == domain.ErrContactNotFound || err == domain.ErrAddressNotFound {
-			h.writeErrorResponse(w, http.StatusNotFound, "Address not found", err)
+			h.writeErrorResponse(w, r, http.StatusNotFound, "Address not found", err)
 			return
 		}
-		h.writeErrorResponse(w, http.StatusInternalServerError, "Failed to delete address", err)
+		h.writeErrorResponse(w, r, http.StatusInternalServerError, "Failed to delete address", err)
 		return
 	}
 
@@ -614,28 +805,28 @@ func (h *ContactHandler) SetPrimaryAddress(w http.ResponseWriter, r *http.Reques
 
 	organizationID, err := getOrganizationIDFromContext(ctx)
 	if err != nil {
-		h.writeErrorResponse(w, http.StatusBadRequest, "Invalid organization ID", err)
+		h.writeErrorResponse(w, r, http.StatusBadRequest, "Invalid organization ID", err)
 		return
 	}
 
 	contactID, err := strconv.ParseUint(chi.URLParam(r, "contactId"), 10, 32)
 	if err != nil {
-		h.writeErrorResponse(w, http.StatusBadRequest, "Invalid contact ID", err)
+		h.writeErrorResponse(w, r, http.StatusBadRequest, "Invalid contact ID", err)
 		return
 	}
 
 	addressID, err := strconv.ParseUint(chi.URLParam(r, "addressId"), 10, 32)
 	if err != nil {
-		h.writeErrorResponse(w, http.StatusBadRequest, "Invalid address ID", err)
+		h.writeErrorResponse(w, r, http.StatusBadRequest, "Invalid address ID", err)
 		return
 	}
 
 	if err := h.contactUC.SetPrimaryAddress(ctx, organizationID, uint(contactID), uint(addressID)); err != nil {
 		if err == domain.ErrContactNotFound || err == domain.ErrAddressNotFound {
-			h.writeErrorResponse(w, http.StatusNotFound, "Address not found", err)
+			h.writeErrorResponse(w, r, http.StatusNotFound, "Address not found", err)
 			return
 		}
-		h.writeErrorResponse(w, http.StatusInternalServerError, "Failed to set primary address", err)
+		h.writeErrorResponse(w, r, http.StatusInternalServerError, "Failed to set primary address", err)
 		return
 	}
 
@@ -647,40 +838,40 @@ func (h *ContactHandler) UpdateContactPhone(w http.ResponseWriter, r *http.Reque
 
 	organizationID, err := getOrganizationIDFromContext(ctx)
 	if err != nil {
-		h.writeErrorResponse(w, http.StatusBadRequest, "Invalid organization ID", err)
+		h.writeErrorResponse(w, r, http.StatusBadRequest, "Invalid organization ID", err)
 		return
 	}
 
 	contactID, err := strconv.ParseUint(chi.URLParam(r, "contactId"), 10, 32)
 	if err != nil {
-		h.writeErrorResponse(w, http.StatusBadRequest, "Invalid contact ID", err)
+		h.writeErrorResponse(w, r, http.StatusBadRequest, "Invalid contact ID", err)
 		return
 	}
 
 	phoneID, err := strconv.ParseUint(chi.URLParam(r, "phoneId"), 10, 32)
 	if err != nil {
-		h.writeErrorResponse(w, http.StatusBadRequest, "Invalid phone ID", err)
+		h.writeErrorResponse(w, r, http.StatusBadRequest, "Invalid phone ID", err)
 		return
 	}
 
 	var req usecase.UpdatePhoneRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		h.writeErrorResponse(w, http.StatusBadRequest, "Invalid request body", err)
+		h.writeErrorResponse(w, r, http.StatusBadRequest, "Invalid request body", err)
 		return
 	}
 
 	if err := h.validator.Struct(&req); err != nil {
-		h.writeErrorResponse(w, http.StatusBadRequest, "Validation failed", err)
+		h.writeErrorResponse(w, r, http.StatusBadRequest, "Validation failed", err)
 		return
 	}
 
 	phone, err := h.contactUC.UpdateContactPhone(ctx, organizationID, uint(contactID), uint(phoneID), req)
 	if err != nil {
 		if err == domain.ErrContactNotFound || err == domain.ErrPhoneNotFound {
-			h.writeErrorResponse(w, http.StatusNotFound, "Phone not found", err)
+			h.writeErrorResponse(w, r, http.StatusNotFound, "Phone not found", err)
 			return
 		}
-		h.writeErrorResponse(w, http.StatusInternalServerError, "Failed to update phone", err)
+		h.writeErrorResponse(w, r, http.StatusInternalServerError, "Failed to update phone", err)
 		return
 	}
 
@@ -692,28 +883,28 @@ func (h *ContactHandler) DeleteContactPhone(w http.ResponseWriter, r *http.Reque
 
 	organizationID, err := getOrganizationIDFromContext(ctx)
 	if err != nil {
-		h.writeErrorResponse(w, http.StatusBadRequest, "Invalid organization ID", err)
+		h.writeErrorResponse(w, r, http.StatusBadRequest, "Invalid organization ID", err)
 		return
 	}
 
 	contactID, err := strconv.ParseUint(chi.URLParam(r, "contactId"), 10, 32)
 	if err != nil {
-		h.writeErrorResponse(w, http.StatusBadRequest, "Invalid contact ID", err)
+		h.writeErrorResponse(w, r, http.StatusBadRequest, "Invalid contact ID", err)
 		return
 	}
 
 	phoneID, err := strconv.ParseUint(chi.URLParam(r, "phoneId"), 10, 32)
 	if err != nil {
-		h.writeErrorResponse(w, http.StatusBadRequest, "Invalid phone ID", err)
+		h.writeErrorResponse(w, r, http.StatusBadRequest, "Invalid phone ID", err)
 		return
 	}
 
 	if err := h.contactUC.DeleteContactPhone(ctx, organizationID, uint(contactID), uint(phoneID)); err != nil {
 		if err == domain.ErrContactNotFound || err == domain.ErrPhoneNotFound {
-			h.writeErrorResponse(w, http.StatusNotFound, "Phone not found", err)
+			h.writeErrorResponse(w, r, http.StatusNotFound, "Phone not found", err)
 			return
 		}
-		h.writeErrorResponse(w, http.StatusInternalServerError, "Failed to delete phone", err)
+		h.writeErrorResponse(w, r, http.StatusInternalServerError, "Failed to delete phone", err)
 		return
 	}
 
@@ -725,28 +916,28 @@ func (h *ContactHandler) SetPrimaryPhone(w http.ResponseWriter, r *http.Request)
 
 	organizationID, err := getOrganizationIDFromContext(ctx)
 	if err != nil {
-		h.writeErrorResponse(w, http.StatusBadRequest, "Invalid organization ID", err)
+		h.writeErrorResponse(w, r, http.StatusBadRequest, "Invalid organization ID", err)
 		return
 	}
 
 	contactID, err := strconv.ParseUint(chi.URLParam(r, "contactId"), 10, 32)
 	if err != nil {
-		h.writeErrorResponse(w, http.StatusBadRequest, "Invalid contact ID", err)
+		h.writeErrorResponse(w, r, http.StatusBadRequest, "Invalid contact ID", err)
 		return
 	}
 
 	phoneID, err := strconv.ParseUint(chi.URLParam(r, "phoneId"), 10, 32)
 	if err != nil {
-		h.writeErrorResponse(w, http.StatusBadRequest, "Invalid phone ID", err)
+		h.writeErrorResponse(w, r, http.StatusBadRequest, "Invalid phone ID", err)
 		return
 	}
 
 	if err := h.contactUC.SetPrimaryPhone(ctx, organizationID, uint(contactID), uint(phoneID)); err != nil {
 		if err == domain.ErrContactNotFound || err == domain.ErrPhoneNotFound {
-			h.writeErrorResponse(w, http.StatusNotFound, "Phone not found", err)
+			h.writeErrorResponse(w, r, http.StatusNotFound, "Phone not found", err)
 			return
 		}
-		h.writeErrorResponse(w, http.StatusInternalServerError, "Failed to set primary phone", err)
+		h.writeErrorResponse(w, r, http.StatusInternalServerError, "Failed to set primary phone", err)
 		return
 	}
 
@@ -755,6 +946,32 @@ func (h *ContactHandler) SetPrimaryPhone(w http.ResponseWriter, r *http.Request)
 
 // Helper methods
 
+// parseTrendPeriod reads the inclusive from/to months of a trend request
+// and returns them as the half-open range [from, to). Missing bounds default
+// to the twelve months ending with the current one.
+func parseTrendPeriod(r *http.Request, now time.Time) (time.Time, time.Time, error) {
+	currentMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	to := currentMonth.AddDate(0, 1, 0)
+	if toStr := r.URL.Query().Get("to"); toStr != "" {
+		month, err := time.Parse("2006-01", toStr)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("to must be YYYY-MM: %w", err)
+		}
+		to = month.AddDate(0, 1, 0)
+	}
+
+	from := to.AddDate(0, -12, 0)
+	if fromStr := r.URL.Query().Get("from"); fromStr != "" {
+		month, err := time.Parse("2006-01", fromStr)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("from must be YYYY-MM: %w", err)
+		}
+		from = month
+	}
+
+	return from, to, nil
+}
+
 func (h *ContactHandler) parseContactFilters(r *http.Request) repository.ContactFilters {
 	filters := repository.DefaultContactFilters()
 
@@ -772,17 +989,7 @@ func (h *ContactHandler) parseContactFilters(r *http.Request) repository.Contact
 		filters.Search = search
 	}
 
-	if pageStr := r.URL.Query().Get("page"); pageStr != "" {
-		if page, err := strconv.Atoi(pageStr); err == nil && page > 0 {
-			filters.Page = page
-		}
-	}
-
-	if pageSizeStr := r.URL.Query().Get("pageSize"); pageSizeStr != "" {
-		if pageSize, err := strconv.Atoi(pageSizeStr); err == nil && pageSize > 0 && pageSize <= 100 {
-			filters.PageSize = pageSize
-		}
-	}
+	filters.Page, filters.PageSize = parsePagination(r, filters.PageSize)
 
 	if sortBy := r.URL.Query().Get("sortBy"); sortBy != "" {
 		filters.SortBy = sortBy
@@ -802,17 +1009,20 @@ func (h *ContactHandler) writeJSONResponse(w http.ResponseWriter, statusCode int
 	json.NewEncoder(w).Encode(data)
 }
 
-func (h *ContactHandler) writeErrorResponse(w http.ResponseWriter, statusCode int, message string, err error) {
+func (h *ContactHandler) writeErrorResponse(w http.ResponseWriter, r *http.Request, statusCode int, message string, err error) {
+	traceID := middleware.GetTraceID(r.Context())
 	h.logger.Error("HTTP error", map[string]interface{}{
 		"status_code": statusCode,
 		"message":     message,
 		"error":       err,
+		"trace_id":    traceID,
 	})
 
 	response := ErrorResponse{
 		Error:   message,
 		Code:    statusCode,
 		Details: nil,
+		TraceID: traceID,
 	}
 
 	if err != nil {
@@ -827,6 +1037,7 @@ type ErrorResponse struct {
 	Error   string      `json:"error"`
 	Code    int         `json:"code"`
 	Details interface{} `json:"details,omitempty"`
+	TraceID string      `json:"traceId,omitempty"`
 }
 
 // Helper function to get organization ID from context
@@ -3,13 +3,19 @@ package adapterhttp
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/base64"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/go-chi/chi/v5"
-
+	"github.com/golang-jwt/jwt/v5"
+	"go.uber.org/fx"
 	"go.uber.org/zap"
 
 	"github.com/pmaojo/kthulu-go/backend/core"
@@ -19,17 +25,57 @@ import (
 	"github.com/pmaojo/kthulu-go/backend/internal/usecase"
 )
 
+// testTokenManager signs the access tokens of authenticateAsMember
+var testTokenManager, _ = core.NewJWT(&core.Config{JWT: core.JWTConfig{Secret: "access-secret", RefreshSecret: "refresh-secret"}})
+
+// authenticateAsMember signs every request as user 1, the member of
+// organization 1 in testMemberships, with a DPoP proof bound to the request,
+// so routes behind RequireAuth and OrganizationMiddleware can be exercised
+func authenticateAsMember(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, _ := testTokenManager.SignAccessToken(jwt.MapClaims{"sub": 1, "exp": time.Now().Add(time.Minute).Unix()})
+		ath := sha256.Sum256([]byte(token))
+		proof, _ := json.Marshal(map[string]string{
+			"htm": r.Method,
+			"htu": r.URL.Scheme + "://" + r.Host + r.URL.Path,
+			"ath": base64.RawURLEncoding.EncodeToString(ath[:]),
+		})
+		r.Header.Set("Authorization", "Bearer "+token)
+		r.Header.Set("DPoP", "e30."+base64.RawURLEncoding.EncodeToString(proof)+".")
+		next.ServeHTTP(w, r)
+	})
+}
+
+func newTestContactHandler(uc *usecase.ContactUseCase, logger core.Logger) *ContactHandler {
+	return NewContactHandler(struct {
+		fx.In
+		ContactUC    *usecase.ContactUseCase
+		OrgUsers     repository.OrganizationUserRepository
+		TokenManager core.TokenManager
+		Denylist     repository.AccessTokenDenylist `optional:"true"`
+		Logger       core.Logger
+	}{
+		ContactUC:    uc,
+		OrgUsers:     testMemberships{},
+		TokenManager: testTokenManager,
+		Logger:       logger,
+	})
+}
+
 // mockContactRepository implements repository.ContactRepository for testing
 type mockContactRepository struct {
 	GetByIDFunc           func(ctx context.Context, organizationID, contactID uint) (*domain.Contact, error)
+	ListFunc              func(ctx context.Context, organizationID uint, filters repository.ContactFilters) ([]*domain.Contact, int64, error)
 	GetAddressByIDFunc    func(ctx context.Context, contactID, addressID uint) (*domain.ContactAddress, error)
 	UpdateAddressFunc     func(ctx context.Context, address *domain.ContactAddress) error
 	DeleteAddressFunc     func(ctx context.Context, contactID, addressID uint) error
 	SetPrimaryAddressFunc func(ctx context.Context, contactID, addressID uint) error
+	GetPhonesFunc         func(ctx context.Context, contactID uint) ([]*domain.ContactPhone, error)
 	GetPhoneByIDFunc      func(ctx context.Context, contactID, phoneID uint) (*domain.ContactPhone, error)
 	UpdatePhoneFunc       func(ctx context.Context, phone *domain.ContactPhone) error
 	DeletePhoneFunc       func(ctx context.Context, contactID, phoneID uint) error
 	SetPrimaryPhoneFunc   func(ctx context.Context, contactID, phoneID uint) error
+	StatsByPeriodFunc     func(ctx context.Context, organizationID uint, from, to time.Time) (*repository.ContactStatsTrend, error)
 }
 
 func (m *mockContactRepository) Create(ctx context.Context, contact *domain.Contact) error {
@@ -51,8 +97,14 @@ func (m *mockContactRepository) Delete(ctx context.Context, organizationID, cont
 	return nil
 }
 func (m *mockContactRepository) List(ctx context.Context, organizationID uint, filters repository.ContactFilters) ([]*domain.Contact, int64, error) {
+	if m.ListFunc != nil {
+		return m.ListFunc(ctx, organizationID, filters)
+	}
 	return nil, 0, nil
 }
+func (m *mockContactRepository) FindPotentialDuplicates(ctx context.Context, organizationID uint) ([]domain.DuplicateGroup, error) {
+	return nil, nil
+}
 func (m *mockContactRepository) CreateAddress(ctx context.Context, address *domain.ContactAddress) error {
 	return nil
 }
@@ -87,6 +139,9 @@ func (m *mockContactRepository) CreatePhone(ctx context.Context, phone *domain.C
 	return nil
 }
 func (m *mockContactRepository) GetPhonesByContactID(ctx context.Context, contactID uint) ([]*domain.ContactPhone, error) {
+	if m.GetPhonesFunc != nil {
+		return m.GetPhonesFunc(ctx, contactID)
+	}
 	return nil, nil
 }
 func (m *mockContactRepository) GetPhoneByID(ctx context.Context, contactID, phoneID uint) (*domain.ContactPhone, error) {
@@ -125,6 +180,43 @@ func (m *mockContactRepository) BulkDelete(ctx context.Context, organizationID u
 func (m *mockContactRepository) GetContactStats(ctx context.Context, organizationID uint) (*repository.ContactStats, error) {
 	return nil, nil
 }
+func (m *mockContactRepository) GetContactStatsByPeriod(ctx context.Context, organizationID uint, from, to time.Time) (*repository.ContactStatsTrend, error) {
+	if m.StatsByPeriodFunc != nil {
+		return m.StatsByPeriodFunc(ctx, organizationID, from, to)
+	}
+	return &repository.ContactStatsTrend{From: from, To: to}, nil
+}
+
+func TestContactHandler_RequiresOrganizationMember(t *testing.T) {
+	repo := &mockContactRepository{
+		GetByIDFunc: func(ctx context.Context, orgID, contactID uint) (*domain.Contact, error) {
+			return &domain.Contact{ID: contactID, OrganizationID: orgID}, nil
+		},
+	}
+	zapLogger := zap.NewNop()
+	handler := newTestContactHandler(usecase.NewContactUseCase(repo, nil, nil, zapLogger), core.NewLoggerFromZap(zapLogger))
+
+	anonymous := chi.NewRouter()
+	handler.RegisterRoutes(anonymous)
+	req := httptest.NewRequest(http.MethodGet, "/contacts/1", nil)
+	req.Header.Set("X-Organization-ID", "1")
+	w := httptest.NewRecorder()
+	anonymous.ServeHTTP(w, req)
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 without a token, got %d", w.Code)
+	}
+
+	router := chi.NewRouter()
+	router.Use(authenticateAsMember)
+	handler.RegisterRoutes(router)
+	req = httptest.NewRequest(http.MethodGet, "/contacts/1", nil)
+	req.Header.Set("X-Organization-ID", "2")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 for another organization, got %d", w.Code)
+	}
+}
 
 func TestContactHandler_AddressRoutes(t *testing.T) {
 	repo := &mockContactRepository{
@@ -136,11 +228,11 @@ func TestContactHandler_AddressRoutes(t *testing.T) {
 		},
 	}
 	zapLogger := zap.NewNop()
-	uc := usecase.NewContactUseCase(repo, zapLogger)
-	handler := NewContactHandler(uc, core.NewLoggerFromZap(zapLogger))
+	uc := usecase.NewContactUseCase(repo, nil, nil, zapLogger)
+	handler := newTestContactHandler(uc, core.NewLoggerFromZap(zapLogger))
 
 	router := chi.NewRouter()
-	router.Use(middleware.OrganizationContextMiddleware)
+	router.Use(authenticateAsMember)
 	handler.RegisterRoutes(router)
 
 	// Update address
@@ -190,11 +282,11 @@ func TestContactHandler_PhoneRoutes(t *testing.T) {
 		},
 	}
 	zapLogger := zap.NewNop()
-	uc := usecase.NewContactUseCase(repo, zapLogger)
-	handler := NewContactHandler(uc, core.NewLoggerFromZap(zapLogger))
+	uc := usecase.NewContactUseCase(repo, nil, nil, zapLogger)
+	handler := newTestContactHandler(uc, core.NewLoggerFromZap(zapLogger))
 
 	router := chi.NewRouter()
-	router.Use(middleware.OrganizationContextMiddleware)
+	router.Use(authenticateAsMember)
 	handler.RegisterRoutes(router)
 
 	// Update phone
@@ -233,3 +325,263 @@ func TestContactHandler_PhoneRoutes(t *testing.T) {
 		t.Fatalf("expected 204, got %d", w.Code)
 	}
 }
+
+func TestContactHandler_StatsTrend(t *testing.T) {
+	var gotFrom, gotTo time.Time
+	repo := &mockContactRepository{
+		StatsByPeriodFunc: func(ctx context.Context, orgID uint, from, to time.Time) (*repository.ContactStatsTrend, error) {
+			gotFrom, gotTo = from, to
+			return &repository.ContactStatsTrend{From: from, To: to, Months: []repository.ContactMonthStats{
+				{Month: "2025-01", Total: 2, ByType: map[domain.ContactType]int64{domain.ContactTypeLead: 2}},
+				{Month: "2025-02", Total: 1, ByType: map[domain.ContactType]int64{domain.ContactTypeCustomer: 1}},
+				{Month: "2025-03", Total: 0, ByType: map[domain.ContactType]int64{}},
+			}}, nil
+		},
+	}
+	zapLogger := zap.NewNop()
+	uc := usecase.NewContactUseCase(repo, nil, nil, zapLogger)
+	handler := newTestContactHandler(uc, core.NewLoggerFromZap(zapLogger))
+
+	router := chi.NewRouter()
+	router.Use(authenticateAsMember)
+	handler.RegisterRoutes(router)
+
+	req := httptest.NewRequest(http.MethodGet, "/contacts/stats/trend?from=2025-01&to=2025-03", nil)
+	req.Header.Set("X-Organization-ID", "1")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if !gotFrom.Equal(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)) || !gotTo.Equal(time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected period [%s, %s)", gotFrom, gotTo)
+	}
+
+	var trend repository.ContactStatsTrend
+	if err := json.NewDecoder(w.Body).Decode(&trend); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(trend.Months) != 3 || trend.Months[1].ByType[domain.ContactTypeCustomer] != 1 {
+		t.Fatalf("unexpected trend: %+v", trend)
+	}
+
+	// Reversed and malformed periods are rejected
+	for _, query := range []string{"from=2025-03&to=2025-01", "from=2025-13", "from=2020-01&to=2025-01"} {
+		req = httptest.NewRequest(http.MethodGet, "/contacts/stats/trend?"+query, nil)
+		req.Header.Set("X-Organization-ID", "1")
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != http.StatusBadRequest {
+			t.Fatalf("%s: expected 400, got %d", query, w.Code)
+		}
+	}
+}
+
+func TestContactHandler_ListContactsEnvelope(t *testing.T) {
+	// 45 contacts, listed 20 per page
+	repo := &mockContactRepository{
+		ListFunc: func(ctx context.Context, orgID uint, filters repository.ContactFilters) ([]*domain.Contact, int64, error) {
+			var contacts []*domain.Contact
+			for id := filters.GetOffset() + 1; id <= 45 && len(contacts) < filters.PageSize; id++ {
+				contacts = append(contacts, &domain.Contact{ID: uint(id), OrganizationID: orgID, CompanyName: "Acme " + strconv.Itoa(id)})
+			}
+			return contacts, 45, nil
+		},
+	}
+	zapLogger := zap.NewNop()
+	handler := newTestContactHandler(usecase.NewContactUseCase(repo, nil, nil, zapLogger), core.NewLoggerFromZap(zapLogger))
+
+	router := chi.NewRouter()
+	router.Use(authenticateAsMember)
+	handler.RegisterRoutes(router)
+
+	for _, tc := range []struct {
+		page, size int
+		first      uint
+	}{{2, 20, 21}, {3, 5, 41}, {4, 0, 0}} {
+		req := httptest.NewRequest(http.MethodGet, "/contacts?page="+strconv.Itoa(tc.page)+"&pageSize=20", nil)
+		req.Header.Set("X-Organization-ID", "1")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("page %d: expected 200, got %d: %s", tc.page, w.Code, w.Body.String())
+		}
+
+		var body map[string]json.RawMessage
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		var envelope usecase.ListResponse[domain.Contact]
+		if err := json.Unmarshal(w.Body.Bytes(), &envelope); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		if len(body) != 5 || string(body["data"]) == "null" {
+			t.Fatalf("page %d: unexpected envelope %s", tc.page, w.Body.String())
+		}
+		if envelope.Page != tc.page || envelope.PageSize != 20 || envelope.Total != 45 || envelope.TotalPages != 3 || len(envelope.Data) != tc.size {
+			t.Fatalf("page %d: unexpected envelope page=%d pageSize=%d total=%d totalPages=%d data=%d",
+				tc.page, envelope.Page, envelope.PageSize, envelope.Total, envelope.TotalPages, len(envelope.Data))
+		}
+		if tc.size > 0 && envelope.Data[0].ID != tc.first {
+			t.Fatalf("page %d: expected contact %d first, got %d", tc.page, tc.first, envelope.Data[0].ID)
+		}
+	}
+}
+
+func TestContactHandler_ExportContactsVCard(t *testing.T) {
+	// 150 customers span two pages of the export
+	var pages []int
+	repo := &mockContactRepository{
+		ListFunc: func(ctx context.Context, orgID uint, filters repository.ContactFilters) ([]*domain.Contact, int64, error) {
+			if filters.Type != domain.ContactTypeCustomer || filters.PageSize != repository.MaxPageSize {
+				t.Fatalf("unexpected filters %+v", filters)
+			}
+			pages = append(pages, filters.Page)
+			var contacts []*domain.Contact
+			for id := filters.GetOffset() + 1; id <= 150 && len(contacts) < filters.PageSize; id++ {
+				contacts = append(contacts, &domain.Contact{ID: uint(id), OrganizationID: orgID, Type: domain.ContactTypeCustomer, FirstName: "Ana", LastName: strconv.Itoa(id), Email: "ana" + strconv.Itoa(id) + "@example.com"})
+			}
+			return contacts, 150, nil
+		},
+		GetPhonesFunc: func(ctx context.Context, contactID uint) ([]*domain.ContactPhone, error) {
+			return []*domain.ContactPhone{
+				{ContactID: contactID, Type: domain.PhoneTypeHome, Number: "555-0199"},
+				{ContactID: contactID, Type: domain.PhoneTypeMobile, Number: "(650) 253-0000", NormalizedNumber: "+16502530000", IsPrimary: true},
+			}, nil
+		},
+	}
+	zapLogger := zap.NewNop()
+	handler := newTestContactHandler(usecase.NewContactUseCase(repo, nil, nil, zapLogger), core.NewLoggerFromZap(zapLogger))
+
+	router := chi.NewRouter()
+	router.Use(authenticateAsMember)
+	handler.RegisterRoutes(router)
+
+	req := httptest.NewRequest(http.MethodGet, "/contacts/export?format=vcard&type=customer&pageSize=10", nil)
+	req.Header.Set("X-Organization-ID", "1")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "text/vcard; charset=utf-8" {
+		t.Fatalf("unexpected content type %q", ct)
+	}
+	if len(pages) != 2 || pages[0] != 1 || pages[1] != 2 {
+		t.Fatalf("expected pages 1 and 2 to be fetched, got %v", pages)
+	}
+
+	body := w.Body.String()
+	if n := strings.Count(body, "BEGIN:VCARD\r\n"); n != 150 {
+		t.Fatalf("expected 150 vCards, got %d", n)
+	}
+	for _, want := range []string{
+		"FN:Ana 42\r\n",
+		"EMAIL;TYPE=INTERNET:ana42@example.com\r\n",
+		"TEL;TYPE=CELL,PREF:+16502530000\r\n",
+	} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("missing %q in export", want)
+		}
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/contacts/export?format=csv", nil)
+	req.Header.Set("X-Organization-ID", "1")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for unsupported format, got %d", w.Code)
+	}
+}
+
+// contactEventsCalendar serves the events linked to contacts
+type contactEventsCalendar struct {
+	repository.CalendarRepository
+	events map[uint][]domain.Event
+}
+
+func (c *contactEventsCalendar) ListEventsForContact(ctx context.Context, organizationID, contactID uint) ([]domain.Event, error) {
+	return c.events[contactID], nil
+}
+
+func TestContactHandler_ListContactEvents(t *testing.T) {
+	contactID := uint(1)
+	repo := &mockContactRepository{
+		GetByIDFunc: func(ctx context.Context, orgID, id uint) (*domain.Contact, error) {
+			if id != contactID {
+				return nil, domain.ErrContactNotFound
+			}
+			return &domain.Contact{ID: id, OrganizationID: orgID}, nil
+		},
+	}
+	calendar := &contactEventsCalendar{events: map[uint][]domain.Event{
+		contactID: {{ID: 7, Title: "Demo", EntityType: domain.EventEntityContact, EntityID: &contactID}},
+	}}
+	zapLogger := zap.NewNop()
+	uc := usecase.NewContactUseCase(repo, nil, calendar, zapLogger)
+	handler := newTestContactHandler(uc, core.NewLoggerFromZap(zapLogger))
+
+	router := chi.NewRouter()
+	router.Use(authenticateAsMember)
+	handler.RegisterRoutes(router)
+
+	req := httptest.NewRequest(http.MethodGet, "/contacts/1/events", nil)
+	req.Header.Set("X-Organization-ID", "1")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var events []struct {
+		ID         uint                   `json:"id"`
+		EntityType domain.EventEntityType `json:"entityType"`
+		EntityID   uint                   `json:"entityId"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&events); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(events) != 1 || events[0].ID != 7 || events[0].EntityType != domain.EventEntityContact || events[0].EntityID != contactID {
+		t.Fatalf("unexpected events: %+v", events)
+	}
+
+	// Contacts outside the organization are not found
+	req = httptest.NewRequest(http.MethodGet, "/contacts/2/events", nil)
+	req.Header.Set("X-Organization-ID", "1")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", w.Code)
+	}
+}
+
+func TestContactHandler_ErrorResponseIncludesTraceID(t *testing.T) {
+	repo := &mockContactRepository{
+		GetByIDFunc: func(ctx context.Context, organizationID, contactID uint) (*domain.Contact, error) {
+			return nil, domain.ErrContactNotFound
+		},
+	}
+	zapLogger := zap.NewNop()
+	handler := newTestContactHandler(usecase.NewContactUseCase(repo, nil, nil, zapLogger), core.NewLoggerFromZap(zapLogger))
+
+	router := chi.NewRouter()
+	router.Use(middleware.TraceIDMiddleware)
+	router.Use(authenticateAsMember)
+	handler.RegisterRoutes(router)
+
+	req := httptest.NewRequest(http.MethodGet, "/contacts/42", nil)
+	req.Header.Set("X-Organization-ID", "1")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp ErrorResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	header := w.Header().Get(middleware.TraceIDHeader)
+	if header == "" || resp.TraceID != header {
+		t.Fatalf("expected traceId %q to match %s header %q", resp.TraceID, middleware.TraceIDHeader, header)
+	}
+}
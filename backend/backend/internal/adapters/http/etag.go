@@ -0,0 +1,38 @@
+package adapterhttp
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// resourceETag builds a weak ETag for a resource from its id and the time it
+// was last updated
+func resourceETag(id uint, updatedAt time.Time) string {
+	return fmt.Sprintf(`W/"%d-%x"`, id, updatedAt.UnixNano())
+}
+
+// checkNotModified sets the ETag header and answers 304 Not Modified when the
+// request's If-None-Match matches it. It reports whether the response has
+// been written.
+func checkNotModified(w http.ResponseWriter, r *http.Request, etag string) bool {
+	w.Header().Set("ETag", etag)
+	if !etagMatches(r.Header.Get("If-None-Match"), etag) {
+		return false
+	}
+	w.WriteHeader(http.StatusNotModified)
+	return true
+}
+
+// etagMatches applies the weak comparison If-None-Match uses to a header
+// holding "*" or a list of entity tags
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,88 @@
+package adapterhttp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+
+	"github.com/pmaojo/kthulu-go/backend/core"
+	"github.com/pmaojo/kthulu-go/backend/internal/domain"
+	"github.com/pmaojo/kthulu-go/backend/internal/usecase"
+)
+
+func getWithIfNoneMatch(router http.Handler, path, ifNoneMatch string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	req.Header.Set("X-Organization-ID", "1")
+	if ifNoneMatch != "" {
+		req.Header.Set("If-None-Match", ifNoneMatch)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestInvoiceHandler_GetInvoice_ConditionalGet(t *testing.T) {
+	updatedAt := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	repo := newInvoiceStatusRepo()
+	repo.invoices[1].UpdatedAt = updatedAt
+	router := newInvoiceTestRouter(repo)
+
+	w := getWithIfNoneMatch(router, "/invoices/1", "")
+	etag := w.Header().Get("ETag")
+	if w.Code != http.StatusOK || etag != resourceETag(1, updatedAt) {
+		t.Fatalf("expected 200 with ETag %s, got %d %q", resourceETag(1, updatedAt), w.Code, etag)
+	}
+
+	w = getWithIfNoneMatch(router, "/invoices/1", etag)
+	if w.Code != http.StatusNotModified || w.Body.Len() != 0 {
+		t.Fatalf("expected empty 304 for matching ETag, got %d %q", w.Code, w.Body.String())
+	}
+
+	repo.invoices[1].UpdatedAt = updatedAt.Add(time.Second)
+	w = getWithIfNoneMatch(router, "/invoices/1", etag)
+	if w.Code != http.StatusOK || w.Header().Get("ETag") == etag {
+		t.Fatalf("expected 200 with a new ETag after an update, got %d %q", w.Code, w.Header().Get("ETag"))
+	}
+}
+
+func TestContactHandler_GetContact_ConditionalGet(t *testing.T) {
+	updatedAt := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	repo := &mockContactRepository{
+		GetByIDFunc: func(ctx context.Context, orgID, contactID uint) (*domain.Contact, error) {
+			return &domain.Contact{ID: contactID, OrganizationID: orgID, UpdatedAt: updatedAt}, nil
+		},
+	}
+	zapLogger := zap.NewNop()
+	handler := newTestContactHandler(usecase.NewContactUseCase(repo, nil, nil, zapLogger), core.NewLoggerFromZap(zapLogger))
+	router := chi.NewRouter()
+	router.Use(authenticateAsMember)
+	handler.RegisterRoutes(router)
+
+	etag := resourceETag(5, updatedAt)
+	tests := []struct {
+		name        string
+		ifNoneMatch string
+		want        int
+	}{
+		{"no precondition", "", http.StatusOK},
+		{"matching", etag, http.StatusNotModified},
+		{"matching in list", `W/"1-0", ` + etag, http.StatusNotModified},
+		{"non-matching", `W/"5-0"`, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := getWithIfNoneMatch(router, "/contacts/5", tt.ifNoneMatch)
+			if w.Code != tt.want {
+				t.Fatalf("expected %d, got %d", tt.want, w.Code)
+			}
+			if w.Header().Get("ETag") != etag {
+				t.Fatalf("expected ETag %s, got %q", etag, w.Header().Get("ETag"))
+			}
+		})
+	}
+}
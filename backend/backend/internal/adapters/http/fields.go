@@ -0,0 +1,90 @@
+package adapterhttp
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+// parseFields reads the comma-separated fields query parameter and checks
+// every name against the JSON fields of model. It returns nil when the
+// parameter is absent.
+func parseFields(r *http.Request, model interface{}) ([]string, error) {
+	raw := r.URL.Query().Get("fields")
+	if raw == "" {
+		return nil, nil
+	}
+
+	known := jsonFieldNames(reflect.TypeOf(model))
+	var fields []string
+	for _, field := range strings.Split(raw, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		if _, ok := known[field]; !ok {
+			return nil, fmt.Errorf("unknown field %q", field)
+		}
+		fields = append(fields, field)
+	}
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("fields must name at least one field")
+	}
+	return fields, nil
+}
+
+// jsonFieldNames returns the names a struct type is marshaled with
+func jsonFieldNames(t reflect.Type) map[string]struct{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	names := make(map[string]struct{}, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		names[name] = struct{}{}
+	}
+	return names
+}
+
+// sparseFieldset marshals a list response and prunes every element of its
+// listKey array down to fields. The rest of the envelope is kept as is.
+func sparseFieldset(response interface{}, listKey string, fields []string) (map[string]json.RawMessage, error) {
+	encoded, err := json.Marshal(response)
+	if err != nil {
+		return nil, err
+	}
+	var envelope map[string]json.RawMessage
+	if err := json.Unmarshal(encoded, &envelope); err != nil {
+		return nil, err
+	}
+	var items []map[string]json.RawMessage
+	if err := json.Unmarshal(envelope[listKey], &items); err != nil {
+		return nil, err
+	}
+
+	pruned := make([]map[string]json.RawMessage, len(items))
+	for i, item := range items {
+		pruned[i] = make(map[string]json.RawMessage, len(fields))
+		for _, field := range fields {
+			if value, ok := item[field]; ok {
+				pruned[i][field] = value
+			}
+		}
+	}
+	if envelope[listKey], err = json.Marshal(pruned); err != nil {
+		return nil, err
+	}
+	return envelope, nil
+}
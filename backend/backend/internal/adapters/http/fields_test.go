@@ -0,0 +1,59 @@
+package adapterhttp
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"testing"
+)
+
+func TestInvoiceHandler_ListInvoices_SparseFieldset(t *testing.T) {
+	router := newInvoiceTestRouter(newInvoiceStatusRepo())
+
+	req := httptest.NewRequest(http.MethodGet, "/invoices?fields=id,%20status", nil)
+	req.Header.Set("X-Organization-ID", "1")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var body struct {
+		Invoices []map[string]json.RawMessage `json:"data"`
+		Total    int64                        `json:"total"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&body); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if body.Total != 4 || len(body.Invoices) != 4 {
+		t.Fatalf("expected the pagination envelope to be kept, got total=%d invoices=%d", body.Total, len(body.Invoices))
+	}
+	for _, invoice := range body.Invoices {
+		keys := make([]string, 0, len(invoice))
+		for key := range invoice {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		if len(keys) != 2 || keys[0] != "id" || keys[1] != "status" {
+			t.Fatalf("expected only id and status, got %v", keys)
+		}
+	}
+	if string(body.Invoices[0]["status"]) != `"draft"` {
+		t.Fatalf("unexpected status %s", body.Invoices[0]["status"])
+	}
+}
+
+func TestInvoiceHandler_ListInvoices_RejectsUnknownField(t *testing.T) {
+	router := newInvoiceTestRouter(newInvoiceStatusRepo())
+
+	for _, fields := range []string{"id,secret", "Status", ","} {
+		req := httptest.NewRequest(http.MethodGet, "/invoices?fields="+fields, nil)
+		req.Header.Set("X-Organization-ID", "1")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != http.StatusBadRequest {
+			t.Fatalf("fields=%s: expected 400, got %d", fields, w.Code)
+		}
+	}
+}
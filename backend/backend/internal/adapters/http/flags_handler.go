@@ -0,0 +1,92 @@
+// @kthulu:core
+package adapterhttp
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/fx"
+
+	"github.com/pmaojo/kthulu-go/backend/core"
+	"github.com/pmaojo/kthulu-go/backend/internal/adapters/http/middleware"
+	flagcfg "github.com/pmaojo/kthulu-go/backend/internal/adapters/http/modules/flags"
+	"github.com/pmaojo/kthulu-go/backend/internal/domain/repository"
+)
+
+// FlagsHandler exposes the feature flags evaluated for the caller
+type FlagsHandler struct {
+	evaluator    *flagcfg.FlagEvaluator
+	orgUsers     repository.OrganizationUserRepository
+	tokenManager core.TokenManager
+	denylist     repository.AccessTokenDenylist
+}
+
+// NewFlagsHandler creates a new flags handler. The denylist and memberships
+// are optional since the flags module is loaded without the auth and
+// organization repositories; without memberships organization rollouts are
+// not evaluated.
+func NewFlagsHandler(p struct {
+	fx.In
+	Evaluator    *flagcfg.FlagEvaluator
+	OrgUsers     repository.OrganizationUserRepository `optional:"true"`
+	TokenManager core.TokenManager
+	Denylist     repository.AccessTokenDenylist `optional:"true"`
+}) *FlagsHandler {
+	return &FlagsHandler{
+		evaluator:    p.Evaluator,
+		orgUsers:     p.OrgUsers,
+		tokenManager: p.TokenManager,
+		denylist:     p.Denylist,
+	}
+}
+
+// RegisterRoutes registers the flags route
+func (h *FlagsHandler) RegisterRoutes(r chi.Router) {
+	r.Group(func(r chi.Router) {
+		r.Use(middleware.RequireAuth(h.tokenManager, h.denylist))
+		r.Get("/flags", instrumentHandler("flags.evaluate", h.evaluateForPrincipal()))
+	})
+}
+
+// evaluateForPrincipal evaluates the flags for the user alone, or for their
+// organization as well when X-Organization-ID names one they belong to
+func (h *FlagsHandler) evaluateForPrincipal() http.HandlerFunc {
+	if h.orgUsers == nil {
+		return h.evaluate
+	}
+	forMember := middleware.OrganizationMiddleware(h.orgUsers)(http.HandlerFunc(h.evaluate))
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Organization-ID") == "" {
+			h.evaluate(w, r)
+			return
+		}
+		forMember.ServeHTTP(w, r)
+	}
+}
+
+// FlagsResponse holds the evaluated flags keyed by name
+type FlagsResponse struct {
+	Flags map[string]bool `json:"flags"`
+}
+
+// evaluate godoc
+// @Summary Evaluate feature flags
+// @Description Returns every configured flag evaluated for the authenticated user and, when X-Organization-ID names an organization they belong to, that organization
+// @Tags Flags
+// @Produce json
+// @Security BearerAuth
+// @Param X-Organization-ID header int false "Organization used for organization rollouts"
+// @Success 200 {object} FlagsResponse "Evaluated flags"
+// @Failure 401 {object} map[string]string "Unauthorized - invalid or missing token"
+// @Failure 403 {object} map[string]string "Forbidden - not a member of the organization"
+// @Router /flags [get]
+func (h *FlagsHandler) evaluate(w http.ResponseWriter, r *http.Request) {
+	principal := flagcfg.Principal{}
+	principal.UserID, _ = middleware.GetUserID(r.Context())
+	principal.OrganizationID, _ = r.Context().Value(middleware.OrganizationIDKey).(uint)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "private, no-cache")
+	_ = json.NewEncoder(w).Encode(FlagsResponse{Flags: h.evaluator.Evaluate(principal)})
+}
@@ -0,0 +1,116 @@
+package graphql
+
+import (
+	"context"
+	"sync"
+
+	"github.com/pmaojo/kthulu-go/backend/internal/domain"
+)
+
+type batchKey struct{}
+
+// batch loads the fields nested under a list for the whole page at once. A
+// list resolver records which parents were listed together, and the first of
+// their nested fields to resolve loads the values of all of them, which the
+// others wait for.
+type batch struct {
+	mu     sync.Mutex
+	groups map[interface{}]*group
+	loads  map[loadKey]*pendingLoad
+}
+
+// group is the IDs nested fields of parents listed together are loaded for
+type group struct {
+	ids []uint
+}
+
+// loadKey identifies a load of field for the parents of group, with the
+// arguments of the field
+type loadKey struct {
+	group *group
+	field string
+	args  interface{}
+}
+
+type pendingLoad struct {
+	done   chan struct{}
+	values interface{}
+	err    error
+}
+
+func withBatch(ctx context.Context) context.Context {
+	return context.WithValue(ctx, batchKey{}, &batch{
+		groups: make(map[interface{}]*group),
+		loads:  make(map[loadKey]*pendingLoad),
+	})
+}
+
+func batchFrom(ctx context.Context) *batch {
+	if b, ok := ctx.Value(batchKey{}).(*batch); ok {
+		return b
+	}
+	// Outside of Server.Execute nothing is shared between fields
+	return withBatch(ctx).Value(batchKey{}).(*batch)
+}
+
+// addContacts groups a page of contacts by their IDs
+func (b *batch) addContacts(contacts []*domain.Contact) {
+	g := &group{}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, contact := range contacts {
+		g.ids = append(g.ids, contact.ID)
+		b.groups[contact] = g
+	}
+}
+
+// addInvoices groups a page of invoices by the contacts they were issued to
+func (b *batch) addInvoices(invoices []*domain.Invoice) {
+	g := &group{}
+	seen := make(map[uint]bool, len(invoices))
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, invoice := range invoices {
+		if !seen[invoice.ContactID] {
+			seen[invoice.ContactID] = true
+			g.ids = append(g.ids, invoice.ContactID)
+		}
+		b.groups[invoice] = g
+	}
+}
+
+// groupOf returns the group of parent, which is a group of its own when it
+// wasn't listed
+func (b *batch) groupOf(parent interface{}, id uint) *group {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if g, ok := b.groups[parent]; ok {
+		return g
+	}
+	g := &group{ids: []uint{id}}
+	b.groups[parent] = g
+	return g
+}
+
+// load returns the values of key, fetching them for the IDs of its group
+// unless another field already did
+func load[T any](b *batch, key loadKey, fetch func(ids []uint) (map[uint]T, error)) (map[uint]T, error) {
+	b.mu.Lock()
+	pending, loading := b.loads[key]
+	if !loading {
+		pending = &pendingLoad{done: make(chan struct{})}
+		b.loads[key] = pending
+	}
+	b.mu.Unlock()
+
+	if loading {
+		<-pending.done
+	} else {
+		pending.values, pending.err = fetch(key.group.ids)
+		close(pending.done)
+	}
+	if pending.err != nil {
+		return nil, pending.err
+	}
+	return pending.values.(map[uint]T), nil
+}
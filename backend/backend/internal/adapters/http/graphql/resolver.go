@@ -0,0 +1,343 @@
+//go:generate go run -mod=mod github.com/99designs/gqlgen generate
+
+package graphql
+
+import (
+	"context"
+	"errors"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/vektah/gqlparser/v2/ast"
+	"go.uber.org/zap"
+
+	"github.com/pmaojo/kthulu-go/backend/internal/adapters/http/middleware"
+	"github.com/pmaojo/kthulu-go/backend/internal/domain"
+	"github.com/pmaojo/kthulu-go/backend/internal/domain/repository"
+	"github.com/pmaojo/kthulu-go/backend/internal/usecase"
+)
+
+// errInternal is reported for resolver failures that aren't the caller's
+// fault, so storage errors don't leak into responses
+var errInternal = errors.New("internal error")
+
+// Resolver resolves the schema with the use cases of the loaded modules and
+// scopes every query to the caller's organization. Any use case can be nil,
+// in which case the fields it resolves are left out.
+type Resolver struct {
+	contacts *usecase.ContactUseCase
+	invoices *usecase.InvoiceUseCase
+	products *usecase.ProductUseCase
+	logger   *zap.Logger
+}
+
+// NewResolver creates a new resolver
+func NewResolver(contacts *usecase.ContactUseCase, invoices *usecase.InvoiceUseCase, products *usecase.ProductUseCase, logger *zap.Logger) *Resolver {
+	return &Resolver{contacts: contacts, invoices: invoices, products: products, logger: logger}
+}
+
+// unavailableFields names the fields, as Type.field, of the use cases that
+// aren't loaded
+func (r *Resolver) unavailableFields() map[string]bool {
+	unavailable := make(map[string]bool)
+	if r.products == nil {
+		unavailable["Query.product"] = true
+		unavailable["Query.products"] = true
+		unavailable["InvoiceItem.product"] = true
+	}
+	if r.contacts == nil {
+		unavailable["Query.contact"] = true
+		unavailable["Query.contacts"] = true
+		unavailable["Invoice.contact"] = true
+	}
+	if r.invoices == nil {
+		unavailable["Query.invoice"] = true
+		unavailable["Query.invoices"] = true
+		unavailable["Contact.invoices"] = true
+	}
+	return unavailable
+}
+
+func (r *Resolver) Query() QueryResolver                   { return &queryResolver{r} }
+func (r *Resolver) Contact() ContactResolver               { return &contactResolver{r} }
+func (r *Resolver) ContactAddress() ContactAddressResolver { return &contactAddressResolver{r} }
+func (r *Resolver) ContactPhone() ContactPhoneResolver     { return &contactPhoneResolver{r} }
+func (r *Resolver) Invoice() InvoiceResolver               { return &invoiceResolver{r} }
+func (r *Resolver) InvoiceItem() InvoiceItemResolver       { return &invoiceItemResolver{r} }
+func (r *Resolver) Payment() PaymentResolver               { return &paymentResolver{r} }
+func (r *Resolver) ProductPrice() ProductPriceResolver     { return &productPriceResolver{r} }
+
+type queryResolver struct{ *Resolver }
+type contactResolver struct{ *Resolver }
+type contactAddressResolver struct{ *Resolver }
+type contactPhoneResolver struct{ *Resolver }
+type invoiceResolver struct{ *Resolver }
+type invoiceItemResolver struct{ *Resolver }
+type paymentResolver struct{ *Resolver }
+type productPriceResolver struct{ *Resolver }
+
+func organizationID(ctx context.Context) uint {
+	organizationID, _ := ctx.Value(middleware.OrganizationIDKey).(uint)
+	return organizationID
+}
+
+// resolverError turns a use case error into the error of a field. Missing
+// entities resolve to null.
+func (r *Resolver) resolverError(err error, notFound error) error {
+	if notFound != nil && errors.Is(err, notFound) {
+		return nil
+	}
+	r.logger.Error("Failed to resolve GraphQL field", zap.Error(err))
+	return errInternal
+}
+
+func (r *queryResolver) Contact(ctx context.Context, id uint) (*domain.Contact, error) {
+	contact, err := r.contacts.GetContact(ctx, organizationID(ctx), id)
+	if err != nil {
+		return nil, r.resolverError(err, domain.ErrContactNotFound)
+	}
+	return contact, nil
+}
+
+func (r *queryResolver) Contacts(ctx context.Context, page, pageSize *int, search, contactType *string) (*usecase.ContactListResponse, error) {
+	filters := repository.ContactFilters{
+		Search:           stringArg(search),
+		Type:             domain.ContactType(stringArg(contactType)),
+		IncludeRelations: selects(ctx, "data", "addresses") || selects(ctx, "data", "phones"),
+	}
+	filters.Page, filters.PageSize = listPage(page, pageSize)
+
+	list, err := r.contacts.ListContacts(ctx, organizationID(ctx), filters)
+	if err != nil {
+		return nil, r.resolverError(err, nil)
+	}
+	batchFrom(ctx).addContacts(list.Data)
+	return list, nil
+}
+
+func (r *queryResolver) Invoice(ctx context.Context, id uint) (*domain.Invoice, error) {
+	invoice, err := r.invoices.GetInvoice(ctx, organizationID(ctx), id)
+	if err != nil {
+		return nil, r.resolverError(err, domain.ErrInvoiceNotFound)
+	}
+	return invoice, nil
+}
+
+func (r *queryResolver) Invoices(ctx context.Context, page, pageSize *int, search *string, contactID *uint, status *string) (*usecase.InvoiceListResponse, error) {
+	filters := invoiceFilters(ctx, page, pageSize, status)
+	filters.Search = stringArg(search)
+	filters.ContactID = contactID
+
+	list, err := r.invoices.ListInvoices(ctx, organizationID(ctx), filters)
+	if err != nil {
+		return nil, r.resolverError(err, nil)
+	}
+	batchFrom(ctx).addInvoices(list.Data)
+	return list, nil
+}
+
+func (r *queryResolver) Product(ctx context.Context, id uint) (*domain.Product, error) {
+	return r.getProduct(ctx, id)
+}
+
+func (r *queryResolver) Products(ctx context.Context, page, pageSize *int, search, category *string) (*usecase.ProductListResponse, error) {
+	filters := repository.ProductFilters{
+		Search:          stringArg(search),
+		Category:        stringArg(category),
+		IncludeVariants: selects(ctx, "data", "variants"),
+		IncludePrices:   selects(ctx, "data", "prices"),
+	}
+	filters.Page, filters.PageSize = listPage(page, pageSize)
+
+	list, err := r.products.ListProducts(ctx, organizationID(ctx), filters)
+	if err != nil {
+		return nil, r.resolverError(err, nil)
+	}
+	return list, nil
+}
+
+func (r *Resolver) getProduct(ctx context.Context, id uint) (*domain.Product, error) {
+	includeRelated := selects(ctx, "variants") || selects(ctx, "prices")
+	product, err := r.products.GetProduct(ctx, organizationID(ctx), id, includeRelated)
+	if err != nil {
+		return nil, r.resolverError(err, domain.ErrProductNotFound)
+	}
+	return product, nil
+}
+
+// Invoices loads the requested page of invoices for every contact listed
+// along with obj at once
+func (r *contactResolver) Invoices(ctx context.Context, obj *domain.Contact, page, pageSize *int, status *string) (*usecase.InvoiceListResponse, error) {
+	filters := invoiceFilters(ctx, page, pageSize, status)
+	b := batchFrom(ctx)
+	key := loadKey{
+		group: b.groupOf(obj, obj.ID),
+		field: "Contact.invoices",
+		args:  [5]interface{}{filters.Page, filters.PageSize, stringArg(status), filters.IncludeItems, filters.IncludePayments},
+	}
+	lists, err := load(b, key, func(ids []uint) (map[uint]*usecase.InvoiceListResponse, error) {
+		lists, err := r.invoices.ListContactInvoices(ctx, organizationID(ctx), ids, filters)
+		if err != nil {
+			return nil, err
+		}
+		var invoices []*domain.Invoice
+		for _, list := range lists {
+			invoices = append(invoices, list.Data...)
+		}
+		b.addInvoices(invoices)
+		return lists, nil
+	})
+	if err != nil {
+		return nil, r.resolverError(err, nil)
+	}
+	return lists[obj.ID], nil
+}
+
+func (r *contactResolver) Type(ctx context.Context, obj *domain.Contact) (string, error) {
+	return string(obj.Type), nil
+}
+
+func (r *contactAddressResolver) Type(ctx context.Context, obj *domain.ContactAddress) (string, error) {
+	return string(obj.Type), nil
+}
+
+func (r *contactPhoneResolver) Type(ctx context.Context, obj *domain.ContactPhone) (string, error) {
+	return string(obj.Type), nil
+}
+
+// Contact loads the contacts of every invoice listed along with obj at once
+func (r *invoiceResolver) Contact(ctx context.Context, obj *domain.Invoice) (*domain.Contact, error) {
+	includeRelations := selects(ctx, "addresses") || selects(ctx, "phones")
+	b := batchFrom(ctx)
+	key := loadKey{group: b.groupOf(obj, obj.ContactID), field: "Invoice.contact", args: includeRelations}
+	contacts, err := load(b, key, func(ids []uint) (map[uint]*domain.Contact, error) {
+		filters := repository.ContactFilters{IDs: ids, Page: 1, PageSize: len(ids), IncludeRelations: includeRelations}
+		list, err := r.contacts.ListContacts(ctx, organizationID(ctx), filters)
+		if err != nil {
+			return nil, err
+		}
+		contacts := make(map[uint]*domain.Contact, len(list.Data))
+		for _, contact := range list.Data {
+			contacts[contact.ID] = contact
+		}
+		return contacts, nil
+	})
+	if err != nil {
+		return nil, r.resolverError(err, nil)
+	}
+	return contacts[obj.ContactID], nil
+}
+
+func (r *invoiceResolver) Items(ctx context.Context, obj *domain.Invoice) ([]*domain.InvoiceItem, error) {
+	if obj.Items != nil {
+		items := make([]*domain.InvoiceItem, len(obj.Items))
+		for i := range obj.Items {
+			items[i] = &obj.Items[i]
+		}
+		return items, nil
+	}
+	items, err := r.invoices.GetInvoiceItems(ctx, organizationID(ctx), obj.ID)
+	if err != nil {
+		return nil, r.resolverError(err, domain.ErrInvoiceNotFound)
+	}
+	return items, nil
+}
+
+func (r *invoiceResolver) Payments(ctx context.Context, obj *domain.Invoice) ([]*domain.Payment, error) {
+	if obj.Payments != nil {
+		payments := make([]*domain.Payment, len(obj.Payments))
+		for i := range obj.Payments {
+			payments[i] = &obj.Payments[i]
+		}
+		return payments, nil
+	}
+	payments, err := r.invoices.GetInvoicePayments(ctx, organizationID(ctx), obj.ID)
+	if err != nil {
+		return nil, r.resolverError(err, domain.ErrInvoiceNotFound)
+	}
+	return payments, nil
+}
+
+func (r *invoiceResolver) Status(ctx context.Context, obj *domain.Invoice) (string, error) {
+	return string(obj.Status), nil
+}
+
+func (r *invoiceResolver) Type(ctx context.Context, obj *domain.Invoice) (string, error) {
+	return string(obj.Type), nil
+}
+
+func (r *invoiceItemResolver) Product(ctx context.Context, obj *domain.InvoiceItem) (*domain.Product, error) {
+	if obj.ProductID == nil {
+		return nil, nil
+	}
+	return r.getProduct(ctx, *obj.ProductID)
+}
+
+func (r *paymentResolver) PaymentMethod(ctx context.Context, obj *domain.Payment) (string, error) {
+	return string(obj.PaymentMethod), nil
+}
+
+func (r *productPriceResolver) PriceType(ctx context.Context, obj *domain.ProductPrice) (string, error) {
+	return string(obj.PriceType), nil
+}
+
+// invoiceFilters builds the filters of an invoice list, loading the items
+// and payments with the page when the query selects them
+func invoiceFilters(ctx context.Context, page, pageSize *int, status *string) repository.InvoiceFilters {
+	filters := repository.InvoiceFilters{
+		IncludeItems:    selects(ctx, "data", "items"),
+		IncludePayments: selects(ctx, "data", "payments"),
+	}
+	filters.Page, filters.PageSize = listPage(page, pageSize)
+	if status != nil && *status != "" {
+		invoiceStatus := domain.InvoiceStatus(*status)
+		filters.Status = &invoiceStatus
+	}
+	return filters
+}
+
+// listPage returns the page arguments of a list, clamped like the REST lists
+func listPage(page, pageSize *int) (int, int) {
+	p, size := 1, repository.DefaultPageSize
+	if page != nil {
+		p = *page
+	}
+	if pageSize != nil {
+		size = *pageSize
+	}
+	return repository.ClampPage(p, size, repository.DefaultPageSize)
+}
+
+// listPageSize returns the size of the page a list returns
+func listPageSize(pageSize *int) int {
+	_, size := listPage(nil, pageSize)
+	return size
+}
+
+func stringArg(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
+
+// selects reports whether the field being resolved selects the subfield at
+// path, through fragments and aliases
+func selects(ctx context.Context, path ...string) bool {
+	fc := graphql.GetFieldContext(ctx)
+	if fc == nil {
+		return false
+	}
+	return selectsPath(graphql.GetOperationContext(ctx), fc.Field.Selections, path)
+}
+
+func selectsPath(opCtx *graphql.OperationContext, selections ast.SelectionSet, path []string) bool {
+	for _, field := range graphql.CollectFields(opCtx, selections, nil) {
+		if field.Name != path[0] {
+			continue
+		}
+		if len(path) == 1 || selectsPath(opCtx, field.Selections, path[1:]) {
+			return true
+		}
+	}
+	return false
+}
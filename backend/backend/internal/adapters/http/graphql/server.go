@@ -0,0 +1,160 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/executor"
+	"github.com/99designs/gqlgen/graphql/handler/extension"
+	"github.com/99designs/gqlgen/graphql/handler/lru"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+	"go.uber.org/zap"
+)
+
+const (
+	// DefaultMaxDepth bounds how deeply a query can nest selections,
+	// fragments included
+	DefaultMaxDepth = 10
+	// DefaultMaxComplexity bounds the cost of a query, where every field
+	// costs one and lists cost their page size times their selections
+	DefaultMaxComplexity = 5000
+	// queryCacheSize is the number of parsed queries kept for reuse
+	queryCacheSize = 100
+)
+
+// Request is a GraphQL request as posted over HTTP
+type Request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName,omitempty"`
+	Variables     map[string]interface{} `json:"variables,omitempty"`
+}
+
+// Response is the result of a request. Data is nil when the request could
+// not be executed at all, in which case Errors says why.
+type Response struct {
+	Data   json.RawMessage `json:"data,omitempty"`
+	Errors gqlerror.List   `json:"errors,omitempty"`
+}
+
+// Server executes read-only queries against the schema
+type Server struct {
+	exec *executor.Executor
+}
+
+// NewServer creates a server resolving queries with resolver. Queries are
+// rejected before they run when they nest deeper than DefaultMaxDepth, cost
+// more than DefaultMaxComplexity or select fields of modules that aren't
+// loaded.
+func NewServer(resolver *Resolver, logger *zap.Logger) *Server {
+	schema := NewExecutableSchema(Config{Resolvers: resolver, Complexity: complexity()})
+	exec := executor.New(schema)
+	exec.SetQueryCache(lru.New[*ast.QueryDocument](queryCacheSize))
+	exec.SetRecoverFunc(func(ctx context.Context, err interface{}) error {
+		logger.Error("GraphQL resolver panicked", zap.Any("panic", err))
+		return errInternal
+	})
+	exec.Use(&queryGuard{maxDepth: DefaultMaxDepth, unavailable: resolver.unavailableFields()})
+	exec.Use(extension.FixedComplexityLimit(DefaultMaxComplexity))
+	return &Server{exec: exec}
+}
+
+// Execute parses, validates and executes req
+func (s *Server) Execute(ctx context.Context, req Request) *Response {
+	ctx = graphql.StartOperationTrace(ctx)
+	opCtx, errs := s.exec.CreateOperationContext(ctx, &graphql.RawParams{
+		Query:         req.Query,
+		OperationName: req.OperationName,
+		Variables:     req.Variables,
+	})
+	if errs != nil {
+		return &Response{Errors: s.exec.DispatchError(graphql.WithOperationContext(ctx, opCtx), errs).Errors}
+	}
+
+	ctx = withBatch(ctx)
+	responses, ctx := s.exec.DispatchOperation(ctx, opCtx)
+	resp := responses(ctx)
+	if resp.Data == nil {
+		// The query ran and its root fields all failed
+		resp.Data = json.RawMessage("null")
+	}
+	return &Response{Data: resp.Data, Errors: resp.Errors}
+}
+
+// complexity prices the list fields by the page they return, so nested
+// lists multiply
+func complexity() ComplexityRoot {
+	var c ComplexityRoot
+	c.Query.Products = func(child int, page, pageSize *int, search, category *string) int {
+		return 1 + child*listPageSize(pageSize)
+	}
+	c.Query.Contacts = func(child int, page, pageSize *int, search, contactType *string) int {
+		return 1 + child*listPageSize(pageSize)
+	}
+	c.Query.Invoices = func(child int, page, pageSize *int, search *string, contactID *uint, status *string) int {
+		return 1 + child*listPageSize(pageSize)
+	}
+	c.Contact.Invoices = func(child int, page, pageSize *int, status *string) int {
+		return 1 + child*listPageSize(pageSize)
+	}
+	return c
+}
+
+// queryGuard rejects operations nesting selections deeper than maxDepth or
+// selecting any of the unavailable fields, named Type.field
+type queryGuard struct {
+	maxDepth    int
+	unavailable map[string]bool
+}
+
+var _ interface {
+	graphql.HandlerExtension
+	graphql.OperationContextMutator
+} = &queryGuard{}
+
+func (g *queryGuard) ExtensionName() string {
+	return "QueryGuard"
+}
+
+func (g *queryGuard) Validate(graphql.ExecutableSchema) error {
+	return nil
+}
+
+func (g *queryGuard) MutateOperationContext(ctx context.Context, opCtx *graphql.OperationContext) *gqlerror.Error {
+	return g.check(opCtx.Operation.SelectionSet, 1)
+}
+
+func (g *queryGuard) check(selections ast.SelectionSet, depth int) *gqlerror.Error {
+	for _, selection := range selections {
+		switch s := selection.(type) {
+		case *ast.Field:
+			if s.ObjectDefinition != nil && g.unavailable[s.ObjectDefinition.Name+"."+s.Name] {
+				return gqlerror.ErrorPosf(s.Position, "Cannot query field %q on type %q.", s.Name, s.ObjectDefinition.Name)
+			}
+			if len(s.SelectionSet) == 0 {
+				continue
+			}
+			if depth >= g.maxDepth {
+				return gqlerror.ErrorPosf(s.Position, "query is nested deeper than %d levels", g.maxDepth)
+			}
+			if err := g.check(s.SelectionSet, depth+1); err != nil {
+				return err
+			}
+		case *ast.InlineFragment:
+			if err := g.check(s.SelectionSet, depth); err != nil {
+				return err
+			}
+		case *ast.FragmentSpread:
+			if s.Definition == nil {
+				return gqlerror.ErrorPosf(s.Position, "unknown fragment %q", s.Name)
+			}
+			if err := g.check(s.Definition.SelectionSet, depth); err != nil {
+				return err
+			}
+		default:
+			return gqlerror.Errorf("unsupported selection %T", selection)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+// @kthulu:module:graphql
+package adapterhttp
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+
+	"github.com/pmaojo/kthulu-go/backend/core"
+	"github.com/pmaojo/kthulu-go/backend/internal/adapters/http/graphql"
+	"github.com/pmaojo/kthulu-go/backend/internal/adapters/http/middleware"
+	"github.com/pmaojo/kthulu-go/backend/internal/domain/repository"
+	"github.com/pmaojo/kthulu-go/backend/internal/usecase"
+)
+
+// maxGraphQLRequestBytes bounds the size of a posted GraphQL request
+const maxGraphQLRequestBytes = 1 << 20
+
+// GraphQLHandler serves read-only GraphQL queries over contacts, invoices and
+// products, resolved by their use cases and scoped to the caller's
+// organization
+type GraphQLHandler struct {
+	orgUsers     repository.OrganizationUserRepository
+	tokenManager core.TokenManager
+	denylist     repository.AccessTokenDenylist
+	server       *graphql.Server
+}
+
+// NewGraphQLHandler creates a new GraphQL handler. Each use case is optional
+// and queries selecting its fields are rejected when its module isn't loaded.
+func NewGraphQLHandler(p struct {
+	fx.In
+	ContactUC    *usecase.ContactUseCase `optional:"true"`
+	InvoiceUC    *usecase.InvoiceUseCase `optional:"true"`
+	ProductUC    *usecase.ProductUseCase `optional:"true"`
+	OrgUsers     repository.OrganizationUserRepository
+	TokenManager core.TokenManager
+	Denylist     repository.AccessTokenDenylist `optional:"true"`
+	Logger       *zap.Logger
+}) *GraphQLHandler {
+	resolver := graphql.NewResolver(p.ContactUC, p.InvoiceUC, p.ProductUC, p.Logger)
+	return &GraphQLHandler{
+		orgUsers:     p.OrgUsers,
+		tokenManager: p.TokenManager,
+		denylist:     p.Denylist,
+		server:       graphql.NewServer(resolver, p.Logger),
+	}
+}
+
+// RegisterRoutes registers the GraphQL route
+func (h *GraphQLHandler) RegisterRoutes(r chi.Router) {
+	r.Group(func(r chi.Router) {
+		r.Use(middleware.RequireAuth(h.tokenManager, h.denylist))
+		r.Use(middleware.OrganizationMiddleware(h.orgUsers))
+		r.Post("/graphql", instrumentHandler("graphql.query", h.Query))
+	})
+}
+
+// Query godoc
+// @Summary Run a GraphQL query
+// @Description Executes a read-only GraphQL query over the contacts, invoices and products of the organization. Mutations are not supported.
+// @Tags GraphQL
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param X-Organization-ID header int true "Organization ID"
+// @Param request body graphql.Request true "GraphQL request"
+// @Success 200 {object} graphql.Response "Query result, with errors of the fields that failed"
+// @Failure 400 {object} graphql.Response "The query could not be parsed or validated, or exceeds the depth or complexity limits"
+// @Failure 401 {object} map[string]string "Unauthorized - invalid or missing token"
+// @Failure 403 {object} map[string]string "Not a member of the organization"
+// @Router /graphql [post]
+func (h *GraphQLHandler) Query(w http.ResponseWriter, r *http.Request) {
+	var req graphql.Request
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxGraphQLRequestBytes))
+	decoder.UseNumber()
+	if err := decoder.Decode(&req); err != nil {
+		h.writeResponse(w, http.StatusBadRequest, &graphql.Response{Errors: gqlerror.List{gqlerror.Errorf("invalid request body")}})
+		return
+	}
+	if req.Query == "" {
+		h.writeResponse(w, http.StatusBadRequest, &graphql.Response{Errors: gqlerror.List{gqlerror.Errorf("query is required")}})
+		return
+	}
+
+	resp := h.server.Execute(r.Context(), req)
+	status := http.StatusOK
+	if resp.Data == nil {
+		status = http.StatusBadRequest
+	}
+	h.writeResponse(w, status, resp)
+}
+
+func (h *GraphQLHandler) writeResponse(w http.ResponseWriter, status int, resp *graphql.Response) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "private, no-cache")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(resp)
+}
@@ -0,0 +1,293 @@
+package adapterhttp
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/golang-jwt/jwt/v5"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+
+	"github.com/pmaojo/kthulu-go/backend/core"
+	"github.com/pmaojo/kthulu-go/backend/internal/domain"
+	"github.com/pmaojo/kthulu-go/backend/internal/domain/money"
+	"github.com/pmaojo/kthulu-go/backend/internal/domain/repository"
+	"github.com/pmaojo/kthulu-go/backend/internal/usecase"
+)
+
+// testMemberships lets user 1 into organization 1 only
+type testMemberships struct {
+	repository.OrganizationUserRepository
+}
+
+func (testMemberships) IsUserInOrganization(ctx context.Context, organizationID, userID uint) (bool, error) {
+	return organizationID == 1 && userID == 1, nil
+}
+
+// graphQLProductRepository serves the products invoice items refer to
+type graphQLProductRepository struct {
+	repository.ProductRepository
+
+	products map[uint]*domain.Product
+}
+
+func (m *graphQLProductRepository) GetByID(ctx context.Context, organizationID, productID uint) (*domain.Product, error) {
+	product, ok := m.products[productID]
+	if !ok || product.OrganizationID != organizationID {
+		return nil, domain.ErrProductNotFound
+	}
+	return product, nil
+}
+
+// graphQLInvoiceRepository lists the invoices of several contacts at once
+type graphQLInvoiceRepository struct {
+	*mockInvoiceRepository
+
+	listByContactsCalls int
+}
+
+func (m *graphQLInvoiceRepository) ListByContacts(ctx context.Context, organizationID uint, contactIDs []uint, filters repository.InvoiceFilters) (map[uint][]*domain.Invoice, map[uint]int64, error) {
+	m.listByContactsCalls++
+	invoices := map[uint][]*domain.Invoice{}
+	totals := map[uint]int64{}
+	all, _, _ := m.List(ctx, organizationID, filters)
+	for _, invoice := range all {
+		for _, contactID := range contactIDs {
+			if invoice.ContactID == contactID {
+				invoices[contactID] = append(invoices[contactID], invoice)
+				totals[contactID]++
+			}
+		}
+	}
+	return invoices, totals, nil
+}
+
+func newGraphQLTestRouter(t *testing.T) (chi.Router, string) {
+	t.Helper()
+	return newGraphQLRouter(t, nil, newGraphQLInvoiceRepository())
+}
+
+func newGraphQLInvoiceRepository() *graphQLInvoiceRepository {
+	productID := uint(5)
+	return &graphQLInvoiceRepository{mockInvoiceRepository: &mockInvoiceRepository{
+		invoices: map[uint]*domain.Invoice{
+			1: {ID: 1, OrganizationID: 1, ContactID: 10, InvoiceNumber: "INV-0001", Status: domain.InvoiceStatusSent, TotalAmount: 121},
+			2: {ID: 2, OrganizationID: 2, InvoiceNumber: "INV-0002"},
+			3: {ID: 3, OrganizationID: 1, ContactID: 11, InvoiceNumber: "INV-0003", Status: domain.InvoiceStatusPaid},
+		},
+		items: map[uint][]*domain.InvoiceItem{
+			1: {
+				{ID: 1, InvoiceID: 1, ProductID: &productID, Description: "Widget", Quantity: 2, UnitPrice: 50},
+				{ID: 2, InvoiceID: 1, Description: "Shipping", Quantity: 1, UnitPrice: 0},
+			},
+		},
+	}}
+}
+
+// newGraphQLRouter serves GraphQL over invoices and products, and over
+// contacts when contacts isn't nil
+func newGraphQLRouter(t *testing.T, contacts repository.ContactRepository, invoices *graphQLInvoiceRepository) (chi.Router, string) {
+	t.Helper()
+	products := &graphQLProductRepository{products: map[uint]*domain.Product{
+		5: {ID: 5, OrganizationID: 1, SKU: "WID-1", Name: "Widget"},
+	}}
+
+	tokens, err := core.NewJWT(&core.Config{JWT: core.JWTConfig{Secret: "access-secret", RefreshSecret: "refresh-secret"}})
+	if err != nil {
+		t.Fatalf("new jwt: %v", err)
+	}
+	token, err := tokens.SignAccessToken(jwt.MapClaims{"sub": 1, "exp": time.Now().Add(time.Minute).Unix()})
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+
+	logger := zap.NewNop()
+	var contactUC *usecase.ContactUseCase
+	if contacts != nil {
+		contactUC = usecase.NewContactUseCase(contacts, nil, nil, logger)
+	}
+	handler := NewGraphQLHandler(struct {
+		fx.In
+		ContactUC    *usecase.ContactUseCase `optional:"true"`
+		InvoiceUC    *usecase.InvoiceUseCase `optional:"true"`
+		ProductUC    *usecase.ProductUseCase `optional:"true"`
+		OrgUsers     repository.OrganizationUserRepository
+		TokenManager core.TokenManager
+		Denylist     repository.AccessTokenDenylist `optional:"true"`
+		Logger       *zap.Logger
+	}{
+		ContactUC:    contactUC,
+		InvoiceUC:    usecase.NewInvoiceUseCase(invoices, mockUnitOfWork{invoices.mockInvoiceRepository}, nil, nil, nil, fakeInvoiceRenderer{}, &fakeInvoiceNotifier{}, nil, nil, nil, nil, money.DefaultPolicy(), core.NewLoggerFromZap(logger)),
+		ProductUC:    usecase.NewProductUseCase(products, logger),
+		OrgUsers:     testMemberships{},
+		TokenManager: tokens,
+		Logger:       logger,
+	})
+
+	router := chi.NewRouter()
+	handler.RegisterRoutes(router)
+	return router, token
+}
+
+func postGraphQL(router chi.Router, token, orgID string, body interface{}) *httptest.ResponseRecorder {
+	payload, _ := json.Marshal(body)
+	req := httptest.NewRequest(http.MethodPost, "http://example.com/graphql", bytes.NewReader(payload))
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		// An unsigned DPoP proof bound to the request and token
+		ath := sha256.Sum256([]byte(token))
+		proof, _ := json.Marshal(map[string]string{
+			"htm": req.Method,
+			"htu": req.URL.Scheme + "://" + req.Host + req.URL.Path,
+			"ath": base64.RawURLEncoding.EncodeToString(ath[:]),
+		})
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("DPoP", "e30."+base64.RawURLEncoding.EncodeToString(proof)+".")
+	}
+	req.Header.Set("X-Organization-ID", orgID)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestGraphQLHandler_InvoiceWithItems(t *testing.T) {
+	router, token := newGraphQLTestRouter(t)
+
+	w := postGraphQL(router, token, "1", map[string]interface{}{
+		"query": `query Invoice($id: ID!) {
+			invoice(id: $id) {
+				invoiceNumber
+				status
+				items { description quantity product { sku name } }
+			}
+			other: invoice(id: 2) { id }
+		}`,
+		"variables": map[string]interface{}{"id": 1},
+	})
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	want := `{"data":{"invoice":{"invoiceNumber":"INV-0001","status":"sent","items":[` +
+		`{"description":"Widget","quantity":2,"product":{"sku":"WID-1","name":"Widget"}},` +
+		`{"description":"Shipping","quantity":1,"product":null}]},"other":null}}`
+	if got := string(bytes.TrimSpace(w.Body.Bytes())); got != want {
+		t.Fatalf("expected %s, got %s", want, got)
+	}
+}
+
+func TestGraphQLHandler_RejectsInvalidRequests(t *testing.T) {
+	router, token := newGraphQLTestRouter(t)
+	query := map[string]interface{}{"query": `{ invoice(id: 1) { id } }`}
+
+	if w := postGraphQL(router, "", "1", query); w.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 without a token, got %d", w.Code)
+	}
+	if w := postGraphQL(router, token, "2", query); w.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 outside the caller's organization, got %d", w.Code)
+	}
+
+	for _, body := range []map[string]interface{}{
+		{"query": `mutation { deleteInvoice(id: 1) }`},
+		{"query": `{ invoice(id: 1) { items } }`},
+		// Contacts are left out of the schema when their module isn't loaded
+		{"query": `{ contacts { total } }`},
+		{},
+	} {
+		w := postGraphQL(router, token, "1", body)
+		var resp struct {
+			Data   json.RawMessage `json:"data"`
+			Errors []struct {
+				Message string `json:"message"`
+			} `json:"errors"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		if w.Code != http.StatusBadRequest || resp.Data != nil || len(resp.Errors) == 0 {
+			t.Fatalf("%v: expected a 400 with errors and no data, got %d: %s", body, w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestGraphQLHandler_BatchesNestedLists(t *testing.T) {
+	var listed []repository.ContactFilters
+	contacts := &mockContactRepository{
+		ListFunc: func(ctx context.Context, organizationID uint, filters repository.ContactFilters) ([]*domain.Contact, int64, error) {
+			listed = append(listed, filters)
+			var page []*domain.Contact
+			for _, id := range []uint{10, 11} {
+				if len(filters.IDs) > 0 && !slices.Contains(filters.IDs, id) {
+					continue
+				}
+				contact := &domain.Contact{ID: id, OrganizationID: organizationID, CompanyName: fmt.Sprintf("Contact %d", id)}
+				if filters.IncludeRelations {
+					contact.Addresses = []domain.ContactAddress{{ContactID: id, City: "Springfield"}}
+				}
+				page = append(page, contact)
+			}
+			return page, int64(len(page)), nil
+		},
+		GetByIDFunc: func(ctx context.Context, organizationID, contactID uint) (*domain.Contact, error) {
+			t.Errorf("contact %d loaded on its own", contactID)
+			return nil, domain.ErrContactNotFound
+		},
+	}
+	invoices := newGraphQLInvoiceRepository()
+	router, token := newGraphQLRouter(t, contacts, invoices)
+
+	w := postGraphQL(router, token, "1", map[string]interface{}{
+		"query": `{
+			contacts {
+				data {
+					companyName
+					addresses { city }
+					invoices { total data { invoiceNumber contact { companyName } } }
+				}
+			}
+		}`,
+	})
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	want := `{"data":{"contacts":{"data":[` +
+		`{"companyName":"Contact 10","addresses":[{"city":"Springfield"}],"invoices":{"total":1,"data":[{"invoiceNumber":"INV-0001","contact":{"companyName":"Contact 10"}}]}},` +
+		`{"companyName":"Contact 11","addresses":[{"city":"Springfield"}],"invoices":{"total":1,"data":[{"invoiceNumber":"INV-0003","contact":{"companyName":"Contact 11"}}]}}]}}}`
+	if got := string(bytes.TrimSpace(w.Body.Bytes())); got != want {
+		t.Fatalf("expected %s, got %s", want, got)
+	}
+	if invoices.listByContactsCalls != 1 {
+		t.Fatalf("expected the invoices of all contacts in one call, got %d", invoices.listByContactsCalls)
+	}
+	// The page of contacts with their relations, then the contacts of their
+	// invoices at once
+	if len(listed) != 2 || !listed[0].IncludeRelations || len(listed[1].IDs) != 2 {
+		t.Fatalf("expected two contact lists, got %+v", listed)
+	}
+}
+
+func TestGraphQLHandler_RejectsExpensiveQueries(t *testing.T) {
+	router, token := newGraphQLRouter(t, &mockContactRepository{}, newGraphQLInvoiceRepository())
+
+	for query, reason := range map[string]string{
+		`{ invoices(pageSize: 100) { data { contact { invoices(pageSize: 100) { data { id invoiceNumber } } } } } }`:                         "complexity",
+		`{ invoices { data { contact { invoices { data { contact { invoices { data { contact { invoices { data { id } } } } } } } } } } } }`: "nested deeper",
+	} {
+		w := postGraphQL(router, token, "1", map[string]interface{}{"query": query})
+		if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), reason) {
+			t.Fatalf("expected a 400 for %s, got %d: %s", reason, w.Code, w.Body.String())
+		}
+	}
+}
@@ -2,9 +2,13 @@
 package adapterhttp
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
+	"net"
 	"net/http"
+	"net/url"
+	"strconv"
 	"time"
 
 	"github.com/go-chi/chi/v5"
@@ -12,27 +16,48 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/pmaojo/kthulu-go/backend/core"
+	"github.com/pmaojo/kthulu-go/backend/internal/adapters/http/middleware"
+	"github.com/pmaojo/kthulu-go/backend/internal/domain/repository"
 )
 
 // HealthHandler provides health check endpoints
 type HealthHandler struct {
-	db      *sql.DB
-	logger  *zap.Logger
-	version string
+	db           *sql.DB
+	logger       *zap.Logger
+	version      string
+	checks       []dependencyCheck
+	tokenManager core.TokenManager
+	denylist     repository.AccessTokenDenylist
 }
 
 // NewHealthHandler creates a new health handler
 func NewHealthHandler(p struct {
 	fx.In
-	DB     *sql.DB
-	Logger *zap.Logger
-	Config *core.Config
+	DB           *sql.DB
+	Logger       *zap.Logger
+	Config       *core.Config
+	TokenManager core.TokenManager              `optional:"true"`
+	Denylist     repository.AccessTokenDenylist `optional:"true"`
 }) *HealthHandler {
-	return &HealthHandler{
-		db:      p.DB,
-		logger:  p.Logger,
-		version: p.Config.Version,
+	h := &HealthHandler{
+		db:           p.DB,
+		logger:       p.Logger,
+		version:      p.Config.Version,
+		tokenManager: p.TokenManager,
+		denylist:     p.Denylist,
 	}
+	h.checks = []dependencyCheck{
+		{name: "database", run: h.checkDatabase},
+		{name: "migrations", run: h.checkMigrations},
+	}
+	if p.Config.SMTP.Enabled {
+		address := net.JoinHostPort(p.Config.SMTP.Host, strconv.Itoa(p.Config.SMTP.Port))
+		h.checks = append(h.checks, dependencyCheck{name: "smtp", run: reachabilityCheck(address)})
+	}
+	if p.Config.Sentry.Enabled && p.Config.Sentry.DSN != "" {
+		h.checks = append(h.checks, dependencyCheck{name: "sentry", run: sentryCheck(p.Config.Sentry.DSN)})
+	}
+	return h
 }
 
 // RegisterRoutes registers health check routes
@@ -40,6 +65,7 @@ func (h *HealthHandler) RegisterRoutes(r chi.Router) {
 	r.Get("/health", h.healthCheck)
 	r.Get("/health/ready", h.readinessCheck)
 	r.Get("/health/live", h.livenessCheck)
+	r.Get("/health/detail", h.detailForCaller())
 }
 
 // HealthResponse represents the health check response
@@ -123,3 +149,181 @@ func (h *HealthHandler) livenessCheck(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 	w.Write([]byte("Alive"))
 }
+
+// Dependency statuses reported by /health/detail
+const (
+	DependencyStatusOK       = "ok"
+	DependencyStatusDegraded = "degraded"
+	DependencyStatusDown     = "down"
+)
+
+// slowDatabaseLatency is the ping latency above which the database is degraded
+const slowDatabaseLatency = time.Second
+
+// dependencyCheckTimeout bounds each dependency check of /health/detail
+const dependencyCheckTimeout = 3 * time.Second
+
+// DependencyCheckResult is the outcome of one dependency check
+type DependencyCheckResult struct {
+	Status    string  `json:"status"`
+	Message   string  `json:"message,omitempty"`
+	LatencyMs float64 `json:"latencyMs,omitempty"`
+	Version   int64   `json:"version,omitempty"`
+}
+
+// HealthDetailResponse represents the detailed health check response
+type HealthDetailResponse struct {
+	Status    string                           `json:"status"`
+	Version   string                           `json:"version"`
+	Timestamp time.Time                        `json:"timestamp"`
+	Checks    map[string]DependencyCheckResult `json:"checks"`
+}
+
+// dependencyCheck is a named check run by /health/detail
+type dependencyCheck struct {
+	name string
+	run  func(ctx context.Context) DependencyCheckResult
+}
+
+// detailForCaller authenticates callers sending a bearer token, so that
+// detailCheck shows them the messages, latencies and versions of the checks
+func (h *HealthHandler) detailForCaller() http.HandlerFunc {
+	if h.tokenManager == nil {
+		return h.detailCheck
+	}
+	authenticated := middleware.RequireAuth(h.tokenManager, h.denylist)(http.HandlerFunc(h.detailCheck))
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") == "" {
+			h.detailCheck(w, r)
+			return
+		}
+		authenticated.ServeHTTP(w, r)
+	}
+}
+
+// detailCheck godoc
+// @Summary Detailed health check
+// @Description Reports the status of each dependency: database, migrations and, when enabled, SMTP and Sentry. The service is degraded when any check is not ok and down when every check is down. Only authenticated callers get the message, latency and version of each check.
+// @Tags Health
+// @Produce json
+// @Param Authorization header string false "Bearer access token, to include check details"
+// @Success 200 {object} HealthDetailResponse "Service is ok or degraded"
+// @Failure 401 {string} string "Unauthorized - invalid token"
+// @Failure 503 {object} HealthDetailResponse "Every dependency is down"
+// @Router /health/detail [get]
+func (h *HealthHandler) detailCheck(w http.ResponseWriter, r *http.Request) {
+	_, err := middleware.GetUserID(r.Context())
+	verbose := err == nil
+
+	checks := make(map[string]DependencyCheckResult, len(h.checks))
+	down := 0
+	status := DependencyStatusOK
+	for _, check := range h.checks {
+		ctx, cancel := context.WithTimeout(r.Context(), dependencyCheckTimeout)
+		result := check.run(ctx)
+		cancel()
+
+		checks[check.name] = result
+		if result.Status != DependencyStatusOK {
+			h.logger.Warn("Dependency check not ok", zap.String("check", check.name), zap.String("status", result.Status), zap.String("message", result.Message))
+			status = DependencyStatusDegraded
+		}
+		if result.Status == DependencyStatusDown {
+			down++
+		}
+		if !verbose {
+			checks[check.name] = DependencyCheckResult{Status: result.Status}
+		}
+	}
+	if len(h.checks) > 0 && down == len(h.checks) {
+		status = DependencyStatusDown
+	}
+
+	response := HealthDetailResponse{
+		Status:    status,
+		Version:   h.version,
+		Timestamp: time.Now(),
+		Checks:    checks,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if status == DependencyStatusDown {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+
+	_ = json.NewEncoder(w).Encode(response)
+}
+
+// checkDatabase pings the database and reports the round trip latency
+func (h *HealthHandler) checkDatabase(ctx context.Context) DependencyCheckResult {
+	if h.db == nil {
+		return DependencyCheckResult{Status: DependencyStatusDown, Message: "database not configured"}
+	}
+
+	start := time.Now()
+	err := h.db.PingContext(ctx)
+	latency := time.Since(start)
+	result := DependencyCheckResult{Status: DependencyStatusOK, LatencyMs: milliseconds(latency)}
+	switch {
+	case err != nil:
+		result.Status = DependencyStatusDown
+		result.Message = err.Error()
+	case latency > slowDatabaseLatency:
+		result.Status = DependencyStatusDegraded
+		result.Message = "slow database response"
+	}
+	return result
+}
+
+// checkMigrations reports the current schema version
+func (h *HealthHandler) checkMigrations(ctx context.Context) DependencyCheckResult {
+	if h.db == nil {
+		return DependencyCheckResult{Status: DependencyStatusDown, Message: "database not configured"}
+	}
+
+	version, err := core.CurrentMigrationVersion(ctx, h.db)
+	if err != nil {
+		return DependencyCheckResult{Status: DependencyStatusDown, Message: err.Error()}
+	}
+	if version == 0 {
+		return DependencyCheckResult{Status: DependencyStatusDegraded, Message: "no migrations applied"}
+	}
+	return DependencyCheckResult{Status: DependencyStatusOK, Version: version}
+}
+
+// reachabilityCheck returns a check opening a TCP connection to address
+func reachabilityCheck(address string) func(ctx context.Context) DependencyCheckResult {
+	return func(ctx context.Context) DependencyCheckResult {
+		start := time.Now()
+		var dialer net.Dialer
+		conn, err := dialer.DialContext(ctx, "tcp", address)
+		if err != nil {
+			return DependencyCheckResult{Status: DependencyStatusDown, Message: err.Error()}
+		}
+		conn.Close()
+		return DependencyCheckResult{Status: DependencyStatusOK, LatencyMs: milliseconds(time.Since(start))}
+	}
+}
+
+// sentryCheck returns a check reaching the host of a Sentry DSN
+func sentryCheck(dsn string) func(ctx context.Context) DependencyCheckResult {
+	u, err := url.Parse(dsn)
+	if err != nil || u.Hostname() == "" {
+		return func(context.Context) DependencyCheckResult {
+			return DependencyCheckResult{Status: DependencyStatusDown, Message: "invalid Sentry DSN"}
+		}
+	}
+
+	port := u.Port()
+	if port == "" {
+		port = "443"
+		if u.Scheme == "http" {
+			port = "80"
+		}
+	}
+	return reachabilityCheck(net.JoinHostPort(u.Hostname(), port))
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
@@ -4,9 +4,11 @@ import (
 	"database/sql"
 	"encoding/json"
 	"errors"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/go-chi/chi/v5"
@@ -14,15 +16,22 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/pmaojo/kthulu-go/backend/core"
+	"github.com/pmaojo/kthulu-go/backend/internal/domain/repository"
 )
 
 func newHealthHandler(db *sql.DB) *HealthHandler {
+	return newHealthHandlerWithConfig(db, &core.Config{Version: "test"})
+}
+
+func newHealthHandlerWithConfig(db *sql.DB, cfg *core.Config) *HealthHandler {
 	return NewHealthHandler(struct {
 		fx.In
-		DB     *sql.DB
-		Logger *zap.Logger
-		Config *core.Config
-	}{DB: db, Logger: zap.NewNop(), Config: &core.Config{Version: "test"}})
+		DB           *sql.DB
+		Logger       *zap.Logger
+		Config       *core.Config
+		TokenManager core.TokenManager              `optional:"true"`
+		Denylist     repository.AccessTokenDenylist `optional:"true"`
+	}{DB: db, Logger: zap.NewNop(), Config: cfg, TokenManager: testTokenManager})
 }
 
 func TestHealthHandler_HealthCheck(t *testing.T) {
@@ -115,3 +124,89 @@ func TestHealthHandler_ReadinessCheck(t *testing.T) {
 		t.Fatalf("unmet expectations: %v", err)
 	}
 }
+
+func TestHealthHandler_DetailCheckDegradedWhenDatabaseDown(t *testing.T) {
+	// A listener stands in for a reachable SMTP server
+	smtp, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer smtp.Close()
+	cfg := &core.Config{Version: "test", SMTP: core.SMTPConfig{Enabled: true, Host: "127.0.0.1", Port: smtp.Addr().(*net.TCPAddr).Port}}
+
+	detail := func(t *testing.T, authenticate func(http.Handler) http.Handler) HealthDetailResponse {
+		t.Helper()
+		db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+		if err != nil {
+			t.Fatalf("failed to create sqlmock: %v", err)
+		}
+		defer db.Close()
+		mock.ExpectPing().WillReturnError(errors.New("db down"))
+		mock.ExpectQuery("SELECT to_regclass").WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+		mock.ExpectQuery("SELECT version_id, is_applied, tstamp FROM goose_db_version").
+			WillReturnRows(sqlmock.NewRows([]string{"version_id", "is_applied", "tstamp"}).
+				AddRow(38, true, time.Now()).
+				AddRow(39, true, time.Now()))
+
+		router := chi.NewRouter()
+		router.Use(authenticate)
+		newHealthHandlerWithConfig(db, cfg).RegisterRoutes(router)
+
+		req := httptest.NewRequest(http.MethodGet, "/health/detail", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200 for a degraded service, got %d", w.Code)
+		}
+		var resp HealthDetailResponse
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if resp.Status != DependencyStatusDegraded {
+			t.Fatalf("expected degraded status, got %+v", resp)
+		}
+		if resp.Checks["database"].Status != DependencyStatusDown || resp.Checks["migrations"].Status != DependencyStatusOK || resp.Checks["smtp"].Status != DependencyStatusOK {
+			t.Fatalf("unexpected check statuses %+v", resp.Checks)
+		}
+		if _, ok := resp.Checks["sentry"]; ok {
+			t.Fatalf("sentry should not be checked when disabled")
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Fatalf("unmet expectations: %v", err)
+		}
+		return resp
+	}
+
+	t.Run("anonymous callers only get statuses", func(t *testing.T) {
+		resp := detail(t, func(next http.Handler) http.Handler { return next })
+		for name, check := range resp.Checks {
+			if check != (DependencyCheckResult{Status: check.Status}) {
+				t.Fatalf("%s check exposes details to an anonymous caller: %+v", name, check)
+			}
+		}
+	})
+
+	t.Run("authenticated callers get details", func(t *testing.T) {
+		resp := detail(t, authenticateAsMember)
+		if check := resp.Checks["database"]; check.Message != "db down" {
+			t.Fatalf("unexpected database check %+v", check)
+		}
+		if check := resp.Checks["migrations"]; check.Version != 39 {
+			t.Fatalf("unexpected migrations check %+v", check)
+		}
+	})
+}
+
+func TestHealthHandler_DetailCheckRejectsInvalidTokens(t *testing.T) {
+	router := chi.NewRouter()
+	newHealthHandler(nil).RegisterRoutes(router)
+
+	req := httptest.NewRequest(http.MethodGet, "/health/detail", nil)
+	req.Header.Set("Authorization", "Bearer not-a-token")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401, got %d", w.Code)
+	}
+}
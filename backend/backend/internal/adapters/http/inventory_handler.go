@@ -3,8 +3,10 @@ package adapterhttp
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"go.uber.org/zap"
@@ -53,6 +55,9 @@ func (h *InventoryHandler) RegisterRoutes(r chi.Router) {
 			r.Get("/{inventoryItemId}", h.GetStockMovements)
 		})
 
+		// Inventory ledger routes
+		r.Get("/ledger/{productId}", h.ListInventoryMovements)
+
 		// Reporting routes
 		r.Get("/low-stock", h.GetLowStockItems)
 	})
@@ -132,15 +137,7 @@ func (h *InventoryHandler) GetWarehouse(w http.ResponseWriter, r *http.Request)
 // @Failure 500 {object} map[string]string "Internal server error"
 // @Router /api/inventory/warehouses [get]
 func (h *InventoryHandler) ListWarehouses(w http.ResponseWriter, r *http.Request) {
-	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
-	if page < 1 {
-		page = 1
-	}
-
-	pageSize, _ := strconv.Atoi(r.URL.Query().Get("pageSize"))
-	if pageSize < 1 || pageSize > 100 {
-		pageSize = 10
-	}
+	page, pageSize := parsePagination(r, 10)
 
 	search := r.URL.Query().Get("search")
 
@@ -157,6 +154,7 @@ func (h *InventoryHandler) ListWarehouses(w http.ResponseWriter, r *http.Request
 		return
 	}
 
+	setPaginationHeaders(w, r, response.Page, response.PageSize, int64(response.Total))
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(response)
 }
@@ -262,15 +260,7 @@ func (h *InventoryHandler) ListInventoryItems(w http.ResponseWriter, r *http.Req
 		}
 	}
 
-	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
-	if page < 1 {
-		page = 1
-	}
-
-	pageSize, _ := strconv.Atoi(r.URL.Query().Get("pageSize"))
-	if pageSize < 1 || pageSize > 100 {
-		pageSize = 10
-	}
+	page, pageSize := parsePagination(r, 10)
 
 	req := usecase.ListInventoryItemsRequest{
 		WarehouseID: warehouseID,
@@ -285,6 +275,7 @@ func (h *InventoryHandler) ListInventoryItems(w http.ResponseWriter, r *http.Req
 		return
 	}
 
+	setPaginationHeaders(w, r, response.Page, response.PageSize, int64(response.Total))
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(response)
 }
@@ -311,6 +302,10 @@ func (h *InventoryHandler) UpdateStock(w http.ResponseWriter, r *http.Request) {
 	req.OrganizationID = h.getOrganizationID(r)
 
 	item, err := h.inventoryUC.UpdateStock(r.Context(), req)
+	if errors.Is(err, domain.ErrInsufficientStock) {
+		http.Error(w, "Insufficient stock", http.StatusConflict)
+		return
+	}
 	if err != nil {
 		h.logger.Error("Failed to update stock", zap.Error(err))
 		http.Error(w, "Failed to update stock", http.StatusInternalServerError)
@@ -340,15 +335,7 @@ func (h *InventoryHandler) GetStockMovements(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
-	if page < 1 {
-		page = 1
-	}
-
-	pageSize, _ := strconv.Atoi(r.URL.Query().Get("pageSize"))
-	if pageSize < 1 || pageSize > 100 {
-		pageSize = 10
-	}
+	page, pageSize := parsePagination(r, 10)
 
 	req := usecase.GetStockMovementsRequest{
 		InventoryItemID: uint(inventoryItemID),
@@ -363,6 +350,76 @@ func (h *InventoryHandler) GetStockMovements(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
+	setPaginationHeaders(w, r, response.Page, response.PageSize, int64(response.Total))
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// ListInventoryMovements godoc
+// @Summary List inventory movements
+// @Description Retrieves the inventory ledger of a product, oldest movement first
+// @Tags Inventory
+// @Produce json
+// @Param productId path int true "Product ID"
+// @Param warehouseId query int false "Filter by warehouse ID"
+// @Param from query string false "First day to include (YYYY-MM-DD)"
+// @Param to query string false "Last day to include (YYYY-MM-DD)"
+// @Param page query int false "Page number" default(1)
+// @Param pageSize query int false "Page size" default(10)
+// @Success 200 {object} usecase.PaginatedResponse[domain.InventoryMovement] "Inventory ledger"
+// @Failure 400 {object} map[string]string "Invalid request"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/inventory/ledger/{productId} [get]
+func (h *InventoryHandler) ListInventoryMovements(w http.ResponseWriter, r *http.Request) {
+	productID, err := strconv.ParseUint(chi.URLParam(r, "productId"), 10, 32)
+	if err != nil {
+		http.Error(w, "Invalid product ID", http.StatusBadRequest)
+		return
+	}
+
+	page, pageSize := parsePagination(r, 10)
+
+	req := usecase.ListInventoryMovementsRequest{
+		ProductID: uint(productID),
+		Page:      page,
+		PageSize:  pageSize,
+	}
+	if warehouseIDStr := r.URL.Query().Get("warehouseId"); warehouseIDStr != "" {
+		id, err := strconv.ParseUint(warehouseIDStr, 10, 32)
+		if err != nil {
+			http.Error(w, "Invalid warehouse ID", http.StatusBadRequest)
+			return
+		}
+		wid := uint(id)
+		req.WarehouseID = &wid
+	}
+	if from := r.URL.Query().Get("from"); from != "" {
+		day, err := time.Parse("2006-01-02", from)
+		if err != nil {
+			http.Error(w, "Invalid from date, expected YYYY-MM-DD", http.StatusBadRequest)
+			return
+		}
+		req.From = &day
+	}
+	if to := r.URL.Query().Get("to"); to != "" {
+		day, err := time.Parse("2006-01-02", to)
+		if err != nil {
+			http.Error(w, "Invalid to date, expected YYYY-MM-DD", http.StatusBadRequest)
+			return
+		}
+		// The whole last day is included
+		end := day.AddDate(0, 0, 1)
+		req.To = &end
+	}
+
+	response, err := h.inventoryUC.ListInventoryMovements(r.Context(), req)
+	if err != nil {
+		h.logger.Error("Failed to list inventory movements", zap.Error(err))
+		http.Error(w, "Failed to list inventory movements", http.StatusInternalServerError)
+		return
+	}
+
+	setPaginationHeaders(w, r, response.Page, response.PageSize, int64(response.Total))
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(response)
 }
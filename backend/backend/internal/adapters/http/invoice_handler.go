@@ -2,14 +2,21 @@
 package adapterhttp
 
 import (
+	"encoding/csv"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-playground/validator/v10"
+	"go.uber.org/fx"
 	"go.uber.org/zap"
 
+	"github.com/pmaojo/kthulu-go/backend/core"
+	"github.com/pmaojo/kthulu-go/backend/internal/adapters/http/middleware"
 	"github.com/pmaojo/kthulu-go/backend/internal/domain"
 	"github.com/pmaojo/kthulu-go/backend/internal/domain/repository"
 	"github.com/pmaojo/kthulu-go/backend/internal/usecase"
@@ -18,34 +25,58 @@ import (
 // InvoiceHandler handles HTTP requests for invoice operations
 type InvoiceHandler struct {
 	invoiceUseCase *usecase.InvoiceUseCase
+	orgUsers       repository.OrganizationUserRepository
+	tokenManager   core.TokenManager
+	denylist       repository.AccessTokenDenylist
 	validator      *validator.Validate
 	logger         *zap.Logger
 }
 
 // NewInvoiceHandler creates a new invoice handler
-func NewInvoiceHandler(invoiceUseCase *usecase.InvoiceUseCase, logger *zap.Logger) *InvoiceHandler {
+func NewInvoiceHandler(p struct {
+	fx.In
+	InvoiceUseCase *usecase.InvoiceUseCase
+	OrgUsers       repository.OrganizationUserRepository
+	TokenManager   core.TokenManager
+	Denylist       repository.AccessTokenDenylist `optional:"true"`
+	Logger         *zap.Logger
+}) *InvoiceHandler {
 	return &InvoiceHandler{
-		invoiceUseCase: invoiceUseCase,
+		invoiceUseCase: p.InvoiceUseCase,
+		orgUsers:       p.OrgUsers,
+		tokenManager:   p.TokenManager,
+		denylist:       p.Denylist,
 		validator:      validator.New(),
-		logger:         logger,
+		logger:         p.Logger,
 	}
 }
 
-// RegisterRoutes registers invoice routes
+// RegisterRoutes registers invoice and payment routes. They are served to
+// members of the organization named by the X-Organization-ID header.
 func (h *InvoiceHandler) RegisterRoutes(r chi.Router) {
 	r.Route("/invoices", func(r chi.Router) {
+		r.Use(middleware.RequireAuth(h.tokenManager, h.denylist))
+		r.Use(middleware.OrganizationMiddleware(h.orgUsers))
+
 		r.Post("/", h.CreateInvoice)
 		r.Get("/", h.ListInvoices)
 		r.Get("/stats", h.GetInvoiceStats)
+		r.Get("/revenue/timeseries", h.GetRevenueTimeSeries)
 		r.Get("/overdue", h.GetOverdueInvoices)
+		r.Get("/export", h.ExportInvoices)
+		r.Post("/bulk/status", h.BulkSetInvoiceStatus)
 		r.Get("/{invoiceId}", h.GetInvoice)
 		r.Put("/{invoiceId}", h.UpdateInvoice)
+		r.Patch("/{invoiceId}", h.PatchInvoice)
 		r.Delete("/{invoiceId}", h.DeleteInvoice)
 		r.Patch("/{invoiceId}/status", h.SetInvoiceStatus)
+		r.Post("/{invoiceId}/send", h.SendInvoice)
+		r.Get("/{invoiceId}/events", h.ListInvoiceEvents)
 
 		// Invoice item routes
 		r.Post("/{invoiceId}/items", h.CreateInvoiceItem)
 		r.Get("/{invoiceId}/items", h.GetInvoiceItems)
+		r.Put("/{invoiceId}/items/order", h.ReorderInvoiceItems)
 		r.Put("/{invoiceId}/items/{itemId}", h.UpdateInvoiceItem)
 		r.Delete("/{invoiceId}/items/{itemId}", h.DeleteInvoiceItem)
 
@@ -55,6 +86,9 @@ func (h *InvoiceHandler) RegisterRoutes(r chi.Router) {
 	})
 
 	r.Route("/payments", func(r chi.Router) {
+		r.Use(middleware.RequireAuth(h.tokenManager, h.denylist))
+		r.Use(middleware.OrganizationMiddleware(h.orgUsers))
+
 		r.Get("/", h.ListPayments)
 		r.Get("/{paymentId}", h.GetPayment)
 		r.Put("/{paymentId}", h.UpdatePayment)
@@ -79,13 +113,13 @@ func (h *InvoiceHandler) RegisterRoutes(r chi.Router) {
 func (h *InvoiceHandler) CreateInvoice(w http.ResponseWriter, r *http.Request) {
 	organizationID := h.getOrganizationID(r)
 	if organizationID == 0 {
-		h.writeError(w, http.StatusBadRequest, "missing organization ID", nil)
+		h.writeError(w, r, http.StatusBadRequest, "missing organization ID", nil)
 		return
 	}
 
 	var req usecase.CreateInvoiceRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		h.writeError(w, http.StatusBadRequest, "invalid request body", err)
+		h.writeError(w, r, http.StatusBadRequest, "invalid request body", err)
 		return
 	}
 
@@ -93,18 +127,22 @@ func (h *InvoiceHandler) CreateInvoice(w http.ResponseWriter, r *http.Request) {
 	req.OrganizationID = organizationID
 
 	if err := h.validator.Struct(req); err != nil {
-		h.writeError(w, http.StatusBadRequest, "validation failed", err)
+		h.writeError(w, r, http.StatusBadRequest, "validation failed", err)
 		return
 	}
 
 	invoice, err := h.invoiceUseCase.CreateInvoice(r.Context(), req)
 	if err != nil {
-		switch err {
-		case domain.ErrInvoiceAlreadyExists:
-			h.writeError(w, http.StatusConflict, "invoice already exists", err)
+		switch {
+		case errors.Is(err, domain.ErrInvoiceAlreadyExists):
+			h.writeError(w, r, http.StatusConflict, "invoice already exists", err)
+		case errors.Is(err, domain.ErrInvalidLineItem):
+			h.writeError(w, r, http.StatusBadRequest, "invalid invoice item", err)
+		case errors.Is(err, domain.ErrExchangeRateUnavailable):
+			h.writeError(w, r, http.StatusServiceUnavailable, "exchange rate unavailable, pass exchangeRate to set it", err)
 		default:
 			h.logger.Error("Failed to create invoice", zap.Error(err))
-			h.writeError(w, http.StatusInternalServerError, "failed to create invoice", err)
+			h.writeError(w, r, http.StatusInternalServerError, "failed to create invoice", err)
 		}
 		return
 	}
@@ -119,7 +157,9 @@ func (h *InvoiceHandler) CreateInvoice(w http.ResponseWriter, r *http.Request) {
 // @Produce json
 // @Param organizationId header string true "Organization ID"
 // @Param invoiceId path string true "Invoice ID"
+// @Param If-None-Match header string false "ETag from a previous response"
 // @Success 200 {object} domain.Invoice
+// @Success 304 "Not modified"
 // @Failure 400 {object} ErrorResponse
 // @Failure 404 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
@@ -128,13 +168,13 @@ func (h *InvoiceHandler) CreateInvoice(w http.ResponseWriter, r *http.Request) {
 func (h *InvoiceHandler) GetInvoice(w http.ResponseWriter, r *http.Request) {
 	organizationID := h.getOrganizationID(r)
 	if organizationID == 0 {
-		h.writeError(w, http.StatusBadRequest, "missing organization ID", nil)
+		h.writeError(w, r, http.StatusBadRequest, "missing organization ID", nil)
 		return
 	}
 
 	invoiceID, err := h.getUintParam(r, "invoiceId")
 	if err != nil {
-		h.writeError(w, http.StatusBadRequest, "invalid invoice ID", err)
+		h.writeError(w, r, http.StatusBadRequest, "invalid invoice ID", err)
 		return
 	}
 
@@ -142,17 +182,61 @@ func (h *InvoiceHandler) GetInvoice(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		switch err {
 		case domain.ErrInvoiceNotFound:
-			h.writeError(w, http.StatusNotFound, "invoice not found", err)
+			h.writeError(w, r, http.StatusNotFound, "invoice not found", err)
 		default:
 			h.logger.Error("Failed to get invoice", zap.Error(err))
-			h.writeError(w, http.StatusInternalServerError, "failed to get invoice", err)
+			h.writeError(w, r, http.StatusInternalServerError, "failed to get invoice", err)
 		}
 		return
 	}
 
+	if checkNotModified(w, r, resourceETag(invoice.ID, invoice.UpdatedAt)) {
+		return
+	}
 	h.writeJSON(w, http.StatusOK, invoice)
 }
 
+// ListInvoiceEvents lists the calendar events linked to an invoice
+// @Summary List invoice events
+// @Description List the calendar events, such as payment follow-ups, linked to an invoice
+// @Tags invoices
+// @Produce json
+// @Param organizationId header string true "Organization ID"
+// @Param invoiceId path string true "Invoice ID"
+// @Success 200 {array} domain.Event
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /invoices/{invoiceId}/events [get]
+func (h *InvoiceHandler) ListInvoiceEvents(w http.ResponseWriter, r *http.Request) {
+	organizationID := h.getOrganizationID(r)
+	if organizationID == 0 {
+		h.writeError(w, r, http.StatusBadRequest, "missing organization ID", nil)
+		return
+	}
+
+	invoiceID, err := h.getUintParam(r, "invoiceId")
+	if err != nil {
+		h.writeError(w, r, http.StatusBadRequest, "invalid invoice ID", err)
+		return
+	}
+
+	events, err := h.invoiceUseCase.ListInvoiceEvents(r.Context(), organizationID, invoiceID)
+	if err != nil {
+		switch err {
+		case domain.ErrInvoiceNotFound:
+			h.writeError(w, r, http.StatusNotFound, "invoice not found", err)
+		default:
+			h.logger.Error("Failed to list invoice events", zap.Error(err))
+			h.writeError(w, r, http.StatusInternalServerError, "failed to list invoice events", err)
+		}
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, events)
+}
+
 // UpdateInvoice updates an existing invoice
 // @Summary Update an invoice
 // @Description Update an existing invoice's information
@@ -171,24 +255,24 @@ func (h *InvoiceHandler) GetInvoice(w http.ResponseWriter, r *http.Request) {
 func (h *InvoiceHandler) UpdateInvoice(w http.ResponseWriter, r *http.Request) {
 	organizationID := h.getOrganizationID(r)
 	if organizationID == 0 {
-		h.writeError(w, http.StatusBadRequest, "missing organization ID", nil)
+		h.writeError(w, r, http.StatusBadRequest, "missing organization ID", nil)
 		return
 	}
 
 	invoiceID, err := h.getUintParam(r, "invoiceId")
 	if err != nil {
-		h.writeError(w, http.StatusBadRequest, "invalid invoice ID", err)
+		h.writeError(w, r, http.StatusBadRequest, "invalid invoice ID", err)
 		return
 	}
 
 	var req usecase.UpdateInvoiceRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		h.writeError(w, http.StatusBadRequest, "invalid request body", err)
+		h.writeError(w, r, http.StatusBadRequest, "invalid request body", err)
 		return
 	}
 
 	if err := h.validator.Struct(req); err != nil {
-		h.writeError(w, http.StatusBadRequest, "validation failed", err)
+		h.writeError(w, r, http.StatusBadRequest, "validation failed", err)
 		return
 	}
 
@@ -196,12 +280,70 @@ func (h *InvoiceHandler) UpdateInvoice(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		switch err {
 		case domain.ErrInvoiceNotFound:
-			h.writeError(w, http.StatusNotFound, "invoice not found", err)
+			h.writeError(w, r, http.StatusNotFound, "invoice not found", err)
 		case domain.ErrInvoiceNotEditable:
-			h.writeError(w, http.StatusBadRequest, "invoice is not editable", err)
+			h.writeError(w, r, http.StatusBadRequest, "invoice is not editable", err)
 		default:
 			h.logger.Error("Failed to update invoice", zap.Error(err))
-			h.writeError(w, http.StatusInternalServerError, "failed to update invoice", err)
+			h.writeError(w, r, http.StatusInternalServerError, "failed to update invoice", err)
+		}
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, invoice)
+}
+
+// PatchInvoice updates some fields of an invoice
+// @Summary Patch an invoice
+// @Description Update only the fields present in the body, leaving the others unchanged
+// @Tags invoices
+// @Accept json
+// @Produce json
+// @Param organizationId header string true "Organization ID"
+// @Param invoiceId path string true "Invoice ID"
+// @Param invoice body usecase.PatchInvoiceRequest true "Fields to update"
+// @Success 200 {object} domain.Invoice
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /invoices/{invoiceId} [patch]
+func (h *InvoiceHandler) PatchInvoice(w http.ResponseWriter, r *http.Request) {
+	organizationID := h.getOrganizationID(r)
+	if organizationID == 0 {
+		h.writeError(w, r, http.StatusBadRequest, "missing organization ID", nil)
+		return
+	}
+
+	invoiceID, err := h.getUintParam(r, "invoiceId")
+	if err != nil {
+		h.writeError(w, r, http.StatusBadRequest, "invalid invoice ID", err)
+		return
+	}
+
+	var req usecase.PatchInvoiceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeError(w, r, http.StatusBadRequest, "invalid request body", err)
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		h.writeError(w, r, http.StatusBadRequest, "validation failed", err)
+		return
+	}
+
+	invoice, err := h.invoiceUseCase.PatchInvoice(r.Context(), organizationID, invoiceID, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrInvoiceNotFound):
+			h.writeError(w, r, http.StatusNotFound, "invoice not found", err)
+		case errors.Is(err, domain.ErrInvoiceNotEditable):
+			h.writeError(w, r, http.StatusBadRequest, "invoice is not editable", err)
+		case errors.Is(err, domain.ErrInvalidInvoiceField):
+			h.writeError(w, r, http.StatusBadRequest, "invalid invoice field", err)
+		default:
+			h.logger.Error("Failed to patch invoice", zap.Error(err))
+			h.writeError(w, r, http.StatusInternalServerError, "failed to patch invoice", err)
 		}
 		return
 	}
@@ -224,13 +366,13 @@ func (h *InvoiceHandler) UpdateInvoice(w http.ResponseWriter, r *http.Request) {
 func (h *InvoiceHandler) DeleteInvoice(w http.ResponseWriter, r *http.Request) {
 	organizationID := h.getOrganizationID(r)
 	if organizationID == 0 {
-		h.writeError(w, http.StatusBadRequest, "missing organization ID", nil)
+		h.writeError(w, r, http.StatusBadRequest, "missing organization ID", nil)
 		return
 	}
 
 	invoiceID, err := h.getUintParam(r, "invoiceId")
 	if err != nil {
-		h.writeError(w, http.StatusBadRequest, "invalid invoice ID", err)
+		h.writeError(w, r, http.StatusBadRequest, "invalid invoice ID", err)
 		return
 	}
 
@@ -238,10 +380,10 @@ func (h *InvoiceHandler) DeleteInvoice(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		switch err {
 		case domain.ErrInvoiceNotFound:
-			h.writeError(w, http.StatusNotFound, "invoice not found", err)
+			h.writeError(w, r, http.StatusNotFound, "invoice not found", err)
 		default:
 			h.logger.Error("Failed to delete invoice", zap.Error(err))
-			h.writeError(w, http.StatusInternalServerError, "failed to delete invoice", err)
+			h.writeError(w, r, http.StatusInternalServerError, "failed to delete invoice", err)
 		}
 		return
 	}
@@ -262,10 +404,12 @@ func (h *InvoiceHandler) DeleteInvoice(w http.ResponseWriter, r *http.Request) {
 // @Param status query string false "Filter by invoice status"
 // @Param currency query string false "Filter by currency"
 // @Param search query string false "Search in invoice number"
+// @Param paymentState query string false "Filter by payment state (unpaid, partial, paid)"
 // @Param sortBy query string false "Sort by field"
 // @Param sortOrder query string false "Sort order (asc, desc)"
 // @Param includeItems query bool false "Include invoice items"
 // @Param includePayments query bool false "Include invoice payments"
+// @Param fields query string false "Comma-separated invoice fields to return"
 // @Success 200 {object} usecase.InvoiceListResponse
 // @Failure 400 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
@@ -274,20 +418,127 @@ func (h *InvoiceHandler) DeleteInvoice(w http.ResponseWriter, r *http.Request) {
 func (h *InvoiceHandler) ListInvoices(w http.ResponseWriter, r *http.Request) {
 	organizationID := h.getOrganizationID(r)
 	if organizationID == 0 {
-		h.writeError(w, http.StatusBadRequest, "missing organization ID", nil)
+		h.writeError(w, r, http.StatusBadRequest, "missing organization ID", nil)
 		return
 	}
 
 	filters := h.parseInvoiceFilters(r)
+	fields, err := parseFields(r, domain.Invoice{})
+	if err != nil {
+		h.writeError(w, r, http.StatusBadRequest, "invalid fields", err)
+		return
+	}
 
 	response, err := h.invoiceUseCase.ListInvoices(r.Context(), organizationID, filters)
 	if err != nil {
+		if errors.Is(err, domain.ErrInvalidInvoiceFilters) {
+			h.writeError(w, r, http.StatusBadRequest, "invalid filters", err)
+			return
+		}
 		h.logger.Error("Failed to list invoices", zap.Error(err))
-		h.writeError(w, http.StatusInternalServerError, "failed to list invoices", err)
+		h.writeError(w, r, http.StatusInternalServerError, "failed to list invoices", err)
 		return
 	}
 
-	h.writeJSON(w, http.StatusOK, response)
+	setPaginationHeaders(w, r, response.Page, response.PageSize, response.Total)
+	if fields == nil {
+		h.writeJSON(w, http.StatusOK, response)
+		return
+	}
+	sparse, err := sparseFieldset(response, "data", fields)
+	if err != nil {
+		h.logger.Error("Failed to select invoice fields", zap.Error(err))
+		h.writeError(w, r, http.StatusInternalServerError, "failed to list invoices", err)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, sparse)
+}
+
+// invoiceExportColumns is the header row of invoice exports
+var invoiceExportColumns = []string{
+	"invoiceNumber", "type", "status", "contactId", "currency", "issueDate", "dueDate",
+	"subtotal", "taxAmount", "discountAmount", "totalAmount", "paidAmount", "balanceDue",
+}
+
+// ExportInvoices exports the invoices matching the list filters
+// @Summary Export invoices
+// @Description Stream the invoices matching the list filters as CSV
+// @Tags invoices
+// @Produce text/csv
+// @Param organizationId header string true "Organization ID"
+// @Param format query string false "Export format (csv)"
+// @Param contactId query int false "Filter by contact ID"
+// @Param type query string false "Filter by invoice type"
+// @Param status query string false "Filter by invoice status"
+// @Param currency query string false "Filter by currency"
+// @Param search query string false "Search in invoice number"
+// @Param paymentState query string false "Filter by payment state (unpaid, partial, paid)"
+// @Param sortBy query string false "Sort by field"
+// @Param sortOrder query string false "Sort order (asc, desc)"
+// @Success 200 {file} file
+// @Failure 400 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /invoices/export [get]
+func (h *InvoiceHandler) ExportInvoices(w http.ResponseWriter, r *http.Request) {
+	organizationID := h.getOrganizationID(r)
+	if organizationID == 0 {
+		h.writeError(w, r, http.StatusBadRequest, "missing organization ID", nil)
+		return
+	}
+
+	if format := r.URL.Query().Get("format"); format != "" && format != "csv" {
+		h.writeError(w, r, http.StatusBadRequest, "unsupported export format", fmt.Errorf("format %q is not supported", format))
+		return
+	}
+
+	filters := h.parseInvoiceFilters(r)
+	// Rejected before the status line is out
+	if err := filters.Validate(); err != nil {
+		h.writeError(w, r, http.StatusBadRequest, "invalid filters", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="invoices.csv"`)
+	w.WriteHeader(http.StatusOK)
+
+	// Rows are written as they are fetched; once the status line is out an
+	// error can only cut the export short.
+	cw := csv.NewWriter(w)
+	cw.Write(invoiceExportColumns)
+	err := h.invoiceUseCase.ExportInvoices(r.Context(), organizationID, filters, func(invoice *domain.Invoice) error {
+		return cw.Write(invoiceExportRow(invoice))
+	})
+	cw.Flush()
+	if err == nil {
+		err = cw.Error()
+	}
+	if err != nil {
+		h.logger.Error("Failed to export invoices", zap.Error(err))
+	}
+}
+
+func invoiceExportRow(invoice *domain.Invoice) []string {
+	dueDate := ""
+	if invoice.DueDate != nil {
+		dueDate = invoice.DueDate.Format("2006-01-02")
+	}
+	amount := func(v float64) string { return strconv.FormatFloat(v, 'f', 2, 64) }
+	return []string{
+		invoice.InvoiceNumber,
+		string(invoice.Type),
+		string(invoice.Status),
+		strconv.FormatUint(uint64(invoice.ContactID), 10),
+		invoice.Currency,
+		invoice.IssueDate.Format("2006-01-02"),
+		dueDate,
+		amount(invoice.Subtotal),
+		amount(invoice.TaxAmount),
+		amount(invoice.DiscountAmount),
+		amount(invoice.TotalAmount),
+		amount(invoice.PaidAmount),
+		amount(invoice.BalanceDue),
+	}
 }
 
 // SetInvoiceStatus sets the status of an invoice
@@ -301,19 +552,20 @@ func (h *InvoiceHandler) ListInvoices(w http.ResponseWriter, r *http.Request) {
 // @Success 204
 // @Failure 400 {object} ErrorResponse
 // @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
 // @Security BearerAuth
 // @Router /invoices/{invoiceId}/status [patch]
 func (h *InvoiceHandler) SetInvoiceStatus(w http.ResponseWriter, r *http.Request) {
 	organizationID := h.getOrganizationID(r)
 	if organizationID == 0 {
-		h.writeError(w, http.StatusBadRequest, "missing organization ID", nil)
+		h.writeError(w, r, http.StatusBadRequest, "missing organization ID", nil)
 		return
 	}
 
 	invoiceID, err := h.getUintParam(r, "invoiceId")
 	if err != nil {
-		h.writeError(w, http.StatusBadRequest, "invalid invoice ID", err)
+		h.writeError(w, r, http.StatusBadRequest, "invalid invoice ID", err)
 		return
 	}
 
@@ -321,18 +573,22 @@ func (h *InvoiceHandler) SetInvoiceStatus(w http.ResponseWriter, r *http.Request
 		Status domain.InvoiceStatus `json:"status"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		h.writeError(w, http.StatusBadRequest, "invalid request body", err)
+		h.writeError(w, r, http.StatusBadRequest, "invalid request body", err)
 		return
 	}
 
 	err = h.invoiceUseCase.SetInvoiceStatus(r.Context(), organizationID, invoiceID, req.Status)
 	if err != nil {
-		switch err {
-		case domain.ErrInvoiceNotFound:
-			h.writeError(w, http.StatusNotFound, "invoice not found", err)
+		switch {
+		case errors.Is(err, domain.ErrInvoiceNotFound):
+			h.writeError(w, r, http.StatusNotFound, "invoice not found", err)
+		case errors.Is(err, domain.ErrInvalidInvoiceStatus):
+			h.writeError(w, r, http.StatusBadRequest, "invalid invoice status", err)
+		case errors.Is(err, domain.ErrIllegalStatusTransition):
+			h.writeError(w, r, http.StatusConflict, "illegal invoice status transition", err)
 		default:
 			h.logger.Error("Failed to set invoice status", zap.Error(err))
-			h.writeError(w, http.StatusInternalServerError, "failed to set invoice status", err)
+			h.writeError(w, r, http.StatusInternalServerError, "failed to set invoice status", err)
 		}
 		return
 	}
@@ -340,6 +596,96 @@ func (h *InvoiceHandler) SetInvoiceStatus(w http.ResponseWriter, r *http.Request
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// SendInvoice emails an invoice to its contact
+// @Summary Send invoice
+// @Description Render the invoice as a PDF and email it to its contact. Draft invoices are marked as sent.
+// @Tags invoices
+// @Param organizationId header string true "Organization ID"
+// @Param invoiceId path string true "Invoice ID"
+// @Success 204
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /invoices/{invoiceId}/send [post]
+func (h *InvoiceHandler) SendInvoice(w http.ResponseWriter, r *http.Request) {
+	organizationID := h.getOrganizationID(r)
+	if organizationID == 0 {
+		h.writeError(w, r, http.StatusBadRequest, "missing organization ID", nil)
+		return
+	}
+
+	invoiceID, err := h.getUintParam(r, "invoiceId")
+	if err != nil {
+		h.writeError(w, r, http.StatusBadRequest, "invalid invoice ID", err)
+		return
+	}
+
+	err = h.invoiceUseCase.SendInvoice(r.Context(), organizationID, invoiceID)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrInvoiceNotFound):
+			h.writeError(w, r, http.StatusNotFound, "invoice not found", err)
+		case errors.Is(err, domain.ErrInvoiceNoRecipient):
+			h.writeError(w, r, http.StatusBadRequest, "invoice contact has no email address", err)
+		case errors.Is(err, domain.ErrInvoiceNotSendable):
+			h.writeError(w, r, http.StatusConflict, "invoice cannot be sent", err)
+		default:
+			h.logger.Error("Failed to send invoice", zap.Error(err))
+			h.writeError(w, r, http.StatusInternalServerError, "failed to send invoice", err)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// BulkSetInvoiceStatus updates the status of several invoices
+// @Summary Bulk update invoice status
+// @Description Move several invoices to a status. Invoices that are missing or cannot make the transition are skipped.
+// @Tags invoices
+// @Accept json
+// @Produce json
+// @Param organizationId header string true "Organization ID"
+// @Param request body usecase.BulkStatusRequest true "Invoice IDs and target status"
+// @Success 200 {object} usecase.BulkStatusResult
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /invoices/bulk/status [post]
+func (h *InvoiceHandler) BulkSetInvoiceStatus(w http.ResponseWriter, r *http.Request) {
+	organizationID := h.getOrganizationID(r)
+	if organizationID == 0 {
+		h.writeError(w, r, http.StatusBadRequest, "missing organization ID", nil)
+		return
+	}
+
+	var req usecase.BulkStatusRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeError(w, r, http.StatusBadRequest, "invalid request body", err)
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		h.writeError(w, r, http.StatusBadRequest, "validation failed", err)
+		return
+	}
+
+	result, err := h.invoiceUseCase.BulkSetInvoiceStatus(r.Context(), organizationID, req)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidInvoiceStatus) {
+			h.writeError(w, r, http.StatusBadRequest, "invalid invoice status", err)
+			return
+		}
+		h.logger.Error("Failed to bulk set invoice status", zap.Error(err))
+		h.writeError(w, r, http.StatusInternalServerError, "failed to update invoice status", err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, result)
+}
+
 // GetInvoiceStats retrieves invoice statistics
 // @Summary Get invoice statistics
 // @Description Retrieve statistics for invoices in the organization
@@ -354,20 +700,89 @@ func (h *InvoiceHandler) SetInvoiceStatus(w http.ResponseWriter, r *http.Request
 func (h *InvoiceHandler) GetInvoiceStats(w http.ResponseWriter, r *http.Request) {
 	organizationID := h.getOrganizationID(r)
 	if organizationID == 0 {
-		h.writeError(w, http.StatusBadRequest, "missing organization ID", nil)
+		h.writeError(w, r, http.StatusBadRequest, "missing organization ID", nil)
 		return
 	}
 
 	stats, err := h.invoiceUseCase.GetInvoiceStats(r.Context(), organizationID)
 	if err != nil {
 		h.logger.Error("Failed to get invoice stats", zap.Error(err))
-		h.writeError(w, http.StatusInternalServerError, "failed to get invoice stats", err)
+		h.writeError(w, r, http.StatusInternalServerError, "failed to get invoice stats", err)
 		return
 	}
 
 	h.writeJSON(w, http.StatusOK, stats)
 }
 
+// GetRevenueTimeSeries retrieves revenue per day, week or month
+// @Summary Get revenue time series
+// @Description Get invoice totals per bucket between two dates (inclusive), with empty buckets zero-filled. Defaults to the last 12 months by month.
+// @Tags invoices
+// @Produce json
+// @Param organizationId header string true "Organization ID"
+// @Param from query string false "First day (YYYY-MM-DD)"
+// @Param to query string false "Last day (YYYY-MM-DD)"
+// @Param granularity query string false "Bucket size (day, week, month)"
+// @Success 200 {object} repository.RevenueTimeSeries
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /invoices/revenue/timeseries [get]
+func (h *InvoiceHandler) GetRevenueTimeSeries(w http.ResponseWriter, r *http.Request) {
+	organizationID := h.getOrganizationID(r)
+	if organizationID == 0 {
+		h.writeError(w, r, http.StatusBadRequest, "missing organization ID", nil)
+		return
+	}
+
+	from, to, err := parseRevenuePeriod(r, time.Now())
+	if err != nil {
+		h.writeError(w, r, http.StatusBadRequest, "invalid period", err)
+		return
+	}
+	granularity := repository.RevenueGranularityMonth
+	if value := r.URL.Query().Get("granularity"); value != "" {
+		granularity = repository.RevenueGranularity(value)
+	}
+
+	series, err := h.invoiceUseCase.GetRevenueTimeSeries(r.Context(), organizationID, from, to, granularity)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidRevenuePeriod) {
+			h.writeError(w, r, http.StatusBadRequest, "invalid period", err)
+			return
+		}
+		h.logger.Error("Failed to get revenue time series", zap.Error(err))
+		h.writeError(w, r, http.StatusInternalServerError, "failed to get revenue time series", err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, series)
+}
+
+// parseRevenuePeriod reads the inclusive from/to days of a revenue query as
+// the half-open range [from, to+1 day)
+func parseRevenuePeriod(r *http.Request, now time.Time) (time.Time, time.Time, error) {
+	to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC).AddDate(0, 0, 1)
+	if toStr := r.URL.Query().Get("to"); toStr != "" {
+		day, err := time.Parse("2006-01-02", toStr)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("to must be YYYY-MM-DD: %w", err)
+		}
+		to = day.AddDate(0, 0, 1)
+	}
+
+	from := repository.RevenueGranularityMonth.Truncate(to.AddDate(0, -12, 0))
+	if fromStr := r.URL.Query().Get("from"); fromStr != "" {
+		day, err := time.Parse("2006-01-02", fromStr)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("from must be YYYY-MM-DD: %w", err)
+		}
+		from = day
+	}
+
+	return from, to, nil
+}
+
 // GetOverdueInvoices retrieves overdue invoices
 // @Summary Get overdue invoices
 // @Description Retrieve all overdue invoices for the organization
@@ -382,14 +797,14 @@ func (h *InvoiceHandler) GetInvoiceStats(w http.ResponseWriter, r *http.Request)
 func (h *InvoiceHandler) GetOverdueInvoices(w http.ResponseWriter, r *http.Request) {
 	organizationID := h.getOrganizationID(r)
 	if organizationID == 0 {
-		h.writeError(w, http.StatusBadRequest, "missing organization ID", nil)
+		h.writeError(w, r, http.StatusBadRequest, "missing organization ID", nil)
 		return
 	}
 
 	invoices, err := h.invoiceUseCase.GetOverdueInvoices(r.Context(), organizationID)
 	if err != nil {
 		h.logger.Error("Failed to get overdue invoices", zap.Error(err))
-		h.writeError(w, http.StatusInternalServerError, "failed to get overdue invoices", err)
+		h.writeError(w, r, http.StatusInternalServerError, "failed to get overdue invoices", err)
 		return
 	}
 
@@ -398,7 +813,7 @@ func (h *InvoiceHandler) GetOverdueInvoices(w http.ResponseWriter, r *http.Reque
 
 // CreatePayment creates a new payment for an invoice
 // @Summary Create a payment
-// @Description Create a new payment for an invoice
+// @Description Create a new payment for an invoice. A negative amount refunds part of what was paid.
 // @Tags payments
 // @Accept json
 // @Produce json
@@ -414,19 +829,19 @@ func (h *InvoiceHandler) GetOverdueInvoices(w http.ResponseWriter, r *http.Reque
 func (h *InvoiceHandler) CreatePayment(w http.ResponseWriter, r *http.Request) {
 	organizationID := h.getOrganizationID(r)
 	if organizationID == 0 {
-		h.writeError(w, http.StatusBadRequest, "missing organization ID", nil)
+		h.writeError(w, r, http.StatusBadRequest, "missing organization ID", nil)
 		return
 	}
 
 	invoiceID, err := h.getUintParam(r, "invoiceId")
 	if err != nil {
-		h.writeError(w, http.StatusBadRequest, "invalid invoice ID", err)
+		h.writeError(w, r, http.StatusBadRequest, "invalid invoice ID", err)
 		return
 	}
 
 	var req usecase.CreatePaymentRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		h.writeError(w, http.StatusBadRequest, "invalid request body", err)
+		h.writeError(w, r, http.StatusBadRequest, "invalid request body", err)
 		return
 	}
 
@@ -435,7 +850,7 @@ func (h *InvoiceHandler) CreatePayment(w http.ResponseWriter, r *http.Request) {
 	req.InvoiceID = invoiceID
 
 	if err := h.validator.Struct(req); err != nil {
-		h.writeError(w, http.StatusBadRequest, "validation failed", err)
+		h.writeError(w, r, http.StatusBadRequest, "validation failed", err)
 		return
 	}
 
@@ -443,12 +858,16 @@ func (h *InvoiceHandler) CreatePayment(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		switch err {
 		case domain.ErrInvoiceNotFound:
-			h.writeError(w, http.StatusNotFound, "invoice not found", err)
+			h.writeError(w, r, http.StatusNotFound, "invoice not found", err)
 		case domain.ErrInsufficientPayment:
-			h.writeError(w, http.StatusBadRequest, "payment amount exceeds balance due", err)
+			h.writeError(w, r, http.StatusBadRequest, "payment amount exceeds balance due", err)
+		case domain.ErrRefundExceedsPaid:
+			h.writeError(w, r, http.StatusBadRequest, "refund amount exceeds amount paid", err)
+		case domain.ErrPaymentNotAllowed:
+			h.writeError(w, r, http.StatusConflict, "invoice cannot take payments", err)
 		default:
 			h.logger.Error("Failed to create payment", zap.Error(err))
-			h.writeError(w, http.StatusInternalServerError, "failed to create payment", err)
+			h.writeError(w, r, http.StatusInternalServerError, "failed to create payment", err)
 		}
 		return
 	}
@@ -456,54 +875,105 @@ func (h *InvoiceHandler) CreatePayment(w http.ResponseWriter, r *http.Request) {
 	h.writeJSON(w, http.StatusCreated, payment)
 }
 
+// ReorderInvoiceItems sets the order of an invoice's items
+// @Summary Reorder invoice items
+// @Description Sort the items of an editable invoice. The body must list every item of the invoice exactly once.
+// @Tags invoices
+// @Accept json
+// @Param organizationId header string true "Organization ID"
+// @Param invoiceId path string true "Invoice ID"
+// @Param order body object{itemIds:[]int} true "Item IDs in their new order"
+// @Success 204
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /invoices/{invoiceId}/items/order [put]
+func (h *InvoiceHandler) ReorderInvoiceItems(w http.ResponseWriter, r *http.Request) {
+	organizationID := h.getOrganizationID(r)
+	if organizationID == 0 {
+		h.writeError(w, r, http.StatusBadRequest, "missing organization ID", nil)
+		return
+	}
+
+	invoiceID, err := h.getUintParam(r, "invoiceId")
+	if err != nil {
+		h.writeError(w, r, http.StatusBadRequest, "invalid invoice ID", err)
+		return
+	}
+
+	var req struct {
+		ItemIDs []uint `json:"itemIds"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeError(w, r, http.StatusBadRequest, "invalid request body", err)
+		return
+	}
+
+	err = h.invoiceUseCase.ReorderInvoiceItems(r.Context(), organizationID, invoiceID, req.ItemIDs)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrInvoiceNotFound):
+			h.writeError(w, r, http.StatusNotFound, "invoice not found", err)
+		case errors.Is(err, domain.ErrInvalidItemOrder):
+			h.writeError(w, r, http.StatusBadRequest, "invalid item order", err)
+		case errors.Is(err, domain.ErrInvoiceNotEditable):
+			h.writeError(w, r, http.StatusConflict, "invoice is not editable", err)
+		default:
+			h.logger.Error("Failed to reorder invoice items", zap.Error(err))
+			h.writeError(w, r, http.StatusInternalServerError, "failed to reorder invoice items", err)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
 // Placeholder implementations for remaining handlers
 func (h *InvoiceHandler) CreateInvoiceItem(w http.ResponseWriter, r *http.Request) {
-	h.writeError(w, http.StatusNotImplemented, "not implemented", nil)
+	h.writeError(w, r, http.StatusNotImplemented, "not implemented", nil)
 }
 
 func (h *InvoiceHandler) GetInvoiceItems(w http.ResponseWriter, r *http.Request) {
-	h.writeError(w, http.StatusNotImplemented, "not implemented", nil)
+	h.writeError(w, r, http.StatusNotImplemented, "not implemented", nil)
 }
 
 func (h *InvoiceHandler) UpdateInvoiceItem(w http.ResponseWriter, r *http.Request) {
-	h.writeError(w, http.StatusNotImplemented, "not implemented", nil)
+	h.writeError(w, r, http.StatusNotImplemented, "not implemented", nil)
 }
 
 func (h *InvoiceHandler) DeleteInvoiceItem(w http.ResponseWriter, r *http.Request) {
-	h.writeError(w, http.StatusNotImplemented, "not implemented", nil)
+	h.writeError(w, r, http.StatusNotImplemented, "not implemented", nil)
 }
 
 func (h *InvoiceHandler) GetInvoicePayments(w http.ResponseWriter, r *http.Request) {
-	h.writeError(w, http.StatusNotImplemented, "not implemented", nil)
+	h.writeError(w, r, http.StatusNotImplemented, "not implemented", nil)
 }
 
 func (h *InvoiceHandler) ListPayments(w http.ResponseWriter, r *http.Request) {
-	h.writeError(w, http.StatusNotImplemented, "not implemented", nil)
+	h.writeError(w, r, http.StatusNotImplemented, "not implemented", nil)
 }
 
 func (h *InvoiceHandler) GetPayment(w http.ResponseWriter, r *http.Request) {
-	h.writeError(w, http.StatusNotImplemented, "not implemented", nil)
+	h.writeError(w, r, http.StatusNotImplemented, "not implemented", nil)
 }
 
 func (h *InvoiceHandler) UpdatePayment(w http.ResponseWriter, r *http.Request) {
-	h.writeError(w, http.StatusNotImplemented, "not implemented", nil)
+	h.writeError(w, r, http.StatusNotImplemented, "not implemented", nil)
 }
 
 func (h *InvoiceHandler) DeletePayment(w http.ResponseWriter, r *http.Request) {
-	h.writeError(w, http.StatusNotImplemented, "not implemented", nil)
+	h.writeError(w, r, http.StatusNotImplemented, "not implemented", nil)
 }
 
 // Helper methods
 
+// getOrganizationID returns the organization OrganizationMiddleware resolved
+// for the request
 func (h *InvoiceHandler) getOrganizationID(r *http.Request) uint {
-	// This should be extracted from JWT token or header
-	// For now, we'll use a header value
-	if orgIDStr := r.Header.Get("X-Organization-ID"); orgIDStr != "" {
-		if orgID, err := strconv.ParseUint(orgIDStr, 10, 32); err == nil {
-			return uint(orgID)
-		}
-	}
-	return 0
+	organizationID, _ := getOrganizationIDFromContext(r.Context())
+	return organizationID
 }
 
 func (h *InvoiceHandler) getUintParam(r *http.Request, param string) (uint, error) {
@@ -548,17 +1018,11 @@ func (h *InvoiceHandler) parseInvoiceFilters(r *http.Request) repository.Invoice
 		filters.Search = search
 	}
 
-	if pageStr := r.URL.Query().Get("page"); pageStr != "" {
-		if page, err := strconv.Atoi(pageStr); err == nil && page > 0 {
-			filters.Page = page
-		}
+	if paymentState := r.URL.Query().Get("paymentState"); paymentState != "" {
+		filters.PaymentState = &paymentState
 	}
 
-	if pageSizeStr := r.URL.Query().Get("pageSize"); pageSizeStr != "" {
-		if pageSize, err := strconv.Atoi(pageSizeStr); err == nil && pageSize > 0 && pageSize <= 100 {
-			filters.PageSize = pageSize
-		}
-	}
+	filters.Page, filters.PageSize = parsePagination(r, filters.PageSize)
 
 	if sortBy := r.URL.Query().Get("sortBy"); sortBy != "" {
 		filters.SortBy = sortBy
@@ -589,7 +1053,7 @@ func (h *InvoiceHandler) writeJSON(w http.ResponseWriter, status int, data inter
 	json.NewEncoder(w).Encode(data)
 }
 
-func (h *InvoiceHandler) writeError(w http.ResponseWriter, status int, message string, err error) {
+func (h *InvoiceHandler) writeError(w http.ResponseWriter, r *http.Request, status int, message string, err error) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
 
@@ -597,6 +1061,9 @@ func (h *InvoiceHandler) writeError(w http.ResponseWriter, status int, message s
 		"error":  message,
 		"status": status,
 	}
+	if traceID := middleware.GetTraceID(r.Context()); traceID != "" {
+		response["traceId"] = traceID
+	}
 
 	if err != nil {
 		response["details"] = err.Error()
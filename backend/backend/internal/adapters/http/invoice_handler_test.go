@@ -0,0 +1,560 @@
+package adapterhttp
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+
+	"github.com/pmaojo/kthulu-go/backend/core"
+	"github.com/pmaojo/kthulu-go/backend/internal/adapters/http/middleware"
+	"github.com/pmaojo/kthulu-go/backend/internal/domain"
+	"github.com/pmaojo/kthulu-go/backend/internal/domain/money"
+	"github.com/pmaojo/kthulu-go/backend/internal/domain/repository"
+	"github.com/pmaojo/kthulu-go/backend/internal/usecase"
+)
+
+// mockInvoiceRepository implements the invoice repository methods used by
+// the handler tests; any other call panics through the nil interface.
+type mockInvoiceRepository struct {
+	repository.InvoiceRepository
+
+	invoices   map[uint]*domain.Invoice
+	items      map[uint][]*domain.InvoiceItem
+	bulkIDs    []uint
+	bulkStatus domain.InvoiceStatus
+	events     []*domain.OutboxEvent
+	patched    map[string]any
+}
+
+func (m *mockInvoiceRepository) GetByID(ctx context.Context, organizationID, invoiceID uint) (*domain.Invoice, error) {
+	invoice, ok := m.invoices[invoiceID]
+	if !ok || invoice.OrganizationID != organizationID {
+		return nil, domain.ErrInvoiceNotFound
+	}
+	found := *invoice
+	return &found, nil
+}
+
+func (m *mockInvoiceRepository) Update(ctx context.Context, invoice *domain.Invoice) error {
+	m.invoices[invoice.ID] = invoice
+	return nil
+}
+
+func (m *mockInvoiceRepository) UpdateFields(ctx context.Context, organizationID, invoiceID uint, fields map[string]any) error {
+	m.patched = fields
+	invoice := m.invoices[invoiceID]
+	if notes, ok := fields["notes"].(string); ok {
+		invoice.Notes = notes
+	}
+	return nil
+}
+
+func (m *mockInvoiceRepository) UpdateWithEvent(ctx context.Context, invoice *domain.Invoice, event *domain.OutboxEvent) error {
+	m.invoices[invoice.ID] = invoice
+	m.events = append(m.events, event)
+	return nil
+}
+
+func (m *mockInvoiceRepository) List(ctx context.Context, organizationID uint, filters repository.InvoiceFilters) ([]*domain.Invoice, int64, error) {
+	var invoices []*domain.Invoice
+	for id := uint(1); id <= uint(len(m.invoices)); id++ {
+		if invoice, ok := m.invoices[id]; ok && invoice.OrganizationID == organizationID {
+			invoices = append(invoices, invoice)
+		}
+	}
+	return invoices, int64(len(invoices)), nil
+}
+
+func (m *mockInvoiceRepository) ListStream(ctx context.Context, organizationID uint, filters repository.InvoiceFilters, fn func(*domain.Invoice) error) error {
+	invoices, _, _ := m.List(ctx, organizationID, filters)
+	for _, invoice := range invoices {
+		if err := fn(invoice); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *mockInvoiceRepository) GetItemsByInvoiceID(ctx context.Context, invoiceID uint) ([]*domain.InvoiceItem, error) {
+	return m.items[invoiceID], nil
+}
+
+func (m *mockInvoiceRepository) GenerateInvoiceNumber(ctx context.Context, organizationID uint, invoiceType domain.InvoiceType) (string, error) {
+	return fmt.Sprintf("INV-%04d", len(m.invoices)+1), nil
+}
+
+func (m *mockInvoiceRepository) Create(ctx context.Context, invoice *domain.Invoice) error {
+	invoice.ID = uint(len(m.invoices) + 1)
+	m.invoices[invoice.ID] = invoice
+	return nil
+}
+
+func (m *mockInvoiceRepository) CreateItem(ctx context.Context, item *domain.InvoiceItem) error {
+	if m.items == nil {
+		m.items = make(map[uint][]*domain.InvoiceItem)
+	}
+	item.ID = uint(len(m.items[item.InvoiceID]) + 1)
+	m.items[item.InvoiceID] = append(m.items[item.InvoiceID], item)
+	return nil
+}
+
+func (m *mockInvoiceRepository) BulkUpdateStatus(ctx context.Context, organizationID uint, invoiceIDs []uint, status domain.InvoiceStatus) error {
+	m.bulkIDs = invoiceIDs
+	m.bulkStatus = status
+	return nil
+}
+
+// mockUnitOfWork runs every unit of work directly against the mock
+// repository; it does not undo failed steps.
+type mockUnitOfWork struct {
+	invoices *mockInvoiceRepository
+}
+
+func (u mockUnitOfWork) Do(ctx context.Context, fn func(repos repository.TxRepositories) error) error {
+	return fn(repository.TxRepositories{Invoices: u.invoices})
+}
+
+// invoiceContactRepository serves the contacts invoices are sent to
+type invoiceContactRepository struct {
+	repository.ContactRepository
+
+	contacts map[uint]*domain.Contact
+}
+
+func (m *invoiceContactRepository) GetByID(ctx context.Context, organizationID, contactID uint) (*domain.Contact, error) {
+	contact, ok := m.contacts[contactID]
+	if !ok {
+		return nil, domain.ErrContactNotFound
+	}
+	return contact, nil
+}
+
+func (m *invoiceContactRepository) GetAddressesByContactID(ctx context.Context, contactID uint) ([]*domain.ContactAddress, error) {
+	return nil, nil
+}
+
+// fakeInvoiceRenderer returns a fixed document naming the rendered invoice
+type fakeInvoiceRenderer struct{}
+
+func (fakeInvoiceRenderer) RenderPDF(ctx context.Context, invoice *domain.Invoice) ([]byte, error) {
+	return []byte(fmt.Sprintf("%%PDF %s items=%d", invoice.InvoiceNumber, len(invoice.Items))), nil
+}
+
+// fakeInvoiceNotifier records the invoice emails it is asked to send
+type fakeInvoiceNotifier struct {
+	repository.NotificationProvider
+
+	to         []string
+	attachment repository.NotificationAttachment
+	err        error
+}
+
+func (f *fakeInvoiceNotifier) SendInvoiceEmail(ctx context.Context, email, invoiceNumber string, attachment repository.NotificationAttachment) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.to = append(f.to, email)
+	f.attachment = attachment
+	return nil
+}
+
+func newInvoiceTestRouter(repo *mockInvoiceRepository) chi.Router {
+	return newInvoiceSendTestRouter(repo, &fakeInvoiceNotifier{})
+}
+
+func newInvoiceSendTestRouter(repo *mockInvoiceRepository, notifier *fakeInvoiceNotifier) chi.Router {
+	contacts := &invoiceContactRepository{contacts: map[uint]*domain.Contact{
+		10: {ID: 10, OrganizationID: 1, Email: "billing@example.com"},
+		11: {ID: 11, OrganizationID: 1},
+	}}
+	uc := usecase.NewInvoiceUseCase(repo, mockUnitOfWork{repo}, contacts, nil, nil, fakeInvoiceRenderer{}, notifier, nil, nil, nil, nil, money.DefaultPolicy(), core.NewLoggerFromZap(zap.NewNop()))
+	handler := NewInvoiceHandler(struct {
+		fx.In
+		InvoiceUseCase *usecase.InvoiceUseCase
+		OrgUsers       repository.OrganizationUserRepository
+		TokenManager   core.TokenManager
+		Denylist       repository.AccessTokenDenylist `optional:"true"`
+		Logger         *zap.Logger
+	}{
+		InvoiceUseCase: uc,
+		OrgUsers:       testMemberships{},
+		TokenManager:   testTokenManager,
+		Logger:         zap.NewNop(),
+	})
+
+	router := chi.NewRouter()
+	router.Use(authenticateAsMember)
+	handler.RegisterRoutes(router)
+	return router
+}
+
+func newInvoiceStatusRepo() *mockInvoiceRepository {
+	return &mockInvoiceRepository{invoices: map[uint]*domain.Invoice{
+		1: {
+			ID: 1, OrganizationID: 1, ContactID: 10, InvoiceNumber: "INV-0001", Type: domain.InvoiceTypeInvoice,
+			Status: domain.InvoiceStatusDraft, Currency: "EUR", IssueDate: time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC), CreatedBy: 1,
+		},
+		2: {ID: 2, OrganizationID: 1, Status: domain.InvoiceStatusSent},
+		3: {ID: 3, OrganizationID: 1, Status: domain.InvoiceStatusPaid},
+		4: {ID: 4, OrganizationID: 1, Status: domain.InvoiceStatusCancelled},
+	}}
+}
+
+func postBulkStatus(t *testing.T, router chi.Router, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/invoices/bulk/status", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Organization-ID", "1")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestInvoiceHandler_BulkStatus_SkipsIllegalTransitions(t *testing.T) {
+	repo := newInvoiceStatusRepo()
+	router := newInvoiceTestRouter(repo)
+
+	w := postBulkStatus(t, router, `{"ids":[1,2,3,4,99],"status":"sent"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var result usecase.BulkStatusResult
+	if err := json.NewDecoder(w.Body).Decode(&result); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if result.Updated != 2 || result.Skipped != 3 {
+		t.Fatalf("expected 2 updated and 3 skipped, got %+v", result)
+	}
+	skipped := make([]uint, len(result.Skips))
+	for i, s := range result.Skips {
+		skipped[i] = s.ID
+	}
+	if !reflect.DeepEqual(skipped, []uint{3, 4, 99}) {
+		t.Fatalf("expected paid, canceled and missing invoices skipped, got %v", skipped)
+	}
+	if !reflect.DeepEqual(repo.bulkIDs, []uint{1, 2}) || repo.bulkStatus != domain.InvoiceStatusSent {
+		t.Fatalf("unexpected bulk update %v -> %s", repo.bulkIDs, repo.bulkStatus)
+	}
+}
+
+func TestInvoiceHandler_BulkStatus_PaidStoresEvents(t *testing.T) {
+	repo := newInvoiceStatusRepo()
+	router := newInvoiceTestRouter(repo)
+
+	w := postBulkStatus(t, router, `{"ids":[2,3],"status":"paid"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var result usecase.BulkStatusResult
+	if err := json.NewDecoder(w.Body).Decode(&result); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if result.Updated != 2 || result.Skipped != 0 {
+		t.Fatalf("expected both invoices updated, got %+v", result)
+	}
+	if len(repo.events) != 1 || repo.events[0].Event != domain.WebhookEventInvoicePaid {
+		t.Fatalf("expected one invoice.paid event for the sent invoice, got %d", len(repo.events))
+	}
+	if repo.invoices[2].Status != domain.InvoiceStatusPaid {
+		t.Fatalf("expected invoice 2 to be paid, got %s", repo.invoices[2].Status)
+	}
+}
+
+func TestInvoiceHandler_BulkStatus_RejectsInvalidRequests(t *testing.T) {
+	router := newInvoiceTestRouter(newInvoiceStatusRepo())
+
+	for _, body := range []string{
+		`{"ids":[1],"status":"archived"}`,
+		`{"ids":[],"status":"sent"}`,
+		`{"ids":[1]}`,
+		`not json`,
+	} {
+		if w := postBulkStatus(t, router, body); w.Code != http.StatusBadRequest {
+			t.Fatalf("%s: expected 400, got %d", body, w.Code)
+		}
+	}
+}
+
+func TestInvoiceHandler_SetStatus_RejectsIllegalTransition(t *testing.T) {
+	repo := newInvoiceStatusRepo()
+	router := newInvoiceTestRouter(repo)
+
+	for _, tc := range []struct {
+		id   string
+		body string
+		want int
+	}{
+		{"3", `{"status":"draft"}`, http.StatusConflict},
+		{"4", `{"status":"sent"}`, http.StatusConflict},
+		{"1", `{"status":"archived"}`, http.StatusBadRequest},
+		{"1", `{"status":"sent"}`, http.StatusNoContent},
+	} {
+		req := httptest.NewRequest(http.MethodPatch, "/invoices/"+tc.id+"/status", bytes.NewBufferString(tc.body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Organization-ID", "1")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != tc.want {
+			t.Fatalf("invoice %s %s: expected %d, got %d: %s", tc.id, tc.body, tc.want, w.Code, w.Body.String())
+		}
+	}
+	if repo.invoices[3].Status != domain.InvoiceStatusPaid {
+		t.Fatalf("paid invoice must stay paid, got %s", repo.invoices[3].Status)
+	}
+	if repo.invoices[1].Status != domain.InvoiceStatusSent {
+		t.Fatalf("expected draft invoice to be sent, got %s", repo.invoices[1].Status)
+	}
+}
+
+func postSendInvoice(t *testing.T, router chi.Router, id string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/invoices/"+id+"/send", nil)
+	req.Header.Set("X-Organization-ID", "1")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestInvoiceHandler_SendInvoice_AttachesPDFAndMarksSent(t *testing.T) {
+	repo := newInvoiceStatusRepo()
+	repo.items = map[uint][]*domain.InvoiceItem{1: {{ID: 1, InvoiceID: 1, Description: "Consulting"}}}
+	notifier := &fakeInvoiceNotifier{}
+	router := newInvoiceSendTestRouter(repo, notifier)
+
+	if w := postSendInvoice(t, router, "1"); w.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d: %s", w.Code, w.Body.String())
+	}
+
+	if !reflect.DeepEqual(notifier.to, []string{"billing@example.com"}) {
+		t.Fatalf("expected one email to the contact, got %v", notifier.to)
+	}
+	if notifier.attachment.Filename != "invoice-INV-0001.pdf" || notifier.attachment.ContentType != "application/pdf" {
+		t.Fatalf("unexpected attachment %s (%s)", notifier.attachment.Filename, notifier.attachment.ContentType)
+	}
+	if string(notifier.attachment.Content) != "%PDF INV-0001 items=1" {
+		t.Fatalf("expected rendered invoice with its items, got %q", notifier.attachment.Content)
+	}
+	if repo.invoices[1].Status != domain.InvoiceStatusSent {
+		t.Fatalf("expected invoice to be marked sent, got %s", repo.invoices[1].Status)
+	}
+}
+
+func TestInvoiceHandler_SendInvoice_Failures(t *testing.T) {
+	repo := newInvoiceStatusRepo()
+	repo.invoices[5] = &domain.Invoice{ID: 5, OrganizationID: 1, ContactID: 11, Status: domain.InvoiceStatusDraft}
+	repo.invoices[4].ContactID = 10
+	router := newInvoiceSendTestRouter(repo, &fakeInvoiceNotifier{})
+
+	for id, want := range map[string]int{
+		"99": http.StatusNotFound,
+		"4":  http.StatusConflict,
+		"5":  http.StatusBadRequest,
+	} {
+		if w := postSendInvoice(t, router, id); w.Code != want {
+			t.Fatalf("invoice %s: expected %d, got %d", id, want, w.Code)
+		}
+	}
+
+	// A failed email leaves the draft untouched
+	failing := newInvoiceSendTestRouter(repo, &fakeInvoiceNotifier{err: errors.New("smtp down")})
+	if w := postSendInvoice(t, failing, "1"); w.Code != http.StatusInternalServerError {
+		t.Fatalf("expected 500 when the email fails, got %d", w.Code)
+	}
+	if repo.invoices[1].Status != domain.InvoiceStatusDraft {
+		t.Fatalf("invoice must stay draft when sending fails, got %s", repo.invoices[1].Status)
+	}
+}
+
+func TestInvoiceHandler_ExportInvoices_CSV(t *testing.T) {
+	repo := newInvoiceStatusRepo()
+	dueDate := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+	repo.invoices[1].Type = domain.InvoiceTypeInvoice
+	repo.invoices[1].Currency = "EUR"
+	repo.invoices[1].IssueDate = time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	repo.invoices[1].DueDate = &dueDate
+	repo.invoices[1].TotalAmount = 121
+	repo.invoices[1].BalanceDue = 121
+	router := newInvoiceTestRouter(repo)
+
+	req := httptest.NewRequest(http.MethodGet, "/invoices/export?format=csv", nil)
+	req.Header.Set("X-Organization-ID", "1")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/csv") {
+		t.Fatalf("unexpected content type %q", ct)
+	}
+
+	rows, err := csv.NewReader(w.Body).ReadAll()
+	if err != nil {
+		t.Fatalf("parse csv: %v", err)
+	}
+	if len(rows) != 5 {
+		t.Fatalf("expected a header and 4 invoices, got %d rows", len(rows))
+	}
+	if !reflect.DeepEqual(rows[0], invoiceExportColumns) {
+		t.Fatalf("unexpected header row %v", rows[0])
+	}
+	want := []string{"INV-0001", "invoice", "draft", "10", "EUR", "2024-03-01", "2024-04-01", "0.00", "0.00", "0.00", "121.00", "0.00", "121.00"}
+	if !reflect.DeepEqual(rows[1], want) {
+		t.Fatalf("unexpected first row:\n got %v\nwant %v", rows[1], want)
+	}
+	if rows[3][2] != "paid" || rows[3][6] != "" {
+		t.Fatalf("unexpected third row %v", rows[3])
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/invoices/export?format=pdf", nil)
+	req.Header.Set("X-Organization-ID", "1")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for unsupported format, got %d", w.Code)
+	}
+}
+
+func TestInvoiceHandler_ListInvoices_RejectsInvalidFilters(t *testing.T) {
+	router := newInvoiceTestRouter(newInvoiceStatusRepo())
+
+	for _, path := range []string{"/invoices?paymentState=overpaid", "/invoices/export?paymentState=overpaid"} {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("X-Organization-ID", "1")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != http.StatusBadRequest {
+			t.Fatalf("%s: expected 400, got %d", path, w.Code)
+		}
+	}
+}
+
+func TestInvoiceHandler_ErrorResponseIncludesTraceID(t *testing.T) {
+	router := middleware.TraceIDMiddleware(newInvoiceTestRouter(newInvoiceStatusRepo()))
+
+	req := httptest.NewRequest(http.MethodGet, "/invoices?paymentState=overpaid", nil)
+	req.Header.Set("X-Organization-ID", "1")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", w.Code)
+	}
+
+	var resp map[string]interface{}
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	header := w.Header().Get(middleware.TraceIDHeader)
+	if header == "" || resp["traceId"] != header {
+		t.Fatalf("expected traceId %v to match %s header %q", resp["traceId"], middleware.TraceIDHeader, header)
+	}
+}
+
+func TestInvoiceHandler_GetRevenueTimeSeries_RejectsInvalidPeriods(t *testing.T) {
+	router := newInvoiceTestRouter(newInvoiceStatusRepo())
+
+	for _, query := range []string{
+		"granularity=year",
+		"from=2024-02-01&to=2024-01-01",
+		"from=2020-01-01&to=2024-01-01&granularity=day",
+		"from=January",
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/invoices/revenue/timeseries?"+query, nil)
+		req.Header.Set("X-Organization-ID", "1")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != http.StatusBadRequest {
+			t.Fatalf("%s: expected 400, got %d", query, w.Code)
+		}
+	}
+}
+
+func TestInvoiceHandler_CreateInvoice_ValidatesItemMath(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		item string
+		want int
+	}{
+		{"discount above 100%", `{"description":"Consulting","quantity":2,"unitPrice":50,"discountPercent":1.5}`, http.StatusBadRequest},
+		{"discount above subtotal", `{"description":"Consulting","quantity":2,"unitPrice":50,"discountAmount":120}`, http.StatusBadRequest},
+		{"mismatched line total", `{"description":"Consulting","quantity":2,"unitPrice":50,"discountPercent":0.1,"taxRate":0.2,"lineTotal":120}`, http.StatusBadRequest},
+		{"matching line total", `{"description":"Consulting","quantity":2,"unitPrice":50,"discountPercent":0.1,"taxRate":0.2,"lineTotal":108}`, http.StatusCreated},
+	} {
+		repo := newInvoiceStatusRepo()
+		router := newInvoiceTestRouter(repo)
+
+		body := `{"contactId":10,"type":"invoice","currency":"EUR","issueDate":"2024-03-01T00:00:00Z","createdBy":1,"items":[` + tc.item + `]}`
+		req := httptest.NewRequest(http.MethodPost, "/invoices", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Organization-ID", "1")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != tc.want {
+			t.Fatalf("%s: expected %d, got %d: %s", tc.name, tc.want, w.Code, w.Body.String())
+		}
+		if tc.want != http.StatusCreated {
+			continue
+		}
+
+		var invoice domain.Invoice
+		if err := json.NewDecoder(w.Body).Decode(&invoice); err != nil {
+			t.Fatalf("decode invoice: %v", err)
+		}
+		if len(invoice.Items) != 1 || invoice.Items[0].LineTotal != 108 || invoice.Items[0].DiscountAmount != 10 {
+			t.Fatalf("unexpected items %+v", invoice.Items)
+		}
+	}
+}
+
+func TestInvoiceHandler_PatchInvoice_SendsOnlyGivenFields(t *testing.T) {
+	repo := newInvoiceStatusRepo()
+	router := newInvoiceTestRouter(repo)
+
+	patch := func(id, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPatch, "/invoices/"+id, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Organization-ID", "1")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := patch("1", `{"notes":"  Call before delivery "}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if !reflect.DeepEqual(repo.patched, map[string]any{"notes": "Call before delivery"}) {
+		t.Fatalf("expected only notes to be updated, got %v", repo.patched)
+	}
+	var invoice domain.Invoice
+	if err := json.NewDecoder(w.Body).Decode(&invoice); err != nil {
+		t.Fatalf("decode invoice: %v", err)
+	}
+	if invoice.Notes != "Call before delivery" || invoice.ContactID != 10 || invoice.InvoiceNumber != "INV-0001" {
+		t.Fatalf("unexpected patched invoice %+v", invoice)
+	}
+
+	if w := patch("1", `{"dueDate":"2025-02-01T00:00:00Z"}`); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected a due date before the issue date to be rejected, got %d", w.Code)
+	}
+	if w := patch("3", `{"notes":"x"}`); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected paid invoice to be rejected, got %d", w.Code)
+	}
+	if w := patch("9", `{"notes":"x"}`); w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for a missing invoice, got %d", w.Code)
+	}
+}
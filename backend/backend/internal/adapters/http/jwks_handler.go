@@ -0,0 +1,39 @@
+// @kthulu:core
+package adapterhttp
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/pmaojo/kthulu-go/backend/core"
+)
+
+// JWKSHandler publishes the public keys access tokens are signed with
+type JWKSHandler struct {
+	tokens core.TokenManager
+}
+
+// NewJWKSHandler creates a new JWKS handler
+func NewJWKSHandler(tokens core.TokenManager) *JWKSHandler {
+	return &JWKSHandler{tokens: tokens}
+}
+
+// RegisterRoutes registers the JWKS route
+func (h *JWKSHandler) RegisterRoutes(r chi.Router) {
+	r.Get("/.well-known/jwks.json", h.jwks)
+}
+
+// jwks godoc
+// @Summary JSON Web Key Set
+// @Description Returns the public keys for verifying RS256 access tokens; the set is empty in HS256 mode
+// @Tags Health
+// @Produce json
+// @Success 200 {object} core.JSONWebKeySet "Public signing keys"
+// @Router /.well-known/jwks.json [get]
+func (h *JWKSHandler) jwks(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	_ = json.NewEncoder(w).Encode(h.tokens.PublicKeySet())
+}
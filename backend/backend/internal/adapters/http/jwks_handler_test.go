@@ -0,0 +1,63 @@
+package adapterhttp
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/pmaojo/kthulu-go/backend/core"
+)
+
+func TestJWKSHandler_ServesPublicKey(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	tokens, err := core.NewJWT(&core.Config{JWT: core.JWTConfig{
+		RefreshSecret: "refresh-secret",
+		Algorithm:     core.JWTAlgorithmRS256,
+		PrivateKey:    string(keyPEM),
+		KeyID:         "primary",
+	}})
+	if err != nil {
+		t.Fatalf("new jwt: %v", err)
+	}
+
+	router := chi.NewRouter()
+	NewJWKSHandler(tokens).RegisterRoutes(router)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	var set core.JSONWebKeySet
+	if err := json.NewDecoder(w.Body).Decode(&set); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(set.Keys) != 1 || set.Keys[0].Kid != "primary" || set.Keys[0].Kty != "RSA" {
+		t.Fatalf("unexpected key set %+v", set)
+	}
+
+	n, err := base64.RawURLEncoding.DecodeString(set.Keys[0].N)
+	if err != nil {
+		t.Fatalf("decode modulus: %v", err)
+	}
+	e, err := base64.RawURLEncoding.DecodeString(set.Keys[0].E)
+	if err != nil {
+		t.Fatalf("decode exponent: %v", err)
+	}
+	if new(big.Int).SetBytes(n).Cmp(key.N) != 0 || int(new(big.Int).SetBytes(e).Int64()) != key.E {
+		t.Fatalf("published key does not match the signing key")
+	}
+}
@@ -8,14 +8,16 @@ import (
 
 	"github.com/pmaojo/kthulu-go/backend/core"
 	"github.com/pmaojo/kthulu-go/backend/internal/domain"
+	"github.com/pmaojo/kthulu-go/backend/internal/domain/repository"
 	auth "github.com/pmaojo/kthulu-go/backend/internal/adapters/http/modules/auth"
 )
 
 // UserIDKey is the context key for user ID
 const UserIDKey ContextKey = "user_id"
 
-// AuthMiddleware creates a middleware that validates JWT tokens and extracts user information
-func AuthMiddleware(tokenManager core.TokenManager) func(next http.Handler) http.Handler {
+// AuthMiddleware creates a middleware that validates JWT tokens and extracts user information.
+// Tokens whose jti is in denylist are rejected; a nil denylist skips the check.
+func AuthMiddleware(tokenManager core.TokenManager, denylist repository.AccessTokenDenylist) func(next http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			// Get logger from context
@@ -47,6 +49,19 @@ func AuthMiddleware(tokenManager core.TokenManager) func(next http.Handler) http
 				return
 			}
 
+			// Reject tokens revoked before their expiry
+			revoked, err := repository.IsAccessTokenRevoked(r.Context(), denylist, claims)
+			if err != nil {
+				logger.Errorw("Failed to check access token revocation", "error", err)
+				http.Error(w, "Unauthorized", http.StatusUnauthorized)
+				return
+			}
+			if revoked {
+				logger.Warnw("Revoked access token", "jti", claims["jti"])
+				http.Error(w, "Unauthorized", http.StatusUnauthorized)
+				return
+			}
+
 			// Validate DPoP proof binding if present
 			if err := auth.ValidateDPoP(r, tokenStr); err != nil {
 				logger.Warnw("Invalid DPoP proof", "error", err)
@@ -64,8 +79,9 @@ func AuthMiddleware(tokenManager core.TokenManager) func(next http.Handler) http
 
 			userID := uint(userIDFloat)
 
-			// Add user ID to context
+			// Add user ID to context, also exposing it as the audit actor
 			ctx := context.WithValue(r.Context(), UserIDKey, userID)
+			ctx = repository.ContextWithActor(ctx, userID)
 			r = r.WithContext(ctx)
 
 			logger.Infow("User authenticated", "userId", userID)
@@ -84,6 +100,6 @@ func GetUserID(ctx context.Context) (uint, error) {
 }
 
 // RequireAuth is a convenience function that creates an auth middleware
-func RequireAuth(tokenManager core.TokenManager) func(next http.Handler) http.Handler {
-	return AuthMiddleware(tokenManager)
+func RequireAuth(tokenManager core.TokenManager, denylist repository.AccessTokenDenylist) func(next http.Handler) http.Handler {
+	return AuthMiddleware(tokenManager, denylist)
 }
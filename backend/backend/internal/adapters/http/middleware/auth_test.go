@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/pmaojo/kthulu-go/backend/core"
+	"github.com/pmaojo/kthulu-go/backend/internal/infrastructure/storage"
+)
+
+// dpopProof builds an unsigned DPoP proof bound to the request and token.
+func dpopProof(t *testing.T, r *http.Request, accessToken string) string {
+	t.Helper()
+	ath := sha256.Sum256([]byte(accessToken))
+	payload, err := json.Marshal(map[string]string{
+		"htm": r.Method,
+		"htu": r.URL.Scheme + "://" + r.Host + r.URL.Path,
+		"ath": base64.RawURLEncoding.EncodeToString(ath[:]),
+	})
+	if err != nil {
+		t.Fatalf("marshal proof: %v", err)
+	}
+	return "e30." + base64.RawURLEncoding.EncodeToString(payload) + "."
+}
+
+func TestAuthMiddleware_RejectsRevokedAccessTokens(t *testing.T) {
+	tokens, err := core.NewJWT(&core.Config{JWT: core.JWTConfig{Secret: "access-secret", RefreshSecret: "refresh-secret"}})
+	if err != nil {
+		t.Fatalf("new jwt: %v", err)
+	}
+	expiresAt := time.Now().Add(time.Minute)
+	sign := func(jti string) string {
+		token, err := tokens.SignAccessToken(jwt.MapClaims{"sub": 1, "jti": jti, "exp": expiresAt.Unix()})
+		if err != nil {
+			t.Fatalf("sign: %v", err)
+		}
+		return token
+	}
+	revoked, active := sign("revoked-jti"), sign("active-jti")
+
+	denylist := storage.NewMemoryAccessTokenDenylist()
+	if err := denylist.Revoke(context.Background(), "revoked-jti", expiresAt); err != nil {
+		t.Fatalf("revoke: %v", err)
+	}
+
+	handler := AuthMiddleware(tokens, denylist)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	call := func(token string) int {
+		req := httptest.NewRequest(http.MethodGet, "http://example.com/users/me", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("DPoP", dpopProof(t, req, token))
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	if code := call(revoked); code != http.StatusUnauthorized {
+		t.Fatalf("expected revoked token to be rejected, got %d", code)
+	}
+	if code := call(active); code != http.StatusOK {
+		t.Fatalf("expected active token to pass, got %d", code)
+	}
+}
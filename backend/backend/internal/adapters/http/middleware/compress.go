@@ -0,0 +1,243 @@
+// @kthulu:core
+package middleware
+
+import (
+	"bufio"
+	"compress/gzip"
+	"errors"
+	"mime"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// DefaultCompressionMinSize is the smallest response body worth compressing
+const DefaultCompressionMinSize = 1024
+
+// DefaultIncompressibleContentTypes are already compressed and sent as is.
+// Entries ending in "/*" match a whole media type family.
+var DefaultIncompressibleContentTypes = []string{
+	"application/pdf",
+	"application/zip",
+	"application/gzip",
+	"application/x-gzip",
+	"application/x-7z-compressed",
+	"application/x-rar-compressed",
+	"application/x-bzip2",
+	"application/zstd",
+	"image/*",
+	"audio/*",
+	"video/*",
+	"font/woff",
+	"font/woff2",
+}
+
+// CompressionOptions controls CompressMiddleware
+type CompressionOptions struct {
+	// Level is the gzip compression level
+	Level int
+	// MinSize is the body size below which responses are sent uncompressed;
+	// zero uses DefaultCompressionMinSize
+	MinSize int
+	// SkipContentTypes are sent uncompressed; nil uses
+	// DefaultIncompressibleContentTypes
+	SkipContentTypes []string
+}
+
+// CompressMiddleware gzips responses for clients that accept it, except for
+// bodies smaller than MinSize and content types that are already compressed.
+// The body is buffered until MinSize bytes have been written or the handler
+// returns, so the decision can take both into account. Upgrade requests,
+// such as WebSocket handshakes, are passed through untouched.
+func CompressMiddleware(opts CompressionOptions) func(http.Handler) http.Handler {
+	if opts.MinSize <= 0 {
+		opts.MinSize = DefaultCompressionMinSize
+	}
+	if opts.SkipContentTypes == nil {
+		opts.SkipContentTypes = DefaultIncompressibleContentTypes
+	}
+	if opts.Level < gzip.HuffmanOnly || opts.Level > gzip.BestCompression {
+		opts.Level = gzip.DefaultCompression
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !acceptsGzip(r) || isUpgrade(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			w.Header().Add("Vary", "Accept-Encoding")
+			cw := &compressWriter{ResponseWriter: w, opts: &opts, status: http.StatusOK}
+			defer cw.Close()
+			next.ServeHTTP(cw, r)
+		})
+	}
+}
+
+func acceptsGzip(r *http.Request) bool {
+	for _, encoding := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(encoding), ";")
+		if strings.EqualFold(strings.TrimSpace(name), "gzip") && strings.ReplaceAll(params, " ", "") != "q=0" {
+			return true
+		}
+	}
+	return false
+}
+
+// isUpgrade reports whether the request asks to switch protocols
+func isUpgrade(r *http.Request) bool {
+	for _, value := range r.Header.Values("Connection") {
+		for _, option := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(option), "upgrade") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// compressWriter buffers the start of the body until it knows whether the
+// response should be compressed
+type compressWriter struct {
+	http.ResponseWriter
+	opts        *CompressionOptions
+	status      int
+	wroteHeader bool
+	decided     bool
+	buf         []byte
+	gz          *gzip.Writer
+}
+
+func (cw *compressWriter) WriteHeader(status int) {
+	if cw.wroteHeader {
+		return
+	}
+	cw.wroteHeader = true
+	cw.status = status
+	// Informational and bodiless responses are never compressed
+	if status < http.StatusOK || status == http.StatusNoContent || status == http.StatusNotModified {
+		cw.decide(false)
+	}
+}
+
+func (cw *compressWriter) Write(p []byte) (int, error) {
+	if !cw.decided {
+		cw.buf = append(cw.buf, p...)
+		if len(cw.buf) < cw.opts.MinSize {
+			return len(p), nil
+		}
+		if err := cw.decide(cw.compressible()); err != nil {
+			return 0, err
+		}
+		return len(p), nil
+	}
+	if cw.gz != nil {
+		return cw.gz.Write(p)
+	}
+	return cw.ResponseWriter.Write(p)
+}
+
+// Flush sends what has been buffered so far. A response that is flushed
+// before reaching MinSize is sent uncompressed.
+func (cw *compressWriter) Flush() {
+	if !cw.decided {
+		cw.decide(false)
+	}
+	if cw.gz != nil {
+		cw.gz.Flush()
+	}
+	if f, ok := cw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Close finishes the response, sending small bodies uncompressed
+func (cw *compressWriter) Close() error {
+	if !cw.decided {
+		if err := cw.decide(false); err != nil {
+			return err
+		}
+	}
+	if cw.gz != nil {
+		return cw.gz.Close()
+	}
+	return nil
+}
+
+// Hijack hands the connection to the handler. Nothing buffered is sent, the
+// handler takes over the response.
+func (cw *compressWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := cw.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("response writer does not support hijacking")
+	}
+	cw.decided = true
+	cw.buf = nil
+	return hijacker.Hijack()
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer
+func (cw *compressWriter) Unwrap() http.ResponseWriter {
+	return cw.ResponseWriter
+}
+
+// decide writes the header and the buffered body, compressed or not
+func (cw *compressWriter) decide(compress bool) error {
+	cw.decided = true
+	header := cw.Header()
+	if len(cw.buf) > 0 && header.Get("Content-Type") == "" {
+		// Sniff before compressing, net/http would otherwise sniff gzip bytes
+		header.Set("Content-Type", http.DetectContentType(cw.buf))
+	}
+	if compress {
+		header.Del("Content-Length")
+		header.Set("Content-Encoding", "gzip")
+	}
+	cw.ResponseWriter.WriteHeader(cw.status)
+
+	buf := cw.buf
+	cw.buf = nil
+	if compress {
+		gz, err := gzip.NewWriterLevel(cw.ResponseWriter, cw.opts.Level)
+		if err != nil {
+			return err
+		}
+		cw.gz = gz
+		_, err = gz.Write(buf)
+		return err
+	}
+	if len(buf) == 0 {
+		return nil
+	}
+	_, err := cw.ResponseWriter.Write(buf)
+	return err
+}
+
+// compressible reports whether the response may be compressed based on its
+// headers
+func (cw *compressWriter) compressible() bool {
+	header := cw.Header()
+	if header.Get("Content-Encoding") != "" || header.Get("Content-Range") != "" {
+		return false
+	}
+
+	contentType := header.Get("Content-Type")
+	if contentType == "" {
+		contentType = http.DetectContentType(cw.buf)
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	for _, skip := range cw.opts.SkipContentTypes {
+		if family, ok := strings.CutSuffix(skip, "/*"); ok {
+			if strings.HasPrefix(mediaType, family+"/") && mediaType != "image/svg+xml" {
+				return false
+			}
+		} else if mediaType == skip {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,140 @@
+package middleware
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func serveCompressed(t *testing.T, contentType string, body []byte) *httptest.ResponseRecorder {
+	t.Helper()
+	handler := CompressMiddleware(CompressionOptions{Level: 5})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", contentType)
+		w.Write(body)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept-Encoding", "gzip, deflate")
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	return rr
+}
+
+func TestCompressMiddlewareSkipsSmallJSON(t *testing.T) {
+	body := []byte(`{"status":"ok"}`)
+	rr := serveCompressed(t, "application/json", body)
+
+	if enc := rr.Header().Get("Content-Encoding"); enc != "" {
+		t.Fatalf("expected small JSON to be sent uncompressed, got Content-Encoding %q", enc)
+	}
+	if !bytes.Equal(rr.Body.Bytes(), body) {
+		t.Fatalf("unexpected body %q", rr.Body.String())
+	}
+}
+
+func TestCompressMiddlewareCompressesLargeJSON(t *testing.T) {
+	body := []byte(`{"items":[` + strings.Repeat(`{"name":"item"},`, 200) + `{}]}`)
+	rr := serveCompressed(t, "application/json; charset=utf-8", body)
+
+	if enc := rr.Header().Get("Content-Encoding"); enc != "gzip" {
+		t.Fatalf("expected large JSON to be gzipped, got Content-Encoding %q", enc)
+	}
+	gz, err := gzip.NewReader(rr.Body)
+	if err != nil {
+		t.Fatalf("gzip reader: %v", err)
+	}
+	decoded, err := io.ReadAll(gz)
+	if err != nil || !bytes.Equal(decoded, body) {
+		t.Fatalf("gzipped body does not round trip: %v", err)
+	}
+}
+
+func TestCompressMiddlewareSkipsPDF(t *testing.T) {
+	body := append([]byte("%PDF-1.4\n"), bytes.Repeat([]byte("0"), 4*DefaultCompressionMinSize)...)
+	rr := serveCompressed(t, "application/pdf", body)
+
+	if enc := rr.Header().Get("Content-Encoding"); enc != "" {
+		t.Fatalf("expected PDF to be sent as is, got Content-Encoding %q", enc)
+	}
+	if !bytes.Equal(rr.Body.Bytes(), body) {
+		t.Fatalf("PDF body was altered")
+	}
+}
+
+func TestCompressMiddlewareRespectsAcceptEncoding(t *testing.T) {
+	handler := CompressMiddleware(CompressionOptions{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write(bytes.Repeat([]byte("a"), 2*DefaultCompressionMinSize))
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept-Encoding", "gzip;q=0, identity")
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	if enc := rr.Header().Get("Content-Encoding"); enc != "" {
+		t.Fatalf("expected no compression when gzip is refused, got %q", enc)
+	}
+}
+
+func TestCompressMiddlewareUpgradesWebSockets(t *testing.T) {
+	handler := CompressMiddleware(CompressionOptions{Level: 5})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var upgrader websocket.Upgrader
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Errorf("upgrade: %v", err)
+			return
+		}
+		defer conn.Close()
+		conn.WriteMessage(websocket.TextMessage, []byte("hello"))
+	}))
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	conn, _, err := websocket.DefaultDialer.DialContext(ctx, "ws"+strings.TrimPrefix(server.URL, "http"), http.Header{
+		"Accept-Encoding": {"gzip, deflate, br"},
+	})
+	if err != nil {
+		t.Fatalf("dial through the middleware: %v", err)
+	}
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if _, msg, err := conn.ReadMessage(); err != nil || string(msg) != "hello" {
+		t.Fatalf("expected hello, got %q, %v", msg, err)
+	}
+}
+
+func TestCompressWriterHijacks(t *testing.T) {
+	handler := CompressMiddleware(CompressionOptions{Level: 5})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, rw, err := http.NewResponseController(w).Hijack()
+		if err != nil {
+			t.Errorf("hijack: %v", err)
+			return
+		}
+		defer conn.Close()
+		rw.WriteString("HTTP/1.1 200 OK\r\nContent-Length: 2\r\nConnection: close\r\n\r\nok")
+		rw.Flush()
+	}))
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	resp, err := http.DefaultTransport.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("request: %v", err)
+	}
+	defer resp.Body.Close()
+	if body, _ := io.ReadAll(resp.Body); string(body) != "ok" {
+		t.Fatalf("expected the hijacked response, got %q", body)
+	}
+}
@@ -29,8 +29,22 @@ type CheckResult struct {
 	Latency time.Duration `json:"latency,omitempty"`
 }
 
+// HealthCheckOptions configures the database probe of the health endpoints.
+// Zero values fall back to core.DefaultHealthCheckQuery and
+// core.DefaultHealthCheckTimeout.
+type HealthCheckOptions struct {
+	Query   string
+	Timeout time.Duration
+}
+
 // HealthCheckHandler creates a comprehensive health check handler
 func HealthCheckHandler(db *sql.DB, logger observability.Logger, version string) http.HandlerFunc {
+	return HealthCheckHandlerWithOptions(db, logger, version, HealthCheckOptions{})
+}
+
+// HealthCheckHandlerWithOptions creates a health check handler probing the
+// database as configured
+func HealthCheckHandlerWithOptions(db *sql.DB, logger observability.Logger, version string, opts HealthCheckOptions) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if r.URL.Path != "/healthz" {
 			return
@@ -42,7 +56,7 @@ func HealthCheckHandler(db *sql.DB, logger observability.Logger, version string)
 
 		// Database health check
 		dbStart := time.Now()
-		dbErr := core.HealthCheck(db)
+		dbErr := core.HealthProbe(db, opts.Query, opts.Timeout)
 		dbLatency := time.Since(dbStart)
 
 		if dbErr != nil {
@@ -104,13 +118,19 @@ func HealthCheckHandler(db *sql.DB, logger observability.Logger, version string)
 
 // ReadinessCheckHandler creates a readiness check handler (simpler than health check)
 func ReadinessCheckHandler(db *sql.DB) http.HandlerFunc {
+	return ReadinessCheckHandlerWithOptions(db, HealthCheckOptions{})
+}
+
+// ReadinessCheckHandlerWithOptions creates a readiness check handler probing
+// the database as configured
+func ReadinessCheckHandlerWithOptions(db *sql.DB, opts HealthCheckOptions) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if r.URL.Path != "/readyz" {
 			return
 		}
 
-		// Quick database ping
-		if err := core.HealthCheck(db); err != nil {
+		// Quick database probe
+		if err := core.HealthProbe(db, opts.Query, opts.Timeout); err != nil {
 			w.WriteHeader(http.StatusServiceUnavailable)
 			w.Write([]byte("Not Ready"))
 			return
@@ -135,8 +155,14 @@ func LivenessCheckHandler() http.HandlerFunc {
 
 // AdvancedHealthMiddleware creates a middleware that handles multiple health endpoints
 func AdvancedHealthMiddleware(db *sql.DB, logger observability.Logger, version string) func(next http.Handler) http.Handler {
-	healthHandler := HealthCheckHandler(db, logger, version)
-	readinessHandler := ReadinessCheckHandler(db)
+	return AdvancedHealthMiddlewareWithOptions(db, logger, version, HealthCheckOptions{})
+}
+
+// AdvancedHealthMiddlewareWithOptions creates the health endpoints middleware
+// with a configured database probe
+func AdvancedHealthMiddlewareWithOptions(db *sql.DB, logger observability.Logger, version string, opts HealthCheckOptions) func(next http.Handler) http.Handler {
+	healthHandler := HealthCheckHandlerWithOptions(db, logger, version, opts)
+	readinessHandler := ReadinessCheckHandlerWithOptions(db, opts)
 	livenessHandler := LivenessCheckHandler()
 
 	return func(next http.Handler) http.Handler {
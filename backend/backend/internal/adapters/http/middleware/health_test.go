@@ -1,10 +1,13 @@
 package middleware
 
 import (
+	"encoding/json"
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"go.uber.org/zap"
@@ -33,12 +36,12 @@ func (f *failingResponseWriter) WriteHeader(statusCode int) {
 }
 
 func TestHealthCheckHandler_EncodeFailure(t *testing.T) {
-	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	db, mock, err := sqlmock.New()
 	if err != nil {
 		t.Fatalf("failed to create sqlmock: %v", err)
 	}
 	defer db.Close()
-	mock.ExpectPing()
+	mock.ExpectQuery("SELECT 1").WillReturnRows(sqlmock.NewRows([]string{"1"}).AddRow(1))
 
 	handler := HealthCheckHandler(db, observability.NewLoggerFromZap(zap.NewNop()), "test-version")
 
@@ -55,3 +58,65 @@ func TestHealthCheckHandler_EncodeFailure(t *testing.T) {
 		t.Errorf("there were unfulfilled expectations: %v", err)
 	}
 }
+
+func TestHealthCheckHandler_ProbeTimeout(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	defer db.Close()
+	// The database accepts connections but the probe hangs
+	mock.ExpectQuery("SELECT 1").WillDelayFor(time.Second).WillReturnRows(sqlmock.NewRows([]string{"1"}).AddRow(1))
+
+	handler := HealthCheckHandlerWithOptions(db, observability.NewLoggerFromZap(zap.NewNop()), "test-version", HealthCheckOptions{
+		Query:   "SELECT 1",
+		Timeout: 20 * time.Millisecond,
+	})
+
+	start := time.Now()
+	w := httptest.NewRecorder()
+	handler(w, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Fatalf("expected the probe to give up after its timeout, took %s", elapsed)
+	}
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected status %d, got %d", http.StatusServiceUnavailable, w.Code)
+	}
+	var health HealthStatus
+	if err := json.NewDecoder(w.Body).Decode(&health); err != nil {
+		t.Fatalf("failed to decode health: %v", err)
+	}
+	check := health.Checks["database"]
+	if health.Status != "unhealthy" || check.Status != "unhealthy" || !strings.Contains(check.Message, "timed out") {
+		t.Fatalf("expected an unhealthy database check, got %+v", health)
+	}
+}
+
+func TestReadinessCheckHandler_ProbeQuery(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	defer db.Close()
+	mock.ExpectQuery("SELECT 1 FROM users LIMIT 1").WillReturnRows(sqlmock.NewRows([]string{"1"}))
+	mock.ExpectQuery("SELECT 1 FROM users LIMIT 1").WillDelayFor(time.Second).WillReturnRows(sqlmock.NewRows([]string{"1"}))
+
+	handler := ReadinessCheckHandlerWithOptions(db, HealthCheckOptions{Query: "SELECT 1 FROM users LIMIT 1", Timeout: 20 * time.Millisecond})
+
+	w := httptest.NewRecorder()
+	handler(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	handler(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected a timed out probe to be not ready, got %d", w.Code)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %v", err)
+	}
+}
@@ -0,0 +1,223 @@
+// @kthulu:core
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/pmaojo/kthulu-go/backend/internal/domain/repository"
+)
+
+const (
+	// IdempotencyKeyHeader names the client chosen key that makes a POST or
+	// PATCH safe to retry
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotentReplayedHeader is set on responses replayed for a repeated
+	// key
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+
+	// maxIdempotentBodyBytes bounds the request bodies that are fingerprinted;
+	// larger requests are served without idempotency
+	maxIdempotentBodyBytes = 1 << 20
+	// idempotencyLockTTL bounds how long a request that never completes, for
+	// example because the instance died, blocks retries of its key
+	idempotencyLockTTL = time.Minute
+)
+
+// idempotentResponse is a stored response replayed for a repeated key
+type idempotentResponse struct {
+	Fingerprint string      `json:"fingerprint"`
+	Status      int         `json:"status"`
+	Header      http.Header `json:"header"`
+	Body        []byte      `json:"body"`
+}
+
+// IdempotencyStore keeps the responses of requests sent with an
+// Idempotency-Key in the shared cache, so a retry reaching any instance
+// gets the original response instead of repeating the request
+type IdempotencyStore struct {
+	cache repository.Cache
+	ttl   time.Duration
+}
+
+// NewIdempotencyStore creates a store keeping responses for ttl
+func NewIdempotencyStore(cache repository.Cache, ttl time.Duration) *IdempotencyStore {
+	return &IdempotencyStore{cache: cache, ttl: ttl}
+}
+
+func (s *IdempotencyStore) lookup(ctx context.Context, key string) (*idempotentResponse, error) {
+	data, ok, err := s.cache.Get(ctx, "idempotency:"+key)
+	if err != nil || !ok {
+		return nil, err
+	}
+	var resp idempotentResponse
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+func (s *IdempotencyStore) save(ctx context.Context, key string, resp *idempotentResponse) error {
+	data, err := json.Marshal(resp)
+	if err != nil {
+		return err
+	}
+	return s.cache.Set(ctx, "idempotency:"+key, data, s.ttl)
+}
+
+// lock claims the key for a request in progress. It reports false when
+// another request holds it.
+func (s *IdempotencyStore) lock(ctx context.Context, key string) (bool, error) {
+	count, err := s.cache.Incr(ctx, "idempotency-lock:"+key, idempotencyLockTTL)
+	return count == 1, err
+}
+
+func (s *IdempotencyStore) unlock(ctx context.Context, key string) error {
+	return s.cache.Del(ctx, "idempotency-lock:"+key)
+}
+
+// IdempotencyMiddleware makes POST and PATCH requests carrying an
+// Idempotency-Key header safe to retry. The first response below 500 is
+// stored and replayed, with an Idempotent-Replayed header, for repeats of
+// the key by the same caller. A repeat while the first request is still in
+// progress gets 409, and reusing the key for a different request gets 422.
+// When the store is nil or unavailable requests are served as usual.
+func IdempotencyMiddleware(store *IdempotencyStore) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			idempotencyKey := r.Header.Get(IdempotencyKeyHeader)
+			if store == nil || idempotencyKey == "" || (r.Method != http.MethodPost && r.Method != http.MethodPatch) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			body, err := io.ReadAll(io.LimitReader(r.Body, maxIdempotentBodyBytes+1))
+			if err != nil {
+				writeIdempotencyError(w, http.StatusBadRequest, "invalid request body")
+				return
+			}
+			if len(body) > maxIdempotentBodyBytes {
+				r.Body = io.NopCloser(io.MultiReader(bytes.NewReader(body), r.Body))
+				next.ServeHTTP(w, r)
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			// Keys are scoped to the caller so they can't collide across users
+			key := hashParts(r.Header.Get("Authorization"), idempotencyKey)
+			fingerprint := hashParts(r.Method, r.URL.RequestURI(), string(body))
+			ctx := r.Context()
+
+			stored, err := store.lookup(ctx, key)
+			if err != nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if stored != nil {
+				replayIdempotent(w, stored, fingerprint)
+				return
+			}
+
+			locked, err := store.lock(ctx, key)
+			if err != nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if !locked {
+				w.Header().Set("Retry-After", "1")
+				writeIdempotencyError(w, http.StatusConflict, "a request with this idempotency key is in progress")
+				return
+			}
+			defer store.unlock(context.WithoutCancel(ctx), key)
+
+			// The request may have completed between the lookup and the lock
+			if stored, err := store.lookup(ctx, key); err == nil && stored != nil {
+				replayIdempotent(w, stored, fingerprint)
+				return
+			}
+
+			recorder := &idempotencyRecorder{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(recorder, r)
+			if recorder.status >= http.StatusInternalServerError {
+				// Server errors are worth retrying
+				return
+			}
+			_ = store.save(context.WithoutCancel(ctx), key, &idempotentResponse{
+				Fingerprint: fingerprint,
+				Status:      recorder.status,
+				Header:      recorder.header,
+				Body:        recorder.body.Bytes(),
+			})
+		})
+	}
+}
+
+// replayIdempotent writes a stored response, unless it was stored for a
+// different request
+func replayIdempotent(w http.ResponseWriter, stored *idempotentResponse, fingerprint string) {
+	if stored.Fingerprint != fingerprint {
+		writeIdempotencyError(w, http.StatusUnprocessableEntity, "idempotency key was used for a different request")
+		return
+	}
+	for name, values := range stored.Header {
+		w.Header()[name] = values
+	}
+	w.Header().Set(IdempotentReplayedHeader, "true")
+	w.Header().Set("Content-Length", strconv.Itoa(len(stored.Body)))
+	w.WriteHeader(stored.Status)
+	w.Write(stored.Body)
+}
+
+func writeIdempotencyError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"error":  message,
+		"status": status,
+	})
+}
+
+func hashParts(parts ...string) string {
+	h := sha256.New()
+	for _, part := range parts {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// idempotencyRecorder passes the response through while keeping a copy to
+// store
+type idempotencyRecorder struct {
+	http.ResponseWriter
+	status      int
+	header      http.Header
+	body        bytes.Buffer
+	wroteHeader bool
+}
+
+func (r *idempotencyRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.wroteHeader = true
+		r.status = status
+		r.header = r.ResponseWriter.Header().Clone()
+		r.header.Del("Set-Cookie")
+		r.header.Del("Content-Length")
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *idempotencyRecorder) Write(b []byte) (int, error) {
+	if !r.wroteHeader {
+		r.WriteHeader(http.StatusOK)
+	}
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
@@ -0,0 +1,96 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pmaojo/kthulu-go/backend/internal/infrastructure/cache"
+)
+
+func TestIdempotencyMiddleware(t *testing.T) {
+	calls := 0
+	started, release := make(chan struct{}), make(chan struct{})
+	handler := IdempotencyMiddleware(NewIdempotencyStore(cache.NewMemoryCache(), time.Hour))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			close(started)
+			<-release
+			return
+		}
+		calls++
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Location", "/invoices/1")
+		w.WriteHeader(http.StatusCreated)
+		w.Write(body)
+	}))
+	send := func(path, auth, key, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		req.Header.Set("Authorization", auth)
+		req.Header.Set(IdempotencyKeyHeader, key)
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	first := send("/invoices", "Bearer a", "k1", `{"n":1}`)
+	replay := send("/invoices", "Bearer a", "k1", `{"n":1}`)
+	if calls != 1 || replay.Code != http.StatusCreated || replay.Body.String() != `{"n":1}` || replay.Header().Get("Location") != "/invoices/1" {
+		t.Fatalf("expected the first response to be replayed, got %d calls, %d %q", calls, replay.Code, replay.Body.String())
+	}
+	if first.Header().Get(IdempotentReplayedHeader) != "" || replay.Header().Get(IdempotentReplayedHeader) != "true" {
+		t.Fatal("expected only the replay to be marked")
+	}
+
+	if rr := send("/invoices", "Bearer a", "k1", `{"n":2}`); rr.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422 for a reused key, got %d", rr.Code)
+	}
+	if send("/invoices", "Bearer b", "k1", `{"n":1}`); calls != 2 {
+		t.Fatalf("expected keys to be scoped to the caller, got %d calls", calls)
+	}
+
+	send("/fail", "Bearer a", "k2", "")
+	send("/fail", "Bearer a", "k2", "")
+	if calls != 4 {
+		t.Fatalf("expected server errors to be retried, got %d calls", calls)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		send("/slow", "Bearer a", "k3", "")
+		close(done)
+	}()
+	<-started
+	if rr := send("/slow", "Bearer a", "k3", ""); rr.Code != http.StatusConflict {
+		t.Fatalf("expected 409 while the first request is in progress, got %d", rr.Code)
+	}
+	close(release)
+	<-done
+}
+
+func TestIdempotencyMiddleware_IgnoresOtherRequests(t *testing.T) {
+	calls := 0
+	handler := IdempotencyMiddleware(NewIdempotencyStore(cache.NewMemoryCache(), time.Hour))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+	}))
+	for _, req := range []*http.Request{
+		httptest.NewRequest(http.MethodPost, "/invoices", nil),
+		httptest.NewRequest(http.MethodPost, "/invoices", nil),
+		httptest.NewRequest(http.MethodGet, "/invoices", nil),
+		httptest.NewRequest(http.MethodGet, "/invoices", nil),
+	} {
+		if req.Method == http.MethodGet {
+			req.Header.Set(IdempotencyKeyHeader, "k")
+		}
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	if calls != 4 {
+		t.Fatalf("expected requests without a key and safe methods to pass through, got %d calls", calls)
+	}
+}
@@ -0,0 +1,32 @@
+// @kthulu:core
+package middleware
+
+import (
+	"net/http"
+	"sync/atomic"
+)
+
+// InFlightTracker counts the requests currently being served so that
+// shutdown can report what is still running when the grace period ends.
+type InFlightTracker struct {
+	active atomic.Int64
+}
+
+// NewInFlightTracker creates an empty request tracker
+func NewInFlightTracker() *InFlightTracker {
+	return &InFlightTracker{}
+}
+
+// Middleware counts the request as in flight until the handler returns
+func (t *InFlightTracker) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.active.Add(1)
+		defer t.active.Add(-1)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Count returns the number of requests currently in flight
+func (t *InFlightTracker) Count() int64 {
+	return t.active.Load()
+}
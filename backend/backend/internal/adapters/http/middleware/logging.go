@@ -29,8 +29,27 @@ const (
 	TraceIDKey ContextKey = "trace_id"
 )
 
+// LoggingOptions controls what LoggingMiddlewareWithOptions logs
+type LoggingOptions struct {
+	// LogRequestBodies adds the request body, redacted, to "Request started"
+	LogRequestBodies bool
+	// Redactor masks sensitive body fields; nil uses the default fields
+	Redactor *BodyRedactor
+}
+
 // LoggingMiddleware creates a middleware that logs HTTP requests with correlation IDs
 func LoggingMiddleware(logger observability.Logger) func(next http.Handler) http.Handler {
+	return LoggingMiddlewareWithOptions(logger, LoggingOptions{})
+}
+
+// LoggingMiddlewareWithOptions is LoggingMiddleware with optional request
+// body logging. Bodies are always redacted before they are logged.
+func LoggingMiddlewareWithOptions(logger observability.Logger, opts LoggingOptions) func(next http.Handler) http.Handler {
+	redactor := opts.Redactor
+	if redactor == nil {
+		redactor = NewBodyRedactor()
+	}
+
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			start := time.Now()
@@ -66,7 +85,11 @@ func LoggingMiddleware(logger observability.Logger) func(next http.Handler) http
 			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
 
 			// Log request start
-			requestLogger.Info("Request started")
+			if opts.LogRequestBodies {
+				requestLogger.Info("Request started", zap.String("body", loggedBody(r, redactor)))
+			} else {
+				requestLogger.Info("Request started")
+			}
 
 			// Process request
 			next.ServeHTTP(ww, r)
@@ -82,6 +105,15 @@ func LoggingMiddleware(logger observability.Logger) func(next http.Handler) http
 	}
 }
 
+// loggedBody returns the redacted request body for the request log
+func loggedBody(r *http.Request, redactor *BodyRedactor) string {
+	body, complete := peekRequestBody(r)
+	if !complete {
+		return "[body too large to log]"
+	}
+	return redactor.Redact(r.Header.Get("Content-Type"), body)
+}
+
 // GetRequestID extracts the request ID from context
 func GetRequestID(ctx context.Context) string {
 	if requestID, ok := ctx.Value(RequestIDKey).(string); ok {
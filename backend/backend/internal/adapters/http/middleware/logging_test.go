@@ -2,6 +2,10 @@ package middleware
 
 import (
 	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/google/uuid"
@@ -34,3 +38,73 @@ func TestGenerateRequestIDRandReadError(t *testing.T) {
 		t.Fatalf("unexpected log message: %s", logs.All()[0].Message)
 	}
 }
+
+func TestLoggingMiddlewareRedactsLoginBody(t *testing.T) {
+	core, logs := observer.New(zap.InfoLevel)
+	logger := observability.NewLoggerFromZap(zap.New(core))
+
+	const body = `{"email":"jane@example.com","password":"hunter2","remember":true}`
+	var received string
+	handler := LoggingMiddlewareWithOptions(logger, LoggingOptions{LogRequestBodies: true})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		received = string(data)
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/auth/login", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	if received != body {
+		t.Fatalf("handler should see the original body, got %q", received)
+	}
+	started := logs.FilterMessage("Request started").All()
+	if len(started) != 1 {
+		t.Fatalf("expected 1 request started entry, got %d", len(started))
+	}
+	logged, _ := started[0].ContextMap()["body"].(string)
+	if strings.Contains(logged, "hunter2") {
+		t.Fatalf("password leaked into the log: %s", logged)
+	}
+	if !strings.Contains(logged, `"password":"[REDACTED]"`) || !strings.Contains(logged, `"email":"jane@example.com"`) {
+		t.Fatalf("unexpected logged body %s", logged)
+	}
+}
+
+func TestLoggingMiddlewareOmitsBodiesByDefault(t *testing.T) {
+	core, logs := observer.New(zap.InfoLevel)
+	logger := observability.NewLoggerFromZap(zap.New(core))
+
+	handler := LoggingMiddleware(logger)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	req := httptest.NewRequest(http.MethodPost, "/auth/login", strings.NewReader(`{"password":"hunter2"}`))
+	req.Header.Set("Content-Type", "application/json")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	for _, entry := range logs.All() {
+		if _, ok := entry.ContextMap()["body"]; ok {
+			t.Fatalf("body logged without LogRequestBodies: %+v", entry.ContextMap())
+		}
+	}
+}
+
+func TestBodyRedactorMasksNestedAndFormFields(t *testing.T) {
+	redactor := NewBodyRedactor("iban")
+
+	json := redactor.Redact("application/json; charset=utf-8", []byte(`{"user":{"currentPassword":"a","new_password":"b"},"tokens":[{"refreshToken":"r"}],"customer":{"tax_number":"B123","IBAN":"ES00"},"amount":12.50}`))
+	for _, secret := range []string{`"a"`, `"b"`, `"r"`, "B123", "ES00"} {
+		if strings.Contains(json, secret) {
+			t.Fatalf("expected %s to be redacted in %s", secret, json)
+		}
+	}
+	if !strings.Contains(json, `"amount":12.50`) {
+		t.Fatalf("expected other fields to be kept as is: %s", json)
+	}
+
+	form := redactor.Redact("application/x-www-form-urlencoded", []byte("email=jane%40example.com&confirmationCode=123456"))
+	if strings.Contains(form, "123456") || !strings.Contains(form, "email=jane%40example.com") {
+		t.Fatalf("unexpected redacted form %s", form)
+	}
+
+	if got := redactor.Redact("text/plain", []byte("password=hunter2")); got != "" {
+		t.Fatalf("expected non JSON/form bodies to be omitted, got %q", got)
+	}
+}
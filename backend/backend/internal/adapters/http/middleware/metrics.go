@@ -2,6 +2,7 @@
 package middleware
 
 import (
+	"context"
 	"net/http"
 	"sync"
 	"time"
@@ -23,6 +24,31 @@ func initMetrics(provider metric.MeterProvider) {
 	requestDuration, _ = meter.Float64Histogram("http_server_request_duration_seconds")
 }
 
+// ModuleLabelKey is the context key of the module label filled in by RouteModuleMiddleware
+const ModuleLabelKey ContextKey = "module_label"
+
+// unlabeledModule is the module label of requests no module route handled
+const unlabeledModule = "none"
+
+// moduleLabel is shared between MetricsMiddleware and the module route group
+// that ends up serving the request.
+type moduleLabel struct {
+	name string
+}
+
+// RouteModuleMiddleware tags requests served by a module's routes so that
+// MetricsMiddleware can label them with the module name.
+func RouteModuleMiddleware(module string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if label, ok := r.Context().Value(ModuleLabelKey).(*moduleLabel); ok {
+				label.name = module
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
 // MetricsMiddleware records HTTP request metrics for Prometheus.
 func MetricsMiddleware(provider metric.MeterProvider) func(http.Handler) http.Handler {
 	metricsOnce.Do(func() { initMetrics(provider) })
@@ -32,6 +58,9 @@ func MetricsMiddleware(provider metric.MeterProvider) func(http.Handler) http.Ha
 			start := time.Now()
 			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
 
+			label := &moduleLabel{name: unlabeledModule}
+			r = r.WithContext(context.WithValue(r.Context(), ModuleLabelKey, label))
+
 			next.ServeHTTP(ww, r)
 
 			duration := time.Since(start).Seconds()
@@ -39,6 +68,7 @@ func MetricsMiddleware(provider metric.MeterProvider) func(http.Handler) http.Ha
 				attribute.String("method", r.Method),
 				attribute.String("path", r.URL.Path),
 				attribute.Int("status", ww.Status()),
+				attribute.String("module", label.name),
 			}
 
 			requestCounter.Add(r.Context(), 1, metric.WithAttributes(attrs...))
@@ -4,6 +4,8 @@ import (
 	"context"
 	"net/http"
 	"strconv"
+
+	"github.com/pmaojo/kthulu-go/backend/internal/domain/repository"
 )
 
 // OrganizationIDKey is the context key for organization ID
@@ -21,3 +23,49 @@ func OrganizationContextMiddleware(next http.Handler) http.Handler {
 		next.ServeHTTP(w, r)
 	})
 }
+
+// OrganizationMiddleware resolves the organization from the X-Organization-ID
+// header and makes sure the authenticated user belongs to it. It must run
+// after AuthMiddleware so the user ID is already in context.
+func OrganizationMiddleware(orgUsers repository.OrganizationUserRepository) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			logger := GetSugaredLogger(r.Context())
+
+			orgIDStr := r.Header.Get("X-Organization-ID")
+			if orgIDStr == "" {
+				logger.Warn("Missing X-Organization-ID header")
+				http.Error(w, "X-Organization-ID header is required", http.StatusBadRequest)
+				return
+			}
+			orgID, err := strconv.ParseUint(orgIDStr, 10, 32)
+			if err != nil || orgID == 0 {
+				logger.Warnw("Invalid X-Organization-ID header", "value", orgIDStr)
+				http.Error(w, "Invalid X-Organization-ID header", http.StatusBadRequest)
+				return
+			}
+
+			userID, err := GetUserID(r.Context())
+			if err != nil {
+				logger.Warn("Organization context requested without an authenticated user")
+				http.Error(w, "Unauthorized", http.StatusUnauthorized)
+				return
+			}
+
+			member, err := orgUsers.IsUserInOrganization(r.Context(), uint(orgID), userID)
+			if err != nil {
+				logger.Errorw("Failed to check organization membership", "organizationId", orgID, "userId", userID, "error", err)
+				http.Error(w, "Internal server error", http.StatusInternalServerError)
+				return
+			}
+			if !member {
+				logger.Warnw("User is not a member of the organization", "organizationId", orgID, "userId", userID)
+				http.Error(w, "Forbidden", http.StatusForbidden)
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), OrganizationIDKey, uint(orgID))
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
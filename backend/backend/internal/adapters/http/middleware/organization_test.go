@@ -0,0 +1,75 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pmaojo/kthulu-go/backend/internal/domain/repository"
+)
+
+// membershipRepository reports membership from a fixed set of organization/user pairs.
+type membershipRepository struct {
+	repository.OrganizationUserRepository
+	members map[[2]uint]bool
+}
+
+func (m *membershipRepository) IsUserInOrganization(ctx context.Context, organizationID, userID uint) (bool, error) {
+	return m.members[[2]uint{organizationID, userID}], nil
+}
+
+func TestOrganizationMiddleware(t *testing.T) {
+	orgUsers := &membershipRepository{members: map[[2]uint]bool{{7, 1}: true}}
+
+	var seenOrgID uint
+	handler := OrganizationMiddleware(orgUsers)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seenOrgID, _ = r.Context().Value(OrganizationIDKey).(uint)
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	tests := []struct {
+		name     string
+		header   string
+		wantCode int
+		wantOrg  uint
+	}{
+		{name: "member", header: "7", wantCode: http.StatusOK, wantOrg: 7},
+		{name: "non-member", header: "8", wantCode: http.StatusForbidden},
+		{name: "missing header", header: "", wantCode: http.StatusBadRequest},
+		{name: "invalid header", header: "acme", wantCode: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			seenOrgID = 0
+			req := httptest.NewRequest(http.MethodGet, "/contacts", nil)
+			req = req.WithContext(context.WithValue(req.Context(), UserIDKey, uint(1)))
+			if tt.header != "" {
+				req.Header.Set("X-Organization-ID", tt.header)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			if w.Code != tt.wantCode {
+				t.Fatalf("expected %d, got %d", tt.wantCode, w.Code)
+			}
+			if seenOrgID != tt.wantOrg {
+				t.Fatalf("expected organization %d in context, got %d", tt.wantOrg, seenOrgID)
+			}
+		})
+	}
+}
+
+func TestOrganizationMiddleware_RequiresAuthenticatedUser(t *testing.T) {
+	handler := OrganizationMiddleware(&membershipRepository{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Fatalf("handler should not be called")
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/contacts", nil)
+	req.Header.Set("X-Organization-ID", "7")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401, got %d", w.Code)
+	}
+}
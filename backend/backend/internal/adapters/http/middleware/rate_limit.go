@@ -2,22 +2,64 @@
 package middleware
 
 import (
+	"encoding/json"
+	"math"
 	"net/http"
+	"strconv"
+	"time"
 
 	"golang.org/x/time/rate"
 )
 
+// RateLimitErrorCode identifies rate limited responses
+const RateLimitErrorCode = "rate_limit_exceeded"
+
+// RateLimitResponse is the body of a rate limited response
+type RateLimitResponse struct {
+	Error             string `json:"error"`
+	Code              string `json:"code"`
+	RetryAfterSeconds int    `json:"retryAfterSeconds"`
+}
+
 // RateLimitMiddleware creates a middleware that limits the number of
 // incoming requests using the provided rate limiter.
-// If the limit is exceeded, the middleware responds with HTTP 429.
+// If the limit is exceeded, the middleware responds with HTTP 429, a
+// Retry-After header and a RateLimitResponse body telling the client how
+// long to wait.
 func RateLimitMiddleware(limiter *rate.Limiter) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			if !limiter.Allow() {
-				http.Error(w, http.StatusText(http.StatusTooManyRequests), http.StatusTooManyRequests)
+			reservation := limiter.Reserve()
+			if !reservation.OK() {
+				writeRateLimited(w, 0)
+				return
+			}
+			if delay := reservation.Delay(); delay > 0 {
+				// Give the token back, the request is rejected rather than delayed
+				reservation.Cancel()
+				writeRateLimited(w, delay)
 				return
 			}
 			next.ServeHTTP(w, r)
 		})
 	}
 }
+
+// writeRateLimited writes a 429 asking the client to retry after delay,
+// rounded up to whole seconds. A zero delay means the request can never be
+// served by the limiter, and no Retry-After header is sent.
+func writeRateLimited(w http.ResponseWriter, delay time.Duration) {
+	seconds := 0
+	if delay > 0 {
+		seconds = int(math.Ceil(delay.Seconds()))
+		w.Header().Set("Retry-After", strconv.Itoa(seconds))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusTooManyRequests)
+	json.NewEncoder(w).Encode(RateLimitResponse{
+		Error:             http.StatusText(http.StatusTooManyRequests),
+		Code:              RateLimitErrorCode,
+		RetryAfterSeconds: seconds,
+	})
+}
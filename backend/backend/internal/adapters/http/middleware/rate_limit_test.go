@@ -1,9 +1,12 @@
 package middleware
 
 import (
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"testing"
+	"time"
 
 	"golang.org/x/time/rate"
 )
@@ -30,3 +33,44 @@ func TestRateLimitMiddleware(t *testing.T) {
 		t.Fatalf("expected status %d, got %d", http.StatusTooManyRequests, rr2.Code)
 	}
 }
+
+func TestRateLimitMiddlewareRetryAfter(t *testing.T) {
+	// One token every four seconds, so the exhausted limiter needs about 4s
+	limiter := rate.NewLimiter(rate.Every(4*time.Second), 2)
+	handler := RateLimitMiddleware(limiter)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	for i := 0; i < 2; i++ {
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/", nil))
+		if rr.Code != http.StatusOK {
+			t.Fatalf("request %d: expected status %d, got %d", i+1, http.StatusOK, rr.Code)
+		}
+	}
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rr.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected status %d, got %d", http.StatusTooManyRequests, rr.Code)
+	}
+	retryAfter, err := strconv.Atoi(rr.Header().Get("Retry-After"))
+	if err != nil || retryAfter < 3 || retryAfter > 4 {
+		t.Fatalf("expected Retry-After of about 4 seconds, got %q", rr.Header().Get("Retry-After"))
+	}
+
+	var body RateLimitResponse
+	if err := json.NewDecoder(rr.Body).Decode(&body); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if body.Code != RateLimitErrorCode || body.RetryAfterSeconds != retryAfter {
+		t.Fatalf("unexpected body %+v for Retry-After %d", body, retryAfter)
+	}
+
+	// Rejected requests must not consume tokens and push the wait further out
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/", nil))
+	if got, _ := strconv.Atoi(rr.Header().Get("Retry-After")); got > retryAfter {
+		t.Fatalf("Retry-After grew from %d to %d after a rejected request", retryAfter, got)
+	}
+}
@@ -0,0 +1,132 @@
+// @kthulu:core
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// DefaultRedactedFields are masked in every logged request body
+var DefaultRedactedFields = []string{"password", "confirmationCode", "refreshToken", "accessToken", "taxNumber"}
+
+// RedactedValue replaces the value of sensitive fields
+const RedactedValue = "[REDACTED]"
+
+// maxLoggedBodySize bounds how much of a request body is read for logging
+const maxLoggedBodySize = 16 << 10
+
+// BodyRedactor masks sensitive fields of request bodies before they are
+// logged. Field names match case-insensitively, ignoring "_" and "-", and a
+// key matches when it contains a field name, so "password" also masks
+// "currentPassword" and "new_password".
+type BodyRedactor struct {
+	fields []string
+}
+
+// NewBodyRedactor creates a redactor for DefaultRedactedFields plus extra
+func NewBodyRedactor(extra ...string) *BodyRedactor {
+	r := &BodyRedactor{}
+	for _, field := range append(append([]string{}, DefaultRedactedFields...), extra...) {
+		if field = normalizeFieldName(field); field != "" {
+			r.fields = append(r.fields, field)
+		}
+	}
+	return r
+}
+
+// Redact returns body as it may be logged. JSON and form bodies are logged
+// with sensitive fields masked; other bodies, and bodies that cannot be
+// parsed, are omitted.
+func (b *BodyRedactor) Redact(contentType string, body []byte) string {
+	if len(bytes.TrimSpace(body)) == 0 {
+		return ""
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch {
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		var value interface{}
+		decoder := json.NewDecoder(bytes.NewReader(body))
+		decoder.UseNumber()
+		if err := decoder.Decode(&value); err != nil {
+			return "[unparseable body omitted]"
+		}
+		redacted, err := json.Marshal(b.redactValue(value))
+		if err != nil {
+			return "[unparseable body omitted]"
+		}
+		return string(redacted)
+	case mediaType == "application/x-www-form-urlencoded":
+		values, err := url.ParseQuery(string(body))
+		if err != nil {
+			return "[unparseable body omitted]"
+		}
+		for key := range values {
+			if b.isSensitive(key) {
+				values[key] = []string{RedactedValue}
+			}
+		}
+		return values.Encode()
+	default:
+		return ""
+	}
+}
+
+func (b *BodyRedactor) redactValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			if b.isSensitive(key) {
+				v[key] = RedactedValue
+			} else {
+				v[key] = b.redactValue(child)
+			}
+		}
+	case []interface{}:
+		for i, child := range v {
+			v[i] = b.redactValue(child)
+		}
+	}
+	return value
+}
+
+func (b *BodyRedactor) isSensitive(key string) bool {
+	key = normalizeFieldName(key)
+	for _, field := range b.fields {
+		if strings.Contains(key, field) {
+			return true
+		}
+	}
+	return false
+}
+
+func normalizeFieldName(name string) string {
+	return strings.ToLower(strings.NewReplacer("_", "", "-", "").Replace(strings.TrimSpace(name)))
+}
+
+// peekRequestBody reads up to maxLoggedBodySize bytes of the request body and
+// puts them back so handlers still see the whole body. The second result is
+// false when the body is larger than what was read.
+func peekRequestBody(r *http.Request) ([]byte, bool) {
+	if r.Body == nil || r.Body == http.NoBody {
+		return nil, true
+	}
+
+	peeked, err := io.ReadAll(io.LimitReader(r.Body, maxLoggedBodySize+1))
+	r.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(peeked), r.Body), r.Body}
+	if err != nil {
+		return nil, false
+	}
+	if len(peeked) > maxLoggedBodySize {
+		return nil, false
+	}
+	return peeked, true
+}
@@ -2,20 +2,40 @@ package middleware
 
 import (
 	"context"
+	"encoding/hex"
 	"net/http"
+	"strings"
 
+	"github.com/google/uuid"
 	"go.opentelemetry.io/otel/trace"
 )
 
-// TraceIDMiddleware stores the current trace ID in the request context.
+// TraceIDHeader echoes the request's trace ID on every response
+const TraceIDHeader = "X-Trace-Id"
+
+// TraceIDMiddleware stores the current trace ID in the request context and
+// echoes it in the X-Trace-Id response header. Requests without a valid span
+// get a random trace ID so errors can still be matched with the logs.
 func TraceIDMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		span := trace.SpanFromContext(r.Context())
-		if span.SpanContext().IsValid() {
-			traceID := span.SpanContext().TraceID().String()
-			ctx := context.WithValue(r.Context(), TraceIDKey, traceID)
-			r = r.WithContext(ctx)
+		var traceID string
+		if span := trace.SpanFromContext(r.Context()); span.SpanContext().IsValid() {
+			traceID = span.SpanContext().TraceID().String()
+		} else {
+			traceID = generateTraceID()
 		}
-		next.ServeHTTP(w, r)
+
+		w.Header().Set(TraceIDHeader, traceID)
+		ctx := context.WithValue(r.Context(), TraceIDKey, traceID)
+		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
+
+// generateTraceID returns a random 16 byte trace ID in W3C hex form
+func generateTraceID() string {
+	var id trace.TraceID
+	if _, err := randRead(id[:]); err != nil {
+		return strings.ReplaceAll(uuid.NewString(), "-", "")
+	}
+	return hex.EncodeToString(id[:])
+}
@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTraceIDMiddlewareEchoesSpanTraceID(t *testing.T) {
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	spanCtx := trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceID, SpanID: spanID})
+
+	var fromContext string
+	handler := TraceIDMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fromContext = GetTraceID(r.Context())
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req = req.WithContext(trace.ContextWithSpanContext(context.Background(), spanCtx))
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	if got := w.Header().Get(TraceIDHeader); got != traceID.String() {
+		t.Fatalf("expected %s header %s, got %q", TraceIDHeader, traceID, got)
+	}
+	if fromContext != traceID.String() {
+		t.Fatalf("expected trace ID %s in context, got %q", traceID, fromContext)
+	}
+}
+
+func TestTraceIDMiddlewareGeneratesTraceIDWithoutSpan(t *testing.T) {
+	var fromContext string
+	handler := TraceIDMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fromContext = GetTraceID(r.Context())
+	}))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	header := w.Header().Get(TraceIDHeader)
+	if _, err := trace.TraceIDFromHex(header); err != nil {
+		t.Fatalf("expected a generated trace ID, got %q: %v", header, err)
+	}
+	if fromContext != header {
+		t.Fatalf("context trace ID %q does not match header %q", fromContext, header)
+	}
+}
@@ -4,7 +4,6 @@ package adapterhttp
 import (
 	"encoding/json"
 	"net/http"
-	"strconv"
 
 	"github.com/go-chi/chi/v5"
 	"go.uber.org/zap"
@@ -51,23 +50,7 @@ func (h *ModuleHandler) listModules(w http.ResponseWriter, r *http.Request) {
 	logger := h.log
 
 	category := r.URL.Query().Get("category")
-	limitStr := r.URL.Query().Get("limit")
-	offsetStr := r.URL.Query().Get("offset")
-
-	limit := 50 // default
-	offset := 0 // default
-
-	if limitStr != "" {
-		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 && l <= 100 {
-			limit = l
-		}
-	}
-
-	if offsetStr != "" {
-		if o, err := strconv.Atoi(offsetStr); err == nil && o >= 0 {
-			offset = o
-		}
-	}
+	limit, offset := parseLimitOffset(r, 50)
 
 	var categoryPtr *string
 	if category != "" {
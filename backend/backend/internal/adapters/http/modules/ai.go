@@ -34,7 +34,7 @@ var AIModule = fx.Options(
 		adapterhttp.NewAIHandler,
 	),
 	fx.Invoke(func(handler *adapterhttp.AIHandler, registry *RouteRegistry) {
-		registry.Register(handler)
+		registry.RegisterModule("ai", handler)
 	}),
 	fx.Invoke(func(lc fx.Lifecycle, client ai.Client) {
 		lc.Append(fx.Hook{OnStop: func(ctx context.Context) error {
@@ -13,17 +13,24 @@ import (
 var AuthModule = fx.Options(
 	// Use cases
 	fx.Provide(
-		usecase.NewAuthUseCase,
+		// Confirmation emails name the inviting organization when the
+		// organization repositories are loaded
+		fx.Annotate(
+			usecase.NewAuthUseCase,
+			fx.ParamTags(``, ``, ``, ``, ``, ``, ``, `optional:"true"`, `optional:"true"`),
+		),
 		usecase.NewAuthService,
 	),
 
 	// HTTP handlers
 	fx.Provide(
 		adapterhttp.NewAuthHandler,
+		adapterhttp.NewAuthEventHandler,
 	),
 
 	// Register routes
-	fx.Invoke(func(handler *adapterhttp.AuthHandler, registry *RouteRegistry) {
-		registry.Register(handler)
+	fx.Invoke(func(handler *adapterhttp.AuthHandler, events *adapterhttp.AuthEventHandler, registry *RouteRegistry) {
+		registry.RegisterModule("auth", handler)
+		registry.RegisterModule("auth", events)
 	}),
 )
@@ -16,11 +16,14 @@ var BuiltinModules = map[string]fx.Option{
 	"contact":      ContactModule,
 	"product":      ProductModule,
 	"invoice":      InvoiceModule,
+	"payments":     PaymentsModule,
 	"inventory":    InventoryModule,
 	"calendar":     CalendarModule,
 	"realtime":     RealtimeModule,
 	"static":       StaticModule,
 	"verifactu":    VerifactuModule,
+	"graphql":      GraphQLModule,
+	"grpc":         GRPCModule,
 	"oauth-sso":    OAuthSSOModule,
 	"secure":       SecureModule,
 	"flags":        FlagsModule,
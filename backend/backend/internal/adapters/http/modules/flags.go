@@ -1,11 +1,22 @@
 package modules
 
 import (
+	adapterhttp "github.com/pmaojo/kthulu-go/backend/internal/adapters/http"
 	flagcfg "github.com/pmaojo/kthulu-go/backend/internal/adapters/http/modules/flags"
 	"go.uber.org/fx"
 )
 
-// FlagsModule provides configuration for request flags.
+// FlagsModule provides configuration for request flags and the flag
+// evaluation endpoint.
 var FlagsModule = fx.Options(
-	fx.Provide(flagcfg.LoadHeaderConfig),
+	fx.Provide(
+		flagcfg.LoadHeaderConfig,
+		flagcfg.LoadFlagRules,
+		flagcfg.NewFlagEvaluator,
+		adapterhttp.NewFlagsHandler,
+	),
+
+	fx.Invoke(func(handler *adapterhttp.FlagsHandler, registry *RouteRegistry) {
+		registry.Register(handler)
+	}),
 )
@@ -0,0 +1,116 @@
+package flags
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFlagEvaluatorBooleanFlag(t *testing.T) {
+	evaluator, err := NewFlagEvaluator(FlagRules{
+		"new_dashboard": {Enabled: true},
+		"beta_reports":  {Enabled: false},
+	})
+	if err != nil {
+		t.Fatalf("new evaluator: %v", err)
+	}
+
+	for _, principal := range []Principal{{}, {UserID: 7}, {UserID: 8, OrganizationID: 3}} {
+		if !evaluator.IsEnabled("new_dashboard", principal) {
+			t.Errorf("expected new_dashboard enabled for %+v", principal)
+		}
+		if evaluator.IsEnabled("beta_reports", principal) {
+			t.Errorf("expected beta_reports disabled for %+v", principal)
+		}
+	}
+	if evaluator.IsEnabled("unknown", Principal{UserID: 7}) {
+		t.Errorf("expected unknown flags to be disabled")
+	}
+}
+
+func TestFlagEvaluatorPercentageRolloutIsStable(t *testing.T) {
+	half := 50.0
+	evaluator, err := NewFlagEvaluator(FlagRules{"checkout_v2": {Enabled: true, Percentage: &half}})
+	if err != nil {
+		t.Fatalf("new evaluator: %v", err)
+	}
+
+	enabled := 0
+	for userID := uint(1); userID <= 1000; userID++ {
+		principal := Principal{UserID: userID}
+		first := evaluator.IsEnabled("checkout_v2", principal)
+		for i := 0; i < 5; i++ {
+			if evaluator.IsEnabled("checkout_v2", principal) != first {
+				t.Fatalf("rollout changed between evaluations for user %d", userID)
+			}
+		}
+		// A fresh evaluator with the same rules gives the same answer
+		again, _ := NewFlagEvaluator(FlagRules{"checkout_v2": {Enabled: true, Percentage: &half}})
+		if again.IsEnabled("checkout_v2", principal) != first {
+			t.Fatalf("rollout differs across evaluators for user %d", userID)
+		}
+		if first {
+			enabled++
+		}
+	}
+	if enabled < 400 || enabled > 600 {
+		t.Fatalf("expected about half of 1000 users in a 50%% rollout, got %d", enabled)
+	}
+	if evaluator.IsEnabled("checkout_v2", Principal{}) {
+		t.Fatalf("anonymous principals should be outside percentage rollouts")
+	}
+}
+
+func TestFlagEvaluatorOrganizationRollout(t *testing.T) {
+	all, none := 100.0, 0.0
+	evaluator, err := NewFlagEvaluator(FlagRules{
+		"org_everyone": {Enabled: true, Percentage: &all, RolloutBy: RolloutByOrganization},
+		"org_nobody":   {Enabled: true, Percentage: &none, RolloutBy: RolloutByOrganization},
+	})
+	if err != nil {
+		t.Fatalf("new evaluator: %v", err)
+	}
+
+	flags := evaluator.Evaluate(Principal{UserID: 1, OrganizationID: 9})
+	if !flags["org_everyone"] || flags["org_nobody"] {
+		t.Fatalf("unexpected evaluation %v", flags)
+	}
+	if evaluator.IsEnabled("org_everyone", Principal{UserID: 1}) {
+		t.Fatalf("organization rollouts need an organization")
+	}
+}
+
+func TestNewFlagEvaluatorRejectsInvalidRules(t *testing.T) {
+	tooMuch := 120.0
+	if _, err := NewFlagEvaluator(FlagRules{"x": {Enabled: true, Percentage: &tooMuch}}); err == nil {
+		t.Fatalf("expected error for percentage above 100")
+	}
+	if _, err := NewFlagEvaluator(FlagRules{"x": {Enabled: true, RolloutBy: "team"}}); err == nil {
+		t.Fatalf("expected error for unknown rollout key")
+	}
+}
+
+func TestLoadFlagRulesFrom(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "flags.yml")
+	content := "new_dashboard:\n  enabled: true\ncheckout_v2:\n  enabled: true\n  percentage: 25\n  rolloutBy: organization\n"
+	if err := os.WriteFile(file, []byte(content), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	rules, err := LoadFlagRulesFrom(file)
+	if err != nil {
+		t.Fatalf("load rules: %v", err)
+	}
+	if !rules["new_dashboard"].Enabled || rules["new_dashboard"].Percentage != nil {
+		t.Errorf("unexpected new_dashboard rule %+v", rules["new_dashboard"])
+	}
+	rollout := rules["checkout_v2"]
+	if rollout.Percentage == nil || *rollout.Percentage != 25 || rollout.RolloutBy != RolloutByOrganization {
+		t.Errorf("unexpected checkout_v2 rule %+v", rollout)
+	}
+
+	missing, err := LoadFlagRulesFrom(filepath.Join(t.TempDir(), "missing.yml"))
+	if err != nil || len(missing) != 0 {
+		t.Fatalf("expected no rules for a missing file, got %v, %v", missing, err)
+	}
+}
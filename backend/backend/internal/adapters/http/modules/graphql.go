@@ -0,0 +1,23 @@
+// @kthulu:module:graphql
+package modules
+
+import (
+	"go.uber.org/fx"
+
+	adapterhttp "github.com/pmaojo/kthulu-go/backend/internal/adapters/http"
+)
+
+// GraphQLModule exposes read-only GraphQL queries over the contact, invoice
+// and product use cases. Load it alongside those modules; their types are
+// left out of the schema when they aren't loaded.
+var GraphQLModule = fx.Options(
+	// HTTP handlers
+	fx.Provide(
+		adapterhttp.NewGraphQLHandler,
+	),
+
+	// Register routes
+	fx.Invoke(func(handler *adapterhttp.GraphQLHandler, registry *RouteRegistry) {
+		registry.RegisterModule("graphql", handler)
+	}),
+)
@@ -0,0 +1,15 @@
+// @kthulu:module:grpc
+package modules
+
+import (
+	"go.uber.org/fx"
+
+	adaptergrpc "github.com/pmaojo/kthulu-go/backend/internal/adapters/grpc"
+)
+
+// GRPCModule serves the gRPC services of the loaded modules on GRPC_ADDR,
+// next to the HTTP server. Load it alongside the invoice module; services
+// whose modules aren't loaded are not registered.
+var GRPCModule = fx.Options(
+	adaptergrpc.Module,
+)
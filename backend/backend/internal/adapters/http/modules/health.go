@@ -7,15 +7,17 @@ import (
 	adapterhttp "github.com/pmaojo/kthulu-go/backend/internal/adapters/http"
 )
 
-// HealthModule provides health check functionality
+// HealthModule provides health check functionality and the JWKS endpoint
 var HealthModule = fx.Options(
 	// HTTP handlers
 	fx.Provide(
 		adapterhttp.NewHealthHandler,
+		adapterhttp.NewJWKSHandler,
 	),
 
 	// Register routes
-	fx.Invoke(func(handler *adapterhttp.HealthHandler, registry *RouteRegistry) {
+	fx.Invoke(func(handler *adapterhttp.HealthHandler, jwks *adapterhttp.JWKSHandler, registry *RouteRegistry) {
 		registry.Register(handler)
+		registry.Register(jwks)
 	}),
 )
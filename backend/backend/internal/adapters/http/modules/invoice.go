@@ -4,7 +4,12 @@ package modules
 import (
 	"go.uber.org/fx"
 
+	"github.com/pmaojo/kthulu-go/backend/core"
 	adapterhttp "github.com/pmaojo/kthulu-go/backend/internal/adapters/http"
+	"github.com/pmaojo/kthulu-go/backend/internal/domain"
+	"github.com/pmaojo/kthulu-go/backend/internal/domain/money"
+	"github.com/pmaojo/kthulu-go/backend/internal/domain/repository"
+	"github.com/pmaojo/kthulu-go/backend/internal/infrastructure/exchangerate"
 	"github.com/pmaojo/kthulu-go/backend/internal/usecase"
 )
 
@@ -13,7 +18,14 @@ import (
 var InvoiceModule = fx.Options(
 	// Use cases
 	fx.Provide(
-		usecase.NewInvoiceUseCase,
+		newRoundingPolicy,
+		newExchangeRateProvider,
+		newTaxCalculator,
+		// In-app notifications are pushed when the realtime module is loaded
+		fx.Annotate(
+			usecase.NewInvoiceUseCase,
+			fx.ParamTags(``, ``, ``, ``, ``, ``, ``, ``, `optional:"true"`),
+		),
 	),
 
 	// HTTP handlers
@@ -23,6 +35,32 @@ var InvoiceModule = fx.Options(
 
 	// Register routes
 	fx.Invoke(func(handler *adapterhttp.InvoiceHandler, registry *RouteRegistry) {
-		registry.Register(handler)
+		registry.RegisterModule("invoices", handler)
 	}),
 )
+
+// newRoundingPolicy builds the invoice rounding policy from the configuration
+func newRoundingPolicy(cfg *core.Config) (money.Policy, error) {
+	mode, err := money.ParseRoundingMode(cfg.Money.RoundingMode)
+	if err != nil {
+		return money.Policy{}, err
+	}
+	return money.NewPolicy(mode, cfg.Money.CurrencyPrecision)
+}
+
+// newExchangeRateProvider builds the cached rates API client, or nil when no
+// rates API is configured
+func newExchangeRateProvider(cfg *core.Config) repository.ExchangeRateProvider {
+	if cfg.ExchangeRates.URL == "" {
+		return nil
+	}
+	return exchangerate.NewCachedProvider(exchangerate.NewHTTPProvider(cfg.ExchangeRates.URL, cfg.ExchangeRates.BaseCurrency))
+}
+
+// newTaxCalculator builds the invoice tax calculator from the configuration
+func newTaxCalculator(cfg *core.Config) domain.TaxCalculator {
+	if cfg.Tax.Calculator == "eu" {
+		return domain.EUVATCalculator{CategoryRates: cfg.Tax.CategoryRates}
+	}
+	return domain.FlatRateTaxCalculator{}
+}
@@ -14,7 +14,13 @@ var Module = fx.Options(
 	fx.Provide(
 		fx.Annotate(
 			db.NewInvoiceRepository,
+			fx.ParamTags(``, ``, `optional:"true"`, `optional:"true"`),
 			fx.As(new(repository.InvoiceRepository)),
 		),
+		fx.Annotate(
+			db.NewUnitOfWork,
+			fx.ParamTags(``, ``, ``, `optional:"true"`),
+			fx.As(new(repository.UnitOfWork)),
+		),
 	),
 )
@@ -105,7 +105,7 @@ func (b *ModuleSetBuilder) WithModule(name string) *ModuleSetBuilder {
 }
 
 var coreModuleNames = []string{"health", "oauth-sso", "user", "access", "notifier", "static", "flags"}
-var erpModuleNames = []string{"organization", "contact", "product", "invoice", "inventory", "calendar", "realtime", "verifactu"}
+var erpModuleNames = []string{"organization", "contact", "product", "invoice", "inventory", "calendar", "realtime", "verifactu", "graphql"}
 
 func init() {
 	if os.Getenv("LEGACY_AUTH") == "true" {
@@ -121,7 +121,7 @@ func (b *ModuleSetBuilder) WithCoreModules() *ModuleSetBuilder {
 	return b
 }
 
-// WithERPModules adds all ERP-lite modules (org, contacts, products, invoices, inventory, calendar).
+// WithERPModules adds all ERP-lite modules (org, contacts, products, invoices, inventory, calendar, graphql).
 func (b *ModuleSetBuilder) WithERPModules() *ModuleSetBuilder {
 	for _, name := range erpModuleNames {
 		b.WithModule(name)
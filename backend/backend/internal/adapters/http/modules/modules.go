@@ -28,6 +28,6 @@ var ModulesModule = fx.Options(
 
 	// Register routes
 	fx.Invoke(func(handler *adapterhttp.ModuleHandler, registry *RouteRegistry) {
-		registry.Register(handler)
+		registry.RegisterModule("modules", handler)
 	}),
 )
@@ -13,16 +13,23 @@ import (
 var OrganizationModule = fx.Options(
 	// Use cases
 	fx.Provide(
-		usecase.NewOrganizationUseCase,
+		// In-app notifications are pushed when the realtime module is loaded;
+		// email templates come with the notifier
+		fx.Annotate(
+			usecase.NewOrganizationUseCase,
+			fx.ParamTags(``, ``, ``, ``, ``, ``, `optional:"true"`, `optional:"true"`),
+		),
 	),
 
 	// HTTP handlers
 	fx.Provide(
 		adapterhttp.NewOrganizationHandler,
+		adapterhttp.NewBlobHandler,
 	),
 
-	// Register routes
-	fx.Invoke(func(handler *adapterhttp.OrganizationHandler, registry *RouteRegistry) {
-		registry.Register(handler)
+	// Register routes; the blob handler serves the logos stored locally
+	fx.Invoke(func(handler *adapterhttp.OrganizationHandler, blobs *adapterhttp.BlobHandler, registry *RouteRegistry) {
+		registry.RegisterModule("org", handler)
+		registry.RegisterModule("org", blobs)
 	}),
 )
@@ -0,0 +1,44 @@
+// @kthulu:module:payments
+package modules
+
+import (
+	"errors"
+
+	"go.uber.org/fx"
+
+	"github.com/pmaojo/kthulu-go/backend/core"
+	adapterhttp "github.com/pmaojo/kthulu-go/backend/internal/adapters/http"
+	"github.com/pmaojo/kthulu-go/backend/internal/domain/money"
+	"github.com/pmaojo/kthulu-go/backend/internal/domain/repository"
+	"github.com/pmaojo/kthulu-go/backend/internal/infrastructure/payment"
+	"github.com/pmaojo/kthulu-go/backend/internal/usecase"
+)
+
+// PaymentsModule lets contacts pay invoices online through Stripe. Load it
+// alongside the invoice module.
+var PaymentsModule = fx.Options(
+	// Gateway and use cases
+	fx.Provide(
+		newPaymentGateway,
+		usecase.NewOnlinePaymentUseCase,
+	),
+
+	// HTTP handlers
+	fx.Provide(
+		adapterhttp.NewPaymentGatewayHandler,
+	),
+
+	// Register routes
+	fx.Invoke(func(handler *adapterhttp.PaymentGatewayHandler, registry *RouteRegistry) {
+		registry.RegisterModule("payments", handler)
+	}),
+)
+
+// newPaymentGateway builds the Stripe gateway from the configuration
+func newPaymentGateway(cfg *core.Config, rounding money.Policy) (repository.PaymentGateway, error) {
+	stripe := cfg.Payments.Stripe
+	if stripe.SecretKey == "" || stripe.WebhookSecret == "" {
+		return nil, errors.New("payments module requires STRIPE_SECRET_KEY and STRIPE_WEBHOOK_SECRET")
+	}
+	return payment.NewStripeGateway(stripe, rounding), nil
+}
@@ -14,6 +14,7 @@ var Module = fx.Options(
 	fx.Provide(
 		fx.Annotate(
 			db.NewProductRepository,
+			fx.ParamTags(``, ``, `optional:"true"`, `optional:"true"`),
 			fx.As(new(repository.ProductRepository)),
 		),
 	),
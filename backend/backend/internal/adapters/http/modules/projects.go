@@ -28,6 +28,6 @@ var ProjectsModule = fx.Options(
 
 	// Register routes
 	fx.Invoke(func(handler *adapterhttp.ProjectHandler, registry *RouteRegistry) {
-		registry.Register(handler)
+		registry.RegisterModule("projects", handler)
 	}),
 )
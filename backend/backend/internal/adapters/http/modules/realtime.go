@@ -19,6 +19,6 @@ var RealtimeModule = fx.Options(
 		adapterhttp.NewRealtimeHandler,
 	),
 	fx.Invoke(func(h *adapterhttp.RealtimeHandler, rr *RouteRegistry) {
-		rr.Register(h)
+		rr.RegisterModule("realtime", h)
 	}),
 )
@@ -6,43 +6,63 @@ import (
 
 	"github.com/go-chi/chi/v5"
 	"go.uber.org/zap"
+
+	"github.com/pmaojo/kthulu-go/backend/internal/adapters/http/middleware"
 )
 
+// coreModule labels registrars that were not registered on behalf of a module
+const coreModule = "core"
+
+// moduleRegistrar is a route registrar together with the module that owns it
+type moduleRegistrar struct {
+	module    string
+	registrar RouteRegistrar
+}
+
 // RouteRegistry manages route registration from multiple modules
 type RouteRegistry struct {
-	registrars []RouteRegistrar
+	registrars []moduleRegistrar
 	logger     *zap.Logger
 }
 
 // NewRouteRegistry creates a new route registry
 func NewRouteRegistry(logger *zap.Logger) *RouteRegistry {
 	return &RouteRegistry{
-		registrars: make([]RouteRegistrar, 0),
+		registrars: make([]moduleRegistrar, 0),
 		logger:     logger,
 	}
 }
 
-// Register adds a route registrar to the registry
+// Register adds a route registrar to the registry under the core module
 func (rr *RouteRegistry) Register(registrar RouteRegistrar) {
+	rr.RegisterModule(coreModule, registrar)
+}
+
+// RegisterModule adds a route registrar owned by the given module. Requests
+// served by its routes are labeled with the module name in HTTP metrics.
+func (rr *RouteRegistry) RegisterModule(module string, registrar RouteRegistrar) {
 	if registrar == nil {
-		rr.logger.Warn("nil route registrar provided")
+		rr.logger.Warn("nil route registrar provided", zap.String("module", module))
 		return
 	}
-	rr.registrars = append(rr.registrars, registrar)
-	rr.logger.Info("Route registrar added", zap.String("type", getTypeName(registrar)))
+	rr.registrars = append(rr.registrars, moduleRegistrar{module: module, registrar: registrar})
+	rr.logger.Info("Route registrar added", zap.String("type", getTypeName(registrar)), zap.String("module", module))
 }
 
 // RegisterAllRoutes registers all routes from all registered handlers
 func (rr *RouteRegistry) RegisterAllRoutes(r chi.Router) {
 	rr.logger.Info("Registering routes from all modules", zap.Int("count", len(rr.registrars)))
 
-	for _, registrar := range rr.registrars {
-		if registrar == nil {
+	for _, entry := range rr.registrars {
+		if entry.registrar == nil {
 			rr.logger.Warn("nil route registrar skipped")
 			continue
 		}
-		registrar.RegisterRoutes(r)
-		rr.logger.Debug("Routes registered", zap.String("handler", getTypeName(registrar)))
+		r.Group(func(r chi.Router) {
+			r.Use(middleware.RouteModuleMiddleware(entry.module))
+			entry.registrar.RegisterRoutes(r)
+		})
+		rr.logger.Debug("Routes registered", zap.String("handler", getTypeName(entry.registrar)), zap.String("module", entry.module))
 	}
 }
 
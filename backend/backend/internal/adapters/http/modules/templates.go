@@ -29,6 +29,6 @@ var TemplatesModule = fx.Options(
 
 	// Register routes
 	fx.Invoke(func(handler *adapterhttp.TemplateHandler, registry *RouteRegistry) {
-		registry.Register(handler)
+		registry.RegisterModule("templates", handler)
 	}),
 )
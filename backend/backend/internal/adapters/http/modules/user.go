@@ -23,6 +23,6 @@ var UserModule = fx.Options(
 
 	// Register routes
 	fx.Invoke(func(handler *adapterhttp.UserHandler, registry *RouteRegistry) {
-		registry.Register(handler)
+		registry.RegisterModule("user", handler)
 	}),
 )
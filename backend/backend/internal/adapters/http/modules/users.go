@@ -16,6 +16,6 @@ var UsersModule = fx.Options(
 		adapterhttp.NewUsersHandler,
 	),
 	fx.Invoke(func(h *adapterhttp.UsersHandler, rr *RouteRegistry) {
-		rr.Register(h)
+		rr.RegisterModule("users", h)
 	}),
 )
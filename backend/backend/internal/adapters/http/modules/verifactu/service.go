@@ -9,6 +9,7 @@ import (
 	"crypto/sha256"
 	"encoding/csv"
 	"encoding/json"
+	"encoding/xml"
 	"errors"
 	"fmt"
 	"strconv"
@@ -25,6 +26,10 @@ type Record struct {
 	SIFCode          string    `json:"sifCode"`
 	Hash             string    `json:"hash"`
 	CreatedAt        time.Time `json:"createdAt"`
+	// PreviousHash and ChainHash are only populated on exported records and
+	// link every record to the one exported before it.
+	PreviousHash string `json:"previousHash,omitempty"`
+	ChainHash    string `json:"chainHash,omitempty"`
 }
 
 // Repository defines the storage behavior required by the service.
@@ -39,8 +44,22 @@ type Repository interface {
 	GetLastHash(ctx context.Context, orgID int) (string, error)
 	// GetLiveMode returns if live mode is active for the given fiscal year.
 	GetLiveMode(ctx context.Context, year int) (bool, error)
-	// SetLiveMode persists the live mode flag for the given fiscal year.
-	SetLiveMode(ctx context.Context, year int, live bool) error
+	// SetLiveMode persists the live mode flag for the given fiscal year,
+	// recording what triggered the change. Setting the current value is a no-op.
+	SetLiveMode(ctx context.Context, year int, live bool, triggeredBy string) error
+	// GetLiveModeStatus returns the live mode flag and its last transition.
+	GetLiveModeStatus(ctx context.Context, year int) (*LiveModeStatus, error)
+	// SaveExportChainHash stores the last chain hash produced by an export.
+	SaveExportChainHash(ctx context.Context, orgID int, hash string) error
+}
+
+// LiveModeStatus describes the live mode state of a fiscal year and the
+// last recorded transition, if any.
+type LiveModeStatus struct {
+	Year        int        `json:"year"`
+	Live        bool       `json:"live"`
+	ChangedAt   *time.Time `json:"changedAt,omitempty"`
+	TriggeredBy string     `json:"triggeredBy,omitempty"`
 }
 
 // Signer defines signing capabilities for generated exports.
@@ -177,19 +196,127 @@ func (s *Service) CancelRecord(ctx context.Context, recordID, userID int) (*Reco
 	return cancelRecord, nil
 }
 
+// ExportSummary describes the contents of an export archive without its payload.
+type ExportSummary struct {
+	Records int       `json:"records"`
+	From    time.Time `json:"from"`
+	To      time.Time `json:"to"`
+}
+
+// SummarizeExport reads records.json from an archive produced by ExportRecords
+// and reports how many records it holds and the creation date range they span.
+func SummarizeExport(data []byte) (ExportSummary, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return ExportSummary{}, err
+	}
+	for _, f := range zr.File {
+		if f.Name != "records.json" {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return ExportSummary{}, err
+		}
+		defer rc.Close()
+
+		var records []*Record
+		if err := json.NewDecoder(rc).Decode(&records); err != nil {
+			return ExportSummary{}, err
+		}
+		summary := ExportSummary{Records: len(records)}
+		for _, r := range records {
+			if summary.From.IsZero() || r.CreatedAt.Before(summary.From) {
+				summary.From = r.CreatedAt
+			}
+			if r.CreatedAt.After(summary.To) {
+				summary.To = r.CreatedAt
+			}
+		}
+		return summary, nil
+	}
+	return ExportSummary{}, errors.New("records.json not found in export")
+}
+
+// ErrChainBroken is returned when an exported record does not chain to its predecessor.
+var ErrChainBroken = errors.New("verifactu record chain broken")
+
+// computeChainHash returns SHA256(record fields || previous chain hash).
+func computeChainHash(r *Record, prev string) string {
+	original := ""
+	if r.OriginalRecordID != nil {
+		original = strconv.Itoa(*r.OriginalRecordID)
+	}
+	data := fmt.Sprintf("%d:%d:%d:%s:%s:%s:%s:%s|%s",
+		r.ID, r.InvoiceID, r.OrganizationID, r.RecordType, original,
+		r.SIFCode, r.Hash, r.CreatedAt.UTC().Format(time.RFC3339Nano), prev)
+	sum := sha256.Sum256([]byte(data))
+	return fmt.Sprintf("%x", sum[:])
+}
+
+// chainRecords links records in order, filling PreviousHash and ChainHash.
+// It returns the chain hash of the last record.
+func chainRecords(records []*Record) string {
+	prev := ""
+	for _, r := range records {
+		r.PreviousHash = prev
+		r.ChainHash = computeChainHash(r, prev)
+		prev = r.ChainHash
+	}
+	return prev
+}
+
+// VerifyRecordChain checks that every exported record chains to the previous
+// one and that its chain hash matches its contents.
+func VerifyRecordChain(records []*Record) error {
+	prev := ""
+	for i, r := range records {
+		if r.PreviousHash != prev || r.ChainHash != computeChainHash(r, prev) {
+			return fmt.Errorf("%w at record %d (index %d)", ErrChainBroken, r.ID, i)
+		}
+		prev = r.ChainHash
+	}
+	return nil
+}
+
 // ExportRecords generates a signed ZIP archive containing all
 // VeriFactu records for the provided organization. The archive includes
-// both JSON and CSV representations of the records. The returned slice
-// contains the ZIP bytes and their signature.
+// both JSON and CSV representations of the records. Records are hash
+// chained in export order and the final chain hash is persisted. The
+// returned slice contains the ZIP bytes and their signature.
 func (s *Service) ExportRecords(ctx context.Context, orgID int) ([]byte, []byte, error) {
-	records, err := s.repo.ListRecordsByOrganization(ctx, orgID)
+	zipBytes, sig, lastHash, err := s.recordsArchive(ctx, orgID)
 	if err != nil {
 		return nil, nil, err
 	}
+	if lastHash != "" {
+		if err := s.repo.SaveExportChainHash(ctx, orgID, lastHash); err != nil {
+			return nil, nil, err
+		}
+	}
+	return zipBytes, sig, nil
+}
+
+// PreviewRecords builds the archive ExportRecords would return without
+// persisting the chain hash, so previews leave the chain untouched.
+func (s *Service) PreviewRecords(ctx context.Context, orgID int) ([]byte, []byte, error) {
+	zipBytes, sig, _, err := s.recordsArchive(ctx, orgID)
+	return zipBytes, sig, err
+}
+
+// recordsArchive builds and signs the export archive and returns the final
+// chain hash, empty when there are no records
+func (s *Service) recordsArchive(ctx context.Context, orgID int) ([]byte, []byte, string, error) {
+	records, err := s.repo.ListRecordsByOrganization(ctx, orgID)
+	if err != nil {
+		return nil, nil, "", err
+	}
+
+	lastHash := chainRecords(records)
 
 	jsonData, err := json.Marshal(records)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, "", err
 	}
 
 	buf := new(bytes.Buffer)
@@ -197,19 +324,19 @@ func (s *Service) ExportRecords(ctx context.Context, orgID int) ([]byte, []byte,
 
 	jsonFile, err := zipWriter.Create("records.json")
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, "", err
 	}
 	if _, err := jsonFile.Write(jsonData); err != nil {
-		return nil, nil, err
+		return nil, nil, "", err
 	}
 
 	csvFile, err := zipWriter.Create("records.csv")
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, "", err
 	}
 	csvWriter := csv.NewWriter(csvFile)
-	if err := csvWriter.Write([]string{"id", "invoiceId", "organizationId", "recordType", "originalRecordId", "sifCode", "createdAt"}); err != nil {
-		return nil, nil, err
+	if err := csvWriter.Write([]string{"id", "invoiceId", "organizationId", "recordType", "originalRecordId", "sifCode", "createdAt", "previousHash", "chainHash"}); err != nil {
+		return nil, nil, "", err
 	}
 	for _, r := range records {
 		original := ""
@@ -224,26 +351,108 @@ func (s *Service) ExportRecords(ctx context.Context, orgID int) ([]byte, []byte,
 			original,
 			r.SIFCode,
 			r.CreatedAt.Format(time.RFC3339),
+			r.PreviousHash,
+			r.ChainHash,
 		}); err != nil {
-			return nil, nil, err
+			return nil, nil, "", err
 		}
 	}
 	csvWriter.Flush()
 	if err := csvWriter.Error(); err != nil {
-		return nil, nil, err
+		return nil, nil, "", err
 	}
 
 	if err := zipWriter.Close(); err != nil {
-		return nil, nil, err
+		return nil, nil, "", err
 	}
 
 	zipBytes := buf.Bytes()
 	sig, err := s.signer.Sign(zipBytes)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, "", err
 	}
 
-	return zipBytes, sig, nil
+	return zipBytes, sig, lastHash, nil
+}
+
+// xmlExport is the root element of the AEAT submission document.
+type xmlExport struct {
+	XMLName   xml.Name    `xml:"RegistroFacturacion"`
+	Cabecera  xmlHeader   `xml:"Cabecera"`
+	Registros []xmlRecord `xml:"Registros>Registro"`
+}
+
+// xmlHeader identifies the issuing organization and software.
+type xmlHeader struct {
+	IDOrganizacion int    `xml:"IDOrganizacion"`
+	CodigoSIF      string `xml:"CodigoSIF"`
+	NumRegistros   int    `xml:"NumRegistros"`
+}
+
+// xmlRecord is the XML representation of a single VeriFactu record.
+type xmlRecord struct {
+	IDRegistro         int            `xml:"IDRegistro"`
+	TipoRegistro       string         `xml:"TipoRegistro"`
+	IDFactura          int            `xml:"IDFactura"`
+	IDRegistroOriginal *int           `xml:"IDRegistroOriginal,omitempty"`
+	CodigoSIF          string         `xml:"CodigoSIF"`
+	Huella             string         `xml:"Huella"`
+	Encadenamiento     xmlChainedHash `xml:"Encadenamiento"`
+	FechaHoraGen       string         `xml:"FechaHoraHusoGenRegistro"`
+}
+
+// xmlChainedHash links a record to the one exported before it.
+type xmlChainedHash struct {
+	HuellaAnterior string `xml:"HuellaAnterior"`
+	HuellaRegistro string `xml:"HuellaRegistro"`
+}
+
+// ExportXML serializes all VeriFactu records for the organization into the
+// XML structure used for AEAT submissions. Records are hash chained exactly
+// as in ExportRecords and the final chain hash is persisted.
+func (s *Service) ExportXML(ctx context.Context, orgID int) ([]byte, error) {
+	records, err := s.repo.ListRecordsByOrganization(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	lastHash := chainRecords(records)
+
+	doc := xmlExport{
+		Cabecera: xmlHeader{
+			IDOrganizacion: orgID,
+			CodigoSIF:      s.sifCode,
+			NumRegistros:   len(records),
+		},
+	}
+	for _, r := range records {
+		doc.Registros = append(doc.Registros, xmlRecord{
+			IDRegistro:         r.ID,
+			TipoRegistro:       r.RecordType,
+			IDFactura:          r.InvoiceID,
+			IDRegistroOriginal: r.OriginalRecordID,
+			CodigoSIF:          r.SIFCode,
+			Huella:             r.Hash,
+			Encadenamiento: xmlChainedHash{
+				HuellaAnterior: r.PreviousHash,
+				HuellaRegistro: r.ChainHash,
+			},
+			FechaHoraGen: r.CreatedAt.Format(time.RFC3339),
+		})
+	}
+
+	body, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	if len(records) > 0 {
+		if err := s.repo.SaveExportChainHash(ctx, orgID, lastHash); err != nil {
+			return nil, err
+		}
+	}
+
+	return append([]byte(xml.Header), append(body, '\n')...), nil
 }
 
 // HMACSigner provides HMAC-SHA256 signing for exported data.
@@ -260,3 +469,19 @@ func (s *HMACSigner) Sign(data []byte) ([]byte, error) {
 	}
 	return mac.Sum(nil), nil
 }
+
+// Verify reports whether signature is a valid HMAC-SHA256 of data for this
+// signer's key. The comparison runs in constant time.
+func (s *HMACSigner) Verify(data, signature []byte) bool {
+	expected, err := s.Sign(data)
+	if err != nil {
+		return false
+	}
+	return hmac.Equal(expected, signature)
+}
+
+// VerifyExport checks the signature printed by the export command against the
+// exported archive bytes using the given key.
+func VerifyExport(data, sig, key []byte) bool {
+	return NewHMACSigner(key).Verify(data, sig)
+}
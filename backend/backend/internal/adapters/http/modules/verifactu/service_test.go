@@ -1,18 +1,25 @@
 package verifactu
 
 import (
+	"archive/zip"
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 )
 
 type memRepo struct {
-	records   map[int][]*Record
-	liveModes map[int]bool
+	records     map[int][]*Record
+	liveModes   map[int]bool
+	chainHashes map[int]string
 }
 
 func newMemRepo() *memRepo {
-	return &memRepo{records: make(map[int][]*Record), liveModes: make(map[int]bool)}
+	return &memRepo{records: make(map[int][]*Record), liveModes: make(map[int]bool), chainHashes: make(map[int]string)}
 }
 
 func (m *memRepo) GetRecordByID(ctx context.Context, id int) (*Record, error) {
@@ -49,16 +56,51 @@ func (m *memRepo) GetLiveMode(ctx context.Context, year int) (bool, error) {
 	return m.liveModes[year], nil
 }
 
-func (m *memRepo) SetLiveMode(ctx context.Context, year int, live bool) error {
+func (m *memRepo) SetLiveMode(ctx context.Context, year int, live bool, triggeredBy string) error {
 	m.liveModes[year] = live
 	return nil
 }
 
+func (m *memRepo) GetLiveModeStatus(ctx context.Context, year int) (*LiveModeStatus, error) {
+	return &LiveModeStatus{Year: year, Live: m.liveModes[year]}, nil
+}
+
+func (m *memRepo) SaveExportChainHash(ctx context.Context, orgID int, hash string) error {
+	m.chainHashes[orgID] = hash
+	return nil
+}
+
+// readExportedRecords extracts records.json from an exported archive.
+func readExportedRecords(t *testing.T, data []byte) []*Record {
+	t.Helper()
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("open export archive: %v", err)
+	}
+	for _, f := range zr.File {
+		if f.Name != "records.json" {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("open records.json: %v", err)
+		}
+		defer rc.Close()
+		var records []*Record
+		if err := json.NewDecoder(rc).Decode(&records); err != nil {
+			t.Fatalf("decode records.json: %v", err)
+		}
+		return records
+	}
+	t.Fatalf("records.json not found in export")
+	return nil
+}
+
 func TestUpdateConfigLiveMode(t *testing.T) {
 	repo := newMemRepo()
 	svc := NewService(repo, NewHMACSigner([]byte("key")), "AA", "real-time")
 	ctx := context.Background()
-	_ = repo.SetLiveMode(ctx, time.Now().Year(), true)
+	_ = repo.SetLiveMode(ctx, time.Now().Year(), true, "test")
 	if _, err := svc.UpdateConfig(ctx, "AA", "queued"); err != ErrModeFrozen {
 		t.Fatalf("expected mode frozen error")
 	}
@@ -138,3 +180,109 @@ func TestCancelRecordComputesHash(t *testing.T) {
 		t.Fatalf("cancel record should reference original")
 	}
 }
+
+func TestVerifyExportSignature(t *testing.T) {
+	repo := newMemRepo()
+	key := []byte("key")
+	svc := NewService(repo, NewHMACSigner(key), "AA", "queued")
+	ctx := context.Background()
+
+	if _, err := svc.GenerateRecord(ctx, 1, 1, "alta"); err != nil {
+		t.Fatalf("generate record: %v", err)
+	}
+	data, sig, err := svc.ExportRecords(ctx, 1)
+	if err != nil {
+		t.Fatalf("export records: %v", err)
+	}
+
+	if !VerifyExport(data, sig, key) {
+		t.Fatalf("expected signature to verify")
+	}
+	if !NewHMACSigner(key).Verify(data, sig) {
+		t.Fatalf("expected signer to verify its own signature")
+	}
+
+	tampered := append([]byte(nil), data...)
+	tampered[len(tampered)-1] ^= 0xff
+	if VerifyExport(tampered, sig, key) {
+		t.Fatalf("tampered data should not verify")
+	}
+
+	if VerifyExport(data, sig, []byte("other-key")) {
+		t.Fatalf("wrong key should not verify")
+	}
+}
+
+func TestExportRecordsChainsHashes(t *testing.T) {
+	repo := newMemRepo()
+	svc := NewService(repo, NewHMACSigner([]byte("key")), "AA", "queued")
+	ctx := context.Background()
+
+	for i := 1; i <= 3; i++ {
+		if _, err := svc.GenerateRecord(ctx, i, 1, "alta"); err != nil {
+			t.Fatalf("generate record %d: %v", i, err)
+		}
+	}
+
+	data, _, err := svc.ExportRecords(ctx, 1)
+	if err != nil {
+		t.Fatalf("export records: %v", err)
+	}
+	records := readExportedRecords(t, data)
+	if len(records) != 3 {
+		t.Fatalf("expected 3 exported records, got %d", len(records))
+	}
+	if records[0].PreviousHash != "" {
+		t.Fatalf("first record should not have a previous hash")
+	}
+	for i := 1; i < len(records); i++ {
+		if records[i].PreviousHash != records[i-1].ChainHash {
+			t.Fatalf("record %d does not chain to its predecessor", i)
+		}
+	}
+	if err := VerifyRecordChain(records); err != nil {
+		t.Fatalf("expected valid chain: %v", err)
+	}
+	if repo.chainHashes[1] != records[2].ChainHash {
+		t.Fatalf("last chain hash not stored in repository")
+	}
+
+	records[1].InvoiceID = 99
+	if err := VerifyRecordChain(records); !errors.Is(err, ErrChainBroken) {
+		t.Fatalf("expected broken chain after altering middle record, got %v", err)
+	}
+}
+
+func TestExportXMLMatchesGolden(t *testing.T) {
+	repo := newMemRepo()
+	svc := NewService(repo, NewHMACSigner([]byte("key")), "AA", "queued")
+	ctx := context.Background()
+
+	created := time.Date(2024, 3, 15, 10, 30, 0, 0, time.UTC)
+	original := 1
+	fixtures := []*Record{
+		{InvoiceID: 10, OrganizationID: 1, RecordType: "alta", SIFCode: "AA", Hash: "h1", CreatedAt: created},
+		{InvoiceID: 11, OrganizationID: 1, RecordType: "alta", SIFCode: "AA", Hash: "h2", CreatedAt: created.Add(time.Minute)},
+		{InvoiceID: 10, OrganizationID: 1, RecordType: "anulacion", OriginalRecordID: &original, SIFCode: "AA", Hash: "h3", CreatedAt: created.Add(2 * time.Minute)},
+	}
+	for _, r := range fixtures {
+		if err := repo.CreateRecord(ctx, r); err != nil {
+			t.Fatalf("create record: %v", err)
+		}
+	}
+
+	got, err := svc.ExportXML(ctx, 1)
+	if err != nil {
+		t.Fatalf("export xml: %v", err)
+	}
+	want, err := os.ReadFile(filepath.Join("testdata", "export.golden.xml"))
+	if err != nil {
+		t.Fatalf("read golden file: %v", err)
+	}
+	if string(got) != string(want) {
+		t.Fatalf("xml export mismatch\n---got---\n%s\n---want---\n%s", got, want)
+	}
+	if repo.chainHashes[1] != fixtures[2].ChainHash {
+		t.Fatalf("last chain hash not stored in repository")
+	}
+}
@@ -3,14 +3,18 @@ package adapterhttp
 
 import (
 	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-playground/validator/v10"
 
 	"github.com/pmaojo/kthulu-go/backend/core"
 	"github.com/pmaojo/kthulu-go/backend/internal/domain"
+	"github.com/pmaojo/kthulu-go/backend/internal/domain/repository"
 	"github.com/pmaojo/kthulu-go/backend/internal/usecase"
 )
 
@@ -42,6 +46,9 @@ func (h *OrganizationHandler) RegisterRoutes(r chi.Router) {
 		r.Route("/{organizationId}", func(r chi.Router) {
 			r.Get("/", h.GetOrganization)
 			r.Patch("/", h.UpdateOrganization)
+			r.Post("/logo", h.UploadLogo)
+			r.Put("/email-templates/{type}", h.SetEmailTemplate)
+			r.Get("/members", h.ListMembers)
 			r.Post("/invitations", h.InviteUser)
 		})
 	})
@@ -194,6 +201,8 @@ type UpdateOrganizationRequest struct {
 	State       *string `json:"state,omitempty" validate:"omitempty,max=100"`
 	Country     *string `json:"country,omitempty" validate:"omitempty,max=100"`
 	PostalCode  *string `json:"postalCode,omitempty" validate:"omitempty,max=20"`
+
+	InvoiceNumberFormat *string `json:"invoiceNumberFormat,omitempty" validate:"omitempty,max=100"`
 }
 
 // UpdateOrganization handles PATCH /organizations/{organizationId}
@@ -244,6 +253,8 @@ func (h *OrganizationHandler) UpdateOrganization(w http.ResponseWriter, r *http.
 		State:       req.State,
 		Country:     req.Country,
 		PostalCode:  req.PostalCode,
+
+		InvoiceNumberFormat: req.InvoiceNumberFormat,
 	}
 
 	// Update organization
@@ -258,6 +269,161 @@ func (h *OrganizationHandler) UpdateOrganization(w http.ResponseWriter, r *http.
 	json.NewEncoder(w).Encode(org)
 }
 
+// logoUploadOverhead is the room left above MaxOrganizationLogoSize for the
+// multipart envelope around the file
+const logoUploadOverhead = 64 << 10
+
+// UploadLogoResponse is returned after a logo upload
+type UploadLogoResponse struct {
+	LogoURL string `json:"logoUrl"`
+}
+
+// UploadLogo godoc
+// @Summary Upload organization logo
+// @Description Stores a PNG, JPEG, GIF or WebP logo of at most 2 MiB, sent as the "logo" field of a multipart form or as the raw request body, and sets it as the organization's logo
+// @Tags Organizations
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param organizationId path int true "Organization ID"
+// @Param logo formData file true "Logo image"
+// @Success 200 {object} UploadLogoResponse "Logo uploaded successfully"
+// @Failure 400 {object} map[string]string "Missing or invalid image"
+// @Failure 403 {object} map[string]string "Insufficient permissions"
+// @Failure 413 {object} map[string]string "Logo too large"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /organizations/{organizationId}/logo [post]
+func (h *OrganizationHandler) UploadLogo(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// Get user ID from context
+	userID, ok := ctx.Value("userID").(uint)
+	if !ok {
+		h.logger.Error("User ID not found in context")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	// Get organization ID from URL
+	organizationIDStr := chi.URLParam(r, "organizationId")
+	organizationID, err := strconv.ParseUint(organizationIDStr, 10, 32)
+	if err != nil {
+		h.logger.Warn("Invalid organization ID in URL", "organizationId", organizationIDStr)
+		http.Error(w, "Invalid organization ID", http.StatusBadRequest)
+		return
+	}
+
+	// Read the image, bounding the body so oversized uploads fail early
+	r.Body = http.MaxBytesReader(w, r.Body, usecase.MaxOrganizationLogoSize+logoUploadOverhead)
+	data, err := readLogo(r)
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			h.handleError(w, domain.ErrLogoTooLarge)
+			return
+		}
+		h.logger.Warn("Invalid logo upload", "organizationId", organizationID, "error", err)
+		http.Error(w, "Invalid logo upload", http.StatusBadRequest)
+		return
+	}
+
+	// Upload logo
+	org, err := h.organizationUC.UploadLogo(ctx, userID, uint(organizationID), data)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	// Return the new logo URL
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(UploadLogoResponse{LogoURL: org.LogoURL})
+}
+
+// readLogo returns the "logo" file of a multipart form, or the raw body for
+// any other content type
+func readLogo(r *http.Request) ([]byte, error) {
+	if !strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/") {
+		return io.ReadAll(r.Body)
+	}
+
+	if err := r.ParseMultipartForm(usecase.MaxOrganizationLogoSize + logoUploadOverhead); err != nil {
+		return nil, err
+	}
+	defer r.MultipartForm.RemoveAll()
+	file, _, err := r.FormFile("logo")
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return io.ReadAll(file)
+}
+
+// SetEmailTemplateRequest represents the request to customize an email template
+type SetEmailTemplateRequest struct {
+	Subject string `json:"subject" validate:"required,max=200"`
+	HTML    string `json:"html" validate:"required"`
+	Text    string `json:"text" validate:"required"`
+}
+
+// SetEmailTemplate godoc
+// @Summary Set organization email template
+// @Description Replaces the built-in template of an email type, such as email_confirmation, for emails sent on behalf of the organization. Subject and text are Go text/template sources, html is an html/template source.
+// @Tags Organizations
+// @Accept json
+// @Security BearerAuth
+// @Param organizationId path int true "Organization ID"
+// @Param type path string true "Email type"
+// @Param request body SetEmailTemplateRequest true "Template sources"
+// @Success 204 "Template stored"
+// @Failure 400 {object} map[string]string "Invalid template"
+// @Failure 403 {object} map[string]string "Insufficient permissions"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /organizations/{organizationId}/email-templates/{type} [put]
+func (h *OrganizationHandler) SetEmailTemplate(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// Get user ID from context
+	userID, ok := ctx.Value("userID").(uint)
+	if !ok {
+		h.logger.Error("User ID not found in context")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	// Get organization ID from URL
+	organizationIDStr := chi.URLParam(r, "organizationId")
+	organizationID, err := strconv.ParseUint(organizationIDStr, 10, 32)
+	if err != nil {
+		h.logger.Warn("Invalid organization ID in URL", "organizationId", organizationIDStr)
+		http.Error(w, "Invalid organization ID", http.StatusBadRequest)
+		return
+	}
+
+	// Parse request body
+	var req SetEmailTemplateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Warn("Invalid JSON in set email template request", "error", err)
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	// Validate request
+	if err := h.validator.Struct(req); err != nil {
+		h.logger.Warn("Validation failed for set email template request", "error", err)
+		h.writeValidationError(w, err)
+		return
+	}
+
+	notificationType := repository.NotificationType(chi.URLParam(r, "type"))
+	tmpl := repository.EmailTemplate{Subject: req.Subject, HTML: req.HTML, Text: req.Text}
+	if err := h.organizationUC.SetEmailTemplate(ctx, userID, uint(organizationID), notificationType, tmpl); err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
 // ListUserOrganizations godoc
 // @Summary List user organizations
 // @Description Returns all organizations the authenticated user belongs to
@@ -294,6 +460,63 @@ func (h *OrganizationHandler) ListUserOrganizations(w http.ResponseWriter, r *ht
 	})
 }
 
+// ListMembers godoc
+// @Summary List organization members
+// @Description Returns the organization's users with their roles, oldest members first
+// @Tags Organizations
+// @Produce json
+// @Security BearerAuth
+// @Param organizationId path int true "Organization ID"
+// @Param role query string false "Only members with this role" Enums(owner, admin, member, guest)
+// @Param search query string false "Search in member emails"
+// @Param page query int false "Page number" default(1)
+// @Param pageSize query int false "Page size" default(20)
+// @Success 200 {object} usecase.MemberListResponse "Members retrieved successfully"
+// @Failure 400 {object} map[string]string "Invalid organization ID"
+// @Failure 403 {object} map[string]string "User not in organization"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /organizations/{organizationId}/members [get]
+func (h *OrganizationHandler) ListMembers(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// Get user ID from context
+	userID, ok := ctx.Value("userID").(uint)
+	if !ok {
+		h.logger.Error("User ID not found in context")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	// Get organization ID from URL
+	organizationIDStr := chi.URLParam(r, "organizationId")
+	organizationID, err := strconv.ParseUint(organizationIDStr, 10, 32)
+	if err != nil {
+		h.logger.Warn("Invalid organization ID in URL", "organizationId", organizationIDStr)
+		http.Error(w, "Invalid organization ID", http.StatusBadRequest)
+		return
+	}
+
+	// Parse filters
+	query := r.URL.Query()
+	filters := repository.MemberFilters{
+		Role:   domain.OrganizationRole(query.Get("role")),
+		Search: query.Get("search"),
+	}
+	filters.Page, filters.PageSize = parsePagination(r, repository.DefaultPageSize)
+
+	// List members
+	response, err := h.organizationUC.ListMembers(ctx, userID, uint(organizationID), filters)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	// Return members
+	setPaginationHeaders(w, r, response.Page, response.PageSize, response.Total)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
 // InviteUserRequest represents the request to invite a user to an organization
 type InviteUserRequest struct {
 	Email   string                  `json:"email" validate:"required,email"`
@@ -391,6 +614,11 @@ func (h *OrganizationHandler) AcceptInvitation(w http.ResponseWriter, r *http.Re
 
 // handleError handles use case errors and converts them to appropriate HTTP responses
 func (h *OrganizationHandler) handleError(w http.ResponseWriter, err error) {
+	if errors.Is(err, domain.ErrInvalidInvoiceNumberFormat) || errors.Is(err, domain.ErrInvalidEmailTemplate) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	switch err {
 	case domain.ErrOrganizationNotFound:
 		http.Error(w, "Organization not found", http.StatusNotFound)
@@ -406,6 +634,10 @@ func (h *OrganizationHandler) handleError(w http.ResponseWriter, err error) {
 		http.Error(w, "Invitation expired", http.StatusGone)
 	case domain.ErrInvitationAlreadyAccepted:
 		http.Error(w, "Invitation already accepted", http.StatusConflict)
+	case domain.ErrInvalidLogo:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case domain.ErrLogoTooLarge:
+		http.Error(w, "Logo must not exceed 2 MiB", http.StatusRequestEntityTooLarge)
 	default:
 		h.logger.Error("Unhandled error in organization handler", "error", err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
@@ -0,0 +1,208 @@
+package adapterhttp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"image"
+	"image/png"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+
+	"github.com/pmaojo/kthulu-go/backend/core"
+	"github.com/pmaojo/kthulu-go/backend/internal/domain"
+	"github.com/pmaojo/kthulu-go/backend/internal/domain/repository"
+	"github.com/pmaojo/kthulu-go/backend/internal/infrastructure/notifier"
+	"github.com/pmaojo/kthulu-go/backend/internal/infrastructure/storage"
+	"github.com/pmaojo/kthulu-go/backend/internal/usecase"
+)
+
+// mockOrganizationRepository keeps a single organization in memory
+type mockOrganizationRepository struct {
+	repository.OrganizationRepository
+	org *domain.Organization
+}
+
+func (m *mockOrganizationRepository) FindByID(ctx context.Context, id uint) (*domain.Organization, error) {
+	if m.org == nil || m.org.ID != id {
+		return nil, domain.ErrOrganizationNotFound
+	}
+	copied := *m.org
+	return &copied, nil
+}
+
+func (m *mockOrganizationRepository) Update(ctx context.Context, org *domain.Organization) error {
+	m.org = org
+	return nil
+}
+
+// mockOrganizationMembers gives every user the same role
+type mockOrganizationMembers struct {
+	repository.OrganizationUserRepository
+	role domain.OrganizationRole
+}
+
+func (m *mockOrganizationMembers) GetUserRole(ctx context.Context, organizationID, userID uint) (domain.OrganizationRole, error) {
+	return m.role, nil
+}
+
+func newLogoTestRouter(t *testing.T) (chi.Router, *mockOrganizationRepository, string) {
+	t.Helper()
+	dir := t.TempDir()
+	orgs := &mockOrganizationRepository{org: &domain.Organization{ID: 7, Name: "Acme", Slug: "acme", Type: domain.OrganizationTypeCompany}}
+	logger := core.NewLoggerFromZap(zap.NewNop())
+	blobs := storage.NewLocalBlobStore(dir, "https://cdn.example.com/uploads", "")
+	uc := usecase.NewOrganizationUseCase(orgs, &mockOrganizationMembers{role: domain.OrganizationRoleOwner}, nil, nil, nil, blobs, nil, nil, logger)
+	blobHandler, err := NewBlobHandler(&core.Config{Storage: core.StorageConfig{PublicURL: "https://cdn.example.com/uploads"}}, blobs)
+	if err != nil {
+		t.Fatalf("new blob handler: %v", err)
+	}
+
+	r := chi.NewRouter()
+	r.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			next.ServeHTTP(w, req.WithContext(context.WithValue(req.Context(), "userID", uint(1))))
+		})
+	})
+	NewOrganizationHandler(uc, logger).RegisterRoutes(r)
+	blobHandler.RegisterRoutes(r)
+	return r, orgs, dir
+}
+
+func multipartLogo(t *testing.T, content []byte) (*bytes.Buffer, string) {
+	t.Helper()
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, err := form.CreateFormFile("logo", "logo.png")
+	if err != nil {
+		t.Fatalf("create form file: %v", err)
+	}
+	part.Write(content)
+	form.Close()
+	return &body, form.FormDataContentType()
+}
+
+func TestOrganizationHandler_UploadLogo_StoresPNG(t *testing.T) {
+	router, orgs, dir := newLogoTestRouter(t)
+
+	var logo bytes.Buffer
+	if err := png.Encode(&logo, image.NewRGBA(image.Rect(0, 0, 4, 4))); err != nil {
+		t.Fatalf("encode png: %v", err)
+	}
+	body, contentType := multipartLogo(t, logo.Bytes())
+	req := httptest.NewRequest(http.MethodPost, "/organizations/7/logo", body)
+	req.Header.Set("Content-Type", contentType)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var resp UploadLogoResponse
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if !strings.HasPrefix(resp.LogoURL, "https://cdn.example.com/uploads/public/organizations/7/logo-") || !strings.HasSuffix(resp.LogoURL, ".png") {
+		t.Fatalf("unexpected logo url %q", resp.LogoURL)
+	}
+	if orgs.org.LogoURL != resp.LogoURL {
+		t.Fatalf("expected organization logo %q, got %q", resp.LogoURL, orgs.org.LogoURL)
+	}
+
+	key := strings.TrimPrefix(resp.LogoURL, "https://cdn.example.com/uploads/")
+	stored, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(key)))
+	if err != nil || !bytes.Equal(stored, logo.Bytes()) {
+		t.Fatalf("expected the uploaded image on disk, got %v", err)
+	}
+
+	// The logo URL is served by the service without a signature
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, resp.LogoURL, nil))
+	if rr.Code != http.StatusOK || !bytes.Equal(rr.Body.Bytes(), logo.Bytes()) {
+		t.Fatalf("expected the logo at its url, got %d", rr.Code)
+	}
+}
+
+func TestOrganizationHandler_UploadLogo_Rejections(t *testing.T) {
+	cases := []struct {
+		name    string
+		content []byte
+		status  int
+	}{
+		{"not an image", []byte("%PDF-1.4 definitely not a logo"), http.StatusBadRequest},
+		{"empty", nil, http.StatusBadRequest},
+		{"oversized", append([]byte("\x89PNG\r\n\x1a\n"), make([]byte, usecase.MaxOrganizationLogoSize)...), http.StatusRequestEntityTooLarge},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			router, orgs, dir := newLogoTestRouter(t)
+
+			body, contentType := multipartLogo(t, tc.content)
+			req := httptest.NewRequest(http.MethodPost, "/organizations/7/logo", body)
+			req.Header.Set("Content-Type", contentType)
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			if rr.Code != tc.status {
+				t.Fatalf("expected %d, got %d: %s", tc.status, rr.Code, rr.Body.String())
+			}
+			if orgs.org.LogoURL != "" {
+				t.Fatalf("expected logo to stay unset, got %q", orgs.org.LogoURL)
+			}
+			if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+				t.Fatalf("expected nothing stored, found %d entries", len(entries))
+			}
+		})
+	}
+}
+
+func TestOrganizationHandler_SetEmailTemplate(t *testing.T) {
+	logger := core.NewLoggerFromZap(zap.NewNop())
+	templates := notifier.NewEmailTemplateRenderer(nil)
+	newRouter := func(role domain.OrganizationRole) chi.Router {
+		uc := usecase.NewOrganizationUseCase(nil, &mockOrganizationMembers{role: role}, nil, nil, nil, nil, nil, templates, logger)
+		r := chi.NewRouter()
+		r.Use(func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				next.ServeHTTP(w, req.WithContext(context.WithValue(req.Context(), "userID", uint(1))))
+			})
+		})
+		NewOrganizationHandler(uc, logger).RegisterRoutes(r)
+		return r
+	}
+	put := func(router chi.Router, notificationType, body string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodPut, "/organizations/7/email-templates/"+notificationType, strings.NewReader(body)))
+		return rr
+	}
+
+	valid := `{"subject":"{{.OrganizationName}} code","html":"<p>{{.Code}}</p>","text":"{{.Code}}"}`
+	if rr := put(newRouter(domain.OrganizationRoleMember), "email_confirmation", valid); rr.Code != http.StatusForbidden {
+		t.Fatalf("expected members to be rejected with 403, got %d", rr.Code)
+	}
+	if rr := put(newRouter(domain.OrganizationRoleAdmin), "email_confirmation", `{"subject":"{{.Code","html":"x","text":"x"}`); rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected broken template to be rejected with 400, got %d", rr.Code)
+	}
+	if rr := put(newRouter(domain.OrganizationRoleAdmin), "fax", valid); rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected unknown email type to be rejected with 400, got %d", rr.Code)
+	}
+	if rr := put(newRouter(domain.OrganizationRoleAdmin), "email_confirmation", valid); rr.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	rendered, err := templates.Render(context.Background(), 7, repository.NotificationTypeEmailConfirmation, notifier.EmailTemplateData{Code: "123456", OrganizationName: "Acme"})
+	if err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	if rendered.Subject != "Acme code" || rendered.HTML != "<p>123456</p>" {
+		t.Fatalf("expected the organization template, got %+v", rendered)
+	}
+}
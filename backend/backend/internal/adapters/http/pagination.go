@@ -0,0 +1,65 @@
+package adapterhttp
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/pmaojo/kthulu-go/backend/internal/domain/repository"
+)
+
+// parsePagination reads the page and pageSize query parameters of a list
+// request. Missing or malformed values get the defaults and out-of-range
+// ones are clamped by repository.ClampPage.
+func parsePagination(r *http.Request, defaultPageSize int) (page, pageSize int) {
+	query := r.URL.Query()
+	page, _ = strconv.Atoi(query.Get("page"))
+	pageSize, _ = strconv.Atoi(query.Get("pageSize"))
+	return repository.ClampPage(page, pageSize, defaultPageSize)
+}
+
+// parseLimitOffset reads the limit and offset query parameters of a list
+// request, clamped like parsePagination
+func parseLimitOffset(r *http.Request, defaultLimit int) (limit, offset int) {
+	query := r.URL.Query()
+	limit, _ = strconv.Atoi(query.Get("limit"))
+	offset, _ = strconv.Atoi(query.Get("offset"))
+	return repository.ClampOffset(limit, offset, defaultLimit)
+}
+
+// setPaginationHeaders describes a page of a list response in headers:
+// X-Total-Count, X-Page and an RFC 5988 Link header with the next and prev
+// pages. It must be called before the response is written.
+func setPaginationHeaders(w http.ResponseWriter, r *http.Request, page, pageSize int, total int64) {
+	w.Header().Set("X-Total-Count", strconv.FormatInt(total, 10))
+	w.Header().Set("X-Page", strconv.Itoa(page))
+	if pageSize <= 0 {
+		return
+	}
+
+	totalPages := int((total + int64(pageSize) - 1) / int64(pageSize))
+	var links []string
+	if page < totalPages {
+		links = append(links, fmt.Sprintf(`<%s>; rel="next"`, pageURL(r, page+1)))
+	}
+	if page > 1 && totalPages > 0 {
+		prev := page - 1
+		if prev > totalPages {
+			prev = totalPages
+		}
+		links = append(links, fmt.Sprintf(`<%s>; rel="prev"`, pageURL(r, prev)))
+	}
+	if len(links) > 0 {
+		w.Header().Set("Link", strings.Join(links, ", "))
+	}
+}
+
+// pageURL returns the request URL with its page query parameter replaced
+func pageURL(r *http.Request, page int) string {
+	u := *r.URL
+	query := u.Query()
+	query.Set("page", strconv.Itoa(page))
+	u.RawQuery = query.Encode()
+	return u.RequestURI()
+}
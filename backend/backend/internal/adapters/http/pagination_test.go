@@ -0,0 +1,103 @@
+package adapterhttp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pmaojo/kthulu-go/backend/internal/domain/repository"
+)
+
+func TestSetPaginationHeaders_MiddlePage(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/products?page=2&pageSize=10&search=bolt", nil)
+	w := httptest.NewRecorder()
+
+	setPaginationHeaders(w, req, 2, 10, 35)
+
+	if got := w.Header().Get("X-Total-Count"); got != "35" {
+		t.Fatalf("expected X-Total-Count 35, got %q", got)
+	}
+	if got := w.Header().Get("X-Page"); got != "2" {
+		t.Fatalf("expected X-Page 2, got %q", got)
+	}
+	want := `</products?page=3&pageSize=10&search=bolt>; rel="next", </products?page=1&pageSize=10&search=bolt>; rel="prev"`
+	if got := w.Header().Get("Link"); got != want {
+		t.Fatalf("unexpected Link header:\n got %s\nwant %s", got, want)
+	}
+}
+
+func TestSetPaginationHeaders_Edges(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/invoices?page=4&pageSize=10", nil)
+	w := httptest.NewRecorder()
+	setPaginationHeaders(w, req, 4, 10, 35)
+	if got := w.Header().Get("Link"); got != `</invoices?page=3&pageSize=10>; rel="prev"` {
+		t.Fatalf("expected only prev on the last page, got %q", got)
+	}
+
+	w = httptest.NewRecorder()
+	setPaginationHeaders(w, req, 1, 10, 0)
+	if got := w.Header().Get("Link"); got != "" {
+		t.Fatalf("expected no Link header for an empty list, got %q", got)
+	}
+	if got := w.Header().Get("X-Total-Count"); got != "0" {
+		t.Fatalf("expected X-Total-Count 0, got %q", got)
+	}
+}
+
+func TestParsePagination_ClampsAtBoundaries(t *testing.T) {
+	for query, want := range map[string][2]int{
+		"":                         {1, 10},
+		"page=0&pageSize=0":        {1, 10},
+		"page=-3&pageSize=-1":      {1, 10},
+		"page=abc&pageSize=ten":    {1, 10},
+		"page=1&pageSize=1":        {1, 1},
+		"page=7&pageSize=99":       {7, 99},
+		"page=2&pageSize=100":      {2, 100},
+		"page=2&pageSize=101":      {2, 100},
+		"page=2&pageSize=1000000":  {2, 100},
+		"page=2&pageSize=99999999": {2, repository.MaxPageSize},
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/items?"+query, nil)
+		page, pageSize := parsePagination(req, 10)
+		if page != want[0] || pageSize != want[1] {
+			t.Fatalf("%q: expected page %d size %d, got %d and %d", query, want[0], want[1], page, pageSize)
+		}
+	}
+}
+
+func TestParseLimitOffset_ClampsAtBoundaries(t *testing.T) {
+	for query, want := range map[string][2]int{
+		"":                     {50, 0},
+		"limit=0&offset=-1":    {50, 0},
+		"limit=100&offset=250": {100, 250},
+		"limit=101":            {100, 0},
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/items?"+query, nil)
+		limit, offset := parseLimitOffset(req, 50)
+		if limit != want[0] || offset != want[1] {
+			t.Fatalf("%q: expected limit %d offset %d, got %d and %d", query, want[0], want[1], limit, offset)
+		}
+	}
+}
+
+func TestPaginationParamsAndFiltersShareTheCeiling(t *testing.T) {
+	params := repository.PaginationParams{Page: 0, PageSize: 1000000, SortBy: "name", SortDir: "desc"}.Clamped()
+	if params.Page != 1 || params.PageSize != repository.MaxPageSize || params.SortBy != "name" || params.SortDir != "desc" {
+		t.Fatalf("unexpected clamped params %+v", params)
+	}
+	if params = repository.NewPaginationParams(3, 0, "", ""); params.PageSize != repository.DefaultPageSize {
+		t.Fatalf("expected the default page size, got %+v", params)
+	}
+
+	contacts := repository.ContactFilters{PageSize: 500}
+	invoices := repository.InvoiceFilters{PageSize: 500}
+	products := repository.ProductFilters{PageSize: 500}
+	contacts.Validate()
+	invoices.Validate()
+	products.Validate()
+	for name, size := range map[string]int{"contacts": contacts.PageSize, "invoices": invoices.PageSize, "products": products.PageSize} {
+		if size != repository.MaxPageSize {
+			t.Fatalf("%s: expected page size %d, got %d", name, repository.MaxPageSize, size)
+		}
+	}
+}
@@ -0,0 +1,171 @@
+// @kthulu:module:payments
+package adapterhttp
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+
+	"github.com/pmaojo/kthulu-go/backend/core"
+	"github.com/pmaojo/kthulu-go/backend/internal/adapters/http/middleware"
+	"github.com/pmaojo/kthulu-go/backend/internal/domain"
+	"github.com/pmaojo/kthulu-go/backend/internal/domain/repository"
+	"github.com/pmaojo/kthulu-go/backend/internal/usecase"
+)
+
+// maxPaymentWebhookBytes bounds the size of a gateway webhook delivery
+const maxPaymentWebhookBytes = 1 << 20
+
+// PaymentGatewayHandler handles online invoice payments: creating payment
+// intents for members of the organization and receiving the gateway's
+// webhooks, which authenticate with their signature instead of a token
+type PaymentGatewayHandler struct {
+	payments     *usecase.OnlinePaymentUseCase
+	orgUsers     repository.OrganizationUserRepository
+	tokenManager core.TokenManager
+	denylist     repository.AccessTokenDenylist
+	validator    *validator.Validate
+	logger       *zap.Logger
+}
+
+// NewPaymentGatewayHandler creates a new payment gateway handler
+func NewPaymentGatewayHandler(p struct {
+	fx.In
+	Payments     *usecase.OnlinePaymentUseCase
+	OrgUsers     repository.OrganizationUserRepository
+	TokenManager core.TokenManager
+	Denylist     repository.AccessTokenDenylist `optional:"true"`
+	Logger       *zap.Logger
+}) *PaymentGatewayHandler {
+	return &PaymentGatewayHandler{
+		payments:     p.Payments,
+		orgUsers:     p.OrgUsers,
+		tokenManager: p.TokenManager,
+		denylist:     p.Denylist,
+		validator:    validator.New(),
+		logger:       p.Logger,
+	}
+}
+
+// RegisterRoutes registers the payment gateway routes
+func (h *PaymentGatewayHandler) RegisterRoutes(r chi.Router) {
+	r.Group(func(r chi.Router) {
+		r.Use(middleware.RequireAuth(h.tokenManager, h.denylist))
+		r.Use(middleware.OrganizationMiddleware(h.orgUsers))
+		r.Post("/payment-intents", h.CreatePaymentIntent)
+	})
+	r.Post("/webhooks/payments", h.HandleWebhook)
+}
+
+// CreatePaymentIntentRequest names the invoice to collect the balance of
+type CreatePaymentIntentRequest struct {
+	InvoiceID uint `json:"invoiceId" validate:"required"`
+}
+
+// CreatePaymentIntent creates a payment intent for an invoice
+// @Summary Create a payment intent
+// @Description Asks the payment gateway to collect the balance due of an invoice. The returned client secret completes the payment in the payer's browser.
+// @Tags payments
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param X-Organization-ID header int true "Organization ID"
+// @Param request body CreatePaymentIntentRequest true "Invoice to pay"
+// @Success 201 {object} repository.PaymentIntent
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /payment-intents [post]
+func (h *PaymentGatewayHandler) CreatePaymentIntent(w http.ResponseWriter, r *http.Request) {
+	organizationID, _ := r.Context().Value(middleware.OrganizationIDKey).(uint)
+
+	var req CreatePaymentIntentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeError(w, r, http.StatusBadRequest, "invalid request body", err)
+		return
+	}
+	if err := h.validator.Struct(req); err != nil {
+		h.writeError(w, r, http.StatusBadRequest, "validation failed", err)
+		return
+	}
+
+	intent, err := h.payments.CreatePaymentIntent(r.Context(), organizationID, req.InvoiceID)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrInvoiceNotFound):
+			h.writeError(w, r, http.StatusNotFound, "invoice not found", err)
+		case errors.Is(err, domain.ErrInvoiceNotPayable):
+			h.writeError(w, r, http.StatusConflict, "invoice has no balance due", err)
+		default:
+			h.logger.Error("Failed to create payment intent", zap.Error(err))
+			h.writeError(w, r, http.StatusBadGateway, "failed to create payment intent", nil)
+		}
+		return
+	}
+
+	h.writeJSON(w, http.StatusCreated, intent)
+}
+
+// HandleWebhook records the payments the gateway reports as collected
+// @Summary Receive a payment gateway webhook
+// @Description Verifies the signature of a gateway webhook delivery and records the payment it reports. Redeliveries of processed events are acknowledged with 200 and skipped. Deliveries for invoices that can no longer take the payment are acknowledged and logged for reconciliation.
+// @Tags payments
+// @Accept json
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Success 204
+// @Failure 400 {object} map[string]interface{}
+// @Router /webhooks/payments [post]
+func (h *PaymentGatewayHandler) HandleWebhook(w http.ResponseWriter, r *http.Request) {
+	payload, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxPaymentWebhookBytes))
+	if err != nil {
+		h.writeError(w, r, http.StatusBadRequest, "invalid request body", err)
+		return
+	}
+
+	result, err := h.payments.HandleWebhook(r.Context(), payload, r.Header)
+	switch {
+	case err == nil && result.Duplicate:
+		h.writeJSON(w, http.StatusOK, map[string]interface{}{"status": "already processed"})
+	case err == nil:
+		if result.Payment != nil {
+			h.logger.Info("Recorded gateway payment", zap.Uint("paymentId", result.Payment.ID), zap.Uint("invoiceId", result.Payment.InvoiceID))
+		}
+		w.WriteHeader(http.StatusNoContent)
+	case errors.Is(err, domain.ErrInvalidPaymentWebhook):
+		h.writeError(w, r, http.StatusBadRequest, "invalid webhook", nil)
+	case errors.Is(err, domain.ErrInvoiceNotFound), errors.Is(err, domain.ErrInsufficientPayment), errors.Is(err, domain.ErrPaymentNotAllowed):
+		// Redelivering won't help; acknowledge so the gateway stops retrying
+		h.logger.Error("Gateway payment needs manual reconciliation", zap.Error(err))
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		h.logger.Error("Failed to record gateway payment", zap.Error(err))
+		h.writeError(w, r, http.StatusInternalServerError, "failed to record payment", nil)
+	}
+}
+
+func (h *PaymentGatewayHandler) writeJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(data)
+}
+
+func (h *PaymentGatewayHandler) writeError(w http.ResponseWriter, r *http.Request, status int, message string, err error) {
+	response := map[string]interface{}{
+		"error":  message,
+		"status": status,
+	}
+	if traceID := middleware.GetTraceID(r.Context()); traceID != "" {
+		response["traceId"] = traceID
+	}
+	if err != nil {
+		response["details"] = err.Error()
+	}
+	h.writeJSON(w, status, response)
+}
@@ -3,14 +3,18 @@ package adapterhttp
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
 	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-playground/validator/v10"
+	"go.uber.org/fx"
 	"go.uber.org/zap"
 
+	"github.com/pmaojo/kthulu-go/backend/core"
+	"github.com/pmaojo/kthulu-go/backend/internal/adapters/http/middleware"
 	"github.com/pmaojo/kthulu-go/backend/internal/domain"
 	"github.com/pmaojo/kthulu-go/backend/internal/domain/repository"
 	"github.com/pmaojo/kthulu-go/backend/internal/usecase"
@@ -19,25 +23,47 @@ import (
 // ProductHandler handles HTTP requests for product operations
 type ProductHandler struct {
 	productUseCase *usecase.ProductUseCase
+	orgUsers       repository.OrganizationUserRepository
+	tokenManager   core.TokenManager
+	denylist       repository.AccessTokenDenylist
 	validator      *validator.Validate
 	logger         *zap.Logger
 }
 
 // NewProductHandler creates a new product handler
-func NewProductHandler(productUseCase *usecase.ProductUseCase, logger *zap.Logger) *ProductHandler {
+func NewProductHandler(p struct {
+	fx.In
+	ProductUseCase *usecase.ProductUseCase
+	OrgUsers       repository.OrganizationUserRepository
+	TokenManager   core.TokenManager
+	Denylist       repository.AccessTokenDenylist `optional:"true"`
+	Logger         *zap.Logger
+}) *ProductHandler {
 	return &ProductHandler{
-		productUseCase: productUseCase,
+		productUseCase: p.ProductUseCase,
+		orgUsers:       p.OrgUsers,
+		tokenManager:   p.TokenManager,
+		denylist:       p.Denylist,
 		validator:      validator.New(),
-		logger:         logger,
+		logger:         p.Logger,
 	}
 }
 
-// RegisterRoutes registers product routes
+// RegisterRoutes registers product routes. They are served to members of the
+// organization named by the X-Organization-ID header.
 func (h *ProductHandler) RegisterRoutes(r chi.Router) {
 	r.Route("/products", func(r chi.Router) {
+		r.Use(middleware.RequireAuth(h.tokenManager, h.denylist))
+		r.Use(middleware.OrganizationMiddleware(h.orgUsers))
+
 		r.Post("/", h.CreateProduct)
 		r.Get("/", h.ListProducts)
 		r.Get("/stats", h.GetProductStats)
+		r.Get("/top", h.GetTopProducts)
+		r.Post("/bulk/tax-rate", h.BulkUpdateTaxRate)
+		r.Post("/categories", h.CreateProductCategory)
+		r.Get("/categories", h.GetProductCategoryTree)
+		r.Get("/categories/{categoryId}/products", h.ListCategoryProducts)
 		r.Get("/{productId}", h.GetProduct)
 		r.Put("/{productId}", h.UpdateProduct)
 		r.Delete("/{productId}", h.DeleteProduct)
@@ -77,18 +103,18 @@ func (h *ProductHandler) RegisterRoutes(r chi.Router) {
 func (h *ProductHandler) CreateProduct(w http.ResponseWriter, r *http.Request) {
 	organizationID := h.getOrganizationID(r)
 	if organizationID == 0 {
-		h.writeError(w, http.StatusBadRequest, "missing organization ID", nil)
+		h.writeError(w, r, http.StatusBadRequest, "missing organization ID", nil)
 		return
 	}
 
 	var req usecase.CreateProductRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		h.writeError(w, http.StatusBadRequest, "invalid request body", err)
+		h.writeError(w, r, http.StatusBadRequest, "invalid request body", err)
 		return
 	}
 
 	if err := h.validator.Struct(req); err != nil {
-		h.writeError(w, http.StatusBadRequest, "validation failed", err)
+		h.writeError(w, r, http.StatusBadRequest, "validation failed", err)
 		return
 	}
 
@@ -96,10 +122,12 @@ func (h *ProductHandler) CreateProduct(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		switch err {
 		case domain.ErrProductAlreadyExists:
-			h.writeError(w, http.StatusConflict, "product with SKU already exists", err)
+			h.writeError(w, r, http.StatusConflict, "product with SKU already exists", err)
+		case domain.ErrProductCategoryNotFound:
+			h.writeError(w, r, http.StatusBadRequest, "product category not found", err)
 		default:
 			h.logger.Error("Failed to create product", zap.Error(err))
-			h.writeError(w, http.StatusInternalServerError, "failed to create product", err)
+			h.writeError(w, r, http.StatusInternalServerError, "failed to create product", err)
 		}
 		return
 	}
@@ -124,13 +152,13 @@ func (h *ProductHandler) CreateProduct(w http.ResponseWriter, r *http.Request) {
 func (h *ProductHandler) GetProduct(w http.ResponseWriter, r *http.Request) {
 	organizationID := h.getOrganizationID(r)
 	if organizationID == 0 {
-		h.writeError(w, http.StatusBadRequest, "missing organization ID", nil)
+		h.writeError(w, r, http.StatusBadRequest, "missing organization ID", nil)
 		return
 	}
 
 	productID, err := h.getUintParam(r, "productId")
 	if err != nil {
-		h.writeError(w, http.StatusBadRequest, "invalid product ID", err)
+		h.writeError(w, r, http.StatusBadRequest, "invalid product ID", err)
 		return
 	}
 
@@ -140,10 +168,10 @@ func (h *ProductHandler) GetProduct(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		switch err {
 		case domain.ErrProductNotFound:
-			h.writeError(w, http.StatusNotFound, "product not found", err)
+			h.writeError(w, r, http.StatusNotFound, "product not found", err)
 		default:
 			h.logger.Error("Failed to get product", zap.Error(err))
-			h.writeError(w, http.StatusInternalServerError, "failed to get product", err)
+			h.writeError(w, r, http.StatusInternalServerError, "failed to get product", err)
 		}
 		return
 	}
@@ -169,24 +197,24 @@ func (h *ProductHandler) GetProduct(w http.ResponseWriter, r *http.Request) {
 func (h *ProductHandler) UpdateProduct(w http.ResponseWriter, r *http.Request) {
 	organizationID := h.getOrganizationID(r)
 	if organizationID == 0 {
-		h.writeError(w, http.StatusBadRequest, "missing organization ID", nil)
+		h.writeError(w, r, http.StatusBadRequest, "missing organization ID", nil)
 		return
 	}
 
 	productID, err := h.getUintParam(r, "productId")
 	if err != nil {
-		h.writeError(w, http.StatusBadRequest, "invalid product ID", err)
+		h.writeError(w, r, http.StatusBadRequest, "invalid product ID", err)
 		return
 	}
 
 	var req usecase.UpdateProductRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		h.writeError(w, http.StatusBadRequest, "invalid request body", err)
+		h.writeError(w, r, http.StatusBadRequest, "invalid request body", err)
 		return
 	}
 
 	if err := h.validator.Struct(req); err != nil {
-		h.writeError(w, http.StatusBadRequest, "validation failed", err)
+		h.writeError(w, r, http.StatusBadRequest, "validation failed", err)
 		return
 	}
 
@@ -194,10 +222,12 @@ func (h *ProductHandler) UpdateProduct(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		switch err {
 		case domain.ErrProductNotFound:
-			h.writeError(w, http.StatusNotFound, "product not found", err)
+			h.writeError(w, r, http.StatusNotFound, "product not found", err)
+		case domain.ErrProductCategoryNotFound:
+			h.writeError(w, r, http.StatusBadRequest, "product category not found", err)
 		default:
 			h.logger.Error("Failed to update product", zap.Error(err))
-			h.writeError(w, http.StatusInternalServerError, "failed to update product", err)
+			h.writeError(w, r, http.StatusInternalServerError, "failed to update product", err)
 		}
 		return
 	}
@@ -220,13 +250,13 @@ func (h *ProductHandler) UpdateProduct(w http.ResponseWriter, r *http.Request) {
 func (h *ProductHandler) DeleteProduct(w http.ResponseWriter, r *http.Request) {
 	organizationID := h.getOrganizationID(r)
 	if organizationID == 0 {
-		h.writeError(w, http.StatusBadRequest, "missing organization ID", nil)
+		h.writeError(w, r, http.StatusBadRequest, "missing organization ID", nil)
 		return
 	}
 
 	productID, err := h.getUintParam(r, "productId")
 	if err != nil {
-		h.writeError(w, http.StatusBadRequest, "invalid product ID", err)
+		h.writeError(w, r, http.StatusBadRequest, "invalid product ID", err)
 		return
 	}
 
@@ -234,10 +264,10 @@ func (h *ProductHandler) DeleteProduct(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		switch err {
 		case domain.ErrProductNotFound:
-			h.writeError(w, http.StatusNotFound, "product not found", err)
+			h.writeError(w, r, http.StatusNotFound, "product not found", err)
 		default:
 			h.logger.Error("Failed to delete product", zap.Error(err))
-			h.writeError(w, http.StatusInternalServerError, "failed to delete product", err)
+			h.writeError(w, r, http.StatusInternalServerError, "failed to delete product", err)
 		}
 		return
 	}
@@ -254,6 +284,7 @@ func (h *ProductHandler) DeleteProduct(w http.ResponseWriter, r *http.Request) {
 // @Param page query int false "Page number (default: 1)"
 // @Param pageSize query int false "Page size (default: 20, max: 100)"
 // @Param category query string false "Filter by category"
+// @Param categoryId query int false "Filter by category tree node, including its descendants"
 // @Param brand query string false "Filter by brand"
 // @Param isActive query bool false "Filter by active status"
 // @Param isTrackable query bool false "Filter by trackable status"
@@ -262,6 +293,7 @@ func (h *ProductHandler) DeleteProduct(w http.ResponseWriter, r *http.Request) {
 // @Param sortOrder query string false "Sort order (asc, desc)"
 // @Param includeVariants query bool false "Include product variants"
 // @Param includePrices query bool false "Include product prices"
+// @Param fields query string false "Comma-separated product fields to return"
 // @Success 200 {object} usecase.ProductListResponse
 // @Failure 400 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
@@ -270,20 +302,36 @@ func (h *ProductHandler) DeleteProduct(w http.ResponseWriter, r *http.Request) {
 func (h *ProductHandler) ListProducts(w http.ResponseWriter, r *http.Request) {
 	organizationID := h.getOrganizationID(r)
 	if organizationID == 0 {
-		h.writeError(w, http.StatusBadRequest, "missing organization ID", nil)
+		h.writeError(w, r, http.StatusBadRequest, "missing organization ID", nil)
 		return
 	}
 
 	filters := h.parseProductFilters(r)
+	fields, err := parseFields(r, domain.Product{})
+	if err != nil {
+		h.writeError(w, r, http.StatusBadRequest, "invalid fields", err)
+		return
+	}
 
 	response, err := h.productUseCase.ListProducts(r.Context(), organizationID, filters)
 	if err != nil {
 		h.logger.Error("Failed to list products", zap.Error(err))
-		h.writeError(w, http.StatusInternalServerError, "failed to list products", err)
+		h.writeError(w, r, http.StatusInternalServerError, "failed to list products", err)
 		return
 	}
 
-	h.writeJSON(w, http.StatusOK, response)
+	setPaginationHeaders(w, r, response.Page, response.PageSize, response.Total)
+	if fields == nil {
+		h.writeJSON(w, http.StatusOK, response)
+		return
+	}
+	sparse, err := sparseFieldset(response, "data", fields)
+	if err != nil {
+		h.logger.Error("Failed to select product fields", zap.Error(err))
+		h.writeError(w, r, http.StatusInternalServerError, "failed to list products", err)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, sparse)
 }
 
 // SetProductStatus sets the active status of a product
@@ -303,13 +351,13 @@ func (h *ProductHandler) ListProducts(w http.ResponseWriter, r *http.Request) {
 func (h *ProductHandler) SetProductStatus(w http.ResponseWriter, r *http.Request) {
 	organizationID := h.getOrganizationID(r)
 	if organizationID == 0 {
-		h.writeError(w, http.StatusBadRequest, "missing organization ID", nil)
+		h.writeError(w, r, http.StatusBadRequest, "missing organization ID", nil)
 		return
 	}
 
 	productID, err := h.getUintParam(r, "productId")
 	if err != nil {
-		h.writeError(w, http.StatusBadRequest, "invalid product ID", err)
+		h.writeError(w, r, http.StatusBadRequest, "invalid product ID", err)
 		return
 	}
 
@@ -317,7 +365,7 @@ func (h *ProductHandler) SetProductStatus(w http.ResponseWriter, r *http.Request
 		Active bool `json:"active"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		h.writeError(w, http.StatusBadRequest, "invalid request body", err)
+		h.writeError(w, r, http.StatusBadRequest, "invalid request body", err)
 		return
 	}
 
@@ -325,10 +373,10 @@ func (h *ProductHandler) SetProductStatus(w http.ResponseWriter, r *http.Request
 	if err != nil {
 		switch err {
 		case domain.ErrProductNotFound:
-			h.writeError(w, http.StatusNotFound, "product not found", err)
+			h.writeError(w, r, http.StatusNotFound, "product not found", err)
 		default:
 			h.logger.Error("Failed to set product status", zap.Error(err))
-			h.writeError(w, http.StatusInternalServerError, "failed to set product status", err)
+			h.writeError(w, r, http.StatusInternalServerError, "failed to set product status", err)
 		}
 		return
 	}
@@ -350,42 +398,257 @@ func (h *ProductHandler) SetProductStatus(w http.ResponseWriter, r *http.Request
 func (h *ProductHandler) GetProductStats(w http.ResponseWriter, r *http.Request) {
 	organizationID := h.getOrganizationID(r)
 	if organizationID == 0 {
-		h.writeError(w, http.StatusBadRequest, "missing organization ID", nil)
+		h.writeError(w, r, http.StatusBadRequest, "missing organization ID", nil)
 		return
 	}
 
 	stats, err := h.productUseCase.GetProductStats(r.Context(), organizationID)
 	if err != nil {
 		h.logger.Error("Failed to get product stats", zap.Error(err))
-		h.writeError(w, http.StatusInternalServerError, "failed to get product stats", err)
+		h.writeError(w, r, http.StatusInternalServerError, "failed to get product stats", err)
 		return
 	}
 
 	h.writeJSON(w, http.StatusOK, stats)
 }
 
+// GetTopProducts retrieves the best-selling products
+// @Summary Get top-selling products
+// @Description Rank products by the quantity invoiced between two dates (inclusive), ignoring draft and canceled invoices. Defaults to the last 12 months.
+// @Tags products
+// @Produce json
+// @Param organizationId header string true "Organization ID"
+// @Param from query string false "First day (YYYY-MM-DD)"
+// @Param to query string false "Last day (YYYY-MM-DD)"
+// @Param limit query int false "Number of products (default: 10, max: 100)"
+// @Success 200 {array} repository.ProductSales
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /products/top [get]
+func (h *ProductHandler) GetTopProducts(w http.ResponseWriter, r *http.Request) {
+	organizationID := h.getOrganizationID(r)
+	if organizationID == 0 {
+		h.writeError(w, r, http.StatusBadRequest, "missing organization ID", nil)
+		return
+	}
+
+	from, to, err := parseRevenuePeriod(r, time.Now())
+	if err != nil {
+		h.writeError(w, r, http.StatusBadRequest, "invalid period", err)
+		return
+	}
+	limit := 0
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		if limit, err = strconv.Atoi(limitStr); err != nil {
+			h.writeError(w, r, http.StatusBadRequest, "invalid limit", err)
+			return
+		}
+	}
+
+	ranking, err := h.productUseCase.GetTopProducts(r.Context(), organizationID, from, to, limit)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidStatsPeriod) {
+			h.writeError(w, r, http.StatusBadRequest, "invalid period", err)
+			return
+		}
+		h.logger.Error("Failed to get top products", zap.Error(err))
+		h.writeError(w, r, http.StatusInternalServerError, "failed to get top products", err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, ranking)
+}
+
+// BulkUpdateTaxRate updates the tax rate of several products
+// @Summary Bulk update product tax rate
+// @Description Set the tax rate of several products at once, for instance after a change in tax law. The rate is a percentage between 0 and 100.
+// @Tags products
+// @Accept json
+// @Param organizationId header string true "Organization ID"
+// @Param request body usecase.BulkTaxRateRequest true "Product IDs and tax rate percentage"
+// @Success 204
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /products/bulk/tax-rate [post]
+func (h *ProductHandler) BulkUpdateTaxRate(w http.ResponseWriter, r *http.Request) {
+	organizationID := h.getOrganizationID(r)
+	if organizationID == 0 {
+		h.writeError(w, r, http.StatusBadRequest, "missing organization ID", nil)
+		return
+	}
+
+	var req usecase.BulkTaxRateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeError(w, r, http.StatusBadRequest, "invalid request body", err)
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		h.writeError(w, r, http.StatusBadRequest, "validation failed", err)
+		return
+	}
+
+	if err := h.productUseCase.BulkUpdateTaxRate(r.Context(), organizationID, req); err != nil {
+		if errors.Is(err, domain.ErrInvalidTaxRate) {
+			h.writeError(w, r, http.StatusBadRequest, "invalid tax rate", err)
+			return
+		}
+		h.logger.Error("Failed to bulk update product tax rate", zap.Error(err))
+		h.writeError(w, r, http.StatusInternalServerError, "failed to update product tax rate", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// CreateProductCategory creates a product category
+// @Summary Create a product category
+// @Description Create a root category, or a subcategory when parentId is set
+// @Tags products
+// @Accept json
+// @Produce json
+// @Param organizationId header string true "Organization ID"
+// @Param category body usecase.CreateCategoryRequest true "Category data"
+// @Success 201 {object} domain.ProductCategory
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /products/categories [post]
+func (h *ProductHandler) CreateProductCategory(w http.ResponseWriter, r *http.Request) {
+	organizationID := h.getOrganizationID(r)
+	if organizationID == 0 {
+		h.writeError(w, r, http.StatusBadRequest, "missing organization ID", nil)
+		return
+	}
+
+	var req usecase.CreateCategoryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeError(w, r, http.StatusBadRequest, "invalid request body", err)
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		h.writeError(w, r, http.StatusBadRequest, "validation failed", err)
+		return
+	}
+
+	category, err := h.productUseCase.CreateCategory(r.Context(), organizationID, req)
+	if err != nil {
+		switch err {
+		case domain.ErrInvalidProductCategory:
+			h.writeError(w, r, http.StatusBadRequest, "invalid product category", err)
+		case domain.ErrProductCategoryNotFound:
+			h.writeError(w, r, http.StatusNotFound, "parent category not found", err)
+		default:
+			h.logger.Error("Failed to create product category", zap.Error(err))
+			h.writeError(w, r, http.StatusInternalServerError, "failed to create product category", err)
+		}
+		return
+	}
+
+	h.writeJSON(w, http.StatusCreated, category)
+}
+
+// GetProductCategoryTree retrieves the category tree
+// @Summary Get the product category tree
+// @Description Retrieve the root categories of the organization with their nested children
+// @Tags products
+// @Produce json
+// @Param organizationId header string true "Organization ID"
+// @Success 200 {array} domain.ProductCategory
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /products/categories [get]
+func (h *ProductHandler) GetProductCategoryTree(w http.ResponseWriter, r *http.Request) {
+	organizationID := h.getOrganizationID(r)
+	if organizationID == 0 {
+		h.writeError(w, r, http.StatusBadRequest, "missing organization ID", nil)
+		return
+	}
+
+	tree, err := h.productUseCase.GetCategoryTree(r.Context(), organizationID)
+	if err != nil {
+		h.logger.Error("Failed to get product categories", zap.Error(err))
+		h.writeError(w, r, http.StatusInternalServerError, "failed to get product categories", err)
+		return
+	}
+	if tree == nil {
+		tree = []*domain.ProductCategory{}
+	}
+
+	h.writeJSON(w, http.StatusOK, tree)
+}
+
+// ListCategoryProducts lists the products of a category and its descendants
+// @Summary List products in a category
+// @Description List the products of a category and of all its subcategories. Accepts the same filters as the product list.
+// @Tags products
+// @Produce json
+// @Param organizationId header string true "Organization ID"
+// @Param categoryId path string true "Category ID"
+// @Param page query int false "Page number (default: 1)"
+// @Param pageSize query int false "Page size (default: 20, max: 100)"
+// @Success 200 {object} usecase.ProductListResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /products/categories/{categoryId}/products [get]
+func (h *ProductHandler) ListCategoryProducts(w http.ResponseWriter, r *http.Request) {
+	organizationID := h.getOrganizationID(r)
+	if organizationID == 0 {
+		h.writeError(w, r, http.StatusBadRequest, "missing organization ID", nil)
+		return
+	}
+
+	categoryID, err := h.getUintParam(r, "categoryId")
+	if err != nil || categoryID == 0 {
+		h.writeError(w, r, http.StatusBadRequest, "invalid category ID", err)
+		return
+	}
+
+	response, err := h.productUseCase.ListCategoryProducts(r.Context(), organizationID, categoryID, h.parseProductFilters(r))
+	if err != nil {
+		switch err {
+		case domain.ErrProductCategoryNotFound:
+			h.writeError(w, r, http.StatusNotFound, "product category not found", err)
+		default:
+			h.logger.Error("Failed to list category products", zap.Error(err))
+			h.writeError(w, r, http.StatusInternalServerError, "failed to list products", err)
+		}
+		return
+	}
+
+	setPaginationHeaders(w, r, response.Page, response.PageSize, response.Total)
+	h.writeJSON(w, http.StatusOK, response)
+}
+
 // CreateProductVariant creates a new product variant
 func (h *ProductHandler) CreateProductVariant(w http.ResponseWriter, r *http.Request) {
 	organizationID := h.getOrganizationID(r)
 	if organizationID == 0 {
-		h.writeError(w, http.StatusBadRequest, "missing organization ID", nil)
+		h.writeError(w, r, http.StatusBadRequest, "missing organization ID", nil)
 		return
 	}
 
 	productID, err := h.getUintParam(r, "productId")
 	if err != nil {
-		h.writeError(w, http.StatusBadRequest, "invalid product ID", err)
+		h.writeError(w, r, http.StatusBadRequest, "invalid product ID", err)
 		return
 	}
 
 	var req usecase.CreateVariantRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		h.writeError(w, http.StatusBadRequest, "invalid request body", err)
+		h.writeError(w, r, http.StatusBadRequest, "invalid request body", err)
 		return
 	}
 
 	if err := h.validator.Struct(req); err != nil {
-		h.writeError(w, http.StatusBadRequest, "validation failed", err)
+		h.writeError(w, r, http.StatusBadRequest, "validation failed", err)
 		return
 	}
 
@@ -393,12 +656,12 @@ func (h *ProductHandler) CreateProductVariant(w http.ResponseWriter, r *http.Req
 	if err != nil {
 		switch err {
 		case domain.ErrProductNotFound:
-			h.writeError(w, http.StatusNotFound, "product not found", err)
+			h.writeError(w, r, http.StatusNotFound, "product not found", err)
 		case domain.ErrVariantAlreadyExists:
-			h.writeError(w, http.StatusConflict, "variant with SKU already exists", err)
+			h.writeError(w, r, http.StatusConflict, "variant with SKU already exists", err)
 		default:
 			h.logger.Error("Failed to create product variant", zap.Error(err))
-			h.writeError(w, http.StatusInternalServerError, "failed to create variant", err)
+			h.writeError(w, r, http.StatusInternalServerError, "failed to create variant", err)
 		}
 		return
 	}
@@ -410,18 +673,18 @@ func (h *ProductHandler) CreateProductVariant(w http.ResponseWriter, r *http.Req
 func (h *ProductHandler) CreateProductPrice(w http.ResponseWriter, r *http.Request) {
 	organizationID := h.getOrganizationID(r)
 	if organizationID == 0 {
-		h.writeError(w, http.StatusBadRequest, "missing organization ID", nil)
+		h.writeError(w, r, http.StatusBadRequest, "missing organization ID", nil)
 		return
 	}
 
 	var req usecase.CreatePriceRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		h.writeError(w, http.StatusBadRequest, "invalid request body", err)
+		h.writeError(w, r, http.StatusBadRequest, "invalid request body", err)
 		return
 	}
 
 	if err := h.validator.Struct(req); err != nil {
-		h.writeError(w, http.StatusBadRequest, "validation failed", err)
+		h.writeError(w, r, http.StatusBadRequest, "validation failed", err)
 		return
 	}
 
@@ -429,12 +692,12 @@ func (h *ProductHandler) CreateProductPrice(w http.ResponseWriter, r *http.Reque
 	if err != nil {
 		switch err {
 		case domain.ErrProductNotFound:
-			h.writeError(w, http.StatusNotFound, "product not found", err)
+			h.writeError(w, r, http.StatusNotFound, "product not found", err)
 		case domain.ErrVariantNotFound:
-			h.writeError(w, http.StatusNotFound, "variant not found", err)
+			h.writeError(w, r, http.StatusNotFound, "variant not found", err)
 		default:
 			h.logger.Error("Failed to create product price", zap.Error(err))
-			h.writeError(w, http.StatusInternalServerError, "failed to create price", err)
+			h.writeError(w, r, http.StatusInternalServerError, "failed to create price", err)
 		}
 		return
 	}
@@ -446,7 +709,7 @@ func (h *ProductHandler) CreateProductPrice(w http.ResponseWriter, r *http.Reque
 func (h *ProductHandler) GetEffectivePrice(w http.ResponseWriter, r *http.Request) {
 	organizationID := h.getOrganizationID(r)
 	if organizationID == 0 {
-		h.writeError(w, http.StatusBadRequest, "missing organization ID", nil)
+		h.writeError(w, r, http.StatusBadRequest, "missing organization ID", nil)
 		return
 	}
 
@@ -489,7 +752,7 @@ func (h *ProductHandler) GetEffectivePrice(w http.ResponseWriter, r *http.Reques
 	}
 
 	if err := h.validator.Struct(req); err != nil {
-		h.writeError(w, http.StatusBadRequest, "validation failed", err)
+		h.writeError(w, r, http.StatusBadRequest, "validation failed", err)
 		return
 	}
 
@@ -497,14 +760,14 @@ func (h *ProductHandler) GetEffectivePrice(w http.ResponseWriter, r *http.Reques
 	if err != nil {
 		switch err {
 		case domain.ErrProductNotFound:
-			h.writeError(w, http.StatusNotFound, "product not found", err)
+			h.writeError(w, r, http.StatusNotFound, "product not found", err)
 		case domain.ErrVariantNotFound:
-			h.writeError(w, http.StatusNotFound, "variant not found", err)
+			h.writeError(w, r, http.StatusNotFound, "variant not found", err)
 		case domain.ErrPriceNotFound:
-			h.writeError(w, http.StatusNotFound, "no effective price found", err)
+			h.writeError(w, r, http.StatusNotFound, "no effective price found", err)
 		default:
 			h.logger.Error("Failed to get effective price", zap.Error(err))
-			h.writeError(w, http.StatusInternalServerError, "failed to get effective price", err)
+			h.writeError(w, r, http.StatusInternalServerError, "failed to get effective price", err)
 		}
 		return
 	}
@@ -514,48 +777,44 @@ func (h *ProductHandler) GetEffectivePrice(w http.ResponseWriter, r *http.Reques
 
 // Placeholder implementations for remaining handlers
 func (h *ProductHandler) GetProductVariants(w http.ResponseWriter, r *http.Request) {
-	h.writeError(w, http.StatusNotImplemented, "not implemented", nil)
+	h.writeError(w, r, http.StatusNotImplemented, "not implemented", nil)
 }
 
 func (h *ProductHandler) GetProductVariant(w http.ResponseWriter, r *http.Request) {
-	h.writeError(w, http.StatusNotImplemented, "not implemented", nil)
+	h.writeError(w, r, http.StatusNotImplemented, "not implemented", nil)
 }
 
 func (h *ProductHandler) UpdateProductVariant(w http.ResponseWriter, r *http.Request) {
-	h.writeError(w, http.StatusNotImplemented, "not implemented", nil)
+	h.writeError(w, r, http.StatusNotImplemented, "not implemented", nil)
 }
 
 func (h *ProductHandler) DeleteProductVariant(w http.ResponseWriter, r *http.Request) {
-	h.writeError(w, http.StatusNotImplemented, "not implemented", nil)
+	h.writeError(w, r, http.StatusNotImplemented, "not implemented", nil)
 }
 
 func (h *ProductHandler) GetProductPrices(w http.ResponseWriter, r *http.Request) {
-	h.writeError(w, http.StatusNotImplemented, "not implemented", nil)
+	h.writeError(w, r, http.StatusNotImplemented, "not implemented", nil)
 }
 
 func (h *ProductHandler) GetVariantPrices(w http.ResponseWriter, r *http.Request) {
-	h.writeError(w, http.StatusNotImplemented, "not implemented", nil)
+	h.writeError(w, r, http.StatusNotImplemented, "not implemented", nil)
 }
 
 func (h *ProductHandler) UpdateProductPrice(w http.ResponseWriter, r *http.Request) {
-	h.writeError(w, http.StatusNotImplemented, "not implemented", nil)
+	h.writeError(w, r, http.StatusNotImplemented, "not implemented", nil)
 }
 
 func (h *ProductHandler) DeleteProductPrice(w http.ResponseWriter, r *http.Request) {
-	h.writeError(w, http.StatusNotImplemented, "not implemented", nil)
+	h.writeError(w, r, http.StatusNotImplemented, "not implemented", nil)
 }
 
 // Helper methods
 
+// getOrganizationID returns the organization OrganizationMiddleware resolved
+// for the request
 func (h *ProductHandler) getOrganizationID(r *http.Request) uint {
-	// This should be extracted from JWT token or header
-	// For now, we'll use a header value
-	if orgIDStr := r.Header.Get("X-Organization-ID"); orgIDStr != "" {
-		if orgID, err := strconv.ParseUint(orgIDStr, 10, 32); err == nil {
-			return uint(orgID)
-		}
-	}
-	return 0
+	organizationID, _ := getOrganizationIDFromContext(r.Context())
+	return organizationID
 }
 
 func (h *ProductHandler) getUintParam(r *http.Request, param string) (uint, error) {
@@ -579,6 +838,13 @@ func (h *ProductHandler) parseProductFilters(r *http.Request) repository.Product
 		filters.Category = category
 	}
 
+	if categoryIDStr := r.URL.Query().Get("categoryId"); categoryIDStr != "" {
+		if categoryID, err := strconv.ParseUint(categoryIDStr, 10, 32); err == nil {
+			id := uint(categoryID)
+			filters.CategoryID = &id
+		}
+	}
+
 	if brand := r.URL.Query().Get("brand"); brand != "" {
 		filters.Brand = brand
 	}
@@ -599,17 +865,7 @@ func (h *ProductHandler) parseProductFilters(r *http.Request) repository.Product
 		filters.Search = search
 	}
 
-	if pageStr := r.URL.Query().Get("page"); pageStr != "" {
-		if page, err := strconv.Atoi(pageStr); err == nil && page > 0 {
-			filters.Page = page
-		}
-	}
-
-	if pageSizeStr := r.URL.Query().Get("pageSize"); pageSizeStr != "" {
-		if pageSize, err := strconv.Atoi(pageSizeStr); err == nil && pageSize > 0 && pageSize <= 100 {
-			filters.PageSize = pageSize
-		}
-	}
+	filters.Page, filters.PageSize = parsePagination(r, filters.PageSize)
 
 	if sortBy := r.URL.Query().Get("sortBy"); sortBy != "" {
 		filters.SortBy = sortBy
@@ -640,7 +896,7 @@ func (h *ProductHandler) writeJSON(w http.ResponseWriter, status int, data inter
 	json.NewEncoder(w).Encode(data)
 }
 
-func (h *ProductHandler) writeError(w http.ResponseWriter, status int, message string, err error) {
+func (h *ProductHandler) writeError(w http.ResponseWriter, r *http.Request, status int, message string, err error) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
 
@@ -648,6 +904,9 @@ func (h *ProductHandler) writeError(w http.ResponseWriter, status int, message s
 		"error":  message,
 		"status": status,
 	}
+	if traceID := middleware.GetTraceID(r.Context()); traceID != "" {
+		response["traceId"] = traceID
+	}
 
 	if err != nil {
 		response["details"] = err.Error()
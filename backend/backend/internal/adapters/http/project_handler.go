@@ -258,23 +258,7 @@ func (h *ProjectHandler) deleteProject(w http.ResponseWriter, r *http.Request) {
 func (h *ProjectHandler) listProjects(w http.ResponseWriter, r *http.Request) {
 	logger := h.log
 
-	limitStr := r.URL.Query().Get("limit")
-	offsetStr := r.URL.Query().Get("offset")
-
-	limit := 10 // default
-	offset := 0 // default
-
-	if limitStr != "" {
-		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 {
-			limit = l
-		}
-	}
-
-	if offsetStr != "" {
-		if o, err := strconv.Atoi(offsetStr); err == nil && o >= 0 {
-			offset = o
-		}
-	}
+	limit, offset := parseLimitOffset(r, 10)
 
 	logger.Infow("List projects request", "limit", limit, "offset", offset)
 
@@ -8,15 +8,18 @@ import (
 
 // RealtimeHandler registers WebSocket routes.
 type RealtimeHandler struct {
-	ws *adapterrealtime.Handler
+	ws            *adapterrealtime.Handler
+	notifications *adapterrealtime.NotificationHub
 }
 
 // NewRealtimeHandler creates a new RealtimeHandler.
-func NewRealtimeHandler(ws *adapterrealtime.Handler) *RealtimeHandler {
-	return &RealtimeHandler{ws: ws}
+func NewRealtimeHandler(ws *adapterrealtime.Handler, notifications *adapterrealtime.NotificationHub) *RealtimeHandler {
+	return &RealtimeHandler{ws: ws, notifications: notifications}
 }
 
 // RegisterRoutes registers the realtime routes.
 func (h *RealtimeHandler) RegisterRoutes(r chi.Router) {
 	r.Handle("/ws", h.ws)
+	// Per-user notifications; authenticated on connect by the hub itself
+	r.Handle("/ws/notifications", h.notifications)
 }
@@ -53,8 +53,7 @@ func (h *TemplateHandler) RegisterRoutes(r chi.Router) {
 func (h *TemplateHandler) listTemplates(w http.ResponseWriter, r *http.Request) {
 	logger := h.log
 
-	limit := 50 // default
-	offset := 0 // default
+	limit, offset := parseLimitOffset(r, 50)
 
 	logger.Infow("List templates request", "limit", limit, "offset", offset)
 
@@ -11,6 +11,7 @@ import (
 	"github.com/pmaojo/kthulu-go/backend/core"
 	"github.com/pmaojo/kthulu-go/backend/internal/adapters/http/middleware"
 	"github.com/pmaojo/kthulu-go/backend/internal/domain"
+	"github.com/pmaojo/kthulu-go/backend/internal/domain/repository"
 	"github.com/pmaojo/kthulu-go/backend/internal/usecase"
 )
 
@@ -18,14 +19,16 @@ import (
 type UserHandler struct {
 	user         *usecase.UserUseCase
 	tokenManager core.TokenManager
+	denylist     repository.AccessTokenDenylist
 	log          *zap.SugaredLogger
 }
 
 // NewUserHandler constructs UserHandler with required dependencies.
-func NewUserHandler(user *usecase.UserUseCase, tokenManager core.TokenManager, logger *zap.Logger) *UserHandler {
+func NewUserHandler(user *usecase.UserUseCase, tokenManager core.TokenManager, denylist repository.AccessTokenDenylist, logger *zap.Logger) *UserHandler {
 	return &UserHandler{
 		user:         user,
 		tokenManager: tokenManager,
+		denylist:     denylist,
 		log:          logger.Sugar(),
 	}
 }
@@ -34,7 +37,7 @@ func NewUserHandler(user *usecase.UserUseCase, tokenManager core.TokenManager, l
 func (h *UserHandler) RegisterRoutes(r chi.Router) {
 	// Protected routes that require authentication
 	r.Group(func(r chi.Router) {
-		r.Use(middleware.RequireAuth(h.tokenManager))
+		r.Use(middleware.RequireAuth(h.tokenManager, h.denylist))
 		r.Get("/users/me", instrumentHandler("user.getProfile", h.getProfile))
 		r.Patch("/users/me", instrumentHandler("user.updateProfile", h.updateProfile))
 	})
@@ -10,6 +10,7 @@ import (
 
 	"github.com/go-chi/chi/v5"
 
+	"github.com/pmaojo/kthulu-go/backend/internal/adapters/http/middleware"
 	"github.com/pmaojo/kthulu-go/backend/internal/adapters/http/modules/verifactu"
 )
 
@@ -38,7 +39,7 @@ func (h *VerifactuHandler) CancelRecord(w http.ResponseWriter, r *http.Request)
 	recordIDStr := chi.URLParam(r, "id")
 	recordID, err := strconv.Atoi(recordIDStr)
 	if err != nil {
-		h.writeError(w, http.StatusBadRequest, "invalid record ID", err)
+		h.writeError(w, r, http.StatusBadRequest, "invalid record ID", err)
 		return
 	}
 
@@ -47,7 +48,7 @@ func (h *VerifactuHandler) CancelRecord(w http.ResponseWriter, r *http.Request)
 
 	record, err := h.service.CancelRecord(r.Context(), recordID, userID)
 	if err != nil {
-		h.writeError(w, http.StatusInternalServerError, "failed to cancel record", err)
+		h.writeError(w, r, http.StatusInternalServerError, "failed to cancel record", err)
 		return
 	}
 
@@ -58,18 +59,18 @@ func (h *VerifactuHandler) CancelRecord(w http.ResponseWriter, r *http.Request)
 func (h *VerifactuHandler) ExportRecords(w http.ResponseWriter, r *http.Request) {
 	orgIDStr := r.URL.Query().Get("org")
 	if orgIDStr == "" {
-		h.writeError(w, http.StatusBadRequest, "organization ID required", nil)
+		h.writeError(w, r, http.StatusBadRequest, "organization ID required", nil)
 		return
 	}
 	orgID, err := strconv.Atoi(orgIDStr)
 	if err != nil {
-		h.writeError(w, http.StatusBadRequest, "invalid organization ID", err)
+		h.writeError(w, r, http.StatusBadRequest, "invalid organization ID", err)
 		return
 	}
 
 	data, sig, err := h.service.ExportRecords(r.Context(), orgID)
 	if err != nil {
-		h.writeError(w, http.StatusInternalServerError, "failed to export records", err)
+		h.writeError(w, r, http.StatusInternalServerError, "failed to export records", err)
 		return
 	}
 
@@ -84,7 +85,7 @@ func (h *VerifactuHandler) ExportRecords(w http.ResponseWriter, r *http.Request)
 func (h *VerifactuHandler) GetConfig(w http.ResponseWriter, r *http.Request) {
 	cfg, err := h.service.Config(r.Context())
 	if err != nil {
-		h.writeError(w, http.StatusInternalServerError, "failed to load config", err)
+		h.writeError(w, r, http.StatusInternalServerError, "failed to load config", err)
 		return
 	}
 	h.writeJSON(w, http.StatusOK, cfg)
@@ -97,16 +98,16 @@ func (h *VerifactuHandler) UpdateConfig(w http.ResponseWriter, r *http.Request)
 		Mode    string `json:"mode"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		h.writeError(w, http.StatusBadRequest, "invalid request", err)
+		h.writeError(w, r, http.StatusBadRequest, "invalid request", err)
 		return
 	}
 	cfg, err := h.service.UpdateConfig(r.Context(), req.SIFCode, req.Mode)
 	if err != nil {
 		if err == verifactu.ErrModeFrozen {
-			h.writeError(w, http.StatusBadRequest, "live mode active", err)
+			h.writeError(w, r, http.StatusBadRequest, "live mode active", err)
 			return
 		}
-		h.writeError(w, http.StatusInternalServerError, "failed to update config", err)
+		h.writeError(w, r, http.StatusInternalServerError, "failed to update config", err)
 		return
 	}
 	h.writeJSON(w, http.StatusOK, cfg)
@@ -118,10 +119,13 @@ func (h *VerifactuHandler) writeJSON(w http.ResponseWriter, status int, data int
 	json.NewEncoder(w).Encode(data)
 }
 
-func (h *VerifactuHandler) writeError(w http.ResponseWriter, status int, message string, err error) {
+func (h *VerifactuHandler) writeError(w http.ResponseWriter, r *http.Request, status int, message string, err error) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
 	resp := map[string]interface{}{"error": message, "status": status}
+	if traceID := middleware.GetTraceID(r.Context()); traceID != "" {
+		resp["traceId"] = traceID
+	}
 	if err != nil {
 		resp["details"] = err.Error()
 	}
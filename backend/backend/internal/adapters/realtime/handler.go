@@ -2,42 +2,53 @@ package adapterrealtime
 
 import (
 	"net/http"
+	"time"
 
-	"nhooyr.io/websocket"
+	"github.com/gorilla/websocket"
 
 	"github.com/pmaojo/kthulu-go/backend/internal/domain/repository"
 )
 
+// closeTimeout bounds writing the close frame of a connection
+const closeTimeout = time.Second
+
 // Handler upgrades HTTP connections to WebSocket and tracks clients.
 type Handler struct {
-	repo repository.ConnectionRepository
+	repo     repository.ConnectionRepository
+	upgrader *websocket.Upgrader
 }
 
 // NewHandler creates a WebSocket handler.
 func NewHandler(repo repository.ConnectionRepository) *Handler {
-	return &Handler{repo: repo}
+	return &Handler{repo: repo, upgrader: newUpgrader()}
 }
 
 // ServeHTTP handles the WebSocket handshake and lifecycle.
 func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	c, err := websocket.Accept(w, r, nil)
+	c, err := h.upgrader.Upgrade(w, r, nil)
 	if err != nil {
 		return
 	}
+	defer c.Close()
 	ctx := r.Context()
 	id, err := h.repo.Add(ctx, c)
 	if err != nil {
-		c.Close(websocket.StatusInternalError, "store failed")
+		closeConn(c, websocket.CloseInternalServerErr, "store failed")
 		return
 	}
 	defer func() {
 		h.repo.Remove(ctx, id)
-		c.Close(websocket.StatusNormalClosure, "closed")
+		closeConn(c, websocket.CloseNormalClosure, "closed")
 	}()
 
 	for {
-		if _, _, err := c.Read(ctx); err != nil {
+		if _, _, err := c.ReadMessage(); err != nil {
 			break
 		}
 	}
 }
+
+// closeConn sends a close frame with code and reason
+func closeConn(c *websocket.Conn, code int, reason string) {
+	_ = c.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, reason), time.Now().Add(closeTimeout))
+}
@@ -1,8 +1,16 @@
 package adapterrealtime
 
-import "go.uber.org/fx"
+import (
+	"go.uber.org/fx"
+
+	"github.com/pmaojo/kthulu-go/backend/internal/domain/repository"
+)
 
 // Module provides the realtime adapter for Fx.
 var Module = fx.Options(
-	fx.Provide(NewHandler),
+	fx.Provide(
+		NewHandler,
+		NewNotificationHub,
+		func(h *NotificationHub) repository.UserNotifier { return h },
+	),
 )
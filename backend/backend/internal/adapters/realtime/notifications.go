@@ -0,0 +1,234 @@
+package adapterrealtime
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+
+	"github.com/pmaojo/kthulu-go/backend/core"
+	"github.com/pmaojo/kthulu-go/backend/internal/domain/repository"
+)
+
+const (
+	// notificationPingInterval is how often idle connections are pinged to
+	// detect dead peers and keep proxies from timing them out
+	notificationPingInterval = 30 * time.Second
+	// notificationWriteTimeout bounds a single write or ping
+	notificationWriteTimeout = 10 * time.Second
+	// notificationBufferSize is how many notifications a connection may have
+	// pending before it's dropped as too slow
+	notificationBufferSize = 16
+	// notificationReadLimit bounds the messages clients may send, which are
+	// discarded anyway
+	notificationReadLimit = 512
+)
+
+// NotificationHubParams holds the dependencies of the notification hub.
+type NotificationHubParams struct {
+	fx.In
+	TokenManager core.TokenManager
+	Denylist     repository.AccessTokenDenylist `optional:"true"`
+	Logger       *zap.Logger
+}
+
+// NotificationHub pushes user notifications to the WebSocket connections of
+// the users they're addressed to. Clients authenticate on connect with an
+// access token, sent as a bearer Authorization header or, since browsers
+// can't set headers on WebSocket requests, an access_token query parameter.
+// Browser requests carrying the token are only accepted from pages of the
+// same origin, so other sites can't connect with a token they obtained.
+type NotificationHub struct {
+	tokens   core.TokenManager
+	denylist repository.AccessTokenDenylist
+	logger   *zap.Logger
+	upgrader *websocket.Upgrader
+
+	pingInterval time.Duration
+	writeTimeout time.Duration
+	bufferSize   int
+
+	mu      sync.Mutex
+	clients map[uint]map[*notificationClient]struct{}
+}
+
+// notificationClient is a connection of a user; send buffers the
+// notifications waiting to be written to it
+type notificationClient struct {
+	userID uint
+	conn   *websocket.Conn
+	send   chan []byte
+}
+
+// NewNotificationHub creates a notification hub.
+func NewNotificationHub(p NotificationHubParams) *NotificationHub {
+	return &NotificationHub{
+		tokens:       p.TokenManager,
+		denylist:     p.Denylist,
+		logger:       p.Logger,
+		upgrader:     newUpgrader(),
+		pingInterval: notificationPingInterval,
+		writeTimeout: notificationWriteTimeout,
+		bufferSize:   notificationBufferSize,
+		clients:      make(map[uint]map[*notificationClient]struct{}),
+	}
+}
+
+// NotifyUser queues notification on every connection of userID. Connections
+// whose buffer is full are dropped rather than holding up the caller.
+func (h *NotificationHub) NotifyUser(ctx context.Context, userID uint, notification repository.UserNotification) error {
+	msg, err := json.Marshal(notification)
+	if err != nil {
+		return err
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for c := range h.clients[userID] {
+		select {
+		case c.send <- msg:
+		default:
+			h.logger.Warn("Dropping slow notification connection", zap.Uint("userId", userID))
+			h.removeLocked(c)
+			close(c.send)
+		}
+	}
+	return nil
+}
+
+// ServeHTTP authenticates the caller and holds their connection open until
+// either side closes it.
+func (h *NotificationHub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !sameOrigin(r) {
+		h.logger.Warn("Rejected cross-origin notification connection", zap.String("origin", r.Header.Get("Origin")))
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+	userID, err := h.authenticate(r)
+	if err != nil {
+		h.logger.Warn("Rejected notification connection", zap.Error(err))
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	conn, err := h.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	c := &notificationClient{userID: userID, conn: conn, send: make(chan []byte, h.bufferSize)}
+	h.add(c)
+	defer h.remove(c)
+
+	closed := make(chan struct{})
+	go h.readLoop(c, closed)
+	h.writeLoop(c, closed)
+}
+
+// readLoop handles the control frames of the connection, discarding what
+// clients send, and closes closed once the connection fails or the peer
+// goes away without answering pings
+func (h *NotificationHub) readLoop(c *notificationClient, closed chan<- struct{}) {
+	defer close(closed)
+	pongWait := h.pingInterval + h.writeTimeout
+	c.conn.SetReadLimit(notificationReadLimit)
+	c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+	for {
+		if _, _, err := c.conn.ReadMessage(); err != nil {
+			return
+		}
+	}
+}
+
+// writeLoop writes buffered notifications to the connection and pings it
+// while idle. It's the only writer of the connection but for the pongs and
+// close replies of readLoop, which gorilla/websocket allows concurrently.
+func (h *NotificationHub) writeLoop(c *notificationClient, closed <-chan struct{}) {
+	ticker := time.NewTicker(h.pingInterval)
+	defer ticker.Stop()
+	defer c.conn.Close()
+
+	for {
+		select {
+		case <-closed:
+			return
+		case msg, ok := <-c.send:
+			if !ok {
+				closeConn(c.conn, websocket.ClosePolicyViolation, "too slow")
+				return
+			}
+			c.conn.SetWriteDeadline(time.Now().Add(h.writeTimeout))
+			if err := c.conn.WriteMessage(websocket.TextMessage, msg); err != nil {
+				return
+			}
+		case <-ticker.C:
+			if err := c.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(h.writeTimeout)); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// authenticate validates the access token of a connection request and
+// returns the user it belongs to
+func (h *NotificationHub) authenticate(r *http.Request) (uint, error) {
+	tokenStr := r.URL.Query().Get("access_token")
+	if header := r.Header.Get("Authorization"); header != "" {
+		var ok bool
+		if tokenStr, ok = strings.CutPrefix(header, "Bearer "); !ok {
+			return 0, errors.New("invalid authorization header format")
+		}
+	}
+	if tokenStr == "" {
+		return 0, errors.New("missing access token")
+	}
+
+	claims, err := h.tokens.ValidateAccessToken(tokenStr)
+	if err != nil {
+		return 0, err
+	}
+	revoked, err := repository.IsAccessTokenRevoked(r.Context(), h.denylist, claims)
+	if err != nil {
+		return 0, err
+	}
+	if revoked {
+		return 0, errors.New("access token revoked")
+	}
+	sub, ok := claims["sub"].(float64)
+	if !ok {
+		return 0, errors.New("invalid user ID in token claims")
+	}
+	return uint(sub), nil
+}
+
+func (h *NotificationHub) add(c *notificationClient) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.clients[c.userID] == nil {
+		h.clients[c.userID] = make(map[*notificationClient]struct{})
+	}
+	h.clients[c.userID][c] = struct{}{}
+}
+
+func (h *NotificationHub) remove(c *notificationClient) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.removeLocked(c)
+}
+
+func (h *NotificationHub) removeLocked(c *notificationClient) {
+	conns := h.clients[c.userID]
+	delete(conns, c)
+	if len(conns) == 0 {
+		delete(h.clients, c.userID)
+	}
+}
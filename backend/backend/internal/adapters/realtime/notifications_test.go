@@ -0,0 +1,156 @@
+package adapterrealtime
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+
+	"github.com/pmaojo/kthulu-go/backend/core"
+	"github.com/pmaojo/kthulu-go/backend/internal/domain/repository"
+)
+
+func newTestHub(t *testing.T) (*NotificationHub, *httptest.Server, func(userID uint) string) {
+	t.Helper()
+	tokens, err := core.NewJWT(&core.Config{JWT: core.JWTConfig{Secret: "access-secret", RefreshSecret: "refresh-secret"}})
+	if err != nil {
+		t.Fatalf("new jwt: %v", err)
+	}
+	hub := NewNotificationHub(NotificationHubParams{TokenManager: tokens, Logger: zap.NewNop()})
+	// Ping often enough for connections to see several heartbeats
+	hub.pingInterval = 10 * time.Millisecond
+
+	srv := httptest.NewServer(hub)
+	t.Cleanup(srv.Close)
+
+	sign := func(userID uint) string {
+		token, err := tokens.SignAccessToken(jwt.MapClaims{"sub": userID, "exp": time.Now().Add(time.Minute).Unix()})
+		if err != nil {
+			t.Fatalf("sign: %v", err)
+		}
+		return token
+	}
+	return hub, srv, sign
+}
+
+func dial(t *testing.T, srv *httptest.Server, token string) (*websocket.Conn, *http.Response, error) {
+	t.Helper()
+	return dialWithHeader(t, srv, token, nil)
+}
+
+func dialWithHeader(t *testing.T, srv *httptest.Server, token string, header http.Header) (*websocket.Conn, *http.Response, error) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	url := "ws" + strings.TrimPrefix(srv.URL, "http")
+	if token != "" {
+		url += "?access_token=" + token
+	}
+	conn, resp, err := websocket.DefaultDialer.DialContext(ctx, url, header)
+	if conn != nil {
+		t.Cleanup(func() { conn.Close() })
+	}
+	return conn, resp, err
+}
+
+// waitForConnections blocks until the hub has registered n connections of
+// userID
+func waitForConnections(t *testing.T, hub *NotificationHub, userID uint, n int) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		hub.mu.Lock()
+		got := len(hub.clients[userID])
+		hub.mu.Unlock()
+		if got == n {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("expected %d connections for user %d", n, userID)
+}
+
+func TestNotificationHub_RequiresAccessToken(t *testing.T) {
+	_, srv, sign := newTestHub(t)
+
+	for name, token := range map[string]string{"missing": "", "invalid": "not-a-token"} {
+		_, resp, err := dial(t, srv, token)
+		if err == nil || resp == nil || resp.StatusCode != http.StatusUnauthorized {
+			t.Fatalf("%s token: expected a 401, got %v %v", name, resp, err)
+		}
+	}
+
+	if _, _, err := dial(t, srv, sign(1)); err != nil {
+		t.Fatalf("expected a valid token to connect, got %v", err)
+	}
+}
+
+func TestNotificationHub_RejectsCrossOriginRequests(t *testing.T) {
+	_, srv, sign := newTestHub(t)
+
+	_, resp, err := dialWithHeader(t, srv, sign(1), http.Header{"Origin": {"https://evil.example"}})
+	if err == nil || resp == nil || resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("expected a cross-origin request to get a 403, got %v %v", resp, err)
+	}
+
+	sameOrigin := http.Header{"Origin": {srv.URL}}
+	if _, _, err := dialWithHeader(t, srv, sign(1), sameOrigin); err != nil {
+		t.Fatalf("expected a same-origin request to connect, got %v", err)
+	}
+}
+
+func TestNotificationHub_DeliversToAddressedUser(t *testing.T) {
+	hub, srv, sign := newTestHub(t)
+
+	first, _, err := dial(t, srv, sign(1))
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	second, _, err := dial(t, srv, sign(1))
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	other, _, err := dial(t, srv, sign(2))
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	waitForConnections(t, hub, 1, 2)
+	waitForConnections(t, hub, 2, 1)
+
+	// Let a few heartbeats go by before notifying
+	time.Sleep(50 * time.Millisecond)
+	notification := repository.UserNotification{
+		Type: repository.UserNotificationPaymentReceived,
+		Data: map[string]interface{}{"invoiceId": 7},
+	}
+	if err := hub.NotifyUser(context.Background(), 1, notification); err != nil {
+		t.Fatalf("notify: %v", err)
+	}
+
+	for _, conn := range []*websocket.Conn{first, second} {
+		conn.SetReadDeadline(time.Now().Add(time.Second))
+		_, msg, err := conn.ReadMessage()
+		if err != nil {
+			t.Fatalf("read: %v", err)
+		}
+		var got repository.UserNotification
+		if err := json.Unmarshal(msg, &got); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		if got.Type != repository.UserNotificationPaymentReceived || got.Data["invoiceId"] != float64(7) {
+			t.Fatalf("unexpected notification %s", msg)
+		}
+	}
+
+	other.SetReadDeadline(time.Now().Add(50 * time.Millisecond))
+	if _, msg, err := other.ReadMessage(); err == nil {
+		t.Fatalf("expected user 2 to receive nothing, got %s", msg)
+	}
+}
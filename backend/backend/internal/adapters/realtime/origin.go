@@ -0,0 +1,30 @@
+package adapterrealtime
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gorilla/websocket"
+)
+
+// newUpgrader creates an upgrader accepting only same-origin connections
+func newUpgrader() *websocket.Upgrader {
+	return &websocket.Upgrader{CheckOrigin: sameOrigin}
+}
+
+// sameOrigin reports whether a connection request comes from a page served
+// by this host. Browsers always send an Origin header on WebSocket requests,
+// so requests without one come from other kinds of clients and are let
+// through; those still have to authenticate like any other.
+func sameOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	if err != nil || u.Host == "" {
+		return false
+	}
+	return strings.EqualFold(u.Host, r.Host)
+}
@@ -0,0 +1,88 @@
+// @kthulu:module:audit
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// AuditAction identifies the kind of mutation recorded in the audit log
+type AuditAction string
+
+const (
+	AuditActionCreate AuditAction = "create"
+	AuditActionUpdate AuditAction = "update"
+	AuditActionDelete AuditAction = "delete"
+)
+
+// AuditLogEntry represents a single mutation performed on a persisted entity
+type AuditLogEntry struct {
+	ID             uint            `json:"id"`
+	OrganizationID uint            `json:"organizationId"`
+	ActorID        *uint           `json:"actorId,omitempty"`
+	Action         AuditAction     `json:"action"`
+	EntityType     string          `json:"entityType"`
+	EntityID       uint            `json:"entityId"`
+	Diff           json.RawMessage `json:"diff,omitempty"`
+	CreatedAt      time.Time       `json:"createdAt"`
+}
+
+// AuditFieldChange holds the previous and new value of a changed field
+type AuditFieldChange struct {
+	Old interface{} `json:"old"`
+	New interface{} `json:"new"`
+}
+
+// NewAuditDiff builds a JSON document describing the fields that differ
+// between before and after. Either side may be nil for creates and deletes.
+func NewAuditDiff(before, after interface{}) (json.RawMessage, error) {
+	oldFields, err := toAuditFields(before)
+	if err != nil {
+		return nil, err
+	}
+	newFields, err := toAuditFields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make(map[string]AuditFieldChange)
+	for key, oldValue := range oldFields {
+		newValue, ok := newFields[key]
+		if !ok || !jsonEqual(oldValue, newValue) {
+			changes[key] = AuditFieldChange{Old: oldValue, New: newValue}
+		}
+	}
+	for key, newValue := range newFields {
+		if _, ok := oldFields[key]; !ok {
+			changes[key] = AuditFieldChange{New: newValue}
+		}
+	}
+
+	return json.Marshal(changes)
+}
+
+// toAuditFields flattens a value into its top-level JSON fields
+func toAuditFields(v interface{}) (map[string]interface{}, error) {
+	fields := make(map[string]interface{})
+	if v == nil {
+		return fields, nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	if string(data) == "null" {
+		return fields, nil
+	}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+// jsonEqual compares two decoded JSON values
+func jsonEqual(a, b interface{}) bool {
+	left, errA := json.Marshal(a)
+	right, errB := json.Marshal(b)
+	return errA == nil && errB == nil && string(left) == string(right)
+}
@@ -0,0 +1,28 @@
+// @kthulu:module:auth
+package domain
+
+import "time"
+
+// AuthEventType identifies the outcome recorded by an authentication event
+type AuthEventType string
+
+const (
+	AuthEventLoginSucceeded   AuthEventType = "login_succeeded"
+	AuthEventLoginFailed      AuthEventType = "login_failed"
+	AuthEventConfirmSucceeded AuthEventType = "confirm_succeeded"
+	AuthEventConfirmFailed    AuthEventType = "confirm_failed"
+	AuthEventRefreshSucceeded AuthEventType = "refresh_succeeded"
+	AuthEventRefreshFailed    AuthEventType = "refresh_failed"
+)
+
+// AuthEvent records an authentication attempt. UserID is set once the user is
+// known; failed attempts for unknown accounts only carry the attempted email.
+type AuthEvent struct {
+	ID        uint          `json:"id"`
+	UserID    *uint         `json:"userId,omitempty"`
+	Email     string        `json:"email,omitempty"`
+	Type      AuthEventType `json:"type"`
+	IPAddress string        `json:"ipAddress,omitempty"`
+	UserAgent string        `json:"userAgent,omitempty"`
+	CreatedAt time.Time     `json:"createdAt"`
+}
@@ -2,9 +2,19 @@
 package domain
 
 import (
+	"errors"
 	"time"
 )
 
+var (
+	// ErrCalendarNotFound is returned when a calendar does not exist or
+	// belongs to another user
+	ErrCalendarNotFound = errors.New("calendar not found")
+	// ErrInvalidEventLink is returned when an event is linked to an unknown
+	// kind of record or without its ID
+	ErrInvalidEventLink = errors.New("invalid event link")
+)
+
 // CalendarType represents the type of calendar
 type CalendarType string
 
@@ -52,23 +62,50 @@ const (
 	EventTypeBooking     EventType = "booking"
 )
 
+// EventEntityType names the kind of record an event is linked to
+type EventEntityType string
+
+const (
+	EventEntityContact EventEntityType = "contact"
+	EventEntityInvoice EventEntityType = "invoice"
+)
+
+// ValidateEventLink checks that an event is either unlinked or linked to a
+// known kind of record with its ID
+func ValidateEventLink(entityType EventEntityType, entityID *uint) error {
+	if entityType == "" && entityID == nil {
+		return nil
+	}
+	switch entityType {
+	case EventEntityContact, EventEntityInvoice:
+		if entityID != nil && *entityID != 0 {
+			return nil
+		}
+	}
+	return ErrInvalidEventLink
+}
+
 // Event represents a calendar event
 type Event struct {
-	ID             uint        `json:"id" gorm:"primaryKey"`
-	CalendarID     uint        `json:"calendarId" gorm:"not null;index"`
-	Title          string      `json:"title" gorm:"not null;size:255"`
-	Description    string      `json:"description" gorm:"size:1000"`
-	Location       string      `json:"location" gorm:"size:255"`
-	StartTime      time.Time   `json:"startTime" gorm:"not null;index"`
-	EndTime        time.Time   `json:"endTime" gorm:"not null;index"`
-	AllDay         bool        `json:"allDay" gorm:"default:false"`
-	Status         EventStatus `json:"status" gorm:"not null;size:20;default:'confirmed'"`
-	Type           EventType   `json:"type" gorm:"not null;size:20;default:'appointment'"`
-	IsRecurring    bool        `json:"isRecurring" gorm:"default:false"`
-	RecurrenceRule string      `json:"recurrenceRule" gorm:"size:500"` // RRULE format
-	CreatedByID    uint        `json:"createdById" gorm:"not null;index"`
-	CreatedAt      time.Time   `json:"createdAt"`
-	UpdatedAt      time.Time   `json:"updatedAt"`
+	ID                   uint            `json:"id" gorm:"primaryKey"`
+	CalendarID           uint            `json:"calendarId" gorm:"not null;index"`
+	Title                string          `json:"title" gorm:"not null;size:255"`
+	Description          string          `json:"description" gorm:"size:1000"`
+	Location             string          `json:"location" gorm:"size:255"`
+	StartTime            time.Time       `json:"startTime" gorm:"not null;index"`
+	EndTime              time.Time       `json:"endTime" gorm:"not null;index"`
+	AllDay               bool            `json:"allDay" gorm:"default:false"`
+	Status               EventStatus     `json:"status" gorm:"not null;size:20;default:'confirmed'"`
+	Type                 EventType       `json:"type" gorm:"not null;size:20;default:'appointment'"`
+	IsRecurring          bool            `json:"isRecurring" gorm:"default:false"`
+	RecurrenceRule       string          `json:"recurrenceRule" gorm:"size:500"`        // RRULE format
+	RecurrenceExceptions string          `json:"recurrenceExceptions" gorm:"size:2000"` // Comma-separated EXDATE values
+	EntityType           EventEntityType `json:"entityType,omitempty" gorm:"size:20;index:idx_events_entity"`
+	EntityID             *uint           `json:"entityId,omitempty" gorm:"index:idx_events_entity"`
+	OrganizationID       uint            `json:"organizationId,omitempty" gorm:"index:idx_events_entity"` // Organization the linked record belongs to
+	CreatedByID          uint            `json:"createdById" gorm:"not null;index"`
+	CreatedAt            time.Time       `json:"createdAt"`
+	UpdatedAt            time.Time       `json:"updatedAt"`
 
 	// Relationships
 	Calendar  Calendar   `json:"calendar,omitempty" gorm:"foreignKey:CalendarID"`
@@ -119,6 +156,7 @@ type Reminder struct {
 	EventID       uint         `json:"eventId" gorm:"not null;index"`
 	Type          ReminderType `json:"type" gorm:"not null;size:20"`
 	MinutesBefore int          `json:"minutesBefore" gorm:"not null"`
+	RemindAt      time.Time    `json:"remindAt" gorm:"index"` // Event start minus MinutesBefore
 	IsSent        bool         `json:"isSent" gorm:"default:false"`
 	SentAt        *time.Time   `json:"sentAt"`
 	CreatedAt     time.Time    `json:"createdAt"`
@@ -189,6 +227,11 @@ func (e *Event) IsActive() bool {
 	return e.StartTime.Before(now) && e.EndTime.After(now)
 }
 
+// ReminderTime returns when a reminder set minutesBefore the event is due
+func (e *Event) ReminderTime(minutesBefore int) time.Time {
+	return e.StartTime.Add(-time.Duration(minutesBefore) * time.Minute)
+}
+
 // IsBookable checks if the availability slot is available for booking
 func (as *AvailabilitySlot) IsBookable() bool {
 	return as.IsAvailable && as.StartTime.After(time.Now())
@@ -0,0 +1,201 @@
+// @kthulu:module:calendar
+package domain
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	icalProductID       = "-//Kthulu//Calendar//EN"
+	icalDateTimeLayout  = "20060102T150405Z"
+	icalDateLayout      = "20060102"
+	icalMaxLineOctets   = 75
+	icalEventUIDPattern = "event-%d@kthulu"
+)
+
+// WriteICalendar writes events as an RFC 5545 VCALENDAR named name. Times
+// are written in UTC, all-day events as dates, and recurring events keep
+// their RRULE with their exceptions as EXDATE values. stamp is the DTSTAMP
+// of every VEVENT.
+func WriteICalendar(w io.Writer, name string, events []Event, stamp time.Time) error {
+	out := &contentLineWriter{w: bufio.NewWriter(w)}
+	out.line("BEGIN:VCALENDAR")
+	out.line("VERSION:2.0")
+	out.line("PRODID:" + icalProductID)
+	out.line("CALSCALE:GREGORIAN")
+	out.line("METHOD:PUBLISH")
+	if name != "" {
+		out.line("X-WR-CALNAME:" + contentText(name))
+	}
+
+	for i := range events {
+		if err := out.event(&events[i], stamp); err != nil {
+			return err
+		}
+	}
+
+	out.line("END:VCALENDAR")
+	if out.err != nil {
+		return out.err
+	}
+	return out.w.Flush()
+}
+
+// contentLineWriter writes the folded content lines shared by iCalendar and
+// vCard, keeping the first error
+type contentLineWriter struct {
+	w   *bufio.Writer
+	err error
+}
+
+// line writes a content line terminated by CRLF, folding it so no physical
+// line exceeds 75 octets without splitting a UTF-8 sequence
+func (o *contentLineWriter) line(content string) {
+	if o.err != nil {
+		return
+	}
+	limit := icalMaxLineOctets
+	for len(content) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(content[cut]) {
+			cut--
+		}
+		if _, o.err = o.w.WriteString(content[:cut] + "\r\n "); o.err != nil {
+			return
+		}
+		content = content[cut:]
+		// Continuation lines start with a space that counts towards the limit
+		limit = icalMaxLineOctets - 1
+	}
+	_, o.err = o.w.WriteString(content + "\r\n")
+}
+
+func (o *contentLineWriter) event(e *Event, stamp time.Time) error {
+	o.line("BEGIN:VEVENT")
+	o.line(fmt.Sprintf("UID:"+icalEventUIDPattern, e.ID))
+	o.line("DTSTAMP:" + stamp.UTC().Format(icalDateTimeLayout))
+	if e.AllDay {
+		end := e.EndTime
+		// DTEND is exclusive, an event ending on its start day lasts that day
+		if !dateOf(end).After(dateOf(e.StartTime)) {
+			end = e.StartTime.AddDate(0, 0, 1)
+		}
+		o.line("DTSTART;VALUE=DATE:" + e.StartTime.Format(icalDateLayout))
+		o.line("DTEND;VALUE=DATE:" + end.Format(icalDateLayout))
+	} else {
+		o.line("DTSTART:" + e.StartTime.UTC().Format(icalDateTimeLayout))
+		o.line("DTEND:" + e.EndTime.UTC().Format(icalDateTimeLayout))
+	}
+	o.line("SUMMARY:" + contentText(e.Title))
+	if e.Description != "" {
+		o.line("DESCRIPTION:" + contentText(e.Description))
+	}
+	if e.Location != "" {
+		o.line("LOCATION:" + contentText(e.Location))
+	}
+	if status := icalStatus(e.Status); status != "" {
+		o.line("STATUS:" + status)
+	}
+	if !e.UpdatedAt.IsZero() {
+		o.line("LAST-MODIFIED:" + e.UpdatedAt.UTC().Format(icalDateTimeLayout))
+	}
+
+	if strings.TrimSpace(e.RecurrenceRule) != "" {
+		rule, err := e.icalRecurrenceRule()
+		if err != nil {
+			return err
+		}
+		o.line("RRULE:" + rule)
+		exceptions, err := e.icalExceptions()
+		if err != nil {
+			return err
+		}
+		for _, exception := range exceptions {
+			o.line(exception)
+		}
+	}
+	o.line("END:VEVENT")
+	return nil
+}
+
+// icalRecurrenceRule returns the event rule without its "RRULE:" prefix.
+// UNTIL is rewritten to match DTSTART: a UTC date-time for timed events and
+// a date for all-day ones.
+func (e *Event) icalRecurrenceRule() (string, error) {
+	if _, err := ParseRecurrenceRule(e.RecurrenceRule); err != nil {
+		return "", err
+	}
+	parts := strings.Split(strings.TrimPrefix(strings.TrimSpace(e.RecurrenceRule), "RRULE:"), ";")
+	for i, part := range parts {
+		key, value, _ := strings.Cut(part, "=")
+		if !strings.EqualFold(key, "UNTIL") {
+			parts[i] = strings.ToUpper(key) + "=" + strings.ToUpper(value)
+			continue
+		}
+		until, dateOnly, err := parseRecurrenceTime(value, e.StartTime.Location())
+		if err != nil {
+			return "", err
+		}
+		switch {
+		case e.AllDay:
+			parts[i] = "UNTIL=" + until.Format(icalDateLayout)
+		case dateOnly:
+			parts[i] = "UNTIL=" + until.AddDate(0, 0, 1).Add(-time.Second).UTC().Format(icalDateTimeLayout)
+		default:
+			parts[i] = "UNTIL=" + until.UTC().Format(icalDateTimeLayout)
+		}
+	}
+	return strings.Join(parts, ";"), nil
+}
+
+// icalExceptions returns the EXDATE lines of the event. Excluded days of
+// timed events become the instance starting that day.
+func (e *Event) icalExceptions() ([]string, error) {
+	loc := e.StartTime.Location()
+	var lines []string
+	for _, value := range strings.Split(e.RecurrenceExceptions, ",") {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		t, dateOnly, err := parseRecurrenceTime(value, loc)
+		if err != nil {
+			return nil, err
+		}
+		if e.AllDay {
+			lines = append(lines, "EXDATE;VALUE=DATE:"+t.Format(icalDateLayout))
+			continue
+		}
+		if dateOnly {
+			t = time.Date(t.Year(), t.Month(), t.Day(), e.StartTime.Hour(), e.StartTime.Minute(), e.StartTime.Second(), 0, loc)
+		}
+		lines = append(lines, "EXDATE:"+t.UTC().Format(icalDateTimeLayout))
+	}
+	return lines, nil
+}
+
+// contentText escapes a TEXT property value
+func contentText(value string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`, "\r", `\n`).Replace(value)
+}
+
+func icalStatus(status EventStatus) string {
+	switch status {
+	case EventStatusTentative:
+		return "TENTATIVE"
+	case EventStatusConfirmed:
+		return "CONFIRMED"
+	case EventStatusCancelled:
+		return "CANCELLED"
+	}
+	return ""
+}
+
+func dateOf(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,142 @@
+package domain
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+// icalLines unfolds an iCalendar document into its content lines
+func icalLines(t *testing.T, ics string) []string {
+	t.Helper()
+	if !strings.HasSuffix(ics, "\r\n") {
+		t.Fatalf("expected the document to end with CRLF")
+	}
+	var lines []string
+	for _, physical := range strings.Split(strings.TrimSuffix(ics, "\r\n"), "\r\n") {
+		if len(physical) > icalMaxLineOctets {
+			t.Fatalf("line longer than %d octets: %q", icalMaxLineOctets, physical)
+		}
+		if strings.Contains(physical, "\n") {
+			t.Fatalf("bare LF in %q", physical)
+		}
+		if strings.HasPrefix(physical, " ") {
+			lines[len(lines)-1] += physical[1:]
+			continue
+		}
+		lines = append(lines, physical)
+	}
+	return lines
+}
+
+func TestWriteICalendar(t *testing.T) {
+	madrid := time.FixedZone("CEST", 2*60*60)
+	stamp := time.Date(2024, 5, 20, 8, 0, 0, 0, time.UTC)
+	events := []Event{
+		{
+			ID:                   1,
+			Title:                "Standup; daily, quick",
+			Description:          "Agenda:\nblockers",
+			Location:             "Room 1",
+			StartTime:            time.Date(2024, 6, 3, 9, 0, 0, 0, madrid),
+			EndTime:              time.Date(2024, 6, 3, 9, 15, 0, 0, madrid),
+			Status:               EventStatusConfirmed,
+			RecurrenceRule:       "RRULE:FREQ=WEEKLY;BYDAY=mo,we;UNTIL=20240630",
+			RecurrenceExceptions: "20240610,20240612T070000Z",
+		},
+		{
+			ID:        2,
+			Title:     strings.Repeat("Quarterly planning and budget review ", 4) + "€",
+			StartTime: time.Date(2024, 6, 14, 0, 0, 0, 0, time.UTC),
+			EndTime:   time.Date(2024, 6, 14, 0, 0, 0, 0, time.UTC),
+			AllDay:    true,
+			Status:    EventStatusCancelled,
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteICalendar(&buf, "Team, Sales", events, stamp); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	lines := icalLines(t, buf.String())
+
+	if lines[0] != "BEGIN:VCALENDAR" || lines[len(lines)-1] != "END:VCALENDAR" {
+		t.Fatalf("expected a VCALENDAR, got %v", lines)
+	}
+	var vevents [][]string
+	depth := 0
+	for _, line := range lines {
+		switch line {
+		case "BEGIN:VEVENT":
+			depth++
+			vevents = append(vevents, nil)
+		case "END:VEVENT":
+			depth--
+		default:
+			if depth == 1 {
+				vevents[len(vevents)-1] = append(vevents[len(vevents)-1], line)
+			} else if depth != 0 {
+				t.Fatalf("unbalanced VEVENT at %q", line)
+			}
+		}
+	}
+	for _, want := range []string{"VERSION:2.0", "PRODID:" + icalProductID, `X-WR-CALNAME:Team\, Sales`} {
+		if !containsLine(lines, want) {
+			t.Fatalf("expected %q in calendar:\n%s", want, buf.String())
+		}
+	}
+	if len(vevents) != 2 {
+		t.Fatalf("expected 2 VEVENTs, got %d", len(vevents))
+	}
+
+	for _, want := range []string{
+		"UID:event-1@kthulu",
+		"DTSTAMP:20240520T080000Z",
+		"DTSTART:20240603T070000Z",
+		"DTEND:20240603T071500Z",
+		`SUMMARY:Standup\; daily\, quick`,
+		`DESCRIPTION:Agenda:\nblockers`,
+		"LOCATION:Room 1",
+		"STATUS:CONFIRMED",
+		"RRULE:FREQ=WEEKLY;BYDAY=MO,WE;UNTIL=20240630T215959Z",
+		"EXDATE:20240610T070000Z",
+		"EXDATE:20240612T070000Z",
+	} {
+		if !containsLine(vevents[0], want) {
+			t.Fatalf("expected %q in first event %v", want, vevents[0])
+		}
+	}
+	for _, want := range []string{
+		"UID:event-2@kthulu",
+		"DTSTART;VALUE=DATE:20240614",
+		"DTEND;VALUE=DATE:20240615",
+		"SUMMARY:" + events[1].Title,
+		"STATUS:CANCELLED",
+	} {
+		if !containsLine(vevents[1], want) {
+			t.Fatalf("expected %q in second event %v", want, vevents[1])
+		}
+	}
+	for _, line := range vevents[1] {
+		if strings.HasPrefix(line, "RRULE") {
+			t.Fatalf("unexpected recurrence on a single event: %q", line)
+		}
+	}
+}
+
+func TestWriteICalendarRejectsInvalidRule(t *testing.T) {
+	events := []Event{{ID: 1, StartTime: time.Now(), EndTime: time.Now(), RecurrenceRule: "FREQ=SECONDLY"}}
+	if err := WriteICalendar(&bytes.Buffer{}, "", events, time.Now()); err == nil {
+		t.Fatalf("expected an error for an invalid recurrence rule")
+	}
+}
+
+func containsLine(lines []string, want string) bool {
+	for _, line := range lines {
+		if line == want {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,329 @@
+// @kthulu:module:calendar
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrInvalidRecurrenceRule is returned for rules that cannot be parsed
+	ErrInvalidRecurrenceRule = errors.New("invalid recurrence rule")
+	// ErrInvalidOccurrenceWindow is returned when an expansion window is empty
+	// or longer than MaxOccurrenceWindow
+	ErrInvalidOccurrenceWindow = errors.New("invalid occurrence window")
+)
+
+// MaxOccurrenceWindow bounds how far a single expansion may look ahead
+const MaxOccurrenceWindow = 366 * 24 * time.Hour
+
+// RecurrenceFrequency is the FREQ part of a recurrence rule
+type RecurrenceFrequency string
+
+const (
+	RecurrenceDaily   RecurrenceFrequency = "DAILY"
+	RecurrenceWeekly  RecurrenceFrequency = "WEEKLY"
+	RecurrenceMonthly RecurrenceFrequency = "MONTHLY"
+)
+
+// RecurrenceRule is the supported subset of an RFC 5545 RRULE: FREQ (DAILY,
+// WEEKLY or MONTHLY), INTERVAL, COUNT, UNTIL, BYDAY without ordinals and
+// BYMONTHDAY. Weeks start on Monday.
+type RecurrenceRule struct {
+	Frequency  RecurrenceFrequency
+	Interval   int
+	Count      int
+	Until      string
+	ByDay      []time.Weekday
+	ByMonthDay []int
+}
+
+var recurrenceWeekdays = map[string]time.Weekday{
+	"MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday, "TH": time.Thursday,
+	"FR": time.Friday, "SA": time.Saturday, "SU": time.Sunday,
+}
+
+// ParseRecurrenceRule parses a rule such as "FREQ=WEEKLY;BYDAY=MO,WE;COUNT=10".
+// A leading "RRULE:" is accepted.
+func ParseRecurrenceRule(rule string) (*RecurrenceRule, error) {
+	rule = strings.TrimPrefix(strings.TrimSpace(rule), "RRULE:")
+	if rule == "" {
+		return nil, fmt.Errorf("%w: empty rule", ErrInvalidRecurrenceRule)
+	}
+
+	parsed := &RecurrenceRule{Interval: 1}
+	for _, part := range strings.Split(rule, ";") {
+		key, value, ok := strings.Cut(part, "=")
+		if !ok || value == "" {
+			return nil, fmt.Errorf("%w: malformed part %q", ErrInvalidRecurrenceRule, part)
+		}
+		switch strings.ToUpper(key) {
+		case "FREQ":
+			parsed.Frequency = RecurrenceFrequency(strings.ToUpper(value))
+			switch parsed.Frequency {
+			case RecurrenceDaily, RecurrenceWeekly, RecurrenceMonthly:
+			default:
+				return nil, fmt.Errorf("%w: unsupported frequency %q", ErrInvalidRecurrenceRule, value)
+			}
+		case "INTERVAL":
+			interval, err := strconv.Atoi(value)
+			if err != nil || interval < 1 {
+				return nil, fmt.Errorf("%w: interval must be a positive number", ErrInvalidRecurrenceRule)
+			}
+			parsed.Interval = interval
+		case "COUNT":
+			count, err := strconv.Atoi(value)
+			if err != nil || count < 1 {
+				return nil, fmt.Errorf("%w: count must be a positive number", ErrInvalidRecurrenceRule)
+			}
+			parsed.Count = count
+		case "UNTIL":
+			if _, _, err := parseRecurrenceTime(value, time.UTC); err != nil {
+				return nil, err
+			}
+			parsed.Until = value
+		case "BYDAY":
+			for _, day := range strings.Split(value, ",") {
+				weekday, ok := recurrenceWeekdays[strings.ToUpper(day)]
+				if !ok {
+					return nil, fmt.Errorf("%w: unsupported day %q", ErrInvalidRecurrenceRule, day)
+				}
+				parsed.ByDay = append(parsed.ByDay, weekday)
+			}
+		case "BYMONTHDAY":
+			for _, day := range strings.Split(value, ",") {
+				monthDay, err := strconv.Atoi(day)
+				if err != nil || monthDay == 0 || monthDay < -31 || monthDay > 31 {
+					return nil, fmt.Errorf("%w: invalid month day %q", ErrInvalidRecurrenceRule, day)
+				}
+				parsed.ByMonthDay = append(parsed.ByMonthDay, monthDay)
+			}
+		default:
+			return nil, fmt.Errorf("%w: unsupported part %q", ErrInvalidRecurrenceRule, key)
+		}
+	}
+
+	if parsed.Frequency == "" {
+		return nil, fmt.Errorf("%w: FREQ is required", ErrInvalidRecurrenceRule)
+	}
+	if len(parsed.ByMonthDay) > 0 && parsed.Frequency != RecurrenceMonthly {
+		return nil, fmt.Errorf("%w: BYMONTHDAY requires FREQ=MONTHLY", ErrInvalidRecurrenceRule)
+	}
+	if parsed.Count > 0 && parsed.Until != "" {
+		return nil, fmt.Errorf("%w: COUNT and UNTIL are mutually exclusive", ErrInvalidRecurrenceRule)
+	}
+	return parsed, nil
+}
+
+// parseRecurrenceTime parses an UNTIL or EXDATE value: a date (20240115), a
+// UTC date-time (20240115T090000Z) or a floating date-time read in loc
+func parseRecurrenceTime(value string, loc *time.Location) (t time.Time, dateOnly bool, err error) {
+	switch {
+	case len(value) == len("20060102"):
+		t, err = time.ParseInLocation("20060102", value, loc)
+		dateOnly = true
+	case strings.HasSuffix(value, "Z"):
+		t, err = time.Parse("20060102T150405Z", value)
+	default:
+		t, err = time.ParseInLocation("20060102T150405", value, loc)
+	}
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("%w: invalid date %q", ErrInvalidRecurrenceRule, value)
+	}
+	return t, dateOnly, nil
+}
+
+// ValidateRecurrenceExceptions checks a comma-separated EXDATE list
+func ValidateRecurrenceExceptions(exceptions string) error {
+	_, err := newRecurrenceExceptions(exceptions, time.UTC)
+	return err
+}
+
+// recurrenceExceptions are the excluded instants and whole days of an event,
+// days being keyed by their date in the event's location
+type recurrenceExceptions struct {
+	instants map[int64]bool
+	days     map[string]bool
+}
+
+func newRecurrenceExceptions(exceptions string, loc *time.Location) (*recurrenceExceptions, error) {
+	excluded := &recurrenceExceptions{instants: make(map[int64]bool), days: make(map[string]bool)}
+	for _, value := range strings.Split(exceptions, ",") {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		t, dateOnly, err := parseRecurrenceTime(value, loc)
+		if err != nil {
+			return nil, err
+		}
+		if dateOnly {
+			excluded.days[t.Format("20060102")] = true
+		} else {
+			excluded.instants[t.UnixNano()] = true
+		}
+	}
+	return excluded, nil
+}
+
+func (e *recurrenceExceptions) excludes(start time.Time) bool {
+	return e.instants[start.UnixNano()] || e.days[start.Format("20060102")]
+}
+
+// EventOccurrence is a single materialized instance of an event
+type EventOccurrence struct {
+	EventID   uint      `json:"eventId"`
+	StartTime time.Time `json:"startTime"`
+	EndTime   time.Time `json:"endTime"`
+}
+
+// Occurrences returns the instances of the event overlapping [from, to),
+// in chronological order. Events without a recurrence rule have a single
+// instance. Recurring instances keep the wall-clock start time of the first
+// one in its location, and those listed in RecurrenceExceptions are skipped;
+// exceptions still count towards COUNT.
+func (e *Event) Occurrences(from, to time.Time) ([]EventOccurrence, error) {
+	if !to.After(from) || to.Sub(from) > MaxOccurrenceWindow {
+		return nil, ErrInvalidOccurrenceWindow
+	}
+
+	duration := e.Duration()
+	if strings.TrimSpace(e.RecurrenceRule) == "" {
+		if e.IsOverlapping(from, to) {
+			return []EventOccurrence{{EventID: e.ID, StartTime: e.StartTime, EndTime: e.EndTime}}, nil
+		}
+		return []EventOccurrence{}, nil
+	}
+
+	rule, err := ParseRecurrenceRule(e.RecurrenceRule)
+	if err != nil {
+		return nil, err
+	}
+	loc := e.StartTime.Location()
+	excluded, err := newRecurrenceExceptions(e.RecurrenceExceptions, loc)
+	if err != nil {
+		return nil, err
+	}
+	var until time.Time
+	if rule.Until != "" {
+		var dateOnly bool
+		if until, dateOnly, err = parseRecurrenceTime(rule.Until, loc); err != nil {
+			return nil, err
+		}
+		if dateOnly {
+			// A date keeps every instance starting on that day
+			until = until.AddDate(0, 0, 1).Add(-time.Nanosecond)
+		}
+	}
+
+	occurrences := []EventOccurrence{}
+	generated := 0
+	for period := 0; ; period++ {
+		candidates := rule.periodStarts(e.StartTime, period)
+		if len(candidates) == 0 && rule.periodBegin(e.StartTime, period).After(to) {
+			return occurrences, nil
+		}
+		for _, start := range candidates {
+			if start.Before(e.StartTime) {
+				continue
+			}
+			if !start.Before(to) || (rule.Count > 0 && generated >= rule.Count) || (!until.IsZero() && start.After(until)) {
+				return occurrences, nil
+			}
+			generated++
+			end := start.Add(duration)
+			if end.After(from) && !excluded.excludes(start) {
+				occurrences = append(occurrences, EventOccurrence{EventID: e.ID, StartTime: start, EndTime: end})
+			}
+		}
+	}
+}
+
+// periodBegin returns midnight at the start of the nth day, week or month of
+// the rule counted from dtstart
+func (r *RecurrenceRule) periodBegin(dtstart time.Time, period int) time.Time {
+	day := time.Date(dtstart.Year(), dtstart.Month(), dtstart.Day(), 0, 0, 0, 0, dtstart.Location())
+	switch r.Frequency {
+	case RecurrenceWeekly:
+		monday := day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+		return monday.AddDate(0, 0, 7*r.Interval*period)
+	case RecurrenceMonthly:
+		return time.Date(day.Year(), day.Month()+time.Month(r.Interval*period), 1, 0, 0, 0, 0, day.Location())
+	default:
+		return day.AddDate(0, 0, r.Interval*period)
+	}
+}
+
+// periodStarts returns the instance start times falling in the nth period,
+// some of which may precede dtstart
+func (r *RecurrenceRule) periodStarts(dtstart time.Time, period int) []time.Time {
+	begin := r.periodBegin(dtstart, period)
+	var days []time.Time
+	switch r.Frequency {
+	case RecurrenceWeekly:
+		weekdays := r.ByDay
+		if len(weekdays) == 0 {
+			weekdays = []time.Weekday{dtstart.Weekday()}
+		}
+		for _, weekday := range weekdays {
+			days = append(days, begin.AddDate(0, 0, (int(weekday)+6)%7))
+		}
+	case RecurrenceMonthly:
+		lastDay := begin.AddDate(0, 1, -1).Day()
+		switch {
+		case len(r.ByMonthDay) > 0:
+			for _, monthDay := range r.ByMonthDay {
+				if monthDay < 0 {
+					monthDay = lastDay + monthDay + 1
+				}
+				// Months too short for the day are skipped, as in RFC 5545
+				if monthDay >= 1 && monthDay <= lastDay && r.matchesDay(begin.AddDate(0, 0, monthDay-1)) {
+					days = append(days, begin.AddDate(0, 0, monthDay-1))
+				}
+			}
+		case len(r.ByDay) > 0:
+			for monthDay := 1; monthDay <= lastDay; monthDay++ {
+				if day := begin.AddDate(0, 0, monthDay-1); r.matchesDay(day) {
+					days = append(days, day)
+				}
+			}
+		case dtstart.Day() <= lastDay:
+			days = append(days, begin.AddDate(0, 0, dtstart.Day()-1))
+		}
+	default:
+		if r.matchesDay(begin) {
+			days = append(days, begin)
+		}
+	}
+
+	starts := make([]time.Time, 0, len(days))
+	for _, day := range days {
+		starts = append(starts, time.Date(day.Year(), day.Month(), day.Day(),
+			dtstart.Hour(), dtstart.Minute(), dtstart.Second(), dtstart.Nanosecond(), dtstart.Location()))
+	}
+	sort.Slice(starts, func(i, j int) bool { return starts[i].Before(starts[j]) })
+	unique := starts[:0]
+	for i, start := range starts {
+		if i == 0 || !start.Equal(starts[i-1]) {
+			unique = append(unique, start)
+		}
+	}
+	return unique
+}
+
+// matchesDay reports whether day passes the BYDAY filter
+func (r *RecurrenceRule) matchesDay(day time.Time) bool {
+	if len(r.ByDay) == 0 {
+		return true
+	}
+	for _, weekday := range r.ByDay {
+		if day.Weekday() == weekday {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,157 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func occurrenceStarts(t *testing.T, event *Event, from, to time.Time) []string {
+	t.Helper()
+	occurrences, err := event.Occurrences(from, to)
+	if err != nil {
+		t.Fatalf("expand: %v", err)
+	}
+	starts := make([]string, 0, len(occurrences))
+	for _, occurrence := range occurrences {
+		if occurrence.EventID != event.ID || occurrence.EndTime.Sub(occurrence.StartTime) != event.Duration() {
+			t.Fatalf("unexpected occurrence %+v", occurrence)
+		}
+		starts = append(starts, occurrence.StartTime.Format("2006-01-02 15:04"))
+	}
+	return starts
+}
+
+func assertStarts(t *testing.T, got []string, want ...string) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected %v, got %v", want, got)
+		}
+	}
+}
+
+func TestEventOccurrencesWeeklyWithException(t *testing.T) {
+	// Mondays and Wednesdays at 09:00 from Monday 1 April 2024, except 10 April
+	event := &Event{
+		ID:                   7,
+		StartTime:            time.Date(2024, 4, 1, 9, 0, 0, 0, time.UTC),
+		EndTime:              time.Date(2024, 4, 1, 10, 0, 0, 0, time.UTC),
+		IsRecurring:          true,
+		RecurrenceRule:       "RRULE:FREQ=WEEKLY;BYDAY=MO,WE",
+		RecurrenceExceptions: "20240410",
+	}
+
+	starts := occurrenceStarts(t, event, time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC))
+	assertStarts(t, starts,
+		"2024-04-01 09:00", "2024-04-03 09:00",
+		"2024-04-08 09:00",
+		"2024-04-15 09:00", "2024-04-17 09:00",
+		"2024-04-22 09:00", "2024-04-24 09:00",
+		"2024-04-29 09:00",
+	)
+
+	// A window starting mid-series only returns later occurrences, including
+	// one already in progress
+	starts = occurrenceStarts(t, event, time.Date(2024, 4, 22, 9, 30, 0, 0, time.UTC), time.Date(2024, 4, 25, 0, 0, 0, 0, time.UTC))
+	assertStarts(t, starts, "2024-04-22 09:00", "2024-04-24 09:00")
+}
+
+func TestEventOccurrencesDaily(t *testing.T) {
+	event := &Event{
+		StartTime:            time.Date(2024, 1, 30, 8, 0, 0, 0, time.UTC),
+		EndTime:              time.Date(2024, 1, 30, 8, 15, 0, 0, time.UTC),
+		RecurrenceRule:       "FREQ=DAILY;INTERVAL=2;COUNT=4",
+		RecurrenceExceptions: "20240203T080000Z",
+	}
+	// The excluded instance still counts towards COUNT
+	starts := occurrenceStarts(t, event, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC))
+	assertStarts(t, starts, "2024-01-30 08:00", "2024-02-01 08:00", "2024-02-05 08:00")
+
+	event.RecurrenceRule = "FREQ=DAILY;BYDAY=SA,SU;UNTIL=20240204"
+	event.RecurrenceExceptions = ""
+	starts = occurrenceStarts(t, event, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC))
+	assertStarts(t, starts, "2024-02-03 08:00", "2024-02-04 08:00")
+}
+
+func TestEventOccurrencesMonthly(t *testing.T) {
+	event := &Event{
+		StartTime:      time.Date(2024, 1, 31, 18, 0, 0, 0, time.UTC),
+		EndTime:        time.Date(2024, 1, 31, 19, 0, 0, 0, time.UTC),
+		RecurrenceRule: "FREQ=MONTHLY",
+	}
+	// Months without a 31st are skipped
+	starts := occurrenceStarts(t, event, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC))
+	assertStarts(t, starts, "2024-01-31 18:00", "2024-03-31 18:00", "2024-05-31 18:00")
+
+	event.RecurrenceRule = "FREQ=MONTHLY;BYMONTHDAY=-1"
+	starts = occurrenceStarts(t, event, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC))
+	assertStarts(t, starts, "2024-01-31 18:00", "2024-02-29 18:00", "2024-03-31 18:00", "2024-04-30 18:00")
+}
+
+func TestEventOccurrencesKeepWallClockTime(t *testing.T) {
+	madrid, err := time.LoadLocation("Europe/Madrid")
+	if err != nil {
+		t.Skipf("time zone data unavailable: %v", err)
+	}
+	// Spans the switch to summer time on 31 March
+	event := &Event{
+		StartTime:      time.Date(2024, 3, 30, 9, 0, 0, 0, madrid),
+		EndTime:        time.Date(2024, 3, 30, 10, 0, 0, 0, madrid),
+		RecurrenceRule: "FREQ=DAILY;COUNT=3",
+	}
+	occurrences, err := event.Occurrences(event.StartTime, event.StartTime.AddDate(0, 0, 2).Add(time.Hour))
+	if err != nil {
+		t.Fatalf("expand: %v", err)
+	}
+	if len(occurrences) != 3 {
+		t.Fatalf("expected 3 occurrences, got %+v", occurrences)
+	}
+	for _, occurrence := range occurrences {
+		if occurrence.StartTime.Hour() != 9 {
+			t.Fatalf("expected occurrences at 09:00 local time, got %v", occurrence.StartTime)
+		}
+	}
+}
+
+func TestEventOccurrencesSingleEvent(t *testing.T) {
+	event := &Event{
+		StartTime: time.Date(2024, 4, 2, 9, 0, 0, 0, time.UTC),
+		EndTime:   time.Date(2024, 4, 2, 10, 0, 0, 0, time.UTC),
+	}
+	starts := occurrenceStarts(t, event, time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 4, 8, 0, 0, 0, 0, time.UTC))
+	assertStarts(t, starts, "2024-04-02 09:00")
+	starts = occurrenceStarts(t, event, time.Date(2024, 4, 3, 0, 0, 0, 0, time.UTC), time.Date(2024, 4, 8, 0, 0, 0, 0, time.UTC))
+	assertStarts(t, starts)
+}
+
+func TestEventOccurrencesRejectsInvalidInput(t *testing.T) {
+	start := time.Date(2024, 4, 1, 9, 0, 0, 0, time.UTC)
+	event := &Event{StartTime: start, EndTime: start.Add(time.Hour), RecurrenceRule: "FREQ=WEEKLY"}
+
+	for _, window := range [][2]time.Time{{start, start}, {start, start.Add(-time.Hour)}, {start, start.AddDate(2, 0, 0)}} {
+		if _, err := event.Occurrences(window[0], window[1]); !errors.Is(err, ErrInvalidOccurrenceWindow) {
+			t.Fatalf("window %v: expected ErrInvalidOccurrenceWindow, got %v", window, err)
+		}
+	}
+
+	for _, rule := range []string{
+		"BYDAY=MO",
+		"FREQ=YEARLY",
+		"FREQ=WEEKLY;BYDAY=1MO",
+		"FREQ=WEEKLY;BYMONTHDAY=3",
+		"FREQ=DAILY;INTERVAL=0",
+		"FREQ=DAILY;COUNT=2;UNTIL=20240501",
+		"FREQ=DAILY;UNTIL=tomorrow",
+	} {
+		if _, err := ParseRecurrenceRule(rule); !errors.Is(err, ErrInvalidRecurrenceRule) {
+			t.Fatalf("%q: expected ErrInvalidRecurrenceRule, got %v", rule, err)
+		}
+	}
+	if err := ValidateRecurrenceExceptions("20240401, 2024-04-02"); !errors.Is(err, ErrInvalidRecurrenceRule) {
+		t.Fatalf("expected ErrInvalidRecurrenceRule for malformed exception, got %v", err)
+	}
+}
@@ -18,6 +18,7 @@ var (
 	ErrContactInvalidPhone  = errors.New("invalid contact phone number")
 	ErrAddressNotFound      = errors.New("address not found")
 	ErrPhoneNotFound        = errors.New("phone not found")
+	ErrInvalidStatsPeriod   = errors.New("invalid statistics period")
 )
 
 // ContactType represents the type of contact
@@ -93,14 +94,15 @@ type ContactAddress struct {
 
 // ContactPhone represents a phone number for a contact
 type ContactPhone struct {
-	ID        uint      `json:"id"`
-	ContactID uint      `json:"contactId" validate:"required"`
-	Type      PhoneType `json:"type" validate:"required,oneof=work mobile home fax other"`
-	Number    string    `json:"number" validate:"required,max=20"`
-	Extension string    `json:"extension,omitempty" validate:"max=10"`
-	IsPrimary bool      `json:"isPrimary"`
-	CreatedAt time.Time `json:"createdAt"`
-	UpdatedAt time.Time `json:"updatedAt"`
+	ID               uint      `json:"id"`
+	ContactID        uint      `json:"contactId" validate:"required"`
+	Type             PhoneType `json:"type" validate:"required,oneof=work mobile home fax other"`
+	Number           string    `json:"number" validate:"required,max=20"` // As entered
+	NormalizedNumber string    `json:"normalizedNumber,omitempty"`        // E.164, empty when Number could not be parsed
+	Extension        string    `json:"extension,omitempty" validate:"max=10"`
+	IsPrimary        bool      `json:"isPrimary"`
+	CreatedAt        time.Time `json:"createdAt"`
+	UpdatedAt        time.Time `json:"updatedAt"`
 }
 
 // NewContact creates a new contact with validation
@@ -270,6 +272,13 @@ func (cp *ContactPhone) Update(number, extension string) error {
 	return validate.Struct(cp)
 }
 
+// Normalize stores the E.164 form of Number, reading numbers without a
+// country code as numbers of defaultRegion. Numbers that cannot be parsed
+// keep an empty normalized form and are only stored as entered.
+func (cp *ContactPhone) Normalize(defaultRegion string) {
+	cp.NormalizedNumber, _ = NormalizePhoneNumber(cp.Number, defaultRegion)
+}
+
 // SetPrimary sets this phone as primary
 func (cp *ContactPhone) SetPrimary(isPrimary bool) {
 	cp.IsPrimary = isPrimary
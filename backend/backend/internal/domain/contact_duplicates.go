@@ -0,0 +1,269 @@
+// @kthulu:module:contacts
+package domain
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// DuplicateReason tells why contacts were grouped as potential duplicates
+type DuplicateReason string
+
+const (
+	DuplicateReasonEmail DuplicateReason = "email"
+	DuplicateReasonPhone DuplicateReason = "phone"
+	DuplicateReasonName  DuplicateReason = "name"
+)
+
+// DuplicateGroup is a set of two or more contacts that likely describe the
+// same person or company
+type DuplicateGroup struct {
+	Reasons  []DuplicateReason `json:"reasons"`
+	Contacts []*Contact        `json:"contacts"`
+}
+
+const (
+	// minDuplicatePhoneDigits ignores numbers too short to identify anyone
+	minDuplicatePhoneDigits = 7
+	// duplicatePhoneDigits compares the trailing digits only, so numbers with
+	// and without a country or trunk prefix match
+	duplicatePhoneDigits = 9
+	// minDuplicateNameSimilarity is the share of characters two normalized
+	// names must have in common to be considered the same
+	minDuplicateNameSimilarity = 0.85
+)
+
+// companySuffixes are legal forms dropped before comparing names
+var companySuffixes = map[string]bool{
+	"inc": true, "incorporated": true, "llc": true, "ltd": true, "limited": true,
+	"corp": true, "corporation": true, "co": true, "company": true, "plc": true,
+	"gmbh": true, "ag": true, "sa": true, "sl": true, "slu": true, "sas": true,
+	"sarl": true, "srl": true, "spa": true, "bv": true, "nv": true,
+}
+
+// NormalizeContactEmail lowercases an email and drops any +tag from its
+// local part
+func NormalizeContactEmail(email string) string {
+	email = strings.ToLower(strings.TrimSpace(email))
+	local, host, ok := strings.Cut(email, "@")
+	if !ok || local == "" || host == "" {
+		return ""
+	}
+	if base, _, tagged := strings.Cut(local, "+"); tagged && base != "" {
+		local = base
+	}
+	return local + "@" + host
+}
+
+// NormalizeContactPhone keeps the trailing digits of a phone number, or
+// returns an empty string when it has too few digits to compare
+func NormalizeContactPhone(number string) string {
+	digits := make([]byte, 0, len(number))
+	for i := 0; i < len(number); i++ {
+		if number[i] >= '0' && number[i] <= '9' {
+			digits = append(digits, number[i])
+		}
+	}
+	if len(digits) < minDuplicatePhoneDigits {
+		return ""
+	}
+	if len(digits) > duplicatePhoneDigits {
+		digits = digits[len(digits)-duplicatePhoneDigits:]
+	}
+	return string(digits)
+}
+
+// NormalizeContactName folds case and accents, drops punctuation and company
+// legal forms, and sorts the remaining words so "Doe, John" and "john doe"
+// are equal
+func NormalizeContactName(name string) string {
+	folded, _, err := transform.String(transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC), name)
+	if err != nil {
+		folded = name
+	}
+
+	// Dots are dropped rather than split on, so "S.L." reads as "sl"
+	folded = strings.ReplaceAll(folded, ".", "")
+	words := strings.FieldsFunc(strings.ToLower(folded), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	kept := words[:0]
+	for _, word := range words {
+		if !companySuffixes[word] {
+			kept = append(kept, word)
+		}
+	}
+	sort.Strings(kept)
+	return strings.Join(kept, " ")
+}
+
+// FindDuplicateGroups groups contacts sharing a normalized email or phone
+// number, or whose normalized names are nearly identical. Matches are
+// transitive: if A matches B and B matches C, all three share a group.
+// Names are only compared within the same initial to keep large contact
+// lists tractable.
+func FindDuplicateGroups(contacts []*Contact) []DuplicateGroup {
+	groups := newDuplicateUnion(len(contacts))
+
+	byKey := func(reason DuplicateReason, keysOf func(*Contact) []string) {
+		first := make(map[string]int)
+		for i, contact := range contacts {
+			for _, key := range keysOf(contact) {
+				if key == "" {
+					continue
+				}
+				if j, ok := first[key]; ok {
+					groups.union(i, j, reason)
+				} else {
+					first[key] = i
+				}
+			}
+		}
+	}
+	byKey(DuplicateReasonEmail, func(c *Contact) []string {
+		return []string{NormalizeContactEmail(c.Email)}
+	})
+	byKey(DuplicateReasonPhone, func(c *Contact) []string {
+		keys := []string{NormalizeContactPhone(c.Phone), NormalizeContactPhone(c.Mobile)}
+		for _, phone := range c.Phones {
+			keys = append(keys, NormalizeContactPhone(phone.Number))
+		}
+		return keys
+	})
+
+	names := make([][]rune, len(contacts))
+	byInitial := make(map[rune][]int)
+	for i, contact := range contacts {
+		names[i] = []rune(NormalizeContactName(contact.GetDisplayName()))
+		if len(names[i]) > 0 {
+			byInitial[names[i][0]] = append(byInitial[names[i][0]], i)
+		}
+	}
+	for _, indexes := range byInitial {
+		for a := 0; a < len(indexes); a++ {
+			for b := a + 1; b < len(indexes); b++ {
+				if similarNames(names[indexes[a]], names[indexes[b]]) {
+					groups.union(indexes[a], indexes[b], DuplicateReasonName)
+				}
+			}
+		}
+	}
+
+	return groups.collect(contacts)
+}
+
+// similarNames reports whether two normalized names are within the allowed
+// edit distance of each other
+func similarNames(a, b []rune) bool {
+	longest := len(a)
+	if len(b) > longest {
+		longest = len(b)
+	}
+	maxEdits := int(float64(longest) * (1 - minDuplicateNameSimilarity))
+	if diff := len(a) - len(b); diff > maxEdits || -diff > maxEdits {
+		return false
+	}
+	return editDistance(a, b) <= maxEdits
+}
+
+// editDistance is the optimal string alignment distance between a and b:
+// insertions, deletions, substitutions and adjacent transpositions
+func editDistance(a, b []rune) int {
+	prev2 := make([]int, len(b)+1)
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				curr[j] = min(curr[j], prev2[j-2]+1)
+			}
+		}
+		prev2, prev, curr = prev, curr, prev2
+	}
+	return prev[len(b)]
+}
+
+// duplicateUnion is a union-find over contact indexes that remembers why
+// contacts were joined
+type duplicateUnion struct {
+	parent  []int
+	reasons map[int]map[DuplicateReason]bool
+}
+
+func newDuplicateUnion(n int) *duplicateUnion {
+	u := &duplicateUnion{parent: make([]int, n), reasons: make(map[int]map[DuplicateReason]bool)}
+	for i := range u.parent {
+		u.parent[i] = i
+	}
+	return u
+}
+
+func (u *duplicateUnion) find(i int) int {
+	for u.parent[i] != i {
+		u.parent[i] = u.parent[u.parent[i]]
+		i = u.parent[i]
+	}
+	return i
+}
+
+func (u *duplicateUnion) union(a, b int, reason DuplicateReason) {
+	rootA, rootB := u.find(a), u.find(b)
+	if rootA != rootB {
+		u.parent[rootB] = rootA
+		for merged := range u.reasons[rootB] {
+			u.addReason(rootA, merged)
+		}
+		delete(u.reasons, rootB)
+	}
+	u.addReason(rootA, reason)
+}
+
+func (u *duplicateUnion) addReason(root int, reason DuplicateReason) {
+	if u.reasons[root] == nil {
+		u.reasons[root] = make(map[DuplicateReason]bool)
+	}
+	u.reasons[root][reason] = true
+}
+
+// collect returns the groups of two or more contacts, ordered by their first
+// contact, with contacts in input order
+func (u *duplicateUnion) collect(contacts []*Contact) []DuplicateGroup {
+	members := make(map[int][]*Contact)
+	var roots []int
+	for i, contact := range contacts {
+		root := u.find(i)
+		if _, seen := members[root]; !seen {
+			roots = append(roots, root)
+		}
+		members[root] = append(members[root], contact)
+	}
+
+	var groups []DuplicateGroup
+	for _, root := range roots {
+		if len(members[root]) < 2 {
+			continue
+		}
+		group := DuplicateGroup{Contacts: members[root]}
+		for _, reason := range []DuplicateReason{DuplicateReasonEmail, DuplicateReasonPhone, DuplicateReasonName} {
+			if u.reasons[root][reason] {
+				group.Reasons = append(group.Reasons, reason)
+			}
+		}
+		groups = append(groups, group)
+	}
+	return groups
+}
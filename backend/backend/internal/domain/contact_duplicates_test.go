@@ -0,0 +1,71 @@
+package domain
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNormalizeContactFields(t *testing.T) {
+	for in, want := range map[string]string{
+		" Jane.Doe+crm@Example.COM ": "jane.doe@example.com",
+		"not-an-email":               "",
+	} {
+		if got := NormalizeContactEmail(in); got != want {
+			t.Fatalf("NormalizeContactEmail(%q) = %q, want %q", in, got, want)
+		}
+	}
+	for in, want := range map[string]string{
+		"+34 612 34 56 78": "612345678",
+		"(612) 345-678":    "612345678",
+		"12-34":            "",
+	} {
+		if got := NormalizeContactPhone(in); got != want {
+			t.Fatalf("NormalizeContactPhone(%q) = %q, want %q", in, got, want)
+		}
+	}
+	for in, want := range map[string]string{
+		"Doe, John":        "doe john",
+		"ACME Inc.":        "acme",
+		"Café Müller S.L.": "cafe muller",
+	} {
+		if got := NormalizeContactName(in); got != want {
+			t.Fatalf("NormalizeContactName(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestFindDuplicateGroups(t *testing.T) {
+	contacts := []*Contact{
+		{ID: 1, FirstName: "Jane", LastName: "Doe", Email: "jane@example.com"},
+		{ID: 2, FirstName: "Janet", LastName: "Smith", Email: "JANE+invoices@example.com"},
+		{ID: 3, CompanyName: "Acme Inc", Phone: "+34 612 345 678"},
+		{ID: 4, CompanyName: "Globex", Phones: []ContactPhone{{Number: "612345678"}}},
+		{ID: 5, FirstName: "Jonathan", LastName: "Whitaker"},
+		{ID: 6, FirstName: "Jonathon", LastName: "Whitaker"},
+		{ID: 7, FirstName: "John", LastName: "Doe"},
+		{ID: 8, CompanyName: "ACME, Inc."},
+	}
+
+	groups := FindDuplicateGroups(contacts)
+
+	type group struct {
+		ids     []uint
+		reasons []DuplicateReason
+	}
+	var got []group
+	for _, g := range groups {
+		var ids []uint
+		for _, c := range g.Contacts {
+			ids = append(ids, c.ID)
+		}
+		got = append(got, group{ids, g.Reasons})
+	}
+	want := []group{
+		{[]uint{1, 2}, []DuplicateReason{DuplicateReasonEmail}},
+		{[]uint{3, 4, 8}, []DuplicateReason{DuplicateReasonPhone, DuplicateReasonName}},
+		{[]uint{5, 6}, []DuplicateReason{DuplicateReasonName}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected groups:\n got %+v\nwant %+v", got, want)
+	}
+}
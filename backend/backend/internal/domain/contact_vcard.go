@@ -0,0 +1,133 @@
+// @kthulu:module:contacts
+package domain
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const (
+	vcardRevisionLayout   = "20060102T150405Z"
+	vcardContactUIDFormat = "contact-%d@kthulu"
+)
+
+// VCardWriter writes contacts as concatenated vCard 3.0 (RFC 2426) entries
+type VCardWriter struct {
+	out *contentLineWriter
+}
+
+// NewVCardWriter creates a vCard writer on w. Call Flush once every contact
+// has been written.
+func NewVCardWriter(w io.Writer) *VCardWriter {
+	return &VCardWriter{out: &contentLineWriter{w: bufio.NewWriter(w)}}
+}
+
+// Write writes a contact with its addresses and phone numbers. Phone numbers
+// are written in E.164 when they could be normalized, and the primary
+// address and phone are marked as preferred.
+func (v *VCardWriter) Write(c *Contact) error {
+	o := v.out
+	o.line("BEGIN:VCARD")
+	o.line("VERSION:3.0")
+	o.line(fmt.Sprintf("UID:"+vcardContactUIDFormat, c.ID))
+	o.line("FN:" + contentText(c.GetDisplayName()))
+	o.line("N:" + vcardComponents(c.LastName, c.FirstName, "", "", ""))
+	if c.CompanyName != "" {
+		o.line("ORG:" + contentText(c.CompanyName))
+	}
+	if c.Email != "" {
+		o.line("EMAIL;TYPE=INTERNET:" + contentText(c.Email))
+	}
+
+	written := make(map[string]bool)
+	for _, phone := range c.Phones {
+		number := phone.NormalizedNumber
+		if number == "" {
+			number = phone.Number
+		}
+		if phone.Extension != "" {
+			number += " ext. " + phone.Extension
+		}
+		o.line("TEL;TYPE=" + vcardTypes(vcardPhoneType(phone.Type), phone.IsPrimary) + ":" + contentText(number))
+		written[phone.Number] = true
+		written[phone.NormalizedNumber] = true
+	}
+	// The inline numbers predate the phone list and are only written when the
+	// list doesn't already hold them
+	if c.Phone != "" && !written[c.Phone] {
+		o.line("TEL;TYPE=WORK,VOICE:" + contentText(c.Phone))
+	}
+	if c.Mobile != "" && !written[c.Mobile] {
+		o.line("TEL;TYPE=CELL:" + contentText(c.Mobile))
+	}
+
+	for _, address := range c.Addresses {
+		o.line("ADR;TYPE=" + vcardTypes(vcardAddressType(address.Type), address.IsPrimary) + ":" + vcardComponents(
+			"", address.AddressLine2, address.AddressLine1, address.City, address.State, address.PostalCode, address.Country,
+		))
+	}
+
+	if c.Website != "" {
+		o.line("URL:" + contentText(c.Website))
+	}
+	if c.Notes != "" {
+		o.line("NOTE:" + contentText(c.Notes))
+	}
+	o.line("CATEGORIES:" + contentText(string(c.Type)))
+	if !c.UpdatedAt.IsZero() {
+		o.line("REV:" + c.UpdatedAt.UTC().Format(vcardRevisionLayout))
+	}
+	o.line("END:VCARD")
+	return o.err
+}
+
+// Flush writes any buffered data to the underlying writer
+func (v *VCardWriter) Flush() error {
+	if v.out.err != nil {
+		return v.out.err
+	}
+	return v.out.w.Flush()
+}
+
+// vcardComponents joins the escaped components of a structured value
+func vcardComponents(values ...string) string {
+	for i, value := range values {
+		values[i] = contentText(value)
+	}
+	return strings.Join(values, ";")
+}
+
+func vcardTypes(kind string, preferred bool) string {
+	if preferred {
+		return kind + ",PREF"
+	}
+	return kind
+}
+
+func vcardPhoneType(kind PhoneType) string {
+	switch kind {
+	case PhoneTypeWork:
+		return "WORK,VOICE"
+	case PhoneTypeMobile:
+		return "CELL"
+	case PhoneTypeHome:
+		return "HOME,VOICE"
+	case PhoneTypeFax:
+		return "FAX"
+	}
+	return "VOICE"
+}
+
+func vcardAddressType(kind AddressType) string {
+	switch kind {
+	case AddressTypeHome:
+		return "HOME"
+	case AddressTypeShipping:
+		return "PARCEL"
+	case AddressTypeBilling:
+		return "POSTAL"
+	}
+	return "WORK"
+}
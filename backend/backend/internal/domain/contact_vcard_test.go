@@ -0,0 +1,72 @@
+package domain
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestVCardWriter(t *testing.T) {
+	contacts := []*Contact{
+		{
+			ID:        1,
+			Type:      ContactTypeCustomer,
+			FirstName: "Ana",
+			LastName:  "García",
+			Email:     "ana@example.com",
+			Phone:     "+34 912 345 678",
+			Mobile:    "600 111 222",
+			Notes:     "Prefers calls, not email",
+			UpdatedAt: time.Date(2024, 5, 20, 8, 0, 0, 0, time.UTC),
+			Phones: []ContactPhone{
+				{Type: PhoneTypeWork, Number: "+34 912 345 678", NormalizedNumber: "+34912345678", Extension: "12"},
+				{Type: PhoneTypeMobile, Number: "600 111 222", NormalizedNumber: "+34600111222", IsPrimary: true},
+			},
+			Addresses: []ContactAddress{
+				{Type: AddressTypeOffice, AddressLine1: "Calle Mayor, 1", AddressLine2: "2º", City: "Madrid", PostalCode: "28013", Country: "ES", IsPrimary: true},
+			},
+		},
+		{ID: 2, Type: ContactTypeSupplier, CompanyName: "Acme; Inc", Phone: "555-0100"},
+	}
+
+	var buf bytes.Buffer
+	vw := NewVCardWriter(&buf)
+	for _, contact := range contacts {
+		if err := vw.Write(contact); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+	if err := vw.Flush(); err != nil {
+		t.Fatalf("flush: %v", err)
+	}
+
+	// The content lines of both cards share icalLines' folding rules
+	lines := icalLines(t, buf.String())
+	text := strings.Join(lines, "\n")
+	if strings.Count(text, "BEGIN:VCARD") != 2 || strings.Count(text, "END:VCARD") != 2 || strings.Count(text, "VERSION:3.0") != 2 {
+		t.Fatalf("expected two vCard 3.0 entries, got\n%s", text)
+	}
+	for _, want := range []string{
+		"FN:Ana García",
+		"N:García;Ana;;;",
+		"EMAIL;TYPE=INTERNET:ana@example.com",
+		"TEL;TYPE=WORK,VOICE:+34912345678 ext. 12",
+		"TEL;TYPE=CELL,PREF:+34600111222",
+		`ADR;TYPE=WORK,PREF:;2º;Calle Mayor\, 1;Madrid;;28013;ES`,
+		`NOTE:Prefers calls\, not email`,
+		"CATEGORIES:customer",
+		"REV:20240520T080000Z",
+		`FN:Acme\; Inc`,
+		`ORG:Acme\; Inc`,
+		"TEL;TYPE=WORK,VOICE:555-0100",
+	} {
+		if !strings.Contains(text, want+"\n") {
+			t.Fatalf("missing %q in\n%s", want, text)
+		}
+	}
+	// Inline numbers already in the phone list are not repeated
+	if strings.Count(text, "TEL;") != 3 {
+		t.Fatalf("expected 3 phone numbers, got\n%s", text)
+	}
+}
@@ -382,7 +382,7 @@ func TestRefreshTokenRepositoryContract(t *testing.T) {
 		err := repo.Create(ctx, expiredToken)
 		require.NoError(t, err)
 
-		_, err = repo.DeleteExpired(ctx)
+		_, err = repo.DeleteExpired(ctx, time.Now())
 		require.NoError(t, err)
 
 		_, err = repo.FindByToken(ctx, expiredToken.Token)
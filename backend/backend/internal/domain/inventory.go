@@ -2,9 +2,16 @@
 package domain
 
 import (
+	"errors"
 	"time"
 )
 
+// Domain errors for inventory module
+var (
+	ErrInsufficientStock   = errors.New("insufficient available stock")
+	ErrReservationNotFound = errors.New("stock reservation not found")
+)
+
 // Warehouse represents a physical or logical storage location
 type Warehouse struct {
 	ID          uint      `json:"id" gorm:"primaryKey"`
@@ -76,6 +83,32 @@ type StockMovement struct {
 	User          *User         `json:"user,omitempty" gorm:"foreignKey:UserID"`
 }
 
+// InventoryMovement is an append-only ledger entry recording a change of the
+// on-hand quantity of a product in a warehouse. The sum of the deltas of a
+// product in a warehouse equals its current quantity.
+type InventoryMovement struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	ProductID   uint      `json:"productId" gorm:"not null;index"`
+	VariantID   *uint     `json:"variantId,omitempty" gorm:"index"`
+	WarehouseID uint      `json:"warehouseId" gorm:"not null;index"`
+	Delta       int       `json:"delta" gorm:"not null"`
+	Reason      string    `json:"reason" gorm:"not null;size:50"`
+	Reference   string    `json:"reference" gorm:"size:255"`
+	CreatedBy   *uint     `json:"createdBy,omitempty"`
+	CreatedAt   time.Time `json:"createdAt" gorm:"index"`
+}
+
+// StockReservation holds stock of an inventory item for a pending quote or
+// invoice. Reservations sharing a reference are released together.
+type StockReservation struct {
+	ID              uint      `json:"id" gorm:"primaryKey"`
+	InventoryItemID uint      `json:"inventoryItemId" gorm:"not null;index"`
+	ProductID       uint      `json:"productId" gorm:"not null;index"`
+	Quantity        int       `json:"quantity" gorm:"not null"`
+	Reference       string    `json:"reference" gorm:"not null;size:255;index"`
+	CreatedAt       time.Time `json:"createdAt"`
+}
+
 // StockAdjustment represents a manual adjustment to inventory levels
 type StockAdjustment struct {
 	ID              uint      `json:"id" gorm:"primaryKey"`
@@ -97,9 +130,9 @@ func (ii *InventoryItem) AvailableQuantity() int {
 	return ii.Quantity - ii.ReservedQuantity
 }
 
-// IsLowStock checks if the item is below the reorder point
+// IsLowStock checks if the available quantity is below the reorder point
 func (ii *InventoryItem) IsLowStock() bool {
-	return ii.Quantity <= ii.ReorderPoint
+	return ii.AvailableQuantity() <= ii.ReorderPoint
 }
 
 // IsOutOfStock checks if the item is out of stock
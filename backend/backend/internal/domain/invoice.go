@@ -3,25 +3,43 @@ package domain
 
 import (
 	"errors"
+	"fmt"
 	"strings"
 	"time"
 
 	"github.com/go-playground/validator/v10"
+
+	"github.com/pmaojo/kthulu-go/backend/internal/domain/money"
 )
 
 // Domain errors for invoice module
 var (
-	ErrInvoiceNotFound      = errors.New("invoice not found")
-	ErrInvoiceAlreadyExists = errors.New("invoice already exists")
-	ErrInvalidInvoiceNumber = errors.New("invalid invoice number")
-	ErrInvalidInvoiceType   = errors.New("invalid invoice type")
-	ErrInvalidInvoiceStatus = errors.New("invalid invoice status")
-	ErrInvoiceItemNotFound  = errors.New("invoice item not found")
-	ErrPaymentNotFound      = errors.New("payment not found")
-	ErrInvalidPaymentMethod = errors.New("invalid payment method")
-	ErrInvalidAmount        = errors.New("invalid amount")
-	ErrInvoiceNotEditable   = errors.New("invoice is not editable")
-	ErrInsufficientPayment  = errors.New("payment amount exceeds balance due")
+	ErrInvoiceNotFound         = errors.New("invoice not found")
+	ErrInvoiceAlreadyExists    = errors.New("invoice already exists")
+	ErrInvalidInvoiceNumber    = errors.New("invalid invoice number")
+	ErrInvalidInvoiceType      = errors.New("invalid invoice type")
+	ErrInvalidInvoiceStatus    = errors.New("invalid invoice status")
+	ErrIllegalStatusTransition = errors.New("illegal invoice status transition")
+	ErrInvoiceItemNotFound     = errors.New("invoice item not found")
+	ErrPaymentNotFound         = errors.New("payment not found")
+	ErrInvalidPaymentMethod    = errors.New("invalid payment method")
+	ErrInvalidAmount           = errors.New("invalid amount")
+	ErrInvoiceNotEditable      = errors.New("invoice is not editable")
+	ErrInsufficientPayment     = errors.New("payment amount exceeds balance due")
+	ErrRefundExceedsPaid       = errors.New("refund amount exceeds amount paid")
+	ErrInvoiceNotSendable      = errors.New("canceled invoices cannot be sent")
+	ErrInvoiceNoRecipient      = errors.New("invoice contact has no email address")
+	ErrInvalidRevenuePeriod    = errors.New("invalid revenue period")
+	ErrInvalidItemOrder        = errors.New("item order must list every invoice item exactly once")
+	ErrInvalidLineItem         = errors.New("invalid line item")
+	ErrInvalidInvoiceField     = errors.New("invalid invoice field")
+	ErrExchangeRateUnavailable = errors.New("exchange rate unavailable")
+	ErrInvoiceNotPayable       = errors.New("invoice has no balance due")
+	ErrPaymentNotAllowed       = errors.New("draft and canceled invoices cannot take payments")
+	ErrInvalidPaymentWebhook   = errors.New("invalid payment webhook")
+	ErrInvalidInvoiceFilters   = errors.New("invalid invoice filters")
+
+	ErrInvalidInvoiceNumberFormat = errors.New("invalid invoice number format")
 )
 
 // InvoiceType represents the type of invoice
@@ -103,6 +121,7 @@ type InvoiceItem struct {
 	DiscountAmount   float64   `json:"discountAmount" validate:"min=0"`
 	TaxRate          float64   `json:"taxRate" validate:"min=0,max=1"`
 	TaxAmount        float64   `json:"taxAmount" validate:"min=0"`
+	ReverseCharge    bool      `json:"reverseCharge"` // Tax is accounted for by the buyer
 	LineTotal        float64   `json:"lineTotal" validate:"min=0"`
 	SortOrder        int       `json:"sortOrder"`
 	CreatedAt        time.Time `json:"createdAt"`
@@ -116,7 +135,8 @@ type Payment struct {
 	InvoiceID       uint          `json:"invoiceId" validate:"required"`
 	PaymentMethod   PaymentMethod `json:"paymentMethod" validate:"required,oneof=cash check credit_card bank_transfer paypal stripe other"`
 	ReferenceNumber string        `json:"referenceNumber,omitempty" validate:"max=100"`
-	Amount          float64       `json:"amount" validate:"required,min=0"`
+	Amount          float64       `json:"amount" validate:"required"` // Negative for refunds
+	Refund          bool          `json:"refund"`                     // Follows the sign of Amount
 	Currency        string        `json:"currency" validate:"required,len=3"`
 	ExchangeRate    float64       `json:"exchangeRate" validate:"min=0"`
 	PaymentDate     time.Time     `json:"paymentDate" validate:"required"`
@@ -194,16 +214,56 @@ func (i *Invoice) UpdateBasicInfo(contactID uint, dueDate *time.Time, paymentTer
 	return i.Validate()
 }
 
+// IsValid reports whether the status is one of the known invoice statuses
+func (s InvoiceStatus) IsValid() bool {
+	switch s {
+	case InvoiceStatusDraft, InvoiceStatusSent, InvoiceStatusViewed, InvoiceStatusPartial,
+		InvoiceStatusPaid, InvoiceStatusOverdue, InvoiceStatusCancelled:
+		return true
+	}
+	return false
+}
+
+// invoiceStatusTransitions lists the statuses each status may move to.
+// Any status except canceled may move to canceled, and keeping the current
+// status is always allowed.
+var invoiceStatusTransitions = map[InvoiceStatus][]InvoiceStatus{
+	InvoiceStatusDraft:   {InvoiceStatusSent},
+	InvoiceStatusSent:    {InvoiceStatusViewed, InvoiceStatusPartial, InvoiceStatusPaid, InvoiceStatusOverdue},
+	InvoiceStatusViewed:  {InvoiceStatusPartial, InvoiceStatusPaid, InvoiceStatusOverdue},
+	InvoiceStatusPartial: {InvoiceStatusPaid, InvoiceStatusOverdue},
+	InvoiceStatusOverdue: {InvoiceStatusPartial, InvoiceStatusPaid},
+	InvoiceStatusPaid:    {},
+}
+
+// CanTransitionTo checks the invoice status state machine for moving the
+// invoice to status
+func (i *Invoice) CanTransitionTo(status InvoiceStatus) error {
+	if !status.IsValid() {
+		return fmt.Errorf("%w: %q", ErrInvalidInvoiceStatus, status)
+	}
+	if status == i.Status {
+		return nil
+	}
+	if i.Status == InvoiceStatusCancelled {
+		return fmt.Errorf("%w: cannot change status of canceled invoice", ErrIllegalStatusTransition)
+	}
+	if status == InvoiceStatusCancelled {
+		return nil
+	}
+
+	for _, next := range invoiceStatusTransitions[i.Status] {
+		if next == status {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s -> %s", ErrIllegalStatusTransition, i.Status, status)
+}
+
 // SetStatus sets the invoice status
 func (i *Invoice) SetStatus(status InvoiceStatus) error {
-	// Business rules for status transitions
-	switch i.Status {
-	case InvoiceStatusCancelled:
-		return errors.New("cannot change status of canceled invoice")
-	case InvoiceStatusPaid:
-		if status != InvoiceStatusPaid {
-			return errors.New("cannot change status of paid invoice")
-		}
+	if err := i.CanTransitionTo(status); err != nil {
+		return err
 	}
 
 	i.Status = status
@@ -241,6 +301,77 @@ func (i *Invoice) CalculateTotals() {
 	i.UpdatedAt = time.Now()
 }
 
+// RecalculateTotals recomputes every item's amounts and the invoice totals,
+// rounding each tax, discount and total to the invoice currency with policy
+// so stored totals always equal the sum of the stored lines
+func (i *Invoice) RecalculateTotals(policy money.Policy) {
+	for idx := range i.Items {
+		i.Items[idx].RecalculateAmounts(policy, i.Currency)
+	}
+	i.CalculateTotals()
+
+	i.Subtotal = policy.Round(i.Subtotal, i.Currency)
+	i.TaxAmount = policy.Round(i.TaxAmount, i.Currency)
+	i.DiscountAmount = policy.Round(i.DiscountAmount, i.Currency)
+	i.TotalAmount = policy.Round(i.Subtotal+i.TaxAmount-i.DiscountAmount, i.Currency)
+	i.BalanceDue = policy.Round(i.TotalAmount-i.PaidAmount, i.Currency)
+}
+
+// ApplyPayment records a payment of amount against the invoice, rounding the
+// new balance with policy and moving the invoice to partial or paid when its
+// status allows it. Draft and canceled invoices take no payments. A negative
+// amount is a refund, see applyRefund.
+func (i *Invoice) ApplyPayment(amount float64, policy money.Policy) error {
+	if amount < 0 {
+		return i.applyRefund(-amount, policy)
+	}
+	if i.Status == InvoiceStatusDraft || i.Status == InvoiceStatusCancelled {
+		return ErrPaymentNotAllowed
+	}
+	if amount > i.BalanceDue {
+		return ErrInsufficientPayment
+	}
+
+	i.PaidAmount = policy.Round(i.PaidAmount+amount, i.Currency)
+	i.BalanceDue = policy.Round(i.TotalAmount-i.PaidAmount, i.Currency)
+
+	status := InvoiceStatusPartial
+	if i.BalanceDue <= 0 {
+		status = InvoiceStatusPaid
+	}
+	if i.CanTransitionTo(status) == nil {
+		i.Status = status
+	}
+	i.UpdatedAt = time.Now()
+	return nil
+}
+
+// applyRefund gives back amount of what was paid on the invoice. Refunds
+// can't exceed the amount paid. They reopen a paid invoice as partial, or as
+// sent once nothing paid remains, which the status state machine doesn't let
+// users do by hand.
+func (i *Invoice) applyRefund(amount float64, policy money.Policy) error {
+	if amount > i.PaidAmount {
+		return ErrRefundExceedsPaid
+	}
+
+	i.PaidAmount = policy.Round(i.PaidAmount-amount, i.Currency)
+	i.BalanceDue = policy.Round(i.TotalAmount-i.PaidAmount, i.Currency)
+
+	if i.Status == InvoiceStatusPaid || i.Status == InvoiceStatusPartial {
+		switch {
+		case i.BalanceDue <= 0:
+			i.Status = InvoiceStatusPaid
+		case i.PaidAmount > 0:
+			i.Status = InvoiceStatusPartial
+		default:
+			i.Status = InvoiceStatusSent
+		}
+	}
+	i.UpdatedAt = time.Now()
+	return nil
+}
+
 // AddItem adds an item to the invoice
 func (i *Invoice) AddItem(item *InvoiceItem) error {
 	if !i.CanEdit() {
@@ -304,19 +435,19 @@ func NewInvoiceItem(invoiceID uint, description string, quantity, unitPrice floa
 func (ii *InvoiceItem) Validate() error {
 	validate := validator.New()
 	if err := validate.Struct(ii); err != nil {
-		return err
+		return fmt.Errorf("%w: %v", ErrInvalidLineItem, err)
 	}
 
 	if ii.Description == "" {
-		return errors.New("description is required")
+		return fmt.Errorf("%w: description is required", ErrInvalidLineItem)
 	}
 
 	if ii.Quantity <= 0 {
-		return errors.New("quantity must be greater than zero")
+		return fmt.Errorf("%w: quantity must be greater than zero", ErrInvalidLineItem)
 	}
 
 	if ii.UnitPrice < 0 {
-		return errors.New("unit price cannot be negative")
+		return fmt.Errorf("%w: unit price cannot be negative", ErrInvalidLineItem)
 	}
 
 	return nil
@@ -356,6 +487,50 @@ func (ii *InvoiceItem) CalculateLineTotal() {
 	ii.LineTotal = discountedSubtotal + ii.TaxAmount
 }
 
+// SetDiscount sets the discount of the line as a fraction of its subtotal, a
+// fixed amount, or both when they agree once rounded with policy. A discount
+// above the subtotal is rejected with ErrInvalidLineItem.
+func (ii *InvoiceItem) SetDiscount(percent, amount float64, policy money.Policy, currency string) error {
+	if percent < 0 || percent > 1 {
+		return fmt.Errorf("%w: discount percent must be between 0 and 1", ErrInvalidLineItem)
+	}
+	if amount < 0 {
+		return fmt.Errorf("%w: discount amount cannot be negative", ErrInvalidLineItem)
+	}
+
+	subtotal := policy.Round(ii.Quantity*ii.UnitPrice, currency)
+	if policy.Round(amount, currency) > subtotal {
+		return fmt.Errorf("%w: discount amount %.2f exceeds line subtotal %.2f", ErrInvalidLineItem, amount, subtotal)
+	}
+	if percent > 0 && amount > 0 && policy.Round(subtotal*percent, currency) != policy.Round(amount, currency) {
+		return fmt.Errorf("%w: discount amount %.2f does not match %.2f%% of %.2f", ErrInvalidLineItem, amount, percent*100, subtotal)
+	}
+
+	ii.DiscountPercent = percent
+	ii.DiscountAmount = amount
+	ii.UpdatedAt = time.Now()
+	return nil
+}
+
+// RecalculateAmounts recomputes the line like CalculateLineTotal, rounding
+// the discount and tax amounts to currency before they are added up
+func (ii *InvoiceItem) RecalculateAmounts(policy money.Policy, currency string) {
+	subtotal := policy.Round(ii.Quantity*ii.UnitPrice, currency)
+
+	if ii.DiscountPercent > 0 {
+		ii.DiscountAmount = subtotal * ii.DiscountPercent
+	}
+	ii.DiscountAmount = policy.Round(ii.DiscountAmount, currency)
+
+	discountedSubtotal := subtotal - ii.DiscountAmount
+	if ii.TaxRate > 0 {
+		ii.TaxAmount = discountedSubtotal * ii.TaxRate
+	}
+	ii.TaxAmount = policy.Round(ii.TaxAmount, currency)
+
+	ii.LineTotal = policy.Round(discountedSubtotal+ii.TaxAmount, currency)
+}
+
 // NewPayment creates a new payment with validation
 func NewPayment(organizationID, invoiceID, createdBy uint, method PaymentMethod, amount float64, currency string, paymentDate time.Time) (*Payment, error) {
 	payment := &Payment{
@@ -363,6 +538,7 @@ func NewPayment(organizationID, invoiceID, createdBy uint, method PaymentMethod,
 		InvoiceID:      invoiceID,
 		PaymentMethod:  method,
 		Amount:         amount,
+		Refund:         amount < 0,
 		Currency:       strings.ToUpper(strings.TrimSpace(currency)),
 		ExchangeRate:   1.0,
 		PaymentDate:    paymentDate,
@@ -385,7 +561,7 @@ func (p *Payment) Validate() error {
 		return err
 	}
 
-	if p.Amount <= 0 {
+	if p.Amount == 0 {
 		return ErrInvalidAmount
 	}
 
@@ -401,6 +577,7 @@ func (p *Payment) UpdateBasicInfo(method PaymentMethod, referenceNumber string,
 	p.PaymentMethod = method
 	p.ReferenceNumber = strings.TrimSpace(referenceNumber)
 	p.Amount = amount
+	p.Refund = amount < 0
 	p.PaymentDate = paymentDate
 	p.Notes = strings.TrimSpace(notes)
 	p.UpdatedAt = time.Now()
@@ -0,0 +1,201 @@
+// @kthulu:module:invoices
+package domain
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// InvoiceNumberFormat is a template for invoice numbers. Placeholders are
+// {prefix} (INV, QUO, CN or PRO by invoice type), {year}, {yy}, {month} and
+// {seq} or {seq:N} for the sequence zero-padded to N digits; anything else is
+// copied as is. The sequence restarts every month when the format contains
+// {month}, every year when it only contains a year, and never otherwise.
+// Every type keeps its own sequence, so {prefix} is required to keep their
+// numbers apart, and {month} needs a year so months of different years do.
+type InvoiceNumberFormat string
+
+// DefaultInvoiceNumberFormat renders numbers such as INV-2024-03-0001
+const DefaultInvoiceNumberFormat InvoiceNumberFormat = "{prefix}-{year}-{month}-{seq:4}"
+
+// maxInvoiceSequenceWidth bounds the {seq:N} padding
+const maxInvoiceSequenceWidth = 12
+
+var invoiceNumberPlaceholder = regexp.MustCompile(`\{([a-z]+)(?::(\d+))?\}`)
+
+// invoiceNumberToken is one literal or placeholder of a parsed format
+type invoiceNumberToken struct {
+	literal string
+	name    string
+	width   int
+}
+
+// OrDefault returns the format, or DefaultInvoiceNumberFormat when it is empty
+func (f InvoiceNumberFormat) OrDefault() InvoiceNumberFormat {
+	if strings.TrimSpace(string(f)) == "" {
+		return DefaultInvoiceNumberFormat
+	}
+	return f
+}
+
+// Validate checks that the format only uses known placeholders, contains
+// exactly one sequence and the {prefix}, and pairs {month} with a year
+func (f InvoiceNumberFormat) Validate() error {
+	_, err := f.parse()
+	return err
+}
+
+func (f InvoiceNumberFormat) parse() ([]invoiceNumberToken, error) {
+	var tokens []invoiceNumberToken
+	sequences := 0
+	placeholders := make(map[string]bool)
+	rest := string(f)
+	for rest != "" {
+		loc := invoiceNumberPlaceholder.FindStringSubmatchIndex(rest)
+		if loc == nil {
+			tokens = append(tokens, invoiceNumberToken{literal: rest})
+			break
+		}
+		if loc[0] > 0 {
+			tokens = append(tokens, invoiceNumberToken{literal: rest[:loc[0]]})
+		}
+
+		token := invoiceNumberToken{name: rest[loc[2]:loc[3]]}
+		hasWidth := loc[4] >= 0
+		switch token.name {
+		case "seq":
+			sequences++
+			if hasWidth {
+				token.width, _ = strconv.Atoi(rest[loc[4]:loc[5]])
+				if token.width < 1 || token.width > maxInvoiceSequenceWidth {
+					return nil, fmt.Errorf("%w: sequence width must be between 1 and %d", ErrInvalidInvoiceNumberFormat, maxInvoiceSequenceWidth)
+				}
+			}
+		case "prefix", "year", "yy", "month":
+			if hasWidth {
+				return nil, fmt.Errorf("%w: {%s} takes no width", ErrInvalidInvoiceNumberFormat, token.name)
+			}
+		default:
+			return nil, fmt.Errorf("%w: unknown placeholder {%s}", ErrInvalidInvoiceNumberFormat, token.name)
+		}
+		placeholders[token.name] = true
+		tokens = append(tokens, token)
+		rest = rest[loc[1]:]
+	}
+
+	if sequences != 1 {
+		return nil, fmt.Errorf("%w: exactly one {seq} placeholder is required", ErrInvalidInvoiceNumberFormat)
+	}
+	if !placeholders["prefix"] {
+		return nil, fmt.Errorf("%w: the {prefix} placeholder is required", ErrInvalidInvoiceNumberFormat)
+	}
+	if placeholders["month"] && !placeholders["year"] && !placeholders["yy"] {
+		return nil, fmt.Errorf("%w: {month} requires {year} or {yy}", ErrInvalidInvoiceNumberFormat)
+	}
+	for _, token := range tokens {
+		if strings.ContainsAny(token.literal, "{}") {
+			return nil, fmt.Errorf("%w: unbalanced braces in %q", ErrInvalidInvoiceNumberFormat, token.literal)
+		}
+	}
+	return tokens, nil
+}
+
+// Render formats the invoice number for the given prefix, date and sequence
+func (f InvoiceNumberFormat) Render(prefix string, at time.Time, sequence int) (string, error) {
+	tokens, err := f.parse()
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	for _, token := range tokens {
+		switch token.name {
+		case "":
+			b.WriteString(token.literal)
+		case "prefix":
+			b.WriteString(prefix)
+		case "year":
+			fmt.Fprintf(&b, "%04d", at.Year())
+		case "yy":
+			fmt.Fprintf(&b, "%02d", at.Year()%100)
+		case "month":
+			fmt.Fprintf(&b, "%02d", int(at.Month()))
+		case "seq":
+			fmt.Fprintf(&b, "%0*d", token.width, sequence)
+		}
+	}
+	return b.String(), nil
+}
+
+// SequencePattern returns a regexp matching the numbers this format renders
+// for prefix, whose first group is the sequence
+func (f InvoiceNumberFormat) SequencePattern(prefix string) (*regexp.Regexp, error) {
+	tokens, err := f.parse()
+	if err != nil {
+		return nil, err
+	}
+
+	var b strings.Builder
+	b.WriteString("^")
+	for _, token := range tokens {
+		switch token.name {
+		case "":
+			b.WriteString(regexp.QuoteMeta(token.literal))
+		case "prefix":
+			b.WriteString(regexp.QuoteMeta(prefix))
+		case "year":
+			b.WriteString(`\d{4}`)
+		case "yy", "month":
+			b.WriteString(`\d{2}`)
+		case "seq":
+			b.WriteString(`(\d+)`)
+		}
+	}
+	b.WriteString("$")
+	return regexp.Compile(b.String())
+}
+
+// SequencePeriod returns the [start, end) range sharing a sequence with at.
+// Both are zero when the sequence never restarts.
+func (f InvoiceNumberFormat) SequencePeriod(at time.Time) (start, end time.Time) {
+	tokens, err := f.parse()
+	if err != nil {
+		return time.Time{}, time.Time{}
+	}
+
+	monthly, yearly := false, false
+	for _, token := range tokens {
+		switch token.name {
+		case "month":
+			monthly = true
+		case "year", "yy":
+			yearly = true
+		}
+	}
+	switch {
+	case monthly:
+		start = time.Date(at.Year(), at.Month(), 1, 0, 0, 0, 0, at.Location())
+		return start, start.AddDate(0, 1, 0)
+	case yearly:
+		start = time.Date(at.Year(), time.January, 1, 0, 0, 0, 0, at.Location())
+		return start, start.AddDate(1, 0, 0)
+	}
+	return time.Time{}, time.Time{}
+}
+
+// InvoiceNumberPrefix returns the {prefix} used for an invoice type
+func InvoiceNumberPrefix(invoiceType InvoiceType) string {
+	switch invoiceType {
+	case InvoiceTypeQuote:
+		return "QUO"
+	case InvoiceTypeCreditNote:
+		return "CN"
+	case InvoiceTypeProforma:
+		return "PRO"
+	default:
+		return "INV"
+	}
+}
@@ -0,0 +1,74 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestInvoiceNumberFormatRender(t *testing.T) {
+	at := time.Date(2024, 3, 9, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		format   InvoiceNumberFormat
+		sequence int
+		want     string
+	}{
+		{DefaultInvoiceNumberFormat, 1, "INV-2024-03-0001"},
+		{"{prefix}/{year}/{seq:5}", 42, "INV/2024/00042"},
+		{"ACME-{prefix}{yy}{month}-{seq:3}", 7, "ACME-INV2403-007"},
+		{"{prefix}{seq}", 12345, "INV12345"},
+		// Sequences wider than the padding are never truncated
+		{"{seq:2}/{prefix}/{year}", 123, "123/INV/2024"},
+	}
+	for _, tt := range tests {
+		got, err := tt.format.Render("INV", at, tt.sequence)
+		if err != nil {
+			t.Fatalf("%s: render: %v", tt.format, err)
+		}
+		if got != tt.want {
+			t.Fatalf("%s: expected %q, got %q", tt.format, tt.want, got)
+		}
+
+		pattern, err := tt.format.SequencePattern("INV")
+		if err != nil {
+			t.Fatalf("%s: pattern: %v", tt.format, err)
+		}
+		if match := pattern.FindStringSubmatch(got); match == nil {
+			t.Fatalf("%s: pattern %s does not match %q", tt.format, pattern, got)
+		}
+	}
+}
+
+func TestInvoiceNumberFormatRejectsInvalidTemplates(t *testing.T) {
+	for _, format := range []InvoiceNumberFormat{
+		"{prefix}-{year}",         // no sequence
+		"{seq}-{seq}",             // two sequences
+		"{prefix}-{day}-{seq}",    // unknown placeholder
+		"{prefix}-{seq:0}",        // zero width
+		"{prefix}-{year:2}-{seq}", // width on a date
+		"{prefix-{seq}",           // unbalanced brace
+		"{year}-{seq}",            // no prefix, so types would share numbers
+		"{prefix}-{month}-{seq}",  // months of different years would collide
+	} {
+		if err := format.Validate(); !errors.Is(err, ErrInvalidInvoiceNumberFormat) {
+			t.Fatalf("%s: expected ErrInvalidInvoiceNumberFormat, got %v", format, err)
+		}
+	}
+}
+
+func TestInvoiceNumberFormatSequencePeriod(t *testing.T) {
+	at := time.Date(2024, 3, 9, 15, 0, 0, 0, time.UTC)
+
+	start, end := DefaultInvoiceNumberFormat.SequencePeriod(at)
+	if !start.Equal(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)) || !end.Equal(time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("expected a monthly sequence, got %s - %s", start, end)
+	}
+	start, end = InvoiceNumberFormat("{prefix}/{year}/{seq:5}").SequencePeriod(at)
+	if !start.Equal(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)) || !end.Equal(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("expected a yearly sequence, got %s - %s", start, end)
+	}
+	if start, _ = InvoiceNumberFormat("{prefix}{seq:6}").SequencePeriod(at); !start.IsZero() {
+		t.Fatalf("expected a sequence that never restarts, got %s", start)
+	}
+}
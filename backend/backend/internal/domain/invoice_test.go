@@ -0,0 +1,198 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/pmaojo/kthulu-go/backend/internal/domain/money"
+)
+
+func TestInvoiceCanTransitionTo(t *testing.T) {
+	legal := map[InvoiceStatus][]InvoiceStatus{
+		InvoiceStatusDraft:     {InvoiceStatusDraft, InvoiceStatusSent, InvoiceStatusCancelled},
+		InvoiceStatusSent:      {InvoiceStatusSent, InvoiceStatusViewed, InvoiceStatusPartial, InvoiceStatusPaid, InvoiceStatusOverdue, InvoiceStatusCancelled},
+		InvoiceStatusViewed:    {InvoiceStatusViewed, InvoiceStatusPartial, InvoiceStatusPaid, InvoiceStatusOverdue, InvoiceStatusCancelled},
+		InvoiceStatusPartial:   {InvoiceStatusPartial, InvoiceStatusPaid, InvoiceStatusOverdue, InvoiceStatusCancelled},
+		InvoiceStatusOverdue:   {InvoiceStatusOverdue, InvoiceStatusPartial, InvoiceStatusPaid, InvoiceStatusCancelled},
+		InvoiceStatusPaid:      {InvoiceStatusPaid, InvoiceStatusCancelled},
+		InvoiceStatusCancelled: {InvoiceStatusCancelled},
+	}
+	all := []InvoiceStatus{
+		InvoiceStatusDraft, InvoiceStatusSent, InvoiceStatusViewed, InvoiceStatusPartial,
+		InvoiceStatusPaid, InvoiceStatusOverdue, InvoiceStatusCancelled,
+	}
+
+	for _, from := range all {
+		allowed := make(map[InvoiceStatus]bool)
+		for _, to := range legal[from] {
+			allowed[to] = true
+		}
+		for _, to := range all {
+			invoice := &Invoice{Status: from}
+			err := invoice.CanTransitionTo(to)
+			if allowed[to] && err != nil {
+				t.Fatalf("%s -> %s should be legal, got %v", from, to, err)
+			}
+			if !allowed[to] && !errors.Is(err, ErrIllegalStatusTransition) {
+				t.Fatalf("%s -> %s should be illegal, got %v", from, to, err)
+			}
+		}
+	}
+}
+
+func TestInvoiceSetStatusRejectsIllegalTransition(t *testing.T) {
+	invoice := &Invoice{Status: InvoiceStatusPaid}
+
+	if err := invoice.SetStatus(InvoiceStatusDraft); !errors.Is(err, ErrIllegalStatusTransition) {
+		t.Fatalf("expected ErrIllegalStatusTransition, got %v", err)
+	}
+	if invoice.Status != InvoiceStatusPaid {
+		t.Fatalf("status must be unchanged after a rejected transition, got %s", invoice.Status)
+	}
+
+	if err := invoice.SetStatus("archived"); !errors.Is(err, ErrInvalidInvoiceStatus) {
+		t.Fatalf("expected ErrInvalidInvoiceStatus, got %v", err)
+	}
+}
+
+func TestInvoiceRecalculateTotalsRoundsWithPolicy(t *testing.T) {
+	newInvoice := func() *Invoice {
+		// 5% tax on 0.10 and 0.30 lands exactly on 0.005 and 0.015
+		return &Invoice{Currency: "EUR", PaidAmount: 0.1, Items: []InvoiceItem{
+			{Quantity: 1, UnitPrice: 0.1, TaxRate: 0.05},
+			{Quantity: 3, UnitPrice: 0.1, TaxRate: 0.05},
+		}}
+	}
+	halfEven, err := money.NewPolicy(money.RoundHalfEven, nil)
+	if err != nil {
+		t.Fatalf("new policy: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		policy     money.Policy
+		itemTaxes  [2]float64
+		tax, total float64
+	}{
+		{"half-up", money.DefaultPolicy(), [2]float64{0.01, 0.02}, 0.03, 0.43},
+		{"half-even", halfEven, [2]float64{0.00, 0.02}, 0.02, 0.42},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			invoice := newInvoice()
+			invoice.RecalculateTotals(tt.policy)
+
+			for i, want := range tt.itemTaxes {
+				if invoice.Items[i].TaxAmount != want {
+					t.Fatalf("item %d: expected tax %v, got %v", i, want, invoice.Items[i].TaxAmount)
+				}
+			}
+			if invoice.Subtotal != 0.4 || invoice.TaxAmount != tt.tax || invoice.TotalAmount != tt.total {
+				t.Fatalf("unexpected totals subtotal=%v tax=%v total=%v", invoice.Subtotal, invoice.TaxAmount, invoice.TotalAmount)
+			}
+			if want := money.Round(tt.total-0.1, 2, tt.policy.Mode); invoice.BalanceDue != want {
+				t.Fatalf("expected balance %v, got %v", want, invoice.BalanceDue)
+			}
+		})
+	}
+}
+
+func TestInvoiceApplyPayment(t *testing.T) {
+	invoice := &Invoice{Status: InvoiceStatusSent, Currency: "EUR", TotalAmount: 100, BalanceDue: 100}
+
+	if err := invoice.ApplyPayment(30.005, money.DefaultPolicy()); err != nil {
+		t.Fatalf("apply partial payment: %v", err)
+	}
+	if invoice.PaidAmount != 30.01 || invoice.BalanceDue != 69.99 || invoice.Status != InvoiceStatusPartial {
+		t.Fatalf("unexpected invoice after partial payment paid=%v balance=%v status=%s", invoice.PaidAmount, invoice.BalanceDue, invoice.Status)
+	}
+
+	if err := invoice.ApplyPayment(70, money.DefaultPolicy()); !errors.Is(err, ErrInsufficientPayment) {
+		t.Fatalf("expected ErrInsufficientPayment, got %v", err)
+	}
+	if err := invoice.ApplyPayment(69.99, money.DefaultPolicy()); err != nil {
+		t.Fatalf("apply final payment: %v", err)
+	}
+	if invoice.BalanceDue != 0 || invoice.Status != InvoiceStatusPaid {
+		t.Fatalf("expected a settled invoice, got balance=%v status=%s", invoice.BalanceDue, invoice.Status)
+	}
+}
+
+func TestInvoiceApplyPayment_RejectsDraftAndCanceledInvoices(t *testing.T) {
+	for _, status := range []InvoiceStatus{InvoiceStatusDraft, InvoiceStatusCancelled} {
+		invoice := &Invoice{Status: status, Currency: "EUR", TotalAmount: 100, BalanceDue: 100}
+		if err := invoice.ApplyPayment(100, money.DefaultPolicy()); !errors.Is(err, ErrPaymentNotAllowed) {
+			t.Fatalf("expected ErrPaymentNotAllowed for a %s invoice, got %v", status, err)
+		}
+		if invoice.PaidAmount != 0 || invoice.BalanceDue != 100 || invoice.Status != status {
+			t.Fatalf("expected a %s invoice to be left untouched, got paid=%v balance=%v status=%s", status, invoice.PaidAmount, invoice.BalanceDue, invoice.Status)
+		}
+	}
+
+	// What was paid before canceling can still be refunded
+	invoice := &Invoice{Status: InvoiceStatusCancelled, Currency: "EUR", TotalAmount: 100, PaidAmount: 100}
+	if err := invoice.ApplyPayment(-100, money.DefaultPolicy()); err != nil {
+		t.Fatalf("refund canceled invoice: %v", err)
+	}
+}
+
+func TestInvoiceApplyRefund(t *testing.T) {
+	invoice := &Invoice{Status: InvoiceStatusPaid, Currency: "EUR", TotalAmount: 100, PaidAmount: 100}
+
+	if err := invoice.ApplyPayment(-100.01, money.DefaultPolicy()); !errors.Is(err, ErrRefundExceedsPaid) {
+		t.Fatalf("expected ErrRefundExceedsPaid, got %v", err)
+	}
+	if err := invoice.ApplyPayment(-40, money.DefaultPolicy()); err != nil {
+		t.Fatalf("apply partial refund: %v", err)
+	}
+	if invoice.PaidAmount != 60 || invoice.BalanceDue != 40 || invoice.Status != InvoiceStatusPartial {
+		t.Fatalf("unexpected invoice after partial refund paid=%v balance=%v status=%s", invoice.PaidAmount, invoice.BalanceDue, invoice.Status)
+	}
+	if err := invoice.ApplyPayment(-60, money.DefaultPolicy()); err != nil {
+		t.Fatalf("apply full refund: %v", err)
+	}
+	if invoice.PaidAmount != 0 || invoice.BalanceDue != 100 || invoice.Status != InvoiceStatusSent {
+		t.Fatalf("expected an unpaid invoice, got paid=%v balance=%v status=%s", invoice.PaidAmount, invoice.BalanceDue, invoice.Status)
+	}
+}
+
+func TestInvoiceItemSetDiscount(t *testing.T) {
+	policy := money.DefaultPolicy()
+	newItem := func() *InvoiceItem {
+		return &InvoiceItem{InvoiceID: 1, Description: "Consulting", Quantity: 2, UnitPrice: 50}
+	}
+
+	for name, tc := range map[string]struct {
+		percent, amount float64
+	}{
+		"percent above 100%":        {percent: 1.5},
+		"negative percent":          {percent: -0.1},
+		"negative amount":           {amount: -1},
+		"amount above subtotal":     {amount: 100.01},
+		"amount disagrees with pct": {percent: 0.1, amount: 15},
+	} {
+		if err := newItem().SetDiscount(tc.percent, tc.amount, policy, "EUR"); !errors.Is(err, ErrInvalidLineItem) {
+			t.Fatalf("%s: expected ErrInvalidLineItem, got %v", name, err)
+		}
+	}
+
+	item := newItem()
+	if err := item.SetDiscount(0.1, 10, policy, "EUR"); err != nil {
+		t.Fatalf("matching percent and amount: %v", err)
+	}
+	item.TaxRate = 0.2
+	item.RecalculateAmounts(policy, "EUR")
+	if item.DiscountAmount != 10 || item.TaxAmount != 18 || item.LineTotal != 108 {
+		t.Fatalf("unexpected amounts discount=%v tax=%v total=%v", item.DiscountAmount, item.TaxAmount, item.LineTotal)
+	}
+
+	// A fixed discount is kept as is
+	item = newItem()
+	if err := item.SetDiscount(0, 25, policy, "EUR"); err != nil {
+		t.Fatalf("fixed discount: %v", err)
+	}
+	item.RecalculateAmounts(policy, "EUR")
+	if item.LineTotal != 75 {
+		t.Fatalf("expected line total 75, got %v", item.LineTotal)
+	}
+}
@@ -0,0 +1,97 @@
+// Package money rounds monetary amounts to the precision of their currency.
+package money
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// RoundingMode decides which way amounts exactly halfway between two minor
+// units are rounded
+type RoundingMode string
+
+const (
+	// RoundHalfUp rounds halves away from zero: 0.125 becomes 0.13
+	RoundHalfUp RoundingMode = "half-up"
+	// RoundHalfEven rounds halves to the even neighbour (banker's rounding):
+	// 0.125 becomes 0.12 and 0.135 becomes 0.14
+	RoundHalfEven RoundingMode = "half-even"
+)
+
+// DefaultPrecision is the number of decimals of currencies without an entry
+// in the policy
+const DefaultPrecision = 2
+
+// ParseRoundingMode parses a configured rounding mode. "bankers" is accepted
+// as an alias of half-even.
+func ParseRoundingMode(s string) (RoundingMode, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", string(RoundHalfUp):
+		return RoundHalfUp, nil
+	case string(RoundHalfEven), "bankers":
+		return RoundHalfEven, nil
+	}
+	return "", fmt.Errorf("unsupported rounding mode %q (supported: half-up, half-even)", s)
+}
+
+// Policy rounds amounts with one mode and a precision per currency
+type Policy struct {
+	Mode      RoundingMode
+	Precision map[string]int
+}
+
+// NewPolicy creates a policy for mode whose currency precisions are the ISO
+// 4217 defaults of DefaultPolicy overridden by precision
+func NewPolicy(mode RoundingMode, precision map[string]int) (Policy, error) {
+	if _, err := ParseRoundingMode(string(mode)); err != nil {
+		return Policy{}, err
+	}
+	policy := DefaultPolicy()
+	policy.Mode = mode
+	for currency, decimals := range precision {
+		if decimals < 0 || decimals > 8 {
+			return Policy{}, fmt.Errorf("invalid precision %d for %s", decimals, currency)
+		}
+		policy.Precision[strings.ToUpper(currency)] = decimals
+	}
+	return policy, nil
+}
+
+// DefaultPolicy rounds half-up with the ISO 4217 minor units of currencies
+// that don't use two decimals
+func DefaultPolicy() Policy {
+	return Policy{
+		Mode: RoundHalfUp,
+		Precision: map[string]int{
+			"BHD": 3, "CLP": 0, "ISK": 0, "JOD": 3, "JPY": 0, "KRW": 0,
+			"KWD": 3, "OMR": 3, "TND": 3, "UGX": 0, "VND": 0,
+		},
+	}
+}
+
+// PrecisionFor returns the number of decimals amounts in currency keep
+func (p Policy) PrecisionFor(currency string) int {
+	if decimals, ok := p.Precision[strings.ToUpper(currency)]; ok {
+		return decimals
+	}
+	return DefaultPrecision
+}
+
+// Round rounds amount to the precision of currency
+func (p Policy) Round(amount float64, currency string) float64 {
+	return Round(amount, p.PrecisionFor(currency), p.Mode)
+}
+
+// Round rounds amount to decimals places. The scaled amount is first
+// snapped to a millionth of a minor unit so values such as 1.005, which
+// floats store as 1.00499999..., are treated as the exact halves they were
+// written as.
+func Round(amount float64, decimals int, mode RoundingMode) float64 {
+	scale := math.Pow10(decimals)
+	scaled := math.Round(amount*scale*1e6) / 1e6
+	if mode == RoundHalfEven {
+		return math.RoundToEven(scaled) / scale
+	}
+	return math.Round(scaled) / scale
+}
@@ -0,0 +1,57 @@
+package money
+
+import "testing"
+
+func TestRoundHalfUpVersusHalfEven(t *testing.T) {
+	tests := []struct {
+		amount           float64
+		halfUp, halfEven float64
+	}{
+		{1.005, 1.01, 1.00},
+		{1.015, 1.02, 1.02},
+		{2.675, 2.68, 2.68},
+		{0.125, 0.13, 0.12},
+		{-1.005, -1.01, -1.00},
+		{10.004, 10.00, 10.00},
+		{10.006, 10.01, 10.01},
+	}
+	for _, tt := range tests {
+		if got := Round(tt.amount, 2, RoundHalfUp); got != tt.halfUp {
+			t.Errorf("half-up %v: expected %v, got %v", tt.amount, tt.halfUp, got)
+		}
+		if got := Round(tt.amount, 2, RoundHalfEven); got != tt.halfEven {
+			t.Errorf("half-even %v: expected %v, got %v", tt.amount, tt.halfEven, got)
+		}
+	}
+}
+
+func TestPolicyUsesCurrencyPrecision(t *testing.T) {
+	policy, err := NewPolicy(RoundHalfEven, map[string]int{"eur": 3})
+	if err != nil {
+		t.Fatalf("new policy: %v", err)
+	}
+
+	if got := policy.Round(1234.5, "JPY"); got != 1234 {
+		t.Fatalf("expected JPY to round to whole yen, got %v", got)
+	}
+	if got := policy.Round(1.0005, "EUR"); got != 1.000 {
+		t.Fatalf("expected the EUR override to keep three decimals, got %v", got)
+	}
+	if got := policy.Round(1.005, "USD"); got != 1.00 {
+		t.Fatalf("expected two decimals by default, got %v", got)
+	}
+}
+
+func TestParseRoundingMode(t *testing.T) {
+	for input, want := range map[string]RoundingMode{"": RoundHalfUp, "half-up": RoundHalfUp, "Bankers": RoundHalfEven, "half-even": RoundHalfEven} {
+		if got, err := ParseRoundingMode(input); err != nil || got != want {
+			t.Fatalf("%q: expected %s, got %s (%v)", input, want, got, err)
+		}
+	}
+	if _, err := ParseRoundingMode("ceiling"); err == nil {
+		t.Fatalf("expected an error for an unknown mode")
+	}
+	if _, err := NewPolicy(RoundHalfUp, map[string]int{"EUR": -1}); err == nil {
+		t.Fatalf("expected an error for a negative precision")
+	}
+}
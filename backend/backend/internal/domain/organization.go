@@ -20,6 +20,11 @@ var (
 	ErrInvitationExpired         = errors.New("invitation expired")
 	ErrInvitationAlreadyAccepted = errors.New("invitation already accepted")
 	ErrInsufficientPermissions   = errors.New("insufficient permissions")
+	ErrInvalidLogo               = errors.New("logo must be a PNG, JPEG, GIF or WebP image")
+	ErrLogoTooLarge              = errors.New("logo is too large")
+	ErrInvalidEmailTemplate      = errors.New("invalid email template")
+	ErrOrganizationActive        = errors.New("organization must be deactivated before it is deleted")
+	ErrOrganizationNotEmpty      = errors.New("organization still has contacts, products or invoices")
 )
 
 // OrganizationType represents the type of organization
@@ -52,6 +57,9 @@ type Organization struct {
 	CreatedAt   time.Time        `json:"createdAt"`
 	UpdatedAt   time.Time        `json:"updatedAt"`
 
+	// Settings; InvoiceNumberFormat overrides DefaultInvoiceNumberFormat when set
+	InvoiceNumberFormat InvoiceNumberFormat `json:"invoiceNumberFormat,omitempty" validate:"max=100"`
+
 	// Relationships
 	Users []OrganizationUser `json:"users,omitempty"`
 }
@@ -81,6 +89,14 @@ type OrganizationUser struct {
 	User         *User         `json:"user,omitempty"`
 }
 
+// OrganizationMember is a user of an organization together with their role
+type OrganizationMember struct {
+	UserID   uint             `json:"userId"`
+	Email    string           `json:"email"`
+	Role     OrganizationRole `json:"role"`
+	JoinedAt time.Time        `json:"joinedAt"`
+}
+
 // InvitationStatus represents the status of an invitation
 type InvitationStatus string
 
@@ -190,6 +206,30 @@ func (o *Organization) UpdateContactInfo(website, phone, address, city, state, c
 	return orgValidator.Struct(o)
 }
 
+// SetInvoiceNumberFormat sets the template for the organization's invoice
+// numbers; an empty format restores the default
+func (o *Organization) SetInvoiceNumberFormat(format string) error {
+	invoiceFormat := InvoiceNumberFormat(strings.TrimSpace(format))
+	if invoiceFormat != "" {
+		if err := invoiceFormat.Validate(); err != nil {
+			return err
+		}
+	}
+
+	o.InvoiceNumberFormat = invoiceFormat
+	o.UpdatedAt = time.Now()
+
+	return orgValidator.Struct(o)
+}
+
+// SetLogoURL points the organization at a newly uploaded logo
+func (o *Organization) SetLogoURL(logoURL string) error {
+	o.LogoURL = strings.TrimSpace(logoURL)
+	o.UpdatedAt = time.Now()
+
+	return orgValidator.Struct(o)
+}
+
 // SetDomain sets the organization's domain
 func (o *Organization) SetDomain(domain string) error {
 	domain = strings.TrimSpace(strings.ToLower(domain))
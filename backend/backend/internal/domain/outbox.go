@@ -0,0 +1,36 @@
+// @kthulu:module:webhooks
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// OutboxEvent is a domain event stored in the same transaction as the write
+// that produced it, so it is published even if the process dies right after
+// the commit.
+type OutboxEvent struct {
+	ID             uint            `json:"id"`
+	OrganizationID uint            `json:"organizationId"`
+	Event          WebhookEvent    `json:"event"`
+	Payload        json.RawMessage `json:"payload"`
+	Attempts       int             `json:"attempts"`
+	LastError      string          `json:"lastError,omitempty"`
+	CreatedAt      time.Time       `json:"createdAt"`
+	DeliveredAt    *time.Time      `json:"deliveredAt,omitempty"`
+}
+
+// NewOutboxEvent creates a pending outbox event carrying data as its JSON payload
+func NewOutboxEvent(organizationID uint, event WebhookEvent, data interface{}) (*OutboxEvent, error) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode outbox payload: %w", err)
+	}
+	return &OutboxEvent{
+		OrganizationID: organizationID,
+		Event:          event,
+		Payload:        payload,
+		CreatedAt:      time.Now(),
+	}, nil
+}
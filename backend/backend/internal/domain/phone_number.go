@@ -0,0 +1,34 @@
+// @kthulu:module:contacts
+package domain
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/nyaruka/phonenumbers"
+)
+
+// ErrInvalidPhoneNumber is returned for numbers that cannot be parsed or
+// have the wrong length for their country
+var ErrInvalidPhoneNumber = errors.New("invalid phone number")
+
+// NormalizePhoneNumber returns the E.164 form of number, such as
+// +15551234567. Numbers without a country code are read as numbers of
+// defaultRegion, an ISO 3166-1 alpha-2 code.
+func NormalizePhoneNumber(number, defaultRegion string) (string, error) {
+	parsed, err := phonenumbers.Parse(number, PhoneRegion(defaultRegion))
+	if err != nil || !phonenumbers.IsPossibleNumber(parsed) {
+		return "", ErrInvalidPhoneNumber
+	}
+	return phonenumbers.Format(parsed, phonenumbers.E164), nil
+}
+
+// PhoneRegion returns country as a region known to the phone number parser,
+// or an empty string when it is not an ISO 3166-1 alpha-2 code
+func PhoneRegion(country string) string {
+	region := strings.ToUpper(strings.TrimSpace(country))
+	if len(region) != 2 || phonenumbers.GetCountryCodeForRegion(region) == 0 {
+		return ""
+	}
+	return region
+}
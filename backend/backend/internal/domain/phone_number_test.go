@@ -0,0 +1,63 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNormalizePhoneNumber(t *testing.T) {
+	for _, number := range []string{
+		"+1 (650) 253-0000",
+		"650.253.0000",
+		"(650) 253 0000",
+		"1-650-253-0000",
+		"+16502530000",
+	} {
+		got, err := NormalizePhoneNumber(number, "us")
+		if err != nil {
+			t.Fatalf("%q: unexpected error %v", number, err)
+		}
+		if got != "+16502530000" {
+			t.Fatalf("%q: expected +16502530000, got %q", number, got)
+		}
+	}
+
+	// The default region only applies to numbers without a country code
+	for _, number := range []string{"912 34 56 78", "+34 912-345-678", "0034 912345678"} {
+		got, err := NormalizePhoneNumber(number, "ES")
+		if err != nil {
+			t.Fatalf("%q: unexpected error %v", number, err)
+		}
+		if got != "+34912345678" {
+			t.Fatalf("%q: expected +34912345678, got %q", number, got)
+		}
+	}
+
+	for _, number := range []string{"", "not a number", "912 34 56 78"} {
+		if _, err := NormalizePhoneNumber(number, ""); !errors.Is(err, ErrInvalidPhoneNumber) {
+			t.Fatalf("%q: expected ErrInvalidPhoneNumber, got %v", number, err)
+		}
+	}
+}
+
+func TestPhoneRegion(t *testing.T) {
+	for country, want := range map[string]string{"us": "US", " es ": "ES", "Spain": "", "ZZ": "", "": ""} {
+		if got := PhoneRegion(country); got != want {
+			t.Fatalf("%q: expected %q, got %q", country, want, got)
+		}
+	}
+}
+
+func TestContactPhoneNormalize(t *testing.T) {
+	phone := &ContactPhone{Number: "(650) 253-0000"}
+	phone.Normalize("US")
+	if phone.Number != "(650) 253-0000" || phone.NormalizedNumber != "+16502530000" {
+		t.Fatalf("expected raw and normalized numbers to be kept, got %+v", phone)
+	}
+
+	phone.Number = "ext. 12"
+	phone.Normalize("US")
+	if phone.NormalizedNumber != "" {
+		t.Fatalf("expected unparseable number to clear the normalized form, got %q", phone.NormalizedNumber)
+	}
+}
@@ -3,6 +3,7 @@ package domain
 
 import (
 	"errors"
+	"math"
 	"strings"
 	"time"
 
@@ -21,8 +22,21 @@ var (
 	ErrInvalidPriceType     = errors.New("invalid price type")
 	ErrInvalidCurrency      = errors.New("invalid currency")
 	ErrInvalidQuantityRange = errors.New("invalid quantity range")
+	ErrInvalidTaxRate       = errors.New("invalid tax rate")
 )
 
+// MaxTaxRatePercent is the highest tax rate accepted as a percentage
+const MaxTaxRatePercent = 100
+
+// TaxRateFromPercent converts a percentage such as 21 to the fraction stored
+// in Product.TaxRate
+func TaxRateFromPercent(percent float64) (float64, error) {
+	if math.IsNaN(percent) || percent < 0 || percent > MaxTaxRatePercent {
+		return 0, ErrInvalidTaxRate
+	}
+	return percent / 100, nil
+}
+
 // PriceType represents the type of price
 type PriceType string
 
@@ -42,6 +56,7 @@ type Product struct {
 	Name           string    `json:"name" validate:"required,min=1,max=200"`
 	Description    string    `json:"description,omitempty"`
 	Category       string    `json:"category,omitempty" validate:"max=100"`
+	CategoryID     *uint     `json:"categoryId,omitempty"` // node of the category tree
 	Brand          string    `json:"brand,omitempty" validate:"max=100"`
 	UnitOfMeasure  string    `json:"unitOfMeasure" validate:"required,max=20"`
 	Weight         *float64  `json:"weight,omitempty" validate:"omitempty,min=0"`
@@ -0,0 +1,90 @@
+// @kthulu:module:products
+package domain
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Domain errors for product categories
+var (
+	ErrProductCategoryNotFound = errors.New("product category not found")
+	ErrInvalidProductCategory  = errors.New("invalid product category")
+)
+
+// maxProductCategoryNameLength bounds category names
+const maxProductCategoryNameLength = 100
+
+// ProductCategory is a node of an organization's category tree. Root
+// categories have no parent.
+type ProductCategory struct {
+	ID             uint      `json:"id"`
+	OrganizationID uint      `json:"organizationId"`
+	ParentID       *uint     `json:"parentId,omitempty"`
+	Name           string    `json:"name"`
+	CreatedAt      time.Time `json:"createdAt"`
+	UpdatedAt      time.Time `json:"updatedAt"`
+
+	// Filled in by BuildProductCategoryTree
+	Children []*ProductCategory `json:"children,omitempty"`
+}
+
+// NewProductCategory creates a category under parentID, or a root category
+// when parentID is nil
+func NewProductCategory(organizationID uint, parentID *uint, name string) (*ProductCategory, error) {
+	name = strings.TrimSpace(name)
+	if organizationID == 0 || name == "" || len(name) > maxProductCategoryNameLength {
+		return nil, ErrInvalidProductCategory
+	}
+	if parentID != nil && *parentID == 0 {
+		parentID = nil
+	}
+
+	now := time.Now()
+	return &ProductCategory{
+		OrganizationID: organizationID,
+		ParentID:       parentID,
+		Name:           name,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}, nil
+}
+
+// BuildProductCategoryTree links flat categories to their parents and
+// returns the roots. Siblings are sorted by name. Categories whose parent is
+// not in the list are treated as roots.
+func BuildProductCategoryTree(categories []*ProductCategory) []*ProductCategory {
+	byID := make(map[uint]*ProductCategory, len(categories))
+	for _, category := range categories {
+		category.Children = nil
+		byID[category.ID] = category
+	}
+
+	var roots []*ProductCategory
+	for _, category := range categories {
+		if category.ParentID != nil {
+			if parent, ok := byID[*category.ParentID]; ok {
+				parent.Children = append(parent.Children, category)
+				continue
+			}
+		}
+		roots = append(roots, category)
+	}
+
+	sortProductCategories(roots)
+	return roots
+}
+
+func sortProductCategories(categories []*ProductCategory) {
+	sort.Slice(categories, func(i, j int) bool {
+		if categories[i].Name != categories[j].Name {
+			return categories[i].Name < categories[j].Name
+		}
+		return categories[i].ID < categories[j].ID
+	})
+	for _, category := range categories {
+		sortProductCategories(category.Children)
+	}
+}
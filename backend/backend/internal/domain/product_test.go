@@ -0,0 +1,25 @@
+package domain
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestTaxRateFromPercent(t *testing.T) {
+	for percent, want := range map[float64]float64{0: 0, 21: 0.21, 7.5: 0.075, 100: 1} {
+		got, err := TaxRateFromPercent(percent)
+		if err != nil {
+			t.Fatalf("%v%%: unexpected error %v", percent, err)
+		}
+		if math.Abs(got-want) > 1e-9 {
+			t.Fatalf("%v%%: expected %v, got %v", percent, want, got)
+		}
+	}
+
+	for _, percent := range []float64{-1, 100.01, 2100, math.NaN()} {
+		if _, err := TaxRateFromPercent(percent); !errors.Is(err, ErrInvalidTaxRate) {
+			t.Fatalf("%v%%: expected ErrInvalidTaxRate, got %v", percent, err)
+		}
+	}
+}
@@ -25,6 +25,10 @@ type RefreshToken struct {
 	ExpiresAt time.Time `json:"expiresAt"`
 	CreatedAt time.Time `json:"createdAt"`
 	User      *User     `json:"user,omitempty"`
+
+	// AccessTokenID is the jti of the access token issued with this refresh
+	// token, revoked when the session ends
+	AccessTokenID string `json:"-"`
 }
 
 // NewRefreshToken creates a new refresh token for a user. It returns the hashed
@@ -0,0 +1,26 @@
+// @kthulu:module:audit
+package repository
+
+import (
+	"context"
+
+	"github.com/pmaojo/kthulu-go/backend/internal/domain"
+)
+
+// AuditLogger records mutations performed by repositories.
+type AuditLogger interface {
+	Log(ctx context.Context, entry *domain.AuditLogEntry) error
+}
+
+type actorContextKey struct{}
+
+// ContextWithActor returns a copy of ctx carrying the ID of the user performing the request.
+func ContextWithActor(ctx context.Context, actorID uint) context.Context {
+	return context.WithValue(ctx, actorContextKey{}, actorID)
+}
+
+// ActorFromContext extracts the acting user ID stored by ContextWithActor.
+func ActorFromContext(ctx context.Context) (uint, bool) {
+	actorID, ok := ctx.Value(actorContextKey{}).(uint)
+	return actorID, ok && actorID != 0
+}
@@ -0,0 +1,55 @@
+// @kthulu:module:auth
+package repository
+
+import (
+	"context"
+
+	"github.com/pmaojo/kthulu-go/backend/internal/domain"
+)
+
+// AuthEventRepository persists authentication events for security review
+type AuthEventRepository interface {
+	Record(ctx context.Context, event *domain.AuthEvent) error
+	List(ctx context.Context, filters AuthEventFilters) ([]*domain.AuthEvent, int64, error)
+}
+
+// AuthEventFilters selects and paginates authentication events, newest first
+type AuthEventFilters struct {
+	UserID uint                 `json:"userId,omitempty"`
+	Email  string               `json:"email,omitempty"`
+	Type   domain.AuthEventType `json:"type,omitempty"`
+
+	// Pagination
+	Page     int `json:"page" validate:"min=1"`
+	PageSize int `json:"pageSize" validate:"min=1,max=100"`
+}
+
+// Validate applies the default page and page size
+func (f *AuthEventFilters) Validate() error {
+	f.Page, f.PageSize = ClampPage(f.Page, f.PageSize, DefaultPageSize)
+	return nil
+}
+
+// GetOffset returns the offset for pagination
+func (f *AuthEventFilters) GetOffset() int {
+	return (f.Page - 1) * f.PageSize
+}
+
+// ClientInfo describes the client that issued a request
+type ClientInfo struct {
+	IPAddress string
+	UserAgent string
+}
+
+type clientInfoContextKey struct{}
+
+// ContextWithClientInfo returns a copy of ctx carrying the requesting client.
+func ContextWithClientInfo(ctx context.Context, info ClientInfo) context.Context {
+	return context.WithValue(ctx, clientInfoContextKey{}, info)
+}
+
+// ClientInfoFromContext extracts the client stored by ContextWithClientInfo.
+func ClientInfoFromContext(ctx context.Context) (ClientInfo, bool) {
+	info, ok := ctx.Value(clientInfoContextKey{}).(ClientInfo)
+	return info, ok
+}
@@ -0,0 +1,31 @@
+// @kthulu:core
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrBlobNotFound is returned when no blob is stored under a key
+var ErrBlobNotFound = errors.New("blob not found")
+
+// PublicBlobPrefix starts the keys of blobs anyone may fetch at the URL Put
+// returns, such as organization logos. Stores that serve their blobs
+// themselves, like the local one, serve any other blob only through
+// SignedURL.
+const PublicBlobPrefix = "public/"
+
+// BlobStore persists files such as organization logos, invoice PDFs and
+// VeriFactu exports. Keys are slash-separated relative paths like
+// "organizations/7/logo.png".
+type BlobStore interface {
+	// Put stores content under key, replacing any existing blob, and returns
+	// the URL it is served from
+	Put(ctx context.Context, key, contentType string, content []byte) (string, error)
+	Get(ctx context.Context, key string) ([]byte, error)
+	// Delete removes key; deleting a missing blob is not an error
+	Delete(ctx context.Context, key string) error
+	// SignedURL returns a URL granting read access to key until ttl elapses
+	SignedURL(ctx context.Context, key string, ttl time.Duration) (string, error)
+}
@@ -22,6 +22,13 @@ type CalendarRepository interface {
 	GetEvent(ctx context.Context, id uint) (*domain.Event, error)
 	ListEvents(ctx context.Context, calendarID uint, page, pageSize int) ([]domain.Event, int, error)
 	GetEventsByTimeRange(ctx context.Context, calendarID uint, start, end time.Time) ([]domain.Event, error)
+	// ListEventsByOwner returns the events of every calendar owned by ownerID,
+	// or of calendarID only when it is not zero
+	ListEventsByOwner(ctx context.Context, ownerID, calendarID uint) ([]domain.Event, error)
+	// ListEventsForContact and ListEventsForInvoice return the events linked
+	// to a record of organizationID
+	ListEventsForContact(ctx context.Context, organizationID, contactID uint) ([]domain.Event, error)
+	ListEventsForInvoice(ctx context.Context, organizationID, invoiceID uint) ([]domain.Event, error)
 	UpdateEvent(ctx context.Context, event *domain.Event) error
 	DeleteEvent(ctx context.Context, id uint) error
 
@@ -34,7 +41,14 @@ type CalendarRepository interface {
 	// Reminder operations
 	CreateReminder(ctx context.Context, reminder *domain.Reminder) error
 	GetReminders(ctx context.Context, eventID uint) ([]domain.Reminder, error)
+	// GetPendingReminders returns the unsent email reminders due by before,
+	// with their event and its attendees
 	GetPendingReminders(ctx context.Context, before time.Time) ([]domain.Reminder, error)
+	// ClaimReminder marks a reminder as sent at sentAt unless it already was,
+	// reporting whether this call claimed it
+	ClaimReminder(ctx context.Context, id uint, sentAt time.Time) (bool, error)
+	// ReleaseReminder marks a claimed reminder as unsent again
+	ReleaseReminder(ctx context.Context, id uint) error
 	UpdateReminder(ctx context.Context, reminder *domain.Reminder) error
 	DeleteReminder(ctx context.Context, id uint) error
 
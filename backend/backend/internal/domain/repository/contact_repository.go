@@ -3,6 +3,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/pmaojo/kthulu-go/backend/internal/domain"
 )
@@ -16,6 +17,9 @@ type ContactRepository interface {
 	Update(ctx context.Context, contact *domain.Contact) error
 	Delete(ctx context.Context, organizationID, contactID uint) error
 	List(ctx context.Context, organizationID uint, filters ContactFilters) ([]*domain.Contact, int64, error)
+	// FindPotentialDuplicates groups the organization's contacts that share
+	// an email or phone number or have nearly the same name
+	FindPotentialDuplicates(ctx context.Context, organizationID uint) ([]domain.DuplicateGroup, error)
 
 	// Address operations
 	CreateAddress(ctx context.Context, address *domain.ContactAddress) error
@@ -40,6 +44,7 @@ type ContactRepository interface {
 
 	// Statistics
 	GetContactStats(ctx context.Context, organizationID uint) (*ContactStats, error)
+	GetContactStatsByPeriod(ctx context.Context, organizationID uint, from, to time.Time) (*ContactStatsTrend, error)
 }
 
 // ContactFilters represents filters for contact listing
@@ -49,6 +54,7 @@ type ContactFilters struct {
 	Search      string             `json:"search,omitempty"`      // Search in name, email, company
 	CreatedFrom *string            `json:"createdFrom,omitempty"` // ISO date string
 	CreatedTo   *string            `json:"createdTo,omitempty"`   // ISO date string
+	IDs         []uint             `json:"ids,omitempty"`         // Only these contacts
 
 	// Pagination
 	Page     int `json:"page" validate:"min=1"`
@@ -57,6 +63,10 @@ type ContactFilters struct {
 	// Sorting
 	SortBy    string `json:"sortBy,omitempty"`    // name, email, company_name, created_at, updated_at
 	SortOrder string `json:"sortOrder,omitempty"` // asc, desc
+
+	// IncludeRelations loads the addresses and phones of the page, one query
+	// per relation
+	IncludeRelations bool `json:"includeRelations,omitempty"`
 }
 
 // ContactStats represents contact statistics for an organization
@@ -71,6 +81,20 @@ type ContactStats struct {
 	RecentContacts   int64 `json:"recentContacts"` // Contacts created in last 30 days
 }
 
+// ContactStatsTrend holds the contacts created per month in [From, To)
+type ContactStatsTrend struct {
+	From   time.Time           `json:"from"`
+	To     time.Time           `json:"to"`
+	Months []ContactMonthStats `json:"months"`
+}
+
+// ContactMonthStats counts the contacts created in a month, by contact type
+type ContactMonthStats struct {
+	Month  string                       `json:"month"` // YYYY-MM
+	Total  int64                        `json:"total"`
+	ByType map[domain.ContactType]int64 `json:"byType"`
+}
+
 // DefaultContactFilters returns default filters for contact listing
 func DefaultContactFilters() ContactFilters {
 	active := true
@@ -85,12 +109,7 @@ func DefaultContactFilters() ContactFilters {
 
 // Validate validates the contact filters
 func (f *ContactFilters) Validate() error {
-	if f.Page < 1 {
-		f.Page = 1
-	}
-	if f.PageSize < 1 || f.PageSize > 100 {
-		f.PageSize = 20
-	}
+	f.Page, f.PageSize = ClampPage(f.Page, f.PageSize, DefaultPageSize)
 	if f.SortBy == "" {
 		f.SortBy = "created_at"
 	}
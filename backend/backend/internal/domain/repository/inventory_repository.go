@@ -3,6 +3,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/pmaojo/kthulu-go/backend/internal/domain"
 )
@@ -29,6 +30,23 @@ type InventoryRepository interface {
 	GetStockMovements(ctx context.Context, inventoryItemID uint, page, pageSize int) ([]domain.StockMovement, int, error)
 	GetStockMovementsByWarehouse(ctx context.Context, warehouseID uint, page, pageSize int) ([]domain.StockMovement, int, error)
 
+	// Inventory ledger operations
+	// AdjustStockLevel saves item and appends movement to the ledger in one
+	// transaction. The stored quantity moves by the movement's delta, or stays
+	// when movement is nil, and the reserved quantity by reservedDelta; both
+	// are loaded back into item. A delta that would take the quantity below
+	// zero, release more than is reserved or reserve more than is on hand
+	// fails with domain.ErrInsufficientStock.
+	AdjustStockLevel(ctx context.Context, item *domain.InventoryItem, movement *domain.InventoryMovement, reservedDelta int) error
+	ListMovements(ctx context.Context, productID uint, filters InventoryMovementFilters) ([]domain.InventoryMovement, int, error)
+
+	// Stock reservation operations
+	// ReserveStock holds qty of the product's available stock under ref, failing
+	// with domain.ErrInsufficientStock when not enough is available
+	ReserveStock(ctx context.Context, productID uint, qty int, ref string) error
+	// ReleaseReservation returns every quantity reserved under ref
+	ReleaseReservation(ctx context.Context, ref string) error
+
 	// Stock adjustment operations
 	CreateStockAdjustment(ctx context.Context, adjustment *domain.StockAdjustment) error
 	GetStockAdjustments(ctx context.Context, inventoryItemID uint, page, pageSize int) ([]domain.StockAdjustment, int, error)
@@ -39,3 +57,12 @@ type InventoryRepository interface {
 	GetInventoryValue(ctx context.Context, warehouseID *uint) (float64, error)
 	GetStockLevels(ctx context.Context, warehouseID *uint, productIDs []uint) ([]domain.InventoryItem, error)
 }
+
+// InventoryMovementFilters narrows the inventory ledger of a product
+type InventoryMovementFilters struct {
+	WarehouseID *uint      `json:"warehouseId,omitempty"`
+	From        *time.Time `json:"from,omitempty"` // inclusive
+	To          *time.Time `json:"to,omitempty"`   // exclusive
+	Page        int        `json:"page"`
+	PageSize    int        `json:"pageSize"` // zero returns every movement
+}
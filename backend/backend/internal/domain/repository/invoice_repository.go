@@ -3,6 +3,7 @@ package repository
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/pmaojo/kthulu-go/backend/internal/domain"
@@ -15,8 +16,19 @@ type InvoiceRepository interface {
 	GetByID(ctx context.Context, organizationID, invoiceID uint) (*domain.Invoice, error)
 	GetByNumber(ctx context.Context, organizationID uint, invoiceNumber string) (*domain.Invoice, error)
 	Update(ctx context.Context, invoice *domain.Invoice) error
+	// UpdateFields updates only the given columns of an invoice, which must
+	// be among InvoicePatchableFields
+	UpdateFields(ctx context.Context, organizationID, invoiceID uint, fields map[string]any) error
+	// UpdateWithEvent updates the invoice and stores event in the outbox atomically
+	UpdateWithEvent(ctx context.Context, invoice *domain.Invoice, event *domain.OutboxEvent) error
 	Delete(ctx context.Context, organizationID, invoiceID uint) error
 	List(ctx context.Context, organizationID uint, filters InvoiceFilters) ([]*domain.Invoice, int64, error)
+	// ListStream calls fn for every invoice matching filters, ignoring their
+	// pagination, as rows are scanned. An error from fn stops the iteration.
+	ListStream(ctx context.Context, organizationID uint, filters InvoiceFilters, fn func(*domain.Invoice) error) error
+	// ListByContacts returns a page of the invoices matching filters for each
+	// of the contacts, with their totals, in one query for all of them
+	ListByContacts(ctx context.Context, organizationID uint, contactIDs []uint, filters InvoiceFilters) (map[uint][]*domain.Invoice, map[uint]int64, error)
 	ListPaginated(ctx context.Context, organizationID uint, params PaginationParams) (PaginationResult[*domain.Invoice], error)
 	SearchPaginated(ctx context.Context, organizationID uint, query string, params PaginationParams) (PaginationResult[*domain.Invoice], error)
 
@@ -24,16 +36,19 @@ type InvoiceRepository interface {
 	CreateItem(ctx context.Context, item *domain.InvoiceItem) error
 	GetItemByID(ctx context.Context, invoiceID, itemID uint) (*domain.InvoiceItem, error)
 	GetItemsByInvoiceID(ctx context.Context, invoiceID uint) ([]*domain.InvoiceItem, error)
+	GetItemsByInvoiceIDs(ctx context.Context, invoiceIDs []uint) (map[uint][]*domain.InvoiceItem, error)
 	UpdateItem(ctx context.Context, item *domain.InvoiceItem) error
 	DeleteItem(ctx context.Context, invoiceID, itemID uint) error
 	BulkCreateItems(ctx context.Context, items []*domain.InvoiceItem) error
 	BulkUpdateItems(ctx context.Context, items []*domain.InvoiceItem) error
 	BulkDeleteItems(ctx context.Context, invoiceID uint, itemIDs []uint) error
+	ReorderItems(ctx context.Context, invoiceID uint, orderedItemIDs []uint) error
 
 	// Payment operations
 	CreatePayment(ctx context.Context, payment *domain.Payment) error
 	GetPaymentByID(ctx context.Context, organizationID, paymentID uint) (*domain.Payment, error)
 	GetPaymentsByInvoiceID(ctx context.Context, invoiceID uint) ([]*domain.Payment, error)
+	GetPaymentsByInvoiceIDs(ctx context.Context, invoiceIDs []uint) (map[uint][]*domain.Payment, error)
 	UpdatePayment(ctx context.Context, payment *domain.Payment) error
 	DeletePayment(ctx context.Context, organizationID, paymentID uint) error
 	ListPayments(ctx context.Context, organizationID uint, filters PaymentFilters) ([]*domain.Payment, int64, error)
@@ -47,16 +62,47 @@ type InvoiceRepository interface {
 	// Statistics and analytics
 	GetInvoiceStats(ctx context.Context, organizationID uint) (*InvoiceStats, error)
 	GetRevenueStats(ctx context.Context, organizationID uint, from, to time.Time) (*RevenueStats, error)
+	// GetRevenueTimeSeries totals invoices issued in [from, to) per bucket,
+	// including empty buckets
+	GetRevenueTimeSeries(ctx context.Context, organizationID uint, from, to time.Time, granularity RevenueGranularity) (*RevenueTimeSeries, error)
 	GetOverdueInvoices(ctx context.Context, organizationID uint) ([]*domain.Invoice, error)
 	GetUpcomingDueInvoices(ctx context.Context, organizationID uint, days int) ([]*domain.Invoice, error)
 
 	// Number generation
 	GenerateInvoiceNumber(ctx context.Context, organizationID uint, invoiceType domain.InvoiceType) (string, error)
+	// ReserveInvoiceNumbers atomically advances the sequence by count and
+	// returns the reserved numbers in order
+	ReserveInvoiceNumbers(ctx context.Context, organizationID uint, invoiceType domain.InvoiceType, count int) ([]string, error)
+}
+
+// InvoiceRenderer renders invoices into documents that can be sent to contacts
+type InvoiceRenderer interface {
+	RenderPDF(ctx context.Context, invoice *domain.Invoice) ([]byte, error)
+}
+
+// ExchangeRateProvider looks up the rates foreign-currency invoices default to
+type ExchangeRateProvider interface {
+	// BaseCurrency is the currency rates are quoted in
+	BaseCurrency() string
+	// Rate returns what one unit of currency was worth in the base currency
+	// on the given day
+	Rate(ctx context.Context, currency string, day time.Time) (float64, error)
+}
+
+// InvoicePatchableFields are the invoice columns UpdateFields may change
+var InvoicePatchableFields = map[string]bool{
+	"contact_id":       true,
+	"issue_date":       true,
+	"due_date":         true,
+	"payment_terms":    true,
+	"notes":            true,
+	"terms_conditions": true,
 }
 
 // InvoiceFilters represents filters for invoice listing
 type InvoiceFilters struct {
 	ContactID  *uint                 `json:"contactId,omitempty"`
+	ContactIDs []uint                `json:"contactIds,omitempty"` // Any of these contacts
 	Type       *domain.InvoiceType   `json:"type,omitempty"`
 	Status     *domain.InvoiceStatus `json:"status,omitempty"`
 	Currency   string                `json:"currency,omitempty"`
@@ -70,6 +116,10 @@ type InvoiceFilters struct {
 	IsOverdue  *bool                 `json:"isOverdue,omitempty"`
 	CreatedBy  *uint                 `json:"createdBy,omitempty"`
 
+	// PaymentState selects invoices by how much of them is paid, regardless
+	// of due date: unpaid, partial or paid
+	PaymentState *string `json:"paymentState,omitempty"`
+
 	// Pagination
 	Page     int `json:"page" validate:"min=1"`
 	PageSize int `json:"pageSize" validate:"min=1,max=100"`
@@ -84,6 +134,14 @@ type InvoiceFilters struct {
 	IncludeContact  bool `json:"includeContact,omitempty"`
 }
 
+// Payment states of InvoiceFilters.PaymentState. Invoices with nothing left
+// to pay count as paid.
+const (
+	InvoicePaymentStateUnpaid  = "unpaid"
+	InvoicePaymentStatePartial = "partial"
+	InvoicePaymentStatePaid    = "paid"
+)
+
 // PaymentFilters represents filters for payment listing
 type PaymentFilters struct {
 	InvoiceID     *uint                 `json:"invoiceId,omitempty"`
@@ -135,6 +193,64 @@ type RevenueStats struct {
 	Currency            string    `json:"currency"`
 }
 
+// RevenueGranularity is the bucket size of a revenue time series
+type RevenueGranularity string
+
+const (
+	RevenueGranularityDay   RevenueGranularity = "day"
+	RevenueGranularityWeek  RevenueGranularity = "week"
+	RevenueGranularityMonth RevenueGranularity = "month"
+)
+
+// IsValid reports whether g is a supported granularity
+func (g RevenueGranularity) IsValid() bool {
+	switch g {
+	case RevenueGranularityDay, RevenueGranularityWeek, RevenueGranularityMonth:
+		return true
+	}
+	return false
+}
+
+// Truncate returns the start of the bucket holding t. Weeks start on Monday,
+// as they do for Postgres date_trunc.
+func (g RevenueGranularity) Truncate(t time.Time) time.Time {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	switch g {
+	case RevenueGranularityWeek:
+		return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+	case RevenueGranularityMonth:
+		return day.AddDate(0, 0, 1-day.Day())
+	}
+	return day
+}
+
+// Next returns the start of the bucket following the one starting at start
+func (g RevenueGranularity) Next(start time.Time) time.Time {
+	switch g {
+	case RevenueGranularityWeek:
+		return start.AddDate(0, 0, 7)
+	case RevenueGranularityMonth:
+		return start.AddDate(0, 1, 0)
+	}
+	return start.AddDate(0, 0, 1)
+}
+
+// RevenueBucket holds the invoice totals of one time series bucket
+type RevenueBucket struct {
+	Start        time.Time `json:"start"`
+	TotalRevenue float64   `json:"totalRevenue"`
+	PaidRevenue  float64   `json:"paidRevenue"`
+	InvoiceCount int64     `json:"invoiceCount"`
+}
+
+// RevenueTimeSeries represents revenue per bucket over a period
+type RevenueTimeSeries struct {
+	Granularity RevenueGranularity `json:"granularity"`
+	From        time.Time          `json:"from"`
+	To          time.Time          `json:"to"`
+	Buckets     []RevenueBucket    `json:"buckets"`
+}
+
 // DefaultInvoiceFilters returns default filters for invoice listing
 func DefaultInvoiceFilters() InvoiceFilters {
 	return InvoiceFilters{
@@ -147,21 +263,63 @@ func DefaultInvoiceFilters() InvoiceFilters {
 
 // Validate validates the invoice filters
 func (f *InvoiceFilters) Validate() error {
-	if f.Page < 1 {
-		f.Page = 1
-	}
-	if f.PageSize < 1 || f.PageSize > 100 {
-		f.PageSize = 20
-	}
+	f.Page, f.PageSize = ClampPage(f.Page, f.PageSize, DefaultPageSize)
 	if f.SortBy == "" {
 		f.SortBy = "created_at"
 	}
 	if f.SortOrder != "asc" && f.SortOrder != "desc" {
 		f.SortOrder = "desc"
 	}
+	if f.PaymentState != nil {
+		switch *f.PaymentState {
+		case InvoicePaymentStateUnpaid, InvoicePaymentStatePartial, InvoicePaymentStatePaid:
+		default:
+			return fmt.Errorf("%w: unknown payment state %q (supported: unpaid, partial, paid)", domain.ErrInvalidInvoiceFilters, *f.PaymentState)
+		}
+	}
+	// A reversed range would silently match nothing
+	if err := validateDateRange("issuedFrom", f.IssuedFrom, "issuedTo", f.IssuedTo); err != nil {
+		return err
+	}
+	if err := validateDateRange("dueFrom", f.DueFrom, "dueTo", f.DueTo); err != nil {
+		return err
+	}
+	if f.MinAmount != nil && f.MaxAmount != nil && *f.MinAmount > *f.MaxAmount {
+		return fmt.Errorf("%w: minAmount %v is greater than maxAmount %v", domain.ErrInvalidInvoiceFilters, *f.MinAmount, *f.MaxAmount)
+	}
+	return nil
+}
+
+// validateDateRange checks that the bounds of a date range filter are ISO
+// dates or RFC 3339 timestamps and that from is not after to
+func validateDateRange(fromName string, from *string, toName string, to *string) error {
+	fromDate, err := parseFilterDate(fromName, from)
+	if err != nil {
+		return err
+	}
+	toDate, err := parseFilterDate(toName, to)
+	if err != nil {
+		return err
+	}
+	if from != nil && to != nil && fromDate.After(toDate) {
+		return fmt.Errorf("%w: %s %s is after %s %s", domain.ErrInvalidInvoiceFilters, fromName, *from, toName, *to)
+	}
 	return nil
 }
 
+func parseFilterDate(name string, value *string) (time.Time, error) {
+	if value == nil {
+		return time.Time{}, nil
+	}
+	if date, err := time.Parse("2006-01-02", *value); err == nil {
+		return date, nil
+	}
+	if date, err := time.Parse(time.RFC3339, *value); err == nil {
+		return date, nil
+	}
+	return time.Time{}, fmt.Errorf("%w: %s %q is not an ISO date", domain.ErrInvalidInvoiceFilters, name, *value)
+}
+
 // GetOffset returns the offset for pagination
 func (f *InvoiceFilters) GetOffset() int {
 	return (f.Page - 1) * f.PageSize
@@ -179,12 +337,7 @@ func DefaultPaymentFilters() PaymentFilters {
 
 // Validate validates the payment filters
 func (f *PaymentFilters) Validate() error {
-	if f.Page < 1 {
-		f.Page = 1
-	}
-	if f.PageSize < 1 || f.PageSize > 100 {
-		f.PageSize = 20
-	}
+	f.Page, f.PageSize = ClampPage(f.Page, f.PageSize, DefaultPageSize)
 	if f.SortBy == "" {
 		f.SortBy = "payment_date"
 	}
@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/pmaojo/kthulu-go/backend/internal/domain"
+)
+
+func TestInvoiceFiltersValidate_Ranges(t *testing.T) {
+	date := func(value string) *string { return &value }
+	amount := func(value float64) *float64 { return &value }
+
+	for name, tc := range map[string]struct {
+		filters InvoiceFilters
+		valid   bool
+	}{
+		"ordered dates":        {InvoiceFilters{IssuedFrom: date("2024-01-01"), IssuedTo: date("2024-01-31"), DueFrom: date("2024-02-01"), DueTo: date("2024-02-01")}, true},
+		"open ended dates":     {InvoiceFilters{IssuedFrom: date("2024-01-01"), DueTo: date("2024-02-01T10:00:00Z")}, true},
+		"same day timestamps":  {InvoiceFilters{IssuedFrom: date("2024-01-01"), IssuedTo: date("2024-01-01T23:59:59Z")}, true},
+		"reversed issue dates": {InvoiceFilters{IssuedFrom: date("2024-02-01"), IssuedTo: date("2024-01-01")}, false},
+		"reversed due dates":   {InvoiceFilters{DueFrom: date("2024-03-01T00:00:00Z"), DueTo: date("2024-02-28")}, false},
+		"malformed date":       {InvoiceFilters{IssuedFrom: date("01/02/2024")}, false},
+		"ordered amounts":      {InvoiceFilters{MinAmount: amount(10), MaxAmount: amount(10)}, true},
+		"open ended amount":    {InvoiceFilters{MinAmount: amount(100)}, true},
+		"reversed amounts":     {InvoiceFilters{MinAmount: amount(100), MaxAmount: amount(10)}, false},
+	} {
+		err := tc.filters.Validate()
+		if tc.valid && err != nil {
+			t.Fatalf("%s: unexpected error: %v", name, err)
+		}
+		if !tc.valid && !errors.Is(err, domain.ErrInvalidInvoiceFilters) {
+			t.Fatalf("%s: expected ErrInvalidInvoiceFilters, got %v", name, err)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package repository
 
 import (
 	"context"
+	"time"
 )
 
 // NotificationRequest represents a notification to be sent
@@ -12,6 +13,17 @@ type NotificationRequest struct {
 	Body    string                 `json:"body"`
 	Type    NotificationType       `json:"type"`
 	Data    map[string]interface{} `json:"data,omitempty"`
+
+	// TextBody is an optional plain-text alternative to Body
+	TextBody    string                   `json:"textBody,omitempty"`
+	Attachments []NotificationAttachment `json:"attachments,omitempty"`
+}
+
+// NotificationAttachment is a file sent along with an email notification
+type NotificationAttachment struct {
+	Filename    string `json:"filename"`
+	ContentType string `json:"contentType"`
+	Content     []byte `json:"-"`
 }
 
 // NotificationType represents the type of notification
@@ -23,6 +35,8 @@ const (
 	NotificationTypePasswordReset     NotificationType = "password_reset"
 	NotificationTypeWelcome           NotificationType = "welcome"
 	NotificationTypeInvitation        NotificationType = "invitation"
+	NotificationTypeInvoice           NotificationType = "invoice"
+	NotificationTypeEventReminder     NotificationType = "event_reminder"
 )
 
 // NotificationProvider defines the interface for sending notifications
@@ -31,4 +45,71 @@ type NotificationProvider interface {
 	SendEmailConfirmation(ctx context.Context, email, confirmationCode string) error
 	SendPasswordReset(ctx context.Context, email, resetCode string) error
 	SendWelcomeEmail(ctx context.Context, email, name string) error
+	SendInvoiceEmail(ctx context.Context, email, invoiceNumber string, attachment NotificationAttachment) error
+}
+
+// NotificationScope carries the organization details used to personalize
+// notification emails
+type NotificationScope struct {
+	OrganizationID   uint
+	OrganizationName string
+	CodeExpiresAt    *time.Time
+}
+
+type notificationScopeKey struct{}
+
+// ContextWithNotificationScope returns a copy of ctx carrying scope for the
+// notifications sent with it
+func ContextWithNotificationScope(ctx context.Context, scope NotificationScope) context.Context {
+	return context.WithValue(ctx, notificationScopeKey{}, scope)
+}
+
+// NotificationScopeFromContext extracts the scope stored by ContextWithNotificationScope
+func NotificationScopeFromContext(ctx context.Context) (NotificationScope, bool) {
+	scope, ok := ctx.Value(notificationScopeKey{}).(NotificationScope)
+	return scope, ok
+}
+
+// EmailTemplate holds the sources of an email template. Subject and Text are
+// text/template sources, HTML is an html/template source.
+type EmailTemplate struct {
+	Subject string `json:"subject"`
+	HTML    string `json:"html"`
+	Text    string `json:"text"`
+}
+
+// EmailTemplateRepository stores the email templates organizations use in
+// place of the built-in ones
+type EmailTemplateRepository interface {
+	// FindByOrganization returns nil when the organization keeps the
+	// built-in template for notificationType
+	FindByOrganization(ctx context.Context, organizationID uint, notificationType NotificationType) (*EmailTemplate, error)
+	Save(ctx context.Context, organizationID uint, notificationType NotificationType, tmpl EmailTemplate) error
+}
+
+// OrganizationEmailTemplates validates and stores organization email templates
+type OrganizationEmailTemplates interface {
+	SetOrganizationTemplate(ctx context.Context, organizationID uint, notificationType NotificationType, tmpl EmailTemplate) error
+}
+
+// UserNotificationType identifies what a user notification is about
+type UserNotificationType string
+
+const (
+	UserNotificationInvoiceSent        UserNotificationType = "invoice.sent"
+	UserNotificationPaymentReceived    UserNotificationType = "payment.received"
+	UserNotificationInvitationAccepted UserNotificationType = "invitation.accepted"
+)
+
+// UserNotification is an in-app notification addressed to a single user
+type UserNotification struct {
+	Type   UserNotificationType   `json:"type"`
+	Data   map[string]interface{} `json:"data,omitempty"`
+	SentAt time.Time              `json:"sentAt"`
+}
+
+// UserNotifier pushes notifications to the sessions a user has open. Users
+// who aren't connected miss them.
+type UserNotifier interface {
+	NotifyUser(ctx context.Context, userID uint, notification UserNotification) error
 }
@@ -16,6 +16,10 @@ type OrganizationRepository interface {
 	FindBySlug(ctx context.Context, slug string) (*domain.Organization, error)
 	Update(ctx context.Context, org *domain.Organization) error
 	Delete(ctx context.Context, id uint) error
+	// DeleteWithCascade deletes an inactive organization together with its
+	// members, invitations and business data. Unless force is set it refuses
+	// organizations that still have contacts, products or invoices.
+	DeleteWithCascade(ctx context.Context, id uint, force bool) error
 
 	// Query operations
 	List(ctx context.Context, limit, offset int) ([]*domain.Organization, error)
@@ -43,6 +47,9 @@ type OrganizationUserRepository interface {
 	FindByUser(ctx context.Context, userID uint) ([]*domain.OrganizationUser, error)
 	FindByRole(ctx context.Context, organizationID uint, role domain.OrganizationRole) ([]*domain.OrganizationUser, error)
 	CountByOrganization(ctx context.Context, organizationID uint) (int64, error)
+	// ListMembers returns a page of the organization's users with their
+	// roles, oldest members first, and the total number of matches
+	ListMembers(ctx context.Context, organizationID uint, filters MemberFilters) ([]*domain.OrganizationMember, int64, error)
 
 	// Permission checks
 	IsUserInOrganization(ctx context.Context, organizationID, userID uint) (bool, error)
@@ -54,6 +61,27 @@ type OrganizationUserRepository interface {
 	UpdateUserRole(ctx context.Context, organizationID, userID uint, role domain.OrganizationRole) error
 }
 
+// MemberFilters selects and paginates organization members
+type MemberFilters struct {
+	Role   domain.OrganizationRole `json:"role,omitempty"`
+	Search string                  `json:"search,omitempty"` // Search in email
+
+	// Pagination
+	Page     int `json:"page" validate:"min=1"`
+	PageSize int `json:"pageSize" validate:"min=1,max=100"`
+}
+
+// Validate applies the default page and page size
+func (f *MemberFilters) Validate() error {
+	f.Page, f.PageSize = ClampPage(f.Page, f.PageSize, DefaultPageSize)
+	return nil
+}
+
+// GetOffset returns the offset for pagination
+func (f *MemberFilters) GetOffset() int {
+	return (f.Page - 1) * f.PageSize
+}
+
 // InvitationRepository defines behavior for invitation persistence.
 type InvitationRepository interface {
 	// Basic CRUD operations
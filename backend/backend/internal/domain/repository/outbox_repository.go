@@ -0,0 +1,25 @@
+// @kthulu:module:webhooks
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/pmaojo/kthulu-go/backend/internal/domain"
+)
+
+// OutboxRepository stores events in the transaction of their source and
+// gives the outbox relay access to events that were committed but not yet
+// published.
+type OutboxRepository interface {
+	Append(ctx context.Context, event *domain.OutboxEvent) error
+	// ClaimPending leases up to limit events that are due for an attempt,
+	// so other relays skip them until the lease expires
+	ClaimPending(ctx context.Context, limit int, lease time.Duration) ([]*domain.OutboxEvent, error)
+	MarkDelivered(ctx context.Context, id uint) error
+	// MarkFailed records a failed attempt and schedules the next one at retryAt
+	MarkFailed(ctx context.Context, id uint, reason string, retryAt time.Time) error
+	// MarkDeadLettered records the last failed attempt and stops relaying
+	// the event
+	MarkDeadLettered(ctx context.Context, id uint, reason string) error
+}
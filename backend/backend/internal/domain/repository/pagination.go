@@ -0,0 +1,36 @@
+// @kthulu:core
+package repository
+
+const (
+	// DefaultPageSize is the page size of lists that don't ask for one
+	DefaultPageSize = 20
+	// MaxPageSize is the largest page any list returns
+	MaxPageSize = 100
+)
+
+// ClampPage brings a requested page and page size into range. Pages start at
+// 1, a page size below 1 falls back to defaultSize and one above MaxPageSize
+// is capped at it.
+func ClampPage(page, pageSize, defaultSize int) (int, int) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = defaultSize
+	}
+	if pageSize > MaxPageSize {
+		pageSize = MaxPageSize
+	}
+	return page, pageSize
+}
+
+// ClampOffset brings a requested limit and offset into range. Offsets can't
+// be negative, a limit below 1 falls back to defaultLimit and one above
+// MaxPageSize is capped at it.
+func ClampOffset(limit, offset, defaultLimit int) (int, int) {
+	if offset < 0 {
+		offset = 0
+	}
+	_, limit = ClampPage(1, limit, defaultLimit)
+	return limit, offset
+}
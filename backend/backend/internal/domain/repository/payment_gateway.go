@@ -0,0 +1,65 @@
+// @kthulu:module:payments
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/pmaojo/kthulu-go/backend/internal/domain"
+)
+
+// PaymentIntentRequest asks a gateway to collect an amount for an invoice
+type PaymentIntentRequest struct {
+	OrganizationID uint
+	InvoiceID      uint
+	InvoiceNumber  string
+	Amount         float64
+	Currency       string
+}
+
+// PaymentIntent is a gateway's pending collection of an invoice amount. The
+// client secret is handed to the payer's browser to complete the payment.
+type PaymentIntent struct {
+	ID           string  `json:"id"`
+	ClientSecret string  `json:"clientSecret"`
+	Amount       float64 `json:"amount"`
+	Currency     string  `json:"currency"`
+}
+
+// PaymentEvent is a payment a gateway reports as collected
+type PaymentEvent struct {
+	IntentID       string
+	OrganizationID uint
+	InvoiceID      uint
+	Amount         float64
+	Currency       string
+	PaidAt         time.Time
+}
+
+// PaymentWebhook is a verified gateway webhook delivery. Payment is nil for
+// events that aren't collected payments.
+type PaymentWebhook struct {
+	// EventID identifies the event across redeliveries
+	EventID string
+	Payment *PaymentEvent
+}
+
+// PaymentGateway collects invoice payments online
+type PaymentGateway interface {
+	// Method is the payment method recorded for collected payments
+	Method() domain.PaymentMethod
+	CreatePaymentIntent(ctx context.Context, req PaymentIntentRequest) (*PaymentIntent, error)
+	// ParseWebhook verifies a webhook delivery and returns the event it
+	// reports. Deliveries that fail verification are rejected with
+	// domain.ErrInvalidPaymentWebhook.
+	ParseWebhook(payload []byte, headers map[string][]string) (*PaymentWebhook, error)
+}
+
+// PaymentWebhookEventRepository remembers the gateway events already
+// processed so redelivered webhooks are skipped
+type PaymentWebhookEventRepository interface {
+	// Claim records the event unless it already was, reporting whether this
+	// call claimed it. Inside a unit of work the claim is undone when the
+	// unit of work rolls back.
+	Claim(ctx context.Context, gateway domain.PaymentMethod, eventID string) (bool, error)
+}
@@ -14,17 +14,34 @@ type ProductRepository interface {
 	Create(ctx context.Context, product *domain.Product) error
 	GetByID(ctx context.Context, organizationID, productID uint) (*domain.Product, error)
 	GetBySKU(ctx context.Context, organizationID uint, sku string) (*domain.Product, error)
+	GetByBarcode(ctx context.Context, organizationID uint, barcode string) (*domain.Product, error)
 	Update(ctx context.Context, product *domain.Product) error
 	Delete(ctx context.Context, organizationID, productID uint) error
 	List(ctx context.Context, organizationID uint, filters ProductFilters) ([]*domain.Product, int64, error)
+	// ListStream calls fn for every product matching filters, ignoring their
+	// pagination, as rows are scanned. An error from fn stops the iteration.
+	ListStream(ctx context.Context, organizationID uint, filters ProductFilters, fn func(*domain.Product) error) error
 	ListPaginated(ctx context.Context, organizationID uint, params PaginationParams) (PaginationResult[*domain.Product], error)
 	SearchPaginated(ctx context.Context, organizationID uint, query string, params PaginationParams) (PaginationResult[*domain.Product], error)
+	// Clone copies a product with its variants and prices under newSKU,
+	// failing with domain.ErrProductAlreadyExists when newSKU is taken
+	Clone(ctx context.Context, organizationID, productID uint, newSKU string) (*domain.Product, error)
+
+	// Category operations
+	// CreateCategory fails with domain.ErrProductCategoryNotFound when the
+	// parent is not a category of the same organization
+	CreateCategory(ctx context.Context, category *domain.ProductCategory) error
+	GetCategoryByID(ctx context.Context, organizationID, categoryID uint) (*domain.ProductCategory, error)
+	// ListCategoryTree returns the root categories with their children
+	ListCategoryTree(ctx context.Context, organizationID uint) ([]*domain.ProductCategory, error)
 
 	// Variant operations
 	CreateVariant(ctx context.Context, variant *domain.ProductVariant) error
 	GetVariantByID(ctx context.Context, productID, variantID uint) (*domain.ProductVariant, error)
 	GetVariantBySKU(ctx context.Context, sku string) (*domain.ProductVariant, error)
+	GetVariantByBarcode(ctx context.Context, organizationID uint, barcode string) (*domain.ProductVariant, error)
 	GetVariantsByProductID(ctx context.Context, productID uint) ([]*domain.ProductVariant, error)
+	GetVariantsByProductIDs(ctx context.Context, productIDs []uint) (map[uint][]*domain.ProductVariant, error)
 	UpdateVariant(ctx context.Context, variant *domain.ProductVariant) error
 	DeleteVariant(ctx context.Context, productID, variantID uint) error
 
@@ -32,25 +49,34 @@ type ProductRepository interface {
 	CreatePrice(ctx context.Context, price *domain.ProductPrice) error
 	GetPriceByID(ctx context.Context, priceID uint) (*domain.ProductPrice, error)
 	GetPricesByProductID(ctx context.Context, productID uint) ([]*domain.ProductPrice, error)
+	GetPricesByProductIDs(ctx context.Context, productIDs []uint) (map[uint][]*domain.ProductPrice, error)
 	GetPricesByVariantID(ctx context.Context, variantID uint) ([]*domain.ProductPrice, error)
 	GetEffectivePrice(ctx context.Context, productID *uint, variantID *uint, priceType domain.PriceType, quantity int, at time.Time) (*domain.ProductPrice, error)
 	UpdatePrice(ctx context.Context, price *domain.ProductPrice) error
 	DeletePrice(ctx context.Context, priceID uint) error
+	GetPriceHistory(ctx context.Context, productID uint) ([]*domain.ProductPrice, error)
 
 	// Bulk operations
 	BulkCreate(ctx context.Context, products []*domain.Product) error
 	BulkUpdate(ctx context.Context, products []*domain.Product) error
 	BulkDelete(ctx context.Context, organizationID uint, productIDs []uint) error
+	// BulkUpdateTaxRate sets the tax rate, a fraction like Product.TaxRate, of
+	// several products at once
+	BulkUpdateTaxRate(ctx context.Context, organizationID uint, productIDs []uint, taxRate float64) error
 
 	// Statistics and analytics
 	GetProductStats(ctx context.Context, organizationID uint) (*ProductStats, error)
 	GetCategoriesWithCounts(ctx context.Context, organizationID uint) ([]CategoryCount, error)
 	GetBrandsWithCounts(ctx context.Context, organizationID uint) ([]BrandCount, error)
+	// GetTopProducts ranks products by the quantity sold on invoices issued
+	// in [from, to), ignoring drafts and canceled invoices
+	GetTopProducts(ctx context.Context, organizationID uint, from, to time.Time, limit int) ([]ProductSales, error)
 }
 
 // ProductFilters represents filters for product listing
 type ProductFilters struct {
 	Category    string  `json:"category,omitempty"`
+	CategoryID  *uint   `json:"categoryId,omitempty"` // Includes descendant categories
 	Brand       string  `json:"brand,omitempty"`
 	IsActive    *bool   `json:"isActive,omitempty"`
 	IsTrackable *bool   `json:"isTrackable,omitempty"`
@@ -103,6 +129,15 @@ type BrandCount struct {
 	Count int64  `json:"count"`
 }
 
+// ProductSales represents how much of a product was invoiced over a period
+type ProductSales struct {
+	ProductID    uint    `json:"productId"`
+	SKU          string  `json:"sku"`
+	Name         string  `json:"name"`
+	QuantitySold float64 `json:"quantitySold"`
+	Revenue      float64 `json:"revenue"`
+}
+
 // DefaultProductFilters returns default filters for product listing
 func DefaultProductFilters() ProductFilters {
 	active := true
@@ -118,12 +153,7 @@ func DefaultProductFilters() ProductFilters {
 
 // Validate validates the product filters
 func (f *ProductFilters) Validate() error {
-	if f.Page < 1 {
-		f.Page = 1
-	}
-	if f.PageSize < 1 || f.PageSize > 100 {
-		f.PageSize = 20
-	}
+	f.Page, f.PageSize = ClampPage(f.Page, f.PageSize, DefaultPageSize)
 	if f.SortBy == "" {
 		f.SortBy = "created_at"
 	}
@@ -5,7 +5,7 @@ import (
 	"fmt"
 	"sync"
 
-	"nhooyr.io/websocket"
+	"github.com/gorilla/websocket"
 )
 
 // ConnectionRepository manages active WebSocket connections.
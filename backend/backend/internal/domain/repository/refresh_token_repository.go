@@ -24,7 +24,7 @@ type RefreshTokenRepository interface {
 	CountByUserID(ctx context.Context, userID uint) (int64, error)
 
 	// Cleanup operations
-	DeleteExpired(ctx context.Context) (int64, error)
+	DeleteExpired(ctx context.Context, before time.Time) (int64, error)
 	DeleteOlderThan(ctx context.Context, cutoff time.Time) (int64, error)
 
 	// Query operations
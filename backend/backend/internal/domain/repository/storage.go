@@ -46,6 +46,36 @@ type TokenStorage interface {
 	CleanupExpiredTokens(ctx context.Context) (int, error)
 }
 
+// AccessTokenDenylist records access tokens revoked before they expire, keyed
+// by their jti claim. Entries only need to outlive the token itself.
+type AccessTokenDenylist interface {
+	Revoke(ctx context.Context, jti string, expiresAt time.Time) error
+	IsRevoked(ctx context.Context, jti string) (bool, error)
+}
+
+// IsAccessTokenRevoked reports whether the access token carrying claims was
+// revoked. A nil denylist or a token without a jti is never revoked.
+func IsAccessTokenRevoked(ctx context.Context, denylist AccessTokenDenylist, claims map[string]interface{}) (bool, error) {
+	jti, ok := claims["jti"].(string)
+	if denylist == nil || !ok {
+		return false, nil
+	}
+	return denylist.IsRevoked(ctx, jti)
+}
+
+// Cache holds state shared by every instance of the service, such as
+// idempotency keys and cached reads. Entries expire after their ttl.
+type Cache interface {
+	// Get returns the value stored under key, and false on a miss
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Del(ctx context.Context, keys ...string) error
+	// Incr adds one to the counter under key and returns the new count. A
+	// counter created by Incr expires after ttl; later increments keep its
+	// expiry.
+	Incr(ctx context.Context, key string, ttl time.Duration) (int64, error)
+}
+
 // CacheStorage defines caching-specific operations
 type CacheStorage interface {
 	Storage
@@ -79,12 +109,7 @@ type PaginationResult[T any] struct {
 
 // NewPaginationParams creates pagination parameters with defaults
 func NewPaginationParams(page, pageSize int, sortBy, sortDir string) PaginationParams {
-	if page < 1 {
-		page = 1
-	}
-	if pageSize < 1 || pageSize > 100 {
-		pageSize = 20
-	}
+	page, pageSize = ClampPage(page, pageSize, DefaultPageSize)
 	if sortDir != "asc" && sortDir != "desc" {
 		sortDir = "asc"
 	}
@@ -97,6 +122,13 @@ func NewPaginationParams(page, pageSize int, sortBy, sortDir string) PaginationP
 	}
 }
 
+// Clamped returns the params with their page and page size brought into
+// range, keeping the sort
+func (p PaginationParams) Clamped() PaginationParams {
+	p.Page, p.PageSize = ClampPage(p.Page, p.PageSize, DefaultPageSize)
+	return p
+}
+
 // CalculateOffset calculates the SQL offset for pagination
 func (p PaginationParams) CalculateOffset() int {
 	return (p.Page - 1) * p.PageSize
@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type stubDenylist struct {
+	revoked map[string]bool
+	err     error
+}
+
+func (d stubDenylist) Revoke(context.Context, string, time.Time) error { return nil }
+
+func (d stubDenylist) IsRevoked(_ context.Context, jti string) (bool, error) {
+	return d.revoked[jti], d.err
+}
+
+func TestIsAccessTokenRevoked(t *testing.T) {
+	ctx := context.Background()
+	denylist := stubDenylist{revoked: map[string]bool{"revoked": true}}
+
+	for name, tc := range map[string]struct {
+		denylist AccessTokenDenylist
+		claims   map[string]interface{}
+		revoked  bool
+	}{
+		"revoked jti":    {denylist, map[string]interface{}{"jti": "revoked"}, true},
+		"active jti":     {denylist, map[string]interface{}{"jti": "active"}, false},
+		"no jti":         {denylist, map[string]interface{}{"sub": float64(1)}, false},
+		"no denylist":    {nil, map[string]interface{}{"jti": "revoked"}, false},
+		"non-string jti": {denylist, map[string]interface{}{"jti": 7}, false},
+	} {
+		revoked, err := IsAccessTokenRevoked(ctx, tc.denylist, tc.claims)
+		if err != nil || revoked != tc.revoked {
+			t.Fatalf("%s: expected revoked=%v, got %v (%v)", name, tc.revoked, revoked, err)
+		}
+	}
+
+	failing := stubDenylist{err: errors.New("store down")}
+	if _, err := IsAccessTokenRevoked(ctx, failing, map[string]interface{}{"jti": "any"}); err == nil {
+		t.Fatalf("expected the denylist error")
+	}
+}
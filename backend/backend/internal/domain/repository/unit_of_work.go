@@ -0,0 +1,29 @@
+// @kthulu:core
+package repository
+
+import "context"
+
+// TxRepositories are the repositories bound to one unit of work. Everything
+// written through them commits or rolls back together, whether the
+// repository is backed by SQL or by GORM.
+type TxRepositories struct {
+	Invoices  InvoiceRepository
+	Products  ProductRepository
+	Contacts  ContactRepository
+	Inventory InventoryRepository
+	Outbox    OutboxRepository
+	// Organizations lets organization changes commit with the records of
+	// other modules, such as a new organization and its first invoice
+	Organizations OrganizationRepository
+	// PaymentWebhookEvents claims gateway events together with the payment
+	// they record
+	PaymentWebhookEvents PaymentWebhookEventRepository
+}
+
+// UnitOfWork runs use case steps that span several repositories inside a
+// single database transaction.
+type UnitOfWork interface {
+	// Do begins a transaction and calls fn with repositories bound to it. The
+	// transaction commits when fn returns nil and rolls back otherwise.
+	Do(ctx context.Context, fn func(repos TxRepositories) error) error
+}
@@ -0,0 +1,24 @@
+// @kthulu:module:webhooks
+package repository
+
+import (
+	"context"
+
+	"github.com/pmaojo/kthulu-go/backend/internal/domain"
+)
+
+// WebhookRepository stores the webhook endpoints of each organization and
+// the delivery attempts made to them.
+type WebhookRepository interface {
+	CreateEndpoint(ctx context.Context, endpoint *domain.WebhookEndpoint) error
+	ListEndpoints(ctx context.Context, organizationID uint, event domain.WebhookEvent) ([]*domain.WebhookEndpoint, error)
+	RecordDelivery(ctx context.Context, delivery *domain.WebhookDelivery) error
+	ListDeliveries(ctx context.Context, endpointID uint) ([]*domain.WebhookDelivery, error)
+}
+
+// WebhookDeliverer delivers an event to the webhooks of an organization and
+// returns once every subscribed endpoint acknowledged it, or with an error
+// when one did not
+type WebhookDeliverer interface {
+	Deliver(ctx context.Context, organizationID uint, event domain.WebhookEvent, data interface{}) error
+}
@@ -0,0 +1,81 @@
+// @kthulu:module:invoices
+package domain
+
+import "strings"
+
+// TaxSale describes an invoice line for tax purposes. Countries are ISO
+// 3166-1 alpha-2 codes.
+type TaxSale struct {
+	SellerCountry string
+	BuyerCountry  string
+	// BuyerTaxNumber is the VAT number of business buyers, empty for
+	// consumers
+	BuyerTaxNumber  string
+	ProductCategory string
+	// Rate is the tax rate given on the line
+	Rate float64
+}
+
+// TaxTreatment is how an invoice line is taxed
+type TaxTreatment struct {
+	Rate float64
+	// ReverseCharge means the buyer accounts for the tax, so none is charged
+	ReverseCharge bool
+}
+
+// TaxCalculator determines the tax treatment of invoice lines
+type TaxCalculator interface {
+	Calculate(sale TaxSale) TaxTreatment
+}
+
+// FlatRateTaxCalculator charges every line the tax rate given on it
+type FlatRateTaxCalculator struct{}
+
+// Calculate returns the line's own rate
+func (FlatRateTaxCalculator) Calculate(sale TaxSale) TaxTreatment {
+	return TaxTreatment{Rate: sale.Rate}
+}
+
+// euMemberStates are the ISO codes of the EU VAT area
+var euMemberStates = map[string]bool{
+	"AT": true, "BE": true, "BG": true, "CY": true, "CZ": true, "DE": true,
+	"DK": true, "EE": true, "ES": true, "FI": true, "FR": true,
+	"GR": true, "HR": true, "HU": true, "IE": true, "IT": true, "LT": true,
+	"LU": true, "LV": true, "MT": true, "NL": true, "PL": true, "PT": true,
+	"RO": true, "SE": true, "SI": true, "SK": true,
+}
+
+// EUVATCalculator applies the EU VAT rules: sales to businesses registered
+// in another member state are reverse-charged, and domestic sales of product
+// categories listed in CategoryRates are charged that rate instead of the
+// line's. Any other sale is charged the line's rate.
+type EUVATCalculator struct {
+	// CategoryRates maps lowercase product categories to reduced rates
+	CategoryRates map[string]float64
+}
+
+// Calculate returns the treatment of sale under the EU VAT rules
+func (c EUVATCalculator) Calculate(sale TaxSale) TaxTreatment {
+	seller := normalizeCountry(sale.SellerCountry)
+	buyer := normalizeCountry(sale.BuyerCountry)
+
+	if euMemberStates[seller] && euMemberStates[buyer] && seller != buyer && strings.TrimSpace(sale.BuyerTaxNumber) != "" {
+		return TaxTreatment{Rate: 0, ReverseCharge: true}
+	}
+	if buyer == "" || buyer == seller {
+		if rate, ok := c.CategoryRates[strings.ToLower(strings.TrimSpace(sale.ProductCategory))]; ok {
+			return TaxTreatment{Rate: rate}
+		}
+	}
+	return TaxTreatment{Rate: sale.Rate}
+}
+
+// normalizeCountry upper-cases an ISO country code, mapping Greece's VAT
+// prefix to its ISO code
+func normalizeCountry(country string) string {
+	country = strings.ToUpper(strings.TrimSpace(country))
+	if country == "EL" {
+		return "GR"
+	}
+	return country
+}
@@ -0,0 +1,46 @@
+package domain
+
+import "testing"
+
+func TestEUVATCalculator(t *testing.T) {
+	calculator := EUVATCalculator{CategoryRates: map[string]float64{"books": 0.04}}
+
+	for name, tc := range map[string]struct {
+		sale TaxSale
+		want TaxTreatment
+	}{
+		"domestic sale": {
+			TaxSale{SellerCountry: "ES", BuyerCountry: "ES", BuyerTaxNumber: "B12345678", Rate: 0.21},
+			TaxTreatment{Rate: 0.21},
+		},
+		"domestic reduced category": {
+			TaxSale{SellerCountry: "ES", BuyerCountry: "es", ProductCategory: "Books", Rate: 0.21},
+			TaxTreatment{Rate: 0.04},
+		},
+		"EU business buyer": {
+			TaxSale{SellerCountry: "ES", BuyerCountry: "FR", BuyerTaxNumber: "FR40303265045", ProductCategory: "books", Rate: 0.21},
+			TaxTreatment{Rate: 0, ReverseCharge: true},
+		},
+		"Greek VAT prefix": {
+			TaxSale{SellerCountry: "DE", BuyerCountry: "EL", BuyerTaxNumber: "EL094259216", Rate: 0.19},
+			TaxTreatment{Rate: 0, ReverseCharge: true},
+		},
+		"EU consumer": {
+			TaxSale{SellerCountry: "ES", BuyerCountry: "FR", Rate: 0.21},
+			TaxTreatment{Rate: 0.21},
+		},
+		"non-EU business buyer": {
+			TaxSale{SellerCountry: "ES", BuyerCountry: "US", BuyerTaxNumber: "12-3456789", Rate: 0.21},
+			TaxTreatment{Rate: 0.21},
+		},
+	} {
+		if got := calculator.Calculate(tc.sale); got != tc.want {
+			t.Errorf("%s: expected %+v, got %+v", name, tc.want, got)
+		}
+	}
+
+	sale := TaxSale{SellerCountry: "ES", BuyerCountry: "FR", BuyerTaxNumber: "FR40303265045", Rate: 0.21}
+	if got := (FlatRateTaxCalculator{}).Calculate(sale); got != (TaxTreatment{Rate: 0.21}) {
+		t.Errorf("expected the flat calculator to keep the line rate, got %+v", got)
+	}
+}
@@ -19,7 +19,10 @@ var (
 	ErrUserNotFound      = errors.New("user not found")
 	ErrUserAlreadyExists = errors.New("user already exists")
 	ErrUserNotConfirmed  = errors.New("user email not confirmed")
+	ErrUserDisabled      = errors.New("user account is disabled")
 	ErrInvalidRole       = errors.New("invalid role")
+
+	ErrConfirmationCodeExpired = errors.New("confirmation code expired")
 )
 
 // User represents a system user with rich domain behavior.
@@ -29,10 +32,14 @@ type User struct {
 	PasswordHash     string     `json:"-"`
 	ConfirmedAt      *time.Time `json:"confirmedAt,omitempty"`
 	ConfirmationCode string     `json:"-"`
-	RoleID           uint       `json:"roleId"`
-	Role             *Role      `json:"role,omitempty"`
-	CreatedAt        time.Time  `json:"createdAt"`
-	UpdatedAt        time.Time  `json:"updatedAt"`
+	// ConfirmationExpiresAt is when ConfirmationCode stops being accepted;
+	// codes issued before expiry was tracked have none
+	ConfirmationExpiresAt *time.Time `json:"-"`
+	DisabledAt            *time.Time `json:"disabledAt,omitempty"`
+	RoleID                uint       `json:"roleId"`
+	Role                  *Role      `json:"role,omitempty"`
+	CreatedAt             time.Time  `json:"createdAt"`
+	UpdatedAt             time.Time  `json:"updatedAt"`
 }
 
 // Email is a value object for email addresses
@@ -150,9 +157,22 @@ func (u *User) Confirm() {
 	now := time.Now()
 	u.ConfirmedAt = &now
 	u.ConfirmationCode = ""
+	u.ConfirmationExpiresAt = nil
 	u.UpdatedAt = now
 }
 
+// SetConfirmationCode issues a new confirmation code accepted for ttl
+func (u *User) SetConfirmationCode(code string, ttl time.Duration) {
+	expiresAt := time.Now().Add(ttl)
+	u.ConfirmationCode = code
+	u.ConfirmationExpiresAt = &expiresAt
+}
+
+// ConfirmationExpired reports whether the confirmation code is past its expiry
+func (u *User) ConfirmationExpired() bool {
+	return u.ConfirmationExpiresAt != nil && time.Now().After(*u.ConfirmationExpiresAt)
+}
+
 // UpdateEmail updates the user's email and marks as unconfirmed
 func (u *User) UpdateEmail(newEmail string) error {
 	emailVO, err := NewEmail(newEmail)
@@ -191,9 +211,47 @@ func (u *User) UpdateRole(roleID uint) error {
 	return nil
 }
 
-// CanLogin returns true if the user can log in (must be confirmed)
+// IsDisabled returns true if the account has been disabled
+func (u *User) IsDisabled() bool {
+	return u.DisabledAt != nil
+}
+
+// Disable stops the user from logging in while keeping the account
+func (u *User) Disable() {
+	now := time.Now()
+	u.DisabledAt = &now
+	u.UpdatedAt = now
+}
+
+// Enable lets a disabled user log in again
+func (u *User) Enable() {
+	u.DisabledAt = nil
+	u.UpdatedAt = time.Now()
+}
+
+// CanLogin returns true if the user can log in (must be confirmed and not
+// disabled)
 func (u *User) CanLogin() bool {
-	return u.IsConfirmed()
+	return u.IsConfirmed() && !u.IsDisabled()
+}
+
+// LoginError returns why CanLogin is false, or nil when the user can log in
+func (u *User) LoginError() error {
+	if u.IsDisabled() {
+		return ErrUserDisabled
+	}
+	if !u.IsConfirmed() {
+		return ErrUserNotConfirmed
+	}
+	return nil
+}
+
+// RoleName returns the name of the loaded role, or "" when it is not loaded
+func (u *User) RoleName() string {
+	if u.Role == nil {
+		return ""
+	}
+	return u.Role.Name
 }
 
 // GetDisplayName returns a display name for the user (email for now)
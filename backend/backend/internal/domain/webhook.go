@@ -0,0 +1,97 @@
+// @kthulu:module:webhooks
+package domain
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// WebhookEvent identifies a domain event customers can subscribe to
+type WebhookEvent string
+
+const (
+	WebhookEventInvoicePaid     WebhookEvent = "invoice.paid"
+	WebhookEventInvoiceOverdue  WebhookEvent = "invoice.overdue"
+	WebhookEventPaymentReceived WebhookEvent = "payment.received"
+)
+
+var (
+	ErrWebhookEndpointNotFound = errors.New("webhook endpoint not found")
+	ErrInvalidWebhookURL       = errors.New("webhook URL must be an absolute http or https URL")
+	ErrWebhookSecretRequired   = errors.New("webhook secret is required")
+)
+
+// WebhookEndpoint is a URL an organization registered to receive events.
+// An endpoint without events receives every event.
+type WebhookEndpoint struct {
+	ID             uint           `json:"id"`
+	OrganizationID uint           `json:"organizationId"`
+	URL            string         `json:"url"`
+	Secret         string         `json:"-"`
+	Events         []WebhookEvent `json:"events,omitempty"`
+	Active         bool           `json:"active"`
+	CreatedAt      time.Time      `json:"createdAt"`
+	UpdatedAt      time.Time      `json:"updatedAt"`
+}
+
+// NewWebhookEndpoint creates a new active webhook endpoint
+func NewWebhookEndpoint(organizationID uint, rawURL, secret string, events []WebhookEvent) (*WebhookEndpoint, error) {
+	now := time.Now()
+	endpoint := &WebhookEndpoint{
+		OrganizationID: organizationID,
+		URL:            strings.TrimSpace(rawURL),
+		Secret:         secret,
+		Events:         events,
+		Active:         true,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	if err := endpoint.Validate(); err != nil {
+		return nil, err
+	}
+	return endpoint, nil
+}
+
+// Validate checks the endpoint can be delivered to
+func (w *WebhookEndpoint) Validate() error {
+	if w.OrganizationID == 0 {
+		return errors.New("organization ID is required")
+	}
+	u, err := url.Parse(w.URL)
+	if err != nil || !u.IsAbs() || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return ErrInvalidWebhookURL
+	}
+	if w.Secret == "" {
+		return ErrWebhookSecretRequired
+	}
+	return nil
+}
+
+// Subscribes reports whether the endpoint wants to receive the event
+func (w *WebhookEndpoint) Subscribes(event WebhookEvent) bool {
+	if len(w.Events) == 0 {
+		return true
+	}
+	for _, e := range w.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookDelivery records a single attempt to deliver an event to an endpoint
+type WebhookDelivery struct {
+	ID             uint         `json:"id"`
+	EndpointID     uint         `json:"endpointId"`
+	OrganizationID uint         `json:"organizationId"`
+	Event          WebhookEvent `json:"event"`
+	Payload        string       `json:"payload"`
+	Attempt        int          `json:"attempt"`
+	StatusCode     int          `json:"statusCode,omitempty"`
+	Error          string       `json:"error,omitempty"`
+	Delivered      bool         `json:"delivered"`
+	CreatedAt      time.Time    `json:"createdAt"`
+}
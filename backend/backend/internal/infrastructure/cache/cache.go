@@ -0,0 +1,30 @@
+// @kthulu:core
+package cache
+
+import (
+	"fmt"
+
+	"go.uber.org/fx"
+
+	"github.com/pmaojo/kthulu-go/backend/core"
+	"github.com/pmaojo/kthulu-go/backend/internal/domain/repository"
+)
+
+// Module provides the repository.Cache selected by the configured driver.
+var Module = fx.Options(
+	fx.Provide(func(cfg *core.Config) (repository.Cache, error) {
+		return NewCache(cfg.Cache)
+	}),
+)
+
+// NewCache creates the cache selected by the configured driver
+func NewCache(cfg core.CacheConfig) (repository.Cache, error) {
+	switch cfg.Driver {
+	case "", "memory":
+		return NewMemoryCache(), nil
+	case "redis":
+		return NewRedisCache(cfg.RedisAddr), nil
+	default:
+		return nil, fmt.Errorf("unsupported cache driver: %s (supported: memory, redis)", cfg.Driver)
+	}
+}
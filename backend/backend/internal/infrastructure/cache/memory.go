@@ -0,0 +1,101 @@
+// @kthulu:core
+package cache
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// memorySweepInterval is how many writes a MemoryCache takes between sweeps
+// of its expired entries
+const memorySweepInterval = 1000
+
+type memoryEntry struct {
+	value     []byte
+	expiresAt time.Time
+}
+
+// MemoryCache is a repository.Cache local to the process, for single
+// instance deployments and tests. Expired entries are dropped when read and
+// swept periodically on writes.
+type MemoryCache struct {
+	mu      sync.RWMutex
+	entries map[string]memoryEntry
+	writes  int
+	now     func() time.Time
+}
+
+// NewMemoryCache creates an empty in-memory cache
+func NewMemoryCache() *MemoryCache {
+	return &MemoryCache{entries: make(map[string]memoryEntry), now: time.Now}
+}
+
+// Get returns the unexpired value stored under key
+func (c *MemoryCache) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	c.mu.RLock()
+	entry, ok := c.entries[key]
+	c.mu.RUnlock()
+	if !ok || !c.now().Before(entry.expiresAt) {
+		return nil, false, nil
+	}
+	return entry.value, true, nil
+}
+
+// Set stores value under key for ttl
+func (c *MemoryCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[key] = memoryEntry{value: value, expiresAt: c.now().Add(ttl)}
+	c.written()
+	return nil
+}
+
+// Del removes the keys
+func (c *MemoryCache) Del(ctx context.Context, keys ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, key := range keys {
+		delete(c.entries, key)
+	}
+	return nil
+}
+
+// Incr adds one to the counter under key, starting a counter expiring after
+// ttl when there is none
+func (c *MemoryCache) Incr(ctx context.Context, key string, ttl time.Duration) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	entry, ok := c.entries[key]
+	var count int64
+	if ok && now.Before(entry.expiresAt) {
+		var err error
+		if count, err = strconv.ParseInt(string(entry.value), 10, 64); err != nil {
+			return 0, err
+		}
+	} else {
+		entry.expiresAt = now.Add(ttl)
+	}
+	count++
+	entry.value = []byte(strconv.FormatInt(count, 10))
+	c.entries[key] = entry
+	c.written()
+	return count, nil
+}
+
+// written counts a write, sweeping expired entries every
+// memorySweepInterval writes. The caller holds the lock.
+func (c *MemoryCache) written() {
+	if c.writes++; c.writes < memorySweepInterval {
+		return
+	}
+	c.writes = 0
+	now := c.now()
+	for key, entry := range c.entries {
+		if !now.Before(entry.expiresAt) {
+			delete(c.entries, key)
+		}
+	}
+}
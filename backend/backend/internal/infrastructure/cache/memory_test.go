@@ -0,0 +1,65 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemoryCache_SetExpires(t *testing.T) {
+	ctx := context.Background()
+	now := time.Unix(1714644000, 0)
+	c := NewMemoryCache()
+	c.now = func() time.Time { return now }
+
+	if err := c.Set(ctx, "k", []byte("v"), time.Minute); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if value, ok, err := c.Get(ctx, "k"); err != nil || !ok || string(value) != "v" {
+		t.Fatalf("expected v before expiry, got %q, %v, %v", value, ok, err)
+	}
+
+	now = now.Add(time.Minute)
+	if value, ok, err := c.Get(ctx, "k"); err != nil || ok {
+		t.Fatalf("expected a miss once the ttl elapsed, got %q, %v, %v", value, ok, err)
+	}
+}
+
+func TestMemoryCache_IncrKeepsWindow(t *testing.T) {
+	ctx := context.Background()
+	now := time.Unix(1714644000, 0)
+	c := NewMemoryCache()
+	c.now = func() time.Time { return now }
+
+	for want := int64(1); want <= 3; want++ {
+		if count, err := c.Incr(ctx, "n", time.Minute); err != nil || count != want {
+			t.Fatalf("expected %d, got %d, %v", want, count, err)
+		}
+		// Increments don't extend the counter's ttl
+		now = now.Add(15 * time.Second)
+	}
+
+	now = now.Add(15 * time.Second)
+	if count, err := c.Incr(ctx, "n", time.Minute); err != nil || count != 1 {
+		t.Fatalf("expected the counter to restart after expiry, got %d, %v", count, err)
+	}
+}
+
+func TestMemoryCache_SweepsExpiredEntries(t *testing.T) {
+	ctx := context.Background()
+	now := time.Unix(1714644000, 0)
+	c := NewMemoryCache()
+	c.now = func() time.Time { return now }
+
+	c.Set(ctx, "stale", []byte("v"), time.Second)
+	now = now.Add(time.Second)
+	for i := 0; i < memorySweepInterval; i++ {
+		c.Set(ctx, "fresh", []byte("v"), time.Minute)
+	}
+	if _, ok := c.entries["stale"]; ok {
+		t.Fatal("expected the expired entry to be swept")
+	}
+	if _, ok := c.entries["fresh"]; !ok {
+		t.Fatal("expected the unexpired entry to be kept")
+	}
+}
@@ -0,0 +1,165 @@
+// @kthulu:module:products
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/pmaojo/kthulu-go/backend/core"
+	"github.com/pmaojo/kthulu-go/backend/internal/domain"
+	"github.com/pmaojo/kthulu-go/backend/internal/domain/repository"
+)
+
+// ProductRepository caches product reads by ID and SKU in front of another
+// product repository. Writes through it invalidate the cached products; a
+// SKU entry only points at a product ID, and is discarded once the product
+// no longer carries that SKU. Cache failures are logged and the wrapped
+// repository answers instead.
+type ProductRepository struct {
+	repository.ProductRepository
+
+	cache  repository.Cache
+	ttl    time.Duration
+	logger core.Logger
+}
+
+// NewProductRepository wraps products with a read-through cache keeping
+// entries for ttl
+func NewProductRepository(products repository.ProductRepository, cache repository.Cache, ttl time.Duration, logger core.Logger) *ProductRepository {
+	return &ProductRepository{
+		ProductRepository: products,
+		cache:             cache,
+		ttl:               ttl,
+		logger:            logger,
+	}
+}
+
+func productIDKey(organizationID, productID uint) string {
+	return fmt.Sprintf("product:%d:id:%d", organizationID, productID)
+}
+
+func productSKUKey(organizationID uint, sku string) string {
+	return fmt.Sprintf("product:%d:sku:%s", organizationID, sku)
+}
+
+// GetByID returns the cached product, loading it on a miss
+func (r *ProductRepository) GetByID(ctx context.Context, organizationID, productID uint) (*domain.Product, error) {
+	key := productIDKey(organizationID, productID)
+	if value, ok := r.get(ctx, key); ok {
+		var product domain.Product
+		if err := json.Unmarshal(value, &product); err == nil {
+			return &product, nil
+		}
+	}
+
+	product, err := r.ProductRepository.GetByID(ctx, organizationID, productID)
+	if err != nil {
+		return nil, err
+	}
+	if value, err := json.Marshal(product); err == nil {
+		r.set(ctx, key, value)
+	}
+	return product, nil
+}
+
+// GetBySKU resolves the SKU to a product ID through the cache, loading the
+// product on a miss
+func (r *ProductRepository) GetBySKU(ctx context.Context, organizationID uint, sku string) (*domain.Product, error) {
+	key := productSKUKey(organizationID, sku)
+	if value, ok := r.get(ctx, key); ok {
+		if productID, err := strconv.ParseUint(string(value), 10, 32); err == nil {
+			product, err := r.GetByID(ctx, organizationID, uint(productID))
+			if err == nil && product.SKU == sku {
+				return product, nil
+			}
+		}
+		r.delete(ctx, key)
+	}
+
+	product, err := r.ProductRepository.GetBySKU(ctx, organizationID, sku)
+	if err != nil {
+		return nil, err
+	}
+	if value, err := json.Marshal(product); err == nil {
+		r.set(ctx, productIDKey(organizationID, product.ID), value)
+	}
+	r.set(ctx, key, []byte(strconv.FormatUint(uint64(product.ID), 10)))
+	return product, nil
+}
+
+// Update updates the product and invalidates its cache entry
+func (r *ProductRepository) Update(ctx context.Context, product *domain.Product) error {
+	if err := r.ProductRepository.Update(ctx, product); err != nil {
+		return err
+	}
+	r.delete(ctx, productIDKey(product.OrganizationID, product.ID))
+	return nil
+}
+
+// Delete deletes the product and invalidates its cache entry
+func (r *ProductRepository) Delete(ctx context.Context, organizationID, productID uint) error {
+	if err := r.ProductRepository.Delete(ctx, organizationID, productID); err != nil {
+		return err
+	}
+	r.delete(ctx, productIDKey(organizationID, productID))
+	return nil
+}
+
+// BulkUpdate updates the products and invalidates their cache entries
+func (r *ProductRepository) BulkUpdate(ctx context.Context, products []*domain.Product) error {
+	err := r.ProductRepository.BulkUpdate(ctx, products)
+	// Some updates may have been applied before a failure
+	keys := make([]string, len(products))
+	for i, product := range products {
+		keys[i] = productIDKey(product.OrganizationID, product.ID)
+	}
+	r.delete(ctx, keys...)
+	return err
+}
+
+// BulkDelete deletes the products and invalidates their cache entries
+func (r *ProductRepository) BulkDelete(ctx context.Context, organizationID uint, productIDs []uint) error {
+	err := r.ProductRepository.BulkDelete(ctx, organizationID, productIDs)
+	r.delete(ctx, productIDKeys(organizationID, productIDs)...)
+	return err
+}
+
+// BulkUpdateTaxRate updates the products' tax rate and invalidates their
+// cache entries
+func (r *ProductRepository) BulkUpdateTaxRate(ctx context.Context, organizationID uint, productIDs []uint, taxRate float64) error {
+	err := r.ProductRepository.BulkUpdateTaxRate(ctx, organizationID, productIDs, taxRate)
+	r.delete(ctx, productIDKeys(organizationID, productIDs)...)
+	return err
+}
+
+func productIDKeys(organizationID uint, productIDs []uint) []string {
+	keys := make([]string, len(productIDs))
+	for i, productID := range productIDs {
+		keys[i] = productIDKey(organizationID, productID)
+	}
+	return keys
+}
+
+func (r *ProductRepository) get(ctx context.Context, key string) ([]byte, bool) {
+	value, ok, err := r.cache.Get(ctx, key)
+	if err != nil {
+		r.logger.Warn("Product cache read failed", "error", err, "key", key)
+		return nil, false
+	}
+	return value, ok
+}
+
+func (r *ProductRepository) set(ctx context.Context, key string, value []byte) {
+	if err := r.cache.Set(ctx, key, value, r.ttl); err != nil {
+		r.logger.Warn("Product cache write failed", "error", err, "key", key)
+	}
+}
+
+func (r *ProductRepository) delete(ctx context.Context, keys ...string) {
+	if err := r.cache.Del(ctx, keys...); err != nil {
+		r.logger.Error("Product cache invalidation failed", "error", err, "keys", keys)
+	}
+}
@@ -0,0 +1,129 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/pmaojo/kthulu-go/backend/core"
+	"github.com/pmaojo/kthulu-go/backend/internal/domain"
+	"github.com/pmaojo/kthulu-go/backend/internal/domain/repository"
+)
+
+// fakeProducts keeps products in memory and counts the reads that reach it;
+// calls it doesn't implement panic
+type fakeProducts struct {
+	repository.ProductRepository
+	products map[uint]domain.Product
+	reads    int
+}
+
+func (f *fakeProducts) GetByID(ctx context.Context, organizationID, productID uint) (*domain.Product, error) {
+	f.reads++
+	product, ok := f.products[productID]
+	if !ok || product.OrganizationID != organizationID {
+		return nil, domain.ErrProductNotFound
+	}
+	return &product, nil
+}
+
+func (f *fakeProducts) GetBySKU(ctx context.Context, organizationID uint, sku string) (*domain.Product, error) {
+	f.reads++
+	for _, product := range f.products {
+		if product.OrganizationID == organizationID && product.SKU == sku {
+			return &product, nil
+		}
+	}
+	return nil, domain.ErrProductNotFound
+}
+
+func (f *fakeProducts) Update(ctx context.Context, product *domain.Product) error {
+	f.products[product.ID] = *product
+	return nil
+}
+
+func (f *fakeProducts) Delete(ctx context.Context, organizationID, productID uint) error {
+	delete(f.products, productID)
+	return nil
+}
+
+func newCachedProducts() (*ProductRepository, *fakeProducts, *MemoryCache) {
+	products := &fakeProducts{products: map[uint]domain.Product{
+		1: {ID: 1, OrganizationID: 1, SKU: "MUG", Name: "Mug", TaxRate: 0.21},
+	}}
+	cache := NewMemoryCache()
+	return NewProductRepository(products, cache, time.Minute, core.NewLoggerFromZap(zap.NewNop())), products, cache
+}
+
+func TestProductRepository_CachesReads(t *testing.T) {
+	repo, products, cache := newCachedProducts()
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		product, err := repo.GetByID(ctx, 1, 1)
+		if err != nil || product.Name != "Mug" {
+			t.Fatalf("unexpected product %+v, %v", product, err)
+		}
+	}
+	if products.reads != 1 {
+		t.Fatalf("expected one miss then hits, got %d reads", products.reads)
+	}
+
+	// A SKU lookup reuses the cached product once the SKU is known
+	for i := 0; i < 3; i++ {
+		if product, err := repo.GetBySKU(ctx, 1, "MUG"); err != nil || product.ID != 1 {
+			t.Fatalf("unexpected product %+v, %v", product, err)
+		}
+	}
+	if products.reads != 2 {
+		t.Fatalf("expected a single SKU miss, got %d reads", products.reads)
+	}
+
+	// Entries are scoped to the organization
+	if _, err := repo.GetByID(ctx, 2, 1); !errors.Is(err, domain.ErrProductNotFound) {
+		t.Fatalf("expected ErrProductNotFound, got %v", err)
+	}
+
+	// Expired entries are loaded again
+	cache.now = func() time.Time { return time.Now().Add(2 * time.Minute) }
+	if _, err := repo.GetByID(ctx, 1, 1); err != nil || products.reads != 4 {
+		t.Fatalf("expected the expired entry to be reloaded, got %d reads, %v", products.reads, err)
+	}
+}
+
+func TestProductRepository_InvalidatesOnWrites(t *testing.T) {
+	repo, _, _ := newCachedProducts()
+	ctx := context.Background()
+
+	if _, err := repo.GetBySKU(ctx, 1, "MUG"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	updated := domain.Product{ID: 1, OrganizationID: 1, SKU: "MUG-XL", Name: "Large mug", TaxRate: 0.21}
+	if err := repo.Update(ctx, &updated); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if product, err := repo.GetByID(ctx, 1, 1); err != nil || product.Name != "Large mug" {
+		t.Fatalf("expected the updated product, got %+v, %v", product, err)
+	}
+	// The old SKU no longer resolves to the renamed product
+	if _, err := repo.GetBySKU(ctx, 1, "MUG"); !errors.Is(err, domain.ErrProductNotFound) {
+		t.Fatalf("expected the old SKU to be gone, got %v", err)
+	}
+	if product, err := repo.GetBySKU(ctx, 1, "MUG-XL"); err != nil || product.ID != 1 {
+		t.Fatalf("expected the new SKU to resolve, got %+v, %v", product, err)
+	}
+
+	if err := repo.Delete(ctx, 1, 1); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := repo.GetByID(ctx, 1, 1); !errors.Is(err, domain.ErrProductNotFound) {
+		t.Fatalf("expected the deleted product to be gone, got %v", err)
+	}
+	if _, err := repo.GetBySKU(ctx, 1, "MUG-XL"); !errors.Is(err, domain.ErrProductNotFound) {
+		t.Fatalf("expected the deleted product's SKU to be gone, got %v", err)
+	}
+}
@@ -0,0 +1,69 @@
+// @kthulu:core
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// redisKeyPrefix namespaces the cache keys in a Redis shared with other
+// applications
+const redisKeyPrefix = "kthulu:cache:"
+
+// RedisCache is a repository.Cache shared by every instance through Redis
+type RedisCache struct {
+	client *redis.Client
+}
+
+// NewRedisCache creates a cache on the Redis server at addr
+func NewRedisCache(addr string) *RedisCache {
+	return &RedisCache{client: redis.NewClient(&redis.Options{Addr: addr})}
+}
+
+// Get returns the value stored under key
+func (c *RedisCache) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	value, err := c.client.Get(ctx, redisKeyPrefix+key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return value, true, nil
+}
+
+// Set stores value under key for ttl
+func (c *RedisCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return c.client.Set(ctx, redisKeyPrefix+key, value, ttl).Err()
+}
+
+// Del removes the keys
+func (c *RedisCache) Del(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	prefixed := make([]string, len(keys))
+	for i, key := range keys {
+		prefixed[i] = redisKeyPrefix + key
+	}
+	return c.client.Del(ctx, prefixed...).Err()
+}
+
+// Incr adds one to the counter under key. The first increment sets the
+// counter's expiry.
+func (c *RedisCache) Incr(ctx context.Context, key string, ttl time.Duration) (int64, error) {
+	key = redisKeyPrefix + key
+	count, err := c.client.Incr(ctx, key).Result()
+	if err != nil {
+		return 0, err
+	}
+	if count == 1 {
+		if err := c.client.Expire(ctx, key, ttl).Err(); err != nil {
+			return count, err
+		}
+	}
+	return count, nil
+}
@@ -0,0 +1,91 @@
+// @kthulu:module:audit
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/pmaojo/kthulu-go/backend/core"
+	"github.com/pmaojo/kthulu-go/backend/internal/domain"
+	"github.com/pmaojo/kthulu-go/backend/internal/domain/repository"
+)
+
+// AuditLogger implements repository.AuditLogger using the audit_logs table
+type AuditLogger struct {
+	db     *sql.DB
+	logger core.Logger
+}
+
+// NewAuditLogger creates a new DB-backed audit logger
+func NewAuditLogger(db *sql.DB, logger core.Logger) repository.AuditLogger {
+	return &AuditLogger{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// Log persists an audit entry. The actor is taken from the context when the
+// entry does not already carry one.
+func (a *AuditLogger) Log(ctx context.Context, entry *domain.AuditLogEntry) error {
+	ctx, cancel := core.WithStatementTimeout(ctx)
+	defer cancel()
+
+	if entry.ActorID == nil {
+		if actorID, ok := repository.ActorFromContext(ctx); ok {
+			entry.ActorID = &actorID
+		}
+	}
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = time.Now()
+	}
+
+	var diff interface{}
+	if len(entry.Diff) > 0 {
+		diff = string(entry.Diff)
+	}
+
+	query := `
+		INSERT INTO audit_logs (
+			organization_id, actor_id, action, entity_type, entity_id, diff, created_at
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7
+		) RETURNING id`
+
+	err := a.db.QueryRowContext(ctx, query,
+		entry.OrganizationID, entry.ActorID, entry.Action, entry.EntityType,
+		entry.EntityID, diff, entry.CreatedAt,
+	).Scan(&entry.ID)
+
+	if err != nil {
+		a.logger.Error("Failed to write audit log", "error", err, "entityType", entry.EntityType, "entityId", entry.EntityID)
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+
+	return nil
+}
+
+// recordAudit writes an audit entry for a repository mutation. Failures are
+// logged and swallowed so that auditing never blocks the mutation itself.
+func recordAudit(ctx context.Context, audit repository.AuditLogger, logger core.Logger, action domain.AuditAction, entityType string, organizationID, entityID uint, before, after interface{}) {
+	if audit == nil {
+		return
+	}
+
+	diff, err := domain.NewAuditDiff(before, after)
+	if err != nil {
+		logger.Warn("Failed to compute audit diff", "error", err, "entityType", entityType, "entityId", entityID)
+	}
+
+	entry := &domain.AuditLogEntry{
+		OrganizationID: organizationID,
+		Action:         action,
+		EntityType:     entityType,
+		EntityID:       entityID,
+		Diff:           diff,
+	}
+	if err := audit.Log(ctx, entry); err != nil {
+		logger.Warn("Audit log entry dropped", "error", err, "entityType", entityType, "entityId", entityID)
+	}
+}
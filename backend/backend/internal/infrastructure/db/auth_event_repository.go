@@ -0,0 +1,121 @@
+// @kthulu:module:auth
+package db
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/pmaojo/kthulu-go/backend/internal/domain"
+	"github.com/pmaojo/kthulu-go/backend/internal/domain/repository"
+)
+
+// AuthEventModel represents the database model for authentication events
+type AuthEventModel struct {
+	ID        uint `gorm:"primaryKey"`
+	UserID    *uint
+	Email     string
+	EventType string `gorm:"not null"`
+	IPAddress string
+	UserAgent string
+	CreatedAt time.Time
+}
+
+// TableName specifies the table name for AuthEventModel
+func (AuthEventModel) TableName() string {
+	return "auth_events"
+}
+
+// ToDomain converts AuthEventModel to domain.AuthEvent
+func (m *AuthEventModel) ToDomain() *domain.AuthEvent {
+	return &domain.AuthEvent{
+		ID:        m.ID,
+		UserID:    m.UserID,
+		Email:     m.Email,
+		Type:      domain.AuthEventType(m.EventType),
+		IPAddress: m.IPAddress,
+		UserAgent: m.UserAgent,
+		CreatedAt: m.CreatedAt,
+	}
+}
+
+// AuthEventRepository provides a database-backed implementation of repository.AuthEventRepository.
+type AuthEventRepository struct {
+	db *gorm.DB
+}
+
+// NewAuthEventRepository creates a new instance bound to a Gorm database.
+func NewAuthEventRepository(db *gorm.DB) repository.AuthEventRepository {
+	return &AuthEventRepository{db: db}
+}
+
+// Record persists an authentication event. The client is taken from the
+// context when the event does not already carry one.
+func (r *AuthEventRepository) Record(ctx context.Context, event *domain.AuthEvent) error {
+	if client, ok := repository.ClientInfoFromContext(ctx); ok {
+		if event.IPAddress == "" {
+			event.IPAddress = client.IPAddress
+		}
+		if event.UserAgent == "" {
+			event.UserAgent = client.UserAgent
+		}
+	}
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now()
+	}
+
+	model := &AuthEventModel{
+		UserID:    event.UserID,
+		Email:     event.Email,
+		EventType: string(event.Type),
+		IPAddress: event.IPAddress,
+		UserAgent: event.UserAgent,
+		CreatedAt: event.CreatedAt,
+	}
+	if err := r.db.WithContext(ctx).Create(model).Error; err != nil {
+		return err
+	}
+
+	event.ID = model.ID
+	return nil
+}
+
+// List returns the events matching filters, newest first, with the total count.
+func (r *AuthEventRepository) List(ctx context.Context, filters repository.AuthEventFilters) ([]*domain.AuthEvent, int64, error) {
+	if err := filters.Validate(); err != nil {
+		return nil, 0, err
+	}
+
+	query := r.db.WithContext(ctx).Model(&AuthEventModel{})
+	if filters.UserID != 0 {
+		query = query.Where("user_id = ?", filters.UserID)
+	}
+	if filters.Email != "" {
+		query = query.Where("LOWER(email) = ?", strings.ToLower(filters.Email))
+	}
+	if filters.Type != "" {
+		query = query.Where("event_type = ?", filters.Type)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var models []AuthEventModel
+	if err := query.
+		Order("created_at DESC, id DESC").
+		Limit(filters.PageSize).
+		Offset(filters.GetOffset()).
+		Find(&models).Error; err != nil {
+		return nil, 0, err
+	}
+
+	events := make([]*domain.AuthEvent, len(models))
+	for i := range models {
+		events[i] = models[i].ToDomain()
+	}
+	return events, total, nil
+}
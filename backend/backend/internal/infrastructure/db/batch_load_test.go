@@ -0,0 +1,169 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/pmaojo/kthulu-go/backend/core"
+	"github.com/pmaojo/kthulu-go/backend/internal/domain/repository"
+)
+
+// countingConn wraps a sqlite connection and counts the queries run on it
+type countingConn struct {
+	driver.Conn
+	queries *int64
+}
+
+func (c *countingConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	atomic.AddInt64(c.queries, 1)
+	return c.Conn.(driver.QueryerContext).QueryContext(ctx, query, args)
+}
+
+func (c *countingConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	return c.Conn.(driver.ExecerContext).ExecContext(ctx, query, args)
+}
+
+type countingConnector struct {
+	queries *int64
+}
+
+func (c *countingConnector) Connect(context.Context) (driver.Conn, error) {
+	conn, err := (&sqlite3.SQLiteDriver{}).Open(":memory:")
+	if err != nil {
+		return nil, err
+	}
+	return &countingConn{Conn: conn, queries: c.queries}, nil
+}
+func (c *countingConnector) Driver() driver.Driver { return &sqlite3.SQLiteDriver{} }
+
+// newCountingDB opens a single-connection in-memory database whose queries
+// are counted. With one connection a query issued while another result set
+// is still open would block, so the tests also catch nested loads.
+func newCountingDB(t *testing.T) (*sql.DB, *int64) {
+	var queries int64
+	sqlDB := sql.OpenDB(&countingConnector{queries: &queries})
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+	return sqlDB, &queries
+}
+
+// countQueries reports how many queries fn ran against the counting database
+func countQueries(queries *int64, fn func()) int64 {
+	before := atomic.LoadInt64(queries)
+	fn()
+	return atomic.LoadInt64(queries) - before
+}
+
+func TestInvoiceRepositoryListBatchLoadsItemsAndPayments(t *testing.T) {
+	sqlDB, queries := newCountingDB(t)
+	setupInvoiceTables(t, sqlDB)
+	repo := NewInvoiceRepository(sqlDB, core.NewLoggerFromZap(zap.NewNop()), nil, nil).(*InvoiceRepository)
+
+	filters := repository.InvoiceFilters{Page: 1, PageSize: 20, IncludeItems: true, IncludePayments: true}
+	listQueries := func() int64 {
+		return countQueries(queries, func() {
+			_, _, err := repo.List(context.Background(), 1, filters)
+			require.NoError(t, err)
+		})
+	}
+
+	issued := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	first := createTestInvoice(t, sqlDB, 1, "sent", issued, 100, 40)
+	createTestInvoiceItem(t, sqlDB, first, 1, 1, 100)
+	_, err := sqlDB.Exec(`INSERT INTO payments (organization_id, invoice_id, payment_method, amount, payment_date) VALUES (1, ?, 'cash', 40, ?)`, first, issued)
+	require.NoError(t, err)
+	single := listQueries()
+
+	for i := 1; i <= 4; i++ {
+		id := createTestInvoice(t, sqlDB, 1, "sent", issued.AddDate(0, 0, i), 50, 0)
+		createTestInvoiceItem(t, sqlDB, id, 2, 1, 25)
+		createTestInvoiceItem(t, sqlDB, id, 3, 1, 25)
+	}
+	// Count, page, items and payments, however many invoices are listed
+	assert.Equal(t, int64(4), single)
+	assert.Equal(t, single, listQueries())
+
+	invoices, total, err := repo.List(context.Background(), 1, filters)
+	require.NoError(t, err)
+	require.Equal(t, int64(5), total)
+	for _, invoice := range invoices {
+		if invoice.ID == uint(first) {
+			assert.Len(t, invoice.Items, 1)
+			require.Len(t, invoice.Payments, 1)
+			assert.Equal(t, 40.0, invoice.Payments[0].Amount)
+			continue
+		}
+		assert.Len(t, invoice.Items, 2)
+		assert.Empty(t, invoice.Payments)
+		for _, item := range invoice.Items {
+			assert.Equal(t, invoice.ID, item.InvoiceID)
+		}
+	}
+}
+
+func TestInvoiceRepositoryGetItemsByInvoiceIDs_Empty(t *testing.T) {
+	repo, _ := newTestInvoiceRepository(t)
+
+	items, err := repo.GetItemsByInvoiceIDs(context.Background(), nil)
+	require.NoError(t, err)
+	assert.Empty(t, items)
+}
+
+func TestProductRepositoryListBatchLoadsVariantsAndPrices(t *testing.T) {
+	sqlDB, queries := newCountingDB(t)
+	setupProductTables(t, sqlDB)
+	logger := core.NewLoggerFromZap(zap.NewNop())
+	repo := NewProductRepository(sqlDB, logger, NewAuditLogger(sqlDB, logger), nil).(*ProductRepository)
+
+	filters := repository.ProductFilters{Page: 1, PageSize: 20, IncludeVariants: true, IncludePrices: true}
+	listQueries := func() int64 {
+		return countQueries(queries, func() {
+			_, _, err := repo.List(context.Background(), 1, filters)
+			require.NoError(t, err)
+		})
+	}
+
+	addProduct := func(sku string, variants int) int64 {
+		result, err := sqlDB.Exec(`INSERT INTO products (organization_id, sku, name, description, category, brand, unit_of_measure, dimensions, barcode) VALUES (1, ?, ?, '', '', '', 'each', '', '')`, sku, sku)
+		require.NoError(t, err)
+		id, err := result.LastInsertId()
+		require.NoError(t, err)
+		for i := 0; i < variants; i++ {
+			_, err := sqlDB.Exec(`INSERT INTO product_variants (product_id, sku, name, description, dimensions, barcode) VALUES (?, ?, 'Variant', '', '', '')`, id, sku+"-V")
+			require.NoError(t, err)
+		}
+		_, err = sqlDB.Exec(`INSERT INTO product_prices (product_id, price_type, amount) VALUES (?, 'base', 10)`, id)
+		require.NoError(t, err)
+		return id
+	}
+
+	addProduct("SKU-1", 1)
+	single := listQueries()
+	for _, sku := range []string{"SKU-2", "SKU-3", "SKU-4"} {
+		addProduct(sku, 2)
+	}
+	// Count, page, variants and prices, however many products are listed
+	assert.Equal(t, int64(4), single)
+	assert.Equal(t, single, listQueries())
+
+	products, _, err := repo.List(context.Background(), 1, filters)
+	require.NoError(t, err)
+	require.Len(t, products, 4)
+	for _, product := range products {
+		assert.Len(t, product.Prices, 1)
+		if product.SKU == "SKU-1" {
+			assert.Len(t, product.Variants, 1)
+		} else {
+			assert.Len(t, product.Variants, 2)
+		}
+	}
+}
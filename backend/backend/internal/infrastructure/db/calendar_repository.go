@@ -116,6 +116,35 @@ func (r *calendarRepository) GetEventsByTimeRange(ctx context.Context, calendarI
 	return events, err
 }
 
+func (r *calendarRepository) ListEventsByOwner(ctx context.Context, ownerID, calendarID uint) ([]domain.Event, error) {
+	var events []domain.Event
+	query := r.db.WithContext(ctx).
+		Joins("JOIN calendars ON calendars.id = events.calendar_id").
+		Where("calendars.owner_id = ?", ownerID)
+	if calendarID != 0 {
+		query = query.Where("events.calendar_id = ?", calendarID)
+	}
+	err := query.Order("events.start_time ASC").Find(&events).Error
+	return events, err
+}
+
+func (r *calendarRepository) ListEventsForContact(ctx context.Context, organizationID, contactID uint) ([]domain.Event, error) {
+	return r.listEventsForEntity(ctx, organizationID, domain.EventEntityContact, contactID)
+}
+
+func (r *calendarRepository) ListEventsForInvoice(ctx context.Context, organizationID, invoiceID uint) ([]domain.Event, error) {
+	return r.listEventsForEntity(ctx, organizationID, domain.EventEntityInvoice, invoiceID)
+}
+
+func (r *calendarRepository) listEventsForEntity(ctx context.Context, organizationID uint, entityType domain.EventEntityType, entityID uint) ([]domain.Event, error) {
+	var events []domain.Event
+	err := r.db.WithContext(ctx).
+		Where("organization_id = ? AND entity_type = ? AND entity_id = ?", organizationID, entityType, entityID).
+		Order("start_time ASC").
+		Find(&events).Error
+	return events, err
+}
+
 func (r *calendarRepository) UpdateEvent(ctx context.Context, event *domain.Event) error {
 	return r.db.WithContext(ctx).Save(event).Error
 }
@@ -163,11 +192,28 @@ func (r *calendarRepository) GetPendingReminders(ctx context.Context, before tim
 	var reminders []domain.Reminder
 	err := r.db.WithContext(ctx).
 		Preload("Event").
-		Where("is_sent = ? AND created_at <= ?", false, before).
+		Preload("Event.Attendees").
+		Where("is_sent = ? AND type = ? AND remind_at <= ?", false, domain.ReminderTypeEmail, before).
+		Order("remind_at ASC").
 		Find(&reminders).Error
 	return reminders, err
 }
 
+func (r *calendarRepository) ClaimReminder(ctx context.Context, id uint, sentAt time.Time) (bool, error) {
+	// The is_sent condition makes concurrent workers race for the row, only
+	// one of them updates it
+	result := r.db.WithContext(ctx).Model(&domain.Reminder{}).
+		Where("id = ? AND is_sent = ?", id, false).
+		Updates(map[string]any{"is_sent": true, "sent_at": sentAt})
+	return result.RowsAffected == 1, result.Error
+}
+
+func (r *calendarRepository) ReleaseReminder(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Model(&domain.Reminder{}).
+		Where("id = ?", id).
+		Updates(map[string]any{"is_sent": false, "sent_at": nil}).Error
+}
+
 func (r *calendarRepository) UpdateReminder(ctx context.Context, reminder *domain.Reminder) error {
 	return r.db.WithContext(ctx).Save(reminder).Error
 }
@@ -63,14 +63,15 @@ func (contactAddressModel) TableName() string {
 
 // contactPhoneModel represents the database model for contact phones
 type contactPhoneModel struct {
-	ID        uint      `gorm:"primaryKey"`
-	ContactID uint      `gorm:"not null;index"`
-	Type      string    `gorm:"not null;size:20"`
-	Number    string    `gorm:"not null;size:20"`
-	Extension string    `gorm:"size:10"`
-	IsPrimary bool      `gorm:"default:false"`
-	CreatedAt Timestamp `gorm:"column:created_at"`
-	UpdatedAt Timestamp `gorm:"column:updated_at"`
+	ID               uint      `gorm:"primaryKey"`
+	ContactID        uint      `gorm:"not null;index"`
+	Type             string    `gorm:"not null;size:20"`
+	Number           string    `gorm:"not null;size:20"`
+	NormalizedNumber string    `gorm:"not null;default:'';size:20;index"`
+	Extension        string    `gorm:"size:10"`
+	IsPrimary        bool      `gorm:"default:false"`
+	CreatedAt        Timestamp `gorm:"column:created_at"`
+	UpdatedAt        Timestamp `gorm:"column:updated_at"`
 }
 
 func (contactPhoneModel) TableName() string {
@@ -189,7 +190,12 @@ func (r *ContactRepository) List(ctx context.Context, organizationID uint, filte
 		query = query.Where("is_active = ?", *filters.IsActive)
 	}
 
+	if len(filters.IDs) > 0 {
+		query = query.Where("id IN ?", filters.IDs)
+	}
+
 	if filters.Search != "" {
+		// Each LOWER(column) matches a trigram index on Postgres
 		searchTerm := "%" + strings.ToLower(filters.Search) + "%"
 		query = query.Where(
 			"LOWER(company_name) LIKE ? OR LOWER(first_name) LIKE ? OR LOWER(last_name) LIKE ? OR LOWER(email) LIKE ?",
@@ -222,6 +228,12 @@ func (r *ContactRepository) List(ctx context.Context, organizationID uint, filte
 	// Apply pagination
 	query = query.Offset(filters.GetOffset()).Limit(filters.PageSize)
 
+	if filters.IncludeRelations {
+		query = query.
+			Preload("Addresses", func(db *gorm.DB) *gorm.DB { return db.Order("is_primary DESC, created_at ASC") }).
+			Preload("Phones", func(db *gorm.DB) *gorm.DB { return db.Order("is_primary DESC, created_at ASC") })
+	}
+
 	var models []contactModel
 	if err := query.Find(&models).Error; err != nil {
 		return nil, 0, fmt.Errorf("failed to list contacts: %w", err)
@@ -230,11 +242,57 @@ func (r *ContactRepository) List(ctx context.Context, organizationID uint, filte
 	contacts := make([]*domain.Contact, len(models))
 	for i, model := range models {
 		contacts[i] = r.modelToDomain(&model)
+		if filters.IncludeRelations {
+			contacts[i].Addresses = make([]domain.ContactAddress, len(model.Addresses))
+			for j := range model.Addresses {
+				contacts[i].Addresses[j] = *r.addressModelToDomain(&model.Addresses[j])
+			}
+			contacts[i].Phones = make([]domain.ContactPhone, len(model.Phones))
+			for j := range model.Phones {
+				contacts[i].Phones[j] = *r.phoneModelToDomain(&model.Phones[j])
+			}
+		}
 	}
 
 	return contacts, total, nil
 }
 
+// FindPotentialDuplicates loads every contact of the organization with its
+// phone numbers and groups the likely duplicates
+func (r *ContactRepository) FindPotentialDuplicates(ctx context.Context, organizationID uint) ([]domain.DuplicateGroup, error) {
+	var models []contactModel
+	if err := r.db.WithContext(ctx).
+		Where("organization_id = ?", organizationID).
+		Order("id ASC").
+		Find(&models).Error; err != nil {
+		return nil, fmt.Errorf("failed to list contacts: %w", err)
+	}
+	if len(models) == 0 {
+		return nil, nil
+	}
+
+	var phones []contactPhoneModel
+	if err := r.db.WithContext(ctx).
+		Where("contact_id IN (?)", r.db.Model(&contactModel{}).Select("id").Where("organization_id = ?", organizationID)).
+		Find(&phones).Error; err != nil {
+		return nil, fmt.Errorf("failed to list contact phones: %w", err)
+	}
+
+	contacts := make([]*domain.Contact, len(models))
+	byID := make(map[uint]*domain.Contact, len(models))
+	for i := range models {
+		contacts[i] = r.modelToDomain(&models[i])
+		byID[contacts[i].ID] = contacts[i]
+	}
+	for i := range phones {
+		if contact, ok := byID[phones[i].ContactID]; ok {
+			contact.Phones = append(contact.Phones, *r.phoneModelToDomain(&phones[i]))
+		}
+	}
+
+	return domain.FindDuplicateGroups(contacts), nil
+}
+
 // CreateAddress creates a new contact address
 func (r *ContactRepository) CreateAddress(ctx context.Context, address *domain.ContactAddress) error {
 	// If this is set as primary, unset other primary addresses of the same type
@@ -431,8 +489,11 @@ func (r *ContactRepository) GetPhoneByID(ctx context.Context, contactID, phoneID
 func (r *ContactRepository) UpdatePhone(ctx context.Context, phone *domain.ContactPhone) error {
 	model := r.phoneDomainToModel(phone)
 
+	// Listed so an empty normalized number is written too; the primary flag
+	// is changed through SetPrimaryPhone
 	result := r.db.WithContext(ctx).
 		Where("id = ? AND contact_id = ?", phone.ID, phone.ContactID).
+		Select("type", "number", "normalized_number", "extension", "updated_at").
 		Updates(&model)
 
 	if result.Error != nil {
@@ -602,6 +663,49 @@ func (r *ContactRepository) GetContactStats(ctx context.Context, organizationID
 	return stats, nil
 }
 
+// GetContactStatsByPeriod counts the contacts created in each month of
+// [from, to), broken down by type. The query groups them by month with
+// date_trunc; months without contacts are reported with zero counts so the
+// series has no gaps.
+func (r *ContactRepository) GetContactStatsByPeriod(ctx context.Context, organizationID uint, from, to time.Time) (*repository.ContactStatsTrend, error) {
+	var rows []struct {
+		Bucket Timestamp
+		Type   string
+		Count  int64
+	}
+
+	if err := r.db.WithContext(ctx).
+		Model(&contactModel{}).
+		Select("date_trunc('month', created_at) AS bucket, type, COUNT(*) AS count").
+		Where("organization_id = ? AND created_at >= ? AND created_at < ?", organizationID, Timestamp{Time: from}, Timestamp{Time: to}).
+		Group("bucket, type").
+		Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to count contacts for trend: %w", err)
+	}
+
+	trend := &repository.ContactStatsTrend{From: from, To: to}
+	index := make(map[string]int)
+	for month := time.Date(from.Year(), from.Month(), 1, 0, 0, 0, 0, from.Location()); month.Before(to); month = month.AddDate(0, 1, 0) {
+		key := month.Format("2006-01")
+		index[key] = len(trend.Months)
+		trend.Months = append(trend.Months, repository.ContactMonthStats{
+			Month:  key,
+			ByType: make(map[domain.ContactType]int64),
+		})
+	}
+
+	for _, row := range rows {
+		i, ok := index[row.Bucket.Format("2006-01")]
+		if !ok {
+			continue
+		}
+		trend.Months[i].Total += row.Count
+		trend.Months[i].ByType[domain.ContactType(row.Type)] += row.Count
+	}
+
+	return trend, nil
+}
+
 // Helper methods for model conversion
 
 func (r *ContactRepository) domainToModel(contact *domain.Contact) *contactModel {
@@ -680,26 +784,28 @@ func (r *ContactRepository) addressModelToDomain(model *contactAddressModel) *do
 
 func (r *ContactRepository) phoneDomainToModel(phone *domain.ContactPhone) *contactPhoneModel {
 	return &contactPhoneModel{
-		ID:        phone.ID,
-		ContactID: phone.ContactID,
-		Type:      string(phone.Type),
-		Number:    phone.Number,
-		Extension: phone.Extension,
-		IsPrimary: phone.IsPrimary,
-		CreatedAt: Timestamp{Time: phone.CreatedAt},
-		UpdatedAt: Timestamp{Time: phone.UpdatedAt},
+		ID:               phone.ID,
+		ContactID:        phone.ContactID,
+		Type:             string(phone.Type),
+		Number:           phone.Number,
+		NormalizedNumber: phone.NormalizedNumber,
+		Extension:        phone.Extension,
+		IsPrimary:        phone.IsPrimary,
+		CreatedAt:        Timestamp{Time: phone.CreatedAt},
+		UpdatedAt:        Timestamp{Time: phone.UpdatedAt},
 	}
 }
 
 func (r *ContactRepository) phoneModelToDomain(model *contactPhoneModel) *domain.ContactPhone {
 	return &domain.ContactPhone{
-		ID:        model.ID,
-		ContactID: model.ContactID,
-		Type:      domain.PhoneType(model.Type),
-		Number:    model.Number,
-		Extension: model.Extension,
-		IsPrimary: model.IsPrimary,
-		CreatedAt: model.CreatedAt.Time,
-		UpdatedAt: model.UpdatedAt.Time,
+		ID:               model.ID,
+		ContactID:        model.ContactID,
+		Type:             domain.PhoneType(model.Type),
+		Number:           model.Number,
+		NormalizedNumber: model.NormalizedNumber,
+		Extension:        model.Extension,
+		IsPrimary:        model.IsPrimary,
+		CreatedAt:        model.CreatedAt.Time,
+		UpdatedAt:        model.UpdatedAt.Time,
 	}
 }
@@ -0,0 +1,177 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pmaojo/kthulu-go/backend/internal/domain"
+	"github.com/pmaojo/kthulu-go/backend/internal/domain/repository"
+	"github.com/pmaojo/kthulu-go/backend/internal/infrastructure/testutils"
+)
+
+func TestContactRepositoryGetContactStatsByPeriod(t *testing.T) {
+	testDB := testutils.SetupTestDB(t)
+	t.Cleanup(func() { testutils.CleanupTestDB(t, testDB) })
+	repo := NewContactRepository(testDB)
+	ctx := context.Background()
+
+	month := func(m time.Month, day int) time.Time { return time.Date(2025, m, day, 10, 0, 0, 0, time.UTC) }
+	contacts := []struct {
+		orgID     uint
+		kind      domain.ContactType
+		createdAt time.Time
+	}{
+		{1, domain.ContactTypeLead, month(time.January, 5)},
+		{1, domain.ContactTypeLead, month(time.January, 20)},
+		{1, domain.ContactTypeCustomer, month(time.January, 31)},
+		{1, domain.ContactTypeCustomer, month(time.March, 1)},
+		{1, domain.ContactTypeLead, month(time.April, 2)},   // outside the period
+		{2, domain.ContactTypeLead, month(time.January, 8)}, // other organization
+	}
+	for _, c := range contacts {
+		require.NoError(t, testDB.Create(&contactModel{
+			OrganizationID: c.orgID,
+			Type:           string(c.kind),
+			CompanyName:    "Acme",
+			IsActive:       true,
+			CreatedAt:      Timestamp{Time: c.createdAt},
+			UpdatedAt:      Timestamp{Time: c.createdAt},
+		}).Error)
+	}
+
+	trend, err := repo.GetContactStatsByPeriod(ctx, 1, time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, time.April, 1, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+
+	require.Len(t, trend.Months, 3)
+	assert.Equal(t, "2025-01", trend.Months[0].Month)
+	assert.Equal(t, int64(3), trend.Months[0].Total)
+	assert.Equal(t, int64(2), trend.Months[0].ByType[domain.ContactTypeLead])
+	assert.Equal(t, int64(1), trend.Months[0].ByType[domain.ContactTypeCustomer])
+
+	assert.Equal(t, "2025-02", trend.Months[1].Month)
+	assert.Equal(t, int64(0), trend.Months[1].Total)
+
+	assert.Equal(t, "2025-03", trend.Months[2].Month)
+	assert.Equal(t, int64(1), trend.Months[2].ByType[domain.ContactTypeCustomer])
+}
+
+func TestContactRepositoryFindPotentialDuplicates(t *testing.T) {
+	testDB := testutils.SetupTestDB(t)
+	t.Cleanup(func() { testutils.CleanupTestDB(t, testDB) })
+	require.NoError(t, testDB.AutoMigrate(&contactPhoneModel{}))
+	repo := NewContactRepository(testDB)
+	ctx := context.Background()
+
+	contacts := []contactModel{
+		{OrganizationID: 1, FirstName: "Jane", LastName: "Doe", Email: "jane.doe@example.com"},
+		{OrganizationID: 1, FirstName: "Jane", LastName: "D.", Email: "Jane.Doe@Example.com"},
+		{OrganizationID: 1, CompanyName: "Initech Ltd"},
+		{OrganizationID: 1, CompanyName: "Inittech"},
+		{OrganizationID: 1, CompanyName: "Umbrella"},
+		{OrganizationID: 1, CompanyName: "Wayne Enterprises"},
+		{OrganizationID: 2, FirstName: "Jane", LastName: "Doe", Email: "jane.doe@example.com"},
+	}
+	for i := range contacts {
+		contacts[i].Type = string(domain.ContactTypeCustomer)
+		contacts[i].IsActive = true
+		require.NoError(t, testDB.Create(&contacts[i]).Error)
+	}
+	// Umbrella and Wayne Enterprises share a number stored on their phones
+	for _, contactID := range []uint{contacts[4].ID, contacts[5].ID} {
+		require.NoError(t, testDB.Create(&contactPhoneModel{ContactID: contactID, Type: string(domain.PhoneTypeWork), Number: "+1 555 010 9999"}).Error)
+	}
+
+	groups, err := repo.FindPotentialDuplicates(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, groups, 3)
+
+	ids := func(group domain.DuplicateGroup) []uint {
+		var result []uint
+		for _, contact := range group.Contacts {
+			result = append(result, contact.ID)
+		}
+		return result
+	}
+	assert.Equal(t, []uint{contacts[0].ID, contacts[1].ID}, ids(groups[0]), "the other organization's Jane Doe is ignored")
+	assert.Equal(t, []domain.DuplicateReason{domain.DuplicateReasonEmail}, groups[0].Reasons)
+	assert.Equal(t, []uint{contacts[2].ID, contacts[3].ID}, ids(groups[1]))
+	assert.Equal(t, []domain.DuplicateReason{domain.DuplicateReasonName}, groups[1].Reasons)
+	assert.Equal(t, []uint{contacts[4].ID, contacts[5].ID}, ids(groups[2]))
+	assert.Equal(t, []domain.DuplicateReason{domain.DuplicateReasonPhone}, groups[2].Reasons)
+	require.Len(t, groups[2].Contacts[0].Phones, 1)
+
+	groups, err = repo.FindPotentialDuplicates(ctx, 3)
+	require.NoError(t, err)
+	assert.Empty(t, groups)
+}
+
+func TestContactRepositoryPhoneKeepsNormalizedNumber(t *testing.T) {
+	testDB := testutils.SetupTestDB(t)
+	t.Cleanup(func() { testutils.CleanupTestDB(t, testDB) })
+	require.NoError(t, testDB.AutoMigrate(&contactPhoneModel{}))
+	repo := NewContactRepository(testDB)
+	ctx := context.Background()
+
+	contact := contactModel{OrganizationID: 1, Type: string(domain.ContactTypeCustomer), CompanyName: "Initech", IsActive: true}
+	require.NoError(t, testDB.Create(&contact).Error)
+
+	phone := &domain.ContactPhone{ContactID: contact.ID, Type: domain.PhoneTypeWork, Number: "(650) 253-0000"}
+	phone.Normalize("US")
+	require.NoError(t, repo.CreatePhone(ctx, phone))
+
+	stored, err := repo.GetPhoneByID(ctx, contact.ID, phone.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "(650) 253-0000", stored.Number)
+	assert.Equal(t, "+16502530000", stored.NormalizedNumber)
+
+	// A number that no longer parses clears the normalized form on update
+	stored.Number = "ext. 12"
+	stored.Normalize("US")
+	require.NoError(t, repo.UpdatePhone(ctx, stored))
+
+	stored, err = repo.GetPhoneByID(ctx, contact.ID, phone.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "ext. 12", stored.Number)
+	assert.Empty(t, stored.NormalizedNumber)
+}
+
+func TestContactRepositoryList_ByIDsWithRelations(t *testing.T) {
+	testDB := testutils.SetupTestDB(t)
+	t.Cleanup(func() { testutils.CleanupTestDB(t, testDB) })
+	require.NoError(t, testDB.AutoMigrate(&contactAddressModel{}, &contactPhoneModel{}))
+	repo := NewContactRepository(testDB)
+	ctx := context.Background()
+
+	contacts := []contactModel{
+		{OrganizationID: 1, CompanyName: "Acme"},
+		{OrganizationID: 1, CompanyName: "Initech"},
+		{OrganizationID: 1, CompanyName: "Umbrella"},
+		{OrganizationID: 2, CompanyName: "Wayne Enterprises"},
+	}
+	for i := range contacts {
+		contacts[i].Type = string(domain.ContactTypeCustomer)
+		require.NoError(t, testDB.Create(&contacts[i]).Error)
+		require.NoError(t, testDB.Create(&contactAddressModel{ContactID: contacts[i].ID, Type: "billing", AddressLine1: "Main St", City: "Springfield", Country: "US"}).Error)
+		require.NoError(t, testDB.Create(&contactPhoneModel{ContactID: contacts[i].ID, Type: "work", Number: "555-0100"}).Error)
+	}
+
+	filters := repository.ContactFilters{IDs: []uint{contacts[0].ID, contacts[2].ID, contacts[3].ID}, IncludeRelations: true, SortBy: "id", SortOrder: "asc"}
+	require.NoError(t, filters.Validate())
+	listed, total, err := repo.List(ctx, 1, filters)
+	require.NoError(t, err)
+
+	require.Equal(t, int64(2), total)
+	require.Len(t, listed, 2)
+	assert.Equal(t, contacts[0].ID, listed[0].ID)
+	assert.Equal(t, contacts[2].ID, listed[1].ID)
+	for _, contact := range listed {
+		require.Len(t, contact.Addresses, 1)
+		assert.Equal(t, "Springfield", contact.Addresses[0].City)
+		require.Len(t, contact.Phones, 1)
+		assert.Equal(t, "555-0100", contact.Phones[0].Number)
+	}
+}
@@ -0,0 +1,72 @@
+// @kthulu:module:notifier
+package db
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/pmaojo/kthulu-go/backend/internal/domain/repository"
+)
+
+// EmailTemplateModel represents the database model for organization email templates
+type EmailTemplateModel struct {
+	OrganizationID   uint   `gorm:"primaryKey"`
+	NotificationType string `gorm:"primaryKey"`
+	Subject          string `gorm:"not null"`
+	HTML             string `gorm:"column:html;not null"`
+	Text             string `gorm:"not null"`
+	UpdatedAt        time.Time
+}
+
+// TableName specifies the table name for EmailTemplateModel
+func (EmailTemplateModel) TableName() string {
+	return "organization_email_templates"
+}
+
+// EmailTemplateRepository provides a database-backed implementation of
+// repository.EmailTemplateRepository.
+type EmailTemplateRepository struct {
+	db *gorm.DB
+}
+
+// NewEmailTemplateRepository creates a new instance bound to a Gorm database.
+func NewEmailTemplateRepository(db *gorm.DB) repository.EmailTemplateRepository {
+	return &EmailTemplateRepository{db: db}
+}
+
+// FindByOrganization returns the organization's template for notificationType,
+// or nil when it keeps the built-in one.
+func (r *EmailTemplateRepository) FindByOrganization(ctx context.Context, organizationID uint, notificationType repository.NotificationType) (*repository.EmailTemplate, error) {
+	var model EmailTemplateModel
+	err := r.db.WithContext(ctx).
+		Where("organization_id = ? AND notification_type = ?", organizationID, string(notificationType)).
+		First(&model).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &repository.EmailTemplate{Subject: model.Subject, HTML: model.HTML, Text: model.Text}, nil
+}
+
+// Save stores the organization's template for notificationType, replacing
+// any earlier one.
+func (r *EmailTemplateRepository) Save(ctx context.Context, organizationID uint, notificationType repository.NotificationType, tmpl repository.EmailTemplate) error {
+	model := EmailTemplateModel{
+		OrganizationID:   organizationID,
+		NotificationType: string(notificationType),
+		Subject:          tmpl.Subject,
+		HTML:             tmpl.HTML,
+		Text:             tmpl.Text,
+		UpdatedAt:        time.Now(),
+	}
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "organization_id"}, {Name: "notification_type"}},
+		DoUpdates: clause.AssignmentColumns([]string{"subject", "html", "text", "updated_at"}),
+	}).Create(&model).Error
+}
@@ -0,0 +1,44 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pmaojo/kthulu-go/backend/internal/domain/repository"
+	"github.com/pmaojo/kthulu-go/backend/internal/infrastructure/testutils"
+)
+
+func TestEmailTemplateRepository(t *testing.T) {
+	testDB := testutils.SetupTestDB(t)
+	defer testutils.CleanupTestDB(t, testDB)
+	require.NoError(t, testDB.Exec(`CREATE TABLE organization_email_templates (
+		organization_id INTEGER NOT NULL,
+		notification_type TEXT NOT NULL,
+		subject TEXT NOT NULL,
+		html TEXT NOT NULL,
+		text TEXT NOT NULL,
+		updated_at DATETIME NOT NULL,
+		PRIMARY KEY (organization_id, notification_type)
+	)`).Error)
+
+	repo := NewEmailTemplateRepository(testDB)
+	ctx := context.Background()
+
+	found, err := repo.FindByOrganization(ctx, 1, repository.NotificationTypeEmailConfirmation)
+	require.NoError(t, err)
+	assert.Nil(t, found, "organizations start with the built-in templates")
+
+	require.NoError(t, repo.Save(ctx, 1, repository.NotificationTypeEmailConfirmation, repository.EmailTemplate{Subject: "v1", HTML: "<p>v1</p>", Text: "v1"}))
+	require.NoError(t, repo.Save(ctx, 1, repository.NotificationTypeEmailConfirmation, repository.EmailTemplate{Subject: "v2", HTML: "<p>v2</p>", Text: "v2"}))
+
+	found, err = repo.FindByOrganization(ctx, 1, repository.NotificationTypeEmailConfirmation)
+	require.NoError(t, err)
+	assert.Equal(t, &repository.EmailTemplate{Subject: "v2", HTML: "<p>v2</p>", Text: "v2"}, found, "saving again replaces the template")
+
+	found, err = repo.FindByOrganization(ctx, 2, repository.NotificationTypeEmailConfirmation)
+	require.NoError(t, err)
+	assert.Nil(t, found, "templates are scoped to their organization")
+}
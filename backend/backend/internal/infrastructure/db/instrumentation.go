@@ -0,0 +1,66 @@
+// @kthulu:core
+package db
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+var (
+	queryDuration    metric.Float64Histogram
+	queryMetricsOnce sync.Once
+)
+
+// initQueryMetrics creates the query duration histogram on the given provider
+func initQueryMetrics(provider metric.MeterProvider) {
+	queryDuration, _ = provider.Meter("kthulu-db").Float64Histogram(
+		"db_query_duration_seconds",
+		metric.WithDescription("Duration of repository database operations"),
+		metric.WithUnit("s"),
+	)
+}
+
+// observeQuery records how long a repository operation took. It is meant to
+// be deferred at the top of the method:
+//
+//	defer observeQuery(ctx, "ProductRepository", "Create", time.Now())
+func observeQuery(ctx context.Context, repository, method string, start time.Time) {
+	queryMetricsOnce.Do(func() {
+		if queryDuration == nil {
+			initQueryMetrics(otel.GetMeterProvider())
+		}
+	})
+	if queryDuration == nil {
+		return
+	}
+
+	queryDuration.Record(ctx, time.Since(start).Seconds(), metric.WithAttributes(
+		attribute.String("repository", repository),
+		attribute.String("method", method),
+	))
+}
+
+// contextCheckInterval is how many rows a scan loop reads between checks of
+// its context
+const contextCheckInterval = 100
+
+// checkContext returns ctx's error every contextCheckInterval rows so long
+// scans stop once the request is cancelled. It is meant to be called at the
+// top of a row loop:
+//
+//	for scanned := 0; rows.Next(); scanned++ {
+//		if err := checkContext(ctx, scanned); err != nil {
+//			return nil, err
+//		}
+//		...
+func checkContext(ctx context.Context, scanned int) error {
+	if scanned%contextCheckInterval != 0 {
+		return nil
+	}
+	return ctx.Err()
+}
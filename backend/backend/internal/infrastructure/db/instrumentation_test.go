@@ -0,0 +1,46 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestObserveQueryRecordsRepositoryDuration(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	initQueryMetrics(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)))
+
+	repo, _ := newTestProductRepository(t)
+	_, err := repo.GetPricesByProductID(context.Background(), 1)
+	require.NoError(t, err)
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &rm))
+
+	var histogram *metricdata.Histogram[float64]
+	for _, scope := range rm.ScopeMetrics {
+		for _, m := range scope.Metrics {
+			if m.Name == "db_query_duration_seconds" {
+				h, ok := m.Data.(metricdata.Histogram[float64])
+				require.True(t, ok)
+				histogram = &h
+			}
+		}
+	}
+	require.NotNil(t, histogram, "query duration histogram not exported")
+
+	var samples uint64
+	for _, point := range histogram.DataPoints {
+		repository, _ := point.Attributes.Value(attribute.Key("repository"))
+		method, _ := point.Attributes.Value(attribute.Key("method"))
+		if repository.AsString() == "ProductRepository" && method.AsString() == "GetPricesByProductID" {
+			samples += point.Count
+		}
+	}
+	assert.GreaterOrEqual(t, samples, uint64(1))
+}
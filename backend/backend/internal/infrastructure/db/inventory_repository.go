@@ -3,6 +3,8 @@ package db
 
 import (
 	"context"
+	"fmt"
+	"time"
 
 	"gorm.io/gorm"
 
@@ -185,6 +187,188 @@ func (r *inventoryRepository) GetStockMovementsByWarehouse(ctx context.Context,
 	return movements, int(total), err
 }
 
+// Inventory ledger operations
+func (r *inventoryRepository) AdjustStockLevel(ctx context.Context, item *domain.InventoryItem, movement *domain.InventoryMovement, reservedDelta int) error {
+	delta := 0
+	if movement != nil {
+		delta = movement.Delta
+	}
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Only the stock columns are written, leaving the item's relations
+		// alone. The quantity moves by the delta so concurrent adjustments
+		// all count, and the guard keeps them from taking it below zero.
+		// The reserved quantity is only written when it moves, the same way,
+		// so an adjustment can't undo a reservation made since item was read;
+		// it can't be released below zero or reserved beyond the quantity.
+		item.UpdatedAt = time.Now()
+		updates := map[string]interface{}{
+			"quantity":        gorm.Expr("quantity + ?", delta),
+			"unit_cost":       item.UnitCost,
+			"last_stock_date": item.LastStockDate,
+			"updated_at":      item.UpdatedAt,
+		}
+		query := tx.Table("inventory_items").Where("id = ? AND quantity + ? >= 0", item.ID, delta)
+		if reservedDelta < 0 {
+			updates["reserved_quantity"] = gorm.Expr("reserved_quantity + ?", reservedDelta)
+			query = query.Where("reserved_quantity + ? >= 0", reservedDelta)
+		} else if reservedDelta > 0 {
+			updates["reserved_quantity"] = gorm.Expr("reserved_quantity + ?", reservedDelta)
+			query = query.Where("quantity + ? >= reserved_quantity + ?", delta, reservedDelta)
+		}
+		result := query.Updates(updates)
+		if result.Error != nil {
+			return fmt.Errorf("failed to update stock level: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return domain.ErrInsufficientStock
+		}
+		var stored struct {
+			Quantity         int
+			ReservedQuantity int
+		}
+		if err := tx.Table("inventory_items").Select("quantity, reserved_quantity").Where("id = ?", item.ID).Scan(&stored).Error; err != nil {
+			return fmt.Errorf("failed to load stock level: %w", err)
+		}
+		item.Quantity, item.ReservedQuantity = stored.Quantity, stored.ReservedQuantity
+		if movement == nil {
+			return nil
+		}
+
+		movement.ProductID = item.ProductID
+		movement.WarehouseID = item.WarehouseID
+		if err := tx.Create(movement).Error; err != nil {
+			return fmt.Errorf("failed to record inventory movement: %w", err)
+		}
+		return nil
+	})
+}
+
+func (r *inventoryRepository) ListMovements(ctx context.Context, productID uint, filters repository.InventoryMovementFilters) ([]domain.InventoryMovement, int, error) {
+	var movements []domain.InventoryMovement
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&domain.InventoryMovement{}).
+		Where("product_id = ?", productID)
+
+	if filters.WarehouseID != nil {
+		query = query.Where("warehouse_id = ?", *filters.WarehouseID)
+	}
+	if filters.From != nil {
+		query = query.Where("created_at >= ?", *filters.From)
+	}
+	if filters.To != nil {
+		query = query.Where("created_at < ?", *filters.To)
+	}
+
+	// Get total count
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// The ledger reads oldest first so running totals can be computed
+	query = query.Order("created_at ASC").Order("id ASC")
+	if filters.PageSize > 0 {
+		page := filters.Page
+		if page < 1 {
+			page = 1
+		}
+		query = query.Offset((page - 1) * filters.PageSize).Limit(filters.PageSize)
+	}
+	err := query.Find(&movements).Error
+
+	return movements, int(total), err
+}
+
+// Stock reservation operations
+func (r *inventoryRepository) ReserveStock(ctx context.Context, productID uint, qty int, ref string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var items []struct {
+			ID        uint
+			Available int
+		}
+		err := tx.Table("inventory_items").
+			Select("id, quantity - reserved_quantity AS available").
+			Where("product_id = ? AND quantity - reserved_quantity > 0", productID).
+			Order("id ASC").
+			Scan(&items).Error
+		if err != nil {
+			return fmt.Errorf("failed to load stock levels: %w", err)
+		}
+
+		// Reserve from each warehouse in turn until the quantity is covered
+		remaining := qty
+		for _, item := range items {
+			if remaining == 0 {
+				break
+			}
+			take := item.Available
+			if take > remaining {
+				take = remaining
+			}
+
+			// The guard keeps concurrent reservations from overselling the item
+			result := tx.Table("inventory_items").
+				Where("id = ? AND quantity - reserved_quantity >= ?", item.ID, take).
+				Updates(map[string]interface{}{
+					"reserved_quantity": gorm.Expr("reserved_quantity + ?", take),
+					"updated_at":        time.Now(),
+				})
+			if result.Error != nil {
+				return fmt.Errorf("failed to reserve stock: %w", result.Error)
+			}
+			if result.RowsAffected == 0 {
+				return domain.ErrInsufficientStock
+			}
+
+			reservation := &domain.StockReservation{
+				InventoryItemID: item.ID,
+				ProductID:       productID,
+				Quantity:        take,
+				Reference:       ref,
+			}
+			if err := tx.Create(reservation).Error; err != nil {
+				return fmt.Errorf("failed to record stock reservation: %w", err)
+			}
+			remaining -= take
+		}
+
+		if remaining > 0 {
+			return domain.ErrInsufficientStock
+		}
+		return nil
+	})
+}
+
+func (r *inventoryRepository) ReleaseReservation(ctx context.Context, ref string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var reservations []domain.StockReservation
+		if err := tx.Where("reference = ?", ref).Find(&reservations).Error; err != nil {
+			return fmt.Errorf("failed to load stock reservations: %w", err)
+		}
+		if len(reservations) == 0 {
+			return domain.ErrReservationNotFound
+		}
+
+		for _, reservation := range reservations {
+			err := tx.Table("inventory_items").
+				Where("id = ?", reservation.InventoryItemID).
+				Updates(map[string]interface{}{
+					"reserved_quantity": gorm.Expr("CASE WHEN reserved_quantity > ? THEN reserved_quantity - ? ELSE 0 END", reservation.Quantity, reservation.Quantity),
+					"updated_at":        time.Now(),
+				}).Error
+			if err != nil {
+				return fmt.Errorf("failed to release stock reservation: %w", err)
+			}
+		}
+
+		if err := tx.Where("reference = ?", ref).Delete(&domain.StockReservation{}).Error; err != nil {
+			return fmt.Errorf("failed to delete stock reservations: %w", err)
+		}
+		return nil
+	})
+}
+
 // Stock adjustment operations
 func (r *inventoryRepository) CreateStockAdjustment(ctx context.Context, adjustment *domain.StockAdjustment) error {
 	return r.db.WithContext(ctx).Create(adjustment).Error
@@ -217,7 +401,7 @@ func (r *inventoryRepository) GetLowStockItems(ctx context.Context, warehouseID
 	query := r.db.WithContext(ctx).
 		Preload("Warehouse").
 		Preload("Product").
-		Where("quantity <= reorder_point")
+		Where("quantity - reserved_quantity <= reorder_point")
 
 	if warehouseID != nil {
 		query = query.Where("warehouse_id = ?", *warehouseID)
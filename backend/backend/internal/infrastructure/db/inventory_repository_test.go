@@ -0,0 +1,247 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/pmaojo/kthulu-go/backend/internal/domain"
+	"github.com/pmaojo/kthulu-go/backend/internal/domain/repository"
+	"github.com/pmaojo/kthulu-go/backend/internal/infrastructure/testutils"
+)
+
+// setupInventoryTables creates the inventory tables used by InventoryRepository tests.
+func setupInventoryTables(t *testing.T, db *gorm.DB) {
+	err := db.Exec(`
+		CREATE TABLE inventory_items (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			warehouse_id INTEGER NOT NULL,
+			product_id INTEGER NOT NULL,
+			sku TEXT NOT NULL,
+			quantity INTEGER DEFAULT 0,
+			reserved_quantity INTEGER DEFAULT 0,
+			minimum_stock INTEGER DEFAULT 0,
+			maximum_stock INTEGER DEFAULT 0,
+			reorder_point INTEGER DEFAULT 0,
+			reorder_quantity INTEGER DEFAULT 0,
+			unit_cost REAL DEFAULT 0,
+			last_stock_date DATETIME,
+			created_at DATETIME,
+			updated_at DATETIME
+		);
+
+		CREATE TABLE inventory_movements (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			product_id INTEGER NOT NULL,
+			variant_id INTEGER,
+			warehouse_id INTEGER NOT NULL,
+			delta INTEGER NOT NULL,
+			reason TEXT NOT NULL,
+			reference TEXT,
+			created_by INTEGER,
+			created_at DATETIME NOT NULL
+		);
+
+		CREATE TABLE stock_reservations (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			inventory_item_id INTEGER NOT NULL,
+			product_id INTEGER NOT NULL,
+			quantity INTEGER NOT NULL,
+			reference TEXT NOT NULL,
+			created_at DATETIME NOT NULL
+		);
+	`).Error
+	require.NoError(t, err)
+}
+
+func newTestInventoryRepository(t *testing.T) (repository.InventoryRepository, *gorm.DB) {
+	testDB := testutils.SetupTestDB(t)
+	t.Cleanup(func() { testutils.CleanupTestDB(t, testDB) })
+	setupInventoryTables(t, testDB)
+	return NewInventoryRepository(testDB), testDB
+}
+
+// createTestInventoryItem inserts a stock line for product 7 in the given warehouse.
+func createTestInventoryItem(t *testing.T, db *gorm.DB, warehouseID uint, quantity int) *domain.InventoryItem {
+	err := db.Exec(`INSERT INTO inventory_items (warehouse_id, product_id, sku, quantity) VALUES (?, 7, 'SKU-7', ?)`, warehouseID, quantity).Error
+	require.NoError(t, err)
+
+	item := &domain.InventoryItem{WarehouseID: warehouseID, ProductID: 7, SKU: "SKU-7", Quantity: quantity}
+	require.NoError(t, db.Raw(`SELECT id FROM inventory_items WHERE product_id = 7 AND warehouse_id = ?`, warehouseID).Scan(&item.ID).Error)
+	return item
+}
+
+// reservedQuantity reads the reserved quantity stored for an inventory item.
+func reservedQuantity(t *testing.T, db *gorm.DB, itemID uint) int {
+	var reserved int
+	require.NoError(t, db.Raw(`SELECT reserved_quantity FROM inventory_items WHERE id = ?`, itemID).Scan(&reserved).Error)
+	return reserved
+}
+
+func TestInventoryRepositoryAdjustStockLevel_LedgerMatchesStock(t *testing.T) {
+	repo, testDB := newTestInventoryRepository(t)
+	ctx := context.Background()
+	item := createTestInventoryItem(t, testDB, 1, 0)
+
+	for _, delta := range []int{20, -5, 12, -9} {
+		item.Quantity += delta
+		movement := &domain.InventoryMovement{Delta: delta, Reason: string(domain.StockMovementTypeAdjustment)}
+		require.NoError(t, repo.AdjustStockLevel(ctx, item, movement, 0))
+	}
+
+	movements, total, err := repo.ListMovements(ctx, 7, repository.InventoryMovementFilters{})
+	require.NoError(t, err)
+	assert.Equal(t, 4, total)
+
+	sum := 0
+	for _, movement := range movements {
+		assert.Equal(t, uint(1), movement.WarehouseID)
+		sum += movement.Delta
+	}
+
+	var stored int
+	require.NoError(t, testDB.Raw(`SELECT quantity FROM inventory_items WHERE id = ?`, item.ID).Scan(&stored).Error)
+	assert.Equal(t, stored, sum)
+	assert.Equal(t, 18, sum)
+}
+
+func TestInventoryRepositoryAdjustStockLevel_AppliesConcurrentDeltas(t *testing.T) {
+	repo, testDB := newTestInventoryRepository(t)
+	ctx := context.Background()
+	item := createTestInventoryItem(t, testDB, 1, 10)
+	variantID := uint(3)
+
+	// Two sales of 4 units computed from the same read both count
+	first, second := *item, *item
+	first.Quantity -= 4
+	require.NoError(t, repo.AdjustStockLevel(ctx, &first, &domain.InventoryMovement{VariantID: &variantID, Delta: -4, Reason: string(domain.StockMovementTypeSale)}, 0))
+	second.Quantity -= 4
+	require.NoError(t, repo.AdjustStockLevel(ctx, &second, &domain.InventoryMovement{Delta: -4, Reason: string(domain.StockMovementTypeSale)}, 0))
+	assert.Equal(t, 2, second.Quantity)
+
+	// A third one would oversell the item
+	third := *item
+	third.Quantity -= 4
+	assert.ErrorIs(t, repo.AdjustStockLevel(ctx, &third, &domain.InventoryMovement{Delta: -4, Reason: string(domain.StockMovementTypeSale)}, 0), domain.ErrInsufficientStock)
+
+	var stored int
+	require.NoError(t, testDB.Raw(`SELECT quantity FROM inventory_items WHERE id = ?`, item.ID).Scan(&stored).Error)
+	assert.Equal(t, 2, stored)
+	movements, total, err := repo.ListMovements(ctx, 7, repository.InventoryMovementFilters{})
+	require.NoError(t, err)
+	require.Equal(t, 2, total)
+	require.NotNil(t, movements[0].VariantID)
+	assert.Equal(t, variantID, *movements[0].VariantID)
+}
+
+func TestInventoryRepositoryAdjustStockLevel_KeepsConcurrentReservations(t *testing.T) {
+	repo, testDB := newTestInventoryRepository(t)
+	ctx := context.Background()
+	item := createTestInventoryItem(t, testDB, 1, 10)
+
+	// A stock update reads the item, a reservation lands, then the update
+	// writes what it computed from its read
+	read := *item
+	require.NoError(t, repo.ReserveStock(ctx, 7, 3, "QUOTE-1"))
+	read.Quantity += 5
+	require.NoError(t, repo.AdjustStockLevel(ctx, &read, &domain.InventoryMovement{Delta: 5, Reason: string(domain.StockMovementTypeReceive)}, 0))
+	assert.Equal(t, 3, reservedQuantity(t, testDB, item.ID), "a receipt must not undo the reservation")
+	assert.Equal(t, 15, read.Quantity)
+	assert.Equal(t, 3, read.ReservedQuantity, "the stored reservation is loaded back")
+
+	// Reserve and release movements move the reservation relative to what is
+	// stored, not to what the update read
+	stale := *item
+	require.NoError(t, repo.AdjustStockLevel(ctx, &stale, nil, 4))
+	assert.Equal(t, 7, reservedQuantity(t, testDB, item.ID))
+	require.NoError(t, repo.ReserveStock(ctx, 7, 2, "QUOTE-2"))
+	require.NoError(t, repo.AdjustStockLevel(ctx, &stale, nil, -4))
+	assert.Equal(t, 5, reservedQuantity(t, testDB, item.ID))
+
+	// The guards hold against the stored quantities
+	assert.ErrorIs(t, repo.AdjustStockLevel(ctx, &stale, nil, -6), domain.ErrInsufficientStock, "releasing more than is reserved")
+	assert.ErrorIs(t, repo.AdjustStockLevel(ctx, &stale, nil, 11), domain.ErrInsufficientStock, "reserving more than is on hand")
+	assert.Equal(t, 5, reservedQuantity(t, testDB, item.ID))
+}
+
+func TestInventoryRepositoryListMovements_FiltersByDate(t *testing.T) {
+	repo, testDB := newTestInventoryRepository(t)
+	ctx := context.Background()
+	item := createTestInventoryItem(t, testDB, 1, 0)
+
+	days := []time.Time{
+		time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC),
+		time.Date(2024, 3, 2, 9, 0, 0, 0, time.UTC),
+		time.Date(2024, 3, 3, 9, 0, 0, 0, time.UTC),
+	}
+	for i, day := range days {
+		item.Quantity++
+		movement := &domain.InventoryMovement{Delta: 1, Reason: string(domain.StockMovementTypeReceive), Reference: string(rune('A' + i)), CreatedAt: day}
+		require.NoError(t, repo.AdjustStockLevel(ctx, item, movement, 0))
+	}
+
+	from := time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 3, 3, 0, 0, 0, 0, time.UTC)
+	movements, total, err := repo.ListMovements(ctx, 7, repository.InventoryMovementFilters{From: &from, To: &to})
+	require.NoError(t, err)
+	require.Equal(t, 1, total)
+	assert.Equal(t, "B", movements[0].Reference)
+
+	movements, total, err = repo.ListMovements(ctx, 7, repository.InventoryMovementFilters{From: &from})
+	require.NoError(t, err)
+	assert.Equal(t, 2, total)
+	assert.Equal(t, []string{"B", "C"}, []string{movements[0].Reference, movements[1].Reference})
+}
+
+func TestInventoryRepositoryReserveStock_SpansWarehouses(t *testing.T) {
+	repo, testDB := newTestInventoryRepository(t)
+	ctx := context.Background()
+	main := createTestInventoryItem(t, testDB, 1, 10)
+	overflow := createTestInventoryItem(t, testDB, 2, 5)
+
+	require.NoError(t, repo.ReserveStock(ctx, 7, 4, "QUOTE-1"))
+	require.NoError(t, repo.ReserveStock(ctx, 7, 8, "INV-1"))
+
+	// On-hand stock is untouched; INV-1 takes the rest of warehouse 1 first
+	assert.Equal(t, 10, reservedQuantity(t, testDB, main.ID))
+	assert.Equal(t, 2, reservedQuantity(t, testDB, overflow.ID))
+
+	var onHand int
+	require.NoError(t, testDB.Raw(`SELECT SUM(quantity) FROM inventory_items`).Scan(&onHand).Error)
+	assert.Equal(t, 15, onHand)
+}
+
+func TestInventoryRepositoryReleaseReservation(t *testing.T) {
+	repo, testDB := newTestInventoryRepository(t)
+	ctx := context.Background()
+	item := createTestInventoryItem(t, testDB, 1, 10)
+
+	require.NoError(t, repo.ReserveStock(ctx, 7, 3, "QUOTE-1"))
+	require.NoError(t, repo.ReserveStock(ctx, 7, 4, "QUOTE-2"))
+	require.NoError(t, repo.ReleaseReservation(ctx, "QUOTE-1"))
+	assert.Equal(t, 4, reservedQuantity(t, testDB, item.ID))
+
+	// Released stock can be reserved again, but the reference is gone
+	require.NoError(t, repo.ReserveStock(ctx, 7, 6, "QUOTE-3"))
+	assert.ErrorIs(t, repo.ReleaseReservation(ctx, "QUOTE-1"), domain.ErrReservationNotFound)
+}
+
+func TestInventoryRepositoryReserveStock_RejectsMoreThanAvailable(t *testing.T) {
+	repo, testDB := newTestInventoryRepository(t)
+	ctx := context.Background()
+	main := createTestInventoryItem(t, testDB, 1, 5)
+	overflow := createTestInventoryItem(t, testDB, 2, 3)
+
+	require.NoError(t, repo.ReserveStock(ctx, 7, 2, "QUOTE-1"))
+	err := repo.ReserveStock(ctx, 7, 7, "QUOTE-2")
+	assert.ErrorIs(t, err, domain.ErrInsufficientStock)
+
+	// The failed reservation leaves nothing behind
+	assert.Equal(t, 2, reservedQuantity(t, testDB, main.ID))
+	assert.Equal(t, 0, reservedQuantity(t, testDB, overflow.ID))
+	assert.ErrorIs(t, repo.ReleaseReservation(ctx, "QUOTE-2"), domain.ErrReservationNotFound)
+}
@@ -5,6 +5,9 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -33,20 +36,65 @@ func scanInvoice(s scanner, invoice *domain.Invoice) error {
 
 // InvoiceRepository implements the invoice repository interface using SQL
 type InvoiceRepository struct {
-	db     *sql.DB
-	logger core.Logger
+	db      *sql.DB
+	tx      *sql.Tx // set on repositories bound to a unit of work
+	replica *sql.DB
+	logger  core.Logger
+	audit   repository.AuditLogger
 }
 
-// NewInvoiceRepository creates a new invoice repository instance
-func NewInvoiceRepository(db *sql.DB, logger core.Logger) repository.InvoiceRepository {
-	return &InvoiceRepository{
+// NewInvoiceRepository creates a new invoice repository instance.
+// The audit logger is optional; invoice mutations are not audited when it is nil.
+// List and stats queries go to the read replica when one is configured.
+func NewInvoiceRepository(db *sql.DB, logger core.Logger, audit repository.AuditLogger, replica *core.ReadReplica) repository.InvoiceRepository {
+	r := &InvoiceRepository{
 		db:     db,
 		logger: logger,
+		audit:  audit,
 	}
+	if replica != nil {
+		r.replica = replica.DB
+	}
+	return r
+}
+
+// conn returns the unit of work transaction when the repository is bound to
+// one, and the pool otherwise
+func (r *InvoiceRepository) conn() sqlConn {
+	if r.tx != nil {
+		return r.tx
+	}
+	return r.db
+}
+
+// reader returns the handle for read-only invoice queries that tolerate
+// replication lag. Inside a unit of work reads see its own writes; outside
+// one they are retried after transient errors.
+func (r *InvoiceRepository) reader() sqlConn {
+	if r.tx != nil {
+		return r.tx
+	}
+	if r.replica != nil {
+		return retryingReader{r.replica}
+	}
+	return retryingReader{r.db}
+}
+
+// readConn returns the handle for read-only invoice queries that must see the
+// latest writes, retried like the reader ones.
+func (r *InvoiceRepository) readConn() sqlConn {
+	if r.tx != nil {
+		return r.tx
+	}
+	return retryingReader{r.db}
 }
 
 // Create creates a new invoice
 func (r *InvoiceRepository) Create(ctx context.Context, invoice *domain.Invoice) error {
+	defer observeQuery(ctx, "InvoiceRepository", "Create", time.Now())
+	ctx, cancel := core.WithStatementTimeout(ctx)
+	defer cancel()
+
 	query := fmt.Sprintf(`
                 INSERT INTO invoices (
                         organization_id, contact_id, invoice_number, type, status, currency,
@@ -57,7 +105,7 @@ func (r *InvoiceRepository) Create(ctx context.Context, invoice *domain.Invoice)
                         $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21
                 ) RETURNING %s`, invoiceColumns)
 
-	row := r.db.QueryRowContext(ctx, query,
+	row := r.conn().QueryRowContext(ctx, query,
 		invoice.OrganizationID, invoice.ContactID, invoice.InvoiceNumber,
 		invoice.Type, invoice.Status, invoice.Currency, invoice.ExchangeRate,
 		invoice.Subtotal, invoice.TaxAmount, invoice.DiscountAmount,
@@ -76,19 +124,24 @@ func (r *InvoiceRepository) Create(ctx context.Context, invoice *domain.Invoice)
 		return fmt.Errorf("failed to create invoice: %w", err)
 	}
 
+	recordAudit(ctx, r.audit, r.logger, domain.AuditActionCreate, "invoice", invoice.OrganizationID, invoice.ID, nil, invoice)
 	r.logger.Info("Invoice created successfully", "invoiceId", invoice.ID, "invoiceNumber", invoice.InvoiceNumber)
 	return nil
 }
 
 // GetByID retrieves an invoice by ID within an organization
 func (r *InvoiceRepository) GetByID(ctx context.Context, organizationID, invoiceID uint) (*domain.Invoice, error) {
+	defer observeQuery(ctx, "InvoiceRepository", "GetByID", time.Now())
+	ctx, cancel := core.WithStatementTimeout(ctx)
+	defer cancel()
+
 	query := fmt.Sprintf(
 		"SELECT %s FROM invoices WHERE id = $1 AND organization_id = $2",
 		invoiceColumns,
 	)
 
 	invoice := &domain.Invoice{}
-	err := scanInvoice(r.db.QueryRowContext(ctx, query, invoiceID, organizationID), invoice)
+	err := scanInvoice(r.readConn().QueryRowContext(ctx, query, invoiceID, organizationID), invoice)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -103,13 +156,17 @@ func (r *InvoiceRepository) GetByID(ctx context.Context, organizationID, invoice
 
 // GetByNumber retrieves an invoice by number within an organization
 func (r *InvoiceRepository) GetByNumber(ctx context.Context, organizationID uint, invoiceNumber string) (*domain.Invoice, error) {
+	defer observeQuery(ctx, "InvoiceRepository", "GetByNumber", time.Now())
+	ctx, cancel := core.WithStatementTimeout(ctx)
+	defer cancel()
+
 	query := fmt.Sprintf(
 		"SELECT %s FROM invoices WHERE invoice_number = $1 AND organization_id = $2",
 		invoiceColumns,
 	)
 
 	invoice := &domain.Invoice{}
-	err := scanInvoice(r.db.QueryRowContext(ctx, query, invoiceNumber, organizationID), invoice)
+	err := scanInvoice(r.readConn().QueryRowContext(ctx, query, invoiceNumber, organizationID), invoice)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -124,26 +181,141 @@ func (r *InvoiceRepository) GetByNumber(ctx context.Context, organizationID uint
 
 // Update updates an existing invoice
 func (r *InvoiceRepository) Update(ctx context.Context, invoice *domain.Invoice) error {
-	query := `
-		UPDATE invoices SET 
-			contact_id = $2, type = $3, status = $4, currency = $5,
-			exchange_rate = $6, subtotal = $7, tax_amount = $8, discount_amount = $9,
-			total_amount = $10, paid_amount = $11, balance_due = $12,
-			issue_date = $13, due_date = $14, payment_terms = $15,
-			notes = $16, terms_conditions = $17, updated_at = $18
-		WHERE id = $1 AND organization_id = $19`
+	defer observeQuery(ctx, "InvoiceRepository", "Update", time.Now())
+	ctx, cancel := core.WithStatementTimeout(ctx)
+	defer cancel()
+
+	var before *domain.Invoice
+	if r.audit != nil {
+		before, _ = r.GetByID(ctx, invoice.OrganizationID, invoice.ID)
+	}
+
+	result, err := r.conn().ExecContext(ctx, updateInvoiceQuery, updateInvoiceArgs(invoice)...)
+	if err := checkInvoiceUpdated(result, err); err != nil {
+		r.logger.Error("Failed to update invoice", "error", err, "invoiceId", invoice.ID)
+		return err
+	}
+
+	recordAudit(ctx, r.audit, r.logger, domain.AuditActionUpdate, "invoice", invoice.OrganizationID, invoice.ID, before, invoice)
+	r.logger.Info("Invoice updated successfully", "invoiceId", invoice.ID)
+	return nil
+}
+
+// UpdateFields updates only the given columns of an invoice. Column names are
+// checked against repository.InvoicePatchableFields before they reach the
+// query.
+func (r *InvoiceRepository) UpdateFields(ctx context.Context, organizationID, invoiceID uint, fields map[string]any) error {
+	defer observeQuery(ctx, "InvoiceRepository", "UpdateFields", time.Now())
+	ctx, cancel := core.WithStatementTimeout(ctx)
+	defer cancel()
+
+	if len(fields) == 0 {
+		return fmt.Errorf("%w: no fields to update", domain.ErrInvalidInvoiceField)
+	}
+	columns := make([]string, 0, len(fields))
+	for column := range fields {
+		if !repository.InvoicePatchableFields[column] {
+			return fmt.Errorf("%w: %s cannot be updated", domain.ErrInvalidInvoiceField, column)
+		}
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+
+	var before *domain.Invoice
+	if r.audit != nil {
+		before, _ = r.GetByID(ctx, organizationID, invoiceID)
+	}
+
+	assignments := make([]string, 0, len(columns)+1)
+	args := make([]any, 0, len(columns)+3)
+	for _, column := range columns {
+		args = append(args, fields[column])
+		assignments = append(assignments, fmt.Sprintf("%s = $%d", column, len(args)))
+	}
+	args = append(args, time.Now())
+	assignments = append(assignments, fmt.Sprintf("updated_at = $%d", len(args)))
+	args = append(args, invoiceID, organizationID)
+
+	query := fmt.Sprintf("UPDATE invoices SET %s WHERE id = $%d AND organization_id = $%d",
+		strings.Join(assignments, ", "), len(args)-1, len(args))
+
+	result, err := r.conn().ExecContext(ctx, query, args...)
+	if err := checkInvoiceUpdated(result, err); err != nil {
+		r.logger.Error("Failed to update invoice fields", "error", err, "invoiceId", invoiceID, "fields", columns)
+		return err
+	}
+
+	if r.audit != nil {
+		after, _ := r.GetByID(ctx, organizationID, invoiceID)
+		recordAudit(ctx, r.audit, r.logger, domain.AuditActionUpdate, "invoice", organizationID, invoiceID, before, after)
+	}
+	r.logger.Info("Invoice fields updated successfully", "invoiceId", invoiceID, "fields", columns)
+	return nil
+}
+
+// UpdateWithEvent updates an invoice and stores the outbox event it emits in
+// the same transaction, so the event is published only if the update commits.
+func (r *InvoiceRepository) UpdateWithEvent(ctx context.Context, invoice *domain.Invoice, event *domain.OutboxEvent) error {
+	defer observeQuery(ctx, "InvoiceRepository", "UpdateWithEvent", time.Now())
+	ctx, cancel := core.WithStatementTimeout(ctx)
+	defer cancel()
+
+	var before *domain.Invoice
+	if r.audit != nil {
+		before, _ = r.GetByID(ctx, invoice.OrganizationID, invoice.ID)
+	}
+
+	tx, err := beginTx(ctx, r.db, r.tx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, updateInvoiceQuery, updateInvoiceArgs(invoice)...)
+	if err := checkInvoiceUpdated(result, err); err != nil {
+		r.logger.Error("Failed to update invoice", "error", err, "invoiceId", invoice.ID)
+		return err
+	}
+
+	if err := insertOutboxEvent(ctx, tx, event); err != nil {
+		r.logger.Error("Failed to store invoice event", "error", err, "invoiceId", invoice.ID, "event", event.Event)
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit invoice update transaction: %w", err)
+	}
 
-	result, err := r.db.ExecContext(ctx, query,
-		invoice.ID, invoice.ContactID, invoice.Type, invoice.Status,
+	recordAudit(ctx, r.audit, r.logger, domain.AuditActionUpdate, "invoice", invoice.OrganizationID, invoice.ID, before, invoice)
+	r.logger.Info("Invoice updated successfully", "invoiceId", invoice.ID, "event", event.Event)
+	return nil
+}
+
+// updateInvoiceQuery numbers its placeholders in the order they appear, as
+// SQLite binds "$N" parameters by position rather than by number
+const updateInvoiceQuery = `
+		UPDATE invoices SET 
+			contact_id = $1, type = $2, status = $3, currency = $4,
+			exchange_rate = $5, subtotal = $6, tax_amount = $7, discount_amount = $8,
+			total_amount = $9, paid_amount = $10, balance_due = $11,
+			issue_date = $12, due_date = $13, payment_terms = $14,
+			notes = $15, terms_conditions = $16, updated_at = $17
+		WHERE id = $18 AND organization_id = $19`
+
+func updateInvoiceArgs(invoice *domain.Invoice) []any {
+	return []any{
+		invoice.ContactID, invoice.Type, invoice.Status,
 		invoice.Currency, invoice.ExchangeRate, invoice.Subtotal,
 		invoice.TaxAmount, invoice.DiscountAmount, invoice.TotalAmount,
 		invoice.PaidAmount, invoice.BalanceDue, invoice.IssueDate,
 		invoice.DueDate, invoice.PaymentTerms, invoice.Notes,
-		invoice.TermsConditions, time.Now(), invoice.OrganizationID,
-	)
+		invoice.TermsConditions, time.Now(), invoice.ID, invoice.OrganizationID,
+	}
+}
 
+// checkInvoiceUpdated turns the result of updateInvoiceQuery into an error
+func checkInvoiceUpdated(result sql.Result, err error) error {
 	if err != nil {
-		r.logger.Error("Failed to update invoice", "error", err, "invoiceId", invoice.ID)
 		return fmt.Errorf("failed to update invoice: %w", err)
 	}
 
@@ -155,16 +327,23 @@ func (r *InvoiceRepository) Update(ctx context.Context, invoice *domain.Invoice)
 	if rowsAffected == 0 {
 		return domain.ErrInvoiceNotFound
 	}
-
-	r.logger.Info("Invoice updated successfully", "invoiceId", invoice.ID)
 	return nil
 }
 
 // Delete deletes an invoice
 func (r *InvoiceRepository) Delete(ctx context.Context, organizationID, invoiceID uint) error {
+	defer observeQuery(ctx, "InvoiceRepository", "Delete", time.Now())
+	ctx, cancel := core.WithStatementTimeout(ctx)
+	defer cancel()
+
+	var before *domain.Invoice
+	if r.audit != nil {
+		before, _ = r.GetByID(ctx, organizationID, invoiceID)
+	}
+
 	query := `DELETE FROM invoices WHERE id = $1 AND organization_id = $2`
 
-	result, err := r.db.ExecContext(ctx, query, invoiceID, organizationID)
+	result, err := r.conn().ExecContext(ctx, query, invoiceID, organizationID)
 	if err != nil {
 		r.logger.Error("Failed to delete invoice", "error", err, "invoiceId", invoiceID)
 		return fmt.Errorf("failed to delete invoice: %w", err)
@@ -179,12 +358,17 @@ func (r *InvoiceRepository) Delete(ctx context.Context, organizationID, invoiceI
 		return domain.ErrInvoiceNotFound
 	}
 
+	recordAudit(ctx, r.audit, r.logger, domain.AuditActionDelete, "invoice", organizationID, invoiceID, before, nil)
 	r.logger.Info("Invoice deleted successfully", "invoiceId", invoiceID)
 	return nil
 }
 
 // List retrieves invoices with filtering and pagination
 func (r *InvoiceRepository) List(ctx context.Context, organizationID uint, filters repository.InvoiceFilters) ([]*domain.Invoice, int64, error) {
+	defer observeQuery(ctx, "InvoiceRepository", "List", time.Now())
+	ctx, cancel := core.WithStatementTimeout(ctx)
+	defer cancel()
+
 	// Validate filters
 	if err := filters.Validate(); err != nil {
 		return nil, 0, err
@@ -196,7 +380,7 @@ func (r *InvoiceRepository) List(ctx context.Context, organizationID uint, filte
 	// Count query
 	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM invoices %s", whereClause)
 	var total int64
-	err := r.db.QueryRowContext(ctx, countQuery, args...).Scan(&total)
+	err := r.reader().QueryRowContext(ctx, countQuery, args...).Scan(&total)
 	if err != nil {
 		r.logger.Error("Failed to count invoices", "error", err)
 		return nil, 0, fmt.Errorf("failed to count invoices: %w", err)
@@ -211,7 +395,7 @@ func (r *InvoiceRepository) List(ctx context.Context, organizationID uint, filte
 		invoiceColumns, whereClause, orderClause, limitClause,
 	)
 
-	rows, err := r.db.QueryContext(ctx, query, args...)
+	rows, err := r.reader().QueryContext(ctx, query, args...)
 	if err != nil {
 		r.logger.Error("Failed to list invoices", "error", err)
 		return nil, 0, fmt.Errorf("failed to list invoices: %w", err)
@@ -219,7 +403,10 @@ func (r *InvoiceRepository) List(ctx context.Context, organizationID uint, filte
 	defer rows.Close()
 
 	var invoices []*domain.Invoice
-	for rows.Next() {
+	for scanned := 0; rows.Next(); scanned++ {
+		if err := checkContext(ctx, scanned); err != nil {
+			return nil, 0, err
+		}
 		invoice := &domain.Invoice{}
 		err := scanInvoice(rows, invoice)
 		if err != nil {
@@ -227,164 +414,307 @@ func (r *InvoiceRepository) List(ctx context.Context, organizationID uint, filte
 			return nil, 0, fmt.Errorf("failed to scan invoice: %w", err)
 		}
 
-		// Load related data if requested
-		if filters.IncludeItems {
-			items, err := r.GetItemsByInvoiceID(ctx, invoice.ID)
-			if err != nil {
-				r.logger.Error("Failed to load invoice items", "error", err, "invoiceId", invoice.ID)
-			} else {
-				// Convert []*domain.InvoiceItem to []domain.InvoiceItem
+		invoices = append(invoices, invoice)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("failed to iterate invoices: %w", err)
+	}
+	rows.Close()
+
+	// Load related data if requested, one query per relation for the whole page
+	if filters.IncludeItems || filters.IncludePayments {
+		r.loadInvoiceRelations(ctx, invoices, filters.IncludeItems, filters.IncludePayments)
+	}
+
+	return invoices, total, nil
+}
+
+// loadInvoiceRelations attaches the items and payments of a page of invoices.
+// Failures are logged and leave the relation empty, as a listing should not
+// fail because of its optional includes.
+func (r *InvoiceRepository) loadInvoiceRelations(ctx context.Context, invoices []*domain.Invoice, includeItems, includePayments bool) {
+	if len(invoices) == 0 {
+		return
+	}
+	ids := make([]uint, len(invoices))
+	for i, invoice := range invoices {
+		ids[i] = invoice.ID
+	}
+
+	if includeItems {
+		itemsByInvoice, err := r.GetItemsByInvoiceIDs(ctx, ids)
+		if err != nil {
+			r.logger.Error("Failed to load invoice items", "error", err, "invoiceCount", len(ids))
+		} else {
+			for _, invoice := range invoices {
+				items := itemsByInvoice[invoice.ID]
 				invoice.Items = make([]domain.InvoiceItem, len(items))
 				for i, item := range items {
 					invoice.Items[i] = *item
 				}
 			}
 		}
+	}
 
-		if filters.IncludePayments {
-			payments, err := r.GetPaymentsByInvoiceID(ctx, invoice.ID)
-			if err != nil {
-				r.logger.Error("Failed to load invoice payments", "error", err, "invoiceId", invoice.ID)
-			} else {
-				// Convert []*domain.Payment to []domain.Payment
+	if includePayments {
+		paymentsByInvoice, err := r.GetPaymentsByInvoiceIDs(ctx, ids)
+		if err != nil {
+			r.logger.Error("Failed to load invoice payments", "error", err, "invoiceCount", len(ids))
+		} else {
+			for _, invoice := range invoices {
+				payments := paymentsByInvoice[invoice.ID]
 				invoice.Payments = make([]domain.Payment, len(payments))
 				for i, payment := range payments {
 					invoice.Payments[i] = *payment
 				}
 			}
 		}
+	}
+}
+
+// ListStream scans the invoices matching filters one row at a time and hands
+// each to fn. Pagination and the related items and payments are ignored so
+// no second query runs while the rows are open.
+func (r *InvoiceRepository) ListStream(ctx context.Context, organizationID uint, filters repository.InvoiceFilters, fn func(*domain.Invoice) error) error {
+	defer observeQuery(ctx, "InvoiceRepository", "ListStream", time.Now())
+	// Exports stream for as long as the client reads, so only the start of
+	// the query is bounded by the statement timeout
+	ctx, started, cancel := core.WithStatementStartTimeout(ctx)
+	defer cancel()
+
+	if err := filters.Validate(); err != nil {
+		return err
+	}
+
+	whereClause, args := r.buildInvoiceWhereClause(organizationID, filters)
+	query := fmt.Sprintf(
+		"SELECT %s FROM invoices %s ORDER BY %s %s",
+		invoiceColumns, whereClause, filters.SortBy, strings.ToUpper(filters.SortOrder),
+	)
+
+	rows, err := r.reader().QueryContext(ctx, query, args...)
+	started()
+	if err != nil {
+		r.logger.Error("Failed to stream invoices", "error", err)
+		return fmt.Errorf("failed to list invoices: %w", err)
+	}
+	defer rows.Close()
+
+	for scanned := 0; rows.Next(); scanned++ {
+		if err := checkContext(ctx, scanned); err != nil {
+			return err
+		}
+		invoice := &domain.Invoice{}
+		if err := scanInvoice(rows, invoice); err != nil {
+			r.logger.Error("Failed to scan invoice", "error", err)
+			return fmt.Errorf("failed to scan invoice: %w", err)
+		}
+		if err := fn(invoice); err != nil {
+			return err
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to iterate invoices: %w", err)
+	}
+	return nil
+}
+
+// ListByContacts pages the invoices of each contact separately, numbering
+// the rows of every contact in the order of filters so a single query returns
+// the same page List would for each of them
+func (r *InvoiceRepository) ListByContacts(ctx context.Context, organizationID uint, contactIDs []uint, filters repository.InvoiceFilters) (map[uint][]*domain.Invoice, map[uint]int64, error) {
+	defer observeQuery(ctx, "InvoiceRepository", "ListByContacts", time.Now())
+	ctx, cancel := core.WithStatementTimeout(ctx)
+	defer cancel()
+
+	if err := filters.Validate(); err != nil {
+		return nil, nil, err
+	}
+
+	invoicesByContact := make(map[uint][]*domain.Invoice, len(contactIDs))
+	totals := make(map[uint]int64, len(contactIDs))
+	if len(contactIDs) == 0 {
+		return invoicesByContact, totals, nil
+	}
+
+	filters.ContactID = nil
+	filters.ContactIDs = contactIDs
+	whereClause, args := r.buildInvoiceWhereClause(organizationID, filters)
+
+	countQuery := fmt.Sprintf("SELECT contact_id, COUNT(*) FROM invoices %s GROUP BY contact_id", whereClause)
+	countRows, err := r.reader().QueryContext(ctx, countQuery, args...)
+	if err != nil {
+		r.logger.Error("Failed to count contact invoices", "error", err, "contactCount", len(contactIDs))
+		return nil, nil, fmt.Errorf("failed to count invoices: %w", err)
+	}
+	defer countRows.Close()
+	for countRows.Next() {
+		var contactID uint
+		var total int64
+		if err := countRows.Scan(&contactID, &total); err != nil {
+			return nil, nil, fmt.Errorf("failed to scan invoice count: %w", err)
+		}
+		totals[contactID] = total
+	}
+	if err := countRows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("failed to iterate invoice counts: %w", err)
+	}
+	countRows.Close()
+
+	query := fmt.Sprintf(`
+		SELECT %s FROM (
+			SELECT %s, ROW_NUMBER() OVER (PARTITION BY contact_id ORDER BY %s %s) AS row_num
+			FROM invoices %s
+		) ranked
+		WHERE row_num > %d AND row_num <= %d
+		ORDER BY contact_id, row_num`,
+		invoiceColumns, invoiceColumns, filters.SortBy, strings.ToUpper(filters.SortOrder), whereClause,
+		filters.GetOffset(), filters.GetOffset()+filters.PageSize,
+	)
 
+	rows, err := r.reader().QueryContext(ctx, query, args...)
+	if err != nil {
+		r.logger.Error("Failed to list contact invoices", "error", err, "contactCount", len(contactIDs))
+		return nil, nil, fmt.Errorf("failed to list invoices: %w", err)
+	}
+	defer rows.Close()
+
+	var invoices []*domain.Invoice
+	for scanned := 0; rows.Next(); scanned++ {
+		if err := checkContext(ctx, scanned); err != nil {
+			return nil, nil, err
+		}
+		invoice := &domain.Invoice{}
+		if err := scanInvoice(rows, invoice); err != nil {
+			r.logger.Error("Failed to scan invoice", "error", err)
+			return nil, nil, fmt.Errorf("failed to scan invoice: %w", err)
+		}
 		invoices = append(invoices, invoice)
+		invoicesByContact[invoice.ContactID] = append(invoicesByContact[invoice.ContactID], invoice)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("failed to iterate invoices: %w", err)
 	}
+	rows.Close()
 
-	if err = rows.Err(); err != nil {
-		return nil, 0, fmt.Errorf("failed to iterate invoices: %w", err)
+	if filters.IncludeItems || filters.IncludePayments {
+		r.loadInvoiceRelations(ctx, invoices, filters.IncludeItems, filters.IncludePayments)
 	}
 
-	return invoices, total, nil
+	return invoicesByContact, totals, nil
 }
 
 // buildInvoiceWhereClause builds the WHERE clause for invoice filtering
 func (r *InvoiceRepository) buildInvoiceWhereClause(organizationID uint, filters repository.InvoiceFilters) (string, []interface{}) {
-	var conditions []string
-	var args []interface{}
-	argIndex := 1
-
 	// Organization filter (always required)
-	conditions = append(conditions, fmt.Sprintf("organization_id = $%d", argIndex))
-	args = append(args, organizationID)
-	argIndex++
+	qb := NewQueryBuilder().Where("organization_id = ?", organizationID)
 
 	// Contact filter
 	if filters.ContactID != nil {
-		conditions = append(conditions, fmt.Sprintf("contact_id = $%d", argIndex))
-		args = append(args, *filters.ContactID)
-		argIndex++
+		qb.Where("contact_id = ?", *filters.ContactID)
+	}
+	if len(filters.ContactIDs) > 0 {
+		contactIDs := make([]interface{}, len(filters.ContactIDs))
+		for i, id := range filters.ContactIDs {
+			contactIDs[i] = id
+		}
+		qb.Where("contact_id IN ("+strings.TrimSuffix(strings.Repeat("?,", len(contactIDs)), ",")+")", contactIDs...)
 	}
 
 	// Type filter
 	if filters.Type != nil {
-		conditions = append(conditions, fmt.Sprintf("type = $%d", argIndex))
-		args = append(args, *filters.Type)
-		argIndex++
+		qb.Where("type = ?", *filters.Type)
 	}
 
 	// Status filter
 	if filters.Status != nil {
-		conditions = append(conditions, fmt.Sprintf("status = $%d", argIndex))
-		args = append(args, *filters.Status)
-		argIndex++
+		qb.Where("status = ?", *filters.Status)
 	}
 
 	// Currency filter
 	if filters.Currency != "" {
-		conditions = append(conditions, fmt.Sprintf("currency = $%d", argIndex))
-		args = append(args, filters.Currency)
-		argIndex++
+		qb.Where("currency = ?", filters.Currency)
 	}
 
-	// Search filter (invoice number)
+	// Search filter (invoice number), served by the trigram index on
+	// LOWER(invoice_number)
 	if filters.Search != "" {
-		searchPattern := "%" + strings.ToLower(filters.Search) + "%"
-		conditions = append(conditions, fmt.Sprintf("LOWER(invoice_number) LIKE $%d", argIndex))
-		args = append(args, searchPattern)
-		argIndex++
+		qb.Where("LOWER(invoice_number) LIKE ?", "%"+strings.ToLower(filters.Search)+"%")
 	}
 
 	// Date range filters
 	if filters.IssuedFrom != nil {
-		conditions = append(conditions, fmt.Sprintf("issue_date >= $%d", argIndex))
-		args = append(args, *filters.IssuedFrom)
-		argIndex++
+		qb.Where("issue_date >= ?", *filters.IssuedFrom)
 	}
 
 	if filters.IssuedTo != nil {
-		conditions = append(conditions, fmt.Sprintf("issue_date <= $%d", argIndex))
-		args = append(args, *filters.IssuedTo)
-		argIndex++
+		qb.Where("issue_date <= ?", *filters.IssuedTo)
 	}
 
 	if filters.DueFrom != nil {
-		conditions = append(conditions, fmt.Sprintf("due_date >= $%d", argIndex))
-		args = append(args, *filters.DueFrom)
-		argIndex++
+		qb.Where("due_date >= ?", *filters.DueFrom)
 	}
 
 	if filters.DueTo != nil {
-		conditions = append(conditions, fmt.Sprintf("due_date <= $%d", argIndex))
-		args = append(args, *filters.DueTo)
-		argIndex++
+		qb.Where("due_date <= ?", *filters.DueTo)
 	}
 
 	// Amount range filters
 	if filters.MinAmount != nil {
-		conditions = append(conditions, fmt.Sprintf("total_amount >= $%d", argIndex))
-		args = append(args, *filters.MinAmount)
-		argIndex++
+		qb.Where("total_amount >= ?", *filters.MinAmount)
 	}
 
 	if filters.MaxAmount != nil {
-		conditions = append(conditions, fmt.Sprintf("total_amount <= $%d", argIndex))
-		args = append(args, *filters.MaxAmount)
-		argIndex++
+		qb.Where("total_amount <= ?", *filters.MaxAmount)
 	}
 
 	// Overdue filter
 	if filters.IsOverdue != nil && *filters.IsOverdue {
-		conditions = append(conditions, "due_date < NOW() AND balance_due > 0 AND status NOT IN ('paid', 'canceled')")
+		qb.Where("due_date < NOW() AND balance_due > 0 AND status NOT IN ('paid', 'canceled')")
+	}
+
+	// Payment state filter
+	if filters.PaymentState != nil {
+		switch *filters.PaymentState {
+		case repository.InvoicePaymentStateUnpaid:
+			qb.Where("balance_due > 0 AND paid_amount <= 0")
+		case repository.InvoicePaymentStatePartial:
+			qb.Where("balance_due > 0 AND paid_amount > 0")
+		case repository.InvoicePaymentStatePaid:
+			qb.Where("balance_due <= 0")
+		}
 	}
 
 	// Created by filter
 	if filters.CreatedBy != nil {
-		conditions = append(conditions, fmt.Sprintf("created_by = $%d", argIndex))
-		args = append(args, *filters.CreatedBy)
-		argIndex++
-	}
-
-	whereClause := ""
-	if len(conditions) > 0 {
-		whereClause = "WHERE " + strings.Join(conditions, " AND ")
+		qb.Where("created_by = ?", *filters.CreatedBy)
 	}
 
-	return whereClause, args
+	return qb.Build()
 }
 
 // CreateItem creates a new invoice item
 func (r *InvoiceRepository) CreateItem(ctx context.Context, item *domain.InvoiceItem) error {
+	defer observeQuery(ctx, "InvoiceRepository", "CreateItem", time.Now())
+	ctx, cancel := core.WithStatementTimeout(ctx)
+	defer cancel()
+
 	query := `
 		INSERT INTO invoice_items (
 			invoice_id, product_id, product_variant_id, description, quantity,
 			unit_price, discount_percent, discount_amount, tax_rate, tax_amount,
-			line_total, sort_order, created_at, updated_at
+			reverse_charge, line_total, sort_order, created_at, updated_at
 		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15
 		) RETURNING id, created_at, updated_at`
 
-	err := r.db.QueryRowContext(ctx, query,
+	err := r.conn().QueryRowContext(ctx, query,
 		item.InvoiceID, item.ProductID, item.ProductVariantID, item.Description,
 		item.Quantity, item.UnitPrice, item.DiscountPercent, item.DiscountAmount,
-		item.TaxRate, item.TaxAmount, item.LineTotal, item.SortOrder,
-		item.CreatedAt, item.UpdatedAt,
+		item.TaxRate, item.TaxAmount, item.ReverseCharge, item.LineTotal,
+		item.SortOrder, item.CreatedAt, item.UpdatedAt,
 	).Scan(&item.ID, &item.CreatedAt, &item.UpdatedAt)
 
 	if err != nil {
@@ -398,19 +728,24 @@ func (r *InvoiceRepository) CreateItem(ctx context.Context, item *domain.Invoice
 
 // GetItemByID retrieves an invoice item by ID
 func (r *InvoiceRepository) GetItemByID(ctx context.Context, invoiceID, itemID uint) (*domain.InvoiceItem, error) {
+	defer observeQuery(ctx, "InvoiceRepository", "GetItemByID", time.Now())
+	ctx, cancel := core.WithStatementTimeout(ctx)
+	defer cancel()
+
 	query := `
 		SELECT id, invoice_id, product_id, product_variant_id, description,
 			   quantity, unit_price, discount_percent, discount_amount,
-			   tax_rate, tax_amount, line_total, sort_order, created_at, updated_at
+			   tax_rate, tax_amount, reverse_charge, line_total, sort_order,
+			   created_at, updated_at
 		FROM invoice_items 
 		WHERE id = $1 AND invoice_id = $2`
 
 	item := &domain.InvoiceItem{}
-	err := r.db.QueryRowContext(ctx, query, itemID, invoiceID).Scan(
+	err := r.readConn().QueryRowContext(ctx, query, itemID, invoiceID).Scan(
 		&item.ID, &item.InvoiceID, &item.ProductID, &item.ProductVariantID,
 		&item.Description, &item.Quantity, &item.UnitPrice, &item.DiscountPercent,
-		&item.DiscountAmount, &item.TaxRate, &item.TaxAmount, &item.LineTotal,
-		&item.SortOrder, &item.CreatedAt, &item.UpdatedAt,
+		&item.DiscountAmount, &item.TaxRate, &item.TaxAmount, &item.ReverseCharge,
+		&item.LineTotal, &item.SortOrder, &item.CreatedAt, &item.UpdatedAt,
 	)
 
 	if err != nil {
@@ -426,15 +761,20 @@ func (r *InvoiceRepository) GetItemByID(ctx context.Context, invoiceID, itemID u
 
 // GetItemsByInvoiceID retrieves all items for an invoice
 func (r *InvoiceRepository) GetItemsByInvoiceID(ctx context.Context, invoiceID uint) ([]*domain.InvoiceItem, error) {
+	defer observeQuery(ctx, "InvoiceRepository", "GetItemsByInvoiceID", time.Now())
+	ctx, cancel := core.WithStatementTimeout(ctx)
+	defer cancel()
+
 	query := `
 		SELECT id, invoice_id, product_id, product_variant_id, description,
 			   quantity, unit_price, discount_percent, discount_amount,
-			   tax_rate, tax_amount, line_total, sort_order, created_at, updated_at
+			   tax_rate, tax_amount, reverse_charge, line_total, sort_order,
+			   created_at, updated_at
 		FROM invoice_items 
 		WHERE invoice_id = $1
 		ORDER BY sort_order ASC, id ASC`
 
-	rows, err := r.db.QueryContext(ctx, query, invoiceID)
+	rows, err := r.readConn().QueryContext(ctx, query, invoiceID)
 	if err != nil {
 		r.logger.Error("Failed to get invoice items", "error", err, "invoiceId", invoiceID)
 		return nil, fmt.Errorf("failed to get invoice items: %w", err)
@@ -447,8 +787,8 @@ func (r *InvoiceRepository) GetItemsByInvoiceID(ctx context.Context, invoiceID u
 		err := rows.Scan(
 			&item.ID, &item.InvoiceID, &item.ProductID, &item.ProductVariantID,
 			&item.Description, &item.Quantity, &item.UnitPrice, &item.DiscountPercent,
-			&item.DiscountAmount, &item.TaxRate, &item.TaxAmount, &item.LineTotal,
-			&item.SortOrder, &item.CreatedAt, &item.UpdatedAt,
+			&item.DiscountAmount, &item.TaxRate, &item.TaxAmount, &item.ReverseCharge,
+			&item.LineTotal, &item.SortOrder, &item.CreatedAt, &item.UpdatedAt,
 		)
 		if err != nil {
 			r.logger.Error("Failed to scan invoice item", "error", err)
@@ -465,20 +805,80 @@ func (r *InvoiceRepository) GetItemsByInvoiceID(ctx context.Context, invoiceID u
 	return items, nil
 }
 
+// GetItemsByInvoiceIDs retrieves the items of several invoices in a single
+// query, keyed by invoice ID
+func (r *InvoiceRepository) GetItemsByInvoiceIDs(ctx context.Context, invoiceIDs []uint) (map[uint][]*domain.InvoiceItem, error) {
+	defer observeQuery(ctx, "InvoiceRepository", "GetItemsByInvoiceIDs", time.Now())
+	ctx, cancel := core.WithStatementTimeout(ctx)
+	defer cancel()
+
+	itemsByInvoice := make(map[uint][]*domain.InvoiceItem, len(invoiceIDs))
+	if len(invoiceIDs) == 0 {
+		return itemsByInvoice, nil
+	}
+
+	placeholders, args := idPlaceholders(invoiceIDs)
+	query := fmt.Sprintf(`
+		SELECT id, invoice_id, product_id, product_variant_id, description,
+			   quantity, unit_price, discount_percent, discount_amount,
+			   tax_rate, tax_amount, reverse_charge, line_total, sort_order,
+			   created_at, updated_at
+		FROM invoice_items 
+		WHERE invoice_id IN (%s)
+		ORDER BY invoice_id ASC, sort_order ASC, id ASC`, placeholders)
+
+	rows, err := r.readConn().QueryContext(ctx, query, args...)
+	if err != nil {
+		r.logger.Error("Failed to get invoice items", "error", err, "invoiceCount", len(invoiceIDs))
+		return nil, fmt.Errorf("failed to get invoice items: %w", err)
+	}
+	defer rows.Close()
+
+	for scanned := 0; rows.Next(); scanned++ {
+		if err := checkContext(ctx, scanned); err != nil {
+			return nil, err
+		}
+		item := &domain.InvoiceItem{}
+		err := rows.Scan(
+			&item.ID, &item.InvoiceID, &item.ProductID, &item.ProductVariantID,
+			&item.Description, &item.Quantity, &item.UnitPrice, &item.DiscountPercent,
+			&item.DiscountAmount, &item.TaxRate, &item.TaxAmount, &item.ReverseCharge,
+			&item.LineTotal, &item.SortOrder, &item.CreatedAt, &item.UpdatedAt,
+		)
+		if err != nil {
+			r.logger.Error("Failed to scan invoice item", "error", err)
+			return nil, fmt.Errorf("failed to scan invoice item: %w", err)
+		}
+
+		itemsByInvoice[item.InvoiceID] = append(itemsByInvoice[item.InvoiceID], item)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate invoice items: %w", err)
+	}
+
+	return itemsByInvoice, nil
+}
+
 // UpdateItem updates an existing invoice item
 func (r *InvoiceRepository) UpdateItem(ctx context.Context, item *domain.InvoiceItem) error {
+	defer observeQuery(ctx, "InvoiceRepository", "UpdateItem", time.Now())
+	ctx, cancel := core.WithStatementTimeout(ctx)
+	defer cancel()
+
 	query := `
 		UPDATE invoice_items SET 
 			product_id = $2, product_variant_id = $3, description = $4,
 			quantity = $5, unit_price = $6, discount_percent = $7,
 			discount_amount = $8, tax_rate = $9, tax_amount = $10,
-			line_total = $11, sort_order = $12, updated_at = $13
+			reverse_charge = $11, line_total = $12, sort_order = $13, updated_at = $14
 		WHERE id = $1`
 
-	result, err := r.db.ExecContext(ctx, query,
+	result, err := r.conn().ExecContext(ctx, query,
 		item.ID, item.ProductID, item.ProductVariantID, item.Description,
 		item.Quantity, item.UnitPrice, item.DiscountPercent, item.DiscountAmount,
-		item.TaxRate, item.TaxAmount, item.LineTotal, item.SortOrder, time.Now(),
+		item.TaxRate, item.TaxAmount, item.ReverseCharge, item.LineTotal, item.SortOrder,
+		time.Now(),
 	)
 
 	if err != nil {
@@ -501,9 +901,13 @@ func (r *InvoiceRepository) UpdateItem(ctx context.Context, item *domain.Invoice
 
 // DeleteItem deletes an invoice item
 func (r *InvoiceRepository) DeleteItem(ctx context.Context, invoiceID, itemID uint) error {
+	defer observeQuery(ctx, "InvoiceRepository", "DeleteItem", time.Now())
+	ctx, cancel := core.WithStatementTimeout(ctx)
+	defer cancel()
+
 	query := `DELETE FROM invoice_items WHERE id = $1 AND invoice_id = $2`
 
-	result, err := r.db.ExecContext(ctx, query, itemID, invoiceID)
+	result, err := r.conn().ExecContext(ctx, query, itemID, invoiceID)
 	if err != nil {
 		r.logger.Error("Failed to delete invoice item", "error", err, "itemId", itemID)
 		return fmt.Errorf("failed to delete invoice item: %w", err)
@@ -524,11 +928,15 @@ func (r *InvoiceRepository) DeleteItem(ctx context.Context, invoiceID, itemID ui
 
 // BulkCreateItems creates multiple invoice items in a single transaction
 func (r *InvoiceRepository) BulkCreateItems(ctx context.Context, items []*domain.InvoiceItem) error {
+	defer observeQuery(ctx, "InvoiceRepository", "BulkCreateItems", time.Now())
+	ctx, cancel := core.WithStatementTimeout(ctx)
+	defer cancel()
+
 	if len(items) == 0 {
 		return nil
 	}
 
-	tx, err := r.db.BeginTx(ctx, nil)
+	tx, err := beginTx(ctx, r.db, r.tx)
 	if err != nil {
 		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
@@ -538,17 +946,17 @@ func (r *InvoiceRepository) BulkCreateItems(ctx context.Context, items []*domain
 		INSERT INTO invoice_items (
 			invoice_id, product_id, product_variant_id, description, quantity,
 			unit_price, discount_percent, discount_amount, tax_rate, tax_amount,
-			line_total, sort_order, created_at, updated_at
+			reverse_charge, line_total, sort_order, created_at, updated_at
 		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15
 		) RETURNING id, created_at, updated_at`
 
 	for _, item := range items {
 		err := tx.QueryRowContext(ctx, query,
 			item.InvoiceID, item.ProductID, item.ProductVariantID, item.Description,
 			item.Quantity, item.UnitPrice, item.DiscountPercent, item.DiscountAmount,
-			item.TaxRate, item.TaxAmount, item.LineTotal, item.SortOrder,
-			item.CreatedAt, item.UpdatedAt,
+			item.TaxRate, item.TaxAmount, item.ReverseCharge, item.LineTotal,
+			item.SortOrder, item.CreatedAt, item.UpdatedAt,
 		).Scan(&item.ID, &item.CreatedAt, &item.UpdatedAt)
 
 		if err != nil {
@@ -567,11 +975,15 @@ func (r *InvoiceRepository) BulkCreateItems(ctx context.Context, items []*domain
 
 // BulkUpdateItems updates multiple invoice items in a single transaction
 func (r *InvoiceRepository) BulkUpdateItems(ctx context.Context, items []*domain.InvoiceItem) error {
+	defer observeQuery(ctx, "InvoiceRepository", "BulkUpdateItems", time.Now())
+	ctx, cancel := core.WithStatementTimeout(ctx)
+	defer cancel()
+
 	if len(items) == 0 {
 		return nil
 	}
 
-	tx, err := r.db.BeginTx(ctx, nil)
+	tx, err := beginTx(ctx, r.db, r.tx)
 	if err != nil {
 		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
@@ -582,14 +994,15 @@ func (r *InvoiceRepository) BulkUpdateItems(ctx context.Context, items []*domain
 			product_id = $2, product_variant_id = $3, description = $4,
 			quantity = $5, unit_price = $6, discount_percent = $7,
 			discount_amount = $8, tax_rate = $9, tax_amount = $10,
-			line_total = $11, sort_order = $12, updated_at = $13
+			reverse_charge = $11, line_total = $12, sort_order = $13, updated_at = $14
 		WHERE id = $1`
 
 	for _, item := range items {
 		result, err := tx.ExecContext(ctx, query,
 			item.ID, item.ProductID, item.ProductVariantID, item.Description,
 			item.Quantity, item.UnitPrice, item.DiscountPercent, item.DiscountAmount,
-			item.TaxRate, item.TaxAmount, item.LineTotal, item.SortOrder, time.Now(),
+			item.TaxRate, item.TaxAmount, item.ReverseCharge, item.LineTotal, item.SortOrder,
+			time.Now(),
 		)
 
 		if err != nil {
@@ -617,11 +1030,15 @@ func (r *InvoiceRepository) BulkUpdateItems(ctx context.Context, items []*domain
 
 // BulkDeleteItems deletes multiple invoice items in a single transaction
 func (r *InvoiceRepository) BulkDeleteItems(ctx context.Context, invoiceID uint, itemIDs []uint) error {
+	defer observeQuery(ctx, "InvoiceRepository", "BulkDeleteItems", time.Now())
+	ctx, cancel := core.WithStatementTimeout(ctx)
+	defer cancel()
+
 	if len(itemIDs) == 0 {
 		return nil
 	}
 
-	tx, err := r.db.BeginTx(ctx, nil)
+	tx, err := beginTx(ctx, r.db, r.tx)
 	if err != nil {
 		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
@@ -658,8 +1075,78 @@ func (r *InvoiceRepository) BulkDeleteItems(ctx context.Context, invoiceID uint,
 	return nil
 }
 
+// ReorderItems sets the sort order of an invoice's items to their position in
+// orderedItemIDs, which must list every item of the invoice exactly once
+func (r *InvoiceRepository) ReorderItems(ctx context.Context, invoiceID uint, orderedItemIDs []uint) error {
+	defer observeQuery(ctx, "InvoiceRepository", "ReorderItems", time.Now())
+	ctx, cancel := core.WithStatementTimeout(ctx)
+	defer cancel()
+
+	tx, err := beginTx(ctx, r.db, r.tx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `SELECT id FROM invoice_items WHERE invoice_id = $1`, invoiceID)
+	if err != nil {
+		r.logger.Error("Failed to get invoice items for reordering", "error", err, "invoiceId", invoiceID)
+		return fmt.Errorf("failed to get invoice items: %w", err)
+	}
+	defer rows.Close()
+
+	pending := make(map[uint]bool)
+	for scanned := 0; rows.Next(); scanned++ {
+		if err := checkContext(ctx, scanned); err != nil {
+			return err
+		}
+		var id uint
+		if err := rows.Scan(&id); err != nil {
+			return fmt.Errorf("failed to scan invoice item id: %w", err)
+		}
+		pending[id] = true
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to iterate invoice items: %w", err)
+	}
+	rows.Close()
+
+	if len(orderedItemIDs) != len(pending) {
+		return domain.ErrInvalidItemOrder
+	}
+	for _, id := range orderedItemIDs {
+		if !pending[id] {
+			return fmt.Errorf("%w: item %d is not an item of invoice %d", domain.ErrInvalidItemOrder, id, invoiceID)
+		}
+		delete(pending, id)
+	}
+
+	now := time.Now()
+	for position, id := range orderedItemIDs {
+		_, err := tx.ExecContext(ctx,
+			`UPDATE invoice_items SET sort_order = $1, updated_at = $2 WHERE id = $3 AND invoice_id = $4`,
+			position, now, id, invoiceID,
+		)
+		if err != nil {
+			r.logger.Error("Failed to reorder invoice item", "error", err, "itemId", id)
+			return fmt.Errorf("failed to reorder invoice item %d: %w", id, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit reorder transaction: %w", err)
+	}
+
+	r.logger.Info("Reordered invoice items successfully", "invoiceId", invoiceID, "count", len(orderedItemIDs))
+	return nil
+}
+
 // CreatePayment creates a new payment
 func (r *InvoiceRepository) CreatePayment(ctx context.Context, payment *domain.Payment) error {
+	defer observeQuery(ctx, "InvoiceRepository", "CreatePayment", time.Now())
+	ctx, cancel := core.WithStatementTimeout(ctx)
+	defer cancel()
+
 	query := `
 		INSERT INTO payments (
 			organization_id, invoice_id, payment_method, reference_number,
@@ -669,7 +1156,7 @@ func (r *InvoiceRepository) CreatePayment(ctx context.Context, payment *domain.P
 			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12
 		) RETURNING id, created_at, updated_at`
 
-	err := r.db.QueryRowContext(ctx, query,
+	err := r.conn().QueryRowContext(ctx, query,
 		payment.OrganizationID, payment.InvoiceID, payment.PaymentMethod,
 		payment.ReferenceNumber, payment.Amount, payment.Currency,
 		payment.ExchangeRate, payment.PaymentDate, payment.Notes,
@@ -687,6 +1174,10 @@ func (r *InvoiceRepository) CreatePayment(ctx context.Context, payment *domain.P
 
 // GetPaymentByID retrieves a payment by ID
 func (r *InvoiceRepository) GetPaymentByID(ctx context.Context, organizationID, paymentID uint) (*domain.Payment, error) {
+	defer observeQuery(ctx, "InvoiceRepository", "GetPaymentByID", time.Now())
+	ctx, cancel := core.WithStatementTimeout(ctx)
+	defer cancel()
+
 	query := `
 		SELECT id, organization_id, invoice_id, payment_method, reference_number,
 			   amount, currency, exchange_rate, payment_date, notes,
@@ -695,7 +1186,7 @@ func (r *InvoiceRepository) GetPaymentByID(ctx context.Context, organizationID,
 		WHERE id = $1 AND organization_id = $2`
 
 	payment := &domain.Payment{}
-	err := r.db.QueryRowContext(ctx, query, paymentID, organizationID).Scan(
+	err := r.readConn().QueryRowContext(ctx, query, paymentID, organizationID).Scan(
 		&payment.ID, &payment.OrganizationID, &payment.InvoiceID,
 		&payment.PaymentMethod, &payment.ReferenceNumber, &payment.Amount,
 		&payment.Currency, &payment.ExchangeRate, &payment.PaymentDate,
@@ -709,12 +1200,17 @@ func (r *InvoiceRepository) GetPaymentByID(ctx context.Context, organizationID,
 		r.logger.Error("Failed to get payment by ID", "error", err, "paymentId", paymentID)
 		return nil, fmt.Errorf("failed to get payment: %w", err)
 	}
+	payment.Refund = payment.Amount < 0
 
 	return payment, nil
 }
 
 // GetPaymentsByInvoiceID retrieves all payments for an invoice
 func (r *InvoiceRepository) GetPaymentsByInvoiceID(ctx context.Context, invoiceID uint) ([]*domain.Payment, error) {
+	defer observeQuery(ctx, "InvoiceRepository", "GetPaymentsByInvoiceID", time.Now())
+	ctx, cancel := core.WithStatementTimeout(ctx)
+	defer cancel()
+
 	query := `
 		SELECT id, organization_id, invoice_id, payment_method, reference_number,
 			   amount, currency, exchange_rate, payment_date, notes,
@@ -723,7 +1219,7 @@ func (r *InvoiceRepository) GetPaymentsByInvoiceID(ctx context.Context, invoiceI
 		WHERE invoice_id = $1
 		ORDER BY payment_date DESC, created_at DESC`
 
-	rows, err := r.db.QueryContext(ctx, query, invoiceID)
+	rows, err := r.readConn().QueryContext(ctx, query, invoiceID)
 	if err != nil {
 		r.logger.Error("Failed to get payments for invoice", "error", err, "invoiceId", invoiceID)
 		return nil, fmt.Errorf("failed to get payments: %w", err)
@@ -744,6 +1240,7 @@ func (r *InvoiceRepository) GetPaymentsByInvoiceID(ctx context.Context, invoiceI
 			return nil, fmt.Errorf("failed to scan payment: %w", err)
 		}
 
+		payment.Refund = payment.Amount < 0
 		payments = append(payments, payment)
 	}
 
@@ -754,8 +1251,67 @@ func (r *InvoiceRepository) GetPaymentsByInvoiceID(ctx context.Context, invoiceI
 	return payments, nil
 }
 
+// GetPaymentsByInvoiceIDs retrieves the payments of several invoices in a
+// single query, keyed by invoice ID
+func (r *InvoiceRepository) GetPaymentsByInvoiceIDs(ctx context.Context, invoiceIDs []uint) (map[uint][]*domain.Payment, error) {
+	defer observeQuery(ctx, "InvoiceRepository", "GetPaymentsByInvoiceIDs", time.Now())
+	ctx, cancel := core.WithStatementTimeout(ctx)
+	defer cancel()
+
+	paymentsByInvoice := make(map[uint][]*domain.Payment, len(invoiceIDs))
+	if len(invoiceIDs) == 0 {
+		return paymentsByInvoice, nil
+	}
+
+	placeholders, args := idPlaceholders(invoiceIDs)
+	query := fmt.Sprintf(`
+		SELECT id, organization_id, invoice_id, payment_method, reference_number,
+			   amount, currency, exchange_rate, payment_date, notes,
+			   created_by, created_at, updated_at
+		FROM payments 
+		WHERE invoice_id IN (%s)
+		ORDER BY invoice_id ASC, payment_date DESC, created_at DESC`, placeholders)
+
+	rows, err := r.readConn().QueryContext(ctx, query, args...)
+	if err != nil {
+		r.logger.Error("Failed to get payments for invoices", "error", err, "invoiceCount", len(invoiceIDs))
+		return nil, fmt.Errorf("failed to get payments: %w", err)
+	}
+	defer rows.Close()
+
+	for scanned := 0; rows.Next(); scanned++ {
+		if err := checkContext(ctx, scanned); err != nil {
+			return nil, err
+		}
+		payment := &domain.Payment{}
+		err := rows.Scan(
+			&payment.ID, &payment.OrganizationID, &payment.InvoiceID,
+			&payment.PaymentMethod, &payment.ReferenceNumber, &payment.Amount,
+			&payment.Currency, &payment.ExchangeRate, &payment.PaymentDate,
+			&payment.Notes, &payment.CreatedBy, &payment.CreatedAt, &payment.UpdatedAt,
+		)
+		if err != nil {
+			r.logger.Error("Failed to scan payment", "error", err)
+			return nil, fmt.Errorf("failed to scan payment: %w", err)
+		}
+
+		payment.Refund = payment.Amount < 0
+		paymentsByInvoice[payment.InvoiceID] = append(paymentsByInvoice[payment.InvoiceID], payment)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate payments: %w", err)
+	}
+
+	return paymentsByInvoice, nil
+}
+
 // UpdatePayment updates an existing payment
 func (r *InvoiceRepository) UpdatePayment(ctx context.Context, payment *domain.Payment) error {
+	defer observeQuery(ctx, "InvoiceRepository", "UpdatePayment", time.Now())
+	ctx, cancel := core.WithStatementTimeout(ctx)
+	defer cancel()
+
 	query := `
 		UPDATE payments SET 
 			payment_method = $2, reference_number = $3, amount = $4,
@@ -763,7 +1319,7 @@ func (r *InvoiceRepository) UpdatePayment(ctx context.Context, payment *domain.P
 			notes = $8, updated_at = $9
 		WHERE id = $1 AND organization_id = $10`
 
-	result, err := r.db.ExecContext(ctx, query,
+	result, err := r.conn().ExecContext(ctx, query,
 		payment.ID, payment.PaymentMethod, payment.ReferenceNumber,
 		payment.Amount, payment.Currency, payment.ExchangeRate,
 		payment.PaymentDate, payment.Notes, time.Now(), payment.OrganizationID,
@@ -789,9 +1345,13 @@ func (r *InvoiceRepository) UpdatePayment(ctx context.Context, payment *domain.P
 
 // DeletePayment deletes a payment
 func (r *InvoiceRepository) DeletePayment(ctx context.Context, organizationID, paymentID uint) error {
+	defer observeQuery(ctx, "InvoiceRepository", "DeletePayment", time.Now())
+	ctx, cancel := core.WithStatementTimeout(ctx)
+	defer cancel()
+
 	query := `DELETE FROM payments WHERE id = $1 AND organization_id = $2`
 
-	result, err := r.db.ExecContext(ctx, query, paymentID, organizationID)
+	result, err := r.conn().ExecContext(ctx, query, paymentID, organizationID)
 	if err != nil {
 		r.logger.Error("Failed to delete payment", "error", err, "paymentId", paymentID)
 		return fmt.Errorf("failed to delete payment: %w", err)
@@ -812,6 +1372,10 @@ func (r *InvoiceRepository) DeletePayment(ctx context.Context, organizationID, p
 
 // ListPayments retrieves payments with filtering and pagination
 func (r *InvoiceRepository) ListPayments(ctx context.Context, organizationID uint, filters repository.PaymentFilters) ([]*domain.Payment, int64, error) {
+	defer observeQuery(ctx, "InvoiceRepository", "ListPayments", time.Now())
+	ctx, cancel := core.WithStatementTimeout(ctx)
+	defer cancel()
+
 	// Validate filters
 	if err := filters.Validate(); err != nil {
 		return nil, 0, err
@@ -823,7 +1387,7 @@ func (r *InvoiceRepository) ListPayments(ctx context.Context, organizationID uin
 	// Count query
 	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM payments %s", whereClause)
 	var total int64
-	err := r.db.QueryRowContext(ctx, countQuery, args...).Scan(&total)
+	err := r.reader().QueryRowContext(ctx, countQuery, args...).Scan(&total)
 	if err != nil {
 		r.logger.Error("Failed to count payments", "error", err)
 		return nil, 0, fmt.Errorf("failed to count payments: %w", err)
@@ -839,7 +1403,7 @@ func (r *InvoiceRepository) ListPayments(ctx context.Context, organizationID uin
 			   created_by, created_at, updated_at
 		FROM payments %s %s %s`, whereClause, orderClause, limitClause)
 
-	rows, err := r.db.QueryContext(ctx, query, args...)
+	rows, err := r.reader().QueryContext(ctx, query, args...)
 	if err != nil {
 		r.logger.Error("Failed to list payments", "error", err)
 		return nil, 0, fmt.Errorf("failed to list payments: %w", err)
@@ -847,7 +1411,10 @@ func (r *InvoiceRepository) ListPayments(ctx context.Context, organizationID uin
 	defer rows.Close()
 
 	var payments []*domain.Payment
-	for rows.Next() {
+	for scanned := 0; rows.Next(); scanned++ {
+		if err := checkContext(ctx, scanned); err != nil {
+			return nil, 0, err
+		}
 		payment := &domain.Payment{}
 		err := rows.Scan(
 			&payment.ID, &payment.OrganizationID, &payment.InvoiceID,
@@ -860,6 +1427,7 @@ func (r *InvoiceRepository) ListPayments(ctx context.Context, organizationID uin
 			return nil, 0, fmt.Errorf("failed to scan payment: %w", err)
 		}
 
+		payment.Refund = payment.Amount < 0
 		payments = append(payments, payment)
 	}
 
@@ -872,92 +1440,66 @@ func (r *InvoiceRepository) ListPayments(ctx context.Context, organizationID uin
 
 // buildPaymentWhereClause builds the WHERE clause for payment filtering
 func (r *InvoiceRepository) buildPaymentWhereClause(organizationID uint, filters repository.PaymentFilters) (string, []interface{}) {
-	var conditions []string
-	var args []interface{}
-	argIndex := 1
-
 	// Organization filter (always required)
-	conditions = append(conditions, fmt.Sprintf("organization_id = $%d", argIndex))
-	args = append(args, organizationID)
-	argIndex++
+	qb := NewQueryBuilder().Where("organization_id = ?", organizationID)
 
 	// Invoice filter
 	if filters.InvoiceID != nil {
-		conditions = append(conditions, fmt.Sprintf("invoice_id = $%d", argIndex))
-		args = append(args, *filters.InvoiceID)
-		argIndex++
+		qb.Where("invoice_id = ?", *filters.InvoiceID)
 	}
 
 	// Payment method filter
 	if filters.PaymentMethod != nil {
-		conditions = append(conditions, fmt.Sprintf("payment_method = $%d", argIndex))
-		args = append(args, *filters.PaymentMethod)
-		argIndex++
+		qb.Where("payment_method = ?", *filters.PaymentMethod)
 	}
 
 	// Currency filter
 	if filters.Currency != "" {
-		conditions = append(conditions, fmt.Sprintf("currency = $%d", argIndex))
-		args = append(args, filters.Currency)
-		argIndex++
+		qb.Where("currency = ?", filters.Currency)
 	}
 
 	// Search filter (reference number)
 	if filters.Search != "" {
-		searchPattern := "%" + strings.ToLower(filters.Search) + "%"
-		conditions = append(conditions, fmt.Sprintf("LOWER(reference_number) LIKE $%d", argIndex))
-		args = append(args, searchPattern)
-		argIndex++
+		qb.Where("LOWER(reference_number) LIKE ?", "%"+strings.ToLower(filters.Search)+"%")
 	}
 
 	// Date range filters
 	if filters.PaymentFrom != nil {
-		conditions = append(conditions, fmt.Sprintf("payment_date >= $%d", argIndex))
-		args = append(args, *filters.PaymentFrom)
-		argIndex++
+		qb.Where("payment_date >= ?", *filters.PaymentFrom)
 	}
 
 	if filters.PaymentTo != nil {
-		conditions = append(conditions, fmt.Sprintf("payment_date <= $%d", argIndex))
-		args = append(args, *filters.PaymentTo)
-		argIndex++
+		qb.Where("payment_date <= ?", *filters.PaymentTo)
 	}
 
 	// Amount range filters
 	if filters.MinAmount != nil {
-		conditions = append(conditions, fmt.Sprintf("amount >= $%d", argIndex))
-		args = append(args, *filters.MinAmount)
-		argIndex++
+		qb.Where("amount >= ?", *filters.MinAmount)
 	}
 
 	if filters.MaxAmount != nil {
-		conditions = append(conditions, fmt.Sprintf("amount <= $%d", argIndex))
-		args = append(args, *filters.MaxAmount)
-		argIndex++
+		qb.Where("amount <= ?", *filters.MaxAmount)
 	}
 
 	// Created by filter
 	if filters.CreatedBy != nil {
-		conditions = append(conditions, fmt.Sprintf("created_by = $%d", argIndex))
-		args = append(args, *filters.CreatedBy)
-		argIndex++
-	}
-
-	whereClause := ""
-	if len(conditions) > 0 {
-		whereClause = "WHERE " + strings.Join(conditions, " AND ")
+		qb.Where("created_by = ?", *filters.CreatedBy)
 	}
 
-	return whereClause, args
+	return qb.Build()
 }
 
 // BulkCreate creates multiple invoices in a single transaction
 func (r *InvoiceRepository) BulkCreate(ctx context.Context, invoices []*domain.Invoice) error {
+	defer observeQuery(ctx, "InvoiceRepository", "BulkCreate", time.Now())
+	ctx, cancel := core.WithStatementTimeout(ctx)
+	defer cancel()
+
 	if len(invoices) == 0 {
 		return nil
 	}
 
-	tx, err := r.db.BeginTx(ctx, nil)
+	tx, err := beginTx(ctx, r.db, r.tx)
 	if err != nil {
 		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
@@ -1001,11 +1543,15 @@ func (r *InvoiceRepository) BulkCreate(ctx context.Context, invoices []*domain.I
 
 // BulkUpdate updates multiple invoices in a single transaction
 func (r *InvoiceRepository) BulkUpdate(ctx context.Context, invoices []*domain.Invoice) error {
+	defer observeQuery(ctx, "InvoiceRepository", "BulkUpdate", time.Now())
+	ctx, cancel := core.WithStatementTimeout(ctx)
+	defer cancel()
+
 	if len(invoices) == 0 {
 		return nil
 	}
 
-	tx, err := r.db.BeginTx(ctx, nil)
+	tx, err := beginTx(ctx, r.db, r.tx)
 	if err != nil {
 		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
@@ -1055,11 +1601,15 @@ func (r *InvoiceRepository) BulkUpdate(ctx context.Context, invoices []*domain.I
 
 // BulkDelete deletes multiple invoices in a single transaction
 func (r *InvoiceRepository) BulkDelete(ctx context.Context, organizationID uint, invoiceIDs []uint) error {
+	defer observeQuery(ctx, "InvoiceRepository", "BulkDelete", time.Now())
+	ctx, cancel := core.WithStatementTimeout(ctx)
+	defer cancel()
+
 	if len(invoiceIDs) == 0 {
 		return nil
 	}
 
-	tx, err := r.db.BeginTx(ctx, nil)
+	tx, err := beginTx(ctx, r.db, r.tx)
 	if err != nil {
 		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
@@ -1098,11 +1648,15 @@ func (r *InvoiceRepository) BulkDelete(ctx context.Context, organizationID uint,
 
 // BulkUpdateStatus updates the status of multiple invoices
 func (r *InvoiceRepository) BulkUpdateStatus(ctx context.Context, organizationID uint, invoiceIDs []uint, status domain.InvoiceStatus) error {
+	defer observeQuery(ctx, "InvoiceRepository", "BulkUpdateStatus", time.Now())
+	ctx, cancel := core.WithStatementTimeout(ctx)
+	defer cancel()
+
 	if len(invoiceIDs) == 0 {
 		return nil
 	}
 
-	tx, err := r.db.BeginTx(ctx, nil)
+	tx, err := beginTx(ctx, r.db, r.tx)
 	if err != nil {
 		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
@@ -1143,6 +1697,10 @@ func (r *InvoiceRepository) BulkUpdateStatus(ctx context.Context, organizationID
 
 // GetInvoiceStats retrieves invoice statistics for an organization
 func (r *InvoiceRepository) GetInvoiceStats(ctx context.Context, organizationID uint) (*repository.InvoiceStats, error) {
+	defer observeQuery(ctx, "InvoiceRepository", "GetInvoiceStats", time.Now())
+	ctx, cancel := core.WithStatementTimeout(ctx)
+	defer cancel()
+
 	query := `
 		SELECT 
 			COUNT(*) as total_invoices,
@@ -1160,7 +1718,7 @@ func (r *InvoiceRepository) GetInvoiceStats(ctx context.Context, organizationID
 		WHERE organization_id = $1`
 
 	stats := &repository.InvoiceStats{}
-	err := r.db.QueryRowContext(ctx, query, organizationID).Scan(
+	err := r.reader().QueryRowContext(ctx, query, organizationID).Scan(
 		&stats.TotalInvoices, &stats.DraftInvoices, &stats.SentInvoices,
 		&stats.PaidInvoices, &stats.CancelledInvoices, &stats.OverdueInvoices,
 		&stats.TotalRevenue, &stats.PaidRevenue, &stats.OutstandingAmount,
@@ -1179,7 +1737,7 @@ func (r *InvoiceRepository) GetInvoiceStats(ctx context.Context, organizationID
 		JOIN invoices i ON p.invoice_id = i.id
 		WHERE i.organization_id = $1 AND i.status = 'paid'`
 
-	err = r.db.QueryRowContext(ctx, paymentTimeQuery, organizationID).Scan(&stats.AveragePaymentTime)
+	err = r.reader().QueryRowContext(ctx, paymentTimeQuery, organizationID).Scan(&stats.AveragePaymentTime)
 	if err != nil {
 		r.logger.Error("Failed to get average payment time", "error", err, "organizationId", organizationID)
 		// Don't fail the entire operation for this
@@ -1191,6 +1749,10 @@ func (r *InvoiceRepository) GetInvoiceStats(ctx context.Context, organizationID
 
 // GetRevenueStats retrieves revenue statistics for a time period
 func (r *InvoiceRepository) GetRevenueStats(ctx context.Context, organizationID uint, from, to time.Time) (*repository.RevenueStats, error) {
+	defer observeQuery(ctx, "InvoiceRepository", "GetRevenueStats", time.Now())
+	ctx, cancel := core.WithStatementTimeout(ctx)
+	defer cancel()
+
 	query := `
 		SELECT 
 			COALESCE(SUM(total_amount), 0) as total_revenue,
@@ -1208,7 +1770,7 @@ func (r *InvoiceRepository) GetRevenueStats(ctx context.Context, organizationID
 		Currency:  "USD", // Default currency, could be made configurable
 	}
 
-	err := r.db.QueryRowContext(ctx, query, organizationID, from, to).Scan(
+	err := r.reader().QueryRowContext(ctx, query, organizationID, from, to).Scan(
 		&stats.TotalRevenue, &stats.PaidRevenue, &stats.OutstandingAmount,
 		&stats.InvoiceCount, &stats.AverageInvoiceValue,
 	)
@@ -1225,7 +1787,7 @@ func (r *InvoiceRepository) GetRevenueStats(ctx context.Context, organizationID
 		JOIN invoices i ON p.invoice_id = i.id
 		WHERE i.organization_id = $1 AND p.payment_date >= $2 AND p.payment_date <= $3`
 
-	err = r.db.QueryRowContext(ctx, paymentCountQuery, organizationID, from, to).Scan(&stats.PaymentCount)
+	err = r.reader().QueryRowContext(ctx, paymentCountQuery, organizationID, from, to).Scan(&stats.PaymentCount)
 	if err != nil {
 		r.logger.Error("Failed to get payment count", "error", err, "organizationId", organizationID)
 		// Don't fail the entire operation for this
@@ -1235,8 +1797,61 @@ func (r *InvoiceRepository) GetRevenueStats(ctx context.Context, organizationID
 	return stats, nil
 }
 
+// GetRevenueTimeSeries buckets the invoices issued in [from, to) with
+// date_trunc. Buckets without invoices are zero-filled.
+func (r *InvoiceRepository) GetRevenueTimeSeries(ctx context.Context, organizationID uint, from, to time.Time, granularity repository.RevenueGranularity) (*repository.RevenueTimeSeries, error) {
+	defer observeQuery(ctx, "InvoiceRepository", "GetRevenueTimeSeries", time.Now())
+	ctx, cancel := core.WithStatementTimeout(ctx)
+	defer cancel()
+
+	series := &repository.RevenueTimeSeries{Granularity: granularity, From: from, To: to}
+	index := make(map[int64]int)
+	for start := granularity.Truncate(from); start.Before(to); start = granularity.Next(start) {
+		index[start.Unix()] = len(series.Buckets)
+		series.Buckets = append(series.Buckets, repository.RevenueBucket{Start: start})
+	}
+
+	query := `
+		SELECT date_trunc($1, issue_date) AS bucket, COALESCE(SUM(total_amount), 0), COALESCE(SUM(paid_amount), 0), COUNT(*)
+		FROM invoices
+		WHERE organization_id = $2 AND issue_date >= $3 AND issue_date < $4
+		GROUP BY bucket`
+
+	rows, err := r.reader().QueryContext(ctx, query, string(granularity), organizationID, from, to)
+	if err != nil {
+		r.logger.Error("Failed to get revenue time series", "error", err, "organizationId", organizationID)
+		return nil, fmt.Errorf("failed to get revenue time series: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var bucket Timestamp
+		var total, paid float64
+		var count int64
+		if err := rows.Scan(&bucket, &total, &paid, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan revenue row: %w", err)
+		}
+		i, ok := index[granularity.Truncate(bucket.In(from.Location())).Unix()]
+		if !ok {
+			continue
+		}
+		series.Buckets[i].TotalRevenue += total
+		series.Buckets[i].PaidRevenue += paid
+		series.Buckets[i].InvoiceCount += count
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate revenue rows: %w", err)
+	}
+	return series, nil
+}
+
 // GetOverdueInvoices retrieves all overdue invoices for an organization
 func (r *InvoiceRepository) GetOverdueInvoices(ctx context.Context, organizationID uint) ([]*domain.Invoice, error) {
+	defer observeQuery(ctx, "InvoiceRepository", "GetOverdueInvoices", time.Now())
+	ctx, cancel := core.WithStatementTimeout(ctx)
+	defer cancel()
+
 	query := fmt.Sprintf(`
                 SELECT %s
                 FROM invoices
@@ -1246,7 +1861,7 @@ func (r *InvoiceRepository) GetOverdueInvoices(ctx context.Context, organization
                   AND status NOT IN ('paid', 'canceled')
                 ORDER BY due_date ASC`, invoiceColumns)
 
-	rows, err := r.db.QueryContext(ctx, query, organizationID)
+	rows, err := r.reader().QueryContext(ctx, query, organizationID)
 	if err != nil {
 		r.logger.Error("Failed to get overdue invoices", "error", err, "organizationId", organizationID)
 		return nil, fmt.Errorf("failed to get overdue invoices: %w", err)
@@ -1274,6 +1889,10 @@ func (r *InvoiceRepository) GetOverdueInvoices(ctx context.Context, organization
 
 // GetUpcomingDueInvoices retrieves invoices due within the specified number of days
 func (r *InvoiceRepository) GetUpcomingDueInvoices(ctx context.Context, organizationID uint, days int) ([]*domain.Invoice, error) {
+	defer observeQuery(ctx, "InvoiceRepository", "GetUpcomingDueInvoices", time.Now())
+	ctx, cancel := core.WithStatementTimeout(ctx)
+	defer cancel()
+
 	query := fmt.Sprintf(`
                 SELECT %s
                 FROM invoices
@@ -1283,7 +1902,7 @@ func (r *InvoiceRepository) GetUpcomingDueInvoices(ctx context.Context, organiza
                   AND status NOT IN ('paid', 'canceled')
                 ORDER BY due_date ASC`, invoiceColumns, days)
 
-	rows, err := r.db.QueryContext(ctx, query, organizationID)
+	rows, err := r.reader().QueryContext(ctx, query, organizationID)
 	if err != nil {
 		r.logger.Error("Failed to get upcoming due invoices", "error", err, "organizationId", organizationID)
 		return nil, fmt.Errorf("failed to get upcoming due invoices: %w", err)
@@ -1310,54 +1929,172 @@ func (r *InvoiceRepository) GetUpcomingDueInvoices(ctx context.Context, organiza
 }
 
 // GenerateInvoiceNumber generates a unique invoice number for the organization
+// from its invoice number format, or domain.DefaultInvoiceNumberFormat
 func (r *InvoiceRepository) GenerateInvoiceNumber(ctx context.Context, organizationID uint, invoiceType domain.InvoiceType) (string, error) {
-	// Get the current year and month
-	now := time.Now()
-	year := now.Year()
-	month := int(now.Month())
-
-	// Get the next sequence number for this organization, type, and period
-	query := `
-		SELECT COALESCE(MAX(
-			CAST(SUBSTRING(invoice_number FROM '[0-9]+$') AS INTEGER)
-		), 0) + 1
-		FROM invoices 
-		WHERE organization_id = $1 
-		  AND type = $2 
-		  AND EXTRACT(YEAR FROM created_at) = $3 
-		  AND EXTRACT(MONTH FROM created_at) = $4`
+	defer observeQuery(ctx, "InvoiceRepository", "GenerateInvoiceNumber", time.Now())
+	ctx, cancel := core.WithStatementTimeout(ctx)
+	defer cancel()
 
-	var nextNumber int
-	err := r.db.QueryRowContext(ctx, query, organizationID, invoiceType, year, month).Scan(&nextNumber)
+	numbers, err := r.advanceInvoiceSequence(ctx, organizationID, invoiceType, 1)
 	if err != nil {
 		r.logger.Error("Failed to generate invoice number", "error", err, "organizationId", organizationID)
 		return "", fmt.Errorf("failed to generate invoice number: %w", err)
 	}
 
-	// Format the invoice number based on type
-	var prefix string
-	switch invoiceType {
-	case domain.InvoiceTypeInvoice:
-		prefix = "INV"
-	case domain.InvoiceTypeQuote:
-		prefix = "QUO"
-	case domain.InvoiceTypeCreditNote:
-		prefix = "CN"
-	case domain.InvoiceTypeProforma:
-		prefix = "PRO"
-	default:
-		prefix = "INV"
+	r.logger.Info("Generated invoice number", "invoiceNumber", numbers[0], "organizationId", organizationID)
+	return numbers[0], nil
+}
+
+// ReserveInvoiceNumbers advances the organization's sequence by count in one
+// step and returns the reserved numbers in order, so a batch of invoices can
+// be numbered up front without interleaving with concurrent creates
+func (r *InvoiceRepository) ReserveInvoiceNumbers(ctx context.Context, organizationID uint, invoiceType domain.InvoiceType, count int) ([]string, error) {
+	defer observeQuery(ctx, "InvoiceRepository", "ReserveInvoiceNumbers", time.Now())
+	ctx, cancel := core.WithStatementTimeout(ctx)
+	defer cancel()
+
+	if count <= 0 {
+		return nil, fmt.Errorf("invalid invoice number count: %d", count)
+	}
+
+	numbers, err := r.advanceInvoiceSequence(ctx, organizationID, invoiceType, count)
+	if err != nil {
+		r.logger.Error("Failed to reserve invoice numbers", "error", err, "organizationId", organizationID, "count", count)
+		return nil, fmt.Errorf("failed to reserve invoice numbers: %w", err)
+	}
+
+	r.logger.Info("Reserved invoice numbers", "first", numbers[0], "last", numbers[len(numbers)-1], "organizationId", organizationID)
+	return numbers, nil
+}
+
+// advanceInvoiceSequence bumps the counter of the current period by count
+// and renders the numbers it skipped over
+func (r *InvoiceRepository) advanceInvoiceSequence(ctx context.Context, organizationID uint, invoiceType domain.InvoiceType, count int) ([]string, error) {
+	format, err := r.invoiceNumberFormat(ctx, organizationID)
+	if err != nil {
+		return nil, err
+	}
+	prefix := domain.InvoiceNumberPrefix(invoiceType)
+	pattern, err := format.SequencePattern(prefix)
+	if err != nil {
+		return nil, err
 	}
 
-	// Format: PREFIX-YYYY-MM-NNNN (e.g., INV-2024-03-0001)
-	invoiceNumber := fmt.Sprintf("%s-%04d-%02d-%04d", prefix, year, month, nextNumber)
+	now := time.Now()
+	start, end := format.SequencePeriod(now)
+	period := invoiceSequencePeriod(start, end)
+
+	// Bump the counter for this organization, type and period. The row lock
+	// the update takes is held until the surrounding transaction ends, so
+	// concurrent creates never see the same value.
+	var last int
+	err = r.conn().QueryRowContext(ctx, `
+		UPDATE invoice_number_sequences SET last_value = last_value + $1, updated_at = $2
+		WHERE organization_id = $3 AND type = $4 AND period = $5
+		RETURNING last_value`,
+		count, now, organizationID, invoiceType, period,
+	).Scan(&last)
+	if err == sql.ErrNoRows {
+		last, err = r.seedInvoiceSequence(ctx, organizationID, invoiceType, period, pattern, start, end, now, count)
+	}
+	if err != nil {
+		return nil, err
+	}
 
-	r.logger.Info("Generated invoice number", "invoiceNumber", invoiceNumber, "organizationId", organizationID)
-	return invoiceNumber, nil
+	numbers := make([]string, 0, count)
+	for sequence := last - count + 1; sequence <= last; sequence++ {
+		number, err := format.Render(prefix, now, sequence)
+		if err != nil {
+			return nil, err
+		}
+		numbers = append(numbers, number)
+	}
+	return numbers, nil
+}
+
+// seedInvoiceSequence creates the counter of a period, advanced by count
+// past the highest number already issued in it. When another transaction
+// creates the counter first the insert falls back to bumping it.
+func (r *InvoiceRepository) seedInvoiceSequence(ctx context.Context, organizationID uint, invoiceType domain.InvoiceType, period string, pattern *regexp.Regexp, start, end, now time.Time, count int) (int, error) {
+	query := `SELECT invoice_number FROM invoices WHERE organization_id = $1 AND type = $2`
+	args := []interface{}{organizationID, invoiceType}
+	if !start.IsZero() {
+		query += ` AND created_at >= $3 AND created_at < $4`
+		args = append(args, start, end)
+	}
+
+	rows, err := r.conn().QueryContext(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	highest := 0
+	for scanned := 0; rows.Next(); scanned++ {
+		if err := checkContext(ctx, scanned); err != nil {
+			return 0, err
+		}
+		var number string
+		if err := rows.Scan(&number); err != nil {
+			return 0, fmt.Errorf("failed to scan invoice number: %w", err)
+		}
+		// Numbers issued under another format do not count
+		if match := pattern.FindStringSubmatch(number); match != nil {
+			if sequence, err := strconv.Atoi(match[1]); err == nil && sequence > highest {
+				highest = sequence
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("failed to iterate invoice numbers: %w", err)
+	}
+	rows.Close()
+
+	var next int
+	err = r.conn().QueryRowContext(ctx, `
+		INSERT INTO invoice_number_sequences (organization_id, type, period, last_value, updated_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (organization_id, type, period)
+		DO UPDATE SET last_value = invoice_number_sequences.last_value + $6, updated_at = excluded.updated_at
+		RETURNING last_value`,
+		organizationID, invoiceType, period, highest+count, now, count,
+	).Scan(&next)
+	return next, err
+}
+
+// invoiceSequencePeriod keys a sequence period by its month or year, and the
+// never restarting sequence by the empty string
+func invoiceSequencePeriod(start, end time.Time) string {
+	switch {
+	case start.IsZero():
+		return ""
+	case start.AddDate(0, 1, 0).Equal(end):
+		return start.Format("2006-01")
+	default:
+		return start.Format("2006")
+	}
+}
+
+// invoiceNumberFormat returns the organization's invoice number format
+func (r *InvoiceRepository) invoiceNumberFormat(ctx context.Context, organizationID uint) (domain.InvoiceNumberFormat, error) {
+	var format string
+	err := r.readConn().QueryRowContext(ctx,
+		`SELECT invoice_number_format FROM organizations WHERE id = $1`, organizationID,
+	).Scan(&format)
+	if err != nil && err != sql.ErrNoRows {
+		r.logger.Error("Failed to get invoice number format", "error", err, "organizationId", organizationID)
+		return "", fmt.Errorf("failed to get invoice number format: %w", err)
+	}
+	return domain.InvoiceNumberFormat(format).OrDefault(), nil
 }
 
 // ListPaginated returns paginated invoices for an organization
 func (r *InvoiceRepository) ListPaginated(ctx context.Context, organizationID uint, params repository.PaginationParams) (repository.PaginationResult[*domain.Invoice], error) {
+	defer observeQuery(ctx, "InvoiceRepository", "ListPaginated", time.Now())
+	ctx, cancel := core.WithStatementTimeout(ctx)
+	defer cancel()
+	params = params.Clamped()
+
 	baseQuery := `
 		SELECT i.id, i.organization_id, i.contact_id, i.invoice_number, i.invoice_type,
 			   i.status, i.currency, i.subtotal, i.tax_amount, i.total_amount,
@@ -1369,7 +2106,7 @@ func (r *InvoiceRepository) ListPaginated(ctx context.Context, organizationID ui
 
 	// Get total count
 	var total int64
-	if err := r.db.QueryRowContext(ctx, countQuery, organizationID).Scan(&total); err != nil {
+	if err := r.reader().QueryRowContext(ctx, countQuery, organizationID).Scan(&total); err != nil {
 		return repository.PaginationResult[*domain.Invoice]{}, fmt.Errorf("failed to get total count: %w", err)
 	}
 
@@ -1379,7 +2116,7 @@ func (r *InvoiceRepository) ListPaginated(ctx context.Context, organizationID ui
 	paginatedQuery := helper.BuildPaginatedQuery(baseQuery, params, allowedSortFields)
 
 	// Execute query
-	rows, err := r.db.QueryContext(ctx, paginatedQuery, organizationID)
+	rows, err := r.reader().QueryContext(ctx, paginatedQuery, organizationID)
 	if err != nil {
 		return repository.PaginationResult[*domain.Invoice]{}, fmt.Errorf("failed to execute paginated query: %w", err)
 	}
@@ -1411,6 +2148,11 @@ func (r *InvoiceRepository) ListPaginated(ctx context.Context, organizationID ui
 
 // SearchPaginated returns paginated invoices matching search query
 func (r *InvoiceRepository) SearchPaginated(ctx context.Context, organizationID uint, query string, params repository.PaginationParams) (repository.PaginationResult[*domain.Invoice], error) {
+	defer observeQuery(ctx, "InvoiceRepository", "SearchPaginated", time.Now())
+	ctx, cancel := core.WithStatementTimeout(ctx)
+	defer cancel()
+	params = params.Clamped()
+
 	baseQuery := `
 		SELECT i.id, i.organization_id, i.contact_id, i.invoice_number, i.invoice_type,
 			   i.status, i.currency, i.subtotal, i.tax_amount, i.total_amount,
@@ -1431,7 +2173,7 @@ func (r *InvoiceRepository) SearchPaginated(ctx context.Context, organizationID
 
 	// Get total count
 	var total int64
-	if err := r.db.QueryRowContext(ctx, countQuery, args...).Scan(&total); err != nil {
+	if err := r.reader().QueryRowContext(ctx, countQuery, args...).Scan(&total); err != nil {
 		return repository.PaginationResult[*domain.Invoice]{}, fmt.Errorf("failed to get total count: %w", err)
 	}
 
@@ -1440,7 +2182,7 @@ func (r *InvoiceRepository) SearchPaginated(ctx context.Context, organizationID
 	paginatedQuery := helper.BuildPaginatedQuery(searchQuery, params, allowedSortFields)
 
 	// Execute query
-	rows, err := r.db.QueryContext(ctx, paginatedQuery, args...)
+	rows, err := r.reader().QueryContext(ctx, paginatedQuery, args...)
 	if err != nil {
 		return repository.PaginationResult[*domain.Invoice]{}, fmt.Errorf("failed to execute paginated query: %w", err)
 	}
@@ -0,0 +1,477 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/pmaojo/kthulu-go/backend/core"
+	"github.com/pmaojo/kthulu-go/backend/internal/domain"
+	"github.com/pmaojo/kthulu-go/backend/internal/domain/repository"
+	"github.com/pmaojo/kthulu-go/backend/internal/infrastructure/testutils"
+)
+
+// setupInvoiceTables creates the invoice tables used by InvoiceRepository tests.
+func setupInvoiceTables(t *testing.T, sqlDB *sql.DB) {
+	_, err := sqlDB.Exec(`
+		CREATE TABLE invoices (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			organization_id INTEGER NOT NULL,
+			contact_id INTEGER NOT NULL DEFAULT 0,
+			invoice_number TEXT NOT NULL,
+			type TEXT NOT NULL DEFAULT 'invoice',
+			status TEXT NOT NULL DEFAULT 'draft',
+			currency TEXT NOT NULL DEFAULT 'EUR',
+			exchange_rate REAL NOT NULL DEFAULT 1,
+			subtotal REAL NOT NULL DEFAULT 0,
+			tax_amount REAL NOT NULL DEFAULT 0,
+			discount_amount REAL NOT NULL DEFAULT 0,
+			total_amount REAL NOT NULL DEFAULT 0,
+			paid_amount REAL NOT NULL DEFAULT 0,
+			balance_due REAL NOT NULL DEFAULT 0,
+			issue_date DATETIME NOT NULL,
+			due_date DATETIME,
+			payment_terms TEXT NOT NULL DEFAULT '',
+			notes TEXT NOT NULL DEFAULT '',
+			terms_conditions TEXT NOT NULL DEFAULT '',
+			created_by INTEGER NOT NULL DEFAULT 1,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		);
+
+		CREATE TABLE invoice_items (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			invoice_id INTEGER NOT NULL,
+			product_id INTEGER,
+			product_variant_id INTEGER,
+			description TEXT NOT NULL,
+			quantity REAL NOT NULL,
+			unit_price REAL NOT NULL,
+			discount_percent REAL NOT NULL DEFAULT 0,
+			discount_amount REAL NOT NULL DEFAULT 0,
+			tax_rate REAL NOT NULL DEFAULT 0,
+			tax_amount REAL NOT NULL DEFAULT 0,
+			reverse_charge BOOLEAN NOT NULL DEFAULT FALSE,
+			line_total REAL NOT NULL,
+			sort_order INTEGER NOT NULL DEFAULT 0,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		);
+
+		CREATE TABLE payments (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			organization_id INTEGER NOT NULL,
+			invoice_id INTEGER NOT NULL,
+			payment_method TEXT NOT NULL,
+			reference_number TEXT NOT NULL DEFAULT '',
+			amount REAL NOT NULL,
+			currency TEXT NOT NULL DEFAULT 'EUR',
+			exchange_rate REAL NOT NULL DEFAULT 1,
+			payment_date DATETIME NOT NULL,
+			notes TEXT NOT NULL DEFAULT '',
+			created_by INTEGER NOT NULL DEFAULT 1,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		);
+
+		CREATE TABLE invoice_number_sequences (
+			organization_id INTEGER NOT NULL,
+			type TEXT NOT NULL,
+			period TEXT NOT NULL,
+			last_value INTEGER NOT NULL,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (organization_id, type, period)
+		);
+	`)
+	require.NoError(t, err)
+}
+
+func newTestInvoiceRepository(t *testing.T) (*InvoiceRepository, *sql.DB) {
+	testDB := testutils.SetupTestDB(t)
+	t.Cleanup(func() { testutils.CleanupTestDB(t, testDB) })
+
+	sqlDB, err := testDB.DB()
+	require.NoError(t, err)
+	setupInvoiceTables(t, sqlDB)
+
+	repo := NewInvoiceRepository(sqlDB, core.NewLoggerFromZap(zap.NewNop()), nil, nil).(*InvoiceRepository)
+	return repo, sqlDB
+}
+
+// createTestInvoice inserts an invoice and returns its id
+func createTestInvoice(t *testing.T, sqlDB *sql.DB, organizationID uint, status string, issueDate time.Time, total, paid float64) int64 {
+	t.Helper()
+	result, err := sqlDB.Exec(
+		`INSERT INTO invoices (organization_id, invoice_number, status, issue_date, total_amount, paid_amount, balance_due) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		organizationID, issueDate.Format("INV-20060102-150405"), status, issueDate, total, paid, total-paid,
+	)
+	require.NoError(t, err)
+	id, err := result.LastInsertId()
+	require.NoError(t, err)
+	return id
+}
+
+// createTestInvoiceItem adds a line for productID to an invoice
+func createTestInvoiceItem(t *testing.T, sqlDB *sql.DB, invoiceID int64, productID uint, quantity, unitPrice float64) {
+	t.Helper()
+	_, err := sqlDB.Exec(
+		`INSERT INTO invoice_items (invoice_id, product_id, description, quantity, unit_price, line_total) VALUES (?, ?, 'Line', ?, ?, ?)`,
+		invoiceID, productID, quantity, unitPrice, quantity*unitPrice,
+	)
+	require.NoError(t, err)
+}
+
+func TestInvoiceRepositoryList_FiltersByPaymentState(t *testing.T) {
+	repo, sqlDB := newTestInvoiceRepository(t)
+	issued := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	unpaid := createTestInvoice(t, sqlDB, 1, "sent", issued, 100, 0)
+	partial := createTestInvoice(t, sqlDB, 1, "partial", issued.AddDate(0, 0, 1), 100, 40)
+	paid := createTestInvoice(t, sqlDB, 1, "paid", issued.AddDate(0, 0, 2), 100, 100)
+	createTestInvoice(t, sqlDB, 2, "sent", issued, 100, 0) // another organization
+
+	for state, want := range map[string]int64{
+		repository.InvoicePaymentStateUnpaid:  unpaid,
+		repository.InvoicePaymentStatePartial: partial,
+		repository.InvoicePaymentStatePaid:    paid,
+	} {
+		filters := repository.InvoiceFilters{PaymentState: &state}
+		require.NoError(t, filters.Validate())
+		invoices, total, err := repo.List(context.Background(), 1, filters)
+		require.NoError(t, err)
+		require.Equal(t, int64(1), total, state)
+		assert.Equal(t, uint(want), invoices[0].ID, state)
+	}
+
+	unknown := "overpaid"
+	filters := repository.InvoiceFilters{PaymentState: &unknown}
+	assert.ErrorIs(t, filters.Validate(), domain.ErrInvalidInvoiceFilters)
+}
+
+func TestInvoiceRepositoryListByContacts_PagesEachContact(t *testing.T) {
+	repo, sqlDB := newTestInvoiceRepository(t)
+	issued := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	ids := map[uint][]uint{}
+	for day := 0; day < 3; day++ {
+		for _, contactID := range []uint{10, 11} {
+			id := createTestInvoice(t, sqlDB, 1, "sent", issued.AddDate(0, 0, day).Add(time.Duration(contactID)*time.Second), 100, 0)
+			_, err := sqlDB.Exec(`UPDATE invoices SET contact_id = ? WHERE id = ?`, contactID, id)
+			require.NoError(t, err)
+			ids[contactID] = append(ids[contactID], uint(id))
+		}
+	}
+	other := createTestInvoice(t, sqlDB, 2, "sent", issued, 100, 0) // another organization
+	_, err := sqlDB.Exec(`UPDATE invoices SET contact_id = 10 WHERE id = ?`, other)
+	require.NoError(t, err)
+
+	for page, want := range map[int]map[uint][]uint{
+		1: {10: ids[10][:2], 11: ids[11][:2]},
+		2: {10: ids[10][2:], 11: ids[11][2:]},
+	} {
+		filters := repository.InvoiceFilters{Page: page, PageSize: 2, SortBy: "issue_date", SortOrder: "asc"}
+		invoices, totals, err := repo.ListByContacts(context.Background(), 1, []uint{10, 11, 12}, filters)
+		require.NoError(t, err)
+		assert.Equal(t, map[uint]int64{10: 3, 11: 3}, totals, "page %d", page)
+		got := map[uint][]uint{}
+		for contactID, list := range invoices {
+			for _, invoice := range list {
+				got[contactID] = append(got[contactID], invoice.ID)
+			}
+		}
+		assert.Equal(t, want, got, "page %d", page)
+	}
+}
+
+func TestInvoiceRepositoryGetRevenueTimeSeries_ZeroFillsMonths(t *testing.T) {
+	repo, sqlDB := newTestInvoiceRepository(t)
+	day := func(month time.Month, d int) time.Time { return time.Date(2024, month, d, 10, 0, 0, 0, time.UTC) }
+
+	createTestInvoice(t, sqlDB, 1, "paid", day(time.January, 5), 100, 100)
+	createTestInvoice(t, sqlDB, 1, "sent", day(time.January, 31), 50, 0)
+	createTestInvoice(t, sqlDB, 1, "paid", day(time.April, 1), 200, 150)
+	createTestInvoice(t, sqlDB, 2, "paid", day(time.February, 10), 999, 999)
+	createTestInvoice(t, sqlDB, 1, "paid", day(time.May, 1), 999, 999) // outside the range
+
+	from := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, time.May, 1, 0, 0, 0, 0, time.UTC)
+	series, err := repo.GetRevenueTimeSeries(context.Background(), 1, from, to, repository.RevenueGranularityMonth)
+	require.NoError(t, err)
+
+	require.Len(t, series.Buckets, 4)
+	want := []repository.RevenueBucket{
+		{Start: from, TotalRevenue: 150, PaidRevenue: 100, InvoiceCount: 2},
+		{Start: from.AddDate(0, 1, 0)},
+		{Start: from.AddDate(0, 2, 0)},
+		{Start: from.AddDate(0, 3, 0), TotalRevenue: 200, PaidRevenue: 150, InvoiceCount: 1},
+	}
+	for i, bucket := range series.Buckets {
+		assert.True(t, want[i].Start.Equal(bucket.Start), "bucket %d starts %s", i, bucket.Start)
+		assert.Equal(t, want[i].TotalRevenue, bucket.TotalRevenue, "bucket %d total", i)
+		assert.Equal(t, want[i].PaidRevenue, bucket.PaidRevenue, "bucket %d paid", i)
+		assert.Equal(t, want[i].InvoiceCount, bucket.InvoiceCount, "bucket %d count", i)
+	}
+}
+
+func TestInvoiceRepositoryGetRevenueTimeSeries_Weeks(t *testing.T) {
+	repo, sqlDB := newTestInvoiceRepository(t)
+
+	// 2024-01-03 is a Wednesday, inside the week starting Monday 2024-01-01
+	createTestInvoice(t, sqlDB, 1, "paid", time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC), 10, 10)
+	createTestInvoice(t, sqlDB, 1, "paid", time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC), 20, 20)
+
+	from := time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 1, 20, 0, 0, 0, 0, time.UTC)
+	series, err := repo.GetRevenueTimeSeries(context.Background(), 1, from, to, repository.RevenueGranularityWeek)
+	require.NoError(t, err)
+
+	require.Len(t, series.Buckets, 3)
+	assert.Equal(t, "2024-01-01", series.Buckets[0].Start.Format("2006-01-02"))
+	assert.Equal(t, []float64{10, 0, 20}, []float64{series.Buckets[0].TotalRevenue, series.Buckets[1].TotalRevenue, series.Buckets[2].TotalRevenue})
+}
+
+func TestInvoiceRepositoryGenerateInvoiceNumber_UsesOrganizationFormat(t *testing.T) {
+	repo, sqlDB := newTestInvoiceRepository(t)
+	ctx := context.Background()
+	now := time.Now()
+	addInvoice := func(organizationID uint, invoiceType, number string) {
+		_, err := sqlDB.Exec(
+			`INSERT INTO invoices (organization_id, invoice_number, type, issue_date, created_at) VALUES (?, ?, ?, ?, ?)`,
+			organizationID, number, invoiceType, now, now,
+		)
+		require.NoError(t, err)
+	}
+	_, err := sqlDB.Exec(`INSERT INTO organizations (id, name, slug, invoice_number_format) VALUES (1, 'Acme', 'acme', '{prefix}/{year}/{seq:5}'), (2, 'Default', 'default', '')`)
+	require.NoError(t, err)
+
+	year := now.Format("2006")
+	addInvoice(1, "invoice", "INV/"+year+"/00041")
+	addInvoice(1, "invoice", "INV-"+now.Format("2006-01")+"-0999") // issued under the old format
+	addInvoice(1, "quote", "QUO/"+year+"/00100")
+
+	number, err := repo.GenerateInvoiceNumber(ctx, 1, domain.InvoiceTypeInvoice)
+	require.NoError(t, err)
+	assert.Equal(t, "INV/"+year+"/00042", number)
+
+	number, err = repo.GenerateInvoiceNumber(ctx, 1, domain.InvoiceTypeCreditNote)
+	require.NoError(t, err)
+	assert.Equal(t, "CN/"+year+"/00001", number)
+
+	// Organizations without a format, or without a row, keep the default
+	addInvoice(2, "invoice", "INV-"+now.Format("2006-01")+"-0007")
+	number, err = repo.GenerateInvoiceNumber(ctx, 2, domain.InvoiceTypeInvoice)
+	require.NoError(t, err)
+	assert.Equal(t, "INV-"+now.Format("2006-01")+"-0008", number)
+
+	number, err = repo.GenerateInvoiceNumber(ctx, 3, domain.InvoiceTypeProforma)
+	require.NoError(t, err)
+	assert.Equal(t, "PRO-"+now.Format("2006-01")+"-0001", number)
+}
+
+func TestInvoiceRepositoryGenerateInvoiceNumber_UniqueUnderConcurrency(t *testing.T) {
+	repo, sqlDB := newTestInvoiceRepository(t)
+	ctx := context.Background()
+	// Every connection to :memory: opens a database of its own
+	sqlDB.SetMaxOpenConns(1)
+
+	// Every goroutine generates its number before any invoice is stored,
+	// which is where reading the highest stored number used to collide
+	const creates = 50
+	numbers := make(chan string, creates)
+	errs := make(chan error, creates)
+	var generated, wg sync.WaitGroup
+	generated.Add(creates)
+	for i := 0; i < creates; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			number, err := repo.GenerateInvoiceNumber(ctx, 1, domain.InvoiceTypeInvoice)
+			generated.Done()
+			if err != nil {
+				errs <- err
+				return
+			}
+			generated.Wait()
+			if _, err := sqlDB.Exec(`INSERT INTO invoices (organization_id, invoice_number, issue_date) VALUES (1, ?, ?)`, number, time.Now()); err != nil {
+				errs <- err
+				return
+			}
+			numbers <- number
+		}()
+	}
+	wg.Wait()
+	close(numbers)
+	close(errs)
+
+	for err := range errs {
+		require.NoError(t, err)
+	}
+	seen := make(map[string]bool, creates)
+	for number := range numbers {
+		assert.False(t, seen[number], "invoice number %s was issued twice", number)
+		seen[number] = true
+	}
+	assert.Len(t, seen, creates)
+}
+
+func TestInvoiceRepositoryReserveInvoiceNumbers(t *testing.T) {
+	repo, sqlDB := newTestInvoiceRepository(t)
+	ctx := context.Background()
+	month := time.Now().Format("2006-01")
+	_, err := sqlDB.Exec(`INSERT INTO invoices (organization_id, invoice_number, type, issue_date, created_at) VALUES (1, ?, 'invoice', ?, ?)`, "INV-"+month+"-0007", time.Now(), time.Now())
+	require.NoError(t, err)
+
+	numbers, err := repo.ReserveInvoiceNumbers(ctx, 1, domain.InvoiceTypeInvoice, 3)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"INV-" + month + "-0008", "INV-" + month + "-0009", "INV-" + month + "-0010"}, numbers)
+
+	number, err := repo.GenerateInvoiceNumber(ctx, 1, domain.InvoiceTypeInvoice)
+	require.NoError(t, err)
+	assert.Equal(t, "INV-"+month+"-0011", number)
+
+	_, err = repo.ReserveInvoiceNumbers(ctx, 1, domain.InvoiceTypeInvoice, 0)
+	assert.Error(t, err)
+}
+
+func TestInvoiceRepositoryReserveInvoiceNumbers_ConcurrentBlocksDontOverlap(t *testing.T) {
+	repo, sqlDB := newTestInvoiceRepository(t)
+	ctx := context.Background()
+	// Every connection to :memory: opens a database of its own
+	sqlDB.SetMaxOpenConns(1)
+
+	const reservations, count = 10, 5
+	blocks := make(chan []string, reservations)
+	errs := make(chan error, reservations)
+	var wg sync.WaitGroup
+	for i := 0; i < reservations; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			numbers, err := repo.ReserveInvoiceNumbers(ctx, 1, domain.InvoiceTypeInvoice, count)
+			if err != nil {
+				errs <- err
+				return
+			}
+			blocks <- numbers
+		}()
+	}
+	wg.Wait()
+	close(blocks)
+	close(errs)
+
+	for err := range errs {
+		require.NoError(t, err)
+	}
+	seen := make(map[string]bool, reservations*count)
+	for numbers := range blocks {
+		require.Len(t, numbers, count)
+		for _, number := range numbers {
+			assert.False(t, seen[number], "invoice number %s was reserved twice", number)
+			seen[number] = true
+		}
+	}
+	assert.Len(t, seen, reservations*count)
+}
+
+func TestInvoiceRepositoryReorderItems(t *testing.T) {
+	repo, sqlDB := newTestInvoiceRepository(t)
+	ctx := context.Background()
+	invoiceID := createTestInvoice(t, sqlDB, 1, "draft", time.Now(), 0, 0)
+	for productID := uint(1); productID <= 3; productID++ {
+		createTestInvoiceItem(t, sqlDB, invoiceID, productID, 1, 10)
+	}
+
+	items, err := repo.GetItemsByInvoiceID(ctx, uint(invoiceID))
+	require.NoError(t, err)
+	require.Len(t, items, 3)
+	first, second, third := items[0].ID, items[1].ID, items[2].ID
+
+	require.NoError(t, repo.ReorderItems(ctx, uint(invoiceID), []uint{third, first, second}))
+
+	items, err = repo.GetItemsByInvoiceID(ctx, uint(invoiceID))
+	require.NoError(t, err)
+	require.Len(t, items, 3)
+	assert.Equal(t, []uint{third, first, second}, []uint{items[0].ID, items[1].ID, items[2].ID})
+	assert.Equal(t, []int{0, 1, 2}, []int{items[0].SortOrder, items[1].SortOrder, items[2].SortOrder})
+
+	// Every item must be listed exactly once
+	assert.ErrorIs(t, repo.ReorderItems(ctx, uint(invoiceID), []uint{third, first}), domain.ErrInvalidItemOrder)
+	assert.ErrorIs(t, repo.ReorderItems(ctx, uint(invoiceID), []uint{third, third, first}), domain.ErrInvalidItemOrder)
+}
+
+func TestInvoiceRepositoryReorderItems_RejectsItemOfAnotherInvoice(t *testing.T) {
+	repo, sqlDB := newTestInvoiceRepository(t)
+	ctx := context.Background()
+	invoiceID := createTestInvoice(t, sqlDB, 1, "draft", time.Now(), 0, 0)
+	otherID := createTestInvoice(t, sqlDB, 1, "draft", time.Now().Add(time.Second), 0, 0)
+	createTestInvoiceItem(t, sqlDB, invoiceID, 1, 1, 10)
+	createTestInvoiceItem(t, sqlDB, invoiceID, 2, 1, 10)
+	createTestInvoiceItem(t, sqlDB, otherID, 3, 1, 10)
+
+	items, err := repo.GetItemsByInvoiceID(ctx, uint(invoiceID))
+	require.NoError(t, err)
+	others, err := repo.GetItemsByInvoiceID(ctx, uint(otherID))
+	require.NoError(t, err)
+
+	err = repo.ReorderItems(ctx, uint(invoiceID), []uint{others[0].ID, items[0].ID})
+	assert.ErrorIs(t, err, domain.ErrInvalidItemOrder)
+
+	// Nothing was reordered
+	items, err = repo.GetItemsByInvoiceID(ctx, uint(invoiceID))
+	require.NoError(t, err)
+	assert.Equal(t, []int{0, 0}, []int{items[0].SortOrder, items[1].SortOrder})
+	others, err = repo.GetItemsByInvoiceID(ctx, uint(otherID))
+	require.NoError(t, err)
+	assert.Equal(t, 0, others[0].SortOrder)
+}
+
+func TestInvoiceRepositoryUpdateFields_OnlyChangesGivenColumns(t *testing.T) {
+	repo, sqlDB := newTestInvoiceRepository(t)
+	ctx := context.Background()
+	invoiceID := createTestInvoice(t, sqlDB, 1, "sent", time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), 121, 21)
+	_, err := sqlDB.Exec(`UPDATE invoices SET payment_terms = 'Net 30', terms_conditions = 'No refunds' WHERE id = ?`, invoiceID)
+	require.NoError(t, err)
+
+	before, err := repo.GetByID(ctx, 1, uint(invoiceID))
+	require.NoError(t, err)
+
+	require.NoError(t, repo.UpdateFields(ctx, 1, uint(invoiceID), map[string]any{"notes": "Paid by transfer"}))
+
+	after, err := repo.GetByID(ctx, 1, uint(invoiceID))
+	require.NoError(t, err)
+	assert.Equal(t, "Paid by transfer", after.Notes)
+
+	// Everything else is as it was
+	after.Notes = before.Notes
+	after.UpdatedAt = before.UpdatedAt
+	assert.Equal(t, before, after)
+}
+
+func TestInvoiceRepositoryUpdateFields_Rejections(t *testing.T) {
+	repo, sqlDB := newTestInvoiceRepository(t)
+	ctx := context.Background()
+	invoiceID := createTestInvoice(t, sqlDB, 1, "draft", time.Now(), 100, 0)
+
+	for _, fields := range []map[string]any{
+		{},
+		{"status": "paid"},
+		{"notes": "ok", "total_amount": 0},
+		{"notes = 'x'; --": "injected"},
+	} {
+		assert.ErrorIs(t, repo.UpdateFields(ctx, 1, uint(invoiceID), fields), domain.ErrInvalidInvoiceField, "fields %v", fields)
+	}
+
+	// The invoice must exist within the organization
+	assert.ErrorIs(t, repo.UpdateFields(ctx, 2, uint(invoiceID), map[string]any{"notes": "x"}), domain.ErrInvoiceNotFound)
+	assert.ErrorIs(t, repo.UpdateFields(ctx, 1, uint(invoiceID)+1, map[string]any{"notes": "x"}), domain.ErrInvoiceNotFound)
+
+	invoice, err := repo.GetByID(ctx, 1, uint(invoiceID))
+	require.NoError(t, err)
+	assert.Equal(t, domain.InvoiceStatusDraft, invoice.Status)
+	assert.Equal(t, 100.0, invoice.TotalAmount)
+	assert.Empty(t, invoice.Notes)
+}
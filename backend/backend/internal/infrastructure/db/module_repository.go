@@ -198,6 +198,8 @@ func (r *ModuleRepository) Count(ctx context.Context) (int64, error) {
 
 // FindPaginated returns paginated modules
 func (r *ModuleRepository) FindPaginated(ctx context.Context, params repository.PaginationParams) (repository.PaginationResult[*domain.ModuleInfo], error) {
+	params = params.Clamped()
+
 	var models []ModuleModel
 	var total int64
 
@@ -240,6 +242,8 @@ func (r *ModuleRepository) FindPaginated(ctx context.Context, params repository.
 
 // SearchPaginated returns paginated modules matching search query
 func (r *ModuleRepository) SearchPaginated(ctx context.Context, query string, params repository.PaginationParams) (repository.PaginationResult[*domain.ModuleInfo], error) {
+	params = params.Clamped()
+
 	var models []ModuleModel
 	var total int64
 
@@ -4,6 +4,8 @@ package db
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strings"
 	"time"
 
 	"gorm.io/gorm"
@@ -32,6 +34,9 @@ type organizationModel struct {
 	CreatedAt   time.Time
 	UpdatedAt   time.Time
 
+	// Settings
+	InvoiceNumberFormat string `gorm:"not null;default:'';size:100"`
+
 	// Relationships
 	Users []organizationUserModel `gorm:"foreignKey:OrganizationID"`
 }
@@ -169,6 +174,79 @@ func (r *OrganizationRepository) Delete(ctx context.Context, id uint) error {
 	return nil
 }
 
+// organizationCascade lists the tables holding an organization's data,
+// children before their parents, with the condition selecting its rows and
+// the table that condition goes through.
+// VeriFactu records and audit logs are kept for their legal retention period.
+var organizationCascade = []struct{ table, parent, where string }{
+	{"payments", "invoices", "invoice_id IN (SELECT id FROM invoices WHERE organization_id = ?)"},
+	{"invoice_items", "invoices", "invoice_id IN (SELECT id FROM invoices WHERE organization_id = ?)"},
+	{"invoices", "", "organization_id = ?"},
+	{"invoice_number_sequences", "", "organization_id = ?"},
+	{"contact_addresses", "contacts", "contact_id IN (SELECT id FROM contacts WHERE organization_id = ?)"},
+	{"contact_phones", "contacts", "contact_id IN (SELECT id FROM contacts WHERE organization_id = ?)"},
+	{"contacts", "", "organization_id = ?"},
+	{"stock_reservations", "products", "product_id IN (SELECT id FROM products WHERE organization_id = ?)"},
+	{"inventory_movements", "products", "product_id IN (SELECT id FROM products WHERE organization_id = ?)"},
+	{"inventory_items", "products", "product_id IN (SELECT id FROM products WHERE organization_id = ?)"},
+	{"product_price_history", "products", "product_id IN (SELECT id FROM products WHERE organization_id = ?)"},
+	{"product_prices", "products", "product_id IN (SELECT id FROM products WHERE organization_id = ?)"},
+	{"product_variants", "products", "product_id IN (SELECT id FROM products WHERE organization_id = ?)"},
+	{"products", "", "organization_id = ?"},
+	{"webhook_deliveries", "", "organization_id = ?"},
+	{"webhook_endpoints", "", "organization_id = ?"},
+	{"outbox", "", "organization_id = ?"},
+	{"invitations", "", "organization_id = ?"},
+	{"organization_users", "", "organization_id = ?"},
+}
+
+// organizationContentTables are the tables whose rows make an organization
+// non-empty
+var organizationContentTables = []string{"contacts", "products", "invoices"}
+
+// DeleteWithCascade deletes an inactive organization and its dependent rows
+// in one transaction. Tables a deployment does not have are skipped.
+func (r *OrganizationRepository) DeleteWithCascade(ctx context.Context, id uint, force bool) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var model organizationModel
+		if err := tx.First(&model, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return domain.ErrOrganizationNotFound
+			}
+			return err
+		}
+		if model.IsActive {
+			return domain.ErrOrganizationActive
+		}
+
+		if !force {
+			for _, table := range organizationContentTables {
+				if !tx.Migrator().HasTable(table) {
+					continue
+				}
+				var count int64
+				if err := tx.Table(table).Where("organization_id = ?", id).Count(&count).Error; err != nil {
+					return fmt.Errorf("failed to count %s: %w", table, err)
+				}
+				if count > 0 {
+					return domain.ErrOrganizationNotEmpty
+				}
+			}
+		}
+
+		for _, dependent := range organizationCascade {
+			if !tx.Migrator().HasTable(dependent.table) || (dependent.parent != "" && !tx.Migrator().HasTable(dependent.parent)) {
+				continue
+			}
+			if err := tx.Exec("DELETE FROM "+dependent.table+" WHERE "+dependent.where, id).Error; err != nil {
+				return fmt.Errorf("failed to delete %s: %w", dependent.table, err)
+			}
+		}
+
+		return tx.Delete(&organizationModel{}, id).Error
+	})
+}
+
 // List lists organizations with pagination
 func (r *OrganizationRepository) List(ctx context.Context, limit, offset int) ([]*domain.Organization, error) {
 	var models []organizationModel
@@ -292,6 +370,8 @@ func (r *OrganizationRepository) domainToModel(org *domain.Organization) *organi
 		IsActive:    org.IsActive,
 		CreatedAt:   org.CreatedAt,
 		UpdatedAt:   org.UpdatedAt,
+
+		InvoiceNumberFormat: string(org.InvoiceNumberFormat),
 	}
 }
 
@@ -315,6 +395,8 @@ func (r *OrganizationRepository) modelToDomain(model *organizationModel) *domain
 		IsActive:    model.IsActive,
 		CreatedAt:   model.CreatedAt,
 		UpdatedAt:   model.UpdatedAt,
+
+		InvoiceNumberFormat: domain.InvoiceNumberFormat(model.InvoiceNumberFormat),
 	}
 }
 
@@ -471,6 +553,41 @@ func (r *OrganizationUserRepository) CountByOrganization(ctx context.Context, or
 	return count, nil
 }
 
+// ListMembers joins the organization's users to their accounts
+func (r *OrganizationUserRepository) ListMembers(ctx context.Context, organizationID uint, filters repository.MemberFilters) ([]*domain.OrganizationMember, int64, error) {
+	if err := filters.Validate(); err != nil {
+		return nil, 0, err
+	}
+
+	query := r.db.WithContext(ctx).
+		Table("organization_users").
+		Joins("JOIN users ON users.id = organization_users.user_id").
+		Where("organization_users.organization_id = ?", organizationID)
+	if filters.Role != "" {
+		query = query.Where("organization_users.role = ?", filters.Role)
+	}
+	if filters.Search != "" {
+		query = query.Where("LOWER(users.email) LIKE ?", "%"+strings.ToLower(filters.Search)+"%")
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var members []*domain.OrganizationMember
+	if err := query.
+		Select("organization_users.user_id, users.email, organization_users.role, organization_users.joined_at").
+		Order("organization_users.joined_at ASC, organization_users.user_id ASC").
+		Limit(filters.PageSize).
+		Offset(filters.GetOffset()).
+		Scan(&members).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return members, total, nil
+}
+
 // IsUserInOrganization checks if a user is in an organization
 func (r *OrganizationUserRepository) IsUserInOrganization(ctx context.Context, organizationID, userID uint) (bool, error) {
 	var count int64
@@ -0,0 +1,169 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/pmaojo/kthulu-go/backend/internal/domain"
+	"github.com/pmaojo/kthulu-go/backend/internal/domain/repository"
+	"github.com/pmaojo/kthulu-go/backend/internal/infrastructure/testutils"
+)
+
+// setupOrganizationCascadeDB creates two organizations with members,
+// invitations, contacts, products and invoices
+func setupOrganizationCascadeDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	testDB := testutils.SetupTestDB(t)
+	t.Cleanup(func() { testutils.CleanupTestDB(t, testDB) })
+
+	require.NoError(t, testDB.Exec(`
+		CREATE TABLE organization_users (id INTEGER PRIMARY KEY, organization_id INTEGER NOT NULL, user_id INTEGER NOT NULL, role TEXT);
+		CREATE TABLE invitations (id INTEGER PRIMARY KEY, organization_id INTEGER NOT NULL, email TEXT);
+		CREATE TABLE contact_addresses (id INTEGER PRIMARY KEY, contact_id INTEGER NOT NULL);
+		CREATE TABLE products (id INTEGER PRIMARY KEY, organization_id INTEGER NOT NULL, name TEXT);
+		CREATE TABLE product_variants (id INTEGER PRIMARY KEY, product_id INTEGER NOT NULL);
+		CREATE TABLE invoices (id INTEGER PRIMARY KEY, organization_id INTEGER NOT NULL);
+		CREATE TABLE invoice_items (id INTEGER PRIMARY KEY, invoice_id INTEGER NOT NULL);
+	`).Error)
+
+	for _, orgID := range []int{1, 2} {
+		require.NoError(t, testDB.Exec(`INSERT INTO organizations (id, name, slug, is_active) VALUES (?, 'Org', ?, 0)`, orgID, orgID).Error)
+		require.NoError(t, testDB.Exec(`INSERT INTO organization_users (organization_id, user_id, role) VALUES (?, 1, 'owner')`, orgID).Error)
+		require.NoError(t, testDB.Exec(`INSERT INTO invitations (organization_id, email) VALUES (?, 'x@example.com')`, orgID).Error)
+		require.NoError(t, testDB.Exec(`INSERT INTO contacts (id, organization_id) VALUES (?, ?)`, orgID*10, orgID).Error)
+		require.NoError(t, testDB.Exec(`INSERT INTO contact_addresses (contact_id) VALUES (?)`, orgID*10).Error)
+		require.NoError(t, testDB.Exec(`INSERT INTO products (id, organization_id, name) VALUES (?, ?, 'Widget')`, orgID*10, orgID).Error)
+		require.NoError(t, testDB.Exec(`INSERT INTO product_variants (product_id) VALUES (?)`, orgID*10).Error)
+		require.NoError(t, testDB.Exec(`INSERT INTO invoices (id, organization_id) VALUES (?, ?)`, orgID*10, orgID).Error)
+		require.NoError(t, testDB.Exec(`INSERT INTO invoice_items (invoice_id) VALUES (?)`, orgID*10).Error)
+	}
+	return testDB
+}
+
+// organizationRowCounts counts the rows of each cascaded table belonging to orgID
+func organizationRowCounts(t *testing.T, testDB *gorm.DB, orgID int) map[string]int64 {
+	t.Helper()
+	queries := map[string]string{
+		"organizations":      `SELECT COUNT(*) FROM organizations WHERE id = ?`,
+		"organization_users": `SELECT COUNT(*) FROM organization_users WHERE organization_id = ?`,
+		"invitations":        `SELECT COUNT(*) FROM invitations WHERE organization_id = ?`,
+		"contacts":           `SELECT COUNT(*) FROM contacts WHERE organization_id = ?`,
+		"contact_addresses":  `SELECT COUNT(*) FROM contact_addresses WHERE contact_id = ? * 10`,
+		"products":           `SELECT COUNT(*) FROM products WHERE organization_id = ?`,
+		"product_variants":   `SELECT COUNT(*) FROM product_variants WHERE product_id = ? * 10`,
+		"invoices":           `SELECT COUNT(*) FROM invoices WHERE organization_id = ?`,
+		"invoice_items":      `SELECT COUNT(*) FROM invoice_items WHERE invoice_id = ? * 10`,
+	}
+	counts := make(map[string]int64, len(queries))
+	for table, query := range queries {
+		var count int64
+		require.NoError(t, testDB.Raw(query, orgID).Scan(&count).Error)
+		counts[table] = count
+	}
+	return counts
+}
+
+func TestOrganizationRepositoryDeleteWithCascade_RemovesDependents(t *testing.T) {
+	testDB := setupOrganizationCascadeDB(t)
+	repo := NewOrganizationRepository(testDB)
+
+	require.NoError(t, repo.DeleteWithCascade(context.Background(), 1, true))
+
+	for table, count := range organizationRowCounts(t, testDB, 1) {
+		assert.Zerof(t, count, "expected no %s left for the deleted organization", table)
+	}
+	for table, count := range organizationRowCounts(t, testDB, 2) {
+		assert.EqualValuesf(t, 1, count, "expected %s of the other organization to be kept", table)
+	}
+}
+
+func TestOrganizationRepositoryDeleteWithCascade_Guards(t *testing.T) {
+	ctx := context.Background()
+	testDB := setupOrganizationCascadeDB(t)
+	repo := NewOrganizationRepository(testDB)
+
+	err := repo.DeleteWithCascade(ctx, 1, false)
+	assert.ErrorIs(t, err, domain.ErrOrganizationNotEmpty)
+	for table, count := range organizationRowCounts(t, testDB, 1) {
+		assert.EqualValuesf(t, 1, count, "expected %s to survive a refused delete", table)
+	}
+
+	require.NoError(t, testDB.Exec(`UPDATE organizations SET is_active = 1 WHERE id = 2`).Error)
+	assert.ErrorIs(t, repo.DeleteWithCascade(ctx, 2, true), domain.ErrOrganizationActive)
+	assert.ErrorIs(t, repo.DeleteWithCascade(ctx, 99, true), domain.ErrOrganizationNotFound)
+
+	// An empty inactive organization only loses its members and invitations
+	require.NoError(t, testDB.Exec(`INSERT INTO organizations (id, name, slug, is_active) VALUES (3, 'Empty', 'empty', 0)`).Error)
+	require.NoError(t, testDB.Exec(`INSERT INTO organization_users (organization_id, user_id, role) VALUES (3, 1, 'owner')`).Error)
+	require.NoError(t, repo.DeleteWithCascade(ctx, 3, false))
+	assert.Zero(t, organizationRowCounts(t, testDB, 3)["organization_users"])
+	assert.Zero(t, organizationRowCounts(t, testDB, 3)["organizations"])
+}
+
+func TestOrganizationUserRepositoryListMembers(t *testing.T) {
+	ctx := context.Background()
+	testDB := testutils.SetupTestDB(t)
+	t.Cleanup(func() { testutils.CleanupTestDB(t, testDB) })
+	require.NoError(t, testDB.Exec(`CREATE TABLE organization_users (
+		id INTEGER PRIMARY KEY, organization_id INTEGER NOT NULL, user_id INTEGER NOT NULL,
+		role TEXT NOT NULL, joined_at DATETIME, created_at DATETIME, updated_at DATETIME)`).Error)
+
+	members := []struct {
+		email string
+		role  domain.OrganizationRole
+		orgID uint
+	}{
+		{"owner@example.com", domain.OrganizationRoleOwner, 1},
+		{"admin@example.com", domain.OrganizationRoleAdmin, 1},
+		{"ann@example.com", domain.OrganizationRoleMember, 1},
+		{"bob@example.com", domain.OrganizationRoleMember, 1},
+		{"guest@other.org", domain.OrganizationRoleGuest, 1},
+		{"outsider@example.com", domain.OrganizationRoleOwner, 2},
+	}
+	joined := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	for i, m := range members {
+		userID := uint(i + 1)
+		require.NoError(t, testDB.Exec(`INSERT INTO users (id, email, password_hash) VALUES (?, ?, 'x')`, userID, m.email).Error)
+		require.NoError(t, testDB.Create(&organizationUserModel{
+			OrganizationID: m.orgID,
+			UserID:         userID,
+			Role:           string(m.role),
+			JoinedAt:       joined.AddDate(0, 0, i),
+		}).Error)
+	}
+	repo := NewOrganizationUserRepository(testDB)
+
+	page, total, err := repo.ListMembers(ctx, 1, repository.MemberFilters{Page: 1, PageSize: 2})
+	require.NoError(t, err)
+	assert.EqualValues(t, 5, total)
+	require.Len(t, page, 2)
+	assert.Equal(t, "owner@example.com", page[0].Email)
+	assert.Equal(t, domain.OrganizationRoleOwner, page[0].Role)
+	assert.Equal(t, uint(1), page[0].UserID)
+	assert.True(t, page[0].JoinedAt.Equal(joined), "unexpected joined at %s", page[0].JoinedAt)
+	assert.Equal(t, "admin@example.com", page[1].Email)
+
+	last, total, err := repo.ListMembers(ctx, 1, repository.MemberFilters{Page: 3, PageSize: 2})
+	require.NoError(t, err)
+	assert.EqualValues(t, 5, total)
+	require.Len(t, last, 1)
+	assert.Equal(t, "guest@other.org", last[0].Email)
+	assert.Equal(t, domain.OrganizationRoleGuest, last[0].Role)
+
+	regular, total, err := repo.ListMembers(ctx, 1, repository.MemberFilters{Role: domain.OrganizationRoleMember})
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, total)
+	require.Len(t, regular, 2)
+	assert.Equal(t, []string{"ann@example.com", "bob@example.com"}, []string{regular[0].Email, regular[1].Email})
+
+	found, total, err := repo.ListMembers(ctx, 1, repository.MemberFilters{Search: "OTHER.ORG"})
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, total)
+	require.Len(t, found, 1)
+	assert.Equal(t, "guest@other.org", found[0].Email)
+}
@@ -0,0 +1,167 @@
+// @kthulu:module:webhooks
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/pmaojo/kthulu-go/backend/core"
+	"github.com/pmaojo/kthulu-go/backend/internal/domain"
+	"github.com/pmaojo/kthulu-go/backend/internal/domain/repository"
+)
+
+// OutboxRepository implements the outbox repository interface using SQL.
+// Inside a unit of work tx is set and appended events commit with it.
+type OutboxRepository struct {
+	db     *sql.DB
+	tx     *sql.Tx
+	logger core.Logger
+}
+
+// NewOutboxRepository creates a new outbox repository instance
+func NewOutboxRepository(db *sql.DB, logger core.Logger) repository.OutboxRepository {
+	return &OutboxRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// conn returns the unit of work transaction when the repository is bound to
+// one, and the pool otherwise
+func (r *OutboxRepository) conn() sqlConn {
+	if r.tx != nil {
+		return r.tx
+	}
+	return r.db
+}
+
+// Append stores an event to be relayed once the surrounding transaction
+// commits
+func (r *OutboxRepository) Append(ctx context.Context, event *domain.OutboxEvent) error {
+	ctx, cancel := core.WithStatementTimeout(ctx)
+	defer cancel()
+
+	if err := insertOutboxEvent(ctx, r.conn(), event); err != nil {
+		r.logger.Error("Failed to append outbox event", "error", err, "event", event.Event)
+		return err
+	}
+	return nil
+}
+
+// insertOutboxEvent stores an event as part of the caller's transaction
+func insertOutboxEvent(ctx context.Context, tx sqlConn, event *domain.OutboxEvent) error {
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now()
+	}
+
+	query := `
+		INSERT INTO outbox (
+			organization_id, event, payload, attempts, created_at
+		) VALUES (
+			$1, $2, $3, $4, $5
+		) RETURNING id`
+
+	err := tx.QueryRowContext(ctx, query,
+		event.OrganizationID, event.Event, string(event.Payload), event.Attempts, event.CreatedAt,
+	).Scan(&event.ID)
+	if err != nil {
+		return fmt.Errorf("failed to insert outbox event: %w", err)
+	}
+	return nil
+}
+
+// ClaimPending leases the oldest events that have not been delivered or
+// dead-lettered and whose next attempt is due. The lease is checked again in
+// the outer WHERE, so of two relays racing for a row only one claims it.
+func (r *OutboxRepository) ClaimPending(ctx context.Context, limit int, lease time.Duration) ([]*domain.OutboxEvent, error) {
+	ctx, cancel := core.WithStatementTimeout(ctx)
+	defer cancel()
+
+	now := time.Now().UTC()
+	query := `
+		UPDATE outbox SET locked_until = $1
+		WHERE id IN (
+			SELECT id FROM outbox
+			WHERE delivered_at IS NULL AND dead_lettered_at IS NULL
+			  AND (next_attempt_at IS NULL OR next_attempt_at <= $2)
+			  AND (locked_until IS NULL OR locked_until <= $2)
+			ORDER BY id
+			LIMIT $3
+		) AND (locked_until IS NULL OR locked_until <= $2)
+		RETURNING id, organization_id, event, payload, attempts, last_error, created_at`
+
+	rows, err := r.conn().QueryContext(ctx, query, now.Add(lease), now, limit)
+	if err != nil {
+		r.logger.Error("Failed to claim pending outbox events", "error", err)
+		return nil, fmt.Errorf("failed to claim pending outbox events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []*domain.OutboxEvent
+	for rows.Next() {
+		event := &domain.OutboxEvent{}
+		var payload string
+		var lastError sql.NullString
+		if err := rows.Scan(
+			&event.ID, &event.OrganizationID, &event.Event, &payload,
+			&event.Attempts, &lastError, &event.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan outbox event: %w", err)
+		}
+		event.Payload = []byte(payload)
+		event.LastError = lastError.String
+		events = append(events, event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate outbox events: %w", err)
+	}
+	sort.Slice(events, func(i, j int) bool { return events[i].ID < events[j].ID })
+	return events, nil
+}
+
+// MarkDelivered records that an event was published
+func (r *OutboxRepository) MarkDelivered(ctx context.Context, id uint) error {
+	ctx, cancel := core.WithStatementTimeout(ctx)
+	defer cancel()
+
+	query := `UPDATE outbox SET delivered_at = $1, attempts = attempts + 1, last_error = NULL, locked_until = NULL WHERE id = $2`
+
+	if _, err := r.conn().ExecContext(ctx, query, time.Now().UTC(), id); err != nil {
+		r.logger.Error("Failed to mark outbox event delivered", "error", err, "eventId", id)
+		return fmt.Errorf("failed to mark outbox event delivered: %w", err)
+	}
+	return nil
+}
+
+// MarkFailed records a failed publishing attempt and releases the event
+// until retryAt
+func (r *OutboxRepository) MarkFailed(ctx context.Context, id uint, reason string, retryAt time.Time) error {
+	ctx, cancel := core.WithStatementTimeout(ctx)
+	defer cancel()
+
+	query := `UPDATE outbox SET attempts = attempts + 1, last_error = $1, next_attempt_at = $2, locked_until = NULL WHERE id = $3`
+
+	if _, err := r.conn().ExecContext(ctx, query, reason, retryAt.UTC(), id); err != nil {
+		r.logger.Error("Failed to mark outbox event failed", "error", err, "eventId", id)
+		return fmt.Errorf("failed to mark outbox event failed: %w", err)
+	}
+	return nil
+}
+
+// MarkDeadLettered records the last failed attempt of an event that ran out
+// of attempts, so it is no longer relayed
+func (r *OutboxRepository) MarkDeadLettered(ctx context.Context, id uint, reason string) error {
+	ctx, cancel := core.WithStatementTimeout(ctx)
+	defer cancel()
+
+	query := `UPDATE outbox SET dead_lettered_at = $1, attempts = attempts + 1, last_error = $2, locked_until = NULL WHERE id = $3`
+
+	if _, err := r.conn().ExecContext(ctx, query, time.Now().UTC(), reason, id); err != nil {
+		r.logger.Error("Failed to dead-letter outbox event", "error", err, "eventId", id)
+		return fmt.Errorf("failed to dead-letter outbox event: %w", err)
+	}
+	return nil
+}
@@ -2,10 +2,12 @@
 package db
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"strings"
 
+	"github.com/pmaojo/kthulu-go/backend/core"
 	"github.com/pmaojo/kthulu-go/backend/internal/domain/repository"
 )
 
@@ -49,8 +51,10 @@ func (h *PaginationHelper) BuildCountQuery(baseQuery string) string {
 	return fmt.Sprintf("SELECT COUNT(*) FROM (%s) AS count_query", baseQuery)
 }
 
-// ExecutePaginatedQuery executes a paginated query and returns results with metadata
+// ExecutePaginatedQuery executes a paginated query and returns results with
+// metadata. Both queries are retried after transient connection errors.
 func (h *PaginationHelper) ExecutePaginatedQuery(
+	ctx context.Context,
 	baseQuery string,
 	countQuery string,
 	params repository.PaginationParams,
@@ -60,7 +64,9 @@ func (h *PaginationHelper) ExecutePaginatedQuery(
 ) (interface{}, int64, error) {
 	// Get total count
 	var total int64
-	if err := h.db.QueryRow(countQuery, args...).Scan(&total); err != nil {
+	ctx, cancel := core.WithStatementTimeout(ctx)
+	defer cancel()
+	if err := queryRowWithRetry(ctx, h.db, countQuery, args...).Scan(&total); err != nil {
 		return nil, 0, fmt.Errorf("failed to get total count: %w", err)
 	}
 
@@ -68,7 +74,7 @@ func (h *PaginationHelper) ExecutePaginatedQuery(
 	paginatedQuery := h.BuildPaginatedQuery(baseQuery, params, allowedSortFields)
 
 	// Execute paginated query
-	rows, err := h.db.Query(paginatedQuery, args...)
+	rows, err := queryWithRetry(ctx, h.db, paginatedQuery, args...)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to execute paginated query: %w", err)
 	}
@@ -168,3 +174,15 @@ func (h *PaginationHelper) CombineArgs(argSlices ...[]interface{}) []interface{}
 	}
 	return combined
 }
+
+// idPlaceholders builds the "$1,$2,..." list and arguments for an
+// `IN (...)` clause over ids
+func idPlaceholders(ids []uint) (string, []interface{}) {
+	placeholders := make([]string, len(ids))
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		placeholders[i] = fmt.Sprintf("$%d", i+1)
+		args[i] = id
+	}
+	return strings.Join(placeholders, ","), args
+}
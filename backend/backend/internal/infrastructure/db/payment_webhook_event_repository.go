@@ -0,0 +1,37 @@
+// @kthulu:module:payments
+package db
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/pmaojo/kthulu-go/backend/internal/domain"
+	"github.com/pmaojo/kthulu-go/backend/internal/domain/repository"
+)
+
+// PaymentWebhookEventRepository provides a database-backed implementation of
+// repository.PaymentWebhookEventRepository.
+type PaymentWebhookEventRepository struct {
+	db *gorm.DB
+}
+
+// NewPaymentWebhookEventRepository creates a new instance bound to a Gorm database.
+func NewPaymentWebhookEventRepository(db *gorm.DB) repository.PaymentWebhookEventRepository {
+	return &PaymentWebhookEventRepository{db: db}
+}
+
+// Claim records the gateway event as processed, reporting false when an
+// earlier delivery already recorded it. The insert is the check, so two
+// concurrent deliveries can't both claim the event.
+func (r *PaymentWebhookEventRepository) Claim(ctx context.Context, gateway domain.PaymentMethod, eventID string) (bool, error) {
+	result := r.db.WithContext(ctx).Exec(
+		"INSERT INTO payment_webhook_events (gateway, event_id, processed_at) VALUES (?, ?, ?) ON CONFLICT DO NOTHING",
+		string(gateway), eventID, time.Now(),
+	)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
@@ -0,0 +1,38 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pmaojo/kthulu-go/backend/internal/domain"
+	"github.com/pmaojo/kthulu-go/backend/internal/infrastructure/testutils"
+)
+
+func TestPaymentWebhookEventRepository(t *testing.T) {
+	testDB := testutils.SetupTestDB(t)
+	defer testutils.CleanupTestDB(t, testDB)
+	require.NoError(t, testDB.Exec(`CREATE TABLE payment_webhook_events (
+		gateway TEXT NOT NULL,
+		event_id TEXT NOT NULL,
+		processed_at DATETIME NOT NULL,
+		PRIMARY KEY (gateway, event_id)
+	)`).Error)
+
+	repo := NewPaymentWebhookEventRepository(testDB)
+	ctx := context.Background()
+
+	claimed, err := repo.Claim(ctx, domain.PaymentMethodStripe, "evt_1")
+	require.NoError(t, err)
+	assert.True(t, claimed)
+
+	claimed, err = repo.Claim(ctx, domain.PaymentMethodStripe, "evt_1")
+	require.NoError(t, err, "claiming twice should not fail")
+	assert.False(t, claimed, "an event is claimed once")
+
+	claimed, err = repo.Claim(ctx, domain.PaymentMethodStripe, "evt_2")
+	require.NoError(t, err)
+	assert.True(t, claimed)
+}
@@ -5,6 +5,7 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -16,34 +17,79 @@ import (
 
 // ProductRepository implements the product repository interface using GORM
 type ProductRepository struct {
-	db     *sql.DB
-	logger core.Logger
+	db      *sql.DB
+	tx      *sql.Tx // set on repositories bound to a unit of work
+	replica *sql.DB
+	logger  core.Logger
+	audit   repository.AuditLogger
 }
 
-// NewProductRepository creates a new product repository instance
-func NewProductRepository(db *sql.DB, logger core.Logger) repository.ProductRepository {
-	return &ProductRepository{
+// NewProductRepository creates a new product repository instance.
+// The audit logger is optional; product mutations are not audited when it is nil.
+// List and stats queries go to the read replica when one is configured.
+func NewProductRepository(db *sql.DB, logger core.Logger, audit repository.AuditLogger, replica *core.ReadReplica) repository.ProductRepository {
+	r := &ProductRepository{
 		db:     db,
 		logger: logger,
+		audit:  audit,
 	}
+	if replica != nil {
+		r.replica = replica.DB
+	}
+	return r
+}
+
+// conn returns the unit of work transaction when the repository is bound to
+// one, and the pool otherwise
+func (r *ProductRepository) conn() sqlConn {
+	if r.tx != nil {
+		return r.tx
+	}
+	return r.db
+}
+
+// reader returns the handle for read-only product queries that tolerate
+// replication lag. Inside a unit of work reads see its own writes; outside
+// one they are retried after transient errors.
+func (r *ProductRepository) reader() sqlConn {
+	if r.tx != nil {
+		return r.tx
+	}
+	if r.replica != nil {
+		return retryingReader{r.replica}
+	}
+	return retryingReader{r.db}
+}
+
+// readConn returns the handle for read-only product queries that must see the
+// latest writes, retried like the reader ones.
+func (r *ProductRepository) readConn() sqlConn {
+	if r.tx != nil {
+		return r.tx
+	}
+	return retryingReader{r.db}
 }
 
 // Create creates a new product
 func (r *ProductRepository) Create(ctx context.Context, product *domain.Product) error {
+	defer observeQuery(ctx, "ProductRepository", "Create", time.Now())
+	ctx, cancel := core.WithStatementTimeout(ctx)
+	defer cancel()
+
 	query := `
 		INSERT INTO products (
 			organization_id, sku, name, description, category, brand, 
 			unit_of_measure, weight, dimensions, barcode, tax_rate, 
-			is_active, is_trackable, created_at, updated_at
+			is_active, is_trackable, created_at, updated_at, category_id
 		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16
 		) RETURNING id, created_at, updated_at`
 
-	err := r.db.QueryRowContext(ctx, query,
+	err := r.conn().QueryRowContext(ctx, query,
 		product.OrganizationID, product.SKU, product.Name, product.Description,
 		product.Category, product.Brand, product.UnitOfMeasure, product.Weight,
 		product.Dimensions, product.Barcode, product.TaxRate, product.IsActive,
-		product.IsTrackable, product.CreatedAt, product.UpdatedAt,
+		product.IsTrackable, product.CreatedAt, product.UpdatedAt, product.CategoryID,
 	).Scan(&product.ID, &product.CreatedAt, &product.UpdatedAt)
 
 	if err != nil {
@@ -54,26 +100,32 @@ func (r *ProductRepository) Create(ctx context.Context, product *domain.Product)
 		return fmt.Errorf("failed to create product: %w", err)
 	}
 
+	recordAudit(ctx, r.audit, r.logger, domain.AuditActionCreate, "product", product.OrganizationID, product.ID, nil, product)
 	r.logger.Info("Product created successfully", "productId", product.ID, "sku", product.SKU)
 	return nil
 }
 
 // GetByID retrieves a product by ID within an organization
 func (r *ProductRepository) GetByID(ctx context.Context, organizationID, productID uint) (*domain.Product, error) {
+	defer observeQuery(ctx, "ProductRepository", "GetByID", time.Now())
+	ctx, cancel := core.WithStatementTimeout(ctx)
+	defer cancel()
+
 	query := `
 		SELECT id, organization_id, sku, name, description, category, brand,
 			   unit_of_measure, weight, dimensions, barcode, tax_rate,
-			   is_active, is_trackable, created_at, updated_at
+			   is_active, is_trackable, created_at, updated_at, category_id
 		FROM products 
 		WHERE id = $1 AND organization_id = $2`
 
 	product := &domain.Product{}
-	err := r.db.QueryRowContext(ctx, query, productID, organizationID).Scan(
+	err := r.readConn().QueryRowContext(ctx, query, productID, organizationID).Scan(
 		&product.ID, &product.OrganizationID, &product.SKU, &product.Name,
 		&product.Description, &product.Category, &product.Brand,
 		&product.UnitOfMeasure, &product.Weight, &product.Dimensions,
 		&product.Barcode, &product.TaxRate, &product.IsActive,
 		&product.IsTrackable, &product.CreatedAt, &product.UpdatedAt,
+		&product.CategoryID,
 	)
 
 	if err != nil {
@@ -89,20 +141,25 @@ func (r *ProductRepository) GetByID(ctx context.Context, organizationID, product
 
 // GetBySKU retrieves a product by SKU within an organization
 func (r *ProductRepository) GetBySKU(ctx context.Context, organizationID uint, sku string) (*domain.Product, error) {
+	defer observeQuery(ctx, "ProductRepository", "GetBySKU", time.Now())
+	ctx, cancel := core.WithStatementTimeout(ctx)
+	defer cancel()
+
 	query := `
 		SELECT id, organization_id, sku, name, description, category, brand,
 			   unit_of_measure, weight, dimensions, barcode, tax_rate,
-			   is_active, is_trackable, created_at, updated_at
+			   is_active, is_trackable, created_at, updated_at, category_id
 		FROM products 
 		WHERE sku = $1 AND organization_id = $2`
 
 	product := &domain.Product{}
-	err := r.db.QueryRowContext(ctx, query, sku, organizationID).Scan(
+	err := r.readConn().QueryRowContext(ctx, query, sku, organizationID).Scan(
 		&product.ID, &product.OrganizationID, &product.SKU, &product.Name,
 		&product.Description, &product.Category, &product.Brand,
 		&product.UnitOfMeasure, &product.Weight, &product.Dimensions,
 		&product.Barcode, &product.TaxRate, &product.IsActive,
 		&product.IsTrackable, &product.CreatedAt, &product.UpdatedAt,
+		&product.CategoryID,
 	)
 
 	if err != nil {
@@ -116,20 +173,70 @@ func (r *ProductRepository) GetBySKU(ctx context.Context, organizationID uint, s
 	return product, nil
 }
 
+// GetByBarcode retrieves a product by its barcode within an organization
+func (r *ProductRepository) GetByBarcode(ctx context.Context, organizationID uint, barcode string) (*domain.Product, error) {
+	defer observeQuery(ctx, "ProductRepository", "GetByBarcode", time.Now())
+	ctx, cancel := core.WithStatementTimeout(ctx)
+	defer cancel()
+
+	// Products without a barcode are stored with an empty one
+	barcode = strings.TrimSpace(barcode)
+	if barcode == "" {
+		return nil, domain.ErrProductNotFound
+	}
+
+	query := `
+		SELECT id, organization_id, sku, name, description, category, brand,
+			   unit_of_measure, weight, dimensions, barcode, tax_rate,
+			   is_active, is_trackable, created_at, updated_at, category_id
+		FROM products
+		WHERE organization_id = $1 AND barcode = $2`
+
+	product := &domain.Product{}
+	err := r.readConn().QueryRowContext(ctx, query, organizationID, barcode).Scan(
+		&product.ID, &product.OrganizationID, &product.SKU, &product.Name,
+		&product.Description, &product.Category, &product.Brand,
+		&product.UnitOfMeasure, &product.Weight, &product.Dimensions,
+		&product.Barcode, &product.TaxRate, &product.IsActive,
+		&product.IsTrackable, &product.CreatedAt, &product.UpdatedAt,
+		&product.CategoryID,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrProductNotFound
+		}
+		r.logger.Error("Failed to get product by barcode", "error", err, "barcode", barcode)
+		return nil, fmt.Errorf("failed to get product: %w", err)
+	}
+
+	return product, nil
+}
+
 // Update updates an existing product
 func (r *ProductRepository) Update(ctx context.Context, product *domain.Product) error {
+	defer observeQuery(ctx, "ProductRepository", "Update", time.Now())
+	ctx, cancel := core.WithStatementTimeout(ctx)
+	defer cancel()
+
+	var before *domain.Product
+	if r.audit != nil {
+		before, _ = r.GetByID(ctx, product.OrganizationID, product.ID)
+	}
+
 	query := `
 		UPDATE products SET 
 			name = $2, description = $3, category = $4, brand = $5,
 			unit_of_measure = $6, weight = $7, dimensions = $8, barcode = $9,
-			tax_rate = $10, is_active = $11, is_trackable = $12, updated_at = $13
+			tax_rate = $10, is_active = $11, is_trackable = $12, updated_at = $13,
+			category_id = $15
 		WHERE id = $1 AND organization_id = $14`
 
-	result, err := r.db.ExecContext(ctx, query,
+	result, err := r.conn().ExecContext(ctx, query,
 		product.ID, product.Name, product.Description, product.Category,
 		product.Brand, product.UnitOfMeasure, product.Weight, product.Dimensions,
 		product.Barcode, product.TaxRate, product.IsActive, product.IsTrackable,
-		time.Now(), product.OrganizationID,
+		time.Now(), product.OrganizationID, product.CategoryID,
 	)
 
 	if err != nil {
@@ -146,15 +253,25 @@ func (r *ProductRepository) Update(ctx context.Context, product *domain.Product)
 		return domain.ErrProductNotFound
 	}
 
+	recordAudit(ctx, r.audit, r.logger, domain.AuditActionUpdate, "product", product.OrganizationID, product.ID, before, product)
 	r.logger.Info("Product updated successfully", "productId", product.ID)
 	return nil
 }
 
 // Delete deletes a product
 func (r *ProductRepository) Delete(ctx context.Context, organizationID, productID uint) error {
+	defer observeQuery(ctx, "ProductRepository", "Delete", time.Now())
+	ctx, cancel := core.WithStatementTimeout(ctx)
+	defer cancel()
+
+	var before *domain.Product
+	if r.audit != nil {
+		before, _ = r.GetByID(ctx, organizationID, productID)
+	}
+
 	query := `DELETE FROM products WHERE id = $1 AND organization_id = $2`
 
-	result, err := r.db.ExecContext(ctx, query, productID, organizationID)
+	result, err := r.conn().ExecContext(ctx, query, productID, organizationID)
 	if err != nil {
 		r.logger.Error("Failed to delete product", "error", err, "productId", productID)
 		return fmt.Errorf("failed to delete product: %w", err)
@@ -169,12 +286,141 @@ func (r *ProductRepository) Delete(ctx context.Context, organizationID, productI
 		return domain.ErrProductNotFound
 	}
 
+	recordAudit(ctx, r.audit, r.logger, domain.AuditActionDelete, "product", organizationID, productID, before, nil)
 	r.logger.Info("Product deleted successfully", "productId", productID)
 	return nil
 }
 
+// Clone copies a product with its variants and prices under newSKU in a
+// single transaction. Variant SKUs take newSKU in place of the original
+// product SKU, or are prefixed with it. Barcodes are not copied, scanner
+// lookups keep resolving to the original product.
+func (r *ProductRepository) Clone(ctx context.Context, organizationID, productID uint, newSKU string) (*domain.Product, error) {
+	defer observeQuery(ctx, "ProductRepository", "Clone", time.Now())
+	ctx, cancel := core.WithStatementTimeout(ctx)
+	defer cancel()
+
+	newSKU = strings.TrimSpace(newSKU)
+	if newSKU == "" {
+		return nil, domain.ErrInvalidSKU
+	}
+
+	tx := r.tx
+	if tx == nil {
+		var err error
+		if tx, err = r.db.BeginTx(ctx, nil); err != nil {
+			return nil, fmt.Errorf("failed to begin transaction: %w", err)
+		}
+		defer tx.Rollback()
+	}
+	// The clone is audited once it is committed, not step by step
+	txRepo := &ProductRepository{db: r.db, tx: tx, logger: r.logger}
+
+	source, err := txRepo.GetByID(ctx, organizationID, productID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := txRepo.GetBySKU(ctx, organizationID, newSKU); err == nil {
+		return nil, domain.ErrProductAlreadyExists
+	} else if !errors.Is(err, domain.ErrProductNotFound) {
+		return nil, err
+	}
+
+	variants, err := txRepo.GetVariantsByProductID(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	prices, err := txRepo.GetPricesByProductID(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	seenPrices := make(map[uint]bool, len(prices))
+	for _, price := range prices {
+		seenPrices[price.ID] = true
+	}
+	for _, variant := range variants {
+		variantPrices, err := txRepo.GetPricesByVariantID(ctx, variant.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, price := range variantPrices {
+			if !seenPrices[price.ID] {
+				seenPrices[price.ID] = true
+				prices = append(prices, price)
+			}
+		}
+	}
+
+	now := time.Now()
+	clone := *source
+	clone.ID = 0
+	clone.SKU = newSKU
+	clone.Barcode = ""
+	clone.CreatedAt, clone.UpdatedAt = now, now
+	clone.Variants, clone.Prices = nil, nil
+	if err := txRepo.Create(ctx, &clone); err != nil {
+		return nil, err
+	}
+
+	variantIDs := make(map[uint]uint, len(variants))
+	for _, variant := range variants {
+		copied := *variant
+		copied.ID = 0
+		copied.ProductID = clone.ID
+		copied.SKU = cloneVariantSKU(source.SKU, variant.SKU, newSKU)
+		copied.Barcode = ""
+		copied.CreatedAt, copied.UpdatedAt = now, now
+		copied.Prices = nil
+		if err := txRepo.CreateVariant(ctx, &copied); err != nil {
+			return nil, err
+		}
+		variantIDs[variant.ID] = copied.ID
+		clone.Variants = append(clone.Variants, copied)
+	}
+
+	for _, price := range prices {
+		copied := *price
+		copied.ID = 0
+		if copied.ProductID != nil {
+			id := clone.ID
+			copied.ProductID = &id
+		}
+		if copied.ProductVariantID != nil {
+			id := variantIDs[*copied.ProductVariantID]
+			copied.ProductVariantID = &id
+		}
+		copied.CreatedAt, copied.UpdatedAt = now, now
+		if err := txRepo.CreatePrice(ctx, &copied); err != nil {
+			return nil, err
+		}
+		clone.Prices = append(clone.Prices, copied)
+	}
+
+	if r.tx == nil {
+		if err := tx.Commit(); err != nil {
+			return nil, fmt.Errorf("failed to commit clone transaction: %w", err)
+		}
+	}
+
+	recordAudit(ctx, r.audit, r.logger, domain.AuditActionCreate, "product", clone.OrganizationID, clone.ID, nil, &clone)
+	r.logger.Info("Product cloned successfully", "productId", clone.ID, "sourceId", productID, "sku", clone.SKU)
+	return &clone, nil
+}
+
+// cloneVariantSKU derives the SKU of a cloned variant
+func cloneVariantSKU(productSKU, variantSKU, newSKU string) string {
+	if rest, ok := strings.CutPrefix(variantSKU, productSKU); ok {
+		return newSKU + rest
+	}
+	return newSKU + "-" + variantSKU
+}
+
 // List retrieves products with filtering and pagination
 func (r *ProductRepository) List(ctx context.Context, organizationID uint, filters repository.ProductFilters) ([]*domain.Product, int64, error) {
+	defer observeQuery(ctx, "ProductRepository", "List", time.Now())
+	ctx, cancel := core.WithStatementTimeout(ctx)
+	defer cancel()
+
 	// Validate filters
 	if err := filters.Validate(); err != nil {
 		return nil, 0, err
@@ -186,7 +432,7 @@ func (r *ProductRepository) List(ctx context.Context, organizationID uint, filte
 	// Count query
 	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM products %s", whereClause)
 	var total int64
-	err := r.db.QueryRowContext(ctx, countQuery, args...).Scan(&total)
+	err := r.reader().QueryRowContext(ctx, countQuery, args...).Scan(&total)
 	if err != nil {
 		r.logger.Error("Failed to count products", "error", err)
 		return nil, 0, fmt.Errorf("failed to count products: %w", err)
@@ -199,10 +445,10 @@ func (r *ProductRepository) List(ctx context.Context, organizationID uint, filte
 	query := fmt.Sprintf(`
 		SELECT id, organization_id, sku, name, description, category, brand,
 			   unit_of_measure, weight, dimensions, barcode, tax_rate,
-			   is_active, is_trackable, created_at, updated_at
+			   is_active, is_trackable, created_at, updated_at, category_id
 		FROM products %s %s %s`, whereClause, orderClause, limitClause)
 
-	rows, err := r.db.QueryContext(ctx, query, args...)
+	rows, err := r.reader().QueryContext(ctx, query, args...)
 	if err != nil {
 		r.logger.Error("Failed to list products", "error", err)
 		return nil, 0, fmt.Errorf("failed to list products: %w", err)
@@ -210,7 +456,10 @@ func (r *ProductRepository) List(ctx context.Context, organizationID uint, filte
 	defer rows.Close()
 
 	var products []*domain.Product
-	for rows.Next() {
+	for scanned := 0; rows.Next(); scanned++ {
+		if err := checkContext(ctx, scanned); err != nil {
+			return nil, 0, err
+		}
 		product := &domain.Product{}
 		err := rows.Scan(
 			&product.ID, &product.OrganizationID, &product.SKU, &product.Name,
@@ -218,119 +467,290 @@ func (r *ProductRepository) List(ctx context.Context, organizationID uint, filte
 			&product.UnitOfMeasure, &product.Weight, &product.Dimensions,
 			&product.Barcode, &product.TaxRate, &product.IsActive,
 			&product.IsTrackable, &product.CreatedAt, &product.UpdatedAt,
+			&product.CategoryID,
 		)
 		if err != nil {
 			r.logger.Error("Failed to scan product", "error", err)
 			return nil, 0, fmt.Errorf("failed to scan product: %w", err)
 		}
 
-		// Load related data if requested
-		if filters.IncludeVariants {
-			variants, err := r.GetVariantsByProductID(ctx, product.ID)
-			if err != nil {
-				r.logger.Error("Failed to load product variants", "error", err, "productId", product.ID)
-			} else {
-				// Convert []*domain.ProductVariant to []domain.ProductVariant
+		products = append(products, product)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("failed to iterate products: %w", err)
+	}
+	rows.Close()
+
+	// Load related data if requested, one query per relation for the whole page
+	if filters.IncludeVariants || filters.IncludePrices {
+		r.loadProductRelations(ctx, products, filters.IncludeVariants, filters.IncludePrices)
+	}
+
+	return products, total, nil
+}
+
+// loadProductRelations attaches the variants and prices of a page of
+// products. Failures are logged and leave the relation empty, as a listing
+// should not fail because of its optional includes.
+func (r *ProductRepository) loadProductRelations(ctx context.Context, products []*domain.Product, includeVariants, includePrices bool) {
+	if len(products) == 0 {
+		return
+	}
+	ids := make([]uint, len(products))
+	for i, product := range products {
+		ids[i] = product.ID
+	}
+
+	if includeVariants {
+		variantsByProduct, err := r.GetVariantsByProductIDs(ctx, ids)
+		if err != nil {
+			r.logger.Error("Failed to load product variants", "error", err, "productCount", len(ids))
+		} else {
+			for _, product := range products {
+				variants := variantsByProduct[product.ID]
 				product.Variants = make([]domain.ProductVariant, len(variants))
 				for i, variant := range variants {
 					product.Variants[i] = *variant
 				}
 			}
 		}
+	}
 
-		if filters.IncludePrices {
-			prices, err := r.GetPricesByProductID(ctx, product.ID)
-			if err != nil {
-				r.logger.Error("Failed to load product prices", "error", err, "productId", product.ID)
-			} else {
-				// Convert []*domain.ProductPrice to []domain.ProductPrice
+	if includePrices {
+		pricesByProduct, err := r.GetPricesByProductIDs(ctx, ids)
+		if err != nil {
+			r.logger.Error("Failed to load product prices", "error", err, "productCount", len(ids))
+		} else {
+			for _, product := range products {
+				prices := pricesByProduct[product.ID]
 				product.Prices = make([]domain.ProductPrice, len(prices))
 				for i, price := range prices {
 					product.Prices[i] = *price
 				}
 			}
 		}
+	}
+}
 
-		products = append(products, product)
+// ListStream scans the products matching filters one row at a time and hands
+// each to fn. Pagination and the related variants and prices are ignored so
+// no second query runs while the rows are open.
+func (r *ProductRepository) ListStream(ctx context.Context, organizationID uint, filters repository.ProductFilters, fn func(*domain.Product) error) error {
+	defer observeQuery(ctx, "ProductRepository", "ListStream", time.Now())
+	// Exports stream for as long as the client reads, so only the start of
+	// the query is bounded by the statement timeout
+	ctx, started, cancel := core.WithStatementStartTimeout(ctx)
+	defer cancel()
+
+	if err := filters.Validate(); err != nil {
+		return err
 	}
 
-	if err = rows.Err(); err != nil {
-		return nil, 0, fmt.Errorf("failed to iterate products: %w", err)
+	whereClause, args := r.buildWhereClause(organizationID, filters)
+	query := fmt.Sprintf(`
+		SELECT id, organization_id, sku, name, description, category, brand,
+			   unit_of_measure, weight, dimensions, barcode, tax_rate,
+			   is_active, is_trackable, created_at, updated_at, category_id
+		FROM products %s ORDER BY %s %s`, whereClause, filters.SortBy, strings.ToUpper(filters.SortOrder))
+
+	rows, err := r.reader().QueryContext(ctx, query, args...)
+	started()
+	if err != nil {
+		r.logger.Error("Failed to stream products", "error", err)
+		return fmt.Errorf("failed to list products: %w", err)
 	}
+	defer rows.Close()
 
-	return products, total, nil
+	for scanned := 0; rows.Next(); scanned++ {
+		if err := checkContext(ctx, scanned); err != nil {
+			return err
+		}
+		product := &domain.Product{}
+		err := rows.Scan(
+			&product.ID, &product.OrganizationID, &product.SKU, &product.Name,
+			&product.Description, &product.Category, &product.Brand,
+			&product.UnitOfMeasure, &product.Weight, &product.Dimensions,
+			&product.Barcode, &product.TaxRate, &product.IsActive,
+			&product.IsTrackable, &product.CreatedAt, &product.UpdatedAt,
+			&product.CategoryID,
+		)
+		if err != nil {
+			r.logger.Error("Failed to scan product", "error", err)
+			return fmt.Errorf("failed to scan product: %w", err)
+		}
+		if err := fn(product); err != nil {
+			return err
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to iterate products: %w", err)
+	}
+	return nil
 }
 
 // buildWhereClause builds the WHERE clause for product filtering
 func (r *ProductRepository) buildWhereClause(organizationID uint, filters repository.ProductFilters) (string, []interface{}) {
-	var conditions []string
-	var args []interface{}
-	argIndex := 1
-
 	// Organization filter (always required)
-	conditions = append(conditions, fmt.Sprintf("organization_id = $%d", argIndex))
-	args = append(args, organizationID)
-	argIndex++
+	qb := NewQueryBuilder().Where("organization_id = ?", organizationID)
 
 	// Category filter
 	if filters.Category != "" {
-		conditions = append(conditions, fmt.Sprintf("category = $%d", argIndex))
-		args = append(args, filters.Category)
-		argIndex++
+		qb.Where("category = ?", filters.Category)
+	}
+
+	// Category tree filter, the category and all of its descendants
+	if filters.CategoryID != nil {
+		qb.Where(`category_id IN (
+			WITH RECURSIVE category_tree(id) AS (
+				SELECT id FROM product_categories WHERE id = ? AND organization_id = ?
+				UNION ALL
+				SELECT c.id FROM product_categories c JOIN category_tree t ON c.parent_id = t.id
+			)
+			SELECT id FROM category_tree)`, *filters.CategoryID, organizationID)
 	}
 
 	// Brand filter
 	if filters.Brand != "" {
-		conditions = append(conditions, fmt.Sprintf("brand = $%d", argIndex))
-		args = append(args, filters.Brand)
-		argIndex++
+		qb.Where("brand = ?", filters.Brand)
 	}
 
 	// Active filter
 	if filters.IsActive != nil {
-		conditions = append(conditions, fmt.Sprintf("is_active = $%d", argIndex))
-		args = append(args, *filters.IsActive)
-		argIndex++
+		qb.Where("is_active = ?", *filters.IsActive)
 	}
 
 	// Trackable filter
 	if filters.IsTrackable != nil {
-		conditions = append(conditions, fmt.Sprintf("is_trackable = $%d", argIndex))
-		args = append(args, *filters.IsTrackable)
-		argIndex++
+		qb.Where("is_trackable = ?", *filters.IsTrackable)
 	}
 
-	// Search filter (name, SKU, description)
+	// Search filter (name, SKU, description); each LOWER(column) matches a
+	// trigram index
 	if filters.Search != "" {
 		searchPattern := "%" + strings.ToLower(filters.Search) + "%"
-		conditions = append(conditions, fmt.Sprintf("(LOWER(name) LIKE $%d OR LOWER(sku) LIKE $%d OR LOWER(description) LIKE $%d)", argIndex, argIndex, argIndex))
-		args = append(args, searchPattern)
-		argIndex++
+		qb.Where("(LOWER(name) LIKE ? OR LOWER(sku) LIKE ? OR LOWER(description) LIKE ?)", searchPattern, searchPattern, searchPattern)
 	}
 
 	// Date range filters
 	if filters.CreatedFrom != nil {
-		conditions = append(conditions, fmt.Sprintf("created_at >= $%d", argIndex))
-		args = append(args, *filters.CreatedFrom)
-		argIndex++
+		qb.Where("created_at >= ?", *filters.CreatedFrom)
 	}
 
 	if filters.CreatedTo != nil {
-		conditions = append(conditions, fmt.Sprintf("created_at <= $%d", argIndex))
-		args = append(args, *filters.CreatedTo)
-		argIndex++
+		qb.Where("created_at <= ?", *filters.CreatedTo)
 	}
 
-	whereClause := ""
-	if len(conditions) > 0 {
-		whereClause = "WHERE " + strings.Join(conditions, " AND ")
+	return qb.Build()
+}
+
+// CreateCategory creates a category, checking that its parent belongs to
+// the same organization
+func (r *ProductRepository) CreateCategory(ctx context.Context, category *domain.ProductCategory) error {
+	defer observeQuery(ctx, "ProductRepository", "CreateCategory", time.Now())
+	ctx, cancel := core.WithStatementTimeout(ctx)
+	defer cancel()
+
+	if category.ParentID != nil {
+		if _, err := r.GetCategoryByID(ctx, category.OrganizationID, *category.ParentID); err != nil {
+			return err
+		}
 	}
 
-	return whereClause, args
+	query := `
+		INSERT INTO product_categories (
+			organization_id, parent_id, name, created_at, updated_at
+		) VALUES (
+			$1, $2, $3, $4, $5
+		) RETURNING id, created_at, updated_at`
+
+	err := r.conn().QueryRowContext(ctx, query,
+		category.OrganizationID, category.ParentID, category.Name,
+		category.CreatedAt, category.UpdatedAt,
+	).Scan(&category.ID, &category.CreatedAt, &category.UpdatedAt)
+	if err != nil {
+		r.logger.Error("Failed to create product category", "error", err, "name", category.Name)
+		return fmt.Errorf("failed to create product category: %w", err)
+	}
+
+	recordAudit(ctx, r.audit, r.logger, domain.AuditActionCreate, "product_category", category.OrganizationID, category.ID, nil, category)
+	r.logger.Info("Product category created successfully", "categoryId", category.ID, "name", category.Name)
+	return nil
+}
+
+// GetCategoryByID retrieves a category by ID within an organization
+func (r *ProductRepository) GetCategoryByID(ctx context.Context, organizationID, categoryID uint) (*domain.ProductCategory, error) {
+	defer observeQuery(ctx, "ProductRepository", "GetCategoryByID", time.Now())
+	ctx, cancel := core.WithStatementTimeout(ctx)
+	defer cancel()
+
+	query := `
+		SELECT id, organization_id, parent_id, name, created_at, updated_at
+		FROM product_categories
+		WHERE id = $1 AND organization_id = $2`
+
+	category := &domain.ProductCategory{}
+	err := r.readConn().QueryRowContext(ctx, query, categoryID, organizationID).Scan(
+		&category.ID, &category.OrganizationID, &category.ParentID,
+		&category.Name, &category.CreatedAt, &category.UpdatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrProductCategoryNotFound
+		}
+		r.logger.Error("Failed to get product category", "error", err, "categoryId", categoryID)
+		return nil, fmt.Errorf("failed to get product category: %w", err)
+	}
+
+	return category, nil
+}
+
+// ListCategoryTree loads every category of the organization and links them
+// into a tree
+func (r *ProductRepository) ListCategoryTree(ctx context.Context, organizationID uint) ([]*domain.ProductCategory, error) {
+	defer observeQuery(ctx, "ProductRepository", "ListCategoryTree", time.Now())
+	ctx, cancel := core.WithStatementTimeout(ctx)
+	defer cancel()
+
+	query := `
+		SELECT id, organization_id, parent_id, name, created_at, updated_at
+		FROM product_categories
+		WHERE organization_id = $1`
+
+	rows, err := r.reader().QueryContext(ctx, query, organizationID)
+	if err != nil {
+		r.logger.Error("Failed to list product categories", "error", err, "organizationId", organizationID)
+		return nil, fmt.Errorf("failed to list product categories: %w", err)
+	}
+	defer rows.Close()
+
+	var categories []*domain.ProductCategory
+	for rows.Next() {
+		category := &domain.ProductCategory{}
+		if err := rows.Scan(
+			&category.ID, &category.OrganizationID, &category.ParentID,
+			&category.Name, &category.CreatedAt, &category.UpdatedAt,
+		); err != nil {
+			r.logger.Error("Failed to scan product category", "error", err)
+			return nil, fmt.Errorf("failed to scan product category: %w", err)
+		}
+		categories = append(categories, category)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate product categories: %w", err)
+	}
+
+	return domain.BuildProductCategoryTree(categories), nil
 }
 
 // CreateVariant creates a new product variant
 func (r *ProductRepository) CreateVariant(ctx context.Context, variant *domain.ProductVariant) error {
+	defer observeQuery(ctx, "ProductRepository", "CreateVariant", time.Now())
+	ctx, cancel := core.WithStatementTimeout(ctx)
+	defer cancel()
+
 	attributesJSON, err := json.Marshal(variant.Attributes)
 	if err != nil {
 		return fmt.Errorf("failed to marshal attributes: %w", err)
@@ -344,7 +764,7 @@ func (r *ProductRepository) CreateVariant(ctx context.Context, variant *domain.P
 			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11
 		) RETURNING id, created_at, updated_at`
 
-	err = r.db.QueryRowContext(ctx, query,
+	err = r.conn().QueryRowContext(ctx, query,
 		variant.ProductID, variant.SKU, variant.Name, variant.Description,
 		attributesJSON, variant.Weight, variant.Dimensions, variant.Barcode,
 		variant.IsActive, variant.CreatedAt, variant.UpdatedAt,
@@ -364,6 +784,10 @@ func (r *ProductRepository) CreateVariant(ctx context.Context, variant *domain.P
 
 // GetVariantByID retrieves a product variant by ID
 func (r *ProductRepository) GetVariantByID(ctx context.Context, productID, variantID uint) (*domain.ProductVariant, error) {
+	defer observeQuery(ctx, "ProductRepository", "GetVariantByID", time.Now())
+	ctx, cancel := core.WithStatementTimeout(ctx)
+	defer cancel()
+
 	query := `
 		SELECT id, product_id, sku, name, description, attributes, weight,
 			   dimensions, barcode, is_active, created_at, updated_at
@@ -373,7 +797,7 @@ func (r *ProductRepository) GetVariantByID(ctx context.Context, productID, varia
 	variant := &domain.ProductVariant{}
 	var attributesJSON []byte
 
-	err := r.db.QueryRowContext(ctx, query, variantID, productID).Scan(
+	err := r.readConn().QueryRowContext(ctx, query, variantID, productID).Scan(
 		&variant.ID, &variant.ProductID, &variant.SKU, &variant.Name,
 		&variant.Description, &attributesJSON, &variant.Weight,
 		&variant.Dimensions, &variant.Barcode, &variant.IsActive,
@@ -401,6 +825,10 @@ func (r *ProductRepository) GetVariantByID(ctx context.Context, productID, varia
 
 // GetVariantBySKU retrieves a product variant by SKU
 func (r *ProductRepository) GetVariantBySKU(ctx context.Context, sku string) (*domain.ProductVariant, error) {
+	defer observeQuery(ctx, "ProductRepository", "GetVariantBySKU", time.Now())
+	ctx, cancel := core.WithStatementTimeout(ctx)
+	defer cancel()
+
 	query := `
 		SELECT id, product_id, sku, name, description, attributes, weight,
 			   dimensions, barcode, is_active, created_at, updated_at
@@ -410,7 +838,7 @@ func (r *ProductRepository) GetVariantBySKU(ctx context.Context, sku string) (*d
 	variant := &domain.ProductVariant{}
 	var attributesJSON []byte
 
-	err := r.db.QueryRowContext(ctx, query, sku).Scan(
+	err := r.readConn().QueryRowContext(ctx, query, sku).Scan(
 		&variant.ID, &variant.ProductID, &variant.SKU, &variant.Name,
 		&variant.Description, &attributesJSON, &variant.Weight,
 		&variant.Dimensions, &variant.Barcode, &variant.IsActive,
@@ -436,8 +864,60 @@ func (r *ProductRepository) GetVariantBySKU(ctx context.Context, sku string) (*d
 	return variant, nil
 }
 
+// GetVariantByBarcode retrieves a product variant by its barcode among the
+// variants of the organization's products
+func (r *ProductRepository) GetVariantByBarcode(ctx context.Context, organizationID uint, barcode string) (*domain.ProductVariant, error) {
+	defer observeQuery(ctx, "ProductRepository", "GetVariantByBarcode", time.Now())
+	ctx, cancel := core.WithStatementTimeout(ctx)
+	defer cancel()
+
+	barcode = strings.TrimSpace(barcode)
+	if barcode == "" {
+		return nil, domain.ErrVariantNotFound
+	}
+
+	query := `
+		SELECT v.id, v.product_id, v.sku, v.name, v.description, v.attributes, v.weight,
+			   v.dimensions, v.barcode, v.is_active, v.created_at, v.updated_at
+		FROM product_variants v
+		JOIN products p ON p.id = v.product_id
+		WHERE p.organization_id = $1 AND v.barcode = $2`
+
+	variant := &domain.ProductVariant{}
+	var attributesJSON []byte
+
+	err := r.readConn().QueryRowContext(ctx, query, organizationID, barcode).Scan(
+		&variant.ID, &variant.ProductID, &variant.SKU, &variant.Name,
+		&variant.Description, &attributesJSON, &variant.Weight,
+		&variant.Dimensions, &variant.Barcode, &variant.IsActive,
+		&variant.CreatedAt, &variant.UpdatedAt,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrVariantNotFound
+		}
+		r.logger.Error("Failed to get product variant by barcode", "error", err, "barcode", barcode)
+		return nil, fmt.Errorf("failed to get product variant: %w", err)
+	}
+
+	// Unmarshal attributes
+	if len(attributesJSON) > 0 {
+		if err := json.Unmarshal(attributesJSON, &variant.Attributes); err != nil {
+			r.logger.Error("Failed to unmarshal variant attributes", "error", err, "barcode", barcode)
+			variant.Attributes = make(map[string]interface{})
+		}
+	}
+
+	return variant, nil
+}
+
 // GetVariantsByProductID retrieves all variants for a product
 func (r *ProductRepository) GetVariantsByProductID(ctx context.Context, productID uint) ([]*domain.ProductVariant, error) {
+	defer observeQuery(ctx, "ProductRepository", "GetVariantsByProductID", time.Now())
+	ctx, cancel := core.WithStatementTimeout(ctx)
+	defer cancel()
+
 	query := `
 		SELECT id, product_id, sku, name, description, attributes, weight,
 			   dimensions, barcode, is_active, created_at, updated_at
@@ -445,7 +925,7 @@ func (r *ProductRepository) GetVariantsByProductID(ctx context.Context, productI
 		WHERE product_id = $1
 		ORDER BY created_at ASC`
 
-	rows, err := r.db.QueryContext(ctx, query, productID)
+	rows, err := r.readConn().QueryContext(ctx, query, productID)
 	if err != nil {
 		r.logger.Error("Failed to get product variants", "error", err, "productId", productID)
 		return nil, fmt.Errorf("failed to get product variants: %w", err)
@@ -453,7 +933,10 @@ func (r *ProductRepository) GetVariantsByProductID(ctx context.Context, productI
 	defer rows.Close()
 
 	var variants []*domain.ProductVariant
-	for rows.Next() {
+	for scanned := 0; rows.Next(); scanned++ {
+		if err := checkContext(ctx, scanned); err != nil {
+			return nil, err
+		}
 		variant := &domain.ProductVariant{}
 		var attributesJSON []byte
 
@@ -486,8 +969,75 @@ func (r *ProductRepository) GetVariantsByProductID(ctx context.Context, productI
 	return variants, nil
 }
 
+// GetVariantsByProductIDs retrieves the variants of several products in a
+// single query, keyed by product ID
+func (r *ProductRepository) GetVariantsByProductIDs(ctx context.Context, productIDs []uint) (map[uint][]*domain.ProductVariant, error) {
+	defer observeQuery(ctx, "ProductRepository", "GetVariantsByProductIDs", time.Now())
+	ctx, cancel := core.WithStatementTimeout(ctx)
+	defer cancel()
+
+	variantsByProduct := make(map[uint][]*domain.ProductVariant, len(productIDs))
+	if len(productIDs) == 0 {
+		return variantsByProduct, nil
+	}
+
+	placeholders, args := idPlaceholders(productIDs)
+	query := fmt.Sprintf(`
+		SELECT id, product_id, sku, name, description, attributes, weight,
+			   dimensions, barcode, is_active, created_at, updated_at
+		FROM product_variants 
+		WHERE product_id IN (%s)
+		ORDER BY product_id ASC, created_at ASC`, placeholders)
+
+	rows, err := r.readConn().QueryContext(ctx, query, args...)
+	if err != nil {
+		r.logger.Error("Failed to get product variants", "error", err, "productCount", len(productIDs))
+		return nil, fmt.Errorf("failed to get product variants: %w", err)
+	}
+	defer rows.Close()
+
+	for scanned := 0; rows.Next(); scanned++ {
+		if err := checkContext(ctx, scanned); err != nil {
+			return nil, err
+		}
+		variant := &domain.ProductVariant{}
+		var attributesJSON []byte
+
+		err := rows.Scan(
+			&variant.ID, &variant.ProductID, &variant.SKU, &variant.Name,
+			&variant.Description, &attributesJSON, &variant.Weight,
+			&variant.Dimensions, &variant.Barcode, &variant.IsActive,
+			&variant.CreatedAt, &variant.UpdatedAt,
+		)
+		if err != nil {
+			r.logger.Error("Failed to scan product variant", "error", err)
+			return nil, fmt.Errorf("failed to scan product variant: %w", err)
+		}
+
+		// Unmarshal attributes
+		if len(attributesJSON) > 0 {
+			if err := json.Unmarshal(attributesJSON, &variant.Attributes); err != nil {
+				r.logger.Error("Failed to unmarshal variant attributes", "error", err, "variantId", variant.ID)
+				variant.Attributes = make(map[string]interface{})
+			}
+		}
+
+		variantsByProduct[variant.ProductID] = append(variantsByProduct[variant.ProductID], variant)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate product variants: %w", err)
+	}
+
+	return variantsByProduct, nil
+}
+
 // UpdateVariant updates an existing product variant
 func (r *ProductRepository) UpdateVariant(ctx context.Context, variant *domain.ProductVariant) error {
+	defer observeQuery(ctx, "ProductRepository", "UpdateVariant", time.Now())
+	ctx, cancel := core.WithStatementTimeout(ctx)
+	defer cancel()
+
 	attributesJSON, err := json.Marshal(variant.Attributes)
 	if err != nil {
 		return fmt.Errorf("failed to marshal attributes: %w", err)
@@ -499,7 +1049,7 @@ func (r *ProductRepository) UpdateVariant(ctx context.Context, variant *domain.P
 			dimensions = $6, barcode = $7, is_active = $8, updated_at = $9
 		WHERE id = $1`
 
-	result, err := r.db.ExecContext(ctx, query,
+	result, err := r.conn().ExecContext(ctx, query,
 		variant.ID, variant.Name, variant.Description, attributesJSON,
 		variant.Weight, variant.Dimensions, variant.Barcode,
 		variant.IsActive, time.Now(),
@@ -525,9 +1075,13 @@ func (r *ProductRepository) UpdateVariant(ctx context.Context, variant *domain.P
 
 // DeleteVariant deletes a product variant
 func (r *ProductRepository) DeleteVariant(ctx context.Context, productID, variantID uint) error {
+	defer observeQuery(ctx, "ProductRepository", "DeleteVariant", time.Now())
+	ctx, cancel := core.WithStatementTimeout(ctx)
+	defer cancel()
+
 	query := `DELETE FROM product_variants WHERE id = $1 AND product_id = $2`
 
-	result, err := r.db.ExecContext(ctx, query, variantID, productID)
+	result, err := r.conn().ExecContext(ctx, query, variantID, productID)
 	if err != nil {
 		r.logger.Error("Failed to delete product variant", "error", err, "variantId", variantID)
 		return fmt.Errorf("failed to delete product variant: %w", err)
@@ -548,6 +1102,10 @@ func (r *ProductRepository) DeleteVariant(ctx context.Context, productID, varian
 
 // CreatePrice creates a new product price
 func (r *ProductRepository) CreatePrice(ctx context.Context, price *domain.ProductPrice) error {
+	defer observeQuery(ctx, "ProductRepository", "CreatePrice", time.Now())
+	ctx, cancel := core.WithStatementTimeout(ctx)
+	defer cancel()
+
 	query := `
 		INSERT INTO product_prices (
 			product_id, product_variant_id, price_type, currency, amount,
@@ -557,7 +1115,7 @@ func (r *ProductRepository) CreatePrice(ctx context.Context, price *domain.Produ
 			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12
 		) RETURNING id, created_at, updated_at`
 
-	err := r.db.QueryRowContext(ctx, query,
+	err := r.conn().QueryRowContext(ctx, query,
 		price.ProductID, price.ProductVariantID, price.PriceType,
 		price.Currency, price.Amount, price.MinQuantity, price.MaxQuantity,
 		price.ValidFrom, price.ValidUntil, price.IsActive,
@@ -575,6 +1133,10 @@ func (r *ProductRepository) CreatePrice(ctx context.Context, price *domain.Produ
 
 // GetPriceByID retrieves a product price by ID
 func (r *ProductRepository) GetPriceByID(ctx context.Context, priceID uint) (*domain.ProductPrice, error) {
+	defer observeQuery(ctx, "ProductRepository", "GetPriceByID", time.Now())
+	ctx, cancel := core.WithStatementTimeout(ctx)
+	defer cancel()
+
 	query := `
 		SELECT id, product_id, product_variant_id, price_type, currency, amount,
 			   min_quantity, max_quantity, valid_from, valid_until,
@@ -583,7 +1145,7 @@ func (r *ProductRepository) GetPriceByID(ctx context.Context, priceID uint) (*do
 		WHERE id = $1`
 
 	price := &domain.ProductPrice{}
-	err := r.db.QueryRowContext(ctx, query, priceID).Scan(
+	err := r.readConn().QueryRowContext(ctx, query, priceID).Scan(
 		&price.ID, &price.ProductID, &price.ProductVariantID, &price.PriceType,
 		&price.Currency, &price.Amount, &price.MinQuantity, &price.MaxQuantity,
 		&price.ValidFrom, &price.ValidUntil, &price.IsActive,
@@ -603,6 +1165,10 @@ func (r *ProductRepository) GetPriceByID(ctx context.Context, priceID uint) (*do
 
 // GetPricesByProductID retrieves all prices for a product
 func (r *ProductRepository) GetPricesByProductID(ctx context.Context, productID uint) ([]*domain.ProductPrice, error) {
+	defer observeQuery(ctx, "ProductRepository", "GetPricesByProductID", time.Now())
+	ctx, cancel := core.WithStatementTimeout(ctx)
+	defer cancel()
+
 	query := `
 		SELECT id, product_id, product_variant_id, price_type, currency, amount,
 			   min_quantity, max_quantity, valid_from, valid_until,
@@ -614,8 +1180,45 @@ func (r *ProductRepository) GetPricesByProductID(ctx context.Context, productID
 	return r.queryPrices(ctx, query, productID)
 }
 
+// GetPricesByProductIDs retrieves the prices of several products in a single
+// query, keyed by product ID
+func (r *ProductRepository) GetPricesByProductIDs(ctx context.Context, productIDs []uint) (map[uint][]*domain.ProductPrice, error) {
+	defer observeQuery(ctx, "ProductRepository", "GetPricesByProductIDs", time.Now())
+	ctx, cancel := core.WithStatementTimeout(ctx)
+	defer cancel()
+
+	pricesByProduct := make(map[uint][]*domain.ProductPrice, len(productIDs))
+	if len(productIDs) == 0 {
+		return pricesByProduct, nil
+	}
+
+	placeholders, args := idPlaceholders(productIDs)
+	query := fmt.Sprintf(`
+		SELECT id, product_id, product_variant_id, price_type, currency, amount,
+			   min_quantity, max_quantity, valid_from, valid_until,
+			   is_active, created_at, updated_at
+		FROM product_prices 
+		WHERE product_id IN (%s)
+		ORDER BY product_id, price_type, min_quantity`, placeholders)
+
+	prices, err := r.queryPrices(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	for _, price := range prices {
+		if price.ProductID != nil {
+			pricesByProduct[*price.ProductID] = append(pricesByProduct[*price.ProductID], price)
+		}
+	}
+	return pricesByProduct, nil
+}
+
 // GetPricesByVariantID retrieves all prices for a product variant
 func (r *ProductRepository) GetPricesByVariantID(ctx context.Context, variantID uint) ([]*domain.ProductPrice, error) {
+	defer observeQuery(ctx, "ProductRepository", "GetPricesByVariantID", time.Now())
+	ctx, cancel := core.WithStatementTimeout(ctx)
+	defer cancel()
+
 	query := `
 		SELECT id, product_id, product_variant_id, price_type, currency, amount,
 			   min_quantity, max_quantity, valid_from, valid_until,
@@ -629,6 +1232,10 @@ func (r *ProductRepository) GetPricesByVariantID(ctx context.Context, variantID
 
 // GetEffectivePrice retrieves the effective price for a product or variant
 func (r *ProductRepository) GetEffectivePrice(ctx context.Context, productID *uint, variantID *uint, priceType domain.PriceType, quantity int, at time.Time) (*domain.ProductPrice, error) {
+	defer observeQuery(ctx, "ProductRepository", "GetEffectivePrice", time.Now())
+	ctx, cancel := core.WithStatementTimeout(ctx)
+	defer cancel()
+
 	var query string
 	var args []interface{}
 
@@ -663,7 +1270,7 @@ func (r *ProductRepository) GetEffectivePrice(ctx context.Context, productID *ui
 	}
 
 	price := &domain.ProductPrice{}
-	err := r.db.QueryRowContext(ctx, query, args...).Scan(
+	err := r.readConn().QueryRowContext(ctx, query, args...).Scan(
 		&price.ID, &price.ProductID, &price.ProductVariantID, &price.PriceType,
 		&price.Currency, &price.Amount, &price.MinQuantity, &price.MaxQuantity,
 		&price.ValidFrom, &price.ValidUntil, &price.IsActive,
@@ -681,17 +1288,56 @@ func (r *ProductRepository) GetEffectivePrice(ctx context.Context, productID *ui
 	return price, nil
 }
 
-// UpdatePrice updates an existing product price
+// UpdatePrice updates an existing product price, archiving the previous
+// values in product_price_history within the same transaction
 func (r *ProductRepository) UpdatePrice(ctx context.Context, price *domain.ProductPrice) error {
+	defer observeQuery(ctx, "ProductRepository", "UpdatePrice", time.Now())
+	ctx, cancel := core.WithStatementTimeout(ctx)
+	defer cancel()
+
+	tx, err := beginTx(ctx, r.db, r.tx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	historyQuery := `
+		INSERT INTO product_price_history (
+			price_id, product_id, product_variant_id, price_type, currency, amount,
+			min_quantity, max_quantity, valid_from, valid_until,
+			is_active, created_at, changed_at
+		)
+		SELECT id, product_id, product_variant_id, price_type, currency, amount,
+			   min_quantity, max_quantity, valid_from, valid_until,
+			   is_active, created_at, $1
+		FROM product_prices
+		WHERE id = $2`
+
+	now := time.Now()
+	result, err := tx.ExecContext(ctx, historyQuery, now, price.ID)
+	if err != nil {
+		r.logger.Error("Failed to archive product price", "error", err, "priceId", price.ID)
+		return fmt.Errorf("failed to archive product price: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return domain.ErrPriceNotFound
+	}
+
 	query := `
 		UPDATE product_prices SET 
-			amount = $2, min_quantity = $3, max_quantity = $4,
-			valid_from = $5, valid_until = $6, is_active = $7, updated_at = $8
-		WHERE id = $1`
+			amount = $1, min_quantity = $2, max_quantity = $3,
+			valid_from = $4, valid_until = $5, is_active = $6, updated_at = $7
+		WHERE id = $8`
 
-	result, err := r.db.ExecContext(ctx, query,
-		price.ID, price.Amount, price.MinQuantity, price.MaxQuantity,
-		price.ValidFrom, price.ValidUntil, price.IsActive, time.Now(),
+	result, err = tx.ExecContext(ctx, query,
+		price.Amount, price.MinQuantity, price.MaxQuantity,
+		price.ValidFrom, price.ValidUntil, price.IsActive, now, price.ID,
 	)
 
 	if err != nil {
@@ -699,7 +1345,7 @@ func (r *ProductRepository) UpdatePrice(ctx context.Context, price *domain.Produ
 		return fmt.Errorf("failed to update product price: %w", err)
 	}
 
-	rowsAffected, err := result.RowsAffected()
+	rowsAffected, err = result.RowsAffected()
 	if err != nil {
 		return fmt.Errorf("failed to get rows affected: %w", err)
 	}
@@ -708,15 +1354,42 @@ func (r *ProductRepository) UpdatePrice(ctx context.Context, price *domain.Produ
 		return domain.ErrPriceNotFound
 	}
 
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit price update transaction: %w", err)
+	}
+
 	r.logger.Info("Product price updated successfully", "priceId", price.ID)
 	return nil
 }
 
+// GetPriceHistory retrieves the archived prices of a product ordered by change time.
+// Each entry carries the ID of the price it was archived from, and UpdatedAt holds
+// the moment the value was replaced.
+func (r *ProductRepository) GetPriceHistory(ctx context.Context, productID uint) ([]*domain.ProductPrice, error) {
+	defer observeQuery(ctx, "ProductRepository", "GetPriceHistory", time.Now())
+	ctx, cancel := core.WithStatementTimeout(ctx)
+	defer cancel()
+
+	query := `
+		SELECT price_id, product_id, product_variant_id, price_type, currency, amount,
+			   min_quantity, max_quantity, valid_from, valid_until,
+			   is_active, created_at, changed_at
+		FROM product_price_history 
+		WHERE product_id = $1
+		ORDER BY changed_at ASC, id ASC`
+
+	return r.queryPrices(ctx, query, productID)
+}
+
 // DeletePrice deletes a product price
 func (r *ProductRepository) DeletePrice(ctx context.Context, priceID uint) error {
+	defer observeQuery(ctx, "ProductRepository", "DeletePrice", time.Now())
+	ctx, cancel := core.WithStatementTimeout(ctx)
+	defer cancel()
+
 	query := `DELETE FROM product_prices WHERE id = $1`
 
-	result, err := r.db.ExecContext(ctx, query, priceID)
+	result, err := r.conn().ExecContext(ctx, query, priceID)
 	if err != nil {
 		r.logger.Error("Failed to delete product price", "error", err, "priceId", priceID)
 		return fmt.Errorf("failed to delete product price: %w", err)
@@ -736,8 +1409,8 @@ func (r *ProductRepository) DeletePrice(ctx context.Context, priceID uint) error
 }
 
 // queryPrices is a helper method to query prices
-func (r *ProductRepository) queryPrices(ctx context.Context, query string, id uint) ([]*domain.ProductPrice, error) {
-	rows, err := r.db.QueryContext(ctx, query, id)
+func (r *ProductRepository) queryPrices(ctx context.Context, query string, args ...interface{}) ([]*domain.ProductPrice, error) {
+	rows, err := r.readConn().QueryContext(ctx, query, args...)
 	if err != nil {
 		r.logger.Error("Failed to query product prices", "error", err)
 		return nil, fmt.Errorf("failed to query product prices: %w", err)
@@ -770,11 +1443,15 @@ func (r *ProductRepository) queryPrices(ctx context.Context, query string, id ui
 
 // BulkCreate creates multiple products in a single transaction
 func (r *ProductRepository) BulkCreate(ctx context.Context, products []*domain.Product) error {
+	defer observeQuery(ctx, "ProductRepository", "BulkCreate", time.Now())
+	ctx, cancel := core.WithStatementTimeout(ctx)
+	defer cancel()
+
 	if len(products) == 0 {
 		return nil
 	}
 
-	tx, err := r.db.BeginTx(ctx, nil)
+	tx, err := beginTx(ctx, r.db, r.tx)
 	if err != nil {
 		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
@@ -784,9 +1461,9 @@ func (r *ProductRepository) BulkCreate(ctx context.Context, products []*domain.P
 		INSERT INTO products (
 			organization_id, sku, name, description, category, brand, 
 			unit_of_measure, weight, dimensions, barcode, tax_rate, 
-			is_active, is_trackable, created_at, updated_at
+			is_active, is_trackable, created_at, updated_at, category_id
 		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16
 		) RETURNING id, created_at, updated_at`
 
 	for _, product := range products {
@@ -794,7 +1471,7 @@ func (r *ProductRepository) BulkCreate(ctx context.Context, products []*domain.P
 			product.OrganizationID, product.SKU, product.Name, product.Description,
 			product.Category, product.Brand, product.UnitOfMeasure, product.Weight,
 			product.Dimensions, product.Barcode, product.TaxRate, product.IsActive,
-			product.IsTrackable, product.CreatedAt, product.UpdatedAt,
+			product.IsTrackable, product.CreatedAt, product.UpdatedAt, product.CategoryID,
 		).Scan(&product.ID, &product.CreatedAt, &product.UpdatedAt)
 
 		if err != nil {
@@ -813,11 +1490,15 @@ func (r *ProductRepository) BulkCreate(ctx context.Context, products []*domain.P
 
 // BulkUpdate updates multiple products in a single transaction
 func (r *ProductRepository) BulkUpdate(ctx context.Context, products []*domain.Product) error {
+	defer observeQuery(ctx, "ProductRepository", "BulkUpdate", time.Now())
+	ctx, cancel := core.WithStatementTimeout(ctx)
+	defer cancel()
+
 	if len(products) == 0 {
 		return nil
 	}
 
-	tx, err := r.db.BeginTx(ctx, nil)
+	tx, err := beginTx(ctx, r.db, r.tx)
 	if err != nil {
 		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
@@ -827,7 +1508,8 @@ func (r *ProductRepository) BulkUpdate(ctx context.Context, products []*domain.P
 		UPDATE products SET 
 			name = $2, description = $3, category = $4, brand = $5,
 			unit_of_measure = $6, weight = $7, dimensions = $8, barcode = $9,
-			tax_rate = $10, is_active = $11, is_trackable = $12, updated_at = $13
+			tax_rate = $10, is_active = $11, is_trackable = $12, updated_at = $13,
+			category_id = $15
 		WHERE id = $1 AND organization_id = $14`
 
 	for _, product := range products {
@@ -835,7 +1517,7 @@ func (r *ProductRepository) BulkUpdate(ctx context.Context, products []*domain.P
 			product.ID, product.Name, product.Description, product.Category,
 			product.Brand, product.UnitOfMeasure, product.Weight, product.Dimensions,
 			product.Barcode, product.TaxRate, product.IsActive, product.IsTrackable,
-			time.Now(), product.OrganizationID,
+			time.Now(), product.OrganizationID, product.CategoryID,
 		)
 
 		if err != nil {
@@ -863,11 +1545,15 @@ func (r *ProductRepository) BulkUpdate(ctx context.Context, products []*domain.P
 
 // BulkDelete deletes multiple products in a single transaction
 func (r *ProductRepository) BulkDelete(ctx context.Context, organizationID uint, productIDs []uint) error {
+	defer observeQuery(ctx, "ProductRepository", "BulkDelete", time.Now())
+	ctx, cancel := core.WithStatementTimeout(ctx)
+	defer cancel()
+
 	if len(productIDs) == 0 {
 		return nil
 	}
 
-	tx, err := r.db.BeginTx(ctx, nil)
+	tx, err := beginTx(ctx, r.db, r.tx)
 	if err != nil {
 		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
@@ -904,8 +1590,93 @@ func (r *ProductRepository) BulkDelete(ctx context.Context, organizationID uint,
 	return nil
 }
 
+// BulkUpdateTaxRate updates the tax rate of multiple products
+func (r *ProductRepository) BulkUpdateTaxRate(ctx context.Context, organizationID uint, productIDs []uint, taxRate float64) error {
+	defer observeQuery(ctx, "ProductRepository", "BulkUpdateTaxRate", time.Now())
+	ctx, cancel := core.WithStatementTimeout(ctx)
+	defer cancel()
+
+	if taxRate < 0 || taxRate > 1 {
+		return domain.ErrInvalidTaxRate
+	}
+	if len(productIDs) == 0 {
+		return nil
+	}
+
+	tx, err := beginTx(ctx, r.db, r.tx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Build placeholders for IN clause
+	placeholders := make([]string, len(productIDs))
+	args := make([]interface{}, len(productIDs)+3)
+	args[0] = taxRate
+	args[1] = time.Now()
+	args[2] = organizationID
+
+	for i, id := range productIDs {
+		placeholders[i] = fmt.Sprintf("$%d", i+4)
+		args[i+3] = id
+	}
+
+	// Read the current rates for the audit trail
+	rates := make(map[uint]float64, len(productIDs))
+	rows, err := tx.QueryContext(ctx, fmt.Sprintf("SELECT id, tax_rate FROM products WHERE organization_id = $1 AND id IN (%s)", strings.Join(placeholders, ",")), args[2:]...)
+	if err != nil {
+		r.logger.Error("Failed to read product tax rates", "error", err)
+		return fmt.Errorf("failed to read product tax rates: %w", err)
+	}
+	for rows.Next() {
+		var id uint
+		var rate float64
+		if err := rows.Scan(&id, &rate); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan product tax rate: %w", err)
+		}
+		rates[id] = rate
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to read product tax rates: %w", err)
+	}
+
+	query := fmt.Sprintf("UPDATE products SET tax_rate = $1, updated_at = $2 WHERE organization_id = $3 AND id IN (%s)", strings.Join(placeholders, ","))
+
+	result, err := tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		r.logger.Error("Failed to bulk update product tax rate", "error", err)
+		return fmt.Errorf("failed to bulk update product tax rate: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit bulk tax rate update transaction: %w", err)
+	}
+
+	for _, id := range productIDs {
+		if before, ok := rates[id]; ok {
+			recordAudit(ctx, r.audit, r.logger, domain.AuditActionUpdate, "product", organizationID, id,
+				map[string]interface{}{"taxRate": before}, map[string]interface{}{"taxRate": taxRate})
+			delete(rates, id)
+		}
+	}
+
+	r.logger.Info("Bulk updated product tax rate successfully", "count", rowsAffected, "requested", len(productIDs), "taxRate", taxRate)
+	return nil
+}
+
 // GetProductStats retrieves product statistics for an organization
 func (r *ProductRepository) GetProductStats(ctx context.Context, organizationID uint) (*repository.ProductStats, error) {
+	defer observeQuery(ctx, "ProductRepository", "GetProductStats", time.Now())
+	ctx, cancel := core.WithStatementTimeout(ctx)
+	defer cancel()
+
 	query := `
 		SELECT 
 			COUNT(*) as total_products,
@@ -919,7 +1690,7 @@ func (r *ProductRepository) GetProductStats(ctx context.Context, organizationID
 		WHERE organization_id = $1`
 
 	stats := &repository.ProductStats{}
-	err := r.db.QueryRowContext(ctx, query, organizationID).Scan(
+	err := r.reader().QueryRowContext(ctx, query, organizationID).Scan(
 		&stats.TotalProducts, &stats.ActiveProducts, &stats.InactiveProducts,
 		&stats.TrackableProducts, &stats.RecentProducts, &stats.TotalCategories,
 		&stats.TotalBrands,
@@ -937,7 +1708,7 @@ func (r *ProductRepository) GetProductStats(ctx context.Context, organizationID
 		JOIN products p ON pv.product_id = p.id
 		WHERE p.organization_id = $1`
 
-	err = r.db.QueryRowContext(ctx, variantQuery, organizationID).Scan(&stats.TotalVariants)
+	err = r.reader().QueryRowContext(ctx, variantQuery, organizationID).Scan(&stats.TotalVariants)
 	if err != nil {
 		r.logger.Error("Failed to get variant count", "error", err, "organizationId", organizationID)
 		// Don't fail the entire operation for this
@@ -958,7 +1729,7 @@ func (r *ProductRepository) GetProductStats(ctx context.Context, organizationID
 		  AND pp.is_active = true
 		  AND pp.price_type = 'base'`
 
-	err = r.db.QueryRowContext(ctx, priceQuery, organizationID).Scan(
+	err = r.reader().QueryRowContext(ctx, priceQuery, organizationID).Scan(
 		&stats.AveragePrice, &stats.HighestPrice, &stats.LowestPrice,
 	)
 
@@ -975,6 +1746,10 @@ func (r *ProductRepository) GetProductStats(ctx context.Context, organizationID
 
 // GetCategoriesWithCounts retrieves categories with their product counts
 func (r *ProductRepository) GetCategoriesWithCounts(ctx context.Context, organizationID uint) ([]repository.CategoryCount, error) {
+	defer observeQuery(ctx, "ProductRepository", "GetCategoriesWithCounts", time.Now())
+	ctx, cancel := core.WithStatementTimeout(ctx)
+	defer cancel()
+
 	query := `
 		SELECT category, COUNT(*) as count
 		FROM products 
@@ -982,7 +1757,7 @@ func (r *ProductRepository) GetCategoriesWithCounts(ctx context.Context, organiz
 		GROUP BY category
 		ORDER BY count DESC, category ASC`
 
-	rows, err := r.db.QueryContext(ctx, query, organizationID)
+	rows, err := r.reader().QueryContext(ctx, query, organizationID)
 	if err != nil {
 		r.logger.Error("Failed to get categories with counts", "error", err, "organizationId", organizationID)
 		return nil, fmt.Errorf("failed to get categories with counts: %w", err)
@@ -1009,6 +1784,10 @@ func (r *ProductRepository) GetCategoriesWithCounts(ctx context.Context, organiz
 
 // GetBrandsWithCounts retrieves brands with their product counts
 func (r *ProductRepository) GetBrandsWithCounts(ctx context.Context, organizationID uint) ([]repository.BrandCount, error) {
+	defer observeQuery(ctx, "ProductRepository", "GetBrandsWithCounts", time.Now())
+	ctx, cancel := core.WithStatementTimeout(ctx)
+	defer cancel()
+
 	query := `
 		SELECT brand, COUNT(*) as count
 		FROM products 
@@ -1016,7 +1795,7 @@ func (r *ProductRepository) GetBrandsWithCounts(ctx context.Context, organizatio
 		GROUP BY brand
 		ORDER BY count DESC, brand ASC`
 
-	rows, err := r.db.QueryContext(ctx, query, organizationID)
+	rows, err := r.reader().QueryContext(ctx, query, organizationID)
 	if err != nil {
 		r.logger.Error("Failed to get brands with counts", "error", err, "organizationId", organizationID)
 		return nil, fmt.Errorf("failed to get brands with counts: %w", err)
@@ -1041,8 +1820,56 @@ func (r *ProductRepository) GetBrandsWithCounts(ctx context.Context, organizatio
 	return brands, nil
 }
 
+// GetTopProducts sums invoice item quantities and line totals per product
+func (r *ProductRepository) GetTopProducts(ctx context.Context, organizationID uint, from, to time.Time, limit int) ([]repository.ProductSales, error) {
+	defer observeQuery(ctx, "ProductRepository", "GetTopProducts", time.Now())
+	ctx, cancel := core.WithStatementTimeout(ctx)
+	defer cancel()
+
+	query := `
+		SELECT ii.product_id, p.sku, p.name,
+			   SUM(ii.quantity) as quantity_sold, SUM(ii.line_total) as revenue
+		FROM invoice_items ii
+		JOIN invoices i ON i.id = ii.invoice_id
+		JOIN products p ON p.id = ii.product_id
+		WHERE i.organization_id = $1 AND i.status NOT IN ($2, $3)
+			AND i.issue_date >= $4 AND i.issue_date < $5
+		GROUP BY ii.product_id, p.sku, p.name
+		ORDER BY quantity_sold DESC, revenue DESC, ii.product_id ASC
+		LIMIT $6`
+
+	rows, err := r.reader().QueryContext(ctx, query, organizationID,
+		domain.InvoiceStatusDraft, domain.InvoiceStatusCancelled, from, to, limit)
+	if err != nil {
+		r.logger.Error("Failed to get top products", "error", err, "organizationId", organizationID)
+		return nil, fmt.Errorf("failed to get top products: %w", err)
+	}
+	defer rows.Close()
+
+	var ranking []repository.ProductSales
+	for rows.Next() {
+		var sales repository.ProductSales
+		if err := rows.Scan(&sales.ProductID, &sales.SKU, &sales.Name, &sales.QuantitySold, &sales.Revenue); err != nil {
+			r.logger.Error("Failed to scan product sales", "error", err)
+			return nil, fmt.Errorf("failed to scan product sales: %w", err)
+		}
+		ranking = append(ranking, sales)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate product sales: %w", err)
+	}
+
+	return ranking, nil
+}
+
 // ListPaginated returns paginated products for an organization
 func (r *ProductRepository) ListPaginated(ctx context.Context, organizationID uint, params repository.PaginationParams) (repository.PaginationResult[*domain.Product], error) {
+	defer observeQuery(ctx, "ProductRepository", "ListPaginated", time.Now())
+	ctx, cancel := core.WithStatementTimeout(ctx)
+	defer cancel()
+	params = params.Clamped()
+
 	baseQuery := `
 		SELECT p.id, p.organization_id, p.name, p.sku, p.description, p.category, p.brand,
 			   p.is_active, p.is_trackable, p.created_at, p.updated_at
@@ -1053,7 +1880,7 @@ func (r *ProductRepository) ListPaginated(ctx context.Context, organizationID ui
 
 	// Get total count
 	var total int64
-	if err := r.db.QueryRowContext(ctx, countQuery, organizationID).Scan(&total); err != nil {
+	if err := r.reader().QueryRowContext(ctx, countQuery, organizationID).Scan(&total); err != nil {
 		return repository.PaginationResult[*domain.Product]{}, fmt.Errorf("failed to get total count: %w", err)
 	}
 
@@ -1063,7 +1890,7 @@ func (r *ProductRepository) ListPaginated(ctx context.Context, organizationID ui
 	paginatedQuery := helper.BuildPaginatedQuery(baseQuery, params, allowedSortFields)
 
 	// Execute query
-	rows, err := r.db.QueryContext(ctx, paginatedQuery, organizationID)
+	rows, err := r.reader().QueryContext(ctx, paginatedQuery, organizationID)
 	if err != nil {
 		return repository.PaginationResult[*domain.Product]{}, fmt.Errorf("failed to execute paginated query: %w", err)
 	}
@@ -1093,6 +1920,11 @@ func (r *ProductRepository) ListPaginated(ctx context.Context, organizationID ui
 
 // SearchPaginated returns paginated products matching search query
 func (r *ProductRepository) SearchPaginated(ctx context.Context, organizationID uint, query string, params repository.PaginationParams) (repository.PaginationResult[*domain.Product], error) {
+	defer observeQuery(ctx, "ProductRepository", "SearchPaginated", time.Now())
+	ctx, cancel := core.WithStatementTimeout(ctx)
+	defer cancel()
+	params = params.Clamped()
+
 	baseQuery := `
 		SELECT p.id, p.organization_id, p.name, p.sku, p.description, p.category, p.brand,
 			   p.is_active, p.is_trackable, p.created_at, p.updated_at
@@ -1112,7 +1944,7 @@ func (r *ProductRepository) SearchPaginated(ctx context.Context, organizationID
 
 	// Get total count
 	var total int64
-	if err := r.db.QueryRowContext(ctx, countQuery, args...).Scan(&total); err != nil {
+	if err := r.reader().QueryRowContext(ctx, countQuery, args...).Scan(&total); err != nil {
 		return repository.PaginationResult[*domain.Product]{}, fmt.Errorf("failed to get total count: %w", err)
 	}
 
@@ -1121,7 +1953,7 @@ func (r *ProductRepository) SearchPaginated(ctx context.Context, organizationID
 	paginatedQuery := helper.BuildPaginatedQuery(searchQuery, params, allowedSortFields)
 
 	// Execute query
-	rows, err := r.db.QueryContext(ctx, paginatedQuery, args...)
+	rows, err := r.reader().QueryContext(ctx, paginatedQuery, args...)
 	if err != nil {
 		return repository.PaginationResult[*domain.Product]{}, fmt.Errorf("failed to execute paginated query: %w", err)
 	}
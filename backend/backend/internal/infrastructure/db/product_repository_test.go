@@ -0,0 +1,669 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/pmaojo/kthulu-go/backend/core"
+	"github.com/pmaojo/kthulu-go/backend/internal/domain"
+	"github.com/pmaojo/kthulu-go/backend/internal/domain/repository"
+	"github.com/pmaojo/kthulu-go/backend/internal/infrastructure/testutils"
+)
+
+// setupProductTables creates the product tables used by ProductRepository tests.
+func setupProductTables(t *testing.T, sqlDB *sql.DB) {
+	_, err := sqlDB.Exec(`
+		CREATE TABLE products (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			organization_id INTEGER NOT NULL,
+			sku TEXT NOT NULL,
+			name TEXT NOT NULL,
+			description TEXT,
+			category TEXT,
+			brand TEXT,
+			unit_of_measure TEXT NOT NULL DEFAULT 'each',
+			weight REAL,
+			dimensions TEXT,
+			barcode TEXT,
+			tax_rate REAL DEFAULT 0.0,
+			is_active INTEGER NOT NULL DEFAULT 1,
+			is_trackable INTEGER NOT NULL DEFAULT 1,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			category_id INTEGER
+		);
+
+		CREATE TABLE product_categories (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			organization_id INTEGER NOT NULL,
+			parent_id INTEGER,
+			name TEXT NOT NULL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		);
+
+		CREATE TABLE product_variants (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			product_id INTEGER NOT NULL,
+			sku TEXT NOT NULL,
+			name TEXT NOT NULL,
+			description TEXT,
+			attributes TEXT,
+			weight REAL,
+			dimensions TEXT,
+			barcode TEXT,
+			is_active INTEGER NOT NULL DEFAULT 1,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		);
+
+		CREATE TABLE product_prices (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			product_id INTEGER,
+			product_variant_id INTEGER,
+			price_type TEXT NOT NULL,
+			currency TEXT NOT NULL DEFAULT 'USD',
+			amount REAL NOT NULL,
+			min_quantity INTEGER DEFAULT 1,
+			max_quantity INTEGER,
+			valid_from DATETIME,
+			valid_until DATETIME,
+			is_active INTEGER NOT NULL DEFAULT 1,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		);
+
+		CREATE TABLE product_price_history (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			price_id INTEGER NOT NULL,
+			product_id INTEGER,
+			product_variant_id INTEGER,
+			price_type TEXT NOT NULL,
+			currency TEXT NOT NULL,
+			amount REAL NOT NULL,
+			min_quantity INTEGER DEFAULT 1,
+			max_quantity INTEGER,
+			valid_from DATETIME,
+			valid_until DATETIME,
+			is_active INTEGER NOT NULL DEFAULT 1,
+			created_at DATETIME NOT NULL,
+			changed_at DATETIME NOT NULL
+		);
+
+		CREATE TABLE audit_logs (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			organization_id INTEGER NOT NULL,
+			actor_id INTEGER,
+			action TEXT NOT NULL,
+			entity_type TEXT NOT NULL,
+			entity_id INTEGER NOT NULL,
+			diff TEXT,
+			created_at DATETIME NOT NULL
+		);
+	`)
+	require.NoError(t, err)
+}
+
+func newTestProductRepository(t *testing.T) (*ProductRepository, *sql.DB) {
+	testDB := testutils.SetupTestDB(t)
+	t.Cleanup(func() { testutils.CleanupTestDB(t, testDB) })
+
+	sqlDB, err := testDB.DB()
+	require.NoError(t, err)
+	setupProductTables(t, sqlDB)
+
+	logger := core.NewLoggerFromZap(zap.NewNop())
+	repo := NewProductRepository(sqlDB, logger, NewAuditLogger(sqlDB, logger), nil).(*ProductRepository)
+	return repo, sqlDB
+}
+
+func createTestProductWithPrice(t *testing.T, repo *ProductRepository, amount float64) (*domain.Product, *domain.ProductPrice) {
+	ctx := context.Background()
+
+	product, err := domain.NewProduct(1, "SKU-1", "Widget", "each")
+	require.NoError(t, err)
+	require.NoError(t, repo.Create(ctx, product))
+
+	now := time.Now()
+	price := &domain.ProductPrice{
+		ProductID:   &product.ID,
+		PriceType:   domain.PriceTypeBase,
+		Currency:    "USD",
+		Amount:      amount,
+		MinQuantity: 1,
+		IsActive:    true,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	require.NoError(t, repo.CreatePrice(ctx, price))
+	return product, price
+}
+
+func TestProductRepositoryUpdatePrice_RecordsHistory(t *testing.T) {
+	repo, _ := newTestProductRepository(t)
+	ctx := context.Background()
+
+	product, price := createTestProductWithPrice(t, repo, 10)
+
+	price.Amount = 12
+	require.NoError(t, repo.UpdatePrice(ctx, price))
+	price.Amount = 15
+	require.NoError(t, repo.UpdatePrice(ctx, price))
+
+	history, err := repo.GetPriceHistory(ctx, product.ID)
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+	assert.Equal(t, 10.0, history[0].Amount)
+	assert.Equal(t, 12.0, history[1].Amount)
+	assert.Equal(t, price.ID, history[0].ID)
+	assert.False(t, history[1].UpdatedAt.Before(history[0].UpdatedAt))
+
+	current, err := repo.GetPriceByID(ctx, price.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 15.0, current.Amount)
+}
+
+func TestProductRepositoryUpdatePrice_NotFound(t *testing.T) {
+	repo, _ := newTestProductRepository(t)
+
+	err := repo.UpdatePrice(context.Background(), &domain.ProductPrice{ID: 999, Amount: 1, MinQuantity: 1})
+	assert.ErrorIs(t, err, domain.ErrPriceNotFound)
+}
+
+func TestProductRepositoryGetPriceHistory_Empty(t *testing.T) {
+	repo, _ := newTestProductRepository(t)
+
+	product, _ := createTestProductWithPrice(t, repo, 10)
+
+	history, err := repo.GetPriceHistory(context.Background(), product.ID)
+	require.NoError(t, err)
+	assert.Empty(t, history)
+}
+
+func TestProductRepositoryCreate_WritesAuditLog(t *testing.T) {
+	repo, sqlDB := newTestProductRepository(t)
+	ctx := repository.ContextWithActor(context.Background(), 42)
+
+	product, err := domain.NewProduct(7, "SKU-AUDIT", "Audited", "each")
+	require.NoError(t, err)
+	require.NoError(t, repo.Create(ctx, product))
+
+	var (
+		orgID, actorID, entityID uint
+		action, entityType, diff string
+	)
+	err = sqlDB.QueryRow(
+		`SELECT organization_id, actor_id, action, entity_type, entity_id, diff FROM audit_logs`,
+	).Scan(&orgID, &actorID, &action, &entityType, &entityID, &diff)
+	require.NoError(t, err)
+
+	assert.Equal(t, uint(7), orgID)
+	assert.Equal(t, uint(42), actorID)
+	assert.Equal(t, string(domain.AuditActionCreate), action)
+	assert.Equal(t, "product", entityType)
+	assert.Equal(t, product.ID, entityID)
+
+	var changes map[string]domain.AuditFieldChange
+	require.NoError(t, json.Unmarshal([]byte(diff), &changes))
+	assert.Nil(t, changes["sku"].Old)
+	assert.Equal(t, "SKU-AUDIT", changes["sku"].New)
+}
+
+func TestProductRepositoryDelete_AuditDiffKeepsPreviousValues(t *testing.T) {
+	repo, sqlDB := newTestProductRepository(t)
+	ctx := context.Background()
+
+	product, err := domain.NewProduct(1, "SKU-2", "Doomed", "each")
+	require.NoError(t, err)
+	require.NoError(t, repo.Create(ctx, product))
+	require.NoError(t, repo.Delete(ctx, product.OrganizationID, product.ID))
+
+	var (
+		actorID sql.NullInt64
+		diff    string
+	)
+	err = sqlDB.QueryRow(
+		`SELECT actor_id, diff FROM audit_logs WHERE action = 'delete' AND entity_id = ?`, product.ID,
+	).Scan(&actorID, &diff)
+	require.NoError(t, err)
+	assert.False(t, actorID.Valid)
+
+	var changes map[string]domain.AuditFieldChange
+	require.NoError(t, json.Unmarshal([]byte(diff), &changes))
+	assert.Equal(t, "Doomed", changes["name"].Old)
+	assert.Nil(t, changes["name"].New)
+}
+
+func TestProductRepositoryGetByBarcode(t *testing.T) {
+	repo, _ := newTestProductRepository(t)
+	ctx := context.Background()
+
+	product, err := domain.NewProduct(1, "SKU-SCAN", "Scanner", "each")
+	require.NoError(t, err)
+	product.Barcode = "4006381333931"
+	require.NoError(t, repo.Create(ctx, product))
+
+	found, err := repo.GetByBarcode(ctx, 1, " 4006381333931 ")
+	require.NoError(t, err)
+	assert.Equal(t, product.ID, found.ID)
+
+	_, err = repo.GetByBarcode(ctx, 2, "4006381333931")
+	assert.ErrorIs(t, err, domain.ErrProductNotFound, "barcodes are scoped to the organization")
+
+	_, err = repo.GetByBarcode(ctx, 1, "0000000000000")
+	assert.ErrorIs(t, err, domain.ErrProductNotFound)
+}
+
+func TestProductRepositoryGetVariantByBarcode(t *testing.T) {
+	repo, _ := newTestProductRepository(t)
+	ctx := context.Background()
+
+	product, err := domain.NewProduct(1, "SKU-TEE", "T-Shirt", "each")
+	require.NoError(t, err)
+	require.NoError(t, repo.Create(ctx, product))
+
+	variant, err := domain.NewProductVariant(product.ID, "SKU-TEE-M", "T-Shirt M", map[string]interface{}{"size": "M"})
+	require.NoError(t, err)
+	variant.Barcode = "5901234123457"
+	require.NoError(t, repo.CreateVariant(ctx, variant))
+
+	// The barcode belongs to the variant only
+	_, err = repo.GetByBarcode(ctx, 1, "5901234123457")
+	assert.ErrorIs(t, err, domain.ErrProductNotFound)
+
+	found, err := repo.GetVariantByBarcode(ctx, 1, "5901234123457")
+	require.NoError(t, err)
+	assert.Equal(t, variant.ID, found.ID)
+	assert.Equal(t, product.ID, found.ProductID)
+	assert.Equal(t, "M", found.Attributes["size"])
+
+	_, err = repo.GetVariantByBarcode(ctx, 2, "5901234123457")
+	assert.ErrorIs(t, err, domain.ErrVariantNotFound)
+
+	_, err = repo.GetVariantByBarcode(ctx, 1, "")
+	assert.ErrorIs(t, err, domain.ErrVariantNotFound)
+}
+
+func TestProductRepositoryListStream(t *testing.T) {
+	repo, _ := newTestProductRepository(t)
+	ctx := context.Background()
+
+	for _, sku := range []string{"SKU-A", "SKU-B", "SKU-C"} {
+		product, err := domain.NewProduct(1, sku, "Widget "+sku, "each")
+		require.NoError(t, err)
+		require.NoError(t, repo.Create(ctx, product))
+	}
+	other, err := domain.NewProduct(2, "SKU-X", "Other", "each")
+	require.NoError(t, err)
+	require.NoError(t, repo.Create(ctx, other))
+
+	filters := repository.DefaultProductFilters()
+	filters.PageSize = 1
+	filters.SortBy = "sku"
+	filters.SortOrder = "asc"
+
+	var skus []string
+	err = repo.ListStream(ctx, 1, filters, func(product *domain.Product) error {
+		skus = append(skus, product.SKU)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"SKU-A", "SKU-B", "SKU-C"}, skus, "pagination is ignored and other organizations are filtered out")
+
+	stop := errors.New("stop")
+	calls := 0
+	err = repo.ListStream(ctx, 1, filters, func(product *domain.Product) error {
+		calls++
+		return stop
+	})
+	assert.ErrorIs(t, err, stop)
+	assert.Equal(t, 1, calls)
+}
+
+func TestProductRepositoryRoutesReadsToReplica(t *testing.T) {
+	primary, primaryDB := newTestProductRepository(t)
+	_, replicaDB := newTestProductRepository(t)
+	ctx := context.Background()
+
+	logger := core.NewLoggerFromZap(zap.NewNop())
+	repo := NewProductRepository(primaryDB, logger, nil, &core.ReadReplica{DB: replicaDB}).(*ProductRepository)
+
+	product, err := domain.NewProduct(1, "SKU-P", "Written", "each")
+	require.NoError(t, err)
+	require.NoError(t, repo.Create(ctx, product))
+
+	_, err = replicaDB.Exec(`INSERT INTO products (organization_id, sku, name, description, category, brand, dimensions, barcode) VALUES (1, 'SKU-R', 'Replicated', '', '', '', '', '')`)
+	require.NoError(t, err)
+
+	// Writes and single-row reads stay on the primary
+	found, err := repo.GetByID(ctx, 1, product.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "SKU-P", found.SKU)
+	primaryProducts, _, err := primary.List(ctx, 1, repository.DefaultProductFilters())
+	require.NoError(t, err)
+	require.Len(t, primaryProducts, 1)
+
+	// Lists and stats are served by the replica
+	products, total, err := repo.List(ctx, 1, repository.DefaultProductFilters())
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+	require.Len(t, products, 1)
+	assert.Equal(t, "SKU-R", products[0].SKU)
+
+	var streamed []string
+	require.NoError(t, repo.ListStream(ctx, 1, repository.DefaultProductFilters(), func(p *domain.Product) error {
+		streamed = append(streamed, p.SKU)
+		return nil
+	}))
+	assert.Equal(t, []string{"SKU-R"}, streamed)
+}
+
+func TestProductRepositoryWithoutReplicaReadsPrimary(t *testing.T) {
+	repo, sqlDB := newTestProductRepository(t)
+	assert.Equal(t, retryingReader{sqlDB}, repo.reader())
+}
+
+func TestProductRepositoryGetTopProducts(t *testing.T) {
+	repo, sqlDB := newTestProductRepository(t)
+	setupInvoiceTables(t, sqlDB)
+	ctx := context.Background()
+
+	var products []*domain.Product
+	for _, sku := range []string{"BOLT", "NUT", "GEAR"} {
+		product, err := domain.NewProduct(1, sku, sku, "each")
+		require.NoError(t, err)
+		require.NoError(t, repo.Create(ctx, product))
+		products = append(products, product)
+	}
+	bolt, nut, gear := products[0].ID, products[1].ID, products[2].ID
+
+	march := time.Date(2024, time.March, 10, 0, 0, 0, 0, time.UTC)
+	first := createTestInvoice(t, sqlDB, 1, "paid", march, 0, 0)
+	createTestInvoiceItem(t, sqlDB, first, bolt, 10, 1)
+	createTestInvoiceItem(t, sqlDB, first, nut, 5, 2)
+	second := createTestInvoice(t, sqlDB, 1, "sent", march.AddDate(0, 0, 5), 0, 0)
+	createTestInvoiceItem(t, sqlDB, second, nut, 8, 2)
+	createTestInvoiceItem(t, sqlDB, second, gear, 1, 50)
+
+	// Drafts, canceled invoices, other periods and organizations don't count
+	draft := createTestInvoice(t, sqlDB, 1, "draft", march, 0, 0)
+	createTestInvoiceItem(t, sqlDB, draft, gear, 100, 50)
+	canceled := createTestInvoice(t, sqlDB, 1, "canceled", march, 0, 0)
+	createTestInvoiceItem(t, sqlDB, canceled, gear, 100, 50)
+	april := createTestInvoice(t, sqlDB, 1, "paid", time.Date(2024, time.April, 2, 0, 0, 0, 0, time.UTC), 0, 0)
+	createTestInvoiceItem(t, sqlDB, april, gear, 100, 50)
+	other := createTestInvoice(t, sqlDB, 2, "paid", march, 0, 0)
+	createTestInvoiceItem(t, sqlDB, other, gear, 100, 50)
+
+	from := time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, time.April, 1, 0, 0, 0, 0, time.UTC)
+	ranking, err := repo.GetTopProducts(ctx, 1, from, to, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []repository.ProductSales{
+		{ProductID: nut, SKU: "NUT", Name: "NUT", QuantitySold: 13, Revenue: 26},
+		{ProductID: bolt, SKU: "BOLT", Name: "BOLT", QuantitySold: 10, Revenue: 10},
+		{ProductID: gear, SKU: "GEAR", Name: "GEAR", QuantitySold: 1, Revenue: 50},
+	}, ranking)
+
+	ranking, err = repo.GetTopProducts(ctx, 1, from, to, 1)
+	require.NoError(t, err)
+	require.Len(t, ranking, 1)
+	assert.Equal(t, nut, ranking[0].ProductID)
+}
+
+func TestProductRepositoryClone(t *testing.T) {
+	repo, sqlDB := newTestProductRepository(t)
+	ctx := context.Background()
+
+	product, err := domain.NewProduct(1, "SKU-1", "Widget", "each")
+	require.NoError(t, err)
+	product.Brand = "Acme"
+	product.Barcode = "5901234123457"
+	require.NoError(t, repo.Create(ctx, product))
+
+	now := time.Now()
+	price := &domain.ProductPrice{
+		ProductID:   &product.ID,
+		PriceType:   domain.PriceTypeBase,
+		Currency:    "USD",
+		Amount:      10,
+		MinQuantity: 1,
+		IsActive:    true,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	require.NoError(t, repo.CreatePrice(ctx, price))
+
+	variant, err := domain.NewProductVariant(product.ID, "SKU-1-RED", "Widget red", map[string]interface{}{"color": "red"})
+	require.NoError(t, err)
+	require.NoError(t, repo.CreateVariant(ctx, variant))
+	variantPrice := &domain.ProductPrice{
+		ProductVariantID: &variant.ID,
+		PriceType:        domain.PriceTypeBase,
+		Currency:         "USD",
+		Amount:           12,
+		MinQuantity:      1,
+		IsActive:         true,
+		CreatedAt:        now,
+		UpdatedAt:        now,
+	}
+	require.NoError(t, repo.CreatePrice(ctx, variantPrice))
+
+	clone, err := repo.Clone(ctx, 1, product.ID, "SKU-2")
+	require.NoError(t, err)
+	assert.NotEqual(t, product.ID, clone.ID)
+	assert.Equal(t, "SKU-2", clone.SKU)
+	assert.Equal(t, "Widget", clone.Name)
+	assert.Equal(t, "Acme", clone.Brand)
+	assert.Empty(t, clone.Barcode)
+
+	variants, err := repo.GetVariantsByProductID(ctx, clone.ID)
+	require.NoError(t, err)
+	require.Len(t, variants, 1)
+	assert.NotEqual(t, variant.ID, variants[0].ID)
+	assert.Equal(t, "SKU-2-RED", variants[0].SKU)
+	assert.Equal(t, "red", variants[0].Attributes["color"])
+
+	prices, err := repo.GetPricesByProductID(ctx, clone.ID)
+	require.NoError(t, err)
+	require.Len(t, prices, 1)
+	assert.NotEqual(t, price.ID, prices[0].ID)
+	assert.Equal(t, 10.0, prices[0].Amount)
+
+	clonedVariantPrices, err := repo.GetPricesByVariantID(ctx, variants[0].ID)
+	require.NoError(t, err)
+	require.Len(t, clonedVariantPrices, 1)
+	assert.NotEqual(t, variantPrice.ID, clonedVariantPrices[0].ID)
+	assert.Equal(t, 12.0, clonedVariantPrices[0].Amount)
+
+	// The source product is left untouched
+	sourcePrices, err := repo.GetPricesByVariantID(ctx, variant.ID)
+	require.NoError(t, err)
+	require.Len(t, sourcePrices, 1)
+	assert.Equal(t, variantPrice.ID, sourcePrices[0].ID)
+
+	var audited int
+	require.NoError(t, sqlDB.QueryRow(`SELECT COUNT(*) FROM audit_logs WHERE entity_id = ? AND action = ?`, clone.ID, string(domain.AuditActionCreate)).Scan(&audited))
+	assert.Equal(t, 1, audited)
+}
+
+func TestProductRepositoryClone_RejectsExistingSKU(t *testing.T) {
+	repo, sqlDB := newTestProductRepository(t)
+	ctx := context.Background()
+
+	product, _ := createTestProductWithPrice(t, repo, 10)
+	other, err := domain.NewProduct(1, "SKU-TAKEN", "Other", "each")
+	require.NoError(t, err)
+	require.NoError(t, repo.Create(ctx, other))
+
+	_, err = repo.Clone(ctx, 1, product.ID, "SKU-TAKEN")
+	assert.ErrorIs(t, err, domain.ErrProductAlreadyExists)
+
+	_, err = repo.Clone(ctx, 2, product.ID, "SKU-NEW")
+	assert.ErrorIs(t, err, domain.ErrProductNotFound)
+
+	var count int
+	require.NoError(t, sqlDB.QueryRow(`SELECT COUNT(*) FROM products`).Scan(&count))
+	assert.Equal(t, 2, count)
+}
+
+func createTestProductCategory(t *testing.T, repo *ProductRepository, organizationID uint, parent *domain.ProductCategory, name string) *domain.ProductCategory {
+	var parentID *uint
+	if parent != nil {
+		parentID = &parent.ID
+	}
+	category, err := domain.NewProductCategory(organizationID, parentID, name)
+	require.NoError(t, err)
+	require.NoError(t, repo.CreateCategory(context.Background(), category))
+	return category
+}
+
+func TestProductRepositoryListCategoryTree(t *testing.T) {
+	repo, _ := newTestProductRepository(t)
+	ctx := context.Background()
+
+	tools := createTestProductCategory(t, repo, 1, nil, "Tools")
+	power := createTestProductCategory(t, repo, 1, tools, "Power tools")
+	createTestProductCategory(t, repo, 1, power, "Drills")
+	createTestProductCategory(t, repo, 1, tools, "Hand tools")
+	createTestProductCategory(t, repo, 1, nil, "Garden")
+	createTestProductCategory(t, repo, 2, nil, "Other organization")
+
+	tree, err := repo.ListCategoryTree(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, tree, 2)
+	assert.Equal(t, "Garden", tree[0].Name)
+	assert.Equal(t, "Tools", tree[1].Name)
+	require.Len(t, tree[1].Children, 2)
+	assert.Equal(t, "Hand tools", tree[1].Children[0].Name)
+	assert.Equal(t, "Power tools", tree[1].Children[1].Name)
+	require.Len(t, tree[1].Children[1].Children, 1)
+	assert.Equal(t, "Drills", tree[1].Children[1].Children[0].Name)
+
+	// Parents must belong to the same organization
+	foreign, err := domain.NewProductCategory(2, &tools.ID, "Stolen")
+	require.NoError(t, err)
+	assert.ErrorIs(t, repo.CreateCategory(ctx, foreign), domain.ErrProductCategoryNotFound)
+}
+
+func TestProductRepositoryList_CategoryIncludesDescendants(t *testing.T) {
+	repo, _ := newTestProductRepository(t)
+	ctx := context.Background()
+
+	tools := createTestProductCategory(t, repo, 1, nil, "Tools")
+	power := createTestProductCategory(t, repo, 1, tools, "Power tools")
+	drills := createTestProductCategory(t, repo, 1, power, "Drills")
+	garden := createTestProductCategory(t, repo, 1, nil, "Garden")
+
+	for sku, category := range map[string]*domain.ProductCategory{
+		"SKU-TOOL":  tools,
+		"SKU-POWER": power,
+		"SKU-DRILL": drills,
+		"SKU-HOSE":  garden,
+	} {
+		product, err := domain.NewProduct(1, sku, sku, "each")
+		require.NoError(t, err)
+		product.CategoryID = &category.ID
+		require.NoError(t, repo.Create(ctx, product))
+	}
+	uncategorized, err := domain.NewProduct(1, "SKU-NONE", "Uncategorized", "each")
+	require.NoError(t, err)
+	require.NoError(t, repo.Create(ctx, uncategorized))
+
+	listSKUs := func(categoryID uint) []string {
+		filters := repository.DefaultProductFilters()
+		filters.CategoryID = &categoryID
+		filters.SortBy = "sku"
+		filters.SortOrder = "asc"
+		products, total, err := repo.List(ctx, 1, filters)
+		require.NoError(t, err)
+		skus := make([]string, len(products))
+		for i, product := range products {
+			skus[i] = product.SKU
+		}
+		assert.Equal(t, int64(len(products)), total)
+		return skus
+	}
+
+	assert.Equal(t, []string{"SKU-DRILL", "SKU-POWER", "SKU-TOOL"}, listSKUs(tools.ID))
+	assert.Equal(t, []string{"SKU-DRILL", "SKU-POWER"}, listSKUs(power.ID))
+	assert.Equal(t, []string{"SKU-DRILL"}, listSKUs(drills.ID))
+	assert.Equal(t, []string{"SKU-HOSE"}, listSKUs(garden.ID))
+
+	// Categories of other organizations match nothing
+	assert.Empty(t, listSKUs(9999))
+	found, err := repo.GetBySKU(ctx, 1, "SKU-DRILL")
+	require.NoError(t, err)
+	require.NotNil(t, found.CategoryID)
+	assert.Equal(t, drills.ID, *found.CategoryID)
+}
+
+func TestProductRepositoryBulkUpdateTaxRate(t *testing.T) {
+	repo, sqlDB := newTestProductRepository(t)
+	ctx := context.Background()
+
+	var products []*domain.Product
+	for _, sku := range []string{"SKU-A", "SKU-B", "SKU-C"} {
+		product, err := domain.NewProduct(1, sku, sku, "each")
+		require.NoError(t, err)
+		product.TaxRate = 0.21
+		require.NoError(t, repo.Create(ctx, product))
+		products = append(products, product)
+	}
+	other, err := domain.NewProduct(2, "SKU-X", "Other", "each")
+	require.NoError(t, err)
+	other.TaxRate = 0.21
+	require.NoError(t, repo.Create(ctx, other))
+
+	// Products of other organizations are ignored even when listed
+	require.NoError(t, repo.BulkUpdateTaxRate(ctx, 1, []uint{products[0].ID, products[1].ID, other.ID}, 0.1))
+
+	taxRates := func(organizationID uint, skus ...string) []float64 {
+		rates := make([]float64, len(skus))
+		for i, sku := range skus {
+			product, err := repo.GetBySKU(ctx, organizationID, sku)
+			require.NoError(t, err)
+			rates[i] = product.TaxRate
+		}
+		return rates
+	}
+	assert.Equal(t, []float64{0.1, 0.1, 0.21}, taxRates(1, "SKU-A", "SKU-B", "SKU-C"))
+	assert.Equal(t, []float64{0.21}, taxRates(2, "SKU-X"))
+
+	// Each updated product gets its own audit entry with the previous rate
+	for _, product := range []*domain.Product{products[0], products[1], other} {
+		var diffs []string
+		rows, err := sqlDB.Query(`SELECT diff FROM audit_logs WHERE action = 'update' AND entity_id = ?`, product.ID)
+		require.NoError(t, err)
+		for rows.Next() {
+			var diff string
+			require.NoError(t, rows.Scan(&diff))
+			diffs = append(diffs, diff)
+		}
+		require.NoError(t, rows.Close())
+		if product == other {
+			assert.Empty(t, diffs)
+			continue
+		}
+		require.Len(t, diffs, 1)
+		var changes map[string]domain.AuditFieldChange
+		require.NoError(t, json.Unmarshal([]byte(diffs[0]), &changes))
+		assert.Equal(t, 0.21, changes["taxRate"].Old)
+		assert.Equal(t, 0.1, changes["taxRate"].New)
+	}
+
+	// Out of range rates are rejected before anything is written
+	assert.ErrorIs(t, repo.BulkUpdateTaxRate(ctx, 1, []uint{products[2].ID}, 21), domain.ErrInvalidTaxRate)
+	assert.ErrorIs(t, repo.BulkUpdateTaxRate(ctx, 1, []uint{products[2].ID}, -0.1), domain.ErrInvalidTaxRate)
+	assert.Equal(t, []float64{0.21}, taxRates(1, "SKU-C"))
+
+	assert.NoError(t, repo.BulkUpdateTaxRate(ctx, 1, nil, 0.1))
+}
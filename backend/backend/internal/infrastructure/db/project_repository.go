@@ -192,6 +192,8 @@ func (r *ProjectRepository) Count(ctx context.Context) (int64, error) {
 
 // FindPaginated returns paginated projects
 func (r *ProjectRepository) FindPaginated(ctx context.Context, params repository.PaginationParams) (repository.PaginationResult[*domain.Project], error) {
+	params = params.Clamped()
+
 	var models []ProjectModel
 	var total int64
 
@@ -234,6 +236,8 @@ func (r *ProjectRepository) FindPaginated(ctx context.Context, params repository
 
 // SearchPaginated returns paginated projects matching search query
 func (r *ProjectRepository) SearchPaginated(ctx context.Context, query string, params repository.PaginationParams) (repository.PaginationResult[*domain.Project], error) {
+	params = params.Clamped()
+
 	var models []ProjectModel
 	var total int64
 
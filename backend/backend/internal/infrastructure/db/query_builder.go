@@ -0,0 +1,68 @@
+// @kthulu:core
+package db
+
+import (
+	"fmt"
+	"strings"
+)
+
+// QueryBuilder collects the conditions of a WHERE clause together with their
+// arguments, numbering $N placeholders in the order arguments are added so
+// callers never track the index themselves.
+type QueryBuilder struct {
+	conditions []string
+	args       []interface{}
+}
+
+// NewQueryBuilder creates an empty builder
+func NewQueryBuilder() *QueryBuilder {
+	return &QueryBuilder{}
+}
+
+// Where adds a condition joined to the others with AND. Every ? in condition
+// is replaced by the placeholder of the next argument, so it needs as many
+// args as it has question marks.
+func (b *QueryBuilder) Where(condition string, args ...interface{}) *QueryBuilder {
+	if n := strings.Count(condition, "?"); n != len(args) {
+		panic(fmt.Sprintf("query builder: condition %q has %d placeholders but %d arguments", condition, n, len(args)))
+	}
+
+	var sb strings.Builder
+	next := 0
+	for _, r := range condition {
+		if r != '?' {
+			sb.WriteRune(r)
+			continue
+		}
+		sb.WriteString(b.Arg(args[next]))
+		next++
+	}
+	b.conditions = append(b.conditions, sb.String())
+	return b
+}
+
+// Arg adds an argument and returns its placeholder, for queries that need
+// one outside of the WHERE clause
+func (b *QueryBuilder) Arg(value interface{}) string {
+	b.args = append(b.args, value)
+	return fmt.Sprintf("$%d", len(b.args))
+}
+
+// WhereClause returns the conditions as a WHERE clause, or an empty string
+// when there are none
+func (b *QueryBuilder) WhereClause() string {
+	if len(b.conditions) == 0 {
+		return ""
+	}
+	return "WHERE " + strings.Join(b.conditions, " AND ")
+}
+
+// Args returns the arguments in placeholder order
+func (b *QueryBuilder) Args() []interface{} {
+	return b.args
+}
+
+// Build returns the WHERE clause and its arguments
+func (b *QueryBuilder) Build() (string, []interface{}) {
+	return b.WhereClause(), b.Args()
+}
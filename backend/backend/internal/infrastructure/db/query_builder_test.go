@@ -0,0 +1,83 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pmaojo/kthulu-go/backend/internal/domain"
+	"github.com/pmaojo/kthulu-go/backend/internal/domain/repository"
+)
+
+func TestQueryBuilderNumbersPlaceholdersInOrder(t *testing.T) {
+	where, args := NewQueryBuilder().
+		Where("organization_id = ?", 7).
+		Where("status = ?", "paid").
+		Where("(name LIKE ? OR sku LIKE ?)", "%a%", "%a%").
+		Where("deleted_at IS NULL").
+		Where("total >= ?", 10.5).
+		Build()
+
+	assert.Equal(t, "WHERE organization_id = $1 AND status = $2 AND (name LIKE $3 OR sku LIKE $4) AND deleted_at IS NULL AND total >= $5", where)
+	assert.Equal(t, []interface{}{7, "paid", "%a%", "%a%", 10.5}, args)
+}
+
+func TestQueryBuilderArgSharesNumbering(t *testing.T) {
+	qb := NewQueryBuilder().Where("organization_id = ?", 1)
+	limit := qb.Arg(20)
+	qb.Where("status = ?", "sent")
+
+	assert.Equal(t, "$2", limit)
+	assert.Equal(t, "WHERE organization_id = $1 AND status = $3", qb.WhereClause())
+	assert.Equal(t, []interface{}{1, 20, "sent"}, qb.Args())
+}
+
+func TestQueryBuilderEmptyAndMismatchedConditions(t *testing.T) {
+	where, args := NewQueryBuilder().Build()
+	assert.Empty(t, where)
+	assert.Empty(t, args)
+
+	assert.Panics(t, func() { NewQueryBuilder().Where("a = ? AND b = ?", 1) })
+}
+
+func TestBuildInvoiceWhereClauseNumbersSparseFilters(t *testing.T) {
+	repo := &InvoiceRepository{}
+	contactID, createdBy := uint(4), uint(9)
+	status := domain.InvoiceStatusSent
+	from := "2024-01-01"
+
+	// Whichever filters are set, placeholders stay contiguous
+	for _, tc := range []struct {
+		filters repository.InvoiceFilters
+		where   string
+		args    []interface{}
+	}{
+		{
+			repository.InvoiceFilters{CreatedBy: &createdBy},
+			"WHERE organization_id = $1 AND created_by = $2",
+			[]interface{}{uint(1), createdBy},
+		},
+		{
+			repository.InvoiceFilters{ContactID: &contactID, IssuedFrom: &from, CreatedBy: &createdBy},
+			"WHERE organization_id = $1 AND contact_id = $2 AND issue_date >= $3 AND created_by = $4",
+			[]interface{}{uint(1), contactID, from, createdBy},
+		},
+		{
+			repository.InvoiceFilters{Status: &status, Search: "INV"},
+			"WHERE organization_id = $1 AND status = $2 AND LOWER(invoice_number) LIKE $3",
+			[]interface{}{uint(1), status, "%inv%"},
+		},
+	} {
+		where, args := repo.buildInvoiceWhereClause(1, tc.filters)
+		assert.Equal(t, tc.where, where)
+		assert.Equal(t, tc.args, args)
+	}
+}
+
+func TestBuildProductWhereClauseRepeatsSearchArgument(t *testing.T) {
+	active := true
+	where, args := (&ProductRepository{}).buildWhereClause(3, repository.ProductFilters{Search: "Bolt", IsActive: &active, Brand: "Acme"})
+
+	assert.Equal(t, "WHERE organization_id = $1 AND brand = $2 AND is_active = $3 AND (LOWER(name) LIKE $4 OR LOWER(sku) LIKE $5 OR LOWER(description) LIKE $6)", where)
+	assert.Equal(t, []interface{}{uint(3), "Acme", true, "%bolt%", "%bolt%", "%bolt%"}, args)
+}
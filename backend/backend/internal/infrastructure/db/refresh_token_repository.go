@@ -21,6 +21,8 @@ type RefreshTokenModel struct {
 	Token     string    `gorm:"uniqueIndex;not null"`
 	ExpiresAt time.Time `gorm:"not null"`
 	CreatedAt time.Time
+	// AccessTokenID is the jti of the access token issued alongside
+	AccessTokenID string `gorm:"not null;default:''"`
 
 	// Association
 	User *UserModel `gorm:"foreignKey:UserID"`
@@ -39,6 +41,8 @@ func (rt *RefreshTokenModel) ToDomain() (*domain.RefreshToken, error) {
 		Token:     rt.Token,
 		ExpiresAt: rt.ExpiresAt,
 		CreatedAt: rt.CreatedAt,
+
+		AccessTokenID: rt.AccessTokenID,
 	}
 
 	if rt.User != nil {
@@ -59,6 +63,7 @@ func (rt *RefreshTokenModel) FromDomain(token *domain.RefreshToken) {
 	rt.Token = token.Token
 	rt.ExpiresAt = token.ExpiresAt
 	rt.CreatedAt = token.CreatedAt
+	rt.AccessTokenID = token.AccessTokenID
 }
 
 // RefreshTokenRepository provides a database-backed implementation of repository.RefreshTokenRepository.
@@ -176,9 +181,9 @@ func (r *RefreshTokenRepository) CountByUserID(ctx context.Context, userID uint)
 	return count, err
 }
 
-// DeleteExpired removes all expired refresh tokens.
-func (r *RefreshTokenRepository) DeleteExpired(ctx context.Context) (int64, error) {
-	result := r.db.WithContext(ctx).Where("expires_at < ?", time.Now()).Delete(&RefreshTokenModel{})
+// DeleteExpired removes all refresh tokens that expired before the given time.
+func (r *RefreshTokenRepository) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Where("expires_at < ?", before).Delete(&RefreshTokenModel{})
 	return result.RowsAffected, result.Error
 }
 
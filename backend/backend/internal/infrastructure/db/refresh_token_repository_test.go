@@ -0,0 +1,50 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pmaojo/kthulu-go/backend/internal/domain"
+	"github.com/pmaojo/kthulu-go/backend/internal/infrastructure/testutils"
+)
+
+func TestRefreshTokenRepositoryDeleteExpired(t *testing.T) {
+	testDB := testutils.SetupTestDB(t)
+	defer testutils.CleanupTestDB(t, testDB)
+
+	repo := NewRefreshTokenRepository(testDB)
+	ctx := context.Background()
+	now := time.Now()
+
+	tokens := map[string]time.Time{
+		"expired-day":  now.Add(-24 * time.Hour),
+		"expired-hour": now.Add(-time.Hour),
+		"valid-hour":   now.Add(time.Hour),
+		"valid-week":   now.Add(7 * 24 * time.Hour),
+	}
+	for token, expiresAt := range tokens {
+		require.NoError(t, repo.Create(ctx, &domain.RefreshToken{UserID: 1, Token: token, ExpiresAt: expiresAt}))
+	}
+
+	deleted, err := repo.DeleteExpired(ctx, now)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), deleted)
+
+	for token, expiresAt := range tokens {
+		_, err := repo.FindByToken(ctx, token)
+		if expiresAt.After(now) {
+			assert.NoError(t, err, "token %s should be kept", token)
+		} else {
+			assert.ErrorIs(t, err, domain.ErrTokenNotFound, "token %s should be deleted", token)
+		}
+	}
+
+	// Nothing is left to delete for the same cutoff
+	deleted, err = repo.DeleteExpired(ctx, now)
+	require.NoError(t, err)
+	assert.Zero(t, deleted)
+}
@@ -0,0 +1,144 @@
+// @kthulu:core
+package db
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// RetryPolicy bounds how reads are retried after transient errors
+type RetryPolicy struct {
+	// Attempts is the total number of tries, the first included
+	Attempts int
+	// InitialBackoff is the wait before the first retry; it doubles for each
+	// further one up to MaxBackoff
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+// DefaultRetryPolicy rides out a dropped connection or a database restart of
+// about a second without holding requests much longer
+var DefaultRetryPolicy = RetryPolicy{
+	Attempts:       3,
+	InitialBackoff: 100 * time.Millisecond,
+	MaxBackoff:     time.Second,
+}
+
+// retriablePgStates are PostgreSQL SQLSTATEs worth retrying besides the
+// connection exception class 08
+var retriablePgStates = map[string]bool{
+	"40001": true, // serialization_failure
+	"40P01": true, // deadlock_detected
+	"53300": true, // too_many_connections
+	"57P01": true, // admin_shutdown
+	"57P02": true, // crash_shutdown
+	"57P03": true, // cannot_connect_now
+}
+
+// IsRetriable reports whether err is a transient database error, such as a
+// broken connection or a server restarting, that a retry can succeed past.
+// Cancelled contexts, missing rows and errors in the statement or its data
+// are not.
+func IsRetriable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) || errors.Is(err, sql.ErrNoRows) {
+		return false
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return strings.HasPrefix(pgErr.Code, "08") || retriablePgStates[pgErr.Code]
+	}
+	var sqliteErr *sqlite.Error
+	if errors.As(err, &sqliteErr) {
+		code := sqliteErr.Code() & 0xff
+		return code == sqlite3.SQLITE_BUSY || code == sqlite3.SQLITE_LOCKED
+	}
+	if pgconn.SafeToRetry(err) {
+		return true
+	}
+
+	switch {
+	case errors.Is(err, driver.ErrBadConn), errors.Is(err, sql.ErrConnDone),
+		errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF),
+		errors.Is(err, syscall.ECONNREFUSED), errors.Is(err, syscall.ECONNRESET),
+		errors.Is(err, syscall.ECONNABORTED), errors.Is(err, syscall.EPIPE):
+		return true
+	}
+	var netErr *net.OpError
+	return errors.As(err, &netErr)
+}
+
+// withRetry calls fn until it succeeds, fails with an error that isn't
+// retriable, runs out of attempts or ctx is done, backing off between tries.
+// Only idempotent work belongs in fn: a write whose connection drops may have
+// committed.
+func withRetry(ctx context.Context, policy RetryPolicy, fn func(context.Context) error) error {
+	backoff := policy.InitialBackoff
+	for attempt := 1; ; attempt++ {
+		err := fn(ctx)
+		if err == nil || attempt >= policy.Attempts || !IsRetriable(err) {
+			return err
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+		if backoff *= 2; backoff > policy.MaxBackoff {
+			backoff = policy.MaxBackoff
+		}
+	}
+}
+
+// queryWithRetry runs a read query, retrying transient errors with the
+// default policy
+func queryWithRetry(ctx context.Context, conn sqlConn, query string, args ...interface{}) (*sql.Rows, error) {
+	var rows *sql.Rows
+	err := withRetry(ctx, DefaultRetryPolicy, func(ctx context.Context) error {
+		var err error
+		rows, err = conn.QueryContext(ctx, query, args...)
+		return err
+	})
+	return rows, err
+}
+
+// queryRowWithRetry runs a single row read query, retrying transient errors
+// with the default policy. The returned row holds the last error, if any.
+func queryRowWithRetry(ctx context.Context, conn sqlConn, query string, args ...interface{}) *sql.Row {
+	var row *sql.Row
+	_ = withRetry(ctx, DefaultRetryPolicy, func(ctx context.Context) error {
+		row = conn.QueryRowContext(ctx, query, args...)
+		return row.Err()
+	})
+	return row
+}
+
+// retryingReader runs the queries of a pool through queryWithRetry and
+// queryRowWithRetry. It is only for reads: statements run through
+// ExecContext are not retried, and neither is anything in a transaction,
+// whose work is lost with its connection.
+type retryingReader struct {
+	sqlConn
+}
+
+func (r retryingReader) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return queryWithRetry(ctx, r.sqlConn, query, args...)
+}
+
+func (r retryingReader) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return queryRowWithRetry(ctx, r.sqlConn, query, args...)
+}
@@ -0,0 +1,143 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/pmaojo/kthulu-go/backend/core"
+)
+
+func TestIsRetriableClassifiesErrors(t *testing.T) {
+	retriable := map[string]error{
+		"bad connection":      driver.ErrBadConn,
+		"connection done":     sql.ErrConnDone,
+		"connection reset":    fmt.Errorf("read: %w", syscall.ECONNRESET),
+		"connection refused":  syscall.ECONNREFUSED,
+		"unexpected eof":      io.ErrUnexpectedEOF,
+		"connection failure":  &pgconn.PgError{Code: "08006"},
+		"admin shutdown":      &pgconn.PgError{Code: "57P01"},
+		"serialization":       &pgconn.PgError{Code: "40001"},
+		"too many clients":    &pgconn.PgError{Code: "53300"},
+		"wrapped pg shutdown": fmt.Errorf("failed to list products: %w", &pgconn.PgError{Code: "57P03"}),
+	}
+	for name, err := range retriable {
+		assert.True(t, IsRetriable(err), name)
+	}
+
+	permanent := map[string]error{
+		"nil":              nil,
+		"no rows":          sql.ErrNoRows,
+		"cancelled":        context.Canceled,
+		"deadline":         fmt.Errorf("query: %w", context.DeadlineExceeded),
+		"unique violation": &pgconn.PgError{Code: "23505"},
+		"syntax error":     &pgconn.PgError{Code: "42601"},
+		"other":            errors.New("no such table: products"),
+	}
+	for name, err := range permanent {
+		assert.False(t, IsRetriable(err), name)
+	}
+}
+
+func TestWithRetryRetriesTransientErrors(t *testing.T) {
+	policy := RetryPolicy{Attempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond}
+
+	calls := 0
+	err := withRetry(context.Background(), policy, func(context.Context) error {
+		if calls++; calls < 3 {
+			return syscall.ECONNRESET
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 3, calls)
+
+	// Attempts are bounded
+	calls = 0
+	err = withRetry(context.Background(), policy, func(context.Context) error {
+		calls++
+		return driver.ErrBadConn
+	})
+	assert.ErrorIs(t, err, driver.ErrBadConn)
+	assert.Equal(t, 3, calls)
+}
+
+func TestWithRetryDoesNotRetryPermanentErrors(t *testing.T) {
+	policy := RetryPolicy{Attempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond}
+	violation := &pgconn.PgError{Code: "23505"}
+
+	calls := 0
+	err := withRetry(context.Background(), policy, func(context.Context) error {
+		calls++
+		return violation
+	})
+	assert.ErrorIs(t, err, violation)
+	assert.Equal(t, 1, calls)
+}
+
+func TestWithRetryStopsWhenContextIsDone(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	policy := RetryPolicy{Attempts: 5, InitialBackoff: time.Hour, MaxBackoff: time.Hour}
+
+	calls := 0
+	err := withRetry(ctx, policy, func(context.Context) error {
+		calls++
+		cancel()
+		return syscall.ECONNREFUSED
+	})
+	assert.ErrorIs(t, err, syscall.ECONNREFUSED)
+	assert.Equal(t, 1, calls)
+}
+
+func TestQueryWithRetryReconnects(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT id FROM products").WillReturnError(syscall.ECONNRESET)
+	mock.ExpectQuery("SELECT id FROM products").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+
+	rows, err := queryWithRetry(context.Background(), db, "SELECT id FROM products")
+	require.NoError(t, err)
+	rows.Close()
+
+	// A permanent error is returned without trying again
+	mock.ExpectQuery("SELECT id FROM missing").WillReturnError(&pgconn.PgError{Code: "42P01"})
+	var id int
+	err = queryRowWithRetry(context.Background(), db, "SELECT id FROM missing").Scan(&id)
+	var pgErr *pgconn.PgError
+	require.ErrorAs(t, err, &pgErr)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestInvoiceRepositoryGetByIDRetriesTransientErrors(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+	repo := NewInvoiceRepository(db, core.NewLoggerFromZap(zap.NewNop()), nil, nil)
+
+	columns := strings.Split(invoiceColumns, ", ")
+	now := time.Now()
+	values := []driver.Value{7, 1, 3, "INV-7", "invoice", "sent", "EUR", 1, 100, 21, 0, 121, 0, 121, now, now, "", "", "", 1, now, now}
+	mock.ExpectQuery("SELECT (.+) FROM invoices WHERE id").WillReturnError(syscall.ECONNRESET)
+	mock.ExpectQuery("SELECT (.+) FROM invoices WHERE id").WillReturnRows(sqlmock.NewRows(columns).AddRow(values...))
+
+	invoice, err := repo.GetByID(context.Background(), 1, 7)
+	require.NoError(t, err)
+	assert.Equal(t, "INV-7", invoice.InvoiceNumber)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
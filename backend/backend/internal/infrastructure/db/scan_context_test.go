@@ -0,0 +1,93 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/pmaojo/kthulu-go/backend/core"
+)
+
+// slowVariantRows serves an endless product_variants result, sleeping before
+// every row like a large scan over a slow network would
+type slowVariantRows struct {
+	served *int64
+}
+
+func (r *slowVariantRows) Columns() []string {
+	return []string{"id", "product_id", "sku", "name", "description", "attributes", "weight",
+		"dimensions", "barcode", "is_active", "created_at", "updated_at"}
+}
+
+func (r *slowVariantRows) Close() error { return nil }
+
+func (r *slowVariantRows) Next(dest []driver.Value) error {
+	time.Sleep(time.Millisecond)
+	id := atomic.AddInt64(r.served, 1)
+	if id > 100000 {
+		return io.EOF
+	}
+	now := time.Now()
+	copy(dest, []driver.Value{id, int64(1), "SKU", "Variant", "", nil, nil, "", "", true, now, now})
+	return nil
+}
+
+// slowConn answers every query with slowVariantRows
+type slowConn struct {
+	served *int64
+}
+
+func (c *slowConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, errors.New("prepare not supported")
+}
+func (c *slowConn) Close() error              { return nil }
+func (c *slowConn) Begin() (driver.Tx, error) { return nil, errors.New("transactions not supported") }
+func (c *slowConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	return &slowVariantRows{served: c.served}, nil
+}
+
+type slowConnector struct {
+	served *int64
+}
+
+func (c *slowConnector) Connect(context.Context) (driver.Conn, error) {
+	return &slowConn{served: c.served}, nil
+}
+func (c *slowConnector) Driver() driver.Driver { return nil }
+
+func TestGetVariantsByProductIDStopsWhenContextIsCancelled(t *testing.T) {
+	var served int64
+	sqlDB := sql.OpenDB(&slowConnector{served: &served})
+	t.Cleanup(func() { sqlDB.Close() })
+	logger := core.NewLoggerFromZap(zap.NewNop())
+	repo := NewProductRepository(sqlDB, logger, NewAuditLogger(sqlDB, logger), nil).(*ProductRepository)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	variants, err := repo.GetVariantsByProductID(ctx, 1)
+	require.Error(t, err)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Nil(t, variants)
+	assert.Less(t, time.Since(start), 5*time.Second)
+	assert.Less(t, atomic.LoadInt64(&served), int64(100000))
+}
+
+func TestCheckContextOnlyChecksEveryInterval(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	assert.NoError(t, checkContext(ctx, 1))
+	assert.NoError(t, checkContext(ctx, contextCheckInterval-1))
+	assert.ErrorIs(t, checkContext(ctx, contextCheckInterval), context.Canceled)
+}
@@ -0,0 +1,95 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/pmaojo/kthulu-go/backend/core"
+	"github.com/pmaojo/kthulu-go/backend/internal/domain"
+	"github.com/pmaojo/kthulu-go/backend/internal/domain/repository"
+)
+
+// blockingConn answers no query: each one runs until its context is done,
+// like a statement stuck behind a lock
+type blockingConn struct{}
+
+func (blockingConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, errors.New("prepare not supported")
+}
+func (blockingConn) Close() error              { return nil }
+func (blockingConn) Begin() (driver.Tx, error) { return nil, errors.New("transactions not supported") }
+func (blockingConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+type blockingConnector struct{}
+
+func (blockingConnector) Connect(context.Context) (driver.Conn, error) { return blockingConn{}, nil }
+func (blockingConnector) Driver() driver.Driver                        { return nil }
+
+func newBlockingProductRepository(t *testing.T, timeout time.Duration) *ProductRepository {
+	sqlDB := sql.OpenDB(blockingConnector{})
+	t.Cleanup(func() { sqlDB.Close() })
+	core.SetStatementTimeout(timeout)
+	t.Cleanup(func() { core.SetStatementTimeout(core.DefaultStatementTimeout) })
+	return NewProductRepository(sqlDB, core.NewLoggerFromZap(zap.NewNop()), nil, nil).(*ProductRepository)
+}
+
+func TestStatementTimeoutCancelsQueriesWithoutDeadline(t *testing.T) {
+	repo := newBlockingProductRepository(t, 30*time.Millisecond)
+
+	start := time.Now()
+	_, err := repo.GetByID(context.Background(), 1, 1)
+	require.Error(t, err)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), time.Second, "the query should stop at the default timeout")
+}
+
+func TestStatementTimeoutKeepsCallerDeadline(t *testing.T) {
+	repo := newBlockingProductRepository(t, 10*time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 150*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := repo.GetByID(ctx, 1, 1)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.GreaterOrEqual(t, time.Since(start), 100*time.Millisecond, "the caller's deadline should win over the default")
+}
+
+func TestStatementTimeoutBoundsOnlyTheStartOfStreams(t *testing.T) {
+	repo, _ := newTestProductRepository(t)
+	ctx := context.Background()
+	for _, sku := range []string{"SKU-1", "SKU-2", "SKU-3"} {
+		product, err := domain.NewProduct(1, sku, sku, "each")
+		require.NoError(t, err)
+		require.NoError(t, repo.Create(ctx, product))
+	}
+	core.SetStatementTimeout(20 * time.Millisecond)
+	t.Cleanup(func() { core.SetStatementTimeout(core.DefaultStatementTimeout) })
+
+	// A slow reader keeps the stream open well past the timeout
+	streamed := 0
+	require.NoError(t, repo.ListStream(ctx, 1, repository.DefaultProductFilters(), func(*domain.Product) error {
+		streamed++
+		time.Sleep(15 * time.Millisecond)
+		return nil
+	}))
+	assert.Equal(t, 3, streamed)
+
+	// A query that never starts is still cancelled
+	blocked := newBlockingProductRepository(t, 30*time.Millisecond)
+	start := time.Now()
+	err := blocked.ListStream(ctx, 1, repository.DefaultProductFilters(), func(*domain.Product) error { return nil })
+	require.Error(t, err)
+	assert.Less(t, time.Since(start), time.Second)
+}
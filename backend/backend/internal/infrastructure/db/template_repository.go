@@ -185,6 +185,8 @@ func (r *TemplateRepository) Count(ctx context.Context) (int64, error) {
 
 // FindPaginated returns paginated templates
 func (r *TemplateRepository) FindPaginated(ctx context.Context, params repository.PaginationParams) (repository.PaginationResult[*domain.Template], error) {
+	params = params.Clamped()
+
 	var models []TemplateModel
 	var total int64
 
@@ -222,6 +224,8 @@ func (r *TemplateRepository) FindPaginated(ctx context.Context, params repositor
 
 // SearchPaginated returns paginated templates matching search query
 func (r *TemplateRepository) SearchPaginated(ctx context.Context, query string, params repository.PaginationParams) (repository.PaginationResult[*domain.Template], error) {
+	params = params.Clamped()
+
 	var models []TemplateModel
 	var total int64
 
@@ -0,0 +1,148 @@
+// @kthulu:core
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/pmaojo/kthulu-go/backend/core"
+	"github.com/pmaojo/kthulu-go/backend/internal/domain"
+	"github.com/pmaojo/kthulu-go/backend/internal/domain/repository"
+)
+
+// sqlConn is what the SQL repositories need from either the pool or the
+// transaction of a unit of work
+type sqlConn interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// sqlTx is a transaction started by a repository method
+type sqlTx interface {
+	sqlConn
+	Commit() error
+	Rollback() error
+}
+
+// joinedTx is the unit of work transaction seen by a repository method that
+// would otherwise start its own. Only the unit of work ends it, so a failed
+// step is undone when the unit of work rolls back.
+type joinedTx struct {
+	*sql.Tx
+}
+
+func (joinedTx) Commit() error   { return nil }
+func (joinedTx) Rollback() error { return nil }
+
+// beginTx starts a transaction on db, or joins tx when the repository is
+// bound to a unit of work
+func beginTx(ctx context.Context, db *sql.DB, tx *sql.Tx) (sqlTx, error) {
+	if tx != nil {
+		return joinedTx{tx}, nil
+	}
+	return db.BeginTx(ctx, nil)
+}
+
+// gormOnTx returns a GORM session that runs its statements on tx, the way
+// gorm.DB.Begin does. GORM repositories keep working unchanged on it and
+// their own Transaction calls become savepoints inside tx.
+func gormOnTx(ctx context.Context, gormDB *gorm.DB, tx *sql.Tx) *gorm.DB {
+	session := gormDB.Session(&gorm.Session{Context: ctx, NewDB: true})
+	session.Statement.ConnPool = tx
+	return session
+}
+
+// UnitOfWork implements repository.UnitOfWork over the SQL and GORM
+// repositories. gormDB must wrap db, as core.NewGormDB does, so both kinds
+// share one transaction.
+type UnitOfWork struct {
+	db     *sql.DB
+	gormDB *gorm.DB
+	logger core.Logger
+	audit  repository.AuditLogger
+}
+
+// NewUnitOfWork creates a unit of work on db. The audit logger is optional;
+// the entries of the transaction-scoped repositories are written to it once
+// the unit of work commits.
+func NewUnitOfWork(db *sql.DB, gormDB *gorm.DB, logger core.Logger, audit repository.AuditLogger) repository.UnitOfWork {
+	return &UnitOfWork{
+		db:     db,
+		gormDB: gormDB,
+		logger: logger,
+		audit:  audit,
+	}
+}
+
+// Do begins a transaction and calls fn with repositories bound to it,
+// committing when fn succeeds and rolling back otherwise
+func (u *UnitOfWork) Do(ctx context.Context, fn func(repos repository.TxRepositories) error) error {
+	tx, err := u.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin unit of work: %w", err)
+	}
+	defer tx.Rollback()
+
+	var audit repository.AuditLogger
+	pending := &pendingAudit{}
+	if u.audit != nil {
+		audit = pending
+	}
+
+	gormTx := gormOnTx(ctx, u.gormDB, tx)
+	repos := repository.TxRepositories{
+		Invoices:      &InvoiceRepository{db: u.db, tx: tx, logger: u.logger, audit: audit},
+		Products:      &ProductRepository{db: u.db, tx: tx, logger: u.logger, audit: audit},
+		Contacts:      NewContactRepository(gormTx),
+		Inventory:     NewInventoryRepository(gormTx),
+		Outbox:        &OutboxRepository{db: u.db, tx: tx, logger: u.logger},
+		Organizations: NewOrganizationRepository(gormTx),
+
+		PaymentWebhookEvents: NewPaymentWebhookEventRepository(gormTx),
+	}
+	if err := fn(repos); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit unit of work: %w", err)
+	}
+	pending.flush(ctx, u.audit, u.logger)
+	return nil
+}
+
+// pendingAudit holds the audit entries of a unit of work until it commits,
+// so rolled back changes leave no entries and a failed audit write can't
+// abort the transaction
+type pendingAudit struct {
+	entries []*domain.AuditLogEntry
+}
+
+// Log keeps entry, resolving its actor and time now as AuditLogger.Log would
+func (p *pendingAudit) Log(ctx context.Context, entry *domain.AuditLogEntry) error {
+	if entry.ActorID == nil {
+		if actorID, ok := repository.ActorFromContext(ctx); ok {
+			entry.ActorID = &actorID
+		}
+	}
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = time.Now()
+	}
+	p.entries = append(p.entries, entry)
+	return nil
+}
+
+// flush writes the kept entries to audit. Failures are logged and swallowed
+// like those of recordAudit.
+func (p *pendingAudit) flush(ctx context.Context, audit repository.AuditLogger, logger core.Logger) {
+	for _, entry := range p.entries {
+		if err := audit.Log(ctx, entry); err != nil {
+			logger.Warn("Audit log entry dropped", "error", err, "entityType", entry.EntityType, "entityId", entry.EntityID)
+		}
+	}
+}
@@ -0,0 +1,271 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/pmaojo/kthulu-go/backend/core"
+	"github.com/pmaojo/kthulu-go/backend/internal/domain"
+	"github.com/pmaojo/kthulu-go/backend/internal/domain/money"
+	"github.com/pmaojo/kthulu-go/backend/internal/domain/repository"
+	"github.com/pmaojo/kthulu-go/backend/internal/infrastructure/testutils"
+)
+
+// payInvoice records a payment of amount against invoiceID through repos
+func payInvoice(ctx context.Context, repos repository.TxRepositories, invoiceID uint, amount float64) error {
+	invoice, err := repos.Invoices.GetByID(ctx, 1, invoiceID)
+	if err != nil {
+		return err
+	}
+	payment, err := domain.NewPayment(1, invoiceID, 1, domain.PaymentMethodCash, amount, "EUR", time.Now())
+	if err != nil {
+		return err
+	}
+	if err := invoice.ApplyPayment(amount, money.DefaultPolicy()); err != nil {
+		return err
+	}
+	if err := repos.Invoices.CreatePayment(ctx, payment); err != nil {
+		return err
+	}
+	return repos.Invoices.Update(ctx, invoice)
+}
+
+func countRows(t *testing.T, sqlDB *sql.DB, table string) int {
+	t.Helper()
+	var count int
+	require.NoError(t, sqlDB.QueryRow("SELECT COUNT(*) FROM "+table).Scan(&count))
+	return count
+}
+
+// newTestUnitOfWork shares one in-memory database between the SQL invoice
+// tables, the GORM inventory tables and the unit of work
+func newTestUnitOfWork(t *testing.T) (*InvoiceRepository, *gorm.DB, *sql.DB, repository.UnitOfWork) {
+	testDB := testutils.SetupTestDB(t)
+	t.Cleanup(func() { testutils.CleanupTestDB(t, testDB) })
+
+	sqlDB, err := testDB.DB()
+	require.NoError(t, err)
+	setupInvoiceTables(t, sqlDB)
+	setupInventoryTables(t, testDB)
+
+	logger := core.NewLoggerFromZap(zap.NewNop())
+	repo := NewInvoiceRepository(sqlDB, logger, nil, nil).(*InvoiceRepository)
+	return repo, testDB, sqlDB, NewUnitOfWork(sqlDB, testDB, logger, nil)
+}
+
+func TestUnitOfWorkCommitsEveryWrite(t *testing.T) {
+	repo, _, sqlDB, units := newTestUnitOfWork(t)
+	ctx := context.Background()
+	invoiceID := uint(createTestInvoice(t, sqlDB, 1, "sent", time.Now(), 100, 0))
+
+	err := units.Do(ctx, func(repos repository.TxRepositories) error {
+		return payInvoice(ctx, repos, invoiceID, 40)
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, countRows(t, sqlDB, "payments"))
+	invoice, err := repo.GetByID(ctx, 1, invoiceID)
+	require.NoError(t, err)
+	assert.Equal(t, 40.0, invoice.PaidAmount)
+	assert.Equal(t, 60.0, invoice.BalanceDue)
+	assert.Equal(t, domain.InvoiceStatusPartial, invoice.Status)
+}
+
+func TestUnitOfWorkRollsBackEveryWriteOnFailure(t *testing.T) {
+	repo, _, sqlDB, units := newTestUnitOfWork(t)
+	ctx := context.Background()
+	invoiceID := uint(createTestInvoice(t, sqlDB, 1, "sent", time.Now(), 100, 0))
+	failure := errors.New("posting failed")
+
+	err := units.Do(ctx, func(repos repository.TxRepositories) error {
+		if err := payInvoice(ctx, repos, invoiceID, 40); err != nil {
+			return err
+		}
+		// Methods that run their own transaction join the unit of work
+		item := &domain.InvoiceItem{InvoiceID: invoiceID, Description: "Fee", Quantity: 1, UnitPrice: 5, LineTotal: 5}
+		if err := repos.Invoices.BulkCreateItems(ctx, []*domain.InvoiceItem{item}); err != nil {
+			return err
+		}
+		return failure
+	})
+	require.ErrorIs(t, err, failure)
+
+	assert.Zero(t, countRows(t, sqlDB, "payments"))
+	assert.Zero(t, countRows(t, sqlDB, "invoice_items"))
+	invoice, err := repo.GetByID(ctx, 1, invoiceID)
+	require.NoError(t, err)
+	assert.Zero(t, invoice.PaidAmount)
+	assert.Equal(t, 100.0, invoice.BalanceDue)
+	assert.Equal(t, domain.InvoiceStatusSent, invoice.Status)
+}
+
+func TestUnitOfWorkSpansSQLAndGORMRepositories(t *testing.T) {
+	ctx := context.Background()
+	failure := errors.New("stock adjustment rejected")
+
+	// createAndShip creates an invoice through the SQL repository and takes
+	// the shipped units out of stock through the GORM one
+	createAndShip := func(units repository.UnitOfWork, item *domain.InventoryItem, number string, fail bool) error {
+		return units.Do(ctx, func(repos repository.TxRepositories) error {
+			invoice, err := domain.NewInvoice(1, 1, 1, number, domain.InvoiceTypeInvoice, "EUR", time.Now())
+			if err != nil {
+				return err
+			}
+			if err := repos.Invoices.Create(ctx, invoice); err != nil {
+				return err
+			}
+			item.Quantity -= 3
+			movement := &domain.InventoryMovement{Delta: -3, Reason: string(domain.StockMovementTypeSale), Reference: number}
+			if err := repos.Inventory.AdjustStockLevel(ctx, item, movement, 0); err != nil {
+				return err
+			}
+			if fail {
+				return failure
+			}
+			return nil
+		})
+	}
+	stock := func(t *testing.T, gormDB *gorm.DB, itemID uint) (quantity int, movements int64) {
+		require.NoError(t, gormDB.Raw(`SELECT quantity FROM inventory_items WHERE id = ?`, itemID).Scan(&quantity).Error)
+		require.NoError(t, gormDB.Table("inventory_movements").Count(&movements).Error)
+		return quantity, movements
+	}
+
+	t.Run("commit", func(t *testing.T) {
+		_, gormDB, sqlDB, units := newTestUnitOfWork(t)
+		item := createTestInventoryItem(t, gormDB, 1, 10)
+
+		require.NoError(t, createAndShip(units, item, "INV-1", false))
+
+		assert.Equal(t, 1, countRows(t, sqlDB, "invoices"))
+		quantity, movements := stock(t, gormDB, item.ID)
+		assert.Equal(t, 7, quantity)
+		assert.Equal(t, int64(1), movements)
+	})
+
+	t.Run("rollback", func(t *testing.T) {
+		_, gormDB, sqlDB, units := newTestUnitOfWork(t)
+		item := createTestInventoryItem(t, gormDB, 1, 10)
+
+		require.ErrorIs(t, createAndShip(units, item, "INV-1", true), failure)
+
+		assert.Zero(t, countRows(t, sqlDB, "invoices"))
+		quantity, movements := stock(t, gormDB, item.ID)
+		assert.Equal(t, 10, quantity)
+		assert.Zero(t, movements)
+	})
+}
+
+func TestUnitOfWorkReleasesWebhookClaimOnFailure(t *testing.T) {
+	_, testDB, sqlDB, units := newTestUnitOfWork(t)
+	ctx := context.Background()
+	require.NoError(t, testDB.Exec(`CREATE TABLE payment_webhook_events (
+		gateway TEXT NOT NULL,
+		event_id TEXT NOT NULL,
+		processed_at DATETIME NOT NULL,
+		PRIMARY KEY (gateway, event_id)
+	)`).Error)
+	invoiceID := uint(createTestInvoice(t, sqlDB, 1, "sent", time.Now(), 100, 0))
+
+	// A payment that fails to book leaves its event for the redelivery
+	err := units.Do(ctx, func(repos repository.TxRepositories) error {
+		claimed, err := repos.PaymentWebhookEvents.Claim(ctx, domain.PaymentMethodStripe, "evt_1")
+		require.NoError(t, err)
+		require.True(t, claimed)
+		return payInvoice(ctx, repos, invoiceID, 500)
+	})
+	require.ErrorIs(t, err, domain.ErrInsufficientPayment)
+	assert.Zero(t, countRows(t, sqlDB, "payment_webhook_events"))
+
+	err = units.Do(ctx, func(repos repository.TxRepositories) error {
+		claimed, err := repos.PaymentWebhookEvents.Claim(ctx, domain.PaymentMethodStripe, "evt_1")
+		require.NoError(t, err)
+		require.True(t, claimed)
+		return payInvoice(ctx, repos, invoiceID, 40)
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, countRows(t, sqlDB, "payment_webhook_events"))
+	assert.Equal(t, 1, countRows(t, sqlDB, "payments"))
+}
+
+func TestUnitOfWorkWritesAuditEntriesOnlyOnCommit(t *testing.T) {
+	testDB := testutils.SetupTestDB(t)
+	t.Cleanup(func() { testutils.CleanupTestDB(t, testDB) })
+	sqlDB, err := testDB.DB()
+	require.NoError(t, err)
+	setupProductTables(t, sqlDB)
+	logger := core.NewLoggerFromZap(zap.NewNop())
+	units := NewUnitOfWork(sqlDB, testDB, logger, NewAuditLogger(sqlDB, logger))
+	ctx := repository.ContextWithActor(context.Background(), 9)
+	failure := errors.New("import rejected")
+
+	createProduct := func(sku string, fail bool) error {
+		return units.Do(ctx, func(repos repository.TxRepositories) error {
+			product, err := domain.NewProduct(1, sku, "Widget", "each")
+			if err != nil {
+				return err
+			}
+			if err := repos.Products.Create(ctx, product); err != nil {
+				return err
+			}
+			if fail {
+				return failure
+			}
+			return nil
+		})
+	}
+
+	require.ErrorIs(t, createProduct("SKU-1", true), failure)
+	assert.Zero(t, countRows(t, sqlDB, "audit_logs"))
+
+	require.NoError(t, createProduct("SKU-2", false))
+	var actorID uint
+	require.NoError(t, sqlDB.QueryRow(`SELECT actor_id FROM audit_logs WHERE entity_type = 'product'`).Scan(&actorID))
+	assert.Equal(t, uint(9), actorID)
+}
+
+func TestUnitOfWorkBindsOrganizations(t *testing.T) {
+	_, _, sqlDB, units := newTestUnitOfWork(t)
+	ctx := context.Background()
+	failure := errors.New("setup failed")
+
+	// createOrganization opens an organization together with its first invoice
+	createOrganization := func(slug string, fail bool) error {
+		return units.Do(ctx, func(repos repository.TxRepositories) error {
+			org, err := domain.NewOrganization("Acme", slug, domain.OrganizationTypeCompany, 1)
+			if err != nil {
+				return err
+			}
+			if err := repos.Organizations.Create(ctx, org); err != nil {
+				return err
+			}
+			invoice, err := domain.NewInvoice(org.ID, 1, 1, "INV-1", domain.InvoiceTypeInvoice, "EUR", time.Now())
+			if err != nil {
+				return err
+			}
+			if err := repos.Invoices.Create(ctx, invoice); err != nil {
+				return err
+			}
+			if fail {
+				return failure
+			}
+			return nil
+		})
+	}
+
+	require.ErrorIs(t, createOrganization("acme", true), failure)
+	assert.Zero(t, countRows(t, sqlDB, "organizations"))
+	assert.Zero(t, countRows(t, sqlDB, "invoices"))
+
+	require.NoError(t, createOrganization("acme", false))
+	assert.Equal(t, 1, countRows(t, sqlDB, "organizations"))
+	assert.Equal(t, 1, countRows(t, sqlDB, "invoices"))
+}
@@ -14,14 +14,16 @@ import (
 
 // UserModel represents the database model for users
 type UserModel struct {
-	ID               uint   `gorm:"primaryKey"`
-	Email            string `gorm:"uniqueIndex;not null"`
-	PasswordHash     string `gorm:"not null"`
-	ConfirmedAt      *time.Time
-	ConfirmationCode string
-	RoleID           uint `gorm:"not null"`
-	CreatedAt        time.Time
-	UpdatedAt        time.Time
+	ID                    uint   `gorm:"primaryKey"`
+	Email                 string `gorm:"uniqueIndex;not null"`
+	PasswordHash          string `gorm:"not null"`
+	ConfirmedAt           *time.Time
+	ConfirmationCode      string
+	ConfirmationExpiresAt *time.Time
+	DisabledAt            *time.Time
+	RoleID                uint `gorm:"not null"`
+	CreatedAt             time.Time
+	UpdatedAt             time.Time
 
 	// Associations
 	Role *RoleModel `gorm:"foreignKey:RoleID"`
@@ -40,14 +42,16 @@ func (u *UserModel) ToDomain() (*domain.User, error) {
 	}
 
 	user := &domain.User{
-		ID:               u.ID,
-		Email:            email,
-		PasswordHash:     u.PasswordHash,
-		ConfirmedAt:      u.ConfirmedAt,
-		ConfirmationCode: u.ConfirmationCode,
-		RoleID:           u.RoleID,
-		CreatedAt:        u.CreatedAt,
-		UpdatedAt:        u.UpdatedAt,
+		ID:                    u.ID,
+		Email:                 email,
+		PasswordHash:          u.PasswordHash,
+		ConfirmedAt:           u.ConfirmedAt,
+		ConfirmationCode:      u.ConfirmationCode,
+		ConfirmationExpiresAt: u.ConfirmationExpiresAt,
+		DisabledAt:            u.DisabledAt,
+		RoleID:                u.RoleID,
+		CreatedAt:             u.CreatedAt,
+		UpdatedAt:             u.UpdatedAt,
 	}
 
 	if u.Role != nil {
@@ -68,6 +72,8 @@ func (u *UserModel) FromDomain(user *domain.User) {
 	u.PasswordHash = user.PasswordHash
 	u.ConfirmedAt = user.ConfirmedAt
 	u.ConfirmationCode = user.ConfirmationCode
+	u.ConfirmationExpiresAt = user.ConfirmationExpiresAt
+	u.DisabledAt = user.DisabledAt
 	u.RoleID = user.RoleID
 	u.CreatedAt = user.CreatedAt
 	u.UpdatedAt = user.UpdatedAt
@@ -234,6 +240,8 @@ func (r *UserRepository) ExistsByID(ctx context.Context, id uint) (bool, error)
 
 // FindPaginated returns paginated users
 func (r *UserRepository) FindPaginated(ctx context.Context, params repository.PaginationParams) (repository.PaginationResult[*domain.User], error) {
+	params = params.Clamped()
+
 	var models []UserModel
 	var total int64
 
@@ -276,6 +284,8 @@ func (r *UserRepository) FindPaginated(ctx context.Context, params repository.Pa
 
 // SearchPaginated returns paginated users matching search query
 func (r *UserRepository) SearchPaginated(ctx context.Context, query string, params repository.PaginationParams) (repository.PaginationResult[*domain.User], error) {
+	params = params.Clamped()
+
 	var models []UserModel
 	var total int64
 
@@ -5,7 +5,9 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"time"
 
+	"github.com/pmaojo/kthulu-go/backend/core"
 	"github.com/pmaojo/kthulu-go/backend/internal/adapters/http/modules/verifactu"
 )
 
@@ -21,6 +23,9 @@ func NewVerifactuRepository(db *sql.DB) verifactu.Repository {
 
 // GetRecordByID retrieves a record by its ID.
 func (r *VerifactuRepository) GetRecordByID(ctx context.Context, id int) (*verifactu.Record, error) {
+	ctx, cancel := core.WithStatementTimeout(ctx)
+	defer cancel()
+
 	const query = `SELECT id, invoice_id, organization_id, record_type, original_record_id, sif_code, hash, created_at FROM verifactu_records WHERE id = $1`
 	rec := &verifactu.Record{}
 	err := r.db.QueryRowContext(ctx, query, id).Scan(&rec.ID, &rec.InvoiceID, &rec.OrganizationID, &rec.RecordType, &rec.OriginalRecordID, &rec.SIFCode, &rec.Hash, &rec.CreatedAt)
@@ -35,12 +40,18 @@ func (r *VerifactuRepository) GetRecordByID(ctx context.Context, id int) (*verif
 
 // CreateRecord inserts a new VeriFactu record.
 func (r *VerifactuRepository) CreateRecord(ctx context.Context, record *verifactu.Record) error {
+	ctx, cancel := core.WithStatementTimeout(ctx)
+	defer cancel()
+
 	const query = `INSERT INTO verifactu_records (invoice_id, organization_id, record_type, original_record_id, sif_code, hash, created_at) VALUES ($1,$2,$3,$4,$5,$6,$7) RETURNING id`
 	return r.db.QueryRowContext(ctx, query, record.InvoiceID, record.OrganizationID, record.RecordType, record.OriginalRecordID, record.SIFCode, record.Hash, record.CreatedAt).Scan(&record.ID)
 }
 
 // ListRecordsByOrganization returns all records for the given organization.
 func (r *VerifactuRepository) ListRecordsByOrganization(ctx context.Context, orgID int) ([]*verifactu.Record, error) {
+	ctx, cancel := core.WithStatementTimeout(ctx)
+	defer cancel()
+
 	const query = `SELECT id, invoice_id, organization_id, record_type, original_record_id, sif_code, hash, created_at FROM verifactu_records WHERE organization_id = $1 ORDER BY id`
 	rows, err := r.db.QueryContext(ctx, query, orgID)
 	if err != nil {
@@ -61,6 +72,9 @@ func (r *VerifactuRepository) ListRecordsByOrganization(ctx context.Context, org
 
 // GetLastHash returns the hash of the most recent record for the organization.
 func (r *VerifactuRepository) GetLastHash(ctx context.Context, orgID int) (string, error) {
+	ctx, cancel := core.WithStatementTimeout(ctx)
+	defer cancel()
+
 	// Select the most recent hash for the given organization. Ordering by
 	// created_at allows using the composite index on (organization_id,
 	// created_at) for efficient lookups.
@@ -78,6 +92,9 @@ func (r *VerifactuRepository) GetLastHash(ctx context.Context, orgID int) (strin
 
 // GetLiveMode returns whether live mode is active for the given fiscal year.
 func (r *VerifactuRepository) GetLiveMode(ctx context.Context, year int) (bool, error) {
+	ctx, cancel := core.WithStatementTimeout(ctx)
+	defer cancel()
+
 	const query = `SELECT live_mode FROM verifactu_settings WHERE fiscal_year = $1`
 	var live sql.NullBool
 	err := r.db.QueryRowContext(ctx, query, year).Scan(&live)
@@ -90,12 +107,85 @@ func (r *VerifactuRepository) GetLiveMode(ctx context.Context, year int) (bool,
 	return live.Bool, nil
 }
 
-// SetLiveMode persists the live mode flag for the given fiscal year.
-func (r *VerifactuRepository) SetLiveMode(ctx context.Context, year int, live bool) error {
-	const query = `INSERT INTO verifactu_settings (fiscal_year, live_mode) VALUES ($1, $2)
-ON CONFLICT (fiscal_year) DO UPDATE SET live_mode = EXCLUDED.live_mode`
-	if _, err := r.db.ExecContext(ctx, query, year, live); err != nil {
+// SetLiveMode persists the live mode flag for the given fiscal year. Calls that
+// would not change the stored flag are no-ops; actual transitions are logged
+// in verifactu_mode_log together with what triggered them.
+func (r *VerifactuRepository) SetLiveMode(ctx context.Context, year int, live bool, triggeredBy string) error {
+	ctx, cancel := core.WithStatementTimeout(ctx)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin verifactu live mode transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// The write is guarded on the stored flag, so of concurrent calls making
+	// the same transition only one changes a row and logs it. A missing row
+	// reads as test mode, so only going live needs to insert one.
+	query, args := `UPDATE verifactu_settings SET live_mode = $1 WHERE fiscal_year = $2 AND live_mode <> $1`, []interface{}{live, year}
+	if live {
+		query = `INSERT INTO verifactu_settings (fiscal_year, live_mode) VALUES ($1, $2)
+ON CONFLICT (fiscal_year) DO UPDATE SET live_mode = EXCLUDED.live_mode WHERE verifactu_settings.live_mode <> EXCLUDED.live_mode`
+		args = []interface{}{year, live}
+	}
+	result, err := tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("set verifactu live mode: %w", err)
+	}
+	changed, err := result.RowsAffected()
+	if err != nil {
 		return fmt.Errorf("set verifactu live mode: %w", err)
 	}
+	if changed == 0 {
+		return nil
+	}
+
+	const logQuery = `INSERT INTO verifactu_mode_log (fiscal_year, live_mode, triggered_by, changed_at) VALUES ($1, $2, $3, $4)`
+	if _, err := tx.ExecContext(ctx, logQuery, year, live, triggeredBy, time.Now().UTC()); err != nil {
+		return fmt.Errorf("log verifactu live mode transition: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit verifactu live mode: %w", err)
+	}
+	return nil
+}
+
+// GetLiveModeStatus returns the live mode flag for the fiscal year along with
+// the most recent logged transition.
+func (r *VerifactuRepository) GetLiveModeStatus(ctx context.Context, year int) (*verifactu.LiveModeStatus, error) {
+	ctx, cancel := core.WithStatementTimeout(ctx)
+	defer cancel()
+
+	live, err := r.GetLiveMode(ctx, year)
+	if err != nil {
+		return nil, err
+	}
+	status := &verifactu.LiveModeStatus{Year: year, Live: live}
+
+	const query = `SELECT triggered_by, changed_at FROM verifactu_mode_log WHERE fiscal_year = $1 ORDER BY changed_at DESC, id DESC LIMIT 1`
+	var changedAt time.Time
+	err = r.db.QueryRowContext(ctx, query, year).Scan(&status.TriggeredBy, &changedAt)
+	if err == sql.ErrNoRows {
+		return status, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get verifactu live mode status: %w", err)
+	}
+	status.ChangedAt = &changedAt
+	return status, nil
+}
+
+// SaveExportChainHash stores the last chain hash produced by an export for the organization.
+func (r *VerifactuRepository) SaveExportChainHash(ctx context.Context, orgID int, hash string) error {
+	ctx, cancel := core.WithStatementTimeout(ctx)
+	defer cancel()
+
+	const query = `INSERT INTO verifactu_export_chain (organization_id, last_hash, updated_at) VALUES ($1, $2, $3)
+ON CONFLICT (organization_id) DO UPDATE SET last_hash = EXCLUDED.last_hash, updated_at = EXCLUDED.updated_at`
+	if _, err := r.db.ExecContext(ctx, query, orgID, hash, time.Now().UTC()); err != nil {
+		return fmt.Errorf("save verifactu export chain hash: %w", err)
+	}
 	return nil
 }
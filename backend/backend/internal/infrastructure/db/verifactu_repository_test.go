@@ -0,0 +1,95 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pmaojo/kthulu-go/backend/internal/infrastructure/testutils"
+)
+
+func newTestVerifactuRepository(t *testing.T) *VerifactuRepository {
+	testDB := testutils.SetupTestDB(t)
+	t.Cleanup(func() { testutils.CleanupTestDB(t, testDB) })
+
+	sqlDB, err := testDB.DB()
+	require.NoError(t, err)
+
+	_, err = sqlDB.Exec(`
+		CREATE TABLE verifactu_settings (
+			fiscal_year INT PRIMARY KEY,
+			live_mode BOOLEAN NOT NULL DEFAULT FALSE
+		);
+
+		CREATE TABLE verifactu_mode_log (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			fiscal_year INT NOT NULL,
+			live_mode BOOLEAN NOT NULL,
+			triggered_by TEXT NOT NULL,
+			changed_at DATETIME NOT NULL
+		);
+	`)
+	require.NoError(t, err)
+
+	return NewVerifactuRepository(sqlDB).(*VerifactuRepository)
+}
+
+func TestVerifactuRepositorySetLiveMode_Idempotent(t *testing.T) {
+	repo := newTestVerifactuRepository(t)
+	ctx := context.Background()
+
+	require.NoError(t, repo.SetLiveMode(ctx, 2025, true, "startup:real-time"))
+	first, err := repo.GetLiveModeStatus(ctx, 2025)
+	require.NoError(t, err)
+	require.NotNil(t, first.ChangedAt)
+
+	require.NoError(t, repo.SetLiveMode(ctx, 2025, true, "startup:second"))
+
+	var transitions int
+	require.NoError(t, repo.db.QueryRow(`SELECT COUNT(*) FROM verifactu_mode_log WHERE fiscal_year = 2025`).Scan(&transitions))
+	assert.Equal(t, 1, transitions)
+
+	status, err := repo.GetLiveModeStatus(ctx, 2025)
+	require.NoError(t, err)
+	assert.True(t, status.Live)
+	assert.Equal(t, "startup:real-time", status.TriggeredBy)
+	assert.True(t, status.ChangedAt.Equal(*first.ChangedAt))
+}
+
+func TestVerifactuRepositorySetLiveMode_LogsOnlyTransitions(t *testing.T) {
+	repo := newTestVerifactuRepository(t)
+	ctx := context.Background()
+
+	require.NoError(t, repo.SetLiveMode(ctx, 2025, true, "startup:real-time"))
+	require.NoError(t, repo.SetLiveMode(ctx, 2025, false, "manual:off"))
+	require.NoError(t, repo.SetLiveMode(ctx, 2025, false, "manual:again"))
+
+	var triggers []string
+	rows, err := repo.db.Query(`SELECT triggered_by FROM verifactu_mode_log WHERE fiscal_year = 2025 ORDER BY id`)
+	require.NoError(t, err)
+	for rows.Next() {
+		var trigger string
+		require.NoError(t, rows.Scan(&trigger))
+		triggers = append(triggers, trigger)
+	}
+	require.NoError(t, rows.Close())
+	assert.Equal(t, []string{"startup:real-time", "manual:off"}, triggers)
+
+	live, err := repo.GetLiveMode(ctx, 2025)
+	require.NoError(t, err)
+	assert.False(t, live)
+}
+
+func TestVerifactuRepositoryGetLiveModeStatus_NoTransitions(t *testing.T) {
+	repo := newTestVerifactuRepository(t)
+
+	require.NoError(t, repo.SetLiveMode(context.Background(), 2024, false, "manual"))
+
+	status, err := repo.GetLiveModeStatus(context.Background(), 2024)
+	require.NoError(t, err)
+	assert.False(t, status.Live)
+	assert.Nil(t, status.ChangedAt)
+	assert.Empty(t, status.TriggeredBy)
+}
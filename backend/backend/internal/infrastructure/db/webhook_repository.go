@@ -0,0 +1,186 @@
+// @kthulu:module:webhooks
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pmaojo/kthulu-go/backend/core"
+	"github.com/pmaojo/kthulu-go/backend/internal/domain"
+	"github.com/pmaojo/kthulu-go/backend/internal/domain/repository"
+)
+
+// WebhookRepository implements the webhook repository interface using SQL
+type WebhookRepository struct {
+	db     *sql.DB
+	logger core.Logger
+}
+
+// NewWebhookRepository creates a new webhook repository instance
+func NewWebhookRepository(db *sql.DB, logger core.Logger) repository.WebhookRepository {
+	return &WebhookRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// CreateEndpoint registers a webhook endpoint for an organization
+func (r *WebhookRepository) CreateEndpoint(ctx context.Context, endpoint *domain.WebhookEndpoint) error {
+	ctx, cancel := core.WithStatementTimeout(ctx)
+	defer cancel()
+
+	query := `
+		INSERT INTO webhook_endpoints (
+			organization_id, url, secret, events, active, created_at, updated_at
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7
+		) RETURNING id`
+
+	err := r.db.QueryRowContext(ctx, query,
+		endpoint.OrganizationID, endpoint.URL, endpoint.Secret,
+		joinWebhookEvents(endpoint.Events), endpoint.Active,
+		endpoint.CreatedAt, endpoint.UpdatedAt,
+	).Scan(&endpoint.ID)
+
+	if err != nil {
+		r.logger.Error("Failed to create webhook endpoint", "error", err, "organizationId", endpoint.OrganizationID)
+		return fmt.Errorf("failed to create webhook endpoint: %w", err)
+	}
+	return nil
+}
+
+// ListEndpoints returns the active endpoints of an organization subscribed to the event
+func (r *WebhookRepository) ListEndpoints(ctx context.Context, organizationID uint, event domain.WebhookEvent) ([]*domain.WebhookEndpoint, error) {
+	ctx, cancel := core.WithStatementTimeout(ctx)
+	defer cancel()
+
+	query := `
+		SELECT id, organization_id, url, secret, events, active, created_at, updated_at
+		FROM webhook_endpoints
+		WHERE organization_id = $1 AND active = $2
+		ORDER BY id`
+
+	rows, err := r.db.QueryContext(ctx, query, organizationID, true)
+	if err != nil {
+		r.logger.Error("Failed to list webhook endpoints", "error", err, "organizationId", organizationID)
+		return nil, fmt.Errorf("failed to list webhook endpoints: %w", err)
+	}
+	defer rows.Close()
+
+	var endpoints []*domain.WebhookEndpoint
+	for rows.Next() {
+		endpoint := &domain.WebhookEndpoint{}
+		var events string
+		if err := rows.Scan(
+			&endpoint.ID, &endpoint.OrganizationID, &endpoint.URL, &endpoint.Secret,
+			&events, &endpoint.Active, &endpoint.CreatedAt, &endpoint.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan webhook endpoint: %w", err)
+		}
+		endpoint.Events = splitWebhookEvents(events)
+		if endpoint.Subscribes(event) {
+			endpoints = append(endpoints, endpoint)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate webhook endpoints: %w", err)
+	}
+	return endpoints, nil
+}
+
+// RecordDelivery persists a delivery attempt
+func (r *WebhookRepository) RecordDelivery(ctx context.Context, delivery *domain.WebhookDelivery) error {
+	ctx, cancel := core.WithStatementTimeout(ctx)
+	defer cancel()
+
+	if delivery.CreatedAt.IsZero() {
+		delivery.CreatedAt = time.Now()
+	}
+
+	var statusCode interface{}
+	if delivery.StatusCode != 0 {
+		statusCode = delivery.StatusCode
+	}
+	var deliveryErr interface{}
+	if delivery.Error != "" {
+		deliveryErr = delivery.Error
+	}
+
+	query := `
+		INSERT INTO webhook_deliveries (
+			endpoint_id, organization_id, event, payload, attempt, status_code, error, delivered, created_at
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8, $9
+		) RETURNING id`
+
+	err := r.db.QueryRowContext(ctx, query,
+		delivery.EndpointID, delivery.OrganizationID, delivery.Event, delivery.Payload,
+		delivery.Attempt, statusCode, deliveryErr, delivery.Delivered, delivery.CreatedAt,
+	).Scan(&delivery.ID)
+
+	if err != nil {
+		r.logger.Error("Failed to record webhook delivery", "error", err, "endpointId", delivery.EndpointID)
+		return fmt.Errorf("failed to record webhook delivery: %w", err)
+	}
+	return nil
+}
+
+// ListDeliveries returns the delivery attempts of an endpoint, oldest first
+func (r *WebhookRepository) ListDeliveries(ctx context.Context, endpointID uint) ([]*domain.WebhookDelivery, error) {
+	ctx, cancel := core.WithStatementTimeout(ctx)
+	defer cancel()
+
+	query := `
+		SELECT id, endpoint_id, organization_id, event, payload, attempt, status_code, error, delivered, created_at
+		FROM webhook_deliveries
+		WHERE endpoint_id = $1
+		ORDER BY id`
+
+	rows, err := r.db.QueryContext(ctx, query, endpointID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	var deliveries []*domain.WebhookDelivery
+	for rows.Next() {
+		delivery := &domain.WebhookDelivery{}
+		var statusCode sql.NullInt64
+		var deliveryErr sql.NullString
+		if err := rows.Scan(
+			&delivery.ID, &delivery.EndpointID, &delivery.OrganizationID, &delivery.Event,
+			&delivery.Payload, &delivery.Attempt, &statusCode, &deliveryErr,
+			&delivery.Delivered, &delivery.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan webhook delivery: %w", err)
+		}
+		delivery.StatusCode = int(statusCode.Int64)
+		delivery.Error = deliveryErr.String
+		deliveries = append(deliveries, delivery)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate webhook deliveries: %w", err)
+	}
+	return deliveries, nil
+}
+
+func joinWebhookEvents(events []domain.WebhookEvent) string {
+	names := make([]string, len(events))
+	for i, event := range events {
+		names[i] = string(event)
+	}
+	return strings.Join(names, ",")
+}
+
+func splitWebhookEvents(value string) []domain.WebhookEvent {
+	var events []domain.WebhookEvent
+	for _, name := range strings.Split(value, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			events = append(events, domain.WebhookEvent(name))
+		}
+	}
+	return events
+}
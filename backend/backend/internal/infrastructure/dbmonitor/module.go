@@ -0,0 +1,35 @@
+// @kthulu:core
+package dbmonitor
+
+import (
+	"context"
+
+	"go.uber.org/fx"
+
+	"github.com/pmaojo/kthulu-go/backend/core"
+)
+
+// Module runs the database connection monitor for the lifetime of the application.
+var Module = fx.Options(
+	fx.Provide(NewMonitor),
+	fx.Invoke(registerMonitor),
+)
+
+// registerMonitor starts the monitor with the application and stops it on shutdown
+func registerMonitor(lc fx.Lifecycle, monitor *Monitor, logger core.Logger) {
+	lc.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			if monitor.interval <= 0 {
+				logger.Info("Database connection monitor disabled")
+				return nil
+			}
+			monitor.Start()
+			logger.Info("Database connection monitor started", "interval", monitor.interval.String())
+			return nil
+		},
+		OnStop: func(context.Context) error {
+			monitor.Stop()
+			return nil
+		},
+	})
+}
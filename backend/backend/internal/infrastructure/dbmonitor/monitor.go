@@ -0,0 +1,124 @@
+// @kthulu:core
+package dbmonitor
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/metric"
+
+	"github.com/pmaojo/kthulu-go/backend/core"
+)
+
+// Monitor periodically probes the database connection, logging when it is
+// lost and restored and exposing its state as the db_connection_up gauge.
+// The pool reconnects on its own; the monitor makes outages visible.
+type Monitor struct {
+	probe    func() error
+	logger   core.Logger
+	interval time.Duration
+
+	connected atomic.Bool
+	since     time.Time
+	gaugeOnce sync.Once
+
+	stop chan struct{}
+	done sync.WaitGroup
+}
+
+// NewMonitor creates a monitor probing db with the health check query at the
+// configured interval
+func NewMonitor(db *sql.DB, cfg *core.Config, logger core.Logger) *Monitor {
+	m := &Monitor{
+		probe: func() error {
+			return core.HealthProbe(db, cfg.Database.HealthCheckQuery, cfg.Database.HealthCheckTimeout)
+		},
+		logger:   logger,
+		interval: cfg.Database.MonitorInterval,
+		since:    time.Now(),
+	}
+	// Startup fails without a connection, so the monitor starts connected
+	m.connected.Store(true)
+	return m
+}
+
+// Connected reports the state seen by the last probe
+func (m *Monitor) Connected() bool {
+	return m.connected.Load()
+}
+
+// CheckOnce probes the database, logs state changes and returns whether it
+// is reachable
+func (m *Monitor) CheckOnce() bool {
+	err := m.probe()
+	connected := err == nil
+	if m.connected.Swap(connected) == connected {
+		return connected
+	}
+
+	downtime := time.Since(m.since)
+	m.since = time.Now()
+	if connected {
+		m.logger.Info("Database connection restored", "downtime", downtime.String())
+	} else {
+		m.logger.Error("Database connection lost", "error", err)
+	}
+	return connected
+}
+
+// Start probes in the background until Stop is called. It does nothing when
+// the interval is not positive.
+func (m *Monitor) Start() {
+	if m.interval <= 0 {
+		return
+	}
+	m.gaugeOnce.Do(m.registerGauge)
+	m.stop = make(chan struct{})
+	m.done.Add(1)
+	go func() {
+		defer m.done.Done()
+		ticker := time.NewTicker(m.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-m.stop:
+				return
+			case <-ticker.C:
+				m.CheckOnce()
+			}
+		}
+	}()
+}
+
+// Stop ends probing and waits for the current probe to finish
+func (m *Monitor) Stop() {
+	if m.stop == nil {
+		return
+	}
+	close(m.stop)
+	m.done.Wait()
+	m.stop = nil
+}
+
+// registerGauge reports the connection state on the global meter provider
+func (m *Monitor) registerGauge() {
+	_, err := otel.GetMeterProvider().Meter("kthulu-db").Int64ObservableGauge(
+		"db_connection_up",
+		metric.WithDescription("Whether the database answered the last connection probe"),
+		metric.WithInt64Callback(func(_ context.Context, o metric.Int64Observer) error {
+			if m.Connected() {
+				o.Observe(1)
+			} else {
+				o.Observe(0)
+			}
+			return nil
+		}),
+	)
+	if err != nil {
+		m.logger.Warn("Failed to register database connection gauge", "error", err)
+	}
+}
@@ -0,0 +1,51 @@
+package dbmonitor
+
+import (
+	"errors"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/pmaojo/kthulu-go/backend/core"
+)
+
+func TestMonitorCheckOnceLogsStateChanges(t *testing.T) {
+	zapCore, logs := observer.New(zap.InfoLevel)
+	monitor := NewMonitor(nil, &core.Config{}, core.NewLoggerFromZap(zap.New(zapCore)))
+
+	probeErr := errors.New("connection refused")
+	for _, step := range []struct {
+		err       error
+		connected bool
+		logged    string
+	}{
+		{nil, true, ""},
+		{probeErr, false, "Database connection lost"},
+		{probeErr, false, ""},
+		{nil, true, "Database connection restored"},
+	} {
+		monitor.probe = func() error { return step.err }
+		logs.TakeAll()
+
+		if got := monitor.CheckOnce(); got != step.connected || monitor.Connected() != step.connected {
+			t.Fatalf("probe error %v: expected connected %v, got %v", step.err, step.connected, got)
+		}
+		entries := logs.TakeAll()
+		if step.logged == "" && len(entries) != 0 {
+			t.Fatalf("expected no log without a state change, got %v", entries)
+		}
+		if step.logged != "" && (len(entries) != 1 || entries[0].Message != step.logged) {
+			t.Fatalf("expected %q to be logged, got %v", step.logged, entries)
+		}
+	}
+}
+
+func TestMonitorStartIsNoopWhenDisabled(t *testing.T) {
+	monitor := NewMonitor(nil, &core.Config{}, core.NewLoggerFromZap(zap.NewNop()))
+	monitor.Start()
+	if monitor.stop != nil {
+		t.Fatalf("expected a zero interval to leave the monitor stopped")
+	}
+	monitor.Stop()
+}
@@ -0,0 +1,179 @@
+// @kthulu:module:calendar
+package eventreminder
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pmaojo/kthulu-go/backend/core"
+	"github.com/pmaojo/kthulu-go/backend/internal/domain"
+	"github.com/pmaojo/kthulu-go/backend/internal/domain/repository"
+)
+
+// reminderTimeLayout is how event start times are shown in reminder emails
+const reminderTimeLayout = "Mon, 02 Jan 2006 15:04 MST"
+
+// Dispatcher periodically emails the attendees of events whose reminders
+// are due. Each reminder is claimed before it is sent so it fires once even
+// with several instances running.
+type Dispatcher struct {
+	calendar repository.CalendarRepository
+	notifier repository.NotificationProvider
+	logger   core.Logger
+	interval time.Duration
+	now      func() time.Time
+
+	stop chan struct{}
+	done sync.WaitGroup
+}
+
+// NewDispatcher creates a dispatcher running at the configured interval
+func NewDispatcher(calendar repository.CalendarRepository, notifier repository.NotificationProvider, cfg *core.Config, logger core.Logger) *Dispatcher {
+	return &Dispatcher{
+		calendar: calendar,
+		notifier: notifier,
+		logger:   logger,
+		interval: cfg.Calendar.ReminderInterval,
+		now:      time.Now,
+	}
+}
+
+// SendDue sends the reminders due by now and returns how many were sent.
+// Reminders of events that have already ended are dropped. A reminder that
+// could not be sent to anyone is released to be retried on the next run.
+func (d *Dispatcher) SendDue(ctx context.Context) (int, error) {
+	now := d.now()
+	reminders, err := d.calendar.GetPendingReminders(ctx, now)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	var failures []error
+	for i := range reminders {
+		reminder := &reminders[i]
+		claimed, err := d.calendar.ClaimReminder(ctx, reminder.ID, now)
+		if err != nil {
+			return sent, err
+		}
+		if !claimed {
+			continue
+		}
+		if !reminder.Event.EndTime.After(now) {
+			d.logger.Warn("Dropping reminder of a past event", "reminderId", reminder.ID, "eventId", reminder.EventID)
+			continue
+		}
+
+		delivered, err := d.send(ctx, &reminder.Event)
+		if err != nil {
+			failures = append(failures, fmt.Errorf("reminder %d: %w", reminder.ID, err))
+			if delivered == 0 {
+				if err := d.calendar.ReleaseReminder(ctx, reminder.ID); err != nil {
+					failures = append(failures, fmt.Errorf("release reminder %d: %w", reminder.ID, err))
+				}
+				continue
+			}
+		}
+		if delivered > 0 {
+			sent++
+		}
+	}
+
+	if sent > 0 {
+		d.logger.Info("Event reminders sent", "count", sent)
+	}
+	return sent, errors.Join(failures...)
+}
+
+// send emails the event reminder to every attendee who has not declined and
+// returns how many emails went out
+func (d *Dispatcher) send(ctx context.Context, event *domain.Event) (int, error) {
+	delivered := 0
+	var failures []error
+	for _, email := range reminderRecipients(event) {
+		if err := d.notifier.SendNotification(ctx, reminderNotification(email, event)); err != nil {
+			failures = append(failures, err)
+			continue
+		}
+		delivered++
+	}
+	return delivered, errors.Join(failures...)
+}
+
+// reminderRecipients returns the distinct emails of the attendees who have
+// not declined the event
+func reminderRecipients(event *domain.Event) []string {
+	seen := make(map[string]bool)
+	var recipients []string
+	for _, attendee := range event.Attendees {
+		email := strings.ToLower(strings.TrimSpace(attendee.Email))
+		if email == "" || attendee.Status == domain.AttendeeStatusDeclined || seen[email] {
+			continue
+		}
+		seen[email] = true
+		recipients = append(recipients, email)
+	}
+	return recipients
+}
+
+func reminderNotification(email string, event *domain.Event) repository.NotificationRequest {
+	startsAt := event.StartTime.Format(reminderTimeLayout)
+	text := fmt.Sprintf("%s starts on %s.", event.Title, startsAt)
+	body := fmt.Sprintf("<p><strong>%s</strong> starts on %s.</p>", html.EscapeString(event.Title), html.EscapeString(startsAt))
+	if event.Location != "" {
+		text += fmt.Sprintf("\nLocation: %s", event.Location)
+		body += fmt.Sprintf("<p>Location: %s</p>", html.EscapeString(event.Location))
+	}
+
+	return repository.NotificationRequest{
+		To:       email,
+		Subject:  fmt.Sprintf("Reminder: %s", event.Title),
+		Body:     body,
+		TextBody: text,
+		Type:     repository.NotificationTypeEventReminder,
+		Data: map[string]interface{}{
+			"eventId":   event.ID,
+			"startTime": event.StartTime,
+		},
+	}
+}
+
+// Start sends reminders in the background until Stop is called. It does
+// nothing when the interval is not positive.
+func (d *Dispatcher) Start() {
+	if d.interval <= 0 {
+		return
+	}
+	d.stop = make(chan struct{})
+	d.done.Add(1)
+	go func() {
+		defer d.done.Done()
+		ticker := time.NewTicker(d.interval)
+		defer ticker.Stop()
+		for {
+			if _, err := d.SendDue(context.Background()); err != nil {
+				d.logger.Error("Sending event reminders failed", "error", err)
+			}
+			select {
+			case <-d.stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop ends sending and waits for the current run to finish
+func (d *Dispatcher) Stop() {
+	if d.stop == nil {
+		return
+	}
+	close(d.stop)
+	d.done.Wait()
+	d.stop = nil
+}
@@ -0,0 +1,191 @@
+package eventreminder
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/pmaojo/kthulu-go/backend/core"
+	"github.com/pmaojo/kthulu-go/backend/internal/domain"
+	"github.com/pmaojo/kthulu-go/backend/internal/domain/repository"
+)
+
+// fakeNotifier records the notifications it is asked to send
+type fakeNotifier struct {
+	repository.NotificationProvider
+	mu   sync.Mutex
+	sent []repository.NotificationRequest
+	fail error
+}
+
+func (n *fakeNotifier) SendNotification(_ context.Context, req repository.NotificationRequest) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.fail != nil {
+		return n.fail
+	}
+	n.sent = append(n.sent, req)
+	return nil
+}
+
+// fakeCalendar keeps reminders in memory, claiming them like the database
+// repository does
+type fakeCalendar struct {
+	repository.CalendarRepository
+	mu        sync.Mutex
+	reminders []domain.Reminder
+}
+
+func (c *fakeCalendar) GetPendingReminders(_ context.Context, before time.Time) ([]domain.Reminder, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var pending []domain.Reminder
+	for _, reminder := range c.reminders {
+		if !reminder.IsSent && reminder.Type == domain.ReminderTypeEmail && !reminder.RemindAt.After(before) {
+			pending = append(pending, reminder)
+		}
+	}
+	return pending, nil
+}
+
+func (c *fakeCalendar) ClaimReminder(_ context.Context, id uint, sentAt time.Time) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i := range c.reminders {
+		if c.reminders[i].ID == id && !c.reminders[i].IsSent {
+			c.reminders[i].IsSent = true
+			c.reminders[i].SentAt = &sentAt
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (c *fakeCalendar) ReleaseReminder(_ context.Context, id uint) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i := range c.reminders {
+		if c.reminders[i].ID == id {
+			c.reminders[i].IsSent = false
+			c.reminders[i].SentAt = nil
+		}
+	}
+	return nil
+}
+
+// newTestDispatcher returns a dispatcher over an event at 09:00 carrying a
+// 15 minute email reminder, with a clock set through the returned pointer
+func newTestDispatcher(t *testing.T) (*Dispatcher, *fakeNotifier, *time.Time, *fakeCalendar) {
+	t.Helper()
+	start := time.Date(2024, 6, 3, 9, 0, 0, 0, time.UTC)
+	event := domain.Event{
+		ID:        4,
+		Title:     "Planning",
+		Location:  "Room 1",
+		StartTime: start,
+		EndTime:   start.Add(time.Hour),
+		Attendees: []domain.Attendee{
+			{Email: "jane@example.com", Status: domain.AttendeeStatusAccepted},
+			{Email: "John@Example.com", Status: domain.AttendeeStatusPending},
+			{Email: "jane@example.com", Status: domain.AttendeeStatusAccepted},
+			{Email: "max@example.com", Status: domain.AttendeeStatusDeclined},
+		},
+	}
+	calendar := &fakeCalendar{reminders: []domain.Reminder{
+		{ID: 1, EventID: event.ID, Event: event, Type: domain.ReminderTypeEmail, MinutesBefore: 15, RemindAt: event.ReminderTime(15)},
+	}}
+
+	notifier := &fakeNotifier{}
+	now := start.Add(-time.Hour)
+	dispatcher := NewDispatcher(calendar, notifier, &core.Config{Calendar: core.CalendarConfig{ReminderInterval: time.Minute}}, core.NewLoggerFromZap(zap.NewNop()))
+	dispatcher.now = func() time.Time { return now }
+	return dispatcher, notifier, &now, calendar
+}
+
+func TestDispatcherSendsReminderOnce(t *testing.T) {
+	dispatcher, notifier, now, _ := newTestDispatcher(t)
+	ctx := context.Background()
+
+	for _, step := range []struct {
+		at   string
+		sent int
+	}{
+		{"08:44", 0},
+		{"08:45", 1},
+		{"08:46", 0},
+		{"08:59", 0},
+	} {
+		clock, _ := time.Parse("15:04", step.at)
+		*now = time.Date(2024, 6, 3, clock.Hour(), clock.Minute(), 0, 0, time.UTC)
+		sent, err := dispatcher.SendDue(ctx)
+		if err != nil {
+			t.Fatalf("%s: send: %v", step.at, err)
+		}
+		if sent != step.sent {
+			t.Fatalf("%s: expected %d reminders sent, got %d", step.at, step.sent, sent)
+		}
+	}
+
+	if len(notifier.sent) != 2 {
+		t.Fatalf("expected one email per attendee who has not declined, got %+v", notifier.sent)
+	}
+	for i, to := range []string{"jane@example.com", "john@example.com"} {
+		req := notifier.sent[i]
+		if req.To != to || req.Subject != "Reminder: Planning" || req.Type != repository.NotificationTypeEventReminder {
+			t.Fatalf("unexpected notification %+v", req)
+		}
+		if !strings.Contains(req.TextBody, "Mon, 03 Jun 2024 09:00 UTC") || !strings.Contains(req.TextBody, "Room 1") {
+			t.Fatalf("unexpected text body %q", req.TextBody)
+		}
+	}
+}
+
+func TestDispatcherRetriesFailedReminder(t *testing.T) {
+	dispatcher, notifier, now, repo := newTestDispatcher(t)
+	ctx := context.Background()
+	*now = time.Date(2024, 6, 3, 8, 50, 0, 0, time.UTC)
+
+	notifier.fail = errors.New("smtp down")
+	if _, err := dispatcher.SendDue(ctx); err == nil {
+		t.Fatalf("expected the notifier error to be returned")
+	}
+	pending, err := repo.GetPendingReminders(ctx, *now)
+	if err != nil || len(pending) != 1 {
+		t.Fatalf("expected the reminder to be released for a retry, got %v, %v", pending, err)
+	}
+
+	notifier.fail = nil
+	if sent, err := dispatcher.SendDue(ctx); err != nil || sent != 1 {
+		t.Fatalf("expected the retry to send the reminder, got %d, %v", sent, err)
+	}
+}
+
+func TestDispatcherDropsRemindersOfPastEvents(t *testing.T) {
+	dispatcher, notifier, now, repo := newTestDispatcher(t)
+	ctx := context.Background()
+	*now = time.Date(2024, 6, 3, 12, 0, 0, 0, time.UTC)
+
+	if sent, err := dispatcher.SendDue(ctx); err != nil || sent != 0 {
+		t.Fatalf("expected nothing sent after the event, got %d, %v", sent, err)
+	}
+	if len(notifier.sent) != 0 {
+		t.Fatalf("unexpected notifications %+v", notifier.sent)
+	}
+	if pending, _ := repo.GetPendingReminders(ctx, *now); len(pending) != 0 {
+		t.Fatalf("expected the stale reminder to be marked sent, got %+v", pending)
+	}
+}
+
+func TestDispatcherStartIsNoopWhenDisabled(t *testing.T) {
+	dispatcher := NewDispatcher(nil, nil, &core.Config{}, core.NewLoggerFromZap(zap.NewNop()))
+	dispatcher.Start()
+	if dispatcher.stop != nil {
+		t.Fatalf("expected a zero interval to leave the dispatcher stopped")
+	}
+	dispatcher.Stop()
+}
@@ -0,0 +1,35 @@
+// @kthulu:module:calendar
+package eventreminder
+
+import (
+	"context"
+
+	"go.uber.org/fx"
+
+	"github.com/pmaojo/kthulu-go/backend/core"
+)
+
+// Module runs the event reminder dispatcher for the lifetime of the application.
+var Module = fx.Options(
+	fx.Provide(NewDispatcher),
+	fx.Invoke(registerDispatcher),
+)
+
+// registerDispatcher starts the dispatcher with the application and stops it on shutdown
+func registerDispatcher(lc fx.Lifecycle, dispatcher *Dispatcher, logger core.Logger) {
+	lc.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			if dispatcher.interval <= 0 {
+				logger.Info("Event reminders disabled")
+				return nil
+			}
+			dispatcher.Start()
+			logger.Info("Event reminders started", "interval", dispatcher.interval.String())
+			return nil
+		},
+		OnStop: func(context.Context) error {
+			dispatcher.Stop()
+			return nil
+		},
+	})
+}
@@ -0,0 +1,58 @@
+// @kthulu:module:invoices
+package exchangerate
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pmaojo/kthulu-go/backend/internal/domain/repository"
+)
+
+// cacheKey identifies a daily rate
+type cacheKey struct {
+	currency string
+	day      string
+}
+
+// CachedProvider remembers the rates of another provider per currency and
+// day. Published daily rates don't change, so entries never expire.
+type CachedProvider struct {
+	next repository.ExchangeRateProvider
+
+	mu    sync.RWMutex
+	rates map[cacheKey]float64
+}
+
+// NewCachedProvider wraps next with a daily rate cache
+func NewCachedProvider(next repository.ExchangeRateProvider) *CachedProvider {
+	return &CachedProvider{next: next, rates: make(map[cacheKey]float64)}
+}
+
+// BaseCurrency returns the base currency of the wrapped provider
+func (c *CachedProvider) BaseCurrency() string {
+	return c.next.BaseCurrency()
+}
+
+// Rate returns the cached rate of currency on day, fetching it on a miss.
+// Failed lookups aren't cached.
+func (c *CachedProvider) Rate(ctx context.Context, currency string, day time.Time) (float64, error) {
+	key := cacheKey{currency: strings.ToUpper(currency), day: day.UTC().Format(dayLayout)}
+
+	c.mu.RLock()
+	rate, ok := c.rates[key]
+	c.mu.RUnlock()
+	if ok {
+		return rate, nil
+	}
+
+	rate, err := c.next.Rate(ctx, currency, day)
+	if err != nil {
+		return 0, err
+	}
+	c.mu.Lock()
+	c.rates[key] = rate
+	c.mu.Unlock()
+	return rate, nil
+}
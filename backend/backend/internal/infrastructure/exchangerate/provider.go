@@ -0,0 +1,83 @@
+// @kthulu:module:invoices
+package exchangerate
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pmaojo/kthulu-go/backend/internal/domain"
+)
+
+const (
+	defaultRequestTimeout = 10 * time.Second
+	dayLayout             = "2006-01-02"
+)
+
+// ratesResponse is the document returned by the rates API
+type ratesResponse struct {
+	Rates map[string]float64 `json:"rates"`
+}
+
+// HTTPProvider fetches daily rates from a Frankfurter-compatible API, which
+// answers GET <url>/<YYYY-MM-DD>?from=USD&to=EUR with
+// {"rates": {"EUR": 0.92}}.
+type HTTPProvider struct {
+	baseURL string
+	base    string
+	client  *http.Client
+}
+
+// NewHTTPProvider creates a provider quoting rates in base currency
+func NewHTTPProvider(baseURL, base string) *HTTPProvider {
+	return &HTTPProvider{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		base:    strings.ToUpper(base),
+		client:  &http.Client{Timeout: defaultRequestTimeout},
+	}
+}
+
+// BaseCurrency returns the currency rates are quoted in
+func (p *HTTPProvider) BaseCurrency() string {
+	return p.base
+}
+
+// Rate fetches what one unit of currency was worth in the base currency on
+// day
+func (p *HTTPProvider) Rate(ctx context.Context, currency string, day time.Time) (float64, error) {
+	currency = strings.ToUpper(currency)
+	if currency == p.base {
+		return 1, nil
+	}
+
+	query := url.Values{"from": {currency}, "to": {p.base}}
+	endpoint := fmt.Sprintf("%s/%s?%s", p.baseURL, day.UTC().Format(dayLayout), query.Encode())
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to build rates request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", domain.ErrExchangeRateUnavailable, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("%w: rates API returned status %d", domain.ErrExchangeRateUnavailable, resp.StatusCode)
+	}
+
+	var body ratesResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return 0, fmt.Errorf("%w: invalid rates response: %v", domain.ErrExchangeRateUnavailable, err)
+	}
+	rate, ok := body.Rates[p.base]
+	if !ok || rate <= 0 {
+		return 0, fmt.Errorf("%w: no %s rate for %s", domain.ErrExchangeRateUnavailable, p.base, currency)
+	}
+	return rate, nil
+}
@@ -0,0 +1,47 @@
+package exchangerate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pmaojo/kthulu-go/backend/internal/domain"
+)
+
+func TestCachedProvider_DoesNotCacheFailures(t *testing.T) {
+	var requests atomic.Int32
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) == 1 {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprint(w, `{"rates": {"EUR": 0.85}}`)
+	}))
+	defer api.Close()
+
+	rates := NewCachedProvider(NewHTTPProvider(api.URL+"/", "eur"))
+	day := time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC)
+
+	if _, err := rates.Rate(context.Background(), "GBP", day); !errors.Is(err, domain.ErrExchangeRateUnavailable) {
+		t.Fatalf("expected ErrExchangeRateUnavailable, got %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		rate, err := rates.Rate(context.Background(), "GBP", day)
+		if err != nil || rate != 0.85 {
+			t.Fatalf("expected 0.85, got %v, %v", rate, err)
+		}
+	}
+	if requests.Load() != 2 {
+		t.Fatalf("expected the failed lookup to be retried once, got %d requests", requests.Load())
+	}
+
+	// Base currency amounts need no lookup
+	if rate, err := rates.Rate(context.Background(), "EUR", day); err != nil || rate != 1 || requests.Load() != 2 {
+		t.Fatalf("expected 1 without a request, got %v, %v", rate, err)
+	}
+}
@@ -0,0 +1,46 @@
+// @kthulu:module:invoices
+package exchangerate
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pmaojo/kthulu-go/backend/internal/domain"
+)
+
+// StaticProvider serves fixed rates regardless of the day, for tests and
+// deployments that maintain their own rates
+type StaticProvider struct {
+	base  string
+	rates map[string]float64
+}
+
+// NewStaticProvider creates a provider quoting rates, keyed by currency code,
+// in base currency
+func NewStaticProvider(base string, rates map[string]float64) *StaticProvider {
+	normalized := make(map[string]float64, len(rates))
+	for currency, rate := range rates {
+		normalized[strings.ToUpper(currency)] = rate
+	}
+	return &StaticProvider{base: strings.ToUpper(base), rates: normalized}
+}
+
+// BaseCurrency returns the currency rates are quoted in
+func (p *StaticProvider) BaseCurrency() string {
+	return p.base
+}
+
+// Rate returns the fixed rate of currency
+func (p *StaticProvider) Rate(ctx context.Context, currency string, day time.Time) (float64, error) {
+	currency = strings.ToUpper(currency)
+	if currency == p.base {
+		return 1, nil
+	}
+	rate, ok := p.rates[currency]
+	if !ok {
+		return 0, fmt.Errorf("%w: no %s rate for %s", domain.ErrExchangeRateUnavailable, p.base, currency)
+	}
+	return rate, nil
+}
@@ -11,13 +11,15 @@ import (
 
 // ConsoleProvider implements NotificationProvider by logging to console
 type ConsoleProvider struct {
-	logger core.Logger
+	templates *EmailTemplateRenderer
+	logger    core.Logger
 }
 
 // NewConsoleProvider creates a new console notification provider
-func NewConsoleProvider(logger core.Logger) repository.NotificationProvider {
+func NewConsoleProvider(templates *EmailTemplateRenderer, logger core.Logger) repository.NotificationProvider {
 	return &ConsoleProvider{
-		logger: logger,
+		templates: templates,
+		logger:    logger,
 	}
 }
 
@@ -40,6 +42,9 @@ func (c *ConsoleProvider) SendNotification(ctx context.Context, req repository.N
 	if len(req.Data) > 0 {
 		fmt.Printf("Data: %+v\n", req.Data)
 	}
+	for _, attachment := range req.Attachments {
+		fmt.Printf("Attachment: %s (%s, %d bytes)\n", attachment.Filename, attachment.ContentType, len(attachment.Content))
+	}
 	fmt.Printf("==================\n\n")
 
 	return nil
@@ -47,15 +52,12 @@ func (c *ConsoleProvider) SendNotification(ctx context.Context, req repository.N
 
 // SendEmailConfirmation sends an email confirmation notification
 func (c *ConsoleProvider) SendEmailConfirmation(ctx context.Context, email, confirmationCode string) error {
-	req := repository.NotificationRequest{
-		To:      email,
-		Subject: "Confirm Your Email Address",
-		Body:    fmt.Sprintf("Please confirm your email address by using this code: %s", confirmationCode),
-		Type:    repository.NotificationTypeEmailConfirmation,
-		Data: map[string]interface{}{
-			"confirmationCode": confirmationCode,
-		},
+	req, err := renderEmailConfirmation(ctx, c.templates, email, confirmationCode)
+	if err != nil {
+		return err
 	}
+	// The console shows the readable version of the email
+	req.Body = req.TextBody
 
 	return c.SendNotification(ctx, req)
 }
@@ -90,5 +92,21 @@ func (c *ConsoleProvider) SendWelcomeEmail(ctx context.Context, email, name stri
 	return c.SendNotification(ctx, req)
 }
 
+// SendInvoiceEmail sends an invoice notification with the rendered invoice attached
+func (c *ConsoleProvider) SendInvoiceEmail(ctx context.Context, email, invoiceNumber string, attachment repository.NotificationAttachment) error {
+	req := repository.NotificationRequest{
+		To:      email,
+		Subject: fmt.Sprintf("Invoice %s", invoiceNumber),
+		Body:    fmt.Sprintf("Please find attached invoice %s.", invoiceNumber),
+		Type:    repository.NotificationTypeInvoice,
+		Data: map[string]interface{}{
+			"invoiceNumber": invoiceNumber,
+		},
+		Attachments: []repository.NotificationAttachment{attachment},
+	}
+
+	return c.SendNotification(ctx, req)
+}
+
 // Ensure ConsoleProvider implements NotificationProvider
 var _ repository.NotificationProvider = (*ConsoleProvider)(nil)
@@ -0,0 +1,218 @@
+// @kthulu:module:notifier
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	htmltemplate "html/template"
+	"sync"
+	texttemplate "text/template"
+	"time"
+
+	"github.com/pmaojo/kthulu-go/backend/internal/domain"
+	"github.com/pmaojo/kthulu-go/backend/internal/domain/repository"
+)
+
+// EmailTemplateData is the data available to email templates
+type EmailTemplateData struct {
+	Email            string
+	Code             string
+	OrganizationName string
+	ExpiresAt        *time.Time
+}
+
+// RenderedEmail is an email template rendered for one recipient
+type RenderedEmail struct {
+	Subject string
+	HTML    string
+	Text    string
+}
+
+type parsedEmailTemplate struct {
+	subject *texttemplate.Template
+	html    *htmltemplate.Template
+	text    *texttemplate.Template
+}
+
+// EmailTemplateRenderer renders notification emails from built-in templates,
+// which organizations may replace with their own.
+type EmailTemplateRenderer struct {
+	defaults map[repository.NotificationType]*parsedEmailTemplate
+	store    repository.EmailTemplateRepository
+}
+
+// NewEmailTemplateRenderer creates a renderer with the built-in templates.
+// Organization templates are kept in store, or in memory when store is nil.
+func NewEmailTemplateRenderer(store repository.EmailTemplateRepository) *EmailTemplateRenderer {
+	if store == nil {
+		store = newMemoryEmailTemplateStore()
+	}
+	r := &EmailTemplateRenderer{
+		defaults: make(map[repository.NotificationType]*parsedEmailTemplate),
+		store:    store,
+	}
+	for notificationType, tmpl := range defaultEmailTemplates {
+		parsed, err := parseEmailTemplate(string(notificationType), tmpl)
+		if err != nil {
+			panic(fmt.Sprintf("invalid built-in email template %s: %v", notificationType, err))
+		}
+		r.defaults[notificationType] = parsed
+	}
+	return r
+}
+
+// SetOrganizationTemplate replaces the template used for notificationType
+// emails sent on behalf of an organization. Only notification types with a
+// built-in template can be customized.
+func (r *EmailTemplateRenderer) SetOrganizationTemplate(ctx context.Context, organizationID uint, notificationType repository.NotificationType, tmpl repository.EmailTemplate) error {
+	if _, ok := r.defaults[notificationType]; !ok {
+		return fmt.Errorf("%w: no %s emails to customize", domain.ErrInvalidEmailTemplate, notificationType)
+	}
+	if _, err := parseEmailTemplate(string(notificationType), tmpl); err != nil {
+		return fmt.Errorf("%w: %v", domain.ErrInvalidEmailTemplate, err)
+	}
+	return r.store.Save(ctx, organizationID, notificationType, tmpl)
+}
+
+// Render renders the organization's template for notificationType, falling
+// back to the built-in template
+func (r *EmailTemplateRenderer) Render(ctx context.Context, organizationID uint, notificationType repository.NotificationType, data EmailTemplateData) (*RenderedEmail, error) {
+	tmpl, ok := r.defaults[notificationType]
+	if !ok {
+		return nil, fmt.Errorf("no email template for %s", notificationType)
+	}
+	if organizationID != 0 {
+		custom, err := r.store.FindByOrganization(ctx, organizationID, notificationType)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load organization email template: %w", err)
+		}
+		if custom != nil {
+			if tmpl, err = parseEmailTemplate(string(notificationType), *custom); err != nil {
+				return nil, fmt.Errorf("invalid organization email template: %w", err)
+			}
+		}
+	}
+
+	var subject, html, text bytes.Buffer
+	if err := tmpl.subject.Execute(&subject, data); err != nil {
+		return nil, fmt.Errorf("failed to render email subject: %w", err)
+	}
+	if err := tmpl.html.Execute(&html, data); err != nil {
+		return nil, fmt.Errorf("failed to render HTML email: %w", err)
+	}
+	if err := tmpl.text.Execute(&text, data); err != nil {
+		return nil, fmt.Errorf("failed to render text email: %w", err)
+	}
+	return &RenderedEmail{Subject: subject.String(), HTML: html.String(), Text: text.String()}, nil
+}
+
+// renderEmailConfirmation builds the confirmation request through the
+// templates of the organization found in ctx
+func renderEmailConfirmation(ctx context.Context, templates *EmailTemplateRenderer, email, confirmationCode string) (repository.NotificationRequest, error) {
+	scope, _ := repository.NotificationScopeFromContext(ctx)
+	rendered, err := templates.Render(ctx, scope.OrganizationID, repository.NotificationTypeEmailConfirmation, EmailTemplateData{
+		Email:            email,
+		Code:             confirmationCode,
+		OrganizationName: scope.OrganizationName,
+		ExpiresAt:        scope.CodeExpiresAt,
+	})
+	if err != nil {
+		return repository.NotificationRequest{}, err
+	}
+
+	return repository.NotificationRequest{
+		To:       email,
+		Subject:  rendered.Subject,
+		Body:     rendered.HTML,
+		TextBody: rendered.Text,
+		Type:     repository.NotificationTypeEmailConfirmation,
+		Data: map[string]interface{}{
+			"confirmationCode": confirmationCode,
+		},
+	}, nil
+}
+
+// memoryEmailTemplateStore keeps organization templates for renderers
+// created without a database
+type memoryEmailTemplateStore struct {
+	mu        sync.RWMutex
+	templates map[uint]map[repository.NotificationType]repository.EmailTemplate
+}
+
+func newMemoryEmailTemplateStore() *memoryEmailTemplateStore {
+	return &memoryEmailTemplateStore{templates: make(map[uint]map[repository.NotificationType]repository.EmailTemplate)}
+}
+
+func (s *memoryEmailTemplateStore) FindByOrganization(_ context.Context, organizationID uint, notificationType repository.NotificationType) (*repository.EmailTemplate, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	tmpl, ok := s.templates[organizationID][notificationType]
+	if !ok {
+		return nil, nil
+	}
+	return &tmpl, nil
+}
+
+func (s *memoryEmailTemplateStore) Save(_ context.Context, organizationID uint, notificationType repository.NotificationType, tmpl repository.EmailTemplate) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.templates[organizationID] == nil {
+		s.templates[organizationID] = make(map[repository.NotificationType]repository.EmailTemplate)
+	}
+	s.templates[organizationID][notificationType] = tmpl
+	return nil
+}
+
+func parseEmailTemplate(name string, tmpl repository.EmailTemplate) (*parsedEmailTemplate, error) {
+	subject, err := texttemplate.New(name + ".subject").Parse(tmpl.Subject)
+	if err != nil {
+		return nil, err
+	}
+	html, err := htmltemplate.New(name + ".html").Parse(tmpl.HTML)
+	if err != nil {
+		return nil, err
+	}
+	text, err := texttemplate.New(name + ".txt").Parse(tmpl.Text)
+	if err != nil {
+		return nil, err
+	}
+	return &parsedEmailTemplate{subject: subject, html: html, text: text}, nil
+}
+
+var defaultEmailTemplates = map[repository.NotificationType]repository.EmailTemplate{
+	repository.NotificationTypeEmailConfirmation: {
+		Subject: `{{if .OrganizationName}}{{.OrganizationName}}: {{end}}Confirm Your Email Address`,
+		HTML: `
+<!DOCTYPE html>
+<html>
+<head>
+    <meta charset="UTF-8">
+    <title>Confirm Your Email</title>
+</head>
+<body style="font-family: Arial, sans-serif; line-height: 1.6; color: #333;">
+    <div style="max-width: 600px; margin: 0 auto; padding: 20px;">
+        <h2 style="color: #2c3e50;">Confirm Your Email Address</h2>
+        <p>Thank you for registering{{if .OrganizationName}} with {{.OrganizationName}}{{end}}! Please confirm your email address by using the confirmation code below:</p>
+        <div style="background-color: #f8f9fa; padding: 15px; border-radius: 5px; text-align: center; margin: 20px 0;">
+            <h3 style="color: #007bff; font-family: monospace; letter-spacing: 2px;">{{.Code}}</h3>
+        </div>
+        {{- if .ExpiresAt}}
+        <p>This code expires on {{.ExpiresAt.Format "2006-01-02 15:04 MST"}}.</p>
+        {{- end}}
+        <p>If you didn't create an account, you can safely ignore this email.</p>
+        <hr style="border: none; border-top: 1px solid #eee; margin: 30px 0;">
+        <p style="font-size: 12px; color: #666;">This is an automated message, please do not reply.</p>
+    </div>
+</body>
+</html>`,
+		Text: `Thank you for registering{{if .OrganizationName}} with {{.OrganizationName}}{{end}}!
+
+Please confirm your email address by using this code: {{.Code}}
+{{if .ExpiresAt}}
+This code expires on {{.ExpiresAt.Format "2006-01-02 15:04 MST"}}.
+{{end}}
+If you didn't create an account, you can safely ignore this email.
+`,
+	},
+}
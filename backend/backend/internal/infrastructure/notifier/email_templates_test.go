@@ -0,0 +1,121 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pmaojo/kthulu-go/backend/internal/domain"
+	"github.com/pmaojo/kthulu-go/backend/internal/domain/repository"
+)
+
+func TestEmailTemplateRendererRendersConfirmation(t *testing.T) {
+	expiresAt := time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC)
+	rendered, err := NewEmailTemplateRenderer(nil).Render(context.Background(), 0, repository.NotificationTypeEmailConfirmation, EmailTemplateData{
+		Email:            "jane@example.com",
+		Code:             "XK42-9QZ",
+		OrganizationName: "Acme <Labs>",
+		ExpiresAt:        &expiresAt,
+	})
+	if err != nil {
+		t.Fatalf("render: %v", err)
+	}
+
+	if rendered.Subject != "Acme <Labs>: Confirm Your Email Address" {
+		t.Fatalf("unexpected subject %q", rendered.Subject)
+	}
+	for name, body := range map[string]string{"html": rendered.HTML, "text": rendered.Text} {
+		if !strings.Contains(body, "XK42-9QZ") {
+			t.Fatalf("%s body is missing the confirmation code:\n%s", name, body)
+		}
+		if !strings.Contains(body, "2024-05-01 12:30 UTC") {
+			t.Fatalf("%s body is missing the expiry:\n%s", name, body)
+		}
+	}
+	if !strings.Contains(rendered.HTML, "with Acme &lt;Labs&gt;") {
+		t.Fatalf("expected escaped organization name in HTML:\n%s", rendered.HTML)
+	}
+	if !strings.Contains(rendered.Text, "with Acme <Labs>") {
+		t.Fatalf("expected organization name in text:\n%s", rendered.Text)
+	}
+}
+
+func TestEmailTemplateRendererUsesOrganizationTemplate(t *testing.T) {
+	renderer := NewEmailTemplateRenderer(nil)
+	err := renderer.SetOrganizationTemplate(context.Background(), 7, repository.NotificationTypeEmailConfirmation, repository.EmailTemplate{
+		Subject: "Your {{.OrganizationName}} code",
+		HTML:    "<p>Code: <b>{{.Code}}</b></p>",
+		Text:    "Code: {{.Code}}",
+	})
+	if err != nil {
+		t.Fatalf("set template: %v", err)
+	}
+
+	data := EmailTemplateData{Code: "123456", OrganizationName: "Acme"}
+	custom, err := renderer.Render(context.Background(), 7, repository.NotificationTypeEmailConfirmation, data)
+	if err != nil {
+		t.Fatalf("render custom: %v", err)
+	}
+	if custom.Subject != "Your Acme code" || custom.HTML != "<p>Code: <b>123456</b></p>" || custom.Text != "Code: 123456" {
+		t.Fatalf("unexpected custom email %+v", custom)
+	}
+
+	// Other organizations keep the built-in template
+	builtin, err := renderer.Render(context.Background(), 8, repository.NotificationTypeEmailConfirmation, data)
+	if err != nil {
+		t.Fatalf("render builtin: %v", err)
+	}
+	if !strings.Contains(builtin.Text, "Please confirm your email address by using this code: 123456") {
+		t.Fatalf("expected built-in template for other organizations:\n%s", builtin.Text)
+	}
+}
+
+func TestEmailTemplateRendererRejectsInvalidTemplate(t *testing.T) {
+	renderer := NewEmailTemplateRenderer(nil)
+
+	err := renderer.SetOrganizationTemplate(context.Background(), 1, repository.NotificationTypeEmailConfirmation, repository.EmailTemplate{Subject: "{{.Code", HTML: "", Text: ""})
+	if !errors.Is(err, domain.ErrInvalidEmailTemplate) {
+		t.Fatalf("expected invalid template error for broken template, got %v", err)
+	}
+	err = renderer.SetOrganizationTemplate(context.Background(), 1, repository.NotificationTypeWelcome, repository.EmailTemplate{Subject: "Hi"})
+	if !errors.Is(err, domain.ErrInvalidEmailTemplate) {
+		t.Fatalf("expected invalid template error for notification type without template, got %v", err)
+	}
+	if _, err := renderer.Render(context.Background(), 1, repository.NotificationTypeWelcome, EmailTemplateData{}); err == nil {
+		t.Fatalf("expected error for notification type without template")
+	}
+
+	// The broken template was not stored
+	rendered, err := renderer.Render(context.Background(), 1, repository.NotificationTypeEmailConfirmation, EmailTemplateData{Code: "123456"})
+	if err != nil {
+		t.Fatalf("render builtin: %v", err)
+	}
+	if !strings.Contains(rendered.Text, "123456") {
+		t.Fatalf("expected built-in template after rejected update:\n%s", rendered.Text)
+	}
+}
+
+func TestSendEmailConfirmationUsesScopeFromContext(t *testing.T) {
+	renderer := NewEmailTemplateRenderer(nil)
+	if err := renderer.SetOrganizationTemplate(context.Background(), 3, repository.NotificationTypeEmailConfirmation, repository.EmailTemplate{
+		Subject: "{{.OrganizationName}} confirmation",
+		HTML:    "<p>{{.Code}}</p>",
+		Text:    "{{.Code}} for {{.Email}}",
+	}); err != nil {
+		t.Fatalf("set template: %v", err)
+	}
+
+	ctx := repository.ContextWithNotificationScope(context.Background(), repository.NotificationScope{OrganizationID: 3, OrganizationName: "Acme"})
+	req, err := renderEmailConfirmation(ctx, renderer, "jane@example.com", "ABC123")
+	if err != nil {
+		t.Fatalf("render confirmation: %v", err)
+	}
+	if req.Subject != "Acme confirmation" || req.Body != "<p>ABC123</p>" || req.TextBody != "ABC123 for jane@example.com" {
+		t.Fatalf("unexpected confirmation request %+v", req)
+	}
+	if req.Type != repository.NotificationTypeEmailConfirmation || req.Data["confirmationCode"] != "ABC123" {
+		t.Fatalf("unexpected confirmation metadata %+v", req)
+	}
+}
@@ -2,8 +2,6 @@
 package notifier
 
 import (
-	"os"
-
 	"go.uber.org/fx"
 
 	"github.com/pmaojo/kthulu-go/backend/core"
@@ -12,32 +10,26 @@ import (
 
 // NotifierModule provides notification services for Fx.
 var NotifierModule = fx.Options(
-	fx.Provide(NewNotificationProvider),
+	fx.Provide(
+		// Organization templates are only kept in memory without a store
+		fx.Annotate(
+			NewEmailTemplateRenderer,
+			fx.ParamTags(`optional:"true"`),
+		),
+		func(templates *EmailTemplateRenderer) repository.OrganizationEmailTemplates { return templates },
+		NewNotificationProvider,
+	),
 )
 
 // NewNotificationProvider creates the appropriate notification provider based on configuration
-func NewNotificationProvider(logger core.Logger) repository.NotificationProvider {
-	// Check if SMTP is configured
-	smtpHost := os.Getenv("SMTP_HOST")
-	smtpPort := os.Getenv("SMTP_PORT")
-	smtpUsername := os.Getenv("SMTP_USERNAME")
-	smtpPassword := os.Getenv("SMTP_PASSWORD")
-	smtpFrom := os.Getenv("SMTP_FROM")
-
-	// If SMTP is fully configured, use SMTP provider
-	if smtpHost != "" && smtpPort != "" && smtpUsername != "" && smtpPassword != "" && smtpFrom != "" {
-		logger.Info("Using SMTP notification provider")
-		config := SMTPConfig{
-			Host:     smtpHost,
-			Port:     smtpPort,
-			Username: smtpUsername,
-			Password: smtpPassword,
-			From:     smtpFrom,
-		}
-		return NewSMTPProvider(config, logger)
+func NewNotificationProvider(cfg *core.Config, templates *EmailTemplateRenderer, logger core.Logger) repository.NotificationProvider {
+	// Use SMTP when it is enabled in the configuration
+	if cfg.SMTP.Enabled {
+		logger.Info("Using SMTP notification provider", "host", cfg.SMTP.Host, "port", cfg.SMTP.Port, "tls", cfg.SMTP.TLS)
+		return NewSMTPProvider(cfg.SMTP, templates, logger)
 	}
 
 	// Default to console provider for development
 	logger.Info("Using console notification provider")
-	return NewConsoleProvider(logger)
+	return NewConsoleProvider(templates, logger)
 }
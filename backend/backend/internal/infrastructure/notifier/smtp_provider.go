@@ -3,33 +3,42 @@ package notifier
 
 import (
 	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"errors"
 	"fmt"
+	"net"
 	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/pmaojo/kthulu-go/backend/core"
 	"github.com/pmaojo/kthulu-go/backend/internal/domain/repository"
 )
 
-// SMTPConfig holds SMTP configuration
-type SMTPConfig struct {
-	Host     string
-	Port     string
-	Username string
-	Password string
-	From     string
-}
+// smtpTimeout bounds a whole SMTP exchange when the context has no deadline
+const smtpTimeout = 30 * time.Second
+
+// ErrStartTLSUnsupported is returned when STARTTLS is required but the server
+// does not offer it
+var ErrStartTLSUnsupported = errors.New("smtp server does not support STARTTLS")
 
 // SMTPProvider implements NotificationProvider using SMTP
 type SMTPProvider struct {
-	config SMTPConfig
-	logger core.Logger
+	config    core.SMTPConfig
+	tlsConfig *tls.Config
+	templates *EmailTemplateRenderer
+	logger    core.Logger
 }
 
 // NewSMTPProvider creates a new SMTP notification provider
-func NewSMTPProvider(config SMTPConfig, logger core.Logger) repository.NotificationProvider {
+func NewSMTPProvider(config core.SMTPConfig, templates *EmailTemplateRenderer, logger core.Logger) repository.NotificationProvider {
 	return &SMTPProvider{
-		config: config,
-		logger: logger,
+		config:    config,
+		tlsConfig: &tls.Config{ServerName: config.Host, MinVersion: tls.VersionTLS12},
+		templates: templates,
+		logger:    logger,
 	}
 }
 
@@ -41,16 +50,11 @@ func (s *SMTPProvider) SendNotification(ctx context.Context, req repository.Noti
 		"subject", req.Subject,
 	)
 
-	// Create SMTP auth
-	auth := smtp.PlainAuth("", s.config.Username, s.config.Password, s.config.Host)
-
 	// Compose email message
-	msg := s.composeMessage(s.config.From, req.To, req.Subject, req.Body)
+	msg := s.composeMessage(s.config.From, req.To, req.Subject, req.Body, req.TextBody, req.Attachments)
 
 	// Send email
-	addr := fmt.Sprintf("%s:%s", s.config.Host, s.config.Port)
-	err := smtp.SendMail(addr, auth, s.config.From, []string{req.To}, []byte(msg))
-	if err != nil {
+	if err := s.send(ctx, req.To, []byte(msg)); err != nil {
 		s.logger.Error("Failed to send SMTP notification",
 			"to", req.To,
 			"subject", req.Subject,
@@ -67,16 +71,79 @@ func (s *SMTPProvider) SendNotification(ctx context.Context, req repository.Noti
 	return nil
 }
 
+// send delivers msg to a single recipient, securing the connection and
+// authenticating as configured
+func (s *SMTPProvider) send(ctx context.Context, to string, msg []byte) error {
+	addr := net.JoinHostPort(s.config.Host, strconv.Itoa(s.config.Port))
+	dialer := &net.Dialer{Timeout: smtpTimeout}
+
+	var conn net.Conn
+	var err error
+	if s.config.TLS == core.SMTPTLSImplicit {
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: s.tlsConfig}).DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to connect to %s: %w", addr, err)
+	}
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(smtpTimeout)
+	}
+	if err := conn.SetDeadline(deadline); err != nil {
+		conn.Close()
+		return err
+	}
+
+	client, err := smtp.NewClient(conn, s.config.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if s.config.TLS == core.SMTPTLSStartTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			return ErrStartTLSUnsupported
+		}
+		if err := client.StartTLS(s.tlsConfig); err != nil {
+			return fmt.Errorf("failed to start TLS: %w", err)
+		}
+	}
+
+	if s.config.Username != "" {
+		auth := smtp.PlainAuth("", s.config.Username, s.config.Password, s.config.Host)
+		if err := client.Auth(auth); err != nil {
+			return fmt.Errorf("failed to authenticate: %w", err)
+		}
+	}
+
+	if err := client.Mail(s.config.From); err != nil {
+		return err
+	}
+	if err := client.Rcpt(to); err != nil {
+		return err
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
 // SendEmailConfirmation sends an email confirmation notification
 func (s *SMTPProvider) SendEmailConfirmation(ctx context.Context, email, confirmationCode string) error {
-	req := repository.NotificationRequest{
-		To:      email,
-		Subject: "Confirm Your Email Address",
-		Body:    s.renderEmailConfirmationTemplate(confirmationCode),
-		Type:    repository.NotificationTypeEmailConfirmation,
-		Data: map[string]interface{}{
-			"confirmationCode": confirmationCode,
-		},
+	req, err := renderEmailConfirmation(ctx, s.templates, email, confirmationCode)
+	if err != nil {
+		return err
 	}
 
 	return s.SendNotification(ctx, req)
@@ -112,43 +179,85 @@ func (s *SMTPProvider) SendWelcomeEmail(ctx context.Context, email, name string)
 	return s.SendNotification(ctx, req)
 }
 
-// composeMessage creates a properly formatted email message
-func (s *SMTPProvider) composeMessage(from, to, subject, body string) string {
+// SendInvoiceEmail sends an invoice notification with the rendered invoice attached
+func (s *SMTPProvider) SendInvoiceEmail(ctx context.Context, email, invoiceNumber string, attachment repository.NotificationAttachment) error {
+	req := repository.NotificationRequest{
+		To:      email,
+		Subject: fmt.Sprintf("Invoice %s", invoiceNumber),
+		Body:    s.renderInvoiceTemplate(invoiceNumber),
+		Type:    repository.NotificationTypeInvoice,
+		Data: map[string]interface{}{
+			"invoiceNumber": invoiceNumber,
+		},
+		Attachments: []repository.NotificationAttachment{attachment},
+	}
+
+	return s.SendNotification(ctx, req)
+}
+
+// Boundaries separating the parts of multipart messages
+const (
+	smtpBoundary            = "kthulu-notification-boundary"
+	smtpAlternativeBoundary = "kthulu-alternative-boundary"
+)
+
+// composeMessage creates a properly formatted email message. A text body
+// makes the content multipart/alternative and attachments wrap everything
+// in a multipart/mixed message with base64-encoded parts.
+func (s *SMTPProvider) composeMessage(from, to, subject, body, textBody string, attachments []repository.NotificationAttachment) string {
 	msg := fmt.Sprintf("From: %s\r\n", from)
 	msg += fmt.Sprintf("To: %s\r\n", to)
 	msg += fmt.Sprintf("Subject: %s\r\n", subject)
 	msg += "MIME-Version: 1.0\r\n"
-	msg += "Content-Type: text/html; charset=UTF-8\r\n"
+	if len(attachments) == 0 {
+		return msg + composeContent(body, textBody)
+	}
+
+	msg += fmt.Sprintf("Content-Type: multipart/mixed; boundary=%q\r\n", smtpBoundary)
 	msg += "\r\n"
-	msg += body
+	msg += fmt.Sprintf("--%s\r\n", smtpBoundary)
+	msg += composeContent(body, textBody) + "\r\n"
+	for _, attachment := range attachments {
+		msg += fmt.Sprintf("--%s\r\n", smtpBoundary)
+		msg += fmt.Sprintf("Content-Type: %s\r\n", attachment.ContentType)
+		msg += "Content-Transfer-Encoding: base64\r\n"
+		msg += fmt.Sprintf("Content-Disposition: attachment; filename=%q\r\n", attachment.Filename)
+		msg += "\r\n"
+		msg += wrapBase64(base64.StdEncoding.EncodeToString(attachment.Content))
+	}
+	msg += fmt.Sprintf("--%s--\r\n", smtpBoundary)
 
 	return msg
 }
 
-// renderEmailConfirmationTemplate renders the email confirmation template
-func (s *SMTPProvider) renderEmailConfirmationTemplate(confirmationCode string) string {
-	template := `
-<!DOCTYPE html>
-<html>
-<head>
-    <meta charset="UTF-8">
-    <title>Confirm Your Email</title>
-</head>
-<body style="font-family: Arial, sans-serif; line-height: 1.6; color: #333;">
-    <div style="max-width: 600px; margin: 0 auto; padding: 20px;">
-        <h2 style="color: #2c3e50;">Confirm Your Email Address</h2>
-        <p>Thank you for registering! Please confirm your email address by using the confirmation code below:</p>
-        <div style="background-color: #f8f9fa; padding: 15px; border-radius: 5px; text-align: center; margin: 20px 0;">
-            <h3 style="color: #007bff; font-family: monospace; letter-spacing: 2px;">%s</h3>
-        </div>
-        <p>If you didn't create an account, you can safely ignore this email.</p>
-        <hr style="border: none; border-top: 1px solid #eee; margin: 30px 0;">
-        <p style="font-size: 12px; color: #666;">This is an automated message, please do not reply.</p>
-    </div>
-</body>
-</html>`
+// composeContent writes the headers and body of the message content, with
+// the text body as the first alternative when there is one
+func composeContent(body, textBody string) string {
+	if textBody == "" {
+		return "Content-Type: text/html; charset=UTF-8\r\n\r\n" + body
+	}
 
-	return fmt.Sprintf(template, confirmationCode)
+	content := fmt.Sprintf("Content-Type: multipart/alternative; boundary=%q\r\n", smtpAlternativeBoundary)
+	content += "\r\n"
+	content += fmt.Sprintf("--%s\r\n", smtpAlternativeBoundary)
+	content += "Content-Type: text/plain; charset=UTF-8\r\n\r\n"
+	content += textBody + "\r\n"
+	content += fmt.Sprintf("--%s\r\n", smtpAlternativeBoundary)
+	content += "Content-Type: text/html; charset=UTF-8\r\n\r\n"
+	content += body + "\r\n"
+	content += fmt.Sprintf("--%s--\r\n", smtpAlternativeBoundary)
+	return content
+}
+
+// wrapBase64 splits encoded content into the 76 character lines required by MIME
+func wrapBase64(encoded string) string {
+	var b strings.Builder
+	for len(encoded) > 76 {
+		b.WriteString(encoded[:76] + "\r\n")
+		encoded = encoded[76:]
+	}
+	b.WriteString(encoded + "\r\n")
+	return b.String()
 }
 
 // renderPasswordResetTemplate renders the password reset template
@@ -206,5 +315,28 @@ func (s *SMTPProvider) renderWelcomeTemplate(name string) string {
 	return fmt.Sprintf(template, nameStr)
 }
 
+// renderInvoiceTemplate renders the invoice email template
+func (s *SMTPProvider) renderInvoiceTemplate(invoiceNumber string) string {
+	template := `
+<!DOCTYPE html>
+<html>
+<head>
+    <meta charset="UTF-8">
+    <title>Invoice %s</title>
+</head>
+<body style="font-family: Arial, sans-serif; line-height: 1.6; color: #333;">
+    <div style="max-width: 600px; margin: 0 auto; padding: 20px;">
+        <h2 style="color: #2c3e50;">Invoice %s</h2>
+        <p>Please find your invoice attached to this email.</p>
+        <p>If you have any questions about this invoice, please get in touch with us.</p>
+        <hr style="border: none; border-top: 1px solid #eee; margin: 30px 0;">
+        <p style="font-size: 12px; color: #666;">This is an automated message, please do not reply.</p>
+    </div>
+</body>
+</html>`
+
+	return fmt.Sprintf(template, invoiceNumber, invoiceNumber)
+}
+
 // Ensure SMTPProvider implements NotificationProvider
 var _ repository.NotificationProvider = (*SMTPProvider)(nil)
@@ -0,0 +1,234 @@
+package notifier
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"net"
+	"net/http/httptest"
+	"net/textproto"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/pmaojo/kthulu-go/backend/core"
+	"github.com/pmaojo/kthulu-go/backend/internal/domain/repository"
+)
+
+// receivedMail is what the fake SMTP server saw during one session
+type receivedMail struct {
+	auth     string
+	from     string
+	to       []string
+	data     string
+	startTLS bool
+}
+
+// fakeSMTPServer accepts a single SMTP session. When tlsConfig is set it
+// either wraps the listener (implicit TLS) or offers STARTTLS.
+type fakeSMTPServer struct {
+	listener  net.Listener
+	tlsConfig *tls.Config
+	startTLS  bool
+	mails     chan receivedMail
+}
+
+func newFakeSMTPServer(t *testing.T, tlsConfig *tls.Config, startTLS bool) *fakeSMTPServer {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	if tlsConfig != nil && !startTLS {
+		listener = tls.NewListener(listener, tlsConfig)
+	}
+	s := &fakeSMTPServer{listener: listener, tlsConfig: tlsConfig, startTLS: startTLS, mails: make(chan receivedMail, 1)}
+	t.Cleanup(func() { listener.Close() })
+	go s.serve()
+	return s
+}
+
+func (s *fakeSMTPServer) port() int {
+	return s.listener.Addr().(*net.TCPAddr).Port
+}
+
+func (s *fakeSMTPServer) serve() {
+	conn, err := s.listener.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	var mail receivedMail
+	text := textproto.NewConn(conn)
+	text.PrintfLine("220 fake ESMTP")
+	for {
+		line, err := text.ReadLine()
+		if err != nil {
+			return
+		}
+		verb, arg, _ := strings.Cut(line, " ")
+		switch strings.ToUpper(verb) {
+		case "EHLO", "HELO":
+			if s.startTLS && !mail.startTLS {
+				text.PrintfLine("250-fake\r\n250-STARTTLS\r\n250 AUTH PLAIN")
+			} else {
+				text.PrintfLine("250-fake\r\n250 AUTH PLAIN")
+			}
+		case "STARTTLS":
+			text.PrintfLine("220 ready")
+			tlsConn := tls.Server(conn, s.tlsConfig)
+			if err := tlsConn.Handshake(); err != nil {
+				return
+			}
+			conn = tlsConn
+			text = textproto.NewConn(conn)
+			mail.startTLS = true
+		case "AUTH":
+			mail.auth = arg
+			text.PrintfLine("235 ok")
+		case "MAIL":
+			mail.from = strings.TrimSuffix(strings.TrimPrefix(arg, "FROM:<"), ">")
+			text.PrintfLine("250 ok")
+		case "RCPT":
+			mail.to = append(mail.to, strings.TrimSuffix(strings.TrimPrefix(arg, "TO:<"), ">"))
+			text.PrintfLine("250 ok")
+		case "DATA":
+			text.PrintfLine("354 go ahead")
+			data, err := text.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			mail.data = string(data)
+			text.PrintfLine("250 queued")
+		case "QUIT":
+			text.PrintfLine("221 bye")
+			s.mails <- mail
+			return
+		default:
+			text.PrintfLine("250 ok")
+		}
+	}
+}
+
+// testTLSConfigs returns a server certificate for 127.0.0.1 and a client
+// configuration trusting it
+func testTLSConfigs(t *testing.T) (server, client *tls.Config) {
+	t.Helper()
+	srv := httptest.NewUnstartedServer(nil)
+	srv.StartTLS()
+	defer srv.Close()
+
+	pool := x509.NewCertPool()
+	pool.AddCert(srv.Certificate())
+	return &tls.Config{Certificates: srv.TLS.Certificates}, &tls.Config{RootCAs: pool, ServerName: "127.0.0.1"}
+}
+
+func newTestSMTPProvider(port int, tlsMode string, clientTLS *tls.Config) *SMTPProvider {
+	provider := NewSMTPProvider(core.SMTPConfig{
+		Host:     "127.0.0.1",
+		Port:     port,
+		Username: "mailer",
+		Password: "secret",
+		From:     "noreply@kthulu.local",
+		TLS:      tlsMode,
+		Enabled:  true,
+	}, NewEmailTemplateRenderer(nil), core.NewLoggerFromZap(zap.NewNop())).(*SMTPProvider)
+	if clientTLS != nil {
+		provider.tlsConfig = clientTLS
+	}
+	return provider
+}
+
+func TestSMTPProviderSendsEmailConfirmation(t *testing.T) {
+	server := newFakeSMTPServer(t, nil, false)
+	provider := newTestSMTPProvider(server.port(), core.SMTPTLSNone, nil)
+
+	if err := provider.SendEmailConfirmation(context.Background(), "jane@example.com", "ABC123"); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+
+	mail := <-server.mails
+	if mail.from != "noreply@kthulu.local" || len(mail.to) != 1 || mail.to[0] != "jane@example.com" {
+		t.Fatalf("unexpected envelope from=%s to=%v", mail.from, mail.to)
+	}
+	wantAuth := "PLAIN " + base64.StdEncoding.EncodeToString([]byte("\x00mailer\x00secret"))
+	if mail.auth != wantAuth {
+		t.Fatalf("expected PLAIN auth for mailer, got %q", mail.auth)
+	}
+	for _, want := range []string{
+		"Subject: Confirm Your Email Address",
+		"Content-Type: multipart/alternative",
+		"Content-Type: text/plain; charset=UTF-8\n\nThank you for registering!",
+		"Please confirm your email address by using this code: ABC123",
+		`letter-spacing: 2px;">ABC123</h3>`,
+	} {
+		if !strings.Contains(mail.data, want) {
+			t.Fatalf("expected %q in message:\n%s", want, mail.data)
+		}
+	}
+}
+
+func TestSMTPProviderSendsInvoiceOverStartTLS(t *testing.T) {
+	serverTLS, clientTLS := testTLSConfigs(t)
+	server := newFakeSMTPServer(t, serverTLS, true)
+	provider := newTestSMTPProvider(server.port(), core.SMTPTLSStartTLS, clientTLS)
+
+	attachment := repository.NotificationAttachment{Filename: "invoice-INV-7.pdf", ContentType: "application/pdf", Content: []byte("%PDF-1.4 test")}
+	if err := provider.SendInvoiceEmail(context.Background(), "billing@example.com", "INV-7", attachment); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+
+	mail := <-server.mails
+	if !mail.startTLS {
+		t.Fatalf("expected the session to be upgraded with STARTTLS")
+	}
+	for _, want := range []string{
+		"Subject: Invoice INV-7",
+		"Content-Type: multipart/mixed",
+		`Content-Disposition: attachment; filename="invoice-INV-7.pdf"`,
+		base64.StdEncoding.EncodeToString(attachment.Content),
+	} {
+		if !strings.Contains(mail.data, want) {
+			t.Fatalf("expected %q in message:\n%s", want, mail.data)
+		}
+	}
+}
+
+func TestSMTPProviderImplicitTLS(t *testing.T) {
+	serverTLS, clientTLS := testTLSConfigs(t)
+	server := newFakeSMTPServer(t, serverTLS, false)
+	provider := newTestSMTPProvider(server.port(), core.SMTPTLSImplicit, clientTLS)
+
+	if err := provider.SendWelcomeEmail(context.Background(), "jane@example.com", "Jane"); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	if mail := <-server.mails; !strings.Contains(mail.data, "Welcome Jane!") {
+		t.Fatalf("unexpected message:\n%s", mail.data)
+	}
+}
+
+func TestSMTPProviderRequiresStartTLS(t *testing.T) {
+	server := newFakeSMTPServer(t, nil, false)
+	provider := newTestSMTPProvider(server.port(), core.SMTPTLSStartTLS, nil)
+
+	err := provider.SendWelcomeEmail(context.Background(), "jane@example.com", "Jane")
+	if !errors.Is(err, ErrStartTLSUnsupported) {
+		t.Fatalf("expected ErrStartTLSUnsupported, got %v", err)
+	}
+}
+
+func TestNewNotificationProviderFallsBackToConsole(t *testing.T) {
+	logger := core.NewLoggerFromZap(zap.NewNop())
+
+	if _, ok := NewNotificationProvider(&core.Config{}, NewEmailTemplateRenderer(nil), logger).(*ConsoleProvider); !ok {
+		t.Fatalf("expected console provider when SMTP is disabled")
+	}
+	cfg := &core.Config{SMTP: core.SMTPConfig{Enabled: true, Host: "localhost", Port: 25, TLS: core.SMTPTLSNone}}
+	if _, ok := NewNotificationProvider(cfg, NewEmailTemplateRenderer(nil), logger).(*SMTPProvider); !ok {
+		t.Fatalf("expected SMTP provider when SMTP is enabled")
+	}
+}
@@ -12,7 +12,11 @@ type MeterProvider interface {
 }
 
 // NewMetricsProvider creates metrics provider based on configuration.
+// METRICS_EXPORTER=none disables metrics with a no-op provider.
 func NewMetricsProvider(cfg *core.Config) (*metrics.PrometheusMetrics, error) {
+	if cfg != nil && cfg.Observability.MetricsExporter == core.MetricsExporterNone {
+		return metrics.NewNoopMetrics(), nil
+	}
 	// Currently only Prometheus exporter is supported.
 	return metrics.NewPrometheusMetrics()
 }
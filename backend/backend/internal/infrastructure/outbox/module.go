@@ -0,0 +1,31 @@
+// @kthulu:module:webhooks
+package outbox
+
+import (
+	"context"
+
+	"go.uber.org/fx"
+
+	"github.com/pmaojo/kthulu-go/backend/core"
+)
+
+// Module runs the outbox relay for the lifetime of the application.
+var Module = fx.Options(
+	fx.Provide(NewRelay),
+	fx.Invoke(registerRelay),
+)
+
+// registerRelay starts the relay with the application and stops it on shutdown
+func registerRelay(lc fx.Lifecycle, relay *Relay, logger core.Logger) {
+	lc.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			relay.Start()
+			logger.Info("Outbox relay started")
+			return nil
+		},
+		OnStop: func(context.Context) error {
+			relay.Stop()
+			return nil
+		},
+	})
+}
@@ -0,0 +1,141 @@
+// @kthulu:module:webhooks
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/pmaojo/kthulu-go/backend/core"
+	"github.com/pmaojo/kthulu-go/backend/internal/domain/repository"
+)
+
+const (
+	defaultPollInterval   = 2 * time.Second
+	defaultBatchSize      = 100
+	defaultMaxAttempts    = 10
+	defaultInitialBackoff = 10 * time.Second
+	defaultMaxBackoff     = time.Hour
+	// defaultLease outlasts a batch of deliveries that all time out
+	defaultLease = 30 * time.Minute
+)
+
+// Relay polls the outbox for committed events and delivers them to the
+// organization webhooks, marking each event delivered once every endpoint
+// acknowledged it. Each poll leases the events it delivers so concurrent
+// relays don't deliver them twice. Failed events are retried after an
+// exponential backoff with jitter until maxAttempts is reached and they are
+// dead-lettered. Events left behind by a crash are picked up once their
+// lease expires.
+type Relay struct {
+	outbox         repository.OutboxRepository
+	deliverer      repository.WebhookDeliverer
+	logger         core.Logger
+	pollInterval   time.Duration
+	batchSize      int
+	maxAttempts    int
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+	lease          time.Duration
+
+	stop chan struct{}
+	done sync.WaitGroup
+}
+
+// NewRelay creates a relay with the default poll interval, batch size and
+// attempt limit
+func NewRelay(outbox repository.OutboxRepository, deliverer repository.WebhookDeliverer, logger core.Logger) *Relay {
+	return &Relay{
+		outbox:         outbox,
+		deliverer:      deliverer,
+		logger:         logger,
+		pollInterval:   defaultPollInterval,
+		batchSize:      defaultBatchSize,
+		maxAttempts:    defaultMaxAttempts,
+		initialBackoff: defaultInitialBackoff,
+		maxBackoff:     defaultMaxBackoff,
+		lease:          defaultLease,
+	}
+}
+
+// backoff returns how long to wait before retrying an event that failed
+// attempts times: initialBackoff*2^(attempts-1) capped at maxBackoff, of
+// which the second half is random so failing events spread out.
+func (r *Relay) backoff(attempts int) time.Duration {
+	delay := r.initialBackoff
+	for i := 1; i < attempts && delay < r.maxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > r.maxBackoff {
+		delay = r.maxBackoff
+	}
+	if delay <= 1 {
+		return delay
+	}
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)))
+}
+
+// RelayOnce delivers one batch of pending events and returns how many were delivered
+func (r *Relay) RelayOnce(ctx context.Context) (int, error) {
+	events, err := r.outbox.ClaimPending(ctx, r.batchSize, r.lease)
+	if err != nil {
+		return 0, err
+	}
+
+	delivered := 0
+	for _, event := range events {
+		if err := r.deliverer.Deliver(ctx, event.OrganizationID, event.Event, json.RawMessage(event.Payload)); err != nil {
+			if event.Attempts+1 >= r.maxAttempts {
+				r.logger.Error("Dead-lettering outbox event", "error", err, "eventId", event.ID, "event", event.Event, "attempts", event.Attempts+1)
+				if markErr := r.outbox.MarkDeadLettered(ctx, event.ID, err.Error()); markErr != nil {
+					return delivered, markErr
+				}
+				continue
+			}
+			retryIn := r.backoff(event.Attempts + 1)
+			r.logger.Warn("Failed to deliver outbox event", "error", err, "eventId", event.ID, "event", event.Event, "retryIn", retryIn)
+			if markErr := r.outbox.MarkFailed(ctx, event.ID, err.Error(), time.Now().Add(retryIn)); markErr != nil {
+				return delivered, markErr
+			}
+			continue
+		}
+		if err := r.outbox.MarkDelivered(ctx, event.ID); err != nil {
+			return delivered, err
+		}
+		delivered++
+	}
+	return delivered, nil
+}
+
+// Start polls the outbox in the background until Stop is called
+func (r *Relay) Start() {
+	r.stop = make(chan struct{})
+	r.done.Add(1)
+	go func() {
+		defer r.done.Done()
+		ticker := time.NewTicker(r.pollInterval)
+		defer ticker.Stop()
+		for {
+			if _, err := r.RelayOnce(context.Background()); err != nil {
+				r.logger.Error("Outbox relay failed", "error", err)
+			}
+			select {
+			case <-r.stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop ends polling and waits for the current batch to finish
+func (r *Relay) Stop() {
+	if r.stop == nil {
+		return
+	}
+	close(r.stop)
+	r.done.Wait()
+	r.stop = nil
+}
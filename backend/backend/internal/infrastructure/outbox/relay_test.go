@@ -0,0 +1,279 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	_ "modernc.org/sqlite"
+
+	"github.com/pmaojo/kthulu-go/backend/core"
+	"github.com/pmaojo/kthulu-go/backend/internal/domain"
+	"github.com/pmaojo/kthulu-go/backend/internal/infrastructure/db"
+)
+
+type published struct {
+	organizationID uint
+	event          domain.WebhookEvent
+	data           json.RawMessage
+}
+
+type fakeDeliverer struct {
+	mu     sync.Mutex
+	events []published
+	err    error
+}
+
+func (f *fakeDeliverer) Deliver(ctx context.Context, organizationID uint, event domain.WebhookEvent, data interface{}) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return f.err
+	}
+	f.events = append(f.events, published{organizationID, event, data.(json.RawMessage)})
+	return nil
+}
+
+// openTestDB opens a file database with an invoice and the outbox table
+// created by its migration.
+func openTestDB(t *testing.T, path string) *sql.DB {
+	t.Helper()
+
+	conn, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	conn.SetMaxOpenConns(1)
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func setupSchema(t *testing.T, conn *sql.DB) {
+	t.Helper()
+
+	var up string
+	for _, name := range []string{"0031_create_outbox.sql", "0044_add_outbox_dead_letter.sql", "0047_add_outbox_retry_schedule.sql"} {
+		migration, err := os.ReadFile(filepath.Join("..", "..", "..", "migrations", name))
+		if err != nil {
+			t.Fatalf("read outbox migration: %v", err)
+		}
+		up += strings.SplitN(string(migration), "-- +goose Down", 2)[0]
+	}
+
+	schema := `
+		CREATE TABLE invoices (
+			id INTEGER PRIMARY KEY,
+			organization_id INTEGER NOT NULL,
+			contact_id INTEGER NOT NULL,
+			invoice_number TEXT NOT NULL,
+			type TEXT NOT NULL,
+			status TEXT NOT NULL,
+			currency TEXT NOT NULL,
+			exchange_rate REAL NOT NULL DEFAULT 1,
+			subtotal REAL NOT NULL DEFAULT 0,
+			tax_amount REAL NOT NULL DEFAULT 0,
+			discount_amount REAL NOT NULL DEFAULT 0,
+			total_amount REAL NOT NULL DEFAULT 0,
+			paid_amount REAL NOT NULL DEFAULT 0,
+			balance_due REAL NOT NULL DEFAULT 0,
+			issue_date DATETIME NOT NULL,
+			due_date DATETIME,
+			payment_terms TEXT,
+			notes TEXT,
+			terms_conditions TEXT,
+			created_by INTEGER NOT NULL,
+			created_at DATETIME NOT NULL,
+			updated_at DATETIME NOT NULL
+		);
+		INSERT INTO invoices (id, organization_id, contact_id, invoice_number, type, status, currency, issue_date, created_by, created_at, updated_at)
+		VALUES (1, 1, 1, 'INV-1', 'invoice', 'sent', 'EUR', datetime('now'), 1, datetime('now'), datetime('now'));
+	`
+	if _, err := conn.Exec(schema + up); err != nil {
+		t.Fatalf("create schema: %v", err)
+	}
+}
+
+func newTestRelay(conn *sql.DB, deliverer *fakeDeliverer) *Relay {
+	logger := core.NewLoggerFromZap(zap.NewNop())
+	return NewRelay(db.NewOutboxRepository(conn, logger), deliverer, logger)
+}
+
+func markInvoicePaid(t *testing.T, conn *sql.DB) {
+	t.Helper()
+
+	logger := core.NewLoggerFromZap(zap.NewNop())
+	invoices := db.NewInvoiceRepository(conn, logger, nil, nil)
+	invoice := &domain.Invoice{ID: 1, OrganizationID: 1, ContactID: 1, Type: domain.InvoiceTypeInvoice, Status: domain.InvoiceStatusPaid, Currency: "EUR"}
+	event, err := domain.NewOutboxEvent(1, domain.WebhookEventInvoicePaid, map[string]uint{"invoiceId": 1})
+	if err != nil {
+		t.Fatalf("new outbox event: %v", err)
+	}
+	if err := invoices.UpdateWithEvent(context.Background(), invoice, event); err != nil {
+		t.Fatalf("update invoice: %v", err)
+	}
+}
+
+func TestCommittedEventSurvivesCrashBeforeDelivery(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "outbox.db")
+
+	// The first process commits the payment and dies before relaying it
+	first := openTestDB(t, path)
+	setupSchema(t, first)
+	markInvoicePaid(t, first)
+	if err := first.Close(); err != nil {
+		t.Fatalf("close db: %v", err)
+	}
+
+	// A restarted process relays the event it finds in the outbox
+	second := openTestDB(t, path)
+	deliverer := &fakeDeliverer{}
+	relay := newTestRelay(second, deliverer)
+
+	delivered, err := relay.RelayOnce(context.Background())
+	if err != nil {
+		t.Fatalf("relay: %v", err)
+	}
+	if delivered != 1 || len(deliverer.events) != 1 {
+		t.Fatalf("expected the committed event to be published once, got %d", len(deliverer.events))
+	}
+	got := deliverer.events[0]
+	if got.organizationID != 1 || got.event != domain.WebhookEventInvoicePaid || string(got.data) != `{"invoiceId":1}` {
+		t.Fatalf("unexpected published event %+v", got)
+	}
+
+	if delivered, err := relay.RelayOnce(context.Background()); err != nil || delivered != 0 {
+		t.Fatalf("delivered event should not be published again, got %d (%v)", delivered, err)
+	}
+}
+
+func TestRelayRetriesUnacknowledgedDelivery(t *testing.T) {
+	conn := openTestDB(t, filepath.Join(t.TempDir(), "outbox.db"))
+	setupSchema(t, conn)
+	markInvoicePaid(t, conn)
+
+	deliverer := &fakeDeliverer{err: errors.New("endpoint unavailable")}
+	relay := newTestRelay(conn, deliverer)
+
+	if delivered, err := relay.RelayOnce(context.Background()); err != nil || delivered != 0 {
+		t.Fatalf("expected no delivery while the endpoint fails, got %d (%v)", delivered, err)
+	}
+	var attempts int
+	var lastError string
+	if err := conn.QueryRow("SELECT attempts, last_error FROM outbox").Scan(&attempts, &lastError); err != nil {
+		t.Fatalf("read outbox: %v", err)
+	}
+	if attempts != 1 || lastError != "endpoint unavailable" {
+		t.Fatalf("expected failed attempt recorded, got attempts=%d last_error=%q", attempts, lastError)
+	}
+
+	// The event waits out its backoff even once the endpoint recovers
+	deliverer.err = nil
+	if delivered, err := relay.RelayOnce(context.Background()); err != nil || delivered != 0 || len(deliverer.events) != 0 {
+		t.Fatalf("expected no retry before the backoff expires, got %d (%v)", delivered, err)
+	}
+
+	if _, err := conn.Exec("UPDATE outbox SET next_attempt_at = ?", time.Now().UTC().Add(-time.Second)); err != nil {
+		t.Fatalf("expire backoff: %v", err)
+	}
+	if delivered, err := relay.RelayOnce(context.Background()); err != nil || delivered != 1 {
+		t.Fatalf("expected event delivered on retry, got %d (%v)", delivered, err)
+	}
+}
+
+func TestRelayBackoffGrowsAndStaysCapped(t *testing.T) {
+	relay := newTestRelay(nil, &fakeDeliverer{})
+	relay.initialBackoff = 10 * time.Second
+	relay.maxBackoff = time.Minute
+
+	for attempts, want := range map[int]time.Duration{1: 10 * time.Second, 2: 20 * time.Second, 3: 40 * time.Second, 4: time.Minute, 20: time.Minute} {
+		if got := relay.backoff(attempts); got < want/2 || got >= want {
+			t.Fatalf("attempt %d: expected a backoff in [%s, %s), got %s", attempts, want/2, want, got)
+		}
+	}
+}
+
+func TestRelaySkipsEventsLeasedByAnotherRelay(t *testing.T) {
+	conn := openTestDB(t, filepath.Join(t.TempDir(), "outbox.db"))
+	setupSchema(t, conn)
+	markInvoicePaid(t, conn)
+
+	// Another relay claimed the event and is still delivering it
+	other := db.NewOutboxRepository(conn, core.NewLoggerFromZap(zap.NewNop()))
+	claimed, err := other.ClaimPending(context.Background(), 10, time.Minute)
+	if err != nil || len(claimed) != 1 {
+		t.Fatalf("expected the event claimed, got %d (%v)", len(claimed), err)
+	}
+
+	deliverer := &fakeDeliverer{}
+	relay := newTestRelay(conn, deliverer)
+	if delivered, err := relay.RelayOnce(context.Background()); err != nil || delivered != 0 || len(deliverer.events) != 0 {
+		t.Fatalf("expected the leased event to be skipped, got %d (%v)", delivered, err)
+	}
+
+	// An expired lease, as left by a crashed relay, is claimed again
+	if _, err := conn.Exec("UPDATE outbox SET locked_until = ?", time.Now().UTC().Add(-time.Second)); err != nil {
+		t.Fatalf("expire lease: %v", err)
+	}
+	if delivered, err := relay.RelayOnce(context.Background()); err != nil || delivered != 1 {
+		t.Fatalf("expected the event delivered after the lease expired, got %d (%v)", delivered, err)
+	}
+}
+
+func TestRelayDeadLettersAfterMaxAttempts(t *testing.T) {
+	conn := openTestDB(t, filepath.Join(t.TempDir(), "outbox.db"))
+	setupSchema(t, conn)
+	markInvoicePaid(t, conn)
+
+	deliverer := &fakeDeliverer{err: errors.New("endpoint unavailable")}
+	relay := newTestRelay(conn, deliverer)
+	relay.maxAttempts = 3
+	relay.initialBackoff = 0
+
+	for i := 0; i < relay.maxAttempts; i++ {
+		if _, err := relay.RelayOnce(context.Background()); err != nil {
+			t.Fatalf("relay: %v", err)
+		}
+	}
+	var attempts int
+	var deadLettered sql.NullTime
+	if err := conn.QueryRow("SELECT attempts, dead_lettered_at FROM outbox").Scan(&attempts, &deadLettered); err != nil {
+		t.Fatalf("read outbox: %v", err)
+	}
+	if attempts != 3 || !deadLettered.Valid {
+		t.Fatalf("expected the event dead-lettered after 3 attempts, got attempts=%d dead_lettered=%v", attempts, deadLettered.Valid)
+	}
+
+	// A dead-lettered event is not retried even once the endpoint recovers
+	deliverer.err = nil
+	if delivered, err := relay.RelayOnce(context.Background()); err != nil || delivered != 0 {
+		t.Fatalf("expected the dead-lettered event to be skipped, got %d (%v)", delivered, err)
+	}
+}
+
+func TestUpdateWithEventRollsBackEventWhenInvoiceMissing(t *testing.T) {
+	conn := openTestDB(t, filepath.Join(t.TempDir(), "outbox.db"))
+	setupSchema(t, conn)
+
+	invoices := db.NewInvoiceRepository(conn, core.NewLoggerFromZap(zap.NewNop()), nil, nil)
+	event, _ := domain.NewOutboxEvent(1, domain.WebhookEventInvoicePaid, nil)
+	err := invoices.UpdateWithEvent(context.Background(), &domain.Invoice{ID: 42, OrganizationID: 1, Status: domain.InvoiceStatusPaid}, event)
+	if !errors.Is(err, domain.ErrInvoiceNotFound) {
+		t.Fatalf("expected invoice not found, got %v", err)
+	}
+
+	var pending int
+	if err := conn.QueryRow("SELECT COUNT(*) FROM outbox").Scan(&pending); err != nil {
+		t.Fatalf("count outbox: %v", err)
+	}
+	if pending != 0 {
+		t.Fatalf("outbox event should roll back with the failed update, found %d", pending)
+	}
+}
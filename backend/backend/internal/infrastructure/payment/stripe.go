@@ -0,0 +1,218 @@
+// @kthulu:module:payments
+package payment
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pmaojo/kthulu-go/backend/core"
+	"github.com/pmaojo/kthulu-go/backend/internal/domain"
+	"github.com/pmaojo/kthulu-go/backend/internal/domain/money"
+	"github.com/pmaojo/kthulu-go/backend/internal/domain/repository"
+)
+
+const (
+	// StripeSignatureHeader carries the timestamped signatures of a Stripe
+	// webhook delivery
+	StripeSignatureHeader = "Stripe-Signature"
+
+	stripeRequestTimeout = 10 * time.Second
+	// stripeSignatureTolerance bounds the age of accepted webhook deliveries
+	// to limit replays
+	stripeSignatureTolerance = 5 * time.Minute
+)
+
+// stripeEvent is the part of a Stripe webhook event the gateway reads
+type stripeEvent struct {
+	ID      string `json:"id"`
+	Type    string `json:"type"`
+	Created int64  `json:"created"`
+	Data    struct {
+		Object stripePaymentIntent `json:"object"`
+	} `json:"data"`
+}
+
+// stripePaymentIntent is the part of a Stripe payment intent the gateway
+// reads
+type stripePaymentIntent struct {
+	ID             string            `json:"id"`
+	ClientSecret   string            `json:"client_secret"`
+	Amount         int64             `json:"amount"`
+	AmountReceived int64             `json:"amount_received"`
+	Currency       string            `json:"currency"`
+	Metadata       map[string]string `json:"metadata"`
+}
+
+// StripeGateway collects invoice payments with Stripe payment intents
+type StripeGateway struct {
+	apiURL        string
+	secretKey     string
+	webhookSecret string
+	rounding      money.Policy
+	client        *http.Client
+	now           func() time.Time
+}
+
+// NewStripeGateway creates a Stripe gateway. Amounts are sent in the minor
+// units of their currency as given by the rounding policy.
+func NewStripeGateway(cfg core.StripeConfig, rounding money.Policy) *StripeGateway {
+	return &StripeGateway{
+		apiURL:        strings.TrimRight(cfg.APIURL, "/"),
+		secretKey:     cfg.SecretKey,
+		webhookSecret: cfg.WebhookSecret,
+		rounding:      rounding,
+		client:        &http.Client{Timeout: stripeRequestTimeout},
+		now:           time.Now,
+	}
+}
+
+// Method returns the stripe payment method
+func (g *StripeGateway) Method() domain.PaymentMethod {
+	return domain.PaymentMethodStripe
+}
+
+// CreatePaymentIntent creates a Stripe payment intent for the amount. Retries
+// for the same invoice and amount return the same intent.
+func (g *StripeGateway) CreatePaymentIntent(ctx context.Context, req repository.PaymentIntentRequest) (*repository.PaymentIntent, error) {
+	amount := g.toMinorUnits(req.Amount, req.Currency)
+	form := url.Values{
+		"amount":                             {strconv.FormatInt(amount, 10)},
+		"currency":                           {strings.ToLower(req.Currency)},
+		"description":                        {"Invoice " + req.InvoiceNumber},
+		"automatic_payment_methods[enabled]": {"true"},
+		"metadata[organization_id]":          {strconv.FormatUint(uint64(req.OrganizationID), 10)},
+		"metadata[invoice_id]":               {strconv.FormatUint(uint64(req.InvoiceID), 10)},
+		"metadata[invoice_number]":           {req.InvoiceNumber},
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, g.apiURL+"/v1/payment_intents", strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build payment intent request: %w", err)
+	}
+	httpReq.Header.Set("Authorization", "Bearer "+g.secretKey)
+	httpReq.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	httpReq.Header.Set("Idempotency-Key", fmt.Sprintf("invoice-%d-%d-%d", req.OrganizationID, req.InvoiceID, amount))
+
+	resp, err := g.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create payment intent: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var body struct {
+			Error struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		_ = json.NewDecoder(resp.Body).Decode(&body)
+		return nil, fmt.Errorf("stripe returned status %d: %s", resp.StatusCode, body.Error.Message)
+	}
+
+	var intent stripePaymentIntent
+	if err := json.NewDecoder(resp.Body).Decode(&intent); err != nil {
+		return nil, fmt.Errorf("failed to decode payment intent: %w", err)
+	}
+	return &repository.PaymentIntent{
+		ID:           intent.ID,
+		ClientSecret: intent.ClientSecret,
+		Amount:       g.fromMinorUnits(intent.Amount, intent.Currency),
+		Currency:     strings.ToUpper(intent.Currency),
+	}, nil
+}
+
+// ParseWebhook verifies the Stripe signature of a webhook delivery and
+// returns its event, with the payment of payment_intent.succeeded events
+func (g *StripeGateway) ParseWebhook(payload []byte, headers map[string][]string) (*repository.PaymentWebhook, error) {
+	if err := g.verifySignature(payload, http.Header(headers).Get(StripeSignatureHeader)); err != nil {
+		return nil, err
+	}
+
+	var event stripeEvent
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidPaymentWebhook, err)
+	}
+	if event.ID == "" {
+		return nil, fmt.Errorf("%w: event has no id", domain.ErrInvalidPaymentWebhook)
+	}
+	webhook := &repository.PaymentWebhook{EventID: event.ID}
+	if event.Type != "payment_intent.succeeded" {
+		return webhook, nil
+	}
+
+	intent := event.Data.Object
+	organizationID, err := strconv.ParseUint(intent.Metadata["organization_id"], 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("%w: payment intent %s has no organization", domain.ErrInvalidPaymentWebhook, intent.ID)
+	}
+	invoiceID, err := strconv.ParseUint(intent.Metadata["invoice_id"], 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("%w: payment intent %s has no invoice", domain.ErrInvalidPaymentWebhook, intent.ID)
+	}
+	webhook.Payment = &repository.PaymentEvent{
+		IntentID:       intent.ID,
+		OrganizationID: uint(organizationID),
+		InvoiceID:      uint(invoiceID),
+		Amount:         g.fromMinorUnits(intent.AmountReceived, intent.Currency),
+		Currency:       strings.ToUpper(intent.Currency),
+		PaidAt:         time.Unix(event.Created, 0).UTC(),
+	}
+	return webhook, nil
+}
+
+// verifySignature checks the header, "t=<unix time>,v1=<hex>[,v1=<hex>]",
+// against the HMAC-SHA256 of "<unix time>.<payload>" keyed with the webhook
+// secret
+func (g *StripeGateway) verifySignature(payload []byte, header string) error {
+	var timestamp string
+	var signatures []string
+	for _, part := range strings.Split(header, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch key {
+		case "t":
+			timestamp = value
+		case "v1":
+			signatures = append(signatures, value)
+		}
+	}
+	if timestamp == "" || len(signatures) == 0 {
+		return fmt.Errorf("%w: missing signature", domain.ErrInvalidPaymentWebhook)
+	}
+
+	signedAt, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("%w: invalid signature timestamp", domain.ErrInvalidPaymentWebhook)
+	}
+	if age := g.now().Sub(time.Unix(signedAt, 0)); age > stripeSignatureTolerance || age < -stripeSignatureTolerance {
+		return fmt.Errorf("%w: signature timestamp outside tolerance", domain.ErrInvalidPaymentWebhook)
+	}
+
+	mac := hmac.New(sha256.New, []byte(g.webhookSecret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(payload)
+	expected := mac.Sum(nil)
+	for _, signature := range signatures {
+		if got, err := hex.DecodeString(signature); err == nil && hmac.Equal(got, expected) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: signature mismatch", domain.ErrInvalidPaymentWebhook)
+}
+
+func (g *StripeGateway) toMinorUnits(amount float64, currency string) int64 {
+	return int64(math.Round(amount * math.Pow10(g.rounding.PrecisionFor(currency))))
+}
+
+func (g *StripeGateway) fromMinorUnits(amount int64, currency string) float64 {
+	return float64(amount) / math.Pow10(g.rounding.PrecisionFor(currency))
+}
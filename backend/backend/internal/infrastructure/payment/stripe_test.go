@@ -0,0 +1,81 @@
+package payment
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/pmaojo/kthulu-go/backend/core"
+	"github.com/pmaojo/kthulu-go/backend/internal/domain"
+	"github.com/pmaojo/kthulu-go/backend/internal/domain/money"
+	"github.com/pmaojo/kthulu-go/backend/internal/domain/repository"
+)
+
+func TestStripeGateway_CreatePaymentIntent(t *testing.T) {
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/payment_intents" || r.Header.Get("Authorization") != "Bearer sk_test" {
+			t.Errorf("unexpected request %s %s", r.URL, r.Header.Get("Authorization"))
+		}
+		if err := r.ParseForm(); err != nil {
+			t.Errorf("parse form: %v", err)
+		}
+		// Yen have no minor units
+		if r.PostForm.Get("amount") != "1250" || r.PostForm.Get("currency") != "jpy" || r.PostForm.Get("metadata[invoice_id]") != "7" {
+			t.Errorf("unexpected form %v", r.PostForm)
+		}
+		fmt.Fprint(w, `{"id": "pi_1", "client_secret": "pi_1_secret", "amount": 1250, "currency": "jpy"}`)
+	}))
+	defer api.Close()
+
+	gateway := NewStripeGateway(core.StripeConfig{SecretKey: "sk_test", APIURL: api.URL}, money.DefaultPolicy())
+	intent, err := gateway.CreatePaymentIntent(context.Background(), repository.PaymentIntentRequest{OrganizationID: 1, InvoiceID: 7, InvoiceNumber: "INV-0007", Amount: 1250, Currency: "JPY"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if intent.ID != "pi_1" || intent.ClientSecret != "pi_1_secret" || intent.Amount != 1250 || intent.Currency != "JPY" {
+		t.Fatalf("unexpected intent %+v", intent)
+	}
+}
+
+func TestStripeGateway_ParseWebhook(t *testing.T) {
+	now := time.Unix(1714644000, 0)
+	gateway := NewStripeGateway(core.StripeConfig{WebhookSecret: "whsec_test"}, money.DefaultPolicy())
+	gateway.now = func() time.Time { return now }
+	sign := func(payload string, at time.Time) map[string][]string {
+		mac := hmac.New(sha256.New, []byte("whsec_test"))
+		fmt.Fprintf(mac, "%d.%s", at.Unix(), payload)
+		return http.Header{StripeSignatureHeader: {fmt.Sprintf("t=%d,v1=%s", at.Unix(), hex.EncodeToString(mac.Sum(nil)))}}
+	}
+
+	succeeded := `{"id": "evt_1", "type": "payment_intent.succeeded", "created": 1714643990, "data": {"object": {"id": "pi_1", "amount_received": 4050, "currency": "eur", "metadata": {"organization_id": "1", "invoice_id": "7"}}}}`
+	webhook, err := gateway.ParseWebhook([]byte(succeeded), sign(succeeded, now))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := repository.PaymentEvent{IntentID: "pi_1", OrganizationID: 1, InvoiceID: 7, Amount: 40.5, Currency: "EUR", PaidAt: time.Unix(1714643990, 0).UTC()}
+	if webhook.EventID != "evt_1" || webhook.Payment == nil || *webhook.Payment != want {
+		t.Fatalf("expected evt_1 paying %+v, got %+v", want, webhook)
+	}
+
+	other := `{"id": "evt_2", "type": "payment_intent.created", "data": {"object": {"id": "pi_1"}}}`
+	if webhook, err := gateway.ParseWebhook([]byte(other), sign(other, now)); err != nil || webhook.EventID != "evt_2" || webhook.Payment != nil {
+		t.Fatalf("expected other events to carry no payment, got %+v, %v", webhook, err)
+	}
+
+	for name, headers := range map[string]map[string][]string{
+		"unsigned": {},
+		"tampered": sign(other, now),
+		"replayed": sign(succeeded, now.Add(-10*time.Minute)),
+	} {
+		if _, err := gateway.ParseWebhook([]byte(succeeded), headers); !errors.Is(err, domain.ErrInvalidPaymentWebhook) {
+			t.Fatalf("%s: expected ErrInvalidPaymentWebhook, got %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+// @kthulu:module:invoices
+package pdf
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pmaojo/kthulu-go/backend/internal/domain"
+	"github.com/pmaojo/kthulu-go/backend/internal/domain/repository"
+)
+
+const (
+	pageWidth    = 595 // A4 in points
+	pageHeight   = 842
+	marginLeft   = 50
+	marginTop    = 60
+	lineHeight   = 14
+	fontSize     = 10
+	linesPerPage = (pageHeight - 2*marginTop) / lineHeight
+)
+
+// InvoiceRenderer renders invoices as plain Courier PDF documents
+type InvoiceRenderer struct{}
+
+// NewInvoiceRenderer creates a new PDF invoice renderer
+func NewInvoiceRenderer() repository.InvoiceRenderer {
+	return &InvoiceRenderer{}
+}
+
+// RenderPDF renders the invoice header, its items and totals into a PDF
+func (r *InvoiceRenderer) RenderPDF(ctx context.Context, invoice *domain.Invoice) ([]byte, error) {
+	if invoice == nil {
+		return nil, fmt.Errorf("failed to render invoice: invoice is nil")
+	}
+	return writeDocument(invoiceLines(invoice)), nil
+}
+
+// invoiceLines lays out the invoice as monospace-friendly text lines
+func invoiceLines(invoice *domain.Invoice) []string {
+	lines := []string{
+		fmt.Sprintf("%s %s", strings.ToUpper(strings.ReplaceAll(string(invoice.Type), "_", " ")), invoice.InvoiceNumber),
+		"",
+		fmt.Sprintf("Issue date: %s", invoice.IssueDate.Format("2006-01-02")),
+	}
+	if invoice.DueDate != nil {
+		lines = append(lines, fmt.Sprintf("Due date:   %s", invoice.DueDate.Format("2006-01-02")))
+	}
+	if invoice.PaymentTerms != "" {
+		lines = append(lines, fmt.Sprintf("Terms:      %s", invoice.PaymentTerms))
+	}
+	lines = append(lines, "", fmt.Sprintf("%-50s %10s %12s %12s", "Description", "Qty", "Unit price", "Total"))
+
+	for _, item := range invoice.Items {
+		lines = append(lines, fmt.Sprintf("%-50.50s %10.2f %12.2f %12.2f", item.Description, item.Quantity, item.UnitPrice, item.LineTotal))
+	}
+
+	lines = append(lines,
+		"",
+		fmt.Sprintf("%73s %12.2f", "Subtotal", invoice.Subtotal),
+		fmt.Sprintf("%73s %12.2f", "Discount", invoice.DiscountAmount),
+		fmt.Sprintf("%73s %12.2f", "Tax", invoice.TaxAmount),
+		fmt.Sprintf("%73s %12.2f", "Total "+invoice.Currency, invoice.TotalAmount),
+		fmt.Sprintf("%73s %12.2f", "Balance due "+invoice.Currency, invoice.BalanceDue),
+	)
+	for _, item := range invoice.Items {
+		if item.ReverseCharge {
+			lines = append(lines, "", "Reverse charge: VAT to be accounted for by the recipient")
+			break
+		}
+	}
+	if invoice.Notes != "" {
+		lines = append(lines, "", invoice.Notes)
+	}
+	if invoice.TermsConditions != "" {
+		lines = append(lines, "", invoice.TermsConditions)
+	}
+	return lines
+}
+
+// writeDocument builds a PDF with one Courier text block per page
+func writeDocument(lines []string) []byte {
+	var pages [][]string
+	for len(lines) > linesPerPage {
+		pages = append(pages, lines[:linesPerPage])
+		lines = lines[linesPerPage:]
+	}
+	pages = append(pages, lines)
+
+	// Objects 1 and 2 are the catalog and page tree, 3 is the font and every
+	// page takes two more objects: the page itself and its content stream.
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"", // page tree, filled in once the page ids are known
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Courier /Encoding /WinAnsiEncoding >>",
+	}
+	kids := make([]string, 0, len(pages))
+	for _, page := range pages {
+		pageID := len(objects) + 1
+		kids = append(kids, fmt.Sprintf("%d 0 R", pageID))
+		content := pageContent(page)
+		objects = append(objects,
+			fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>", pageWidth, pageHeight, pageID+1),
+			fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(content), content),
+		)
+	}
+	objects[1] = fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages))
+
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, object := range objects {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+	return buf.Bytes()
+}
+
+// pageContent writes the text operators for one page
+func pageContent(lines []string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "BT\n/F1 %d Tf\n%d TL\n%d %d Td\n", fontSize, lineHeight, marginLeft, pageHeight-marginTop)
+	for _, line := range lines {
+		fmt.Fprintf(&b, "(%s) '\n", escapeText(line))
+	}
+	b.WriteString("ET")
+	return b.String()
+}
+
+// escapeText escapes PDF string delimiters and replaces characters the
+// standard font encoding cannot show
+func escapeText(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '\\' || r == '(' || r == ')':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r < 0x20 || r > 0x7e:
+			b.WriteByte('?')
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,70 @@
+package pdf
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/pmaojo/kthulu-go/backend/internal/domain"
+)
+
+func TestRenderPDFWritesInvoiceDocument(t *testing.T) {
+	invoice := &domain.Invoice{
+		InvoiceNumber: "INV-0042",
+		Type:          domain.InvoiceTypeInvoice,
+		Currency:      "EUR",
+		IssueDate:     time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+		TotalAmount:   121,
+		Items:         []domain.InvoiceItem{{Description: "Support (March)", Quantity: 1, UnitPrice: 100, LineTotal: 100}},
+	}
+
+	doc, err := NewInvoiceRenderer().RenderPDF(context.Background(), invoice)
+	if err != nil {
+		t.Fatalf("render: %v", err)
+	}
+
+	if !bytes.HasPrefix(doc, []byte("%PDF-1.4\n")) || !bytes.HasSuffix(doc, []byte("%%EOF\n")) {
+		t.Fatalf("missing PDF header or trailer")
+	}
+	for _, want := range []string{"(INVOICE INV-0042) '", "Support \\(March\\)", "Issue date: 2024-03-01"} {
+		if !bytes.Contains(doc, []byte(want)) {
+			t.Fatalf("expected %q in document", want)
+		}
+	}
+
+	// The xref table must point at the start of every object
+	start := regexp.MustCompile(`startxref\n(\d+)`).FindSubmatch(doc)
+	if start == nil {
+		t.Fatalf("missing startxref")
+	}
+	xref, _ := strconv.Atoi(string(start[1]))
+	offsets := regexp.MustCompile(`(\d{10}) 00000 n`).FindAllSubmatch(doc[xref:], -1)
+	if len(offsets) != 5 {
+		t.Fatalf("expected 5 objects for a single page, got %d", len(offsets))
+	}
+	for i, match := range offsets {
+		offset, _ := strconv.Atoi(string(match[1]))
+		if want := fmt.Sprintf("%d 0 obj", i+1); !bytes.HasPrefix(doc[offset:], []byte(want)) {
+			t.Fatalf("xref entry %d does not point at %q", i+1, want)
+		}
+	}
+}
+
+func TestRenderPDFSplitsLongInvoicesIntoPages(t *testing.T) {
+	invoice := &domain.Invoice{InvoiceNumber: "INV-1", Type: domain.InvoiceTypeInvoice}
+	for i := 0; i < 2*linesPerPage; i++ {
+		invoice.Items = append(invoice.Items, domain.InvoiceItem{Description: fmt.Sprintf("Line %d", i)})
+	}
+
+	doc, err := NewInvoiceRenderer().RenderPDF(context.Background(), invoice)
+	if err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	if !bytes.Contains(doc, []byte("/Count 3")) {
+		t.Fatalf("expected items to flow over three pages")
+	}
+}
@@ -0,0 +1,194 @@
+// @kthulu:core
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pmaojo/kthulu-go/backend/core"
+	"github.com/pmaojo/kthulu-go/backend/internal/domain/repository"
+)
+
+var (
+	// ErrInvalidBlobKey is returned for keys that are empty or escape the store
+	ErrInvalidBlobKey = errors.New("invalid blob key")
+	// ErrURLSigningDisabled is returned by SignedURL when no signing key is set
+	ErrURLSigningDisabled = errors.New("blob URL signing is not configured")
+)
+
+// LocalBlobStore implements BlobStore on the local filesystem. Blobs are
+// written below dir and their URLs are built from publicURL. Serving dir
+// statically exposes every blob; mount the store itself as an http.Handler
+// at publicURL to only serve public blobs and signed URLs.
+type LocalBlobStore struct {
+	dir        string
+	publicURL  string
+	signingKey []byte
+	now        func() time.Time
+}
+
+// NewLocalBlobStore creates a blob store writing below dir. URLs are only
+// signed when signingKey is set.
+func NewLocalBlobStore(dir, publicURL, signingKey string) *LocalBlobStore {
+	return &LocalBlobStore{
+		dir:        dir,
+		publicURL:  strings.TrimSuffix(publicURL, "/"),
+		signingKey: []byte(signingKey),
+		now:        time.Now,
+	}
+}
+
+// NewBlobStore builds the BlobStore selected by the storage configuration
+func NewBlobStore(cfg *core.Config) (repository.BlobStore, error) {
+	switch cfg.Storage.Driver {
+	case "", "local":
+		return NewLocalBlobStore(cfg.Storage.LocalDir, cfg.Storage.PublicURL, cfg.Storage.SigningKey), nil
+	case "s3":
+		return NewS3BlobStore(cfg.Storage.S3, cfg.Storage.PublicURL), nil
+	default:
+		return nil, fmt.Errorf("unsupported storage driver: %s (supported: local, s3)", cfg.Storage.Driver)
+	}
+}
+
+// Put writes content to dir/key through a temporary file so readers never
+// see a partial blob
+func (s *LocalBlobStore) Put(ctx context.Context, key, contentType string, content []byte) (string, error) {
+	target, err := s.path(key)
+	if err != nil {
+		return "", err
+	}
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return "", fmt.Errorf("failed to create blob directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(target), ".upload-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create blob file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		return "", fmt.Errorf("failed to write blob file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return "", fmt.Errorf("failed to write blob file: %w", err)
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return "", fmt.Errorf("failed to write blob file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), target); err != nil {
+		return "", fmt.Errorf("failed to store blob file: %w", err)
+	}
+
+	return s.url(key), nil
+}
+
+// Get reads dir/key
+func (s *LocalBlobStore) Get(ctx context.Context, key string) ([]byte, error) {
+	target, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	content, err := os.ReadFile(target)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, repository.ErrBlobNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read blob file: %w", err)
+	}
+	return content, nil
+}
+
+// Delete removes dir/key
+func (s *LocalBlobStore) Delete(ctx context.Context, key string) error {
+	target, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(target); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to delete blob file: %w", err)
+	}
+	return nil
+}
+
+// SignedURL returns the blob URL with an expiry and an HMAC of both, which
+// ServeHTTP checks
+func (s *LocalBlobStore) SignedURL(ctx context.Context, key string, ttl time.Duration) (string, error) {
+	if len(s.signingKey) == 0 {
+		return "", ErrURLSigningDisabled
+	}
+	if _, err := s.path(key); err != nil {
+		return "", err
+	}
+	expires := strconv.FormatInt(s.now().Add(ttl).Unix(), 10)
+	query := url.Values{"expires": {expires}, "signature": {s.signature(key, expires)}}
+	return s.url(key) + "?" + query.Encode(), nil
+}
+
+// ServeHTTP serves the blobs below repository.PublicBlobPrefix, and any blob
+// addressed by a URL from SignedURL until it expires. Paths are relative to
+// publicURL.
+func (s *LocalBlobStore) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	key := strings.TrimPrefix(r.URL.Path, "/")
+	if !strings.HasPrefix(key, repository.PublicBlobPrefix) && !s.validSignature(r, key) {
+		http.Error(w, "Invalid or expired link", http.StatusForbidden)
+		return
+	}
+
+	content, err := s.Get(r.Context(), key)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", http.DetectContentType(content))
+	w.Write(content)
+}
+
+// validSignature reports whether r carries an unexpired signature for key
+func (s *LocalBlobStore) validSignature(r *http.Request, key string) bool {
+	expires := r.URL.Query().Get("expires")
+	unix, err := strconv.ParseInt(expires, 10, 64)
+	return len(s.signingKey) > 0 && err == nil && s.now().Unix() <= unix &&
+		hmac.Equal([]byte(s.signature(key, expires)), []byte(r.URL.Query().Get("signature")))
+}
+
+func (s *LocalBlobStore) signature(key, expires string) string {
+	mac := hmac.New(sha256.New, s.signingKey)
+	mac.Write([]byte(key + "\n" + expires))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (s *LocalBlobStore) url(key string) string {
+	return s.publicURL + "/" + (&url.URL{Path: key}).EscapedPath()
+}
+
+// path resolves key below dir, rejecting keys that would escape it
+func (s *LocalBlobStore) path(key string) (string, error) {
+	if err := validateBlobKey(key); err != nil {
+		return "", err
+	}
+	return filepath.Join(s.dir, filepath.FromSlash(key)), nil
+}
+
+// validateBlobKey only accepts clean relative slash-separated keys
+func validateBlobKey(key string) error {
+	if key == "" || path.Clean("/"+key) != "/"+key {
+		return fmt.Errorf("%w: %q", ErrInvalidBlobKey, key)
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pmaojo/kthulu-go/backend/internal/domain/repository"
+)
+
+func TestLocalBlobStoreRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	store := NewLocalBlobStore(dir, "https://cdn.example.com/uploads/", "")
+
+	url, err := store.Put(ctx, "organizations/7/logo.png", "image/png", []byte("png"))
+	if err != nil {
+		t.Fatalf("put: %v", err)
+	}
+	if url != "https://cdn.example.com/uploads/organizations/7/logo.png" {
+		t.Fatalf("unexpected url %q", url)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "organizations", "7", "logo.png")); err != nil {
+		t.Fatalf("expected blob on disk: %v", err)
+	}
+
+	content, err := store.Get(ctx, "organizations/7/logo.png")
+	if err != nil || string(content) != "png" {
+		t.Fatalf("expected stored blob, got %q (%v)", content, err)
+	}
+
+	if err := store.Delete(ctx, "organizations/7/logo.png"); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if _, err := store.Get(ctx, "organizations/7/logo.png"); !errors.Is(err, repository.ErrBlobNotFound) {
+		t.Fatalf("expected ErrBlobNotFound after delete, got %v", err)
+	}
+	if err := store.Delete(ctx, "organizations/7/logo.png"); err != nil {
+		t.Fatalf("deleting a missing blob should succeed: %v", err)
+	}
+}
+
+func TestLocalBlobStoreRejectsEscapingKeys(t *testing.T) {
+	store := NewLocalBlobStore(t.TempDir(), "http://localhost/uploads", "")
+	for _, key := range []string{"", "../secret", "a/../../b", "/etc/passwd", "a//b", "a/"} {
+		if _, err := store.Put(context.Background(), key, "image/png", nil); !errors.Is(err, ErrInvalidBlobKey) {
+			t.Fatalf("expected ErrInvalidBlobKey for %q, got %v", key, err)
+		}
+	}
+}
+
+func TestLocalBlobStoreSignedURL(t *testing.T) {
+	ctx := context.Background()
+	store := NewLocalBlobStore(t.TempDir(), "http://localhost/uploads", "secret")
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	store.now = func() time.Time { return now }
+	if _, err := store.Put(ctx, "verifactu/export.zip", "application/zip", []byte("PK")); err != nil {
+		t.Fatalf("put: %v", err)
+	}
+
+	signed, err := store.SignedURL(ctx, "verifactu/export.zip", time.Hour)
+	if err != nil {
+		t.Fatalf("signed url: %v", err)
+	}
+	path := strings.TrimPrefix(signed, "http://localhost/uploads")
+
+	rr := httptest.NewRecorder()
+	store.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, path, nil))
+	if rr.Code != http.StatusOK || !bytes.Equal(rr.Body.Bytes(), []byte("PK")) {
+		t.Fatalf("expected blob through signed url, got %d %q", rr.Code, rr.Body.String())
+	}
+
+	tampered := strings.Replace(path, "export.zip", "other.zip", 1)
+	rr = httptest.NewRecorder()
+	store.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, tampered, nil))
+	if rr.Code != http.StatusForbidden {
+		t.Fatalf("expected tampered url to be rejected, got %d", rr.Code)
+	}
+
+	now = now.Add(2 * time.Hour)
+	rr = httptest.NewRecorder()
+	store.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, path, nil))
+	if rr.Code != http.StatusForbidden {
+		t.Fatalf("expected expired url to be rejected, got %d", rr.Code)
+	}
+
+	if _, err := NewLocalBlobStore(t.TempDir(), "http://localhost", "").SignedURL(ctx, "a.zip", time.Hour); !errors.Is(err, ErrURLSigningDisabled) {
+		t.Fatalf("expected ErrURLSigningDisabled without a key, got %v", err)
+	}
+}
+
+func TestLocalBlobStoreServesPublicBlobsWithoutSignature(t *testing.T) {
+	ctx := context.Background()
+	store := NewLocalBlobStore(t.TempDir(), "http://localhost/uploads", "")
+	publicURL, err := store.Put(ctx, "public/organizations/7/logo.png", "image/png", []byte("png"))
+	if err != nil {
+		t.Fatalf("put public: %v", err)
+	}
+	privateURL, err := store.Put(ctx, "verifactu/export.zip", "application/zip", []byte("PK"))
+	if err != nil {
+		t.Fatalf("put private: %v", err)
+	}
+
+	rr := httptest.NewRecorder()
+	store.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, strings.TrimPrefix(publicURL, "http://localhost/uploads"), nil))
+	if rr.Code != http.StatusOK || rr.Body.String() != "png" {
+		t.Fatalf("expected public blob at its url, got %d %q", rr.Code, rr.Body.String())
+	}
+
+	rr = httptest.NewRecorder()
+	store.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, strings.TrimPrefix(privateURL, "http://localhost/uploads"), nil))
+	if rr.Code != http.StatusForbidden {
+		t.Fatalf("expected unsigned private blob to be rejected, got %d", rr.Code)
+	}
+}
@@ -0,0 +1,83 @@
+// @kthulu:core
+package storage
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pmaojo/kthulu-go/backend/internal/domain/repository"
+)
+
+// MemoryAccessTokenDenylist implements AccessTokenDenylist in memory.
+// Revocations are lost on restart and are not shared between instances.
+type MemoryAccessTokenDenylist struct {
+	revoked map[string]time.Time
+	mutex   sync.RWMutex
+	now     func() time.Time
+}
+
+// NewMemoryAccessTokenDenylist creates a new memory-based access token denylist
+func NewMemoryAccessTokenDenylist() repository.AccessTokenDenylist {
+	denylist := newMemoryAccessTokenDenylist(time.Now)
+
+	// Start cleanup goroutine
+	go denylist.cleanupLoop()
+
+	return denylist
+}
+
+func newMemoryAccessTokenDenylist(now func() time.Time) *MemoryAccessTokenDenylist {
+	return &MemoryAccessTokenDenylist{
+		revoked: make(map[string]time.Time),
+		now:     now,
+	}
+}
+
+// Revoke denies the token until it expires
+func (m *MemoryAccessTokenDenylist) Revoke(ctx context.Context, jti string, expiresAt time.Time) error {
+	if !expiresAt.After(m.now()) {
+		return nil // already unusable
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.revoked[jti] = expiresAt
+	return nil
+}
+
+// IsRevoked reports whether the token has been revoked and has not expired yet
+func (m *MemoryAccessTokenDenylist) IsRevoked(ctx context.Context, jti string) (bool, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	expiresAt, exists := m.revoked[jti]
+	return exists && m.now().Before(expiresAt), nil
+}
+
+// removeExpired drops entries whose tokens have expired
+func (m *MemoryAccessTokenDenylist) removeExpired() int {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	now := m.now()
+	count := 0
+	for jti, expiresAt := range m.revoked {
+		if !now.Before(expiresAt) {
+			delete(m.revoked, jti)
+			count++
+		}
+	}
+	return count
+}
+
+// cleanupLoop runs periodic cleanup of expired revocations
+func (m *MemoryAccessTokenDenylist) cleanupLoop() {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		m.removeExpired()
+	}
+}
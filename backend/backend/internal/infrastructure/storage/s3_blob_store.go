@@ -0,0 +1,272 @@
+// @kthulu:core
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pmaojo/kthulu-go/backend/core"
+	"github.com/pmaojo/kthulu-go/backend/internal/domain/repository"
+)
+
+const (
+	s3Algorithm       = "AWS4-HMAC-SHA256"
+	s3UnsignedPayload = "UNSIGNED-PAYLOAD"
+	s3TimeFormat      = "20060102T150405Z"
+	// s3MaxPresignTTL is the longest expiry S3 accepts for presigned URLs
+	s3MaxPresignTTL = 7 * 24 * time.Hour
+)
+
+// S3BlobStore implements BlobStore against the S3 REST API, signing requests
+// with AWS Signature Version 4. It works with AWS and with S3-compatible
+// servers such as MinIO or Cloudflare R2.
+type S3BlobStore struct {
+	cfg       core.S3Config
+	publicURL string
+	client    *http.Client
+	now       func() time.Time
+}
+
+// NewS3BlobStore creates an S3 blob store. Blob URLs are built from
+// publicURL, or from the bucket URL when it is empty.
+func NewS3BlobStore(cfg core.S3Config, publicURL string) *S3BlobStore {
+	if cfg.Region == "" {
+		cfg.Region = "us-east-1"
+	}
+	if cfg.Endpoint == "" {
+		cfg.Endpoint = "https://s3." + cfg.Region + ".amazonaws.com"
+	}
+	cfg.Endpoint = strings.TrimSuffix(cfg.Endpoint, "/")
+	return &S3BlobStore{
+		cfg:       cfg,
+		publicURL: strings.TrimSuffix(publicURL, "/"),
+		client:    &http.Client{Timeout: 30 * time.Second},
+		now:       time.Now,
+	}
+}
+
+// Put uploads content with a PUT Object request
+func (s *S3BlobStore) Put(ctx context.Context, key, contentType string, content []byte) (string, error) {
+	if err := validateBlobKey(key); err != nil {
+		return "", err
+	}
+	headers := http.Header{}
+	if contentType != "" {
+		headers.Set("Content-Type", contentType)
+	}
+	resp, err := s.do(ctx, http.MethodPut, key, headers, content)
+	if err != nil {
+		return "", err
+	}
+	resp.Body.Close()
+
+	if s.publicURL != "" {
+		return s.publicURL + "/" + s3EscapePath(key), nil
+	}
+	return s.objectURL(key).String(), nil
+}
+
+// Get downloads a blob with a GET Object request
+func (s *S3BlobStore) Get(ctx context.Context, key string) ([]byte, error) {
+	if err := validateBlobKey(key); err != nil {
+		return nil, err
+	}
+	resp, err := s.do(ctx, http.MethodGet, key, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	return io.ReadAll(resp.Body)
+}
+
+// Delete removes a blob; S3 reports success for missing keys as well
+func (s *S3BlobStore) Delete(ctx context.Context, key string) error {
+	if err := validateBlobKey(key); err != nil {
+		return err
+	}
+	resp, err := s.do(ctx, http.MethodDelete, key, nil, nil)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// SignedURL returns a presigned GET Object URL
+func (s *S3BlobStore) SignedURL(ctx context.Context, key string, ttl time.Duration) (string, error) {
+	if err := validateBlobKey(key); err != nil {
+		return "", err
+	}
+	if ttl <= 0 || ttl > s3MaxPresignTTL {
+		return "", fmt.Errorf("presigned URL expiry must be between 1s and %s", s3MaxPresignTTL)
+	}
+
+	now := s.now().UTC()
+	target := s.objectURL(key)
+	query := url.Values{
+		"X-Amz-Algorithm":     {s3Algorithm},
+		"X-Amz-Credential":    {s.cfg.AccessKeyID + "/" + s.scope(now)},
+		"X-Amz-Date":          {now.Format(s3TimeFormat)},
+		"X-Amz-Expires":       {strconv.FormatInt(int64(ttl/time.Second), 10)},
+		"X-Amz-SignedHeaders": {"host"},
+	}
+	headers := http.Header{"Host": {target.Host}}
+	signature := s.sign(now, http.MethodGet, target.EscapedPath(), query, headers, s3UnsignedPayload)
+	query.Set("X-Amz-Signature", signature)
+	target.RawQuery = s3CanonicalQuery(query)
+	return target.String(), nil
+}
+
+// do sends a signed request for key and fails on any non-2xx status
+func (s *S3BlobStore) do(ctx context.Context, method, key string, headers http.Header, body []byte) (*http.Response, error) {
+	target := s.objectURL(key)
+	req, err := http.NewRequestWithContext(ctx, method, target.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	for name, values := range headers {
+		req.Header[name] = values
+	}
+
+	now := s.now().UTC()
+	payloadHash := s3Hash(body)
+	req.Header.Set("X-Amz-Date", now.Format(s3TimeFormat))
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	signed := req.Header.Clone()
+	signed.Set("Host", target.Host)
+	signature := s.sign(now, method, target.EscapedPath(), url.Values{}, signed, payloadHash)
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s3Algorithm, s.cfg.AccessKeyID, s.scope(now), s3SignedHeaders(signed), signature))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("s3 %s %s: %w", method, key, err)
+	}
+	if resp.StatusCode == http.StatusNotFound && method == http.MethodGet {
+		resp.Body.Close()
+		return nil, repository.ErrBlobNotFound
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		resp.Body.Close()
+		return nil, fmt.Errorf("s3 %s %s: %s: %s", method, key, resp.Status, strings.TrimSpace(string(detail)))
+	}
+	return resp, nil
+}
+
+// objectURL addresses key in the bucket, path-style or virtual-hosted
+func (s *S3BlobStore) objectURL(key string) *url.URL {
+	target, _ := url.Parse(s.cfg.Endpoint)
+	escaped := "/" + s3EscapePath(key)
+	if s.cfg.PathStyle {
+		escaped = "/" + s3EscapePath(s.cfg.Bucket) + escaped
+	} else {
+		target.Host = s.cfg.Bucket + "." + target.Host
+	}
+	target.Path, _ = url.PathUnescape(escaped)
+	target.RawPath = escaped
+	return target
+}
+
+func (s *S3BlobStore) scope(now time.Time) string {
+	return now.Format("20060102") + "/" + s.cfg.Region + "/s3/aws4_request"
+}
+
+// sign computes the Signature Version 4 signature of a request
+func (s *S3BlobStore) sign(now time.Time, method, escapedPath string, query url.Values, headers http.Header, payloadHash string) string {
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, strings.ToLower(name))
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + strings.TrimSpace(headers.Get(name)) + "\n")
+	}
+
+	canonicalRequest := strings.Join([]string{
+		method,
+		escapedPath,
+		s3CanonicalQuery(query),
+		canonicalHeaders.String(),
+		s3SignedHeaders(headers),
+		payloadHash,
+	}, "\n")
+	stringToSign := strings.Join([]string{s3Algorithm, now.Format(s3TimeFormat), s.scope(now), s3Hash([]byte(canonicalRequest))}, "\n")
+
+	key := s3HMAC([]byte("AWS4"+s.cfg.SecretAccessKey), now.Format("20060102"))
+	for _, part := range []string{s.cfg.Region, "s3", "aws4_request"} {
+		key = s3HMAC(key, part)
+	}
+	return hex.EncodeToString(s3HMAC(key, stringToSign))
+}
+
+func s3SignedHeaders(headers http.Header) string {
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, strings.ToLower(name))
+	}
+	sort.Strings(names)
+	return strings.Join(names, ";")
+}
+
+// s3CanonicalQuery encodes query sorted by key, escaping as SigV4 requires
+func s3CanonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		for _, value := range query[key] {
+			pairs = append(pairs, s3Escape(key, true)+"="+s3Escape(value, true))
+		}
+	}
+	return strings.Join(pairs, "&")
+}
+
+// s3EscapePath URI-encodes every segment of a key, keeping the slashes
+func s3EscapePath(key string) string {
+	return s3Escape(key, false)
+}
+
+// s3Escape percent-encodes everything but the RFC 3986 unreserved characters
+// and, unless encodeSlash is set, '/'
+func s3Escape(value string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9', c == '-', c == '.', c == '_', c == '~':
+			b.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+func s3Hash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func s3HMAC(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
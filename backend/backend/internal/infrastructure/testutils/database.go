@@ -19,7 +19,7 @@ import (
 
 // SetupTestDB creates a test database connection
 func SetupTestDB(t *testing.T) *gorm.DB {
-	gormDB, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
+	gormDB, err := gorm.Open(sqlite.New(sqlite.Config{DriverName: sqliteDriverName, DSN: ":memory:"}), &gorm.Config{
 		Logger: logger.Default.LogMode(logger.Silent),
 	})
 	require.NoError(t, err)
@@ -55,6 +55,8 @@ func SetupTestDB(t *testing.T) *gorm.DB {
                         role_id INTEGER DEFAULT 1,
                         confirmed_at DATETIME,
                         confirmation_code TEXT,
+                        confirmation_expires_at DATETIME,
+                        disabled_at DATETIME,
                         created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
                         updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
                 );
@@ -75,6 +77,7 @@ func SetupTestDB(t *testing.T) *gorm.DB {
                         country TEXT,
                         postal_code TEXT,
                         is_active INTEGER DEFAULT 1,
+                        invoice_number_format TEXT NOT NULL DEFAULT '',
                         created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
                         updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
                 );
@@ -102,7 +105,8 @@ func SetupTestDB(t *testing.T) *gorm.DB {
                         user_id INTEGER NOT NULL,
                         token TEXT UNIQUE NOT NULL,
                         expires_at DATETIME NOT NULL,
-                        created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+                        created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+                        access_token_id TEXT NOT NULL DEFAULT ''
                 );
 
                 INSERT OR IGNORE INTO roles (id, name, description) VALUES (1, 'user', 'Default user role');
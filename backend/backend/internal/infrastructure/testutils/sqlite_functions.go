@@ -0,0 +1,50 @@
+// @kthulu:core
+package testutils
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// sqliteDriverName is the SQLite driver SetupTestDB opens. Its connections
+// define the Postgres functions repositories rely on that SQLite lacks.
+const sqliteDriverName = "sqlite3_kthulu_test"
+
+func init() {
+	sql.Register(sqliteDriverName, &sqlite3.SQLiteDriver{
+		ConnectHook: func(conn *sqlite3.SQLiteConn) error {
+			return conn.RegisterFunc("date_trunc", dateTrunc, true)
+		},
+	})
+}
+
+// dateTrunc mirrors Postgres date_trunc for the day, week and month fields
+// on timestamps stored in UTC. Weeks start on Monday.
+func dateTrunc(field, value string) (string, error) {
+	var t time.Time
+	var err error
+	for _, format := range sqlite3.SQLiteTimestampFormats {
+		if t, err = time.ParseInLocation(format, value, time.UTC); err == nil {
+			break
+		}
+	}
+	if err != nil {
+		return "", fmt.Errorf("date_trunc: cannot parse %q", value)
+	}
+
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	switch field {
+	case "day":
+	case "week":
+		day = day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+	case "month":
+		day = day.AddDate(0, 0, 1-day.Day())
+	default:
+		return "", fmt.Errorf("date_trunc: unsupported field %q", field)
+	}
+	return day.Format("2006-01-02 15:04:05"), nil
+}
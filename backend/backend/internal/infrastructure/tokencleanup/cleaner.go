@@ -0,0 +1,80 @@
+// @kthulu:module:auth
+package tokencleanup
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pmaojo/kthulu-go/backend/core"
+	"github.com/pmaojo/kthulu-go/backend/internal/domain/repository"
+)
+
+// Cleaner periodically deletes refresh tokens that have expired, which
+// would otherwise accumulate forever.
+type Cleaner struct {
+	tokens   repository.RefreshTokenRepository
+	logger   core.Logger
+	interval time.Duration
+	now      func() time.Time
+
+	stop chan struct{}
+	done sync.WaitGroup
+}
+
+// NewCleaner creates a cleaner running at the configured interval
+func NewCleaner(tokens repository.RefreshTokenRepository, cfg *core.Config, logger core.Logger) *Cleaner {
+	return &Cleaner{
+		tokens:   tokens,
+		logger:   logger,
+		interval: cfg.JWT.RefreshTokenCleanupInterval,
+		now:      time.Now,
+	}
+}
+
+// CleanOnce deletes the tokens expired by now and returns how many were removed
+func (c *Cleaner) CleanOnce(ctx context.Context) (int64, error) {
+	deleted, err := c.tokens.DeleteExpired(ctx, c.now())
+	if err != nil {
+		return 0, err
+	}
+	if deleted > 0 {
+		c.logger.Info("Expired refresh tokens deleted", "count", deleted)
+	}
+	return deleted, nil
+}
+
+// Start cleans in the background until Stop is called. It does nothing when
+// the interval is not positive.
+func (c *Cleaner) Start() {
+	if c.interval <= 0 {
+		return
+	}
+	c.stop = make(chan struct{})
+	c.done.Add(1)
+	go func() {
+		defer c.done.Done()
+		ticker := time.NewTicker(c.interval)
+		defer ticker.Stop()
+		for {
+			if _, err := c.CleanOnce(context.Background()); err != nil {
+				c.logger.Error("Refresh token cleanup failed", "error", err)
+			}
+			select {
+			case <-c.stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop ends cleaning and waits for the current run to finish
+func (c *Cleaner) Stop() {
+	if c.stop == nil {
+		return
+	}
+	close(c.stop)
+	c.done.Wait()
+	c.stop = nil
+}
@@ -0,0 +1,51 @@
+package tokencleanup
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/pmaojo/kthulu-go/backend/core"
+	"github.com/pmaojo/kthulu-go/backend/internal/domain"
+	"github.com/pmaojo/kthulu-go/backend/internal/infrastructure/db"
+	"github.com/pmaojo/kthulu-go/backend/internal/infrastructure/testutils"
+)
+
+func TestCleanerCleanOnceDeletesExpiredTokens(t *testing.T) {
+	testDB := testutils.SetupTestDB(t)
+	defer testutils.CleanupTestDB(t, testDB)
+
+	repo := db.NewRefreshTokenRepository(testDB)
+	ctx := context.Background()
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	for token, expiresAt := range map[string]time.Time{"old": now.Add(-time.Minute), "fresh": now.Add(time.Minute)} {
+		if err := repo.Create(ctx, &domain.RefreshToken{UserID: 1, Token: token, ExpiresAt: expiresAt}); err != nil {
+			t.Fatalf("create %s: %v", token, err)
+		}
+	}
+
+	cleaner := NewCleaner(repo, &core.Config{JWT: core.JWTConfig{RefreshTokenCleanupInterval: time.Hour}}, core.NewLoggerFromZap(zap.NewNop()))
+	cleaner.now = func() time.Time { return now }
+
+	deleted, err := cleaner.CleanOnce(ctx)
+	if err != nil {
+		t.Fatalf("clean: %v", err)
+	}
+	if deleted != 1 {
+		t.Fatalf("expected 1 expired token deleted, got %d", deleted)
+	}
+	if _, err := repo.FindByToken(ctx, "fresh"); err != nil {
+		t.Fatalf("unexpired token was deleted: %v", err)
+	}
+}
+
+func TestCleanerStartIsNoopWhenDisabled(t *testing.T) {
+	cleaner := NewCleaner(nil, &core.Config{}, core.NewLoggerFromZap(zap.NewNop()))
+	cleaner.Start()
+	if cleaner.stop != nil {
+		t.Fatalf("expected a zero interval to leave the cleaner stopped")
+	}
+	cleaner.Stop()
+}
@@ -0,0 +1,35 @@
+// @kthulu:module:auth
+package tokencleanup
+
+import (
+	"context"
+
+	"go.uber.org/fx"
+
+	"github.com/pmaojo/kthulu-go/backend/core"
+)
+
+// Module runs the refresh token cleaner for the lifetime of the application.
+var Module = fx.Options(
+	fx.Provide(NewCleaner),
+	fx.Invoke(registerCleaner),
+)
+
+// registerCleaner starts the cleaner with the application and stops it on shutdown
+func registerCleaner(lc fx.Lifecycle, cleaner *Cleaner, logger core.Logger) {
+	lc.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			if cleaner.interval <= 0 {
+				logger.Info("Refresh token cleanup disabled")
+				return nil
+			}
+			cleaner.Start()
+			logger.Info("Refresh token cleanup started", "interval", cleaner.interval.String())
+			return nil
+		},
+		OnStop: func(context.Context) error {
+			cleaner.Stop()
+			return nil
+		},
+	})
+}
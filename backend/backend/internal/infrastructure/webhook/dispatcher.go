@@ -0,0 +1,166 @@
+// @kthulu:module:webhooks
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/pmaojo/kthulu-go/backend/core"
+	"github.com/pmaojo/kthulu-go/backend/internal/domain"
+	"github.com/pmaojo/kthulu-go/backend/internal/domain/repository"
+)
+
+const (
+	// SignatureHeader carries the hex HMAC-SHA256 of the request body,
+	// keyed with the endpoint secret and prefixed with "sha256=".
+	SignatureHeader = "X-Kthulu-Signature"
+	// EventHeader carries the name of the delivered event
+	EventHeader = "X-Kthulu-Event"
+
+	defaultRequestTimeout = 10 * time.Second
+)
+
+// Payload is the JSON document POSTed to webhook endpoints
+type Payload struct {
+	Event          domain.WebhookEvent `json:"event"`
+	OrganizationID uint                `json:"organizationId"`
+	OccurredAt     time.Time           `json:"occurredAt"`
+	Data           interface{}         `json:"data"`
+}
+
+// WebhookDispatcher delivers events to the webhook endpoints registered by an
+// organization. Every attempt is recorded in the repository; retrying failed
+// deliveries is left to the outbox relay.
+type WebhookDispatcher struct {
+	webhooks repository.WebhookRepository
+	client   *http.Client
+	logger   core.Logger
+}
+
+// NewWebhookDispatcher creates a dispatcher with the default request timeout
+func NewWebhookDispatcher(webhooks repository.WebhookRepository, logger core.Logger) *WebhookDispatcher {
+	return &WebhookDispatcher{
+		webhooks: webhooks,
+		client:   &http.Client{Timeout: defaultRequestTimeout},
+		logger:   logger,
+	}
+}
+
+// encodePayload lists the endpoints subscribed to the event and encodes the
+// payload they receive
+func (d *WebhookDispatcher) encodePayload(ctx context.Context, organizationID uint, event domain.WebhookEvent, data interface{}) ([]*domain.WebhookEndpoint, []byte, error) {
+	endpoints, err := d.webhooks.ListEndpoints(ctx, organizationID, event)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list webhook endpoints: %w", err)
+	}
+	if len(endpoints) == 0 {
+		return nil, nil, nil
+	}
+
+	body, err := json.Marshal(Payload{
+		Event:          event,
+		OrganizationID: organizationID,
+		OccurredAt:     time.Now().UTC(),
+		Data:           data,
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to encode webhook payload: %w", err)
+	}
+	return endpoints, body, nil
+}
+
+// Deliver sends the event once to every subscribed endpoint of the
+// organization and waits for the answers. It fails unless every endpoint
+// answered 2xx, leaving retries to the caller; endpoints that already
+// acknowledged receive the event again on a retry.
+func (d *WebhookDispatcher) Deliver(ctx context.Context, organizationID uint, event domain.WebhookEvent, data interface{}) error {
+	endpoints, body, err := d.encodePayload(ctx, organizationID, event, data)
+	if err != nil || len(endpoints) == 0 {
+		return err
+	}
+
+	errs := make([]error, len(endpoints))
+	var wg sync.WaitGroup
+	for i, endpoint := range endpoints {
+		wg.Add(1)
+		go func(i int, endpoint *domain.WebhookEndpoint) {
+			defer wg.Done()
+			if !d.attempt(ctx, endpoint, event, body, 1) {
+				errs[i] = fmt.Errorf("endpoint %d did not acknowledge %s", endpoint.ID, event)
+			}
+		}(i, endpoint)
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// attempt sends the payload once, records the attempt and reports whether
+// the endpoint acknowledged it
+func (d *WebhookDispatcher) attempt(ctx context.Context, endpoint *domain.WebhookEndpoint, event domain.WebhookEvent, body []byte, attempt int) bool {
+	delivery := &domain.WebhookDelivery{
+		EndpointID:     endpoint.ID,
+		OrganizationID: endpoint.OrganizationID,
+		Event:          event,
+		Payload:        string(body),
+		Attempt:        attempt,
+	}
+
+	statusCode, err := d.send(ctx, endpoint, event, body)
+	delivery.StatusCode = statusCode
+	if err != nil {
+		delivery.Error = err.Error()
+	} else {
+		delivery.Delivered = true
+	}
+
+	if recordErr := d.webhooks.RecordDelivery(ctx, delivery); recordErr != nil {
+		d.logger.Error("Failed to record webhook delivery", "error", recordErr, "endpointId", endpoint.ID)
+	}
+	if delivery.Delivered {
+		d.logger.Info("Webhook delivered", "endpointId", endpoint.ID, "event", event, "attempt", attempt)
+		return true
+	}
+	d.logger.Warn("Webhook delivery failed", "endpointId", endpoint.ID, "event", event, "attempt", attempt, "error", err)
+	return false
+}
+
+// send performs a single signed delivery request
+func (d *WebhookDispatcher) send(ctx context.Context, endpoint *domain.WebhookEndpoint, event domain.WebhookEvent, body []byte) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("failed to build webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, string(event))
+	req.Header.Set(SignatureHeader, "sha256="+Sign(endpoint.Secret, body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("endpoint responded with status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// Sign returns the hex HMAC-SHA256 of body keyed with secret, so receivers
+// can verify the SignatureHeader of a delivery.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,142 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/pmaojo/kthulu-go/backend/core"
+	"github.com/pmaojo/kthulu-go/backend/internal/domain"
+)
+
+type fakeWebhookRepository struct {
+	mu         sync.Mutex
+	endpoints  []*domain.WebhookEndpoint
+	deliveries []*domain.WebhookDelivery
+}
+
+func (f *fakeWebhookRepository) CreateEndpoint(ctx context.Context, endpoint *domain.WebhookEndpoint) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	endpoint.ID = uint(len(f.endpoints) + 1)
+	f.endpoints = append(f.endpoints, endpoint)
+	return nil
+}
+
+func (f *fakeWebhookRepository) ListEndpoints(ctx context.Context, organizationID uint, event domain.WebhookEvent) ([]*domain.WebhookEndpoint, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var endpoints []*domain.WebhookEndpoint
+	for _, endpoint := range f.endpoints {
+		if endpoint.OrganizationID == organizationID && endpoint.Active && endpoint.Subscribes(event) {
+			endpoints = append(endpoints, endpoint)
+		}
+	}
+	return endpoints, nil
+}
+
+func (f *fakeWebhookRepository) RecordDelivery(ctx context.Context, delivery *domain.WebhookDelivery) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delivery.ID = uint(len(f.deliveries) + 1)
+	f.deliveries = append(f.deliveries, delivery)
+	return nil
+}
+
+func (f *fakeWebhookRepository) ListDeliveries(ctx context.Context, endpointID uint) ([]*domain.WebhookDelivery, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var deliveries []*domain.WebhookDelivery
+	for _, delivery := range f.deliveries {
+		if delivery.EndpointID == endpointID {
+			deliveries = append(deliveries, delivery)
+		}
+	}
+	return deliveries, nil
+}
+
+func newTestDispatcher(t *testing.T, url string) (*WebhookDispatcher, *fakeWebhookRepository) {
+	t.Helper()
+
+	repo := &fakeWebhookRepository{}
+	endpoint, err := domain.NewWebhookEndpoint(1, url, "s3cret", []domain.WebhookEvent{domain.WebhookEventInvoicePaid})
+	if err != nil {
+		t.Fatalf("new endpoint: %v", err)
+	}
+	repo.CreateEndpoint(context.Background(), endpoint)
+
+	return NewWebhookDispatcher(repo, core.NewLoggerFromZap(zap.NewNop())), repo
+}
+
+func TestDispatcherDeliversSignedPayload(t *testing.T) {
+	var received atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if got, want := r.Header.Get(SignatureHeader), "sha256="+Sign("s3cret", body); got != want {
+			t.Errorf("expected signature %q, got %q", want, got)
+		}
+		if got := r.Header.Get(EventHeader); got != string(domain.WebhookEventInvoicePaid) {
+			t.Errorf("unexpected event header %q", got)
+		}
+		var payload Payload
+		if err := json.Unmarshal(body, &payload); err != nil || payload.OrganizationID != 1 {
+			t.Errorf("unexpected payload %s: %v", body, err)
+		}
+		received.Add(1)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	d, repo := newTestDispatcher(t, server.URL)
+	if err := d.Deliver(context.Background(), 1, domain.WebhookEventInvoicePaid, map[string]uint{"invoiceId": 7}); err != nil {
+		t.Fatalf("deliver: %v", err)
+	}
+
+	if received.Load() != 1 {
+		t.Fatalf("expected one request, got %d", received.Load())
+	}
+	deliveries, _ := repo.ListDeliveries(context.Background(), 1)
+	if len(deliveries) != 1 || !deliveries[0].Delivered || deliveries[0].StatusCode != http.StatusNoContent {
+		t.Fatalf("expected one successful delivery recorded, got %+v", deliveries)
+	}
+}
+
+func TestDispatcherSkipsUnsubscribedEvents(t *testing.T) {
+	d, repo := newTestDispatcher(t, "http://127.0.0.1:1")
+	if err := d.Deliver(context.Background(), 1, domain.WebhookEventInvoiceOverdue, nil); err != nil {
+		t.Fatalf("deliver: %v", err)
+	}
+
+	if len(repo.deliveries) != 0 {
+		t.Fatalf("expected no deliveries, got %d", len(repo.deliveries))
+	}
+}
+
+func TestDispatcherDeliverReportsAcknowledgement(t *testing.T) {
+	var status atomic.Int32
+	status.Store(http.StatusServiceUnavailable)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(int(status.Load()))
+	}))
+	defer server.Close()
+
+	d, repo := newTestDispatcher(t, server.URL)
+	if err := d.Deliver(context.Background(), 1, domain.WebhookEventInvoicePaid, nil); err == nil {
+		t.Fatal("expected an unacknowledged delivery to fail")
+	}
+	if len(repo.deliveries) != 1 {
+		t.Fatalf("expected a single attempt, got %d", len(repo.deliveries))
+	}
+
+	status.Store(http.StatusOK)
+	if err := d.Deliver(context.Background(), 1, domain.WebhookEventInvoicePaid, nil); err != nil {
+		t.Fatalf("expected an acknowledged delivery, got %v", err)
+	}
+}
@@ -0,0 +1,19 @@
+// @kthulu:module:webhooks
+package webhook
+
+import (
+	"go.uber.org/fx"
+
+	"github.com/pmaojo/kthulu-go/backend/internal/domain/repository"
+)
+
+// Module provides the webhook dispatcher for Fx dependency injection as the
+// synchronous deliverer used by the outbox relay.
+var Module = fx.Options(
+	fx.Provide(
+		fx.Annotate(
+			NewWebhookDispatcher,
+			fx.As(new(repository.WebhookDeliverer)),
+		),
+	),
+)
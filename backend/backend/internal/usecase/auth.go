@@ -23,17 +23,28 @@ type AuthUseCase struct {
 	refreshTokens repository.RefreshTokenRepository
 	roles         repository.RoleRepository
 	tokens        core.TokenManager
+	denylist      repository.AccessTokenDenylist
+	events        repository.AuthEventRepository
 	notifier      repository.NotificationProvider
+	invitations   repository.InvitationRepository
+	organizations repository.OrganizationRepository
 	logger        core.Logger
 }
 
+// ConfirmationCodeTTL is how long a confirmation code is accepted after it is sent
+const ConfirmationCodeTTL = 24 * time.Hour
+
 // NewAuthUseCase builds an AuthUseCase instance.
 func NewAuthUseCase(
 	users repository.UserRepository,
 	refreshTokens repository.RefreshTokenRepository,
 	roles repository.RoleRepository,
 	tokens core.TokenManager,
+	denylist repository.AccessTokenDenylist,
+	events repository.AuthEventRepository,
 	notifier repository.NotificationProvider,
+	invitations repository.InvitationRepository,
+	organizations repository.OrganizationRepository,
 	logger core.Logger,
 ) *AuthUseCase {
 	return &AuthUseCase{
@@ -41,7 +52,11 @@ func NewAuthUseCase(
 		refreshTokens: refreshTokens,
 		roles:         roles,
 		tokens:        tokens,
+		denylist:      denylist,
+		events:        events,
 		notifier:      notifier,
+		invitations:   invitations,
+		organizations: organizations,
 		logger:        logger,
 	}
 }
@@ -128,7 +143,7 @@ func (a *AuthUseCase) Register(ctx context.Context, req RegisterRequest) (*AuthR
 		a.logger.Error("Failed to generate confirmation code", "email", req.Email, "error", err)
 		// Don't fail registration if code generation fails
 	} else {
-		user.ConfirmationCode = confirmationCode
+		user.SetConfirmationCode(confirmationCode, ConfirmationCodeTTL)
 	}
 
 	// Persist user
@@ -139,7 +154,7 @@ func (a *AuthUseCase) Register(ctx context.Context, req RegisterRequest) (*AuthR
 
 	// Send confirmation email if we have a code
 	if confirmationCode != "" {
-		if err := a.notifier.SendEmailConfirmation(ctx, req.Email, confirmationCode); err != nil {
+		if err := a.notifier.SendEmailConfirmation(a.confirmationScope(ctx, user), req.Email, confirmationCode); err != nil {
 			a.logger.Error("Failed to send confirmation email", "userId", user.ID, "email", req.Email, "error", err)
 			// Don't fail registration if email sending fails
 		} else {
@@ -164,9 +179,12 @@ type LoginRequest struct {
 }
 
 // Login authenticates an existing user and returns access and refresh tokens.
-func (a *AuthUseCase) Login(ctx context.Context, req LoginRequest) (*AuthResponse, error) {
+func (a *AuthUseCase) Login(ctx context.Context, req LoginRequest) (response *AuthResponse, err error) {
 	ctx, span := startUseCaseSpan(ctx, "AuthUseCase.Login")
 	defer span.End()
+	defer func() {
+		a.recordAuthEvent(ctx, domain.AuthEventLoginSucceeded, domain.AuthEventLoginFailed, req.Email, response, err)
+	}()
 
 	a.logger.Info("User login attempt", "email", req.Email)
 
@@ -181,10 +199,10 @@ func (a *AuthUseCase) Login(ctx context.Context, req LoginRequest) (*AuthRespons
 		return nil, fmt.Errorf("failed to find user: %w", err)
 	}
 
-	// Check if user can login (must be confirmed)
+	// Check if user can login (must be confirmed and not disabled)
 	if !user.CanLogin() {
-		a.logger.Warn("Login attempt for unconfirmed user", "userId", user.ID, "email", req.Email)
-		return nil, domain.ErrUserNotConfirmed
+		a.logger.Warn("Login attempt for user who cannot log in", "userId", user.ID, "email", req.Email, "disabled", user.IsDisabled())
+		return nil, user.LoginError()
 	}
 
 	// Verify password
@@ -214,7 +232,7 @@ func (a *AuthUseCase) Login(ctx context.Context, req LoginRequest) (*AuthRespons
 		User:         user,
 		AccessToken:  accessToken,
 		RefreshToken: refreshTokenStr,
-		ExpiresIn:    int64(a.tokens.GetAccessTokenTTL().Seconds()),
+		ExpiresIn:    int64(a.tokens.AccessTokenTTLForRole(user.RoleName()).Seconds()),
 	}, nil
 }
 
@@ -225,9 +243,12 @@ type ConfirmRequest struct {
 }
 
 // Confirm marks a user as confirmed and returns authentication tokens.
-func (a *AuthUseCase) Confirm(ctx context.Context, req ConfirmRequest) (*AuthResponse, error) {
+func (a *AuthUseCase) Confirm(ctx context.Context, req ConfirmRequest) (response *AuthResponse, err error) {
 	ctx, span := startUseCaseSpan(ctx, "AuthUseCase.Confirm")
 	defer span.End()
+	defer func() {
+		a.recordAuthEvent(ctx, domain.AuthEventConfirmSucceeded, domain.AuthEventConfirmFailed, req.Email, response, err)
+	}()
 
 	a.logger.Info("User email confirmation attempt", "email", req.Email)
 
@@ -242,6 +263,12 @@ func (a *AuthUseCase) Confirm(ctx context.Context, req ConfirmRequest) (*AuthRes
 		return nil, fmt.Errorf("failed to find user: %w", err)
 	}
 
+	// A disabled user neither gets confirmed nor signed in
+	if user.IsDisabled() {
+		a.logger.Warn("Confirmation attempt for disabled user", "userId", user.ID, "email", req.Email)
+		return nil, domain.ErrUserDisabled
+	}
+
 	// Check if user is already confirmed
 	if user.IsConfirmed() {
 		a.logger.Info("User already confirmed", "userId", user.ID, "email", req.Email)
@@ -254,6 +281,10 @@ func (a *AuthUseCase) Confirm(ctx context.Context, req ConfirmRequest) (*AuthRes
 		a.logger.Warn("Invalid confirmation code provided", "email", req.Email)
 		return nil, errors.New("invalid confirmation code")
 	}
+	if user.ConfirmationExpired() {
+		a.logger.Warn("Expired confirmation code provided", "email", req.Email)
+		return nil, domain.ErrConfirmationCodeExpired
+	}
 
 	// Use domain method to confirm user (also clears confirmation code)
 	user.Confirm()
@@ -285,7 +316,7 @@ func (a *AuthUseCase) Confirm(ctx context.Context, req ConfirmRequest) (*AuthRes
 		User:         user,
 		AccessToken:  accessToken,
 		RefreshToken: refreshTokenStr,
-		ExpiresIn:    int64(a.tokens.GetAccessTokenTTL().Seconds()),
+		ExpiresIn:    int64(a.tokens.AccessTokenTTLForRole(user.RoleName()).Seconds()),
 	}, nil
 }
 
@@ -295,9 +326,12 @@ type RefreshRequest struct {
 }
 
 // Refresh validates a refresh token and returns new access and refresh tokens.
-func (a *AuthUseCase) Refresh(ctx context.Context, req RefreshRequest) (*AuthResponse, error) {
+func (a *AuthUseCase) Refresh(ctx context.Context, req RefreshRequest) (response *AuthResponse, err error) {
 	ctx, span := startUseCaseSpan(ctx, "AuthUseCase.Refresh")
 	defer span.End()
+	defer func() {
+		a.recordAuthEvent(ctx, domain.AuthEventRefreshSucceeded, domain.AuthEventRefreshFailed, "", response, err)
+	}()
 
 	a.logger.Info("Token refresh attempt")
 
@@ -354,8 +388,8 @@ func (a *AuthUseCase) Refresh(ctx context.Context, req RefreshRequest) (*AuthRes
 
 	// Check if user can still login
 	if !user.CanLogin() {
-		a.logger.Warn("Refresh token used for unconfirmed user", "userId", userID)
-		return nil, domain.ErrUserNotConfirmed
+		a.logger.Warn("Refresh token used for user who cannot log in", "userId", userID, "disabled", user.IsDisabled())
+		return nil, user.LoginError()
 	}
 
 	// Load user role
@@ -366,11 +400,13 @@ func (a *AuthUseCase) Refresh(ctx context.Context, req RefreshRequest) (*AuthRes
 	}
 	user.Role = role
 
-	// Delete old refresh token
+	// Delete old refresh token. Its access token is revoked with it, as
+	// nothing would be left to revoke it by when the user's sessions end.
 	if err := a.refreshTokens.Delete(ctx, refreshToken.ID); err != nil {
 		a.logger.Error("Failed to delete old refresh token", "tokenId", refreshToken.ID, "error", err)
 		// Continue anyway, as this is not critical
 	}
+	a.revokeSessionAccessToken(ctx, refreshToken)
 
 	// Generate new token pair
 	accessToken, newRefreshTokenStr, err := a.generateTokenPair(ctx, user)
@@ -385,22 +421,30 @@ func (a *AuthUseCase) Refresh(ctx context.Context, req RefreshRequest) (*AuthRes
 		User:         user,
 		AccessToken:  accessToken,
 		RefreshToken: newRefreshTokenStr,
-		ExpiresIn:    int64(a.tokens.GetAccessTokenTTL().Seconds()),
+		ExpiresIn:    int64(a.tokens.AccessTokenTTLForRole(user.RoleName()).Seconds()),
 	}, nil
 }
 
 // LogoutRequest contains the data needed to logout a user
 type LogoutRequest struct {
 	RefreshToken string `json:"refreshToken" validate:"required"`
+	// AccessToken is the bearer token of the request, revoked when present
+	AccessToken string `json:"-"`
 }
 
-// Logout invalidates the user's refresh token.
+// Logout invalidates the user's refresh token and, when given, its access token.
 func (a *AuthUseCase) Logout(ctx context.Context, req LogoutRequest) error {
 	ctx, span := startUseCaseSpan(ctx, "AuthUseCase.Logout")
 	defer span.End()
 
 	a.logger.Info("User logout attempt")
 
+	if req.AccessToken != "" {
+		if err := a.RevokeAccessToken(ctx, req.AccessToken); err != nil {
+			a.logger.Warn("Failed to revoke access token during logout", "error", err)
+		}
+	}
+
 	// Validate token and extract jti
 	claims, err := a.tokens.ValidateRefreshToken(req.RefreshToken)
 	if err != nil {
@@ -436,10 +480,21 @@ func (a *AuthUseCase) Logout(ctx context.Context, req LogoutRequest) error {
 	return nil
 }
 
-// LogoutAll invalidates all refresh tokens for a user.
+// LogoutAll invalidates all refresh tokens for a user and revokes the access
+// tokens issued with them.
 func (a *AuthUseCase) LogoutAll(ctx context.Context, userID uint) error {
 	a.logger.Info("User logout all attempt", "userId", userID)
 
+	// Revoke the access tokens of the sessions before deleting them
+	sessions, err := a.refreshTokens.FindByUserID(ctx, userID)
+	if err != nil {
+		a.logger.Error("Failed to list user sessions", "userId", userID, "error", err)
+		return fmt.Errorf("failed to list refresh tokens: %w", err)
+	}
+	for _, session := range sessions {
+		a.revokeSessionAccessToken(ctx, session)
+	}
+
 	// Delete all refresh tokens for the user
 	if err := a.refreshTokens.DeleteByUserID(ctx, userID); err != nil {
 		a.logger.Error("Failed to delete all refresh tokens", "userId", userID, "error", err)
@@ -450,28 +505,178 @@ func (a *AuthUseCase) LogoutAll(ctx context.Context, userID uint) error {
 	return nil
 }
 
-// generateTokenPair creates both access and refresh tokens for a user
+// revokeSessionAccessToken denies the access token issued with a refresh
+// token. The refresh token outlives it, so its expiry bounds the entry.
+func (a *AuthUseCase) revokeSessionAccessToken(ctx context.Context, session *domain.RefreshToken) {
+	if a.denylist == nil || session.AccessTokenID == "" {
+		return
+	}
+	if err := a.denylist.Revoke(ctx, session.AccessTokenID, session.ExpiresAt); err != nil {
+		a.logger.Warn("Failed to revoke session access token", "userId", session.UserID, "tokenId", session.ID, "error", err)
+	}
+}
+
+// DisableUser stops a user from logging in without deleting the account and
+// ends all of their sessions.
+func (a *AuthUseCase) DisableUser(ctx context.Context, userID uint) error {
+	a.logger.Info("Disable user request", "userId", userID)
+
+	user, err := a.users.FindByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user.IsDisabled() {
+		return nil
+	}
+
+	user.Disable()
+	if err := a.users.Update(ctx, user); err != nil {
+		a.logger.Error("Failed to disable user", "userId", userID, "error", err)
+		return fmt.Errorf("failed to disable user: %w", err)
+	}
+
+	if err := a.LogoutAll(ctx, userID); err != nil {
+		return err
+	}
+
+	a.logger.Info("User disabled", "userId", userID)
+	return nil
+}
+
+// EnableUser lets a disabled user log in again.
+func (a *AuthUseCase) EnableUser(ctx context.Context, userID uint) error {
+	a.logger.Info("Enable user request", "userId", userID)
+
+	user, err := a.users.FindByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !user.IsDisabled() {
+		return nil
+	}
+
+	user.Enable()
+	if err := a.users.Update(ctx, user); err != nil {
+		a.logger.Error("Failed to enable user", "userId", userID, "error", err)
+		return fmt.Errorf("failed to enable user: %w", err)
+	}
+
+	a.logger.Info("User enabled", "userId", userID)
+	return nil
+}
+
+// RevokeAccessToken denies an access token until it expires.
+func (a *AuthUseCase) RevokeAccessToken(ctx context.Context, tokenStr string) error {
+	if a.denylist == nil {
+		return errors.New("access token denylist not available")
+	}
+
+	claims, err := a.tokens.ValidateAccessToken(tokenStr)
+	if err != nil {
+		return fmt.Errorf("invalid access token: %w", err)
+	}
+	jti, ok := claims["jti"].(string)
+	if !ok {
+		return domain.ErrInvalidToken
+	}
+	expiresAt, err := claims.GetExpirationTime()
+	if err != nil || expiresAt == nil {
+		return domain.ErrInvalidToken
+	}
+
+	if err := a.denylist.Revoke(ctx, jti, expiresAt.Time); err != nil {
+		return fmt.Errorf("failed to revoke access token: %w", err)
+	}
+
+	a.logger.Info("Access token revoked", "jti", jti)
+	return nil
+}
+
+// AuthEventListResponse is a page of authentication events
+type AuthEventListResponse struct {
+	Events     []*domain.AuthEvent `json:"events"`
+	Total      int64               `json:"total"`
+	Page       int                 `json:"page"`
+	PageSize   int                 `json:"pageSize"`
+	TotalPages int64               `json:"totalPages"`
+}
+
+// ListAuthEvents returns recorded authentication events, newest first.
+// Callers are responsible for restricting access to administrators.
+func (a *AuthUseCase) ListAuthEvents(ctx context.Context, filters repository.AuthEventFilters) (*AuthEventListResponse, error) {
+	if a.events == nil {
+		return nil, errors.New("auth event repository not available")
+	}
+
+	if err := filters.Validate(); err != nil {
+		return nil, err
+	}
+	events, total, err := a.events.List(ctx, filters)
+	if err != nil {
+		a.logger.Error("Failed to list auth events", "error", err)
+		return nil, fmt.Errorf("failed to list auth events: %w", err)
+	}
+
+	return &AuthEventListResponse{
+		Events:     events,
+		Total:      total,
+		Page:       filters.Page,
+		PageSize:   filters.PageSize,
+		TotalPages: (total + int64(filters.PageSize) - 1) / int64(filters.PageSize),
+	}, nil
+}
+
+// recordAuthEvent stores the outcome of an authentication attempt. Failures
+// are logged and swallowed so that auditing never blocks authentication.
+func (a *AuthUseCase) recordAuthEvent(ctx context.Context, succeeded, failed domain.AuthEventType, email string, response *AuthResponse, err error) {
+	if a.events == nil {
+		return
+	}
+
+	event := &domain.AuthEvent{Type: succeeded, Email: email}
+	if err != nil {
+		event.Type = failed
+	}
+	if response != nil && response.User != nil {
+		userID := response.User.ID
+		event.UserID = &userID
+		event.Email = response.User.Email.String()
+	}
+	if recordErr := a.events.Record(ctx, event); recordErr != nil {
+		a.logger.Warn("Auth event dropped", "type", event.Type, "error", recordErr)
+	}
+}
+
+// generateTokenPair creates both access and refresh tokens for a user, with
+// the lifetimes configured for the user's role
 func (a *AuthUseCase) generateTokenPair(ctx context.Context, user *domain.User) (string, string, error) {
 	now := time.Now()
+	role := user.RoleName()
 
-	// Generate access token
+	// Generate access token, identified by jti so it can be revoked early
+	accessTokenID, err := generateTokenID()
+	if err != nil {
+		return "", "", err
+	}
 	accessToken, err := a.tokens.SignAccessToken(jwt.MapClaims{
 		"sub":   user.ID,
 		"email": user.Email.String(),
 		"role":  user.RoleID,
 		"type":  "access",
-		"exp":   now.Add(a.tokens.GetAccessTokenTTL()).Unix(),
+		"exp":   now.Add(a.tokens.AccessTokenTTLForRole(role)).Unix(),
 		"iat":   now.Unix(),
+		"jti":   accessTokenID,
 	})
 	if err != nil {
 		return "", "", fmt.Errorf("failed to sign access token: %w", err)
 	}
 
 	// Create refresh token domain entity
-	refreshToken, rawToken, err := domain.NewRefreshToken(user.ID, a.tokens.GetRefreshTokenTTL())
+	refreshToken, rawToken, err := domain.NewRefreshToken(user.ID, a.tokens.RefreshTokenTTLForRole(role))
 	if err != nil {
 		return "", "", fmt.Errorf("failed to create refresh token: %w", err)
 	}
+	refreshToken.AccessTokenID = accessTokenID
 
 	// Persist refresh token (only hash stored)
 	if err := a.refreshTokens.Create(ctx, refreshToken); err != nil {
@@ -552,14 +757,14 @@ func (a *AuthUseCase) ResendConfirmation(ctx context.Context, req ResendConfirma
 		return fmt.Errorf("failed to generate confirmation code: %w", err)
 	}
 
-	user.ConfirmationCode = confirmationCode
+	user.SetConfirmationCode(confirmationCode, ConfirmationCodeTTL)
 	if err := a.users.Update(ctx, user); err != nil {
 		a.logger.Error("Failed to store new confirmation code", "userId", user.ID, "error", err)
 		return fmt.Errorf("failed to store confirmation code: %w", err)
 	}
 
 	// Send confirmation email
-	if err := a.notifier.SendEmailConfirmation(ctx, req.Email, confirmationCode); err != nil {
+	if err := a.notifier.SendEmailConfirmation(a.confirmationScope(ctx, user), req.Email, confirmationCode); err != nil {
 		a.logger.Error("Failed to resend confirmation email", "userId", user.ID, "email", req.Email, "error", err)
 		return fmt.Errorf("failed to send confirmation email: %w", err)
 	}
@@ -602,6 +807,14 @@ func (a *AuthUseCase) ValidateAccessToken(ctx context.Context, tokenStr string)
 		return 0, fmt.Errorf("invalid access token: %w", err)
 	}
 
+	revoked, err := repository.IsAccessTokenRevoked(ctx, a.denylist, claims)
+	if err != nil {
+		return 0, fmt.Errorf("failed to check access token revocation: %w", err)
+	}
+	if revoked {
+		return 0, domain.ErrInvalidToken
+	}
+
 	// Extract user ID from claims
 	userIDFloat, ok := claims["sub"].(float64)
 	if !ok {
@@ -611,6 +824,46 @@ func (a *AuthUseCase) ValidateAccessToken(ctx context.Context, tokenStr string)
 	return uint(userIDFloat), nil
 }
 
+// confirmationScope returns ctx carrying what the confirmation email shows
+// besides the code: its expiry and, when the user was invited, the inviting
+// organization
+func (a *AuthUseCase) confirmationScope(ctx context.Context, user *domain.User) context.Context {
+	scope := repository.NotificationScope{CodeExpiresAt: user.ConfirmationExpiresAt}
+	if invitation := a.pendingInvitation(ctx, user.Email.String()); invitation != nil {
+		org, err := a.organizations.FindByID(ctx, invitation.OrganizationID)
+		if err != nil {
+			a.logger.Warn("Failed to load inviting organization for confirmation email", "organizationId", invitation.OrganizationID, "error", err)
+		} else {
+			scope.OrganizationID = org.ID
+			scope.OrganizationName = org.Name
+		}
+	}
+	return repository.ContextWithNotificationScope(ctx, scope)
+}
+
+// pendingInvitation returns the most recent invitation still open for email
+func (a *AuthUseCase) pendingInvitation(ctx context.Context, email string) *domain.Invitation {
+	if a.invitations == nil || a.organizations == nil {
+		return nil
+	}
+	invitations, err := a.invitations.FindByEmail(ctx, email)
+	if err != nil {
+		a.logger.Warn("Failed to load invitations for confirmation email", "email", email, "error", err)
+		return nil
+	}
+
+	var latest *domain.Invitation
+	for _, invitation := range invitations {
+		if invitation.Status != domain.InvitationStatusPending || invitation.IsExpired() {
+			continue
+		}
+		if latest == nil || invitation.CreatedAt.After(latest.CreatedAt) {
+			latest = invitation
+		}
+	}
+	return latest
+}
+
 // GenerateConfirmationCode generates a secure confirmation code for email verification
 func (a *AuthUseCase) GenerateConfirmationCode() (string, error) {
 	bytes := make([]byte, 16) // 32 hex characters
@@ -619,3 +872,12 @@ func (a *AuthUseCase) GenerateConfirmationCode() (string, error) {
 	}
 	return hex.EncodeToString(bytes), nil
 }
+
+// generateTokenID generates a random identifier for the jti claim
+func generateTokenID() (string, error) {
+	bytes := make([]byte, 16)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", fmt.Errorf("failed to generate token ID: %w", err)
+	}
+	return hex.EncodeToString(bytes), nil
+}
@@ -33,6 +33,7 @@ func NewAuthService(
 	roles repository.RoleRepository,
 	tokenStorage repository.TokenStorage,
 	tokenManager core.TokenManager,
+	denylist repository.AccessTokenDenylist,
 	notifier repository.NotificationProvider,
 	logger core.Logger,
 ) *AuthService {
@@ -41,6 +42,7 @@ func NewAuthService(
 		refreshTokens: refreshTokens,
 		roles:         roles,
 		tokens:        tokenManager,
+		denylist:      denylist,
 		notifier:      notifier,
 		logger:        logger,
 	}
@@ -71,7 +73,7 @@ func (a *AuthService) CreateTokenProvider(userID uint) TokenProvider {
 
 		// Check if user can still get tokens
 		if !user.CanLogin() {
-			return "", domain.ErrUserNotConfirmed
+			return "", user.LoginError()
 		}
 
 		// Load user role
@@ -88,7 +90,7 @@ func (a *AuthService) CreateTokenProvider(userID uint) TokenProvider {
 			"email": user.Email.String(),
 			"role":  user.RoleID,
 			"type":  "access",
-			"exp":   now.Add(a.tokens.GetAccessTokenTTL()).Unix(),
+			"exp":   now.Add(a.tokens.AccessTokenTTLForRole(user.RoleName())).Unix(),
 			"iat":   now.Unix(),
 		})
 		if err != nil {
@@ -199,6 +201,7 @@ func (a *AuthService) RevokeAllUserTokens(ctx context.Context, userID uint) erro
 func (a *AuthService) generateTokenPairWithStorage(ctx context.Context, user *domain.User) (string, string, error) {
 	now := time.Now()
 	tokenID := fmt.Sprintf("token_%d_%d", user.ID, now.Unix())
+	accessTTL := a.tokens.AccessTokenTTLForRole(user.RoleName())
 
 	// Generate access token with token ID
 	accessToken, err := a.tokens.SignAccessToken(jwt.MapClaims{
@@ -206,7 +209,7 @@ func (a *AuthService) generateTokenPairWithStorage(ctx context.Context, user *do
 		"email": user.Email.String(),
 		"role":  user.RoleID,
 		"type":  "access",
-		"exp":   now.Add(a.tokens.GetAccessTokenTTL()).Unix(),
+		"exp":   now.Add(accessTTL).Unix(),
 		"iat":   now.Unix(),
 		"jti":   tokenID, // Token ID for revocation
 	})
@@ -216,14 +219,14 @@ func (a *AuthService) generateTokenPairWithStorage(ctx context.Context, user *do
 
 	// Store token in token storage if available
 	if a.tokenStorage != nil {
-		if err := a.tokenStorage.StoreToken(ctx, tokenID, user.ID, a.tokens.GetAccessTokenTTL()); err != nil {
+		if err := a.tokenStorage.StoreToken(ctx, tokenID, user.ID, accessTTL); err != nil {
 			a.logger.Warn("Failed to store token", "tokenId", tokenID, "error", err)
 			// Continue - don't fail token generation
 		}
 	}
 
 	// Create refresh token domain entity
-	refreshToken, rawToken, err := domain.NewRefreshToken(user.ID, a.tokens.GetRefreshTokenTTL())
+	refreshToken, rawToken, err := domain.NewRefreshToken(user.ID, a.tokens.RefreshTokenTTLForRole(user.RoleName()))
 	if err != nil {
 		return "", "", fmt.Errorf("failed to create refresh token: %w", err)
 	}
@@ -15,7 +15,7 @@ func TestNewAuthService_InitializesAuthUseCase(t *testing.T) {
 	notifier := &mockNotificationProvider{}
 	logger := &mockLogger{}
 
-	svc := NewAuthService(userRepo, refreshTokenRepo, roleRepo, nil, tokenManager, notifier, logger)
+	svc := NewAuthService(userRepo, refreshTokenRepo, roleRepo, nil, tokenManager, nil, notifier, logger)
 
 	if svc.authUseCase == nil {
 		t.Fatalf("authUseCase should be initialized")
@@ -37,7 +37,7 @@ func TestAuthService_Register(t *testing.T) {
 	defaultRole.ID = 1
 	roleRepo.roles[domain.RoleUser] = defaultRole
 
-	svc := NewAuthService(userRepo, refreshTokenRepo, roleRepo, nil, tokenManager, notifier, logger)
+	svc := NewAuthService(userRepo, refreshTokenRepo, roleRepo, nil, tokenManager, nil, notifier, logger)
 
 	req := RegisterRequest{Email: "test@example.com", Password: "password123"}
 	resp, err := svc.Register(context.Background(), req)
@@ -61,7 +61,7 @@ func TestAuthService_Login_UnconfirmedUser(t *testing.T) {
 	defaultRole.ID = 1
 	roleRepo.roles[domain.RoleUser] = defaultRole
 
-	svc := NewAuthService(userRepo, refreshTokenRepo, roleRepo, nil, tokenManager, notifier, logger)
+	svc := NewAuthService(userRepo, refreshTokenRepo, roleRepo, nil, tokenManager, nil, notifier, logger)
 
 	registerReq := RegisterRequest{Email: "test@example.com", Password: "password123"}
 	if _, err := svc.Register(context.Background(), registerReq); err != nil {
@@ -86,7 +86,7 @@ func TestAuthService_Confirm(t *testing.T) {
 	defaultRole.ID = 1
 	roleRepo.roles[domain.RoleUser] = defaultRole
 
-	svc := NewAuthService(userRepo, refreshTokenRepo, roleRepo, nil, tokenManager, notifier, logger)
+	svc := NewAuthService(userRepo, refreshTokenRepo, roleRepo, nil, tokenManager, nil, notifier, logger)
 
 	registerReq := RegisterRequest{Email: "test@example.com", Password: "password123"}
 	if _, err := svc.Register(context.Background(), registerReq); err != nil {
@@ -5,6 +5,8 @@ import (
 	"context"
 	"crypto/sha256"
 	"encoding/hex"
+	"errors"
+	"fmt"
 	"testing"
 	"time"
 
@@ -13,6 +15,7 @@ import (
 	"github.com/pmaojo/kthulu-go/backend/core"
 	"github.com/pmaojo/kthulu-go/backend/internal/domain"
 	"github.com/pmaojo/kthulu-go/backend/internal/domain/repository"
+	"github.com/pmaojo/kthulu-go/backend/internal/infrastructure/storage"
 )
 
 // Mock implementations for testing
@@ -128,12 +131,20 @@ func (m *mockRefreshTokenRepository) DeleteByToken(ctx context.Context, token st
 	return nil
 }
 func (m *mockRefreshTokenRepository) FindByUserID(ctx context.Context, userID uint) ([]*domain.RefreshToken, error) {
-	return nil, nil
+	var tokens []*domain.RefreshToken
+	for _, t := range m.tokens {
+		if t.UserID == userID {
+			tokens = append(tokens, t)
+		}
+	}
+	return tokens, nil
 }
 func (m *mockRefreshTokenRepository) CountByUserID(ctx context.Context, userID uint) (int64, error) {
 	return 0, nil
 }
-func (m *mockRefreshTokenRepository) DeleteExpired(ctx context.Context) (int64, error) { return 0, nil }
+func (m *mockRefreshTokenRepository) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	return 0, nil
+}
 func (m *mockRefreshTokenRepository) DeleteOlderThan(ctx context.Context, cutoff time.Time) (int64, error) {
 	return 0, nil
 }
@@ -232,6 +243,18 @@ func (m *mockTokenManager) GetRefreshTokenTTL() time.Duration {
 	return 7 * 24 * time.Hour
 }
 
+func (m *mockTokenManager) AccessTokenTTLForRole(role string) time.Duration {
+	return m.GetAccessTokenTTL()
+}
+
+func (m *mockTokenManager) RefreshTokenTTLForRole(role string) time.Duration {
+	return m.GetRefreshTokenTTL()
+}
+
+func (m *mockTokenManager) PublicKeySet() core.JSONWebKeySet {
+	return core.JSONWebKeySet{}
+}
+
 type mockNotificationProvider struct{}
 
 func (m *mockNotificationProvider) SendNotification(ctx context.Context, req repository.NotificationRequest) error {
@@ -250,6 +273,46 @@ func (m *mockNotificationProvider) SendWelcomeEmail(ctx context.Context, email,
 	return nil
 }
 
+func (m *mockNotificationProvider) SendInvoiceEmail(ctx context.Context, email, invoiceNumber string, attachment repository.NotificationAttachment) error {
+	return nil
+}
+
+// scopeRecordingNotifier records the notification scope confirmation emails are sent with
+type scopeRecordingNotifier struct {
+	mockNotificationProvider
+	scope repository.NotificationScope
+}
+
+func (m *scopeRecordingNotifier) SendEmailConfirmation(ctx context.Context, email, confirmationCode string) error {
+	m.scope, _ = repository.NotificationScopeFromContext(ctx)
+	return nil
+}
+
+// namedOrganizations serves organizations named after their ID
+type namedOrganizations struct {
+	repository.OrganizationRepository
+}
+
+func (namedOrganizations) FindByID(ctx context.Context, id uint) (*domain.Organization, error) {
+	return &domain.Organization{ID: id, Name: fmt.Sprintf("Org %d", id)}, nil
+}
+
+type mockAuthEventRepository struct {
+	events []*domain.AuthEvent
+}
+
+func (m *mockAuthEventRepository) Record(ctx context.Context, event *domain.AuthEvent) error {
+	if client, ok := repository.ClientInfoFromContext(ctx); ok {
+		event.IPAddress, event.UserAgent = client.IPAddress, client.UserAgent
+	}
+	m.events = append(m.events, event)
+	return nil
+}
+
+func (m *mockAuthEventRepository) List(ctx context.Context, filters repository.AuthEventFilters) ([]*domain.AuthEvent, int64, error) {
+	return m.events, int64(len(m.events)), nil
+}
+
 type mockLogger struct{}
 
 func (m *mockLogger) Debug(msg string, fields ...interface{}) {}
@@ -275,7 +338,7 @@ func TestAuthUseCase_Register(t *testing.T) {
 	roleRepo.roles[domain.RoleUser] = defaultRole
 
 	// Create auth use case
-	authUC := NewAuthUseCase(userRepo, refreshTokenRepo, roleRepo, tokenManager, notifier, logger)
+	authUC := NewAuthUseCase(userRepo, refreshTokenRepo, roleRepo, tokenManager, nil, nil, notifier, nil, nil, logger)
 
 	// Test registration
 	req := RegisterRequest{
@@ -322,7 +385,7 @@ func TestAuthUseCase_Login_UnconfirmedUser(t *testing.T) {
 	roleRepo.roles[domain.RoleUser] = defaultRole
 
 	// Create auth use case
-	authUC := NewAuthUseCase(userRepo, refreshTokenRepo, roleRepo, tokenManager, notifier, logger)
+	authUC := NewAuthUseCase(userRepo, refreshTokenRepo, roleRepo, tokenManager, nil, nil, notifier, nil, nil, logger)
 
 	// Register user first
 	registerReq := RegisterRequest{
@@ -358,7 +421,7 @@ func TestAuthUseCase_Confirm(t *testing.T) {
 	defaultRole.ID = 1
 	roleRepo.roles[domain.RoleUser] = defaultRole
 
-	authUC := NewAuthUseCase(userRepo, refreshTokenRepo, roleRepo, tokenManager, notifier, logger)
+	authUC := NewAuthUseCase(userRepo, refreshTokenRepo, roleRepo, tokenManager, nil, nil, notifier, nil, nil, logger)
 
 	registerReq := RegisterRequest{
 		Email:    "test@example.com",
@@ -400,3 +463,291 @@ func TestAuthUseCase_Confirm(t *testing.T) {
 		t.Fatalf("User should not be confirmed with invalid code")
 	}
 }
+
+func TestAuthUseCase_ConfirmationEmailScope(t *testing.T) {
+	ctx := context.Background()
+	userRepo := &mockUserRepository{users: make(map[string]*domain.User)}
+	roleRepo := &mockRoleRepository{roles: make(map[string]*domain.Role)}
+	defaultRole, _ := domain.NewRole(domain.RoleUser, "Default user role")
+	defaultRole.ID = 1
+	roleRepo.roles[domain.RoleUser] = defaultRole
+
+	now := time.Now()
+	invitations := &mockInvitationRepository{invitations: []*domain.Invitation{
+		{OrganizationID: 3, Email: "invited@example.com", Status: domain.InvitationStatusPending, ExpiresAt: now.Add(time.Hour), CreatedAt: now.Add(-2 * time.Hour)},
+		{OrganizationID: 4, Email: "invited@example.com", Status: domain.InvitationStatusPending, ExpiresAt: now.Add(time.Hour), CreatedAt: now.Add(-time.Hour)},
+		{OrganizationID: 5, Email: "invited@example.com", Status: domain.InvitationStatusDeclined, ExpiresAt: now.Add(time.Hour), CreatedAt: now},
+	}}
+	notifier := &scopeRecordingNotifier{}
+	authUC := NewAuthUseCase(userRepo, &mockRefreshTokenRepository{tokens: make(map[string]*domain.RefreshToken)}, roleRepo, &mockTokenManager{}, nil, nil, notifier, invitations, namedOrganizations{}, &mockLogger{})
+
+	if _, err := authUC.Register(ctx, RegisterRequest{Email: "invited@example.com", Password: "password123"}); err != nil {
+		t.Fatalf("Registration failed: %v", err)
+	}
+	user := userRepo.users["invited@example.com"]
+	if user.ConfirmationExpiresAt == nil {
+		t.Fatalf("Expected the confirmation code to expire")
+	}
+	if ttl := time.Until(*user.ConfirmationExpiresAt); ttl <= ConfirmationCodeTTL-time.Minute || ttl > ConfirmationCodeTTL {
+		t.Fatalf("Expected the code to expire in %s, got %s", ConfirmationCodeTTL, ttl)
+	}
+	if notifier.scope.OrganizationID != 4 || notifier.scope.OrganizationName != "Org 4" {
+		t.Fatalf("Expected the latest pending invitation's organization, got %+v", notifier.scope)
+	}
+	if notifier.scope.CodeExpiresAt == nil || !notifier.scope.CodeExpiresAt.Equal(*user.ConfirmationExpiresAt) {
+		t.Fatalf("Expected the code expiry in the email, got %v", notifier.scope.CodeExpiresAt)
+	}
+
+	// Users nobody invited get the expiry without an organization
+	notifier.scope = repository.NotificationScope{}
+	if _, err := authUC.Register(ctx, RegisterRequest{Email: "solo@example.com", Password: "password123"}); err != nil {
+		t.Fatalf("Registration failed: %v", err)
+	}
+	if notifier.scope.OrganizationID != 0 || notifier.scope.CodeExpiresAt == nil {
+		t.Fatalf("Expected only the expiry for uninvited users, got %+v", notifier.scope)
+	}
+
+	// Resending issues a new code with a new expiry
+	notifier.scope = repository.NotificationScope{}
+	if err := authUC.ResendConfirmation(ctx, ResendConfirmationRequest{Email: "invited@example.com"}); err != nil {
+		t.Fatalf("Resend failed: %v", err)
+	}
+	if notifier.scope.OrganizationID != 4 || notifier.scope.CodeExpiresAt == nil {
+		t.Fatalf("Expected the resent email to carry the scope, got %+v", notifier.scope)
+	}
+}
+
+func TestAuthUseCase_ConfirmRejectsExpiredCode(t *testing.T) {
+	ctx := context.Background()
+	userRepo := &mockUserRepository{users: make(map[string]*domain.User)}
+	roleRepo := &mockRoleRepository{roles: make(map[string]*domain.Role)}
+	defaultRole, _ := domain.NewRole(domain.RoleUser, "Default user role")
+	defaultRole.ID = 1
+	roleRepo.roles[domain.RoleUser] = defaultRole
+	authUC := NewAuthUseCase(userRepo, &mockRefreshTokenRepository{tokens: make(map[string]*domain.RefreshToken)}, roleRepo, &mockTokenManager{}, nil, nil, &mockNotificationProvider{}, nil, nil, &mockLogger{})
+
+	if _, err := authUC.Register(ctx, RegisterRequest{Email: "late@example.com", Password: "password123"}); err != nil {
+		t.Fatalf("Registration failed: %v", err)
+	}
+	user := userRepo.users["late@example.com"]
+	expired := time.Now().Add(-time.Minute)
+	user.ConfirmationExpiresAt = &expired
+
+	_, err := authUC.Confirm(ctx, ConfirmRequest{Email: "late@example.com", ConfirmationCode: user.ConfirmationCode})
+	if !errors.Is(err, domain.ErrConfirmationCodeExpired) {
+		t.Fatalf("Expected ErrConfirmationCodeExpired, got %v", err)
+	}
+	if userRepo.users["late@example.com"].IsConfirmed() {
+		t.Fatalf("User should not be confirmed with an expired code")
+	}
+}
+
+func TestAuthUseCase_ConfirmRejectsDisabledUser(t *testing.T) {
+	ctx := context.Background()
+	userRepo := &mockUserRepository{users: make(map[string]*domain.User)}
+	refreshTokenRepo := &mockRefreshTokenRepository{tokens: make(map[string]*domain.RefreshToken)}
+	roleRepo := &mockRoleRepository{roles: make(map[string]*domain.Role)}
+	defaultRole, _ := domain.NewRole(domain.RoleUser, "Default user role")
+	defaultRole.ID = 1
+	roleRepo.roles[domain.RoleUser] = defaultRole
+	authUC := NewAuthUseCase(userRepo, refreshTokenRepo, roleRepo, &mockTokenManager{}, nil, nil, &mockNotificationProvider{}, nil, nil, &mockLogger{})
+
+	if _, err := authUC.Register(ctx, RegisterRequest{Email: "banned@example.com", Password: "password123"}); err != nil {
+		t.Fatalf("Registration failed: %v", err)
+	}
+	user := userRepo.users["banned@example.com"]
+	user.Disable()
+
+	response, err := authUC.Confirm(ctx, ConfirmRequest{Email: "banned@example.com", ConfirmationCode: user.ConfirmationCode})
+	if !errors.Is(err, domain.ErrUserDisabled) {
+		t.Fatalf("Expected ErrUserDisabled, got %v", err)
+	}
+	if response != nil {
+		t.Fatalf("Expected no tokens for a disabled user, got %+v", response)
+	}
+	if userRepo.users["banned@example.com"].IsConfirmed() {
+		t.Fatalf("Disabled user should not be confirmed")
+	}
+	if len(refreshTokenRepo.tokens) != 0 {
+		t.Fatalf("Expected no refresh tokens, got %d", len(refreshTokenRepo.tokens))
+	}
+}
+
+func TestAuthUseCase_DisableAndEnableUser(t *testing.T) {
+	ctx := context.Background()
+	userRepo := &mockUserRepository{users: make(map[string]*domain.User)}
+	refreshTokenRepo := &mockRefreshTokenRepository{tokens: make(map[string]*domain.RefreshToken)}
+	roleRepo := &mockRoleRepository{roles: make(map[string]*domain.Role)}
+
+	defaultRole, _ := domain.NewRole(domain.RoleUser, "Default user role")
+	defaultRole.ID = 1
+	roleRepo.roles[domain.RoleUser] = defaultRole
+
+	tokens, err := core.NewJWT(&core.Config{JWT: core.JWTConfig{
+		Secret:          "access-secret",
+		RefreshSecret:   "refresh-secret",
+		AccessTokenTTL:  time.Hour,
+		RefreshTokenTTL: 7 * 24 * time.Hour,
+	}})
+	if err != nil {
+		t.Fatalf("new jwt: %v", err)
+	}
+	authUC := NewAuthUseCase(userRepo, refreshTokenRepo, roleRepo, tokens, storage.NewMemoryAccessTokenDenylist(), nil, &mockNotificationProvider{}, nil, nil, &mockLogger{})
+
+	creds := LoginRequest{Email: "test@example.com", Password: "password123"}
+	if _, err := authUC.Register(ctx, RegisterRequest{Email: creds.Email, Password: creds.Password}); err != nil {
+		t.Fatalf("Registration failed: %v", err)
+	}
+	user := userRepo.users[creds.Email]
+	if _, err := authUC.Confirm(ctx, ConfirmRequest{Email: creds.Email, ConfirmationCode: user.ConfirmationCode}); err != nil {
+		t.Fatalf("Confirmation failed: %v", err)
+	}
+	session, err := authUC.Login(ctx, creds)
+	if err != nil {
+		t.Fatalf("Login failed: %v", err)
+	}
+	if len(refreshTokenRepo.tokens) == 0 {
+		t.Fatalf("Expected refresh tokens after login")
+	}
+	if _, err := authUC.ValidateAccessToken(ctx, session.AccessToken); err != nil {
+		t.Fatalf("Access token should be valid before disabling: %v", err)
+	}
+
+	if err := authUC.DisableUser(ctx, user.ID); err != nil {
+		t.Fatalf("DisableUser failed: %v", err)
+	}
+	if !userRepo.users[creds.Email].IsDisabled() || userRepo.users[creds.Email].CanLogin() {
+		t.Fatalf("Disabled user should not be able to log in")
+	}
+	if len(refreshTokenRepo.tokens) != 0 {
+		t.Errorf("Expected all sessions to be logged out, %d refresh tokens left", len(refreshTokenRepo.tokens))
+	}
+	if _, err := authUC.ValidateAccessToken(ctx, session.AccessToken); err == nil {
+		t.Errorf("Expected the access token of a disabled user to be revoked")
+	}
+	if _, err := authUC.Login(ctx, creds); err != domain.ErrUserDisabled {
+		t.Fatalf("Expected ErrUserDisabled, got %v", err)
+	}
+
+	if err := authUC.EnableUser(ctx, user.ID); err != nil {
+		t.Fatalf("EnableUser failed: %v", err)
+	}
+	session, err = authUC.Login(ctx, creds)
+	if err != nil {
+		t.Fatalf("Login after re-enabling failed: %v", err)
+	}
+	if _, err := authUC.ValidateAccessToken(ctx, session.AccessToken); err != nil {
+		t.Errorf("Access tokens issued after re-enabling should be valid: %v", err)
+	}
+}
+
+func TestAuthUseCase_LoginRecordsAuthEvents(t *testing.T) {
+	userRepo := &mockUserRepository{users: make(map[string]*domain.User)}
+	refreshTokenRepo := &mockRefreshTokenRepository{tokens: make(map[string]*domain.RefreshToken)}
+	roleRepo := &mockRoleRepository{roles: make(map[string]*domain.Role)}
+	events := &mockAuthEventRepository{}
+
+	defaultRole, _ := domain.NewRole(domain.RoleUser, "Default user role")
+	defaultRole.ID = 1
+	roleRepo.roles[domain.RoleUser] = defaultRole
+
+	authUC := NewAuthUseCase(userRepo, refreshTokenRepo, roleRepo, &mockTokenManager{}, nil, events, &mockNotificationProvider{}, nil, nil, &mockLogger{})
+
+	ctx := repository.ContextWithClientInfo(context.Background(), repository.ClientInfo{IPAddress: "203.0.113.7", UserAgent: "test-agent"})
+	creds := LoginRequest{Email: "test@example.com", Password: "password123"}
+	if _, err := authUC.Register(ctx, RegisterRequest{Email: creds.Email, Password: creds.Password}); err != nil {
+		t.Fatalf("Registration failed: %v", err)
+	}
+	user := userRepo.users[creds.Email]
+	if _, err := authUC.Confirm(ctx, ConfirmRequest{Email: creds.Email, ConfirmationCode: user.ConfirmationCode}); err != nil {
+		t.Fatalf("Confirmation failed: %v", err)
+	}
+
+	if _, err := authUC.Login(ctx, LoginRequest{Email: creds.Email, Password: "wrong-password"}); err == nil {
+		t.Fatalf("Expected login with a wrong password to fail")
+	}
+	if _, err := authUC.Login(ctx, creds); err != nil {
+		t.Fatalf("Login failed: %v", err)
+	}
+
+	if len(events.events) != 3 {
+		t.Fatalf("Expected confirm and two login events, got %d", len(events.events))
+	}
+	if events.events[0].Type != domain.AuthEventConfirmSucceeded {
+		t.Errorf("Expected %s, got %s", domain.AuthEventConfirmSucceeded, events.events[0].Type)
+	}
+
+	failed := events.events[1]
+	if failed.Type != domain.AuthEventLoginFailed || failed.UserID != nil || failed.Email != creds.Email {
+		t.Errorf("Unexpected failed login event %+v", failed)
+	}
+	if failed.IPAddress != "203.0.113.7" || failed.UserAgent != "test-agent" {
+		t.Errorf("Expected client info on failed login event, got %+v", failed)
+	}
+
+	succeeded := events.events[2]
+	if succeeded.Type != domain.AuthEventLoginSucceeded || succeeded.UserID == nil || *succeeded.UserID != user.ID {
+		t.Errorf("Unexpected successful login event %+v", succeeded)
+	}
+}
+
+func TestAuthUseCase_RoleTokenTTLs(t *testing.T) {
+	ctx := context.Background()
+	userRepo := &mockUserRepository{users: make(map[string]*domain.User)}
+	refreshTokenRepo := &mockRefreshTokenRepository{tokens: make(map[string]*domain.RefreshToken)}
+	roleRepo := &mockRoleRepository{roles: make(map[string]*domain.Role)}
+
+	userRole, _ := domain.NewRole(domain.RoleUser, "Default user role")
+	userRole.ID = 1
+	roleRepo.roles[domain.RoleUser] = userRole
+	adminRole, _ := domain.NewRole(domain.RoleAdmin, "Administrator role")
+	adminRole.ID = 2
+	roleRepo.roles[domain.RoleAdmin] = adminRole
+
+	tokens, err := core.NewJWT(&core.Config{JWT: core.JWTConfig{
+		Secret:              "access-secret",
+		RefreshSecret:       "refresh-secret",
+		AccessTokenTTL:      time.Hour,
+		RefreshTokenTTL:     7 * 24 * time.Hour,
+		RoleAccessTokenTTLs: map[string]time.Duration{domain.RoleAdmin: 5 * time.Minute},
+	}})
+	if err != nil {
+		t.Fatalf("new jwt: %v", err)
+	}
+	authUC := NewAuthUseCase(userRepo, refreshTokenRepo, roleRepo, tokens, nil, nil, &mockNotificationProvider{}, nil, nil, &mockLogger{})
+
+	login := func(email string, roleID uint) (*AuthResponse, time.Duration) {
+		t.Helper()
+		if _, err := authUC.Register(ctx, RegisterRequest{Email: email, Password: "password123", RoleID: roleID}); err != nil {
+			t.Fatalf("Registration failed: %v", err)
+		}
+		user := userRepo.users[email]
+		if _, err := authUC.Confirm(ctx, ConfirmRequest{Email: email, ConfirmationCode: user.ConfirmationCode}); err != nil {
+			t.Fatalf("Confirmation failed: %v", err)
+		}
+		resp, err := authUC.Login(ctx, LoginRequest{Email: email, Password: "password123"})
+		if err != nil {
+			t.Fatalf("Login failed: %v", err)
+		}
+		claims, err := tokens.ValidateAccessToken(resp.AccessToken)
+		if err != nil {
+			t.Fatalf("validate access token: %v", err)
+		}
+		exp, _ := claims.GetExpirationTime()
+		iat, _ := claims.GetIssuedAt()
+		return resp, exp.Sub(iat.Time)
+	}
+
+	adminResp, adminTTL := login("admin@example.com", adminRole.ID)
+	userResp, userTTL := login("user@example.com", 0)
+
+	if adminTTL != 5*time.Minute || userTTL != time.Hour {
+		t.Fatalf("Expected 5m admin and 1h user access tokens, got %s and %s", adminTTL, userTTL)
+	}
+	if adminTTL >= userTTL {
+		t.Errorf("Expected admin access token to expire before a default user's")
+	}
+	if adminResp.ExpiresIn != int64((5*time.Minute).Seconds()) || userResp.ExpiresIn != int64(time.Hour.Seconds()) {
+		t.Errorf("Unexpected expiresIn admin=%d user=%d", adminResp.ExpiresIn, userResp.ExpiresIn)
+	}
+}
@@ -2,7 +2,9 @@
 package usecase
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -25,15 +27,21 @@ type PaginatedResponse[T any] struct {
 type CalendarUseCase struct {
 	calendarRepo       repository.CalendarRepository
 	userRepo           repository.UserRepository
+	contactRepo        repository.ContactRepository
+	invoiceRepo        repository.InvoiceRepository
 	appointmentService *AppointmentService
 	conflictChecker    *BookingConflictChecker
 	logger             *zap.Logger
 }
 
-// NewCalendarUseCase creates a new calendar use case
+// NewCalendarUseCase creates a new calendar use case. The contact and
+// invoice repositories check the records events are linked to; without them
+// events can't be linked to that kind of record.
 func NewCalendarUseCase(
 	calendarRepo repository.CalendarRepository,
 	userRepo repository.UserRepository,
+	contactRepo repository.ContactRepository,
+	invoiceRepo repository.InvoiceRepository,
 	logger *zap.Logger,
 ) *CalendarUseCase {
 	// Default timezone - can be made configurable
@@ -42,6 +50,8 @@ func NewCalendarUseCase(
 	return &CalendarUseCase{
 		calendarRepo:       calendarRepo,
 		userRepo:           userRepo,
+		contactRepo:        contactRepo,
+		invoiceRepo:        invoiceRepo,
 		appointmentService: NewAppointmentService(timezone),
 		conflictChecker:    NewBookingConflictChecker(15 * time.Minute), // 15 min buffer
 		logger:             logger,
@@ -103,6 +113,18 @@ func (uc *CalendarUseCase) CreateEvent(ctx context.Context, req CreateEventReque
 		return nil, fmt.Errorf("calendar not found: %w", err)
 	}
 
+	if req.RecurrenceRule != "" {
+		if _, err := domain.ParseRecurrenceRule(req.RecurrenceRule); err != nil {
+			return nil, err
+		}
+	}
+	if err := domain.ValidateRecurrenceExceptions(req.RecurrenceExceptions); err != nil {
+		return nil, err
+	}
+	if err := uc.checkEventLink(ctx, req.OrganizationID, req.EntityType, req.EntityID); err != nil {
+		return nil, err
+	}
+
 	// Check for conflicts if requested
 	if req.CheckConflicts {
 		conflicts, err := uc.calendarRepo.GetEventsByTimeRange(ctx, req.CalendarID, req.StartTime, req.EndTime)
@@ -115,18 +137,24 @@ func (uc *CalendarUseCase) CreateEvent(ctx context.Context, req CreateEventReque
 	}
 
 	event := &domain.Event{
-		CalendarID:     req.CalendarID,
-		Title:          req.Title,
-		Description:    req.Description,
-		Location:       req.Location,
-		StartTime:      req.StartTime,
-		EndTime:        req.EndTime,
-		AllDay:         req.AllDay,
-		Status:         domain.EventStatusConfirmed,
-		Type:           req.Type,
-		IsRecurring:    req.IsRecurring,
-		RecurrenceRule: req.RecurrenceRule,
-		CreatedByID:    req.CreatedByID,
+		CalendarID:           req.CalendarID,
+		Title:                req.Title,
+		Description:          req.Description,
+		Location:             req.Location,
+		StartTime:            req.StartTime,
+		EndTime:              req.EndTime,
+		AllDay:               req.AllDay,
+		Status:               domain.EventStatusConfirmed,
+		Type:                 req.Type,
+		IsRecurring:          req.IsRecurring || req.RecurrenceRule != "",
+		RecurrenceRule:       req.RecurrenceRule,
+		RecurrenceExceptions: req.RecurrenceExceptions,
+		EntityType:           req.EntityType,
+		EntityID:             req.EntityID,
+		CreatedByID:          req.CreatedByID,
+	}
+	if req.EntityType != "" {
+		event.OrganizationID = req.OrganizationID
 	}
 
 	if err := uc.calendarRepo.CreateEvent(ctx, event); err != nil {
@@ -159,6 +187,7 @@ func (uc *CalendarUseCase) CreateEvent(ctx context.Context, req CreateEventReque
 				EventID:       event.ID,
 				Type:          reminderReq.Type,
 				MinutesBefore: reminderReq.MinutesBefore,
+				RemindAt:      event.ReminderTime(reminderReq.MinutesBefore),
 			}
 			if err := uc.calendarRepo.CreateReminder(ctx, reminder); err != nil {
 				uc.logger.Error("Failed to create reminder", zap.Error(err))
@@ -171,6 +200,41 @@ func (uc *CalendarUseCase) CreateEvent(ctx context.Context, req CreateEventReque
 	return event, nil
 }
 
+// checkEventLink validates the link of a new event and makes sure the linked
+// record belongs to the caller's organization. Records of other
+// organizations are reported as invalid links so their existence isn't
+// revealed.
+func (uc *CalendarUseCase) checkEventLink(ctx context.Context, organizationID uint, entityType domain.EventEntityType, entityID *uint) error {
+	if err := domain.ValidateEventLink(entityType, entityID); err != nil || entityType == "" {
+		return err
+	}
+	if organizationID == 0 {
+		return fmt.Errorf("%w: an organization is required", domain.ErrInvalidEventLink)
+	}
+
+	var err error
+	switch entityType {
+	case domain.EventEntityContact:
+		if uc.contactRepo == nil {
+			return fmt.Errorf("%w: contacts are not available", domain.ErrInvalidEventLink)
+		}
+		_, err = uc.contactRepo.GetByID(ctx, organizationID, *entityID)
+	case domain.EventEntityInvoice:
+		if uc.invoiceRepo == nil {
+			return fmt.Errorf("%w: invoices are not available", domain.ErrInvalidEventLink)
+		}
+		_, err = uc.invoiceRepo.GetByID(ctx, organizationID, *entityID)
+	}
+	if errors.Is(err, domain.ErrContactNotFound) || errors.Is(err, domain.ErrInvoiceNotFound) {
+		return fmt.Errorf("%w: %s %d not found", domain.ErrInvalidEventLink, entityType, *entityID)
+	}
+	if err != nil {
+		uc.logger.Error("Failed to check event link", zap.String("entityType", string(entityType)), zap.Uint("entityId", *entityID), zap.Error(err))
+		return fmt.Errorf("failed to check event link: %w", err)
+	}
+	return nil
+}
+
 // GetEvent retrieves an event by ID
 func (uc *CalendarUseCase) GetEvent(ctx context.Context, id uint) (*domain.Event, error) {
 	event, err := uc.calendarRepo.GetEvent(ctx, id)
@@ -208,6 +272,49 @@ func (uc *CalendarUseCase) ListEvents(ctx context.Context, req ListEventsRequest
 	}, nil
 }
 
+// ExpandOccurrences materializes the instances of an event overlapping
+// [from, to), applying its recurrence rule and exceptions
+func (uc *CalendarUseCase) ExpandOccurrences(ctx context.Context, eventID uint, from, to time.Time) ([]domain.EventOccurrence, error) {
+	event, err := uc.calendarRepo.GetEvent(ctx, eventID)
+	if err != nil {
+		uc.logger.Error("Failed to get event", zap.Uint("id", eventID), zap.Error(err))
+		return nil, err
+	}
+
+	occurrences, err := event.Occurrences(from, to)
+	if err != nil {
+		uc.logger.Warn("Failed to expand event occurrences", zap.Uint("id", eventID), zap.Error(err))
+		return nil, err
+	}
+	return occurrences, nil
+}
+
+// ExportICalendar renders the events of the calendars owned by ownerID as an
+// iCalendar file, limited to calendarID when it is not zero
+func (uc *CalendarUseCase) ExportICalendar(ctx context.Context, ownerID, calendarID uint) ([]byte, error) {
+	name := "Kthulu"
+	if calendarID != 0 {
+		calendar, err := uc.calendarRepo.GetCalendar(ctx, calendarID)
+		if err != nil || calendar.OwnerID != ownerID {
+			return nil, domain.ErrCalendarNotFound
+		}
+		name = calendar.Name
+	}
+
+	events, err := uc.calendarRepo.ListEventsByOwner(ctx, ownerID, calendarID)
+	if err != nil {
+		uc.logger.Error("Failed to list events for export", zap.Uint("ownerId", ownerID), zap.Error(err))
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := domain.WriteICalendar(&buf, name, events, time.Now()); err != nil {
+		uc.logger.Error("Failed to render calendar export", zap.Uint("ownerId", ownerID), zap.Error(err))
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
 // GetAvailableSlots generates available time slots for a calendar
 func (uc *CalendarUseCase) GetAvailableSlots(ctx context.Context, req GetAvailableSlotsRequest) ([]domain.AvailabilitySlot, error) {
 	// Get existing events for the date
@@ -317,20 +424,24 @@ type ListCalendarsRequest struct {
 }
 
 type CreateEventRequest struct {
-	CalendarID     uint                    `json:"calendarId" validate:"required"`
-	Title          string                  `json:"title" validate:"required,max=255"`
-	Description    string                  `json:"description" validate:"max=1000"`
-	Location       string                  `json:"location" validate:"max=255"`
-	StartTime      time.Time               `json:"startTime" validate:"required"`
-	EndTime        time.Time               `json:"endTime" validate:"required"`
-	AllDay         bool                    `json:"allDay"`
-	Type           domain.EventType        `json:"type" validate:"required"`
-	IsRecurring    bool                    `json:"isRecurring"`
-	RecurrenceRule string                  `json:"recurrenceRule" validate:"max=500"`
-	CreatedByID    uint                    `json:"createdById" validate:"required"`
-	CheckConflicts bool                    `json:"checkConflicts"`
-	Attendees      []CreateAttendeeRequest `json:"attendees"`
-	Reminders      []CreateReminderRequest `json:"reminders"`
+	CalendarID           uint                    `json:"calendarId" validate:"required"`
+	Title                string                  `json:"title" validate:"required,max=255"`
+	Description          string                  `json:"description" validate:"max=1000"`
+	Location             string                  `json:"location" validate:"max=255"`
+	StartTime            time.Time               `json:"startTime" validate:"required"`
+	EndTime              time.Time               `json:"endTime" validate:"required"`
+	AllDay               bool                    `json:"allDay"`
+	Type                 domain.EventType        `json:"type" validate:"required"`
+	IsRecurring          bool                    `json:"isRecurring"`
+	RecurrenceRule       string                  `json:"recurrenceRule" validate:"max=500"`
+	RecurrenceExceptions string                  `json:"recurrenceExceptions" validate:"max=2000"`
+	EntityType           domain.EventEntityType  `json:"entityType,omitempty"`
+	EntityID             *uint                   `json:"entityId,omitempty"`
+	OrganizationID       uint                    `json:"-"` // Organization of the caller, set by the handler
+	CreatedByID          uint                    `json:"createdById" validate:"required"`
+	CheckConflicts       bool                    `json:"checkConflicts"`
+	Attendees            []CreateAttendeeRequest `json:"attendees"`
+	Reminders            []CreateReminderRequest `json:"reminders"`
 }
 
 type CreateAttendeeRequest struct {
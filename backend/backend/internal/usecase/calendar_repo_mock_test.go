@@ -37,6 +37,21 @@ func (m *MockCalendarRepository) EXPECT() *MockCalendarRepositoryMockRecorder {
 	return m.recorder
 }
 
+// ClaimReminder mocks base method.
+func (m *MockCalendarRepository) ClaimReminder(ctx context.Context, id uint, sentAt time.Time) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimReminder", ctx, id, sentAt)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimReminder indicates an expected call of ClaimReminder.
+func (mr *MockCalendarRepositoryMockRecorder) ClaimReminder(ctx, id, sentAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimReminder", reflect.TypeOf((*MockCalendarRepository)(nil).ClaimReminder), ctx, id, sentAt)
+}
+
 // CreateAttendee mocks base method.
 func (m *MockCalendarRepository) CreateAttendee(ctx context.Context, attendee *domain.Attendee) error {
 	m.ctrl.T.Helper()
@@ -433,6 +448,65 @@ func (mr *MockCalendarRepositoryMockRecorder) ListEvents(ctx, calendarID, page,
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListEvents", reflect.TypeOf((*MockCalendarRepository)(nil).ListEvents), ctx, calendarID, page, pageSize)
 }
 
+// ListEventsByOwner mocks base method.
+func (m *MockCalendarRepository) ListEventsByOwner(ctx context.Context, ownerID, calendarID uint) ([]domain.Event, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListEventsByOwner", ctx, ownerID, calendarID)
+	ret0, _ := ret[0].([]domain.Event)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListEventsByOwner indicates an expected call of ListEventsByOwner.
+func (mr *MockCalendarRepositoryMockRecorder) ListEventsByOwner(ctx, ownerID, calendarID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListEventsByOwner", reflect.TypeOf((*MockCalendarRepository)(nil).ListEventsByOwner), ctx, ownerID, calendarID)
+}
+
+// ListEventsForContact mocks base method.
+func (m *MockCalendarRepository) ListEventsForContact(ctx context.Context, organizationID, contactID uint) ([]domain.Event, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListEventsForContact", ctx, organizationID, contactID)
+	ret0, _ := ret[0].([]domain.Event)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListEventsForContact indicates an expected call of ListEventsForContact.
+func (mr *MockCalendarRepositoryMockRecorder) ListEventsForContact(ctx, organizationID, contactID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListEventsForContact", reflect.TypeOf((*MockCalendarRepository)(nil).ListEventsForContact), ctx, organizationID, contactID)
+}
+
+// ListEventsForInvoice mocks base method.
+func (m *MockCalendarRepository) ListEventsForInvoice(ctx context.Context, organizationID, invoiceID uint) ([]domain.Event, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListEventsForInvoice", ctx, organizationID, invoiceID)
+	ret0, _ := ret[0].([]domain.Event)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListEventsForInvoice indicates an expected call of ListEventsForInvoice.
+func (mr *MockCalendarRepositoryMockRecorder) ListEventsForInvoice(ctx, organizationID, invoiceID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListEventsForInvoice", reflect.TypeOf((*MockCalendarRepository)(nil).ListEventsForInvoice), ctx, organizationID, invoiceID)
+}
+
+// ReleaseReminder mocks base method.
+func (m *MockCalendarRepository) ReleaseReminder(ctx context.Context, id uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReleaseReminder", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReleaseReminder indicates an expected call of ReleaseReminder.
+func (mr *MockCalendarRepositoryMockRecorder) ReleaseReminder(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseReminder", reflect.TypeOf((*MockCalendarRepository)(nil).ReleaseReminder), ctx, id)
+}
+
 // SearchEvents mocks base method.
 func (m *MockCalendarRepository) SearchEvents(ctx context.Context, calendarID uint, query string, page, pageSize int) ([]domain.Event, int, error) {
 	m.ctrl.T.Helper()
@@ -11,6 +11,7 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/pmaojo/kthulu-go/backend/internal/domain"
+	"github.com/pmaojo/kthulu-go/backend/internal/domain/repository"
 )
 
 func TestCalendarUseCase_CreateCalendar(t *testing.T) {
@@ -18,7 +19,7 @@ func TestCalendarUseCase_CreateCalendar(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockRepo := NewMockCalendarRepository(ctrl)
-	uc := NewCalendarUseCase(mockRepo, nil, zap.NewNop())
+	uc := NewCalendarUseCase(mockRepo, nil, nil, nil, zap.NewNop())
 
 	req := CreateCalendarRequest{Name: "Team", Type: domain.CalendarTypePersonal, OwnerID: 1}
 
@@ -38,7 +39,7 @@ func TestCalendarUseCase_GetAndList(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockRepo := NewMockCalendarRepository(ctrl)
-	uc := NewCalendarUseCase(mockRepo, nil, zap.NewNop())
+	uc := NewCalendarUseCase(mockRepo, nil, nil, nil, zap.NewNop())
 
 	cal := &domain.Calendar{ID: 1, Name: "Team"}
 
@@ -69,7 +70,7 @@ func TestCalendarUseCase_CreateEvent(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockRepo := NewMockCalendarRepository(ctrl)
-	uc := NewCalendarUseCase(mockRepo, nil, zap.NewNop())
+	uc := NewCalendarUseCase(mockRepo, nil, nil, nil, zap.NewNop())
 
 	start := time.Now()
 	end := start.Add(time.Hour)
@@ -90,3 +91,131 @@ func TestCalendarUseCase_CreateEvent(t *testing.T) {
 	assert.Error(t, err)
 	assert.Nil(t, evt)
 }
+
+func TestCalendarUseCase_ExpandOccurrences(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := NewMockCalendarRepository(ctrl)
+	uc := NewCalendarUseCase(mockRepo, nil, nil, nil, zap.NewNop())
+
+	start := time.Date(2024, 4, 1, 9, 0, 0, 0, time.UTC)
+	event := &domain.Event{
+		ID:                   3,
+		StartTime:            start,
+		EndTime:              start.Add(time.Hour),
+		IsRecurring:          true,
+		RecurrenceRule:       "FREQ=WEEKLY",
+		RecurrenceExceptions: "20240415",
+	}
+	from, to := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	mockRepo.EXPECT().GetEvent(gomock.Any(), uint(3)).Return(event, nil)
+	occurrences, err := uc.ExpandOccurrences(context.Background(), 3, from, to)
+	assert.NoError(t, err)
+	var days []int
+	for _, occurrence := range occurrences {
+		days = append(days, occurrence.StartTime.Day())
+	}
+	assert.Equal(t, []int{1, 8, 22, 29}, days)
+
+	mockRepo.EXPECT().GetEvent(gomock.Any(), uint(3)).Return(event, nil)
+	_, err = uc.ExpandOccurrences(context.Background(), 3, to, from)
+	assert.ErrorIs(t, err, domain.ErrInvalidOccurrenceWindow)
+
+	mockRepo.EXPECT().GetEvent(gomock.Any(), uint(4)).Return(nil, errors.New("missing"))
+	_, err = uc.ExpandOccurrences(context.Background(), 4, from, to)
+	assert.Error(t, err)
+}
+
+func TestCalendarUseCase_CreateEventRejectsInvalidRecurrence(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := NewMockCalendarRepository(ctrl)
+	uc := NewCalendarUseCase(mockRepo, nil, nil, nil, zap.NewNop())
+
+	start := time.Now()
+	req := CreateEventRequest{CalendarID: 1, Title: "Standup", StartTime: start, EndTime: start.Add(15 * time.Minute), Type: domain.EventTypeMeeting, CreatedByID: 1, RecurrenceRule: "FREQ=HOURLY"}
+
+	mockRepo.EXPECT().GetCalendar(gomock.Any(), uint(1)).Return(&domain.Calendar{ID: 1}, nil)
+	evt, err := uc.CreateEvent(context.Background(), req)
+	assert.ErrorIs(t, err, domain.ErrInvalidRecurrenceRule)
+	assert.Nil(t, evt)
+}
+
+// orgContacts serves contacts only to the organization owning them
+type orgContacts struct {
+	repository.ContactRepository
+
+	owners map[uint]uint
+}
+
+func (c orgContacts) GetByID(ctx context.Context, organizationID, contactID uint) (*domain.Contact, error) {
+	if c.owners[contactID] != organizationID {
+		return nil, domain.ErrContactNotFound
+	}
+	return &domain.Contact{ID: contactID, OrganizationID: organizationID}, nil
+}
+
+func TestCalendarUseCase_CreateEventLinksEntity(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := NewMockCalendarRepository(ctrl)
+	uc := NewCalendarUseCase(mockRepo, nil, orgContacts{owners: map[uint]uint{42: 7, 43: 8}}, nil, zap.NewNop())
+
+	start := time.Now()
+	contactID := uint(42)
+	req := CreateEventRequest{CalendarID: 1, Title: "Demo", StartTime: start, EndTime: start.Add(time.Hour), Type: domain.EventTypeMeeting, CreatedByID: 1, OrganizationID: 7, EntityType: domain.EventEntityContact, EntityID: &contactID}
+
+	var stored *domain.Event
+	mockRepo.EXPECT().GetCalendar(gomock.Any(), uint(1)).Return(&domain.Calendar{ID: 1}, nil)
+	mockRepo.EXPECT().CreateEvent(gomock.Any(), gomock.Any()).Do(func(_ context.Context, event *domain.Event) { stored = event }).Return(nil)
+	_, err := uc.CreateEvent(context.Background(), req)
+	assert.NoError(t, err)
+	assert.Equal(t, domain.EventEntityContact, stored.EntityType)
+	assert.Equal(t, contactID, *stored.EntityID)
+	assert.Equal(t, uint(7), stored.OrganizationID)
+
+	// Unknown kinds, links without an ID, records of another organization and
+	// kinds without a repository to check them are rejected before storing
+	zero, foreign := uint(0), uint(43)
+	for _, link := range []struct {
+		entityType domain.EventEntityType
+		entityID   *uint
+	}{{"deal", &contactID}, {domain.EventEntityInvoice, nil}, {domain.EventEntityInvoice, &zero}, {"", &contactID}, {domain.EventEntityContact, &foreign}, {domain.EventEntityInvoice, &contactID}} {
+		req.EntityType, req.EntityID = link.entityType, link.entityID
+		mockRepo.EXPECT().GetCalendar(gomock.Any(), uint(1)).Return(&domain.Calendar{ID: 1}, nil)
+		evt, err := uc.CreateEvent(context.Background(), req)
+		assert.ErrorIs(t, err, domain.ErrInvalidEventLink)
+		assert.Nil(t, evt)
+	}
+}
+
+func TestCalendarUseCase_ExportICalendar(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := NewMockCalendarRepository(ctrl)
+	uc := NewCalendarUseCase(mockRepo, nil, nil, nil, zap.NewNop())
+	start := time.Date(2024, 6, 3, 9, 0, 0, 0, time.UTC)
+	events := []domain.Event{{ID: 5, Title: "Review", StartTime: start, EndTime: start.Add(time.Hour)}}
+
+	mockRepo.EXPECT().GetCalendar(gomock.Any(), uint(3)).Return(&domain.Calendar{ID: 3, Name: "Sales", OwnerID: 1}, nil)
+	mockRepo.EXPECT().ListEventsByOwner(gomock.Any(), uint(1), uint(3)).Return(events, nil)
+	ics, err := uc.ExportICalendar(context.Background(), 1, 3)
+	assert.NoError(t, err)
+	assert.Contains(t, string(ics), "X-WR-CALNAME:Sales\r\n")
+	assert.Contains(t, string(ics), "UID:event-5@kthulu\r\n")
+
+	// Calendars of other users are not exported
+	mockRepo.EXPECT().GetCalendar(gomock.Any(), uint(3)).Return(&domain.Calendar{ID: 3, OwnerID: 1}, nil)
+	_, err = uc.ExportICalendar(context.Background(), 2, 3)
+	assert.ErrorIs(t, err, domain.ErrCalendarNotFound)
+
+	mockRepo.EXPECT().ListEventsByOwner(gomock.Any(), uint(2), uint(0)).Return(nil, nil)
+	ics, err = uc.ExportICalendar(context.Background(), 2, 0)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(ics), "BEGIN:VEVENT")
+}
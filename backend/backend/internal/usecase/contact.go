@@ -4,6 +4,7 @@ package usecase
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/pmaojo/kthulu-go/backend/internal/domain"
 	"github.com/pmaojo/kthulu-go/backend/internal/domain/repository"
@@ -13,15 +14,21 @@ import (
 
 // ContactUseCase handles business logic for contact management
 type ContactUseCase struct {
-	contactRepo repository.ContactRepository
-	logger      *zap.Logger
+	contactRepo      repository.ContactRepository
+	organizationRepo repository.OrganizationRepository
+	calendarRepo     repository.CalendarRepository
+	logger           *zap.Logger
 }
 
-// NewContactUseCase creates a new contact use case
-func NewContactUseCase(contactRepo repository.ContactRepository, logger *zap.Logger) *ContactUseCase {
+// NewContactUseCase creates a new contact use case. The organization
+// repository provides the default region of phone numbers and the calendar
+// repository the events linked to contacts; both may be nil.
+func NewContactUseCase(contactRepo repository.ContactRepository, organizationRepo repository.OrganizationRepository, calendarRepo repository.CalendarRepository, logger *zap.Logger) *ContactUseCase {
 	return &ContactUseCase{
-		contactRepo: contactRepo,
-		logger:      logger,
+		contactRepo:      contactRepo,
+		organizationRepo: organizationRepo,
+		calendarRepo:     calendarRepo,
+		logger:           logger,
 	}
 }
 
@@ -183,13 +190,38 @@ func (uc *ContactUseCase) ListContacts(ctx context.Context, organizationID uint,
 		return nil, fmt.Errorf("failed to list contacts: %w", err)
 	}
 
-	return &ContactListResponse{
-		Contacts:   contacts,
-		Total:      total,
-		Page:       filters.Page,
-		PageSize:   filters.PageSize,
-		TotalPages: (total + int64(filters.PageSize) - 1) / int64(filters.PageSize),
-	}, nil
+	return NewListResponse(contacts, total, filters.Page, filters.PageSize), nil
+}
+
+// ExportContacts calls fn for every contact matching filters, with its
+// addresses and phones, ignoring their pagination. Contacts are fetched a page
+// at a time so large organizations don't have to be held in memory.
+func (uc *ContactUseCase) ExportContacts(ctx context.Context, organizationID uint, filters repository.ContactFilters, fn func(*domain.Contact) error) error {
+	if err := filters.Validate(); err != nil {
+		return err
+	}
+	filters.Page = 1
+	filters.PageSize = repository.MaxPageSize
+
+	for {
+		contacts, total, err := uc.contactRepo.List(ctx, organizationID, filters)
+		if err != nil {
+			uc.logger.Error("Failed to export contacts", zap.Uint("organization_id", organizationID), zap.Error(err))
+			return fmt.Errorf("failed to export contacts: %w", err)
+		}
+		for _, contact := range contacts {
+			if err := uc.loadContactRelations(ctx, contact); err != nil {
+				return fmt.Errorf("failed to load contact relations: %w", err)
+			}
+			if err := fn(contact); err != nil {
+				return err
+			}
+		}
+		if len(contacts) < filters.PageSize || int64(filters.Page*filters.PageSize) >= total {
+			return nil
+		}
+		filters.Page++
+	}
 }
 
 // SetContactActive sets the active status of a contact
@@ -252,6 +284,63 @@ func (uc *ContactUseCase) GetContactStats(ctx context.Context, organizationID ui
 	return stats, nil
 }
 
+// FindPotentialDuplicates reports groups of contacts that likely describe
+// the same person or company, as candidates for merging
+func (uc *ContactUseCase) FindPotentialDuplicates(ctx context.Context, organizationID uint) ([]domain.DuplicateGroup, error) {
+	groups, err := uc.contactRepo.FindPotentialDuplicates(ctx, organizationID)
+	if err != nil {
+		uc.logger.Error("Failed to find potential duplicate contacts", zap.Error(err))
+		return nil, fmt.Errorf("failed to find potential duplicate contacts: %w", err)
+	}
+
+	if groups == nil {
+		groups = []domain.DuplicateGroup{}
+	}
+	return groups, nil
+}
+
+// ListContactEvents retrieves the calendar events linked to a contact of the
+// organization
+func (uc *ContactUseCase) ListContactEvents(ctx context.Context, organizationID, contactID uint) ([]domain.Event, error) {
+	if _, err := uc.contactRepo.GetByID(ctx, organizationID, contactID); err != nil {
+		return nil, err
+	}
+	if uc.calendarRepo == nil {
+		return []domain.Event{}, nil
+	}
+
+	events, err := uc.calendarRepo.ListEventsForContact(ctx, organizationID, contactID)
+	if err != nil {
+		uc.logger.Error("Failed to list contact events", zap.Uint("contact_id", contactID), zap.Error(err))
+		return nil, fmt.Errorf("failed to list contact events: %w", err)
+	}
+	if events == nil {
+		events = []domain.Event{}
+	}
+	return events, nil
+}
+
+// MaxContactTrendMonths bounds the period covered by a contact trend
+const MaxContactTrendMonths = 36
+
+// GetContactStatsTrend retrieves the contacts created per month and type in [from, to)
+func (uc *ContactUseCase) GetContactStatsTrend(ctx context.Context, organizationID uint, from, to time.Time) (*repository.ContactStatsTrend, error) {
+	if !to.After(from) {
+		return nil, fmt.Errorf("%w: end must be after start", domain.ErrInvalidStatsPeriod)
+	}
+	if to.After(from.AddDate(0, MaxContactTrendMonths, 0)) {
+		return nil, fmt.Errorf("%w: period cannot exceed %d months", domain.ErrInvalidStatsPeriod, MaxContactTrendMonths)
+	}
+
+	trend, err := uc.contactRepo.GetContactStatsByPeriod(ctx, organizationID, from, to)
+	if err != nil {
+		uc.logger.Error("Failed to get contact stats trend", zap.Error(err))
+		return nil, fmt.Errorf("failed to get contact stats trend: %w", err)
+	}
+
+	return trend, nil
+}
+
 // AddContactAddress adds an address to a contact
 func (uc *ContactUseCase) AddContactAddress(ctx context.Context, organizationID, contactID uint, req CreateAddressRequest) (*domain.ContactAddress, error) {
 	uc.logger.Info("Adding address to contact",
@@ -321,6 +410,7 @@ func (uc *ContactUseCase) AddContactPhone(ctx context.Context, organizationID, c
 	if err != nil {
 		return nil, err
 	}
+	phone.Normalize(uc.phoneRegion(ctx, organizationID))
 
 	if err := uc.contactRepo.CreatePhone(ctx, phone); err != nil {
 		uc.logger.Error("Failed to create contact phone", zap.Error(err))
@@ -451,6 +541,7 @@ func (uc *ContactUseCase) UpdateContactPhone(ctx context.Context, organizationID
 	if err != nil {
 		return nil, err
 	}
+	phone.Normalize(uc.phoneRegion(ctx, organizationID))
 	phone.ID = phoneID
 	phone.CreatedAt = existing.CreatedAt
 
@@ -511,6 +602,23 @@ func (uc *ContactUseCase) SetPrimaryPhone(ctx context.Context, organizationID, c
 	return nil
 }
 
+// phoneRegion returns the region phone numbers without a country code are
+// read in, taken from the organization's country
+func (uc *ContactUseCase) phoneRegion(ctx context.Context, organizationID uint) string {
+	if uc.organizationRepo == nil {
+		return ""
+	}
+	org, err := uc.organizationRepo.FindByID(ctx, organizationID)
+	if err != nil {
+		uc.logger.Warn("Failed to get organization for phone region",
+			zap.Uint("organization_id", organizationID),
+			zap.Error(err),
+		)
+		return ""
+	}
+	return domain.PhoneRegion(org.Country)
+}
+
 // loadContactRelations loads addresses and phones for a contact
 func (uc *ContactUseCase) loadContactRelations(ctx context.Context, contact *domain.Contact) error {
 	// Load addresses
@@ -594,10 +702,4 @@ type UpdateAddressRequest = CreateAddressRequest
 type UpdatePhoneRequest = CreatePhoneRequest
 
 // ContactListResponse represents a paginated list of contacts
-type ContactListResponse struct {
-	Contacts   []*domain.Contact `json:"contacts"`
-	Total      int64             `json:"total"`
-	Page       int               `json:"page"`
-	PageSize   int               `json:"pageSize"`
-	TotalPages int64             `json:"totalPages"`
-}
+type ContactListResponse = ListResponse[*domain.Contact]
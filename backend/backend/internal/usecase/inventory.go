@@ -151,6 +151,12 @@ func (uc *InventoryUseCase) UpdateStock(ctx context.Context, req UpdateStockRequ
 		}
 	}
 
+	if req.VariantID != nil {
+		if _, err := uc.productRepo.GetVariantByID(ctx, req.ProductID, *req.VariantID); err != nil {
+			return nil, fmt.Errorf("product variant not found: %w", err)
+		}
+	}
+
 	// Get or create inventory item
 	item, err := uc.inventoryRepo.GetInventoryItem(ctx, req.WarehouseID, req.ProductID)
 	if err != nil {
@@ -177,6 +183,7 @@ func (uc *InventoryUseCase) UpdateStock(ctx context.Context, req UpdateStockRequ
 	}
 
 	previousQuantity := item.Quantity
+	previousReserved := item.ReservedQuantity
 
 	// Update quantities based on movement type
 	switch req.MovementType {
@@ -216,8 +223,18 @@ func (uc *InventoryUseCase) UpdateStock(ctx context.Context, req UpdateStockRequ
 	now := time.Now()
 	item.LastStockDate = &now
 
-	// Update inventory item
-	if err := uc.inventoryRepo.UpdateInventoryItem(ctx, item); err != nil {
+	// Update inventory item, recording on-hand changes in the ledger
+	var ledgerEntry *domain.InventoryMovement
+	if delta := item.Quantity - previousQuantity; delta != 0 {
+		ledgerEntry = &domain.InventoryMovement{
+			VariantID: req.VariantID,
+			Delta:     delta,
+			Reason:    string(req.MovementType),
+			Reference: req.Reference,
+			CreatedBy: req.UserID,
+		}
+	}
+	if err := uc.inventoryRepo.AdjustStockLevel(ctx, item, ledgerEntry, item.ReservedQuantity-previousReserved); err != nil {
 		return nil, err
 	}
 
@@ -249,7 +266,41 @@ func (uc *InventoryUseCase) UpdateStock(ctx context.Context, req UpdateStockRequ
 	return item, nil
 }
 
-// GetLowStockItems retrieves items that are below their reorder point
+// ReserveStock holds qty of a product's available stock for the quote or
+// invoice identified by ref
+func (uc *InventoryUseCase) ReserveStock(ctx context.Context, productID uint, qty int, ref string) error {
+	if qty <= 0 {
+		return fmt.Errorf("reservation quantity must be positive")
+	}
+	if ref == "" {
+		return fmt.Errorf("reservation reference is required")
+	}
+
+	if err := uc.inventoryRepo.ReserveStock(ctx, productID, qty, ref); err != nil {
+		uc.logger.Error("Failed to reserve stock",
+			zap.Uint("productId", productID), zap.String("reference", ref), zap.Error(err))
+		return err
+	}
+
+	uc.logger.Info("Stock reserved",
+		zap.Uint("productId", productID),
+		zap.Int("quantity", qty),
+		zap.String("reference", ref))
+	return nil
+}
+
+// ReleaseReservation returns the stock reserved under ref
+func (uc *InventoryUseCase) ReleaseReservation(ctx context.Context, ref string) error {
+	if err := uc.inventoryRepo.ReleaseReservation(ctx, ref); err != nil {
+		uc.logger.Error("Failed to release stock reservation", zap.String("reference", ref), zap.Error(err))
+		return err
+	}
+
+	uc.logger.Info("Stock reservation released", zap.String("reference", ref))
+	return nil
+}
+
+// GetLowStockItems retrieves items whose available stock is at or below their reorder point
 func (uc *InventoryUseCase) GetLowStockItems(ctx context.Context, warehouseID *uint) ([]domain.InventoryItem, error) {
 	items, err := uc.inventoryRepo.GetLowStockItems(ctx, warehouseID)
 	if err != nil {
@@ -276,6 +327,30 @@ func (uc *InventoryUseCase) GetStockMovements(ctx context.Context, req GetStockM
 	}, nil
 }
 
+// ListInventoryMovements retrieves the inventory ledger of a product
+func (uc *InventoryUseCase) ListInventoryMovements(ctx context.Context, req ListInventoryMovementsRequest) (*PaginatedResponse[domain.InventoryMovement], error) {
+	filters := repository.InventoryMovementFilters{
+		WarehouseID: req.WarehouseID,
+		From:        req.From,
+		To:          req.To,
+		Page:        req.Page,
+		PageSize:    req.PageSize,
+	}
+	movements, total, err := uc.inventoryRepo.ListMovements(ctx, req.ProductID, filters)
+	if err != nil {
+		uc.logger.Error("Failed to list inventory movements", zap.Error(err))
+		return nil, err
+	}
+
+	return &PaginatedResponse[domain.InventoryMovement]{
+		Data:       movements,
+		Total:      total,
+		Page:       req.Page,
+		PageSize:   req.PageSize,
+		TotalPages: (total + req.PageSize - 1) / req.PageSize,
+	}, nil
+}
+
 // Helper function to extract organization ID from context
 func getOrganizationIDFromContext(ctx context.Context) (uint, error) {
 	if orgID, ok := ctx.Value("organizationID").(uint); ok && orgID != 0 {
@@ -315,6 +390,7 @@ type UpdateStockRequest struct {
 	OrganizationID uint                     `json:"-"`
 	WarehouseID    uint                     `json:"warehouseId" validate:"required"`
 	ProductID      uint                     `json:"productId" validate:"required"`
+	VariantID      *uint                    `json:"variantId,omitempty"` // recorded on the ledger entry
 	MovementType   domain.StockMovementType `json:"movementType" validate:"required"`
 	Quantity       int                      `json:"quantity" validate:"required"`
 	AdjustmentType string                   `json:"adjustmentType"` // for adjustments: set, increase, decrease
@@ -331,3 +407,12 @@ type GetStockMovementsRequest struct {
 	Page            int  `json:"page" validate:"min=1"`
 	PageSize        int  `json:"pageSize" validate:"min=1,max=100"`
 }
+
+type ListInventoryMovementsRequest struct {
+	ProductID   uint       `json:"productId" validate:"required"`
+	WarehouseID *uint      `json:"warehouseId,omitempty"`
+	From        *time.Time `json:"from,omitempty"`
+	To          *time.Time `json:"to,omitempty"`
+	Page        int        `json:"page" validate:"min=1"`
+	PageSize    int        `json:"pageSize" validate:"min=1,max=100"`
+}
@@ -9,6 +9,7 @@ import (
 	reflect "reflect"
 
 	domain "github.com/pmaojo/kthulu-go/backend/internal/domain"
+	repository "github.com/pmaojo/kthulu-go/backend/internal/domain/repository"
 
 	gomock "github.com/golang/mock/gomock"
 )
@@ -36,6 +37,20 @@ func (m *MockInventoryRepository) EXPECT() *MockInventoryRepositoryMockRecorder
 	return m.recorder
 }
 
+// AdjustStockLevel mocks base method.
+func (m *MockInventoryRepository) AdjustStockLevel(ctx context.Context, item *domain.InventoryItem, movement *domain.InventoryMovement, reservedDelta int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AdjustStockLevel", ctx, item, movement, reservedDelta)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AdjustStockLevel indicates an expected call of AdjustStockLevel.
+func (mr *MockInventoryRepositoryMockRecorder) AdjustStockLevel(ctx, item, movement, reservedDelta interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AdjustStockLevel", reflect.TypeOf((*MockInventoryRepository)(nil).AdjustStockLevel), ctx, item, movement, reservedDelta)
+}
+
 // CreateInventoryItem mocks base method.
 func (m *MockInventoryRepository) CreateInventoryItem(ctx context.Context, item *domain.InventoryItem) error {
 	m.ctrl.T.Helper()
@@ -289,6 +304,22 @@ func (mr *MockInventoryRepositoryMockRecorder) ListInventoryItems(ctx, warehouse
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListInventoryItems", reflect.TypeOf((*MockInventoryRepository)(nil).ListInventoryItems), ctx, warehouseID, page, pageSize)
 }
 
+// ListMovements mocks base method.
+func (m *MockInventoryRepository) ListMovements(ctx context.Context, productID uint, filters repository.InventoryMovementFilters) ([]domain.InventoryMovement, int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListMovements", ctx, productID, filters)
+	ret0, _ := ret[0].([]domain.InventoryMovement)
+	ret1, _ := ret[1].(int)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ListMovements indicates an expected call of ListMovements.
+func (mr *MockInventoryRepositoryMockRecorder) ListMovements(ctx, productID, filters interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListMovements", reflect.TypeOf((*MockInventoryRepository)(nil).ListMovements), ctx, productID, filters)
+}
+
 // ListWarehouses mocks base method.
 func (m *MockInventoryRepository) ListWarehouses(ctx context.Context, page, pageSize int, search string) ([]domain.Warehouse, int, error) {
 	m.ctrl.T.Helper()
@@ -305,6 +336,34 @@ func (mr *MockInventoryRepositoryMockRecorder) ListWarehouses(ctx, page, pageSiz
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWarehouses", reflect.TypeOf((*MockInventoryRepository)(nil).ListWarehouses), ctx, page, pageSize, search)
 }
 
+// ReleaseReservation mocks base method.
+func (m *MockInventoryRepository) ReleaseReservation(ctx context.Context, ref string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReleaseReservation", ctx, ref)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReleaseReservation indicates an expected call of ReleaseReservation.
+func (mr *MockInventoryRepositoryMockRecorder) ReleaseReservation(ctx, ref interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseReservation", reflect.TypeOf((*MockInventoryRepository)(nil).ReleaseReservation), ctx, ref)
+}
+
+// ReserveStock mocks base method.
+func (m *MockInventoryRepository) ReserveStock(ctx context.Context, productID uint, qty int, ref string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReserveStock", ctx, productID, qty, ref)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReserveStock indicates an expected call of ReserveStock.
+func (mr *MockInventoryRepositoryMockRecorder) ReserveStock(ctx, productID, qty, ref interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReserveStock", reflect.TypeOf((*MockInventoryRepository)(nil).ReserveStock), ctx, productID, qty, ref)
+}
+
 // UpdateInventoryItem mocks base method.
 func (m *MockInventoryRepository) UpdateInventoryItem(ctx context.Context, item *domain.InventoryItem) error {
 	m.ctrl.T.Helper()
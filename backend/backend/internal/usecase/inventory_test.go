@@ -65,7 +65,12 @@ func TestInventoryUseCase_UpdateStock(t *testing.T) {
 
 	// Success path
 	mockRepo.EXPECT().GetInventoryItem(gomock.Any(), uint(1), uint(2)).Return(item, nil)
-	mockRepo.EXPECT().UpdateInventoryItem(gomock.Any(), gomock.Any()).Return(nil)
+	mockRepo.EXPECT().AdjustStockLevel(gomock.Any(), gomock.Any(), gomock.Any(), 0).
+		DoAndReturn(func(_ context.Context, _ *domain.InventoryItem, movement *domain.InventoryMovement, _ int) error {
+			assert.Equal(t, 5, movement.Delta)
+			assert.Equal(t, string(domain.StockMovementTypeReceive), movement.Reason)
+			return nil
+		})
 	mockRepo.EXPECT().CreateStockMovement(gomock.Any(), gomock.Any()).Return(nil)
 
 	req := UpdateStockRequest{OrganizationID: 1, WarehouseID: 1, ProductID: 2, MovementType: domain.StockMovementTypeReceive, Quantity: 5}
@@ -73,9 +78,24 @@ func TestInventoryUseCase_UpdateStock(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, 15, updated.Quantity)
 
+	// Reservations move by the reserved quantity alone, so a reservation
+	// stored since the item was read is kept
+	mockRepo.EXPECT().GetInventoryItem(gomock.Any(), uint(1), uint(2)).Return(&domain.InventoryItem{ID: 1, WarehouseID: 1, ProductID: 2, Quantity: 10, ReservedQuantity: 2}, nil)
+	mockRepo.EXPECT().AdjustStockLevel(gomock.Any(), gomock.Any(), nil, 3).Return(nil)
+	mockRepo.EXPECT().CreateStockMovement(gomock.Any(), gomock.Any()).Return(nil)
+	_, err = uc.UpdateStock(context.Background(), UpdateStockRequest{OrganizationID: 1, WarehouseID: 1, ProductID: 2, MovementType: domain.StockMovementTypeReserve, Quantity: 3})
+	assert.NoError(t, err)
+
+	// Releasing more than the item had reserved releases what it had
+	mockRepo.EXPECT().GetInventoryItem(gomock.Any(), uint(1), uint(2)).Return(&domain.InventoryItem{ID: 1, WarehouseID: 1, ProductID: 2, Quantity: 10, ReservedQuantity: 2}, nil)
+	mockRepo.EXPECT().AdjustStockLevel(gomock.Any(), gomock.Any(), nil, -2).Return(nil)
+	mockRepo.EXPECT().CreateStockMovement(gomock.Any(), gomock.Any()).Return(nil)
+	_, err = uc.UpdateStock(context.Background(), UpdateStockRequest{OrganizationID: 1, WarehouseID: 1, ProductID: 2, MovementType: domain.StockMovementTypeRelease, Quantity: 5})
+	assert.NoError(t, err)
+
 	// Error path - update failure
 	mockRepo.EXPECT().GetInventoryItem(gomock.Any(), uint(1), uint(2)).Return(item, nil)
-	mockRepo.EXPECT().UpdateInventoryItem(gomock.Any(), gomock.Any()).Return(errors.New("update fail"))
+	mockRepo.EXPECT().AdjustStockLevel(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("update fail"))
 
 	updated, err = uc.UpdateStock(context.Background(), req)
 	assert.Error(t, err)
@@ -5,27 +5,65 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/pmaojo/kthulu-go/backend/core"
 	"github.com/pmaojo/kthulu-go/backend/internal/domain"
+	"github.com/pmaojo/kthulu-go/backend/internal/domain/money"
 	"github.com/pmaojo/kthulu-go/backend/internal/domain/repository"
 )
 
 // InvoiceUseCase orchestrates invoice management workflows
 type InvoiceUseCase struct {
-	invoices repository.InvoiceRepository
-	logger   core.Logger
+	invoices      repository.InvoiceRepository
+	units         repository.UnitOfWork
+	contacts      repository.ContactRepository
+	organizations repository.OrganizationRepository
+	products      repository.ProductRepository
+	renderer      repository.InvoiceRenderer
+	notifier      repository.NotificationProvider
+	calendar      repository.CalendarRepository
+	pushes        repository.UserNotifier
+	rates         repository.ExchangeRateProvider
+	taxes         domain.TaxCalculator
+	rounding      money.Policy
+	logger        core.Logger
 }
 
 // NewInvoiceUseCase creates a new invoice use case instance
 func NewInvoiceUseCase(
 	invoices repository.InvoiceRepository,
+	units repository.UnitOfWork,
+	contacts repository.ContactRepository,
+	organizations repository.OrganizationRepository,
+	products repository.ProductRepository,
+	renderer repository.InvoiceRenderer,
+	notifier repository.NotificationProvider,
+	calendar repository.CalendarRepository,
+	pushes repository.UserNotifier,
+	rates repository.ExchangeRateProvider,
+	taxes domain.TaxCalculator,
+	rounding money.Policy,
 	logger core.Logger,
 ) *InvoiceUseCase {
+	if taxes == nil {
+		taxes = domain.FlatRateTaxCalculator{}
+	}
 	return &InvoiceUseCase{
-		invoices: invoices,
-		logger:   logger,
+		invoices:      invoices,
+		units:         units,
+		contacts:      contacts,
+		organizations: organizations,
+		products:      products,
+		renderer:      renderer,
+		notifier:      notifier,
+		calendar:      calendar,
+		pushes:        pushes,
+		rates:         rates,
+		taxes:         taxes,
+		rounding:      rounding,
+		logger:        logger,
 	}
 }
 
@@ -35,6 +73,7 @@ type CreateInvoiceRequest struct {
 	ContactID       uint                       `json:"contactId" validate:"required"`
 	Type            domain.InvoiceType         `json:"type" validate:"required,oneof=invoice quote credit_note proforma"`
 	Currency        string                     `json:"currency" validate:"required,len=3"`
+	ExchangeRate    *float64                   `json:"exchangeRate,omitempty" validate:"omitempty,gt=0"` // Looked up when omitted
 	IssueDate       time.Time                  `json:"issueDate" validate:"required"`
 	DueDate         *time.Time                 `json:"dueDate,omitempty"`
 	PaymentTerms    string                     `json:"paymentTerms,omitempty" validate:"max=50"`
@@ -52,7 +91,11 @@ type CreateInvoiceItemRequest struct {
 	Quantity         float64 `json:"quantity" validate:"required,min=0"`
 	UnitPrice        float64 `json:"unitPrice" validate:"required,min=0"`
 	DiscountPercent  float64 `json:"discountPercent" validate:"min=0,max=1"`
+	DiscountAmount   float64 `json:"discountAmount" validate:"min=0"`
 	TaxRate          float64 `json:"taxRate" validate:"min=0,max=1"`
+	// LineTotal is the total the client computed for the line. When set it
+	// must match the line total computed from the other fields.
+	LineTotal *float64 `json:"lineTotal,omitempty"`
 }
 
 // UpdateInvoiceRequest contains the data needed to update an invoice
@@ -64,13 +107,56 @@ type UpdateInvoiceRequest struct {
 	TermsConditions string     `json:"termsConditions,omitempty"`
 }
 
+// PatchInvoiceRequest contains the invoice fields to change; nil fields are
+// left as they are
+type PatchInvoiceRequest struct {
+	ContactID       *uint      `json:"contactId,omitempty" validate:"omitempty,min=1"`
+	IssueDate       *time.Time `json:"issueDate,omitempty"`
+	DueDate         *time.Time `json:"dueDate,omitempty"`
+	PaymentTerms    *string    `json:"paymentTerms,omitempty" validate:"omitempty,max=50"`
+	Notes           *string    `json:"notes,omitempty"`
+	TermsConditions *string    `json:"termsConditions,omitempty"`
+}
+
+// apply sets the given fields on invoice, trimming texts as UpdateBasicInfo
+// does, and maps them to their invoice columns
+func (req PatchInvoiceRequest) apply(invoice *domain.Invoice) map[string]any {
+	fields := make(map[string]any)
+	if req.ContactID != nil {
+		invoice.ContactID = *req.ContactID
+		fields["contact_id"] = invoice.ContactID
+	}
+	if req.IssueDate != nil {
+		invoice.IssueDate = *req.IssueDate
+		fields["issue_date"] = invoice.IssueDate
+	}
+	if req.DueDate != nil {
+		dueDate := *req.DueDate
+		invoice.DueDate = &dueDate
+		fields["due_date"] = dueDate
+	}
+	if req.PaymentTerms != nil {
+		invoice.PaymentTerms = strings.TrimSpace(*req.PaymentTerms)
+		fields["payment_terms"] = invoice.PaymentTerms
+	}
+	if req.Notes != nil {
+		invoice.Notes = strings.TrimSpace(*req.Notes)
+		fields["notes"] = invoice.Notes
+	}
+	if req.TermsConditions != nil {
+		invoice.TermsConditions = strings.TrimSpace(*req.TermsConditions)
+		fields["terms_conditions"] = invoice.TermsConditions
+	}
+	return fields
+}
+
 // CreatePaymentRequest contains the data needed to create a payment
 type CreatePaymentRequest struct {
 	OrganizationID  uint                 `json:"organizationId" validate:"required"`
 	InvoiceID       uint                 `json:"invoiceId" validate:"required"`
 	PaymentMethod   domain.PaymentMethod `json:"paymentMethod" validate:"required,oneof=cash check credit_card bank_transfer paypal stripe other"`
 	ReferenceNumber string               `json:"referenceNumber,omitempty" validate:"max=100"`
-	Amount          float64              `json:"amount" validate:"required,min=0"`
+	Amount          float64              `json:"amount" validate:"required"` // Negative amounts are refunds
 	Currency        string               `json:"currency" validate:"required,len=3"`
 	PaymentDate     time.Time            `json:"paymentDate" validate:"required"`
 	Notes           string               `json:"notes,omitempty"`
@@ -78,99 +164,217 @@ type CreatePaymentRequest struct {
 }
 
 // InvoiceListResponse represents a paginated list of invoices
-type InvoiceListResponse struct {
-	Invoices   []*domain.Invoice `json:"invoices"`
-	Total      int64             `json:"total"`
-	Page       int               `json:"page"`
-	PageSize   int               `json:"pageSize"`
-	TotalPages int64             `json:"totalPages"`
-}
+type InvoiceListResponse = ListResponse[*domain.Invoice]
 
 // PaymentListResponse represents a paginated list of payments
-type PaymentListResponse struct {
-	Payments   []*domain.Payment `json:"payments"`
-	Total      int64             `json:"total"`
-	Page       int               `json:"page"`
-	PageSize   int               `json:"pageSize"`
-	TotalPages int64             `json:"totalPages"`
-}
+type PaymentListResponse = ListResponse[*domain.Payment]
 
 // CreateInvoice creates a new invoice
 func (uc *InvoiceUseCase) CreateInvoice(ctx context.Context, req CreateInvoiceRequest) (*domain.Invoice, error) {
 	uc.logger.Info("Creating new invoice", "organizationId", req.OrganizationID, "contactId", req.ContactID)
 
-	// Generate invoice number
-	invoiceNumber, err := uc.invoices.GenerateInvoiceNumber(ctx, req.OrganizationID, req.Type)
+	// Look the rate and the tax parties up before opening the transaction
+	exchangeRate, err := uc.exchangeRate(ctx, req)
 	if err != nil {
-		uc.logger.Error("Failed to generate invoice number", "error", err)
-		return nil, fmt.Errorf("failed to generate invoice number: %w", err)
+		return nil, err
+	}
+	var sale domain.TaxSale
+	if len(req.Items) > 0 {
+		if sale, err = uc.taxSale(ctx, req.OrganizationID, req.ContactID); err != nil {
+			return nil, err
+		}
 	}
 
-	// Create invoice domain entity
-	invoice, err := domain.NewInvoice(
-		req.OrganizationID, req.ContactID, req.CreatedBy,
-		invoiceNumber, req.Type, req.Currency, req.IssueDate,
-	)
+	var invoice *domain.Invoice
+	err = uc.units.Do(ctx, func(repos repository.TxRepositories) error {
+		// Generate the number in the same transaction as the insert so the
+		// counter stays locked until the invoice is stored
+		invoiceNumber, err := repos.Invoices.GenerateInvoiceNumber(ctx, req.OrganizationID, req.Type)
+		if err != nil {
+			uc.logger.Error("Failed to generate invoice number", "error", err)
+			return fmt.Errorf("failed to generate invoice number: %w", err)
+		}
+
+		// Create invoice domain entity
+		invoice, err = domain.NewInvoice(
+			req.OrganizationID, req.ContactID, req.CreatedBy,
+			invoiceNumber, req.Type, req.Currency, req.IssueDate,
+		)
+		if err != nil {
+			uc.logger.Error("Failed to create invoice domain entity", "error", err)
+			return fmt.Errorf("failed to create invoice: %w", err)
+		}
+		invoice.ExchangeRate = exchangeRate
+
+		// Update additional properties
+		if err := invoice.UpdateBasicInfo(req.ContactID, req.DueDate, req.PaymentTerms, req.Notes, req.TermsConditions); err != nil {
+			uc.logger.Error("Failed to update invoice basic info", "error", err)
+			return fmt.Errorf("failed to update invoice info: %w", err)
+		}
+
+		// Persist invoice
+		if err := repos.Invoices.Create(ctx, invoice); err != nil {
+			uc.logger.Error("Failed to persist invoice", "error", err)
+			return fmt.Errorf("failed to create invoice: %w", err)
+		}
+
+		// Create invoice items if provided
+		if len(req.Items) > 0 {
+			for i, itemReq := range req.Items {
+				item, err := uc.newInvoiceItem(ctx, invoice, sale, itemReq)
+				if err != nil {
+					uc.logger.Warn("Invalid invoice item", "error", err, "itemIndex", i)
+					return fmt.Errorf("invalid invoice item %d: %w", i, err)
+				}
+				item.SortOrder = i
+
+				// Persist item
+				if err := repos.Invoices.CreateItem(ctx, item); err != nil {
+					uc.logger.Error("Failed to persist invoice item", "error", err, "itemIndex", i)
+					return fmt.Errorf("failed to create invoice item %d: %w", i, err)
+				}
+
+				invoice.Items = append(invoice.Items, *item)
+			}
+
+			// Recalculate totals
+			invoice.RecalculateTotals(uc.rounding)
+
+			// Update invoice with calculated totals
+			if err := repos.Invoices.Update(ctx, invoice); err != nil {
+				uc.logger.Error("Failed to update invoice totals", "error", err)
+				return fmt.Errorf("failed to update invoice totals: %w", err)
+			}
+		}
+		return nil
+	})
 	if err != nil {
-		uc.logger.Error("Failed to create invoice domain entity", "error", err)
-		return nil, fmt.Errorf("failed to create invoice: %w", err)
+		return nil, err
 	}
 
-	// Update additional properties
-	if err := invoice.UpdateBasicInfo(req.ContactID, req.DueDate, req.PaymentTerms, req.Notes, req.TermsConditions); err != nil {
-		uc.logger.Error("Failed to update invoice basic info", "error", err)
-		return nil, fmt.Errorf("failed to update invoice info: %w", err)
-	}
+	uc.logger.Info("Invoice created successfully", "invoiceId", invoice.ID, "invoiceNumber", invoice.InvoiceNumber)
+	return invoice, nil
+}
 
-	// Persist invoice
-	if err := uc.invoices.Create(ctx, invoice); err != nil {
-		uc.logger.Error("Failed to persist invoice", "error", err)
-		return nil, fmt.Errorf("failed to create invoice: %w", err)
+// exchangeRate returns the rate a new invoice is stored with: the one given
+// in req, else the provider's rate on the issue date for invoices in a
+// currency other than the base one, else 1
+func (uc *InvoiceUseCase) exchangeRate(ctx context.Context, req CreateInvoiceRequest) (float64, error) {
+	if req.ExchangeRate != nil {
+		return *req.ExchangeRate, nil
+	}
+	if uc.rates == nil || strings.EqualFold(req.Currency, uc.rates.BaseCurrency()) {
+		return 1.0, nil
 	}
 
-	// Create invoice items if provided
-	if len(req.Items) > 0 {
-		for i, itemReq := range req.Items {
-			item, err := domain.NewInvoiceItem(
-				invoice.ID, itemReq.Description, itemReq.Quantity, itemReq.UnitPrice,
-			)
-			if err != nil {
-				uc.logger.Error("Failed to create invoice item", "error", err, "itemIndex", i)
-				return nil, fmt.Errorf("failed to create invoice item %d: %w", i, err)
-			}
+	rate, err := uc.rates.Rate(ctx, req.Currency, req.IssueDate)
+	if err != nil {
+		uc.logger.Error("Failed to look up exchange rate", "error", err, "currency", req.Currency)
+		return 0, fmt.Errorf("failed to look up %s exchange rate: %w", req.Currency, err)
+	}
+	return rate, nil
+}
 
-			// Update item properties
-			if err := item.UpdateBasicInfo(
-				itemReq.ProductID, itemReq.ProductVariantID, itemReq.Description,
-				itemReq.Quantity, itemReq.UnitPrice, itemReq.DiscountPercent, itemReq.TaxRate,
-			); err != nil {
-				uc.logger.Error("Failed to update invoice item info", "error", err, "itemIndex", i)
-				return nil, fmt.Errorf("failed to update invoice item %d: %w", i, err)
-			}
+// taxSale describes the sales of an invoice for tax purposes, from the
+// countries of the organization and of the contact's billing address. Missing
+// records leave their fields empty.
+func (uc *InvoiceUseCase) taxSale(ctx context.Context, organizationID, contactID uint) (domain.TaxSale, error) {
+	var sale domain.TaxSale
+	if uc.organizations != nil {
+		org, err := uc.organizations.FindByID(ctx, organizationID)
+		switch {
+		case err == nil:
+			sale.SellerCountry = org.Country
+		case !errors.Is(err, domain.ErrOrganizationNotFound):
+			uc.logger.Error("Failed to get organization for tax calculation", "error", err, "organizationId", organizationID)
+			return sale, fmt.Errorf("failed to get organization: %w", err)
+		}
+	}
+	if uc.contacts == nil {
+		return sale, nil
+	}
 
-			item.SortOrder = i
+	contact, err := uc.contacts.GetByID(ctx, organizationID, contactID)
+	if err != nil {
+		if errors.Is(err, domain.ErrContactNotFound) {
+			return sale, nil
+		}
+		uc.logger.Error("Failed to get contact for tax calculation", "error", err, "contactId", contactID)
+		return sale, fmt.Errorf("failed to get contact: %w", err)
+	}
+	sale.BuyerTaxNumber = contact.TaxNumber
 
-			// Persist item
-			if err := uc.invoices.CreateItem(ctx, item); err != nil {
-				uc.logger.Error("Failed to persist invoice item", "error", err, "itemIndex", i)
-				return nil, fmt.Errorf("failed to create invoice item %d: %w", i, err)
-			}
+	addresses, err := uc.contacts.GetAddressesByContactID(ctx, contactID)
+	if err != nil {
+		uc.logger.Error("Failed to get contact addresses for tax calculation", "error", err, "contactId", contactID)
+		return sale, fmt.Errorf("failed to get contact addresses: %w", err)
+	}
+	sale.BuyerCountry = billingCountry(addresses)
+	return sale, nil
+}
 
-			invoice.Items = append(invoice.Items, *item)
+// billingCountry returns the country of the primary billing address, falling
+// back to the primary address and then to the first one
+func billingCountry(addresses []*domain.ContactAddress) string {
+	var primary, first *domain.ContactAddress
+	for _, address := range addresses {
+		if address.Type == domain.AddressTypeBilling && address.IsPrimary {
+			return address.Country
+		}
+		if address.IsPrimary && primary == nil {
+			primary = address
+		}
+		if first == nil {
+			first = address
 		}
+	}
+	if primary != nil {
+		return primary.Country
+	}
+	if first != nil {
+		return first.Country
+	}
+	return ""
+}
 
-		// Recalculate totals
-		invoice.CalculateTotals()
+// newInvoiceItem builds a line of invoice from req, computing its amounts
+// from the quantity, unit price, discount and the tax rate the tax calculator
+// determines for sale. Inconsistent input is rejected with
+// domain.ErrInvalidLineItem.
+func (uc *InvoiceUseCase) newInvoiceItem(ctx context.Context, invoice *domain.Invoice, sale domain.TaxSale, req CreateInvoiceItemRequest) (*domain.InvoiceItem, error) {
+	item, err := domain.NewInvoiceItem(invoice.ID, req.Description, req.Quantity, req.UnitPrice)
+	if err != nil {
+		return nil, err
+	}
 
-		// Update invoice with calculated totals
-		if err := uc.invoices.Update(ctx, invoice); err != nil {
-			uc.logger.Error("Failed to update invoice totals", "error", err)
-			return nil, fmt.Errorf("failed to update invoice totals: %w", err)
+	sale.Rate = req.TaxRate
+	if req.ProductID != nil && uc.products != nil {
+		product, err := uc.products.GetByID(ctx, invoice.OrganizationID, *req.ProductID)
+		switch {
+		case err == nil:
+			sale.ProductCategory = product.Category
+		case !errors.Is(err, domain.ErrProductNotFound):
+			return nil, fmt.Errorf("failed to get product: %w", err)
 		}
 	}
+	tax := uc.taxes.Calculate(sale)
+	item.ReverseCharge = tax.ReverseCharge
 
-	uc.logger.Info("Invoice created successfully", "invoiceId", invoice.ID, "invoiceNumber", invoice.InvoiceNumber)
-	return invoice, nil
+	if err := item.UpdateBasicInfo(
+		req.ProductID, req.ProductVariantID, req.Description,
+		req.Quantity, req.UnitPrice, req.DiscountPercent, tax.Rate,
+	); err != nil {
+		return nil, err
+	}
+	if err := item.SetDiscount(req.DiscountPercent, req.DiscountAmount, uc.rounding, invoice.Currency); err != nil {
+		return nil, err
+	}
+
+	item.RecalculateAmounts(uc.rounding, invoice.Currency)
+	if req.LineTotal != nil && uc.rounding.Round(*req.LineTotal, invoice.Currency) != item.LineTotal {
+		return nil, fmt.Errorf("%w: line total %.2f does not match computed %.2f", domain.ErrInvalidLineItem, *req.LineTotal, item.LineTotal)
+	}
+	return item, nil
 }
 
 // GetInvoice retrieves an invoice by ID
@@ -190,6 +394,55 @@ func (uc *InvoiceUseCase) GetInvoice(ctx context.Context, organizationID, invoic
 	return invoice, nil
 }
 
+// ListInvoiceEvents retrieves the calendar events linked to an invoice of the
+// organization
+func (uc *InvoiceUseCase) ListInvoiceEvents(ctx context.Context, organizationID, invoiceID uint) ([]domain.Event, error) {
+	if _, err := uc.GetInvoice(ctx, organizationID, invoiceID); err != nil {
+		return nil, err
+	}
+	if uc.calendar == nil {
+		return []domain.Event{}, nil
+	}
+
+	events, err := uc.calendar.ListEventsForInvoice(ctx, organizationID, invoiceID)
+	if err != nil {
+		uc.logger.Error("Failed to list invoice events", "error", err, "invoiceId", invoiceID)
+		return nil, fmt.Errorf("failed to list invoice events: %w", err)
+	}
+	if events == nil {
+		events = []domain.Event{}
+	}
+	return events, nil
+}
+
+// GetInvoiceItems retrieves the items of an invoice of the organization
+func (uc *InvoiceUseCase) GetInvoiceItems(ctx context.Context, organizationID, invoiceID uint) ([]*domain.InvoiceItem, error) {
+	if _, err := uc.GetInvoice(ctx, organizationID, invoiceID); err != nil {
+		return nil, err
+	}
+
+	items, err := uc.invoices.GetItemsByInvoiceID(ctx, invoiceID)
+	if err != nil {
+		uc.logger.Error("Failed to get invoice items", "error", err, "invoiceId", invoiceID)
+		return nil, fmt.Errorf("failed to get invoice items: %w", err)
+	}
+	return items, nil
+}
+
+// GetInvoicePayments retrieves the payments of an invoice of the organization
+func (uc *InvoiceUseCase) GetInvoicePayments(ctx context.Context, organizationID, invoiceID uint) ([]*domain.Payment, error) {
+	if _, err := uc.GetInvoice(ctx, organizationID, invoiceID); err != nil {
+		return nil, err
+	}
+
+	payments, err := uc.invoices.GetPaymentsByInvoiceID(ctx, invoiceID)
+	if err != nil {
+		uc.logger.Error("Failed to get invoice payments", "error", err, "invoiceId", invoiceID)
+		return nil, fmt.Errorf("failed to get invoice payments: %w", err)
+	}
+	return payments, nil
+}
+
 // GetInvoiceByNumber retrieves an invoice by number
 func (uc *InvoiceUseCase) GetInvoiceByNumber(ctx context.Context, organizationID uint, invoiceNumber string) (*domain.Invoice, error) {
 	uc.logger.Info("Getting invoice by number", "organizationId", organizationID, "invoiceNumber", invoiceNumber)
@@ -244,6 +497,52 @@ func (uc *InvoiceUseCase) UpdateInvoice(ctx context.Context, organizationID, inv
 	return invoice, nil
 }
 
+// PatchInvoice updates only the fields set in req, leaving every other
+// column of the invoice untouched
+func (uc *InvoiceUseCase) PatchInvoice(ctx context.Context, organizationID, invoiceID uint, req PatchInvoiceRequest) (*domain.Invoice, error) {
+	uc.logger.Info("Patching invoice", "organizationId", organizationID, "invoiceId", invoiceID)
+
+	invoice, err := uc.invoices.GetByID(ctx, organizationID, invoiceID)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvoiceNotFound) {
+			uc.logger.Warn("Invoice not found for patch", "invoiceId", invoiceID, "organizationId", organizationID)
+			return nil, domain.ErrInvoiceNotFound
+		}
+		uc.logger.Error("Failed to get invoice for patch", "error", err, "invoiceId", invoiceID)
+		return nil, fmt.Errorf("failed to get invoice: %w", err)
+	}
+
+	if !invoice.CanEdit() {
+		uc.logger.Warn("Attempt to patch non-editable invoice", "invoiceId", invoiceID, "status", invoice.Status)
+		return nil, domain.ErrInvoiceNotEditable
+	}
+
+	fields := req.apply(invoice)
+	if len(fields) == 0 {
+		return invoice, nil
+	}
+	if err := invoice.Validate(); err != nil {
+		uc.logger.Warn("Invoice patch rejected", "error", err, "invoiceId", invoiceID)
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidInvoiceField, err)
+	}
+	if err := uc.invoices.UpdateFields(ctx, organizationID, invoiceID, fields); err != nil {
+		if errors.Is(err, domain.ErrInvoiceNotFound) {
+			return nil, domain.ErrInvoiceNotFound
+		}
+		uc.logger.Error("Failed to patch invoice", "error", err, "invoiceId", invoiceID)
+		return nil, fmt.Errorf("failed to update invoice: %w", err)
+	}
+
+	invoice, err = uc.invoices.GetByID(ctx, organizationID, invoiceID)
+	if err != nil {
+		uc.logger.Error("Failed to reload patched invoice", "error", err, "invoiceId", invoiceID)
+		return nil, fmt.Errorf("failed to get invoice: %w", err)
+	}
+
+	uc.logger.Info("Invoice patched successfully", "invoiceId", invoiceID)
+	return invoice, nil
+}
+
 // DeleteInvoice deletes an invoice
 func (uc *InvoiceUseCase) DeleteInvoice(ctx context.Context, organizationID, invoiceID uint) error {
 	uc.logger.Info("Deleting invoice", "organizationId", organizationID, "invoiceId", invoiceID)
@@ -291,18 +590,46 @@ func (uc *InvoiceUseCase) ListInvoices(ctx context.Context, organizationID uint,
 		return nil, fmt.Errorf("failed to list invoices: %w", err)
 	}
 
-	response := &InvoiceListResponse{
-		Invoices:   invoices,
-		Total:      total,
-		Page:       filters.Page,
-		PageSize:   filters.PageSize,
-		TotalPages: (total + int64(filters.PageSize) - 1) / int64(filters.PageSize),
-	}
+	response := NewListResponse(invoices, total, filters.Page, filters.PageSize)
 
 	uc.logger.Info("Invoices listed successfully", "organizationId", organizationID, "count", len(invoices), "total", total)
 	return response, nil
 }
 
+// ListContactInvoices lists a page of the invoices of each of the contacts,
+// keyed by contact, loading all of them at once
+func (uc *InvoiceUseCase) ListContactInvoices(ctx context.Context, organizationID uint, contactIDs []uint, filters repository.InvoiceFilters) (map[uint]*InvoiceListResponse, error) {
+	if err := filters.Validate(); err != nil {
+		uc.logger.Error("Invalid invoice filters", "error", err, "filters", filters)
+		return nil, fmt.Errorf("invalid filters: %w", err)
+	}
+
+	invoices, totals, err := uc.invoices.ListByContacts(ctx, organizationID, contactIDs, filters)
+	if err != nil {
+		uc.logger.Error("Failed to list contact invoices", "error", err, "organizationId", organizationID)
+		return nil, fmt.Errorf("failed to list invoices: %w", err)
+	}
+
+	lists := make(map[uint]*InvoiceListResponse, len(contactIDs))
+	for _, contactID := range contactIDs {
+		lists[contactID] = NewListResponse(invoices[contactID], totals[contactID], filters.Page, filters.PageSize)
+	}
+	return lists, nil
+}
+
+// ExportInvoices calls fn for every invoice matching filters, ignoring their
+// pagination. Invoices are streamed from the repository so exports of large
+// organizations don't have to be held in memory.
+func (uc *InvoiceUseCase) ExportInvoices(ctx context.Context, organizationID uint, filters repository.InvoiceFilters, fn func(*domain.Invoice) error) error {
+	uc.logger.Info("Exporting invoices", "organizationId", organizationID, "filters", filters)
+
+	if err := uc.invoices.ListStream(ctx, organizationID, filters, fn); err != nil {
+		uc.logger.Error("Failed to export invoices", "error", err, "organizationId", organizationID)
+		return fmt.Errorf("failed to export invoices: %w", err)
+	}
+	return nil
+}
+
 // SetInvoiceStatus sets the status of an invoice
 func (uc *InvoiceUseCase) SetInvoiceStatus(ctx context.Context, organizationID, invoiceID uint, status domain.InvoiceStatus) error {
 	uc.logger.Info("Setting invoice status", "organizationId", organizationID, "invoiceId", invoiceID, "status", status)
@@ -318,14 +645,31 @@ func (uc *InvoiceUseCase) SetInvoiceStatus(ctx context.Context, organizationID,
 		return fmt.Errorf("failed to get invoice: %w", err)
 	}
 
+	// Enforce the status state machine before anything is persisted
+	if err := invoice.CanTransitionTo(status); err != nil {
+		uc.logger.Warn("Rejected invoice status transition", "error", err, "invoiceId", invoiceID, "from", invoice.Status, "to", status)
+		return err
+	}
+
 	// Set status
+	previous := invoice.Status
 	if err := invoice.SetStatus(status); err != nil {
 		uc.logger.Error("Failed to set invoice status", "error", err, "invoiceId", invoiceID, "status", status)
 		return fmt.Errorf("failed to set invoice status: %w", err)
 	}
 
-	// Persist changes
-	if err := uc.invoices.Update(ctx, invoice); err != nil {
+	// Persist changes, together with the webhook event of the transition
+	event, err := statusEvent(invoice, previous)
+	if err != nil {
+		uc.logger.Error("Failed to build invoice status event", "error", err, "invoiceId", invoiceID)
+		return err
+	}
+	if event != nil {
+		err = uc.invoices.UpdateWithEvent(ctx, invoice, event)
+	} else {
+		err = uc.invoices.Update(ctx, invoice)
+	}
+	if err != nil {
 		uc.logger.Error("Failed to persist invoice status update", "error", err, "invoiceId", invoiceID)
 		return fmt.Errorf("failed to update invoice status: %w", err)
 	}
@@ -334,27 +678,254 @@ func (uc *InvoiceUseCase) SetInvoiceStatus(ctx context.Context, organizationID,
 	return nil
 }
 
-// CreatePayment creates a new payment for an invoice
-func (uc *InvoiceUseCase) CreatePayment(ctx context.Context, req CreatePaymentRequest) (*domain.Payment, error) {
-	uc.logger.Info("Creating payment", "organizationId", req.OrganizationID, "invoiceId", req.InvoiceID)
+// SendInvoice renders the invoice as a PDF and emails it to its contact.
+// Draft invoices are marked as sent once the email has gone out; other
+// invoices keep their status so reminders can be sent again.
+func (uc *InvoiceUseCase) SendInvoice(ctx context.Context, organizationID, invoiceID uint) error {
+	uc.logger.Info("Sending invoice", "organizationId", organizationID, "invoiceId", invoiceID)
 
-	// Get invoice to validate payment
-	invoice, err := uc.invoices.GetByID(ctx, req.OrganizationID, req.InvoiceID)
+	invoice, err := uc.invoices.GetByID(ctx, organizationID, invoiceID)
 	if err != nil {
 		if errors.Is(err, domain.ErrInvoiceNotFound) {
-			uc.logger.Warn("Invoice not found for payment", "invoiceId", req.InvoiceID, "organizationId", req.OrganizationID)
-			return nil, domain.ErrInvoiceNotFound
+			uc.logger.Warn("Invoice not found for sending", "invoiceId", invoiceID, "organizationId", organizationID)
+			return domain.ErrInvoiceNotFound
 		}
-		uc.logger.Error("Failed to get invoice for payment", "error", err, "invoiceId", req.InvoiceID)
-		return nil, fmt.Errorf("failed to get invoice: %w", err)
+		uc.logger.Error("Failed to get invoice for sending", "error", err, "invoiceId", invoiceID)
+		return fmt.Errorf("failed to get invoice: %w", err)
+	}
+	if invoice.Status == domain.InvoiceStatusCancelled {
+		uc.logger.Warn("Attempt to send canceled invoice", "invoiceId", invoiceID)
+		return domain.ErrInvoiceNotSendable
+	}
+
+	contact, err := uc.contacts.GetByID(ctx, organizationID, invoice.ContactID)
+	if err != nil {
+		uc.logger.Error("Failed to get invoice contact", "error", err, "invoiceId", invoiceID, "contactId", invoice.ContactID)
+		return fmt.Errorf("failed to get invoice contact: %w", err)
+	}
+	if contact.Email == "" {
+		uc.logger.Warn("Invoice contact has no email", "invoiceId", invoiceID, "contactId", invoice.ContactID)
+		return domain.ErrInvoiceNoRecipient
 	}
 
-	// Validate payment amount
-	if req.Amount > invoice.BalanceDue {
-		uc.logger.Warn("Payment amount exceeds balance due", "amount", req.Amount, "balanceDue", invoice.BalanceDue)
-		return nil, domain.ErrInsufficientPayment
+	items, err := uc.invoices.GetItemsByInvoiceID(ctx, invoice.ID)
+	if err != nil {
+		uc.logger.Error("Failed to get invoice items", "error", err, "invoiceId", invoiceID)
+		return fmt.Errorf("failed to get invoice items: %w", err)
+	}
+	invoice.Items = make([]domain.InvoiceItem, len(items))
+	for i, item := range items {
+		invoice.Items[i] = *item
+	}
+
+	document, err := uc.renderer.RenderPDF(ctx, invoice)
+	if err != nil {
+		uc.logger.Error("Failed to render invoice", "error", err, "invoiceId", invoiceID)
+		return fmt.Errorf("failed to render invoice: %w", err)
+	}
+
+	attachment := repository.NotificationAttachment{
+		Filename:    fmt.Sprintf("invoice-%s.pdf", invoice.InvoiceNumber),
+		ContentType: "application/pdf",
+		Content:     document,
+	}
+	if err := uc.notifier.SendInvoiceEmail(ctx, contact.Email, invoice.InvoiceNumber, attachment); err != nil {
+		uc.logger.Error("Failed to email invoice", "error", err, "invoiceId", invoiceID)
+		return fmt.Errorf("failed to send invoice email: %w", err)
+	}
+
+	if invoice.Status == domain.InvoiceStatusDraft {
+		if err := invoice.SetStatus(domain.InvoiceStatusSent); err != nil {
+			return err
+		}
+		if err := uc.invoices.Update(ctx, invoice); err != nil {
+			uc.logger.Error("Failed to mark invoice as sent", "error", err, "invoiceId", invoiceID)
+			return fmt.Errorf("failed to update invoice status: %w", err)
+		}
+	}
+
+	notifyUser(ctx, uc.pushes, uc.logger, invoice.CreatedBy, repository.UserNotificationInvoiceSent, map[string]interface{}{
+		"organizationId": invoice.OrganizationID,
+		"invoiceId":      invoice.ID,
+		"invoiceNumber":  invoice.InvoiceNumber,
+		"to":             contact.Email,
+	})
+
+	uc.logger.Info("Invoice sent successfully", "invoiceId", invoiceID, "to", contact.Email)
+	return nil
+}
+
+// BulkStatusRequest contains the invoices to move to a new status
+type BulkStatusRequest struct {
+	IDs    []uint               `json:"ids" validate:"required,min=1,max=500"`
+	Status domain.InvoiceStatus `json:"status" validate:"required"`
+}
+
+// BulkStatusSkip explains why an invoice was left untouched by a bulk update
+type BulkStatusSkip struct {
+	ID     uint   `json:"id"`
+	Reason string `json:"reason"`
+}
+
+// BulkStatusResult reports the outcome of a bulk status update
+type BulkStatusResult struct {
+	Updated int              `json:"updated"`
+	Skipped int              `json:"skipped"`
+	Skips   []BulkStatusSkip `json:"skips,omitempty"`
+}
+
+// BulkSetInvoiceStatus moves every invoice whose current status allows it to
+// the requested status. Invoices that are missing or cannot make the
+// transition are skipped and reported instead of failing the whole batch.
+func (uc *InvoiceUseCase) BulkSetInvoiceStatus(ctx context.Context, organizationID uint, req BulkStatusRequest) (*BulkStatusResult, error) {
+	uc.logger.Info("Bulk setting invoice status", "organizationId", organizationID, "count", len(req.IDs), "status", req.Status)
+
+	if !req.Status.IsValid() {
+		return nil, fmt.Errorf("%w: %q", domain.ErrInvalidInvoiceStatus, req.Status)
+	}
+
+	result := &BulkStatusResult{}
+	skip := func(id uint, reason string) {
+		result.Skipped++
+		result.Skips = append(result.Skips, BulkStatusSkip{ID: id, Reason: reason})
+	}
+
+	seen := make(map[uint]bool, len(req.IDs))
+	var eligible []*domain.Invoice
+	for _, id := range req.IDs {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		invoice, err := uc.invoices.GetByID(ctx, organizationID, id)
+		if err != nil {
+			if errors.Is(err, domain.ErrInvoiceNotFound) {
+				skip(id, domain.ErrInvoiceNotFound.Error())
+				continue
+			}
+			uc.logger.Error("Failed to get invoice for bulk status update", "error", err, "invoiceId", id)
+			return nil, fmt.Errorf("failed to get invoice: %w", err)
+		}
+		if err := invoice.CanTransitionTo(req.Status); err != nil {
+			skip(id, err.Error())
+			continue
+		}
+		eligible = append(eligible, invoice)
+	}
+
+	if len(eligible) == 0 {
+		return result, nil
+	}
+
+	// Transitions customers subscribe to need their outbox event, which is
+	// stored per invoice; everything else goes through a single update
+	if req.Status == domain.InvoiceStatusPaid || req.Status == domain.InvoiceStatusOverdue {
+		for _, invoice := range eligible {
+			previous := invoice.Status
+			if err := invoice.SetStatus(req.Status); err != nil {
+				skip(invoice.ID, err.Error())
+				continue
+			}
+			event, err := statusEvent(invoice, previous)
+			if err != nil {
+				return nil, err
+			}
+			if event != nil {
+				err = uc.invoices.UpdateWithEvent(ctx, invoice, event)
+			} else {
+				err = uc.invoices.Update(ctx, invoice)
+			}
+			if err != nil {
+				uc.logger.Error("Failed to persist bulk invoice status update", "error", err, "invoiceId", invoice.ID)
+				return nil, fmt.Errorf("failed to update invoice status: %w", err)
+			}
+			result.Updated++
+		}
+	} else {
+		ids := make([]uint, len(eligible))
+		for i, invoice := range eligible {
+			ids[i] = invoice.ID
+		}
+		if err := uc.invoices.BulkUpdateStatus(ctx, organizationID, ids, req.Status); err != nil {
+			uc.logger.Error("Failed to bulk update invoice status", "error", err)
+			return nil, fmt.Errorf("failed to bulk update invoice status: %w", err)
+		}
+		result.Updated = len(ids)
+	}
+
+	uc.logger.Info("Bulk invoice status update completed", "organizationId", organizationID, "updated", result.Updated, "skipped", result.Skipped)
+	return result, nil
+}
+
+// statusEvent returns the outbox event customers receive for a status
+// transition, or nil when the transition is not published.
+func statusEvent(invoice *domain.Invoice, previous domain.InvoiceStatus) (*domain.OutboxEvent, error) {
+	if invoice.Status == previous {
+		return nil, nil
+	}
+
+	var event domain.WebhookEvent
+	switch invoice.Status {
+	case domain.InvoiceStatusPaid:
+		event = domain.WebhookEventInvoicePaid
+	case domain.InvoiceStatusOverdue:
+		event = domain.WebhookEventInvoiceOverdue
+	default:
+		return nil, nil
+	}
+
+	return domain.NewOutboxEvent(invoice.OrganizationID, event, invoice)
+}
+
+// ReorderInvoiceItems sorts the items of an editable invoice in the order of
+// itemIDs, which must list every item of the invoice
+func (uc *InvoiceUseCase) ReorderInvoiceItems(ctx context.Context, organizationID, invoiceID uint, itemIDs []uint) error {
+	uc.logger.Info("Reordering invoice items", "organizationId", organizationID, "invoiceId", invoiceID)
+
+	invoice, err := uc.invoices.GetByID(ctx, organizationID, invoiceID)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvoiceNotFound) {
+			uc.logger.Warn("Invoice not found for item reordering", "invoiceId", invoiceID, "organizationId", organizationID)
+			return domain.ErrInvoiceNotFound
+		}
+		uc.logger.Error("Failed to get invoice for item reordering", "error", err, "invoiceId", invoiceID)
+		return fmt.Errorf("failed to get invoice: %w", err)
+	}
+
+	if !invoice.CanEdit() {
+		uc.logger.Warn("Attempt to reorder items of non-editable invoice", "invoiceId", invoiceID, "status", invoice.Status)
+		return domain.ErrInvoiceNotEditable
+	}
+
+	if err := uc.invoices.ReorderItems(ctx, invoiceID, itemIDs); err != nil {
+		if errors.Is(err, domain.ErrInvalidItemOrder) {
+			uc.logger.Warn("Invalid invoice item order", "error", err, "invoiceId", invoiceID)
+			return err
+		}
+		uc.logger.Error("Failed to reorder invoice items", "error", err, "invoiceId", invoiceID)
+		return fmt.Errorf("failed to reorder invoice items: %w", err)
 	}
 
+	uc.logger.Info("Invoice items reordered successfully", "invoiceId", invoiceID)
+	return nil
+}
+
+// CreatePayment creates a new payment for an invoice and applies it to the
+// invoice balance. A negative amount records a refund of part of what was
+// paid. The writes share one unit of work with the payment.received event,
+// and invoice.paid when the payment settles the invoice, so a payment is
+// never stored without the invoice reflecting it and webhooks announcing it.
+func (uc *InvoiceUseCase) CreatePayment(ctx context.Context, req CreatePaymentRequest) (*domain.Payment, error) {
+	return uc.createPayment(ctx, req, nil)
+}
+
+// createPayment creates a payment like CreatePayment, calling claim first
+// inside the unit of work when it is set. An error from claim rolls the
+// payment back and is returned as is.
+func (uc *InvoiceUseCase) createPayment(ctx context.Context, req CreatePaymentRequest, claim func(repos repository.TxRepositories) error) (*domain.Payment, error) {
+	uc.logger.Info("Creating payment", "organizationId", req.OrganizationID, "invoiceId", req.InvoiceID)
+
 	// Create payment domain entity
 	payment, err := domain.NewPayment(
 		req.OrganizationID, req.InvoiceID, req.CreatedBy,
@@ -371,16 +942,274 @@ func (uc *InvoiceUseCase) CreatePayment(ctx context.Context, req CreatePaymentRe
 		return nil, fmt.Errorf("failed to update payment info: %w", err)
 	}
 
-	// Persist payment
-	if err := uc.invoices.CreatePayment(ctx, payment); err != nil {
-		uc.logger.Error("Failed to persist payment", "error", err)
-		return nil, fmt.Errorf("failed to create payment: %w", err)
+	var invoice *domain.Invoice
+	err = uc.units.Do(ctx, func(repos repository.TxRepositories) error {
+		if claim != nil {
+			if err := claim(repos); err != nil {
+				return err
+			}
+		}
+
+		// Get invoice to validate payment
+		var err error
+		invoice, err = repos.Invoices.GetByID(ctx, req.OrganizationID, req.InvoiceID)
+		if err != nil {
+			if errors.Is(err, domain.ErrInvoiceNotFound) {
+				uc.logger.Warn("Invoice not found for payment", "invoiceId", req.InvoiceID, "organizationId", req.OrganizationID)
+				return domain.ErrInvoiceNotFound
+			}
+			uc.logger.Error("Failed to get invoice for payment", "error", err, "invoiceId", req.InvoiceID)
+			return fmt.Errorf("failed to get invoice: %w", err)
+		}
+
+		// Validate payment amount and apply it to the balance
+		previous := invoice.Status
+		if err := invoice.ApplyPayment(payment.Amount, uc.rounding); err != nil {
+			uc.logger.Warn("Payment cannot be applied to invoice", "error", err, "amount", payment.Amount, "balanceDue", invoice.BalanceDue, "paidAmount", invoice.PaidAmount)
+			return err
+		}
+
+		// Persist payment and invoice
+		if err := repos.Invoices.CreatePayment(ctx, payment); err != nil {
+			uc.logger.Error("Failed to persist payment", "error", err)
+			return fmt.Errorf("failed to create payment: %w", err)
+		}
+		if err := repos.Invoices.Update(ctx, invoice); err != nil {
+			uc.logger.Error("Failed to apply payment to invoice", "error", err, "invoiceId", invoice.ID)
+			return fmt.Errorf("failed to update invoice: %w", err)
+		}
+
+		// Publish the payment, and the invoice becoming paid, only if they commit
+		received, err := domain.NewOutboxEvent(invoice.OrganizationID, domain.WebhookEventPaymentReceived, payment)
+		if err != nil {
+			return fmt.Errorf("failed to encode payment event: %w", err)
+		}
+		transition, err := statusEvent(invoice, previous)
+		if err != nil {
+			return fmt.Errorf("failed to encode invoice event: %w", err)
+		}
+		for _, event := range []*domain.OutboxEvent{received, transition} {
+			if event == nil {
+				continue
+			}
+			if err := repos.Outbox.Append(ctx, event); err != nil {
+				uc.logger.Error("Failed to record payment event", "error", err, "invoiceId", invoice.ID, "event", event.Event)
+				return fmt.Errorf("failed to record payment event: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
+	notifyUser(ctx, uc.pushes, uc.logger, invoice.CreatedBy, repository.UserNotificationPaymentReceived, map[string]interface{}{
+		"organizationId": invoice.OrganizationID,
+		"invoiceId":      invoice.ID,
+		"invoiceNumber":  invoice.InvoiceNumber,
+		"paymentId":      payment.ID,
+		"amount":         payment.Amount,
+		"refund":         payment.Refund,
+		"currency":       payment.Currency,
+		"balanceDue":     invoice.BalanceDue,
+	})
+
 	uc.logger.Info("Payment created successfully", "paymentId", payment.ID, "invoiceId", req.InvoiceID)
 	return payment, nil
 }
 
+// PaymentImportRow is a payment read from a bank statement. Reference is
+// matched against invoice numbers and kept as the payment's reference number.
+type PaymentImportRow struct {
+	Reference     string               `json:"reference"`
+	Amount        float64              `json:"amount"`
+	Currency      string               `json:"currency"`
+	PaymentDate   time.Time            `json:"paymentDate"`
+	PaymentMethod domain.PaymentMethod `json:"paymentMethod,omitempty"` // Defaults to bank_transfer
+	Notes         string               `json:"notes,omitempty"`
+}
+
+// PaymentImportMatch is an imported row and the payment it was booked as
+type PaymentImportMatch struct {
+	Row           int             `json:"row"`
+	InvoiceID     uint            `json:"invoiceId"`
+	InvoiceNumber string          `json:"invoiceNumber"`
+	Payment       *domain.Payment `json:"payment"`
+}
+
+// PaymentImportMiss explains why an imported row was not booked
+type PaymentImportMiss struct {
+	Row       int    `json:"row"`
+	Reference string `json:"reference"`
+	Reason    string `json:"reason"`
+	// Candidates lists the invoice numbers an ambiguous reference matches
+	Candidates []string `json:"candidates,omitempty"`
+}
+
+// PaymentImportResult reports the outcome of a payment import
+type PaymentImportResult struct {
+	Matched   []PaymentImportMatch `json:"matched"`
+	Unmatched []PaymentImportMiss  `json:"unmatched"`
+}
+
+// Reasons an imported payment is left unmatched
+const (
+	paymentImportNoMatch   = "no matching invoice"
+	paymentImportAmbiguous = "reference matches several invoices"
+	paymentImportCurrency  = "currency does not match invoice"
+)
+
+// paymentImportRowErrors are the errors booking a row can fail with that
+// leave the row unmatched rather than failing the import
+var paymentImportRowErrors = []error{
+	domain.ErrInvoiceNotFound,
+	domain.ErrPaymentNotAllowed,
+	domain.ErrInsufficientPayment,
+	domain.ErrRefundExceedsPaid,
+	domain.ErrInvalidAmount,
+	domain.ErrInvalidPaymentMethod,
+}
+
+// isPaymentImportRowError reports whether err is one of paymentImportRowErrors
+func isPaymentImportRowError(err error) bool {
+	for _, target := range paymentImportRowErrors {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// ImportPayments books bank statement payments against the invoices their
+// references name, updating each invoice's balance. A reference matches the
+// invoice with exactly that number or, failing that, the single open invoice
+// whose number contains it. Rows that match no invoice, several invoices, or
+// can't be applied are reported as unmatched instead of failing the import;
+// so are refunds, which go through CreatePayment.
+// Each row is booked in its own unit of work, so rows booked before an
+// unexpected error stay booked.
+func (uc *InvoiceUseCase) ImportPayments(ctx context.Context, organizationID uint, rows []PaymentImportRow) (*PaymentImportResult, error) {
+	uc.logger.Info("Importing payments", "organizationId", organizationID, "rows", len(rows))
+
+	createdBy, ok := repository.ActorFromContext(ctx)
+	if !ok {
+		return nil, fmt.Errorf("payment import needs an acting user: %w", domain.ErrUserNotFound)
+	}
+
+	result := &PaymentImportResult{Matched: []PaymentImportMatch{}, Unmatched: []PaymentImportMiss{}}
+	for i, row := range rows {
+		reference := strings.TrimSpace(row.Reference)
+		miss := func(reason string, candidates ...string) {
+			result.Unmatched = append(result.Unmatched, PaymentImportMiss{Row: i, Reference: row.Reference, Reason: reason, Candidates: candidates})
+		}
+		if reference == "" {
+			miss(paymentImportNoMatch)
+			continue
+		}
+		if row.Amount <= 0 {
+			miss(domain.ErrInvalidAmount.Error())
+			continue
+		}
+
+		invoice, candidates, err := uc.matchPaymentReference(ctx, organizationID, reference)
+		if err != nil {
+			return nil, err
+		}
+		if invoice == nil {
+			if len(candidates) > 1 {
+				miss(paymentImportAmbiguous, candidates...)
+			} else {
+				miss(paymentImportNoMatch)
+			}
+			continue
+		}
+		if !strings.EqualFold(strings.TrimSpace(row.Currency), invoice.Currency) {
+			miss(paymentImportCurrency)
+			continue
+		}
+
+		method := row.PaymentMethod
+		if method == "" {
+			method = domain.PaymentMethodBankTransfer
+		}
+		// Reject rows the payment itself would refuse before booking them,
+		// so their reason reaches the caller
+		if _, err := domain.NewPayment(organizationID, invoice.ID, createdBy, method, row.Amount, row.Currency, row.PaymentDate); err != nil {
+			miss(err.Error())
+			continue
+		}
+
+		payment, err := uc.CreatePayment(ctx, CreatePaymentRequest{
+			OrganizationID:  organizationID,
+			InvoiceID:       invoice.ID,
+			PaymentMethod:   method,
+			ReferenceNumber: reference,
+			Amount:          row.Amount,
+			Currency:        row.Currency,
+			PaymentDate:     row.PaymentDate,
+			Notes:           row.Notes,
+			CreatedBy:       createdBy,
+		})
+		if err != nil {
+			if isPaymentImportRowError(err) {
+				miss(err.Error())
+				continue
+			}
+			return nil, err
+		}
+		result.Matched = append(result.Matched, PaymentImportMatch{
+			Row:           i,
+			InvoiceID:     invoice.ID,
+			InvoiceNumber: invoice.InvoiceNumber,
+			Payment:       payment,
+		})
+	}
+
+	uc.logger.Info("Payment import completed", "organizationId", organizationID, "matched", len(result.Matched), "unmatched", len(result.Unmatched))
+	return result, nil
+}
+
+// matchPaymentReference finds the invoice a payment reference names. An
+// exact number matches whatever the invoice's status, so booking reports why
+// it can't take the payment; a partial reference only matches open invoices,
+// those sent with a balance due. When it matches several it returns no
+// invoice and their numbers.
+func (uc *InvoiceUseCase) matchPaymentReference(ctx context.Context, organizationID uint, reference string) (*domain.Invoice, []string, error) {
+	invoice, err := uc.invoices.GetByNumber(ctx, organizationID, reference)
+	if err == nil {
+		return invoice, nil, nil
+	}
+	if !errors.Is(err, domain.ErrInvoiceNotFound) {
+		uc.logger.Error("Failed to get invoice by number", "error", err, "invoiceNumber", reference)
+		return nil, nil, fmt.Errorf("failed to get invoice: %w", err)
+	}
+
+	filters := repository.InvoiceFilters{Search: reference, PageSize: repository.MaxPageSize}
+	if err := filters.Validate(); err != nil {
+		return nil, nil, err
+	}
+	found, _, err := uc.invoices.List(ctx, organizationID, filters)
+	if err != nil {
+		uc.logger.Error("Failed to search invoices by reference", "error", err, "reference", reference)
+		return nil, nil, fmt.Errorf("failed to search invoices: %w", err)
+	}
+
+	var open []*domain.Invoice
+	for _, candidate := range found {
+		if candidate.BalanceDue > 0 && candidate.Status != domain.InvoiceStatusDraft && candidate.Status != domain.InvoiceStatusCancelled {
+			open = append(open, candidate)
+		}
+	}
+	if len(open) == 1 {
+		return open[0], nil, nil
+	}
+	numbers := make([]string, len(open))
+	for i, candidate := range open {
+		numbers[i] = candidate.InvoiceNumber
+	}
+	return nil, numbers, nil
+}
+
 // GetInvoiceStats retrieves invoice statistics for an organization
 func (uc *InvoiceUseCase) GetInvoiceStats(ctx context.Context, organizationID uint) (*repository.InvoiceStats, error) {
 	uc.logger.Info("Getting invoice statistics", "organizationId", organizationID)
@@ -395,6 +1224,32 @@ func (uc *InvoiceUseCase) GetInvoiceStats(ctx context.Context, organizationID ui
 	return stats, nil
 }
 
+// MaxRevenueBuckets bounds the number of buckets in a revenue time series
+const MaxRevenueBuckets = 366
+
+// GetRevenueTimeSeries retrieves revenue per day, week or month in [from, to)
+func (uc *InvoiceUseCase) GetRevenueTimeSeries(ctx context.Context, organizationID uint, from, to time.Time, granularity repository.RevenueGranularity) (*repository.RevenueTimeSeries, error) {
+	if !granularity.IsValid() {
+		return nil, fmt.Errorf("%w: unsupported granularity %q", domain.ErrInvalidRevenuePeriod, granularity)
+	}
+	if !to.After(from) {
+		return nil, fmt.Errorf("%w: end must be after start", domain.ErrInvalidRevenuePeriod)
+	}
+	buckets := 0
+	for start := granularity.Truncate(from); start.Before(to); start = granularity.Next(start) {
+		if buckets++; buckets > MaxRevenueBuckets {
+			return nil, fmt.Errorf("%w: period cannot exceed %d buckets", domain.ErrInvalidRevenuePeriod, MaxRevenueBuckets)
+		}
+	}
+
+	series, err := uc.invoices.GetRevenueTimeSeries(ctx, organizationID, from, to, granularity)
+	if err != nil {
+		uc.logger.Error("Failed to get revenue time series", "error", err, "organizationId", organizationID)
+		return nil, fmt.Errorf("failed to get revenue time series: %w", err)
+	}
+	return series, nil
+}
+
 // GetOverdueInvoices retrieves all overdue invoices for an organization
 func (uc *InvoiceUseCase) GetOverdueInvoices(ctx context.Context, organizationID uint) ([]*domain.Invoice, error) {
 	uc.logger.Info("Getting overdue invoices", "organizationId", organizationID)
@@ -0,0 +1,477 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pmaojo/kthulu-go/backend/internal/domain"
+	"github.com/pmaojo/kthulu-go/backend/internal/domain/money"
+	"github.com/pmaojo/kthulu-go/backend/internal/domain/repository"
+	"github.com/pmaojo/kthulu-go/backend/internal/infrastructure/exchangerate"
+)
+
+// fakeInvoiceRepository keeps invoices and payments in memory; calls it
+// doesn't implement panic through the embedded interface
+type fakeInvoiceRepository struct {
+	repository.InvoiceRepository
+
+	invoices map[uint]*domain.Invoice
+	payments []*domain.Payment
+	outbox   fakeOutbox
+	// webhookEvents is shared by the online payment use case and its units
+	// of work
+	webhookEvents fakePaymentWebhookEvents
+}
+
+func (f *fakeInvoiceRepository) GenerateInvoiceNumber(ctx context.Context, organizationID uint, invoiceType domain.InvoiceType) (string, error) {
+	return fmt.Sprintf("INV-%04d", len(f.invoices)+1), nil
+}
+
+func (f *fakeInvoiceRepository) Create(ctx context.Context, invoice *domain.Invoice) error {
+	invoice.ID = uint(len(f.invoices) + 1)
+	f.invoices[invoice.ID] = invoice
+	return nil
+}
+
+func (f *fakeInvoiceRepository) CreateItem(ctx context.Context, item *domain.InvoiceItem) error {
+	return nil
+}
+
+func (f *fakeInvoiceRepository) GetByID(ctx context.Context, organizationID, invoiceID uint) (*domain.Invoice, error) {
+	invoice, ok := f.invoices[invoiceID]
+	if !ok || invoice.OrganizationID != organizationID {
+		return nil, domain.ErrInvoiceNotFound
+	}
+	return invoice, nil
+}
+
+func (f *fakeInvoiceRepository) GetByNumber(ctx context.Context, organizationID uint, invoiceNumber string) (*domain.Invoice, error) {
+	for _, invoice := range f.invoices {
+		if invoice.OrganizationID == organizationID && invoice.InvoiceNumber == invoiceNumber {
+			return invoice, nil
+		}
+	}
+	return nil, domain.ErrInvoiceNotFound
+}
+
+func (f *fakeInvoiceRepository) List(ctx context.Context, organizationID uint, filters repository.InvoiceFilters) ([]*domain.Invoice, int64, error) {
+	var found []*domain.Invoice
+	for id := uint(1); id <= uint(len(f.invoices)); id++ {
+		invoice := f.invoices[id]
+		if invoice.OrganizationID == organizationID && strings.Contains(strings.ToLower(invoice.InvoiceNumber), strings.ToLower(filters.Search)) {
+			found = append(found, invoice)
+		}
+	}
+	return found, int64(len(found)), nil
+}
+
+func (f *fakeInvoiceRepository) CreatePayment(ctx context.Context, payment *domain.Payment) error {
+	payment.ID = uint(len(f.payments) + 1)
+	f.payments = append(f.payments, payment)
+	return nil
+}
+
+func (f *fakeInvoiceRepository) GetPaymentsByInvoiceID(ctx context.Context, invoiceID uint) ([]*domain.Payment, error) {
+	var found []*domain.Payment
+	for _, payment := range f.payments {
+		if payment.InvoiceID == invoiceID {
+			found = append(found, payment)
+		}
+	}
+	return found, nil
+}
+
+func (f *fakeInvoiceRepository) Update(ctx context.Context, invoice *domain.Invoice) error {
+	f.invoices[invoice.ID] = invoice
+	return nil
+}
+
+// fakeOutbox records the events appended inside a unit of work
+type fakeOutbox struct {
+	repository.OutboxRepository
+
+	events []*domain.OutboxEvent
+}
+
+func (f *fakeOutbox) Append(ctx context.Context, event *domain.OutboxEvent) error {
+	f.events = append(f.events, event)
+	return nil
+}
+
+type fakeUnitOfWork struct {
+	invoices *fakeInvoiceRepository
+}
+
+func (u fakeUnitOfWork) Do(ctx context.Context, fn func(repos repository.TxRepositories) error) error {
+	return fn(repository.TxRepositories{Invoices: u.invoices, Outbox: &u.invoices.outbox, PaymentWebhookEvents: &u.invoices.webhookEvents})
+}
+
+// fakeTaxOrganizations, fakeTaxContacts and fakeTaxProducts serve the
+// parties and products the tax of invoice lines depends on
+type fakeTaxOrganizations struct {
+	repository.OrganizationRepository
+}
+
+func (fakeTaxOrganizations) FindByID(ctx context.Context, id uint) (*domain.Organization, error) {
+	return &domain.Organization{ID: id, Country: "ES"}, nil
+}
+
+// fakeTaxContacts knows a French business as contact 2 and a Spanish one as
+// contact 3
+type fakeTaxContacts struct {
+	repository.ContactRepository
+}
+
+func (fakeTaxContacts) GetByID(ctx context.Context, organizationID, contactID uint) (*domain.Contact, error) {
+	if contactID != 2 && contactID != 3 {
+		return nil, domain.ErrContactNotFound
+	}
+	return &domain.Contact{ID: contactID, OrganizationID: organizationID, TaxNumber: fmt.Sprintf("VAT-%d", contactID)}, nil
+}
+
+func (fakeTaxContacts) GetAddressesByContactID(ctx context.Context, contactID uint) ([]*domain.ContactAddress, error) {
+	country := map[uint]string{2: "FR", 3: "ES"}[contactID]
+	return []*domain.ContactAddress{
+		{ContactID: contactID, Type: domain.AddressTypeShipping, Country: "PT"},
+		{ContactID: contactID, Type: domain.AddressTypeBilling, Country: country, IsPrimary: true},
+	}, nil
+}
+
+type fakeTaxProducts struct {
+	repository.ProductRepository
+}
+
+func (fakeTaxProducts) GetByID(ctx context.Context, organizationID, productID uint) (*domain.Product, error) {
+	return &domain.Product{ID: productID, OrganizationID: organizationID, Category: "books"}, nil
+}
+
+func newPaymentImportUseCase() (*InvoiceUseCase, *fakeInvoiceRepository) {
+	invoices := &fakeInvoiceRepository{invoices: map[uint]*domain.Invoice{
+		1: {ID: 1, OrganizationID: 1, InvoiceNumber: "INV-0001", Status: domain.InvoiceStatusSent, Currency: "EUR", TotalAmount: 100, BalanceDue: 100},
+		2: {ID: 2, OrganizationID: 1, InvoiceNumber: "INV-0042", Status: domain.InvoiceStatusSent, Currency: "EUR", TotalAmount: 50, BalanceDue: 50},
+		3: {ID: 3, OrganizationID: 1, InvoiceNumber: "INV-1042", Status: domain.InvoiceStatusSent, Currency: "EUR", TotalAmount: 80, BalanceDue: 80},
+		4: {ID: 4, OrganizationID: 1, InvoiceNumber: "INV-0777", Status: domain.InvoiceStatusSent, Currency: "USD", TotalAmount: 70, BalanceDue: 70},
+		// Paid invoices don't make a partial reference ambiguous
+		5: {ID: 5, OrganizationID: 1, InvoiceNumber: "INV-2077", Status: domain.InvoiceStatusPaid, Currency: "USD", TotalAmount: 10, PaidAmount: 10},
+		6: {ID: 6, OrganizationID: 2, InvoiceNumber: "INV-0009", Status: domain.InvoiceStatusSent, Currency: "EUR", TotalAmount: 10, BalanceDue: 10},
+		// Drafts can't take payments, so they don't either
+		7: {ID: 7, OrganizationID: 1, InvoiceNumber: "INV-3042", Status: domain.InvoiceStatusDraft, Currency: "EUR", TotalAmount: 30, BalanceDue: 30},
+	}}
+	uc := NewInvoiceUseCase(invoices, fakeUnitOfWork{invoices}, nil, nil, nil, nil, nil, nil, nil, nil, nil, money.DefaultPolicy(), &recordingLogger{})
+	return uc, invoices
+}
+
+func TestImportPayments_MatchesReferences(t *testing.T) {
+	uc, invoices := newPaymentImportUseCase()
+	ctx := repository.ContextWithActor(context.Background(), 9)
+	paidOn := time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC)
+
+	result, err := uc.ImportPayments(ctx, 1, []PaymentImportRow{
+		{Reference: "INV-0001", Amount: 40, Currency: "EUR", PaymentDate: paidOn},
+		// A partial reference matching a single open invoice
+		{Reference: "0777", Amount: 70, Currency: "usd", PaymentDate: paidOn},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Matched) != 2 || len(result.Unmatched) != 0 {
+		t.Fatalf("expected two matched rows, got %+v", result)
+	}
+	if m := result.Matched[1]; m.Row != 1 || m.InvoiceID != 4 || m.Payment.ReferenceNumber != "0777" {
+		t.Fatalf("unexpected match %+v", m)
+	}
+
+	payment := invoices.payments[0]
+	if payment.InvoiceID != 1 || payment.CreatedBy != 9 || payment.PaymentMethod != domain.PaymentMethodBankTransfer {
+		t.Fatalf("unexpected payment %+v", payment)
+	}
+	if invoice := invoices.invoices[1]; invoice.BalanceDue != 60 || invoice.Status != domain.InvoiceStatusPartial {
+		t.Fatalf("expected a partially paid invoice, got balance %.2f, status %s", invoice.BalanceDue, invoice.Status)
+	}
+	if invoice := invoices.invoices[4]; invoice.BalanceDue != 0 || invoice.Status != domain.InvoiceStatusPaid {
+		t.Fatalf("expected a paid invoice, got balance %.2f, status %s", invoice.BalanceDue, invoice.Status)
+	}
+}
+
+func TestImportPayments_ReportsUnmatchedRows(t *testing.T) {
+	uc, invoices := newPaymentImportUseCase()
+	ctx := repository.ContextWithActor(context.Background(), 9)
+	paidOn := time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC)
+
+	result, err := uc.ImportPayments(ctx, 1, []PaymentImportRow{
+		{Reference: "INV-9999", Amount: 10, Currency: "EUR", PaymentDate: paidOn},
+		// Another organization's invoice
+		{Reference: "INV-0009", Amount: 10, Currency: "EUR", PaymentDate: paidOn},
+		{Reference: "", Amount: 10, Currency: "EUR", PaymentDate: paidOn},
+		{Reference: "INV-0001", Amount: 10, Currency: "USD", PaymentDate: paidOn},
+		{Reference: "INV-0001", Amount: 500, Currency: "EUR", PaymentDate: paidOn},
+		{Reference: "INV-0001", Amount: -5, Currency: "EUR", PaymentDate: paidOn},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Matched) != 0 || len(invoices.payments) != 0 {
+		t.Fatalf("expected nothing booked, got %+v", result.Matched)
+	}
+
+	want := []string{
+		paymentImportNoMatch,
+		paymentImportNoMatch,
+		paymentImportNoMatch,
+		paymentImportCurrency,
+		domain.ErrInsufficientPayment.Error(),
+		domain.ErrInvalidAmount.Error(),
+	}
+	if len(result.Unmatched) != len(want) {
+		t.Fatalf("expected %d unmatched rows, got %+v", len(want), result.Unmatched)
+	}
+	for i, miss := range result.Unmatched {
+		if miss.Row != i || miss.Reason != want[i] {
+			t.Fatalf("row %d: expected %q, got %+v", i, want[i], miss)
+		}
+	}
+	if invoices.invoices[1].BalanceDue != 100 {
+		t.Fatalf("expected the balance to be untouched, got %.2f", invoices.invoices[1].BalanceDue)
+	}
+}
+
+func TestImportPayments_AmbiguousReference(t *testing.T) {
+	uc, invoices := newPaymentImportUseCase()
+	ctx := repository.ContextWithActor(context.Background(), 9)
+
+	result, err := uc.ImportPayments(ctx, 1, []PaymentImportRow{
+		{Reference: "042", Amount: 50, Currency: "EUR", PaymentDate: time.Now()},
+		// Only one of the invoices containing 077 is still open
+		{Reference: "077", Amount: 20, Currency: "USD", PaymentDate: time.Now()},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Unmatched) != 1 || len(result.Matched) != 1 || result.Matched[0].InvoiceID != 4 {
+		t.Fatalf("expected one ambiguous and one matched row, got %+v", result)
+	}
+	miss := result.Unmatched[0]
+	if miss.Reason != paymentImportAmbiguous || strings.Join(miss.Candidates, ",") != "INV-0042,INV-1042" {
+		t.Fatalf("unexpected miss %+v", miss)
+	}
+	if invoices.invoices[2].BalanceDue != 50 || invoices.invoices[3].BalanceDue != 80 {
+		t.Fatal("expected ambiguous invoices to be untouched")
+	}
+
+	if _, err := uc.ImportPayments(context.Background(), 1, nil); err == nil {
+		t.Fatal("expected an import without an acting user to fail")
+	}
+}
+
+func TestImportPayments_DraftInvoiceDoesNotStopImport(t *testing.T) {
+	uc, invoices := newPaymentImportUseCase()
+	ctx := repository.ContextWithActor(context.Background(), 9)
+	paidOn := time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC)
+
+	result, err := uc.ImportPayments(ctx, 1, []PaymentImportRow{
+		{Reference: "INV-0001", Amount: 40, Currency: "EUR", PaymentDate: paidOn},
+		{Reference: "INV-3042", Amount: 30, Currency: "EUR", PaymentDate: paidOn},
+		// A partial reference whose only match is the draft
+		{Reference: "3042", Amount: 30, Currency: "EUR", PaymentDate: paidOn},
+		{Reference: "0777", Amount: 70, Currency: "USD", PaymentDate: paidOn},
+	})
+	if err != nil {
+		t.Fatalf("expected the draft rows to be reported, not to fail the import: %v", err)
+	}
+	if len(result.Matched) != 2 || result.Matched[0].Row != 0 || result.Matched[1].Row != 3 {
+		t.Fatalf("expected the rows around the draft to be booked, got %+v", result.Matched)
+	}
+	want := []PaymentImportMiss{
+		{Row: 1, Reference: "INV-3042", Reason: domain.ErrPaymentNotAllowed.Error()},
+		{Row: 2, Reference: "3042", Reason: paymentImportNoMatch},
+	}
+	if len(result.Unmatched) != len(want) {
+		t.Fatalf("expected %d unmatched rows, got %+v", len(want), result.Unmatched)
+	}
+	for i, miss := range result.Unmatched {
+		if miss.Row != want[i].Row || miss.Reference != want[i].Reference || miss.Reason != want[i].Reason {
+			t.Fatalf("expected %+v, got %+v", want[i], miss)
+		}
+	}
+	if draft := invoices.invoices[7]; draft.BalanceDue != 30 || draft.Status != domain.InvoiceStatusDraft {
+		t.Fatalf("expected the draft to be untouched, got balance %.2f, status %s", draft.BalanceDue, draft.Status)
+	}
+}
+
+func TestCreatePayment_Refunds(t *testing.T) {
+	uc, invoices := newPaymentImportUseCase()
+	invoices.invoices[1] = &domain.Invoice{ID: 1, OrganizationID: 1, InvoiceNumber: "INV-0001", Status: domain.InvoiceStatusPaid, Currency: "EUR", TotalAmount: 100, PaidAmount: 100}
+	refund := func(amount float64) (*domain.Payment, error) {
+		return uc.CreatePayment(context.Background(), CreatePaymentRequest{
+			OrganizationID: 1,
+			InvoiceID:      1,
+			PaymentMethod:  domain.PaymentMethodBankTransfer,
+			Amount:         amount,
+			Currency:       "EUR",
+			PaymentDate:    time.Now(),
+			CreatedBy:      9,
+		})
+	}
+
+	payment, err := refund(-30)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !payment.Refund || len(invoices.payments) != 1 {
+		t.Fatalf("expected a stored refund, got %+v", payment)
+	}
+	if invoice := invoices.invoices[1]; invoice.PaidAmount != 70 || invoice.BalanceDue != 30 || invoice.Status != domain.InvoiceStatusPartial {
+		t.Fatalf("expected the invoice reopened as partial, got paid=%.2f balance=%.2f status=%s", invoice.PaidAmount, invoice.BalanceDue, invoice.Status)
+	}
+
+	if _, err := refund(-70.01); !errors.Is(err, domain.ErrRefundExceedsPaid) {
+		t.Fatalf("expected ErrRefundExceedsPaid, got %v", err)
+	}
+	if len(invoices.payments) != 1 || invoices.invoices[1].PaidAmount != 70 {
+		t.Fatal("expected a rejected refund to leave the invoice untouched")
+	}
+}
+
+func TestCreatePayment_RecordsEvents(t *testing.T) {
+	uc, invoices := newPaymentImportUseCase()
+	invoices.invoices[1] = &domain.Invoice{ID: 1, OrganizationID: 1, InvoiceNumber: "INV-0001", Status: domain.InvoiceStatusSent, Currency: "EUR", TotalAmount: 100, BalanceDue: 100}
+	pay := func(amount float64) {
+		t.Helper()
+		if _, err := uc.CreatePayment(context.Background(), CreatePaymentRequest{
+			OrganizationID: 1,
+			InvoiceID:      1,
+			PaymentMethod:  domain.PaymentMethodBankTransfer,
+			Amount:         amount,
+			Currency:       "EUR",
+			PaymentDate:    time.Now(),
+			CreatedBy:      9,
+		}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	pay(40)
+	if events := invoices.outbox.events; len(events) != 1 || events[0].Event != domain.WebhookEventPaymentReceived {
+		t.Fatalf("expected only payment.received for a partial payment, got %+v", events)
+	}
+
+	pay(60)
+	events := invoices.outbox.events
+	if len(events) != 3 || events[1].Event != domain.WebhookEventPaymentReceived || events[2].Event != domain.WebhookEventInvoicePaid {
+		t.Fatalf("expected payment.received and invoice.paid for the settling payment, got %+v", events)
+	}
+	if events[1].OrganizationID != 1 {
+		t.Fatalf("expected the event scoped to the organization, got %d", events[1].OrganizationID)
+	}
+}
+
+func TestCreateInvoice_DefaultsExchangeRate(t *testing.T) {
+	var lookups atomic.Int32
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lookups.Add(1)
+		if r.URL.Path != "/2024-05-02" || r.URL.Query().Get("from") != "USD" || r.URL.Query().Get("to") != "EUR" {
+			t.Errorf("unexpected rates request %s", r.URL)
+		}
+		fmt.Fprint(w, `{"amount": 1.0, "base": "USD", "date": "2024-05-02", "rates": {"EUR": 0.93}}`)
+	}))
+	defer api.Close()
+
+	invoices := &fakeInvoiceRepository{invoices: map[uint]*domain.Invoice{}}
+	rates := exchangerate.NewCachedProvider(exchangerate.NewHTTPProvider(api.URL, "EUR"))
+	uc := NewInvoiceUseCase(invoices, fakeUnitOfWork{invoices}, nil, nil, nil, nil, nil, nil, nil, rates, nil, money.DefaultPolicy(), &recordingLogger{})
+	create := func(currency string, exchangeRate *float64) *domain.Invoice {
+		t.Helper()
+		invoice, err := uc.CreateInvoice(context.Background(), CreateInvoiceRequest{
+			OrganizationID: 1,
+			ContactID:      2,
+			Type:           domain.InvoiceTypeInvoice,
+			Currency:       currency,
+			ExchangeRate:   exchangeRate,
+			IssueDate:      time.Date(2024, 5, 2, 15, 0, 0, 0, time.UTC),
+			CreatedBy:      9,
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return invoice
+	}
+
+	if invoice := create("USD", nil); invoice.ExchangeRate != 0.93 {
+		t.Fatalf("expected the provider's rate, got %v", invoice.ExchangeRate)
+	}
+	// The second invoice of the day is served from the cache
+	if invoice := create("usd", nil); invoice.ExchangeRate != 0.93 || lookups.Load() != 1 {
+		t.Fatalf("expected a cached rate after one lookup, got %v after %d lookups", invoice.ExchangeRate, lookups.Load())
+	}
+
+	manual := 0.9
+	if invoice := create("USD", &manual); invoice.ExchangeRate != 0.9 {
+		t.Fatalf("expected the given rate to win, got %v", invoice.ExchangeRate)
+	}
+	if invoice := create("EUR", nil); invoice.ExchangeRate != 1 {
+		t.Fatalf("expected base currency invoices to keep a rate of 1, got %v", invoice.ExchangeRate)
+	}
+	if lookups.Load() != 1 {
+		t.Fatalf("expected no further lookups, got %d", lookups.Load())
+	}
+
+	uc = NewInvoiceUseCase(invoices, fakeUnitOfWork{invoices}, nil, nil, nil, nil, nil, nil, nil, exchangerate.NewStaticProvider("EUR", nil), nil, money.DefaultPolicy(), &recordingLogger{})
+	_, err := uc.CreateInvoice(context.Background(), CreateInvoiceRequest{OrganizationID: 1, ContactID: 2, Type: domain.InvoiceTypeInvoice, Currency: "GBP", IssueDate: time.Now(), CreatedBy: 9})
+	if !errors.Is(err, domain.ErrExchangeRateUnavailable) {
+		t.Fatalf("expected ErrExchangeRateUnavailable, got %v", err)
+	}
+}
+
+func TestCreateInvoice_TaxesLinesPerJurisdiction(t *testing.T) {
+	invoices := &fakeInvoiceRepository{invoices: map[uint]*domain.Invoice{}}
+	taxes := domain.EUVATCalculator{CategoryRates: map[string]float64{"books": 0.04}}
+	uc := NewInvoiceUseCase(invoices, fakeUnitOfWork{invoices}, fakeTaxContacts{}, fakeTaxOrganizations{}, fakeTaxProducts{}, nil, nil, nil, nil, nil, taxes, money.DefaultPolicy(), &recordingLogger{})
+	book := uint(7)
+	create := func(contactID uint) *domain.Invoice {
+		t.Helper()
+		invoice, err := uc.CreateInvoice(context.Background(), CreateInvoiceRequest{
+			OrganizationID: 1,
+			ContactID:      contactID,
+			Type:           domain.InvoiceTypeInvoice,
+			Currency:       "EUR",
+			IssueDate:      time.Now(),
+			CreatedBy:      9,
+			Items: []CreateInvoiceItemRequest{
+				{Description: "Consulting", Quantity: 2, UnitPrice: 50, TaxRate: 0.21},
+				{ProductID: &book, Description: "Handbook", Quantity: 1, UnitPrice: 25, TaxRate: 0.21},
+			},
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return invoice
+	}
+
+	domestic := create(3)
+	if item := domestic.Items[0]; item.TaxRate != 0.21 || item.TaxAmount != 21 || item.ReverseCharge {
+		t.Fatalf("expected the domestic sale charged the line rate, got %+v", item)
+	}
+	if item := domestic.Items[1]; item.TaxRate != 0.04 || item.TaxAmount != 1 {
+		t.Fatalf("expected the book charged the reduced rate, got %+v", item)
+	}
+	if domestic.TaxAmount != 22 || domestic.TotalAmount != 147 {
+		t.Fatalf("expected tax 22 and total 147, got %.2f and %.2f", domestic.TaxAmount, domestic.TotalAmount)
+	}
+
+	reverseCharged := create(2)
+	for _, item := range reverseCharged.Items {
+		if item.TaxRate != 0 || item.TaxAmount != 0 || !item.ReverseCharge {
+			t.Fatalf("expected a reverse-charged line, got %+v", item)
+		}
+	}
+	if reverseCharged.TaxAmount != 0 || reverseCharged.TotalAmount != 125 {
+		t.Fatalf("expected no tax and a total of 125, got %.2f and %.2f", reverseCharged.TaxAmount, reverseCharged.TotalAmount)
+	}
+}
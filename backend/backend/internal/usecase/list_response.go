@@ -0,0 +1,31 @@
+// @kthulu:core
+package usecase
+
+// ListResponse is the envelope of every paginated list: one page of Data
+// along with where that page sits in the full result
+type ListResponse[T any] struct {
+	Data       []T   `json:"data"`
+	Page       int   `json:"page"`
+	PageSize   int   `json:"pageSize"`
+	Total      int64 `json:"total"`
+	TotalPages int64 `json:"totalPages"`
+}
+
+// NewListResponse wraps a page of data. An empty page is encoded as an empty
+// array rather than null.
+func NewListResponse[T any](data []T, total int64, page, pageSize int) *ListResponse[T] {
+	if data == nil {
+		data = []T{}
+	}
+	var totalPages int64
+	if pageSize > 0 {
+		totalPages = (total + int64(pageSize) - 1) / int64(pageSize)
+	}
+	return &ListResponse[T]{
+		Data:       data,
+		Page:       page,
+		PageSize:   pageSize,
+		Total:      total,
+		TotalPages: totalPages,
+	}
+}
@@ -0,0 +1,156 @@
+// @kthulu:module:payments
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/pmaojo/kthulu-go/backend/core"
+	"github.com/pmaojo/kthulu-go/backend/internal/domain"
+	"github.com/pmaojo/kthulu-go/backend/internal/domain/repository"
+)
+
+// OnlinePaymentUseCase lets contacts pay invoices through a payment gateway
+// and books the payments the gateway reports as collected
+type OnlinePaymentUseCase struct {
+	invoices  repository.InvoiceRepository
+	invoiceUC *InvoiceUseCase
+	gateway   repository.PaymentGateway
+	events    repository.PaymentWebhookEventRepository
+	logger    core.Logger
+}
+
+// PaymentWebhookResult is the outcome of a gateway webhook delivery
+type PaymentWebhookResult struct {
+	// Payment is the payment the event recorded, nil for other events
+	Payment *domain.Payment
+	// Duplicate is set when the event was already processed by an earlier
+	// delivery
+	Duplicate bool
+}
+
+// NewOnlinePaymentUseCase creates a new online payment use case instance
+func NewOnlinePaymentUseCase(
+	invoices repository.InvoiceRepository,
+	invoiceUC *InvoiceUseCase,
+	gateway repository.PaymentGateway,
+	events repository.PaymentWebhookEventRepository,
+	logger core.Logger,
+) *OnlinePaymentUseCase {
+	return &OnlinePaymentUseCase{
+		invoices:  invoices,
+		invoiceUC: invoiceUC,
+		gateway:   gateway,
+		events:    events,
+		logger:    logger,
+	}
+}
+
+// CreatePaymentIntent asks the gateway to collect the balance due of an
+// invoice. Canceled and settled invoices are rejected with
+// domain.ErrInvoiceNotPayable.
+func (uc *OnlinePaymentUseCase) CreatePaymentIntent(ctx context.Context, organizationID, invoiceID uint) (*repository.PaymentIntent, error) {
+	invoice, err := uc.invoiceUC.GetInvoice(ctx, organizationID, invoiceID)
+	if err != nil {
+		return nil, err
+	}
+	if invoice.Status == domain.InvoiceStatusCancelled || invoice.BalanceDue <= 0 {
+		return nil, domain.ErrInvoiceNotPayable
+	}
+
+	intent, err := uc.gateway.CreatePaymentIntent(ctx, repository.PaymentIntentRequest{
+		OrganizationID: organizationID,
+		InvoiceID:      invoiceID,
+		InvoiceNumber:  invoice.InvoiceNumber,
+		Amount:         invoice.BalanceDue,
+		Currency:       invoice.Currency,
+	})
+	if err != nil {
+		uc.logger.Error("Failed to create payment intent", "error", err, "invoiceId", invoiceID)
+		return nil, fmt.Errorf("failed to create payment intent: %w", err)
+	}
+
+	uc.logger.Info("Payment intent created", "invoiceId", invoiceID, "intentId", intent.ID, "amount", intent.Amount)
+	return intent, nil
+}
+
+// HandleWebhook verifies a gateway webhook delivery and records the payment
+// it reports. Gateways redeliver webhooks, so events already processed are
+// skipped and reported as duplicates, and a payment already recorded for the
+// same intent is returned instead of being booked twice.
+func (uc *OnlinePaymentUseCase) HandleWebhook(ctx context.Context, payload []byte, headers map[string][]string) (*PaymentWebhookResult, error) {
+	webhook, err := uc.gateway.ParseWebhook(payload, headers)
+	if err != nil {
+		uc.logger.Warn("Rejected payment webhook", "error", err)
+		return nil, err
+	}
+
+	if webhook.Payment == nil {
+		claimed, err := uc.events.Claim(ctx, uc.gateway.Method(), webhook.EventID)
+		if err != nil {
+			uc.logger.Error("Failed to claim payment webhook event", "error", err, "eventId", webhook.EventID)
+			return nil, fmt.Errorf("failed to claim payment webhook event: %w", err)
+		}
+		return &PaymentWebhookResult{Duplicate: !claimed}, nil
+	}
+
+	payment, err := uc.recordPayment(ctx, webhook.EventID, webhook.Payment)
+	if errors.Is(err, errPaymentWebhookProcessed) {
+		uc.logger.Info("Payment webhook event already processed", "eventId", webhook.EventID)
+		return &PaymentWebhookResult{Duplicate: true}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &PaymentWebhookResult{Payment: payment}, nil
+}
+
+// errPaymentWebhookProcessed rolls back a payment whose gateway event was
+// claimed by an earlier delivery
+var errPaymentWebhookProcessed = errors.New("payment webhook event already processed")
+
+// recordPayment books a collected payment against its invoice unless it was
+// already recorded for the same intent. The gateway event is claimed in the
+// unit of work of the payment, so concurrent deliveries book it once and a
+// failed payment leaves the event to be redelivered.
+func (uc *OnlinePaymentUseCase) recordPayment(ctx context.Context, eventID string, event *repository.PaymentEvent) (*domain.Payment, error) {
+	invoice, err := uc.invoiceUC.GetInvoice(ctx, event.OrganizationID, event.InvoiceID)
+	if err != nil {
+		return nil, err
+	}
+	payments, err := uc.invoices.GetPaymentsByInvoiceID(ctx, invoice.ID)
+	if err != nil {
+		uc.logger.Error("Failed to get invoice payments", "error", err, "invoiceId", invoice.ID)
+		return nil, fmt.Errorf("failed to get invoice payments: %w", err)
+	}
+	for _, payment := range payments {
+		if payment.PaymentMethod == uc.gateway.Method() && payment.ReferenceNumber == event.IntentID {
+			uc.logger.Info("Payment webhook already recorded", "invoiceId", invoice.ID, "intentId", event.IntentID)
+			return payment, nil
+		}
+	}
+
+	claim := func(repos repository.TxRepositories) error {
+		claimed, err := repos.PaymentWebhookEvents.Claim(ctx, uc.gateway.Method(), eventID)
+		if err != nil {
+			uc.logger.Error("Failed to claim payment webhook event", "error", err, "eventId", eventID)
+			return fmt.Errorf("failed to claim payment webhook event: %w", err)
+		}
+		if !claimed {
+			return errPaymentWebhookProcessed
+		}
+		return nil
+	}
+	return uc.invoiceUC.createPayment(ctx, CreatePaymentRequest{
+		OrganizationID:  event.OrganizationID,
+		InvoiceID:       invoice.ID,
+		PaymentMethod:   uc.gateway.Method(),
+		ReferenceNumber: event.IntentID,
+		Amount:          event.Amount,
+		Currency:        event.Currency,
+		PaymentDate:     event.PaidAt,
+		Notes:           "Paid online",
+		CreatedBy:       invoice.CreatedBy,
+	}, claim)
+}
@@ -0,0 +1,174 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/pmaojo/kthulu-go/backend/internal/domain"
+	"github.com/pmaojo/kthulu-go/backend/internal/domain/repository"
+)
+
+// fakePaymentGateway records the intents it's asked for and reports the
+// webhook encoded in payloads signed "valid"
+type fakePaymentGateway struct {
+	intents []repository.PaymentIntentRequest
+}
+
+func (g *fakePaymentGateway) Method() domain.PaymentMethod {
+	return domain.PaymentMethodStripe
+}
+
+func (g *fakePaymentGateway) CreatePaymentIntent(ctx context.Context, req repository.PaymentIntentRequest) (*repository.PaymentIntent, error) {
+	g.intents = append(g.intents, req)
+	return &repository.PaymentIntent{ID: "pi_1", ClientSecret: "pi_1_secret", Amount: req.Amount, Currency: req.Currency}, nil
+}
+
+func (g *fakePaymentGateway) ParseWebhook(payload []byte, headers map[string][]string) (*repository.PaymentWebhook, error) {
+	if len(headers["Signature"]) == 0 || headers["Signature"][0] != "valid" {
+		return nil, domain.ErrInvalidPaymentWebhook
+	}
+	var webhook repository.PaymentWebhook
+	if err := json.Unmarshal(payload, &webhook); err != nil {
+		return nil, err
+	}
+	return &webhook, nil
+}
+
+// fakePaymentWebhookEvents keeps the claimed event ids in memory
+type fakePaymentWebhookEvents struct {
+	processed map[string]bool
+}
+
+func (f *fakePaymentWebhookEvents) Claim(ctx context.Context, gateway domain.PaymentMethod, eventID string) (bool, error) {
+	if f.processed == nil {
+		f.processed = map[string]bool{}
+	}
+	key := string(gateway) + "/" + eventID
+	if f.processed[key] {
+		return false, nil
+	}
+	f.processed[key] = true
+	return true, nil
+}
+
+func newOnlinePaymentUseCase() (*OnlinePaymentUseCase, *fakePaymentGateway, *fakeInvoiceRepository) {
+	invoiceUC, invoices := newPaymentImportUseCase()
+	gateway := &fakePaymentGateway{}
+	return NewOnlinePaymentUseCase(invoices, invoiceUC, gateway, &invoices.webhookEvents, &recordingLogger{}), gateway, invoices
+}
+
+func TestOnlinePayment_CreatePaymentIntent(t *testing.T) {
+	uc, gateway, _ := newOnlinePaymentUseCase()
+
+	intent, err := uc.CreatePaymentIntent(context.Background(), 1, 4)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if intent.ClientSecret != "pi_1_secret" || len(gateway.intents) != 1 {
+		t.Fatalf("unexpected intent %+v", intent)
+	}
+	if req := gateway.intents[0]; req.InvoiceNumber != "INV-0777" || req.Amount != 70 || req.Currency != "USD" {
+		t.Fatalf("expected the intent to collect the balance due, got %+v", req)
+	}
+
+	// Settled invoices and other organizations' invoices can't be paid
+	if _, err := uc.CreatePaymentIntent(context.Background(), 1, 5); !errors.Is(err, domain.ErrInvoiceNotPayable) {
+		t.Fatalf("expected ErrInvoiceNotPayable, got %v", err)
+	}
+	if _, err := uc.CreatePaymentIntent(context.Background(), 1, 6); !errors.Is(err, domain.ErrInvoiceNotFound) {
+		t.Fatalf("expected ErrInvoiceNotFound, got %v", err)
+	}
+	if len(gateway.intents) != 1 {
+		t.Fatalf("expected no further intents, got %d", len(gateway.intents))
+	}
+}
+
+func TestOnlinePayment_HandleWebhookRecordsPayment(t *testing.T) {
+	uc, _, invoices := newOnlinePaymentUseCase()
+	invoices.invoices[1].CreatedBy = 3
+	paidAt := time.Date(2024, 5, 2, 10, 0, 0, 0, time.UTC)
+	event := &repository.PaymentEvent{IntentID: "pi_1", OrganizationID: 1, InvoiceID: 1, Amount: 40, Currency: "EUR", PaidAt: paidAt}
+	payload, _ := json.Marshal(repository.PaymentWebhook{EventID: "evt_1", Payment: event})
+	signed := map[string][]string{"Signature": {"valid"}}
+
+	result, err := uc.HandleWebhook(context.Background(), payload, signed)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	payment := result.Payment
+	if result.Duplicate || payment.PaymentMethod != domain.PaymentMethodStripe || payment.ReferenceNumber != "pi_1" || payment.Amount != 40 || payment.CreatedBy != 3 || !payment.PaymentDate.Equal(paidAt) {
+		t.Fatalf("unexpected result %+v", result)
+	}
+	if invoice := invoices.invoices[1]; invoice.BalanceDue != 60 || invoice.Status != domain.InvoiceStatusPartial {
+		t.Fatalf("expected a partially paid invoice, got balance %.2f, status %s", invoice.BalanceDue, invoice.Status)
+	}
+
+	// A different event for the same intent returns the recorded payment
+	payload, _ = json.Marshal(repository.PaymentWebhook{EventID: "evt_2", Payment: event})
+	again, err := uc.HandleWebhook(context.Background(), payload, signed)
+	if err != nil || again.Payment.ID != payment.ID || len(invoices.payments) != 1 {
+		t.Fatalf("expected the recorded payment back, got %+v, %v", again, err)
+	}
+
+	// Events other than collected payments are ignored
+	payload, _ = json.Marshal(repository.PaymentWebhook{EventID: "evt_3"})
+	if result, err := uc.HandleWebhook(context.Background(), payload, signed); err != nil || result.Payment != nil || result.Duplicate {
+		t.Fatalf("expected the event to be ignored, got %+v, %v", result, err)
+	}
+}
+
+func TestOnlinePayment_HandleWebhookSkipsDuplicateEvents(t *testing.T) {
+	uc, _, invoices := newOnlinePaymentUseCase()
+	invoices.invoices[1].CreatedBy = 3
+	payload, _ := json.Marshal(repository.PaymentWebhook{EventID: "evt_1", Payment: &repository.PaymentEvent{
+		IntentID: "pi_1", OrganizationID: 1, InvoiceID: 1, Amount: 40, Currency: "EUR", PaidAt: time.Now(),
+	}})
+	signed := map[string][]string{"Signature": {"valid"}}
+
+	if result, err := uc.HandleWebhook(context.Background(), payload, signed); err != nil || result.Duplicate {
+		t.Fatalf("expected the first delivery to be processed, got %+v, %v", result, err)
+	}
+	// Clear the recorded payment so only the event store can catch the redelivery
+	recorded := invoices.payments
+	invoices.payments = nil
+
+	result, err := uc.HandleWebhook(context.Background(), payload, signed)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result.Duplicate || result.Payment != nil || len(invoices.payments) != 0 {
+		t.Fatalf("expected the redelivery to be skipped, got %+v with %d payments", result, len(invoices.payments))
+	}
+	if len(recorded) != 1 || invoices.invoices[1].BalanceDue != 60 {
+		t.Fatalf("expected a single payment of 40, got %d payments and balance %.2f", len(recorded), invoices.invoices[1].BalanceDue)
+	}
+}
+
+func TestOnlinePayment_HandleWebhookRejectsBadSignature(t *testing.T) {
+	uc, _, invoices := newOnlinePaymentUseCase()
+	invoices.invoices[1].CreatedBy = 3
+	payload, _ := json.Marshal(repository.PaymentWebhook{EventID: "evt_1", Payment: &repository.PaymentEvent{
+		IntentID: "pi_1", OrganizationID: 1, InvoiceID: 1, Amount: 40, Currency: "EUR", PaidAt: time.Now(),
+	}})
+
+	for name, headers := range map[string]map[string][]string{
+		"unsigned": {},
+		"forged":   {"Signature": {"forged"}},
+	} {
+		if _, err := uc.HandleWebhook(context.Background(), payload, headers); !errors.Is(err, domain.ErrInvalidPaymentWebhook) {
+			t.Fatalf("%s: expected ErrInvalidPaymentWebhook, got %v", name, err)
+		}
+	}
+	if len(invoices.payments) != 0 {
+		t.Fatal("expected an unverified webhook to record nothing")
+	}
+
+	// A rejected delivery doesn't mark the event, so the genuine one is processed
+	result, err := uc.HandleWebhook(context.Background(), payload, map[string][]string{"Signature": {"valid"}})
+	if err != nil || result.Duplicate || result.Payment == nil {
+		t.Fatalf("expected the signed delivery to be processed, got %+v, %v", result, err)
+	}
+}
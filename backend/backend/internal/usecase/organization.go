@@ -4,9 +4,11 @@ package usecase
 import (
 	"context"
 	"crypto/rand"
+	"crypto/sha256"
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"net/http"
 	"time"
 
 	"github.com/pmaojo/kthulu-go/backend/core"
@@ -21,6 +23,9 @@ type OrganizationUseCase struct {
 	invitations   repository.InvitationRepository
 	users         repository.UserRepository
 	notifier      repository.NotificationProvider
+	blobs         repository.BlobStore
+	pushes        repository.UserNotifier
+	templates     repository.OrganizationEmailTemplates
 	logger        core.Logger
 }
 
@@ -31,6 +36,9 @@ func NewOrganizationUseCase(
 	invitations repository.InvitationRepository,
 	users repository.UserRepository,
 	notifier repository.NotificationProvider,
+	blobs repository.BlobStore,
+	pushes repository.UserNotifier,
+	templates repository.OrganizationEmailTemplates,
 	logger core.Logger,
 ) *OrganizationUseCase {
 	return &OrganizationUseCase{
@@ -39,6 +47,9 @@ func NewOrganizationUseCase(
 		invitations:   invitations,
 		users:         users,
 		notifier:      notifier,
+		blobs:         blobs,
+		pushes:        pushes,
+		templates:     templates,
 		logger:        logger,
 	}
 }
@@ -167,6 +178,8 @@ type UpdateOrganizationRequest struct {
 	State       *string `json:"state,omitempty" validate:"omitempty,max=100"`
 	Country     *string `json:"country,omitempty" validate:"omitempty,max=100"`
 	PostalCode  *string `json:"postalCode,omitempty" validate:"omitempty,max=20"`
+
+	InvoiceNumberFormat *string `json:"invoiceNumberFormat,omitempty" validate:"omitempty,max=100"`
 }
 
 // UpdateOrganization updates an organization
@@ -273,6 +286,15 @@ func (u *OrganizationUseCase) UpdateOrganization(ctx context.Context, userID, or
 		hasChanges = true
 	}
 
+	// Update invoice number format if provided
+	if req.InvoiceNumberFormat != nil {
+		if err := org.SetInvoiceNumberFormat(*req.InvoiceNumberFormat); err != nil {
+			u.logger.Warn("Invalid invoice number format", "organizationId", organizationID, "error", err)
+			return nil, err
+		}
+		hasChanges = true
+	}
+
 	// Save changes if any were made
 	if hasChanges {
 		if err := u.organizations.Update(ctx, org); err != nil {
@@ -285,6 +307,103 @@ func (u *OrganizationUseCase) UpdateOrganization(ctx context.Context, userID, or
 	return org, nil
 }
 
+// MaxOrganizationLogoSize is the largest logo UploadLogo accepts, in bytes
+const MaxOrganizationLogoSize = 2 << 20
+
+// organizationLogoExtensions maps the accepted logo content types to the
+// extension the stored file gets
+var organizationLogoExtensions = map[string]string{
+	"image/png":  ".png",
+	"image/jpeg": ".jpg",
+	"image/gif":  ".gif",
+	"image/webp": ".webp",
+}
+
+// UploadLogo stores a new logo for the organization and points LogoURL at it.
+// The content type is sniffed from the data rather than trusted from the
+// client.
+func (u *OrganizationUseCase) UploadLogo(ctx context.Context, userID, organizationID uint, data []byte) (*domain.Organization, error) {
+	u.logger.Info("Upload organization logo request", "userId", userID, "organizationId", organizationID, "size", len(data))
+
+	if len(data) > MaxOrganizationLogoSize {
+		return nil, domain.ErrLogoTooLarge
+	}
+	contentType := http.DetectContentType(data)
+	extension, ok := organizationLogoExtensions[contentType]
+	if len(data) == 0 || !ok {
+		u.logger.Warn("Rejected organization logo", "organizationId", organizationID, "contentType", contentType)
+		return nil, domain.ErrInvalidLogo
+	}
+
+	canManage, err := u.canManageOrganization(ctx, userID, organizationID)
+	if err != nil {
+		return nil, err
+	}
+	if !canManage {
+		u.logger.Warn("User attempted to upload organization logo without permissions", "userId", userID, "organizationId", organizationID)
+		return nil, domain.ErrInsufficientPermissions
+	}
+
+	org, err := u.organizations.FindByID(ctx, organizationID)
+	if err != nil {
+		u.logger.Error("Failed to find organization for logo upload", "organizationId", organizationID, "error", err)
+		return nil, fmt.Errorf("failed to find organization: %w", err)
+	}
+
+	// Logos are public so LogoURL resolves for anyone. Content-addressed keys
+	// give every logo a new URL, so caches never serve the previous image.
+	sum := sha256.Sum256(data)
+	key := fmt.Sprintf(repository.PublicBlobPrefix+"organizations/%d/logo-%s%s", organizationID, hex.EncodeToString(sum[:8]), extension)
+	logoURL, err := u.blobs.Put(ctx, key, contentType, data)
+	if err != nil {
+		u.logger.Error("Failed to store organization logo", "organizationId", organizationID, "error", err)
+		return nil, fmt.Errorf("failed to store logo: %w", err)
+	}
+
+	if err := org.SetLogoURL(logoURL); err != nil {
+		return nil, fmt.Errorf("failed to set logo: %w", err)
+	}
+	if err := u.organizations.Update(ctx, org); err != nil {
+		u.logger.Error("Failed to persist organization logo", "organizationId", organizationID, "error", err)
+		if delErr := u.blobs.Delete(ctx, key); delErr != nil {
+			u.logger.Warn("Failed to remove orphaned organization logo", "key", key, "error", delErr)
+		}
+		return nil, fmt.Errorf("failed to update organization: %w", err)
+	}
+
+	u.logger.Info("Organization logo uploaded", "organizationId", organizationID, "userId", userID, "logoUrl", logoURL)
+	return org, nil
+}
+
+// SetEmailTemplate replaces the template of the notificationType emails sent
+// on behalf of the organization
+func (u *OrganizationUseCase) SetEmailTemplate(ctx context.Context, userID, organizationID uint, notificationType repository.NotificationType, tmpl repository.EmailTemplate) error {
+	u.logger.Info("Set organization email template request", "userId", userID, "organizationId", organizationID, "type", notificationType)
+
+	if u.templates == nil {
+		return errors.New("email templates are not available")
+	}
+
+	canManage, err := u.canManageOrganization(ctx, userID, organizationID)
+	if err != nil {
+		return err
+	}
+	if !canManage {
+		u.logger.Warn("User attempted to set organization email template without permissions", "userId", userID, "organizationId", organizationID)
+		return domain.ErrInsufficientPermissions
+	}
+
+	if err := u.templates.SetOrganizationTemplate(ctx, organizationID, notificationType, tmpl); err != nil {
+		if !errors.Is(err, domain.ErrInvalidEmailTemplate) {
+			u.logger.Error("Failed to store organization email template", "organizationId", organizationID, "type", notificationType, "error", err)
+		}
+		return err
+	}
+
+	u.logger.Info("Organization email template updated", "organizationId", organizationID, "userId", userID, "type", notificationType)
+	return nil
+}
+
 // ListUserOrganizations lists organizations for a user
 func (u *OrganizationUseCase) ListUserOrganizations(ctx context.Context, userID uint) ([]*domain.Organization, error) {
 	u.logger.Info("List user organizations request", "userId", userID)
@@ -311,6 +430,48 @@ func (u *OrganizationUseCase) ListUserOrganizations(ctx context.Context, userID
 	return organizations, nil
 }
 
+// MemberListResponse is a page of organization members
+type MemberListResponse struct {
+	Members    []*domain.OrganizationMember `json:"members"`
+	Total      int64                        `json:"total"`
+	Page       int                          `json:"page"`
+	PageSize   int                          `json:"pageSize"`
+	TotalPages int64                        `json:"totalPages"`
+}
+
+// ListMembers lists the members of an organization the user belongs to
+func (u *OrganizationUseCase) ListMembers(ctx context.Context, userID, organizationID uint, filters repository.MemberFilters) (*MemberListResponse, error) {
+	u.logger.Info("List organization members request", "userId", userID, "organizationId", organizationID)
+
+	// Check if user is in organization
+	inOrg, err := u.orgUsers.IsUserInOrganization(ctx, organizationID, userID)
+	if err != nil {
+		u.logger.Error("Failed to check user organization membership", "userId", userID, "organizationId", organizationID, "error", err)
+		return nil, fmt.Errorf("failed to check organization membership: %w", err)
+	}
+	if !inOrg {
+		u.logger.Warn("User attempted to list members of organization they're not a member of", "userId", userID, "organizationId", organizationID)
+		return nil, domain.ErrUserNotInOrganization
+	}
+
+	if err := filters.Validate(); err != nil {
+		return nil, err
+	}
+	members, total, err := u.orgUsers.ListMembers(ctx, organizationID, filters)
+	if err != nil {
+		u.logger.Error("Failed to list organization members", "organizationId", organizationID, "error", err)
+		return nil, fmt.Errorf("failed to list members: %w", err)
+	}
+
+	return &MemberListResponse{
+		Members:    members,
+		Total:      total,
+		Page:       filters.Page,
+		PageSize:   filters.PageSize,
+		TotalPages: (total + int64(filters.PageSize) - 1) / int64(filters.PageSize),
+	}, nil
+}
+
 // InviteUserRequest contains the data needed to invite a user to an organization
 type InviteUserRequest struct {
 	Email   string                  `json:"email" validate:"required,email"`
@@ -467,6 +628,14 @@ func (u *OrganizationUseCase) AcceptInvitation(ctx context.Context, userID uint,
 		return nil, fmt.Errorf("failed to find organization: %w", err)
 	}
 
+	notifyUser(ctx, u.pushes, u.logger, invitation.InviterID, repository.UserNotificationInvitationAccepted, map[string]interface{}{
+		"organizationId":   invitation.OrganizationID,
+		"organizationName": org.Name,
+		"invitationId":     invitation.ID,
+		"userId":           userID,
+		"email":            invitation.Email,
+	})
+
 	u.logger.Info("Invitation accepted successfully", "userId", userID, "organizationId", invitation.OrganizationID)
 	return org, nil
 }
@@ -41,6 +41,9 @@ func (m *mockOrganizationUserRepository) FindByRole(ctx context.Context, organiz
 func (m *mockOrganizationUserRepository) CountByOrganization(ctx context.Context, organizationID uint) (int64, error) {
 	return 0, nil
 }
+func (m *mockOrganizationUserRepository) ListMembers(ctx context.Context, organizationID uint, filters repository.MemberFilters) ([]*domain.OrganizationMember, int64, error) {
+	return nil, 0, nil
+}
 func (m *mockOrganizationUserRepository) IsUserInOrganization(ctx context.Context, organizationID, userID uint) (bool, error) {
 	return false, nil
 }
@@ -81,7 +84,13 @@ func (m *mockInvitationRepository) FindByOrganization(ctx context.Context, organ
 	return nil, nil
 }
 func (m *mockInvitationRepository) FindByEmail(ctx context.Context, email string) ([]*domain.Invitation, error) {
-	return nil, nil
+	var found []*domain.Invitation
+	for _, invitation := range m.invitations {
+		if invitation.Email == email {
+			found = append(found, invitation)
+		}
+	}
+	return found, nil
 }
 func (m *mockInvitationRepository) FindByInviter(ctx context.Context, inviterID uint) ([]*domain.Invitation, error) {
 	return nil, nil
@@ -124,6 +133,9 @@ func (m *mockInvitationNotifier) SendPasswordReset(ctx context.Context, email, r
 func (m *mockInvitationNotifier) SendWelcomeEmail(ctx context.Context, email, name string) error {
 	return nil
 }
+func (m *mockInvitationNotifier) SendInvoiceEmail(ctx context.Context, email, invoiceNumber string, attachment repository.NotificationAttachment) error {
+	return nil
+}
 
 // recordingLogger records error messages
 type recordingLogger struct {
@@ -146,7 +158,7 @@ func TestInviteUser_SendsNotification(t *testing.T) {
 	notifier := &mockInvitationNotifier{}
 	logger := &recordingLogger{}
 
-	uc := NewOrganizationUseCase(nil, orgUserRepo, invRepo, userRepo, notifier, logger)
+	uc := NewOrganizationUseCase(nil, orgUserRepo, invRepo, userRepo, notifier, nil, nil, nil, logger)
 
 	req := InviteUserRequest{Email: "invitee@example.com", Role: domain.OrganizationRoleMember}
 	invitation, err := uc.InviteUser(ctx, 1, 1, req)
@@ -170,7 +182,7 @@ func TestInviteUser_NotificationFailureLogged(t *testing.T) {
 	notifier := &mockInvitationNotifier{err: errors.New("send failed")}
 	logger := &recordingLogger{}
 
-	uc := NewOrganizationUseCase(nil, orgUserRepo, invRepo, userRepo, notifier, logger)
+	uc := NewOrganizationUseCase(nil, orgUserRepo, invRepo, userRepo, notifier, nil, nil, nil, logger)
 
 	req := InviteUserRequest{Email: "invitee@example.com", Role: domain.OrganizationRoleMember}
 	invitation, err := uc.InviteUser(ctx, 1, 1, req)
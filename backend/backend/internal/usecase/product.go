@@ -70,6 +70,10 @@ func (uc *ProductUseCase) CreateProduct(ctx context.Context, organizationID uint
 		product.SetTrackable(*req.IsTrackable)
 	}
 
+	if err := uc.assignCategory(ctx, product, req.CategoryID); err != nil {
+		return nil, err
+	}
+
 	// Save to repository
 	if err := uc.productRepo.Create(ctx, product); err != nil {
 		uc.logger.Error("Failed to save product to repository", zap.Error(err))
@@ -140,6 +144,10 @@ func (uc *ProductUseCase) UpdateProduct(ctx context.Context, organizationID, pro
 		product.SetTrackable(*req.IsTrackable)
 	}
 
+	if err := uc.assignCategory(ctx, product, req.CategoryID); err != nil {
+		return nil, err
+	}
+
 	// Save changes
 	if err := uc.productRepo.Update(ctx, product); err != nil {
 		uc.logger.Error("Failed to update product in repository", zap.Error(err))
@@ -191,13 +199,7 @@ func (uc *ProductUseCase) ListProducts(ctx context.Context, organizationID uint,
 		}
 	}
 
-	return &ProductListResponse{
-		Products:   products,
-		Total:      total,
-		Page:       filters.Page,
-		PageSize:   filters.PageSize,
-		TotalPages: (total + int64(filters.PageSize) - 1) / int64(filters.PageSize),
-	}, nil
+	return NewListResponse(products, total, filters.Page, filters.PageSize), nil
 }
 
 // SetProductActive sets the active status of a product
@@ -235,6 +237,36 @@ func (uc *ProductUseCase) GetProductStats(ctx context.Context, organizationID ui
 	return stats, nil
 }
 
+// DefaultTopProductsLimit and MaxTopProductsLimit bound the product ranking
+const (
+	DefaultTopProductsLimit = 10
+	MaxTopProductsLimit     = 100
+)
+
+// GetTopProducts ranks the products sold in [from, to) by quantity
+func (uc *ProductUseCase) GetTopProducts(ctx context.Context, organizationID uint, from, to time.Time, limit int) ([]repository.ProductSales, error) {
+	if !to.After(from) {
+		return nil, fmt.Errorf("%w: end must be after start", domain.ErrInvalidStatsPeriod)
+	}
+	if limit <= 0 {
+		limit = DefaultTopProductsLimit
+	}
+	if limit > MaxTopProductsLimit {
+		limit = MaxTopProductsLimit
+	}
+
+	ranking, err := uc.productRepo.GetTopProducts(ctx, organizationID, from, to, limit)
+	if err != nil {
+		uc.logger.Error("Failed to get top products", zap.Error(err))
+		return nil, fmt.Errorf("failed to get top products: %w", err)
+	}
+	if ranking == nil {
+		ranking = []repository.ProductSales{}
+	}
+
+	return ranking, nil
+}
+
 // CreateProductVariant creates a new product variant
 func (uc *ProductUseCase) CreateProductVariant(ctx context.Context, organizationID, productID uint, req CreateVariantRequest) (*domain.ProductVariant, error) {
 	uc.logger.Info("Creating product variant",
@@ -385,6 +417,88 @@ func (uc *ProductUseCase) GetEffectivePrice(ctx context.Context, organizationID
 	return price, nil
 }
 
+// BulkUpdateTaxRate applies a new tax rate, given as a percentage, to
+// several products in one transaction. Products of other organizations are
+// left untouched.
+func (uc *ProductUseCase) BulkUpdateTaxRate(ctx context.Context, organizationID uint, req BulkTaxRateRequest) error {
+	uc.logger.Info("Bulk updating product tax rate",
+		zap.Uint("organization_id", organizationID),
+		zap.Int("count", len(req.IDs)),
+		zap.Float64("tax_rate_percent", req.TaxRatePercent),
+	)
+
+	taxRate, err := domain.TaxRateFromPercent(req.TaxRatePercent)
+	if err != nil {
+		return err
+	}
+
+	if err := uc.productRepo.BulkUpdateTaxRate(ctx, organizationID, req.IDs, taxRate); err != nil {
+		uc.logger.Error("Failed to bulk update product tax rate", zap.Error(err))
+		return fmt.Errorf("failed to bulk update product tax rate: %w", err)
+	}
+
+	return nil
+}
+
+// CreateCategory adds a category to the organization's category tree
+func (uc *ProductUseCase) CreateCategory(ctx context.Context, organizationID uint, req CreateCategoryRequest) (*domain.ProductCategory, error) {
+	category, err := domain.NewProductCategory(organizationID, req.ParentID, req.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := uc.productRepo.CreateCategory(ctx, category); err != nil {
+		uc.logger.Error("Failed to create product category", zap.Error(err))
+		return nil, err
+	}
+
+	uc.logger.Info("Product category created successfully",
+		zap.Uint("category_id", category.ID),
+		zap.String("name", category.Name),
+	)
+	return category, nil
+}
+
+// GetCategoryTree returns the organization's root categories with their
+// children
+func (uc *ProductUseCase) GetCategoryTree(ctx context.Context, organizationID uint) ([]*domain.ProductCategory, error) {
+	tree, err := uc.productRepo.ListCategoryTree(ctx, organizationID)
+	if err != nil {
+		uc.logger.Error("Failed to get product category tree", zap.Error(err))
+		return nil, fmt.Errorf("failed to get product categories: %w", err)
+	}
+	return tree, nil
+}
+
+// ListCategoryProducts lists the products of a category and of all its
+// descendants
+func (uc *ProductUseCase) ListCategoryProducts(ctx context.Context, organizationID, categoryID uint, filters repository.ProductFilters) (*ProductListResponse, error) {
+	if _, err := uc.productRepo.GetCategoryByID(ctx, organizationID, categoryID); err != nil {
+		return nil, err
+	}
+	filters.CategoryID = &categoryID
+	return uc.ListProducts(ctx, organizationID, filters)
+}
+
+// assignCategory links the product to a node of the category tree. The
+// legacy category string defaults to the node's name so both stay usable.
+func (uc *ProductUseCase) assignCategory(ctx context.Context, product *domain.Product, categoryID *uint) error {
+	if categoryID == nil || *categoryID == 0 {
+		product.CategoryID = nil
+		return nil
+	}
+
+	category, err := uc.productRepo.GetCategoryByID(ctx, product.OrganizationID, *categoryID)
+	if err != nil {
+		return err
+	}
+	product.CategoryID = &category.ID
+	if product.Category == "" {
+		product.Category = category.Name
+	}
+	return nil
+}
+
 // loadProductRelations loads variants and prices for a product
 func (uc *ProductUseCase) loadProductRelations(ctx context.Context, product *domain.Product) error {
 	// Load variants
@@ -436,6 +550,7 @@ type CreateProductRequest struct {
 	Barcode       string   `json:"barcode,omitempty" validate:"max=100"`
 	TaxRate       float64  `json:"taxRate" validate:"min=0,max=1"`
 	IsTrackable   *bool    `json:"isTrackable,omitempty"`
+	CategoryID    *uint    `json:"categoryId,omitempty"`
 }
 
 // UpdateProductRequest represents a request to update a product
@@ -450,6 +565,19 @@ type UpdateProductRequest struct {
 	Barcode       string   `json:"barcode,omitempty" validate:"max=100"`
 	TaxRate       float64  `json:"taxRate" validate:"min=0,max=1"`
 	IsTrackable   *bool    `json:"isTrackable,omitempty"`
+	CategoryID    *uint    `json:"categoryId,omitempty"`
+}
+
+// BulkTaxRateRequest contains the products whose tax rate changes
+type BulkTaxRateRequest struct {
+	IDs            []uint  `json:"ids" validate:"required,min=1,max=500"`
+	TaxRatePercent float64 `json:"taxRatePercent" validate:"min=0,max=100"`
+}
+
+// CreateCategoryRequest represents a request to create a product category
+type CreateCategoryRequest struct {
+	Name     string `json:"name" validate:"required,min=1,max=100"`
+	ParentID *uint  `json:"parentId,omitempty"`
 }
 
 // CreateVariantRequest represents a request to create a product variant
@@ -486,10 +614,4 @@ type GetEffectivePriceRequest struct {
 }
 
 // ProductListResponse represents a paginated list of products
-type ProductListResponse struct {
-	Products   []*domain.Product `json:"products"`
-	Total      int64             `json:"total"`
-	Page       int               `json:"page"`
-	PageSize   int               `json:"pageSize"`
-	TotalPages int64             `json:"totalPages"`
-}
+type ProductListResponse = ListResponse[*domain.Product]
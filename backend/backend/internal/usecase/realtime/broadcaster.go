@@ -3,8 +3,10 @@ package realtime
 import (
 	"context"
 	"encoding/json"
+	"sync"
+	"time"
 
-	"nhooyr.io/websocket"
+	"github.com/gorilla/websocket"
 
 	"github.com/pmaojo/kthulu-go/backend/internal/domain/repository"
 )
@@ -20,9 +22,15 @@ type Broadcaster interface {
 	Broadcast(ctx context.Context, event DomainEvent) error
 }
 
+// broadcastWriteTimeout bounds writing an event to a connection when ctx
+// has no deadline of its own
+const broadcastWriteTimeout = 10 * time.Second
+
 // Service implements the Broadcaster interface.
 type Service struct {
 	repo repository.ConnectionRepository
+	// mu serializes broadcasts, as a connection takes one writer at a time
+	mu sync.Mutex
 }
 
 // NewService creates a new real-time broadcasting service.
@@ -40,8 +48,16 @@ func (s *Service) Broadcast(ctx context.Context, event DomainEvent) error {
 	if err != nil {
 		return err
 	}
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(broadcastWriteTimeout)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	for _, c := range conns {
-		_ = c.Write(ctx, websocket.MessageText, msg)
+		c.SetWriteDeadline(deadline)
+		_ = c.WriteMessage(websocket.TextMessage, msg)
 	}
 	return nil
 }
@@ -0,0 +1,22 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/pmaojo/kthulu-go/backend/core"
+	"github.com/pmaojo/kthulu-go/backend/internal/domain/repository"
+)
+
+// notifyUser pushes an in-app notification to userID when realtime
+// notifications are enabled. Notifications are best effort: a failed push is
+// logged and never fails the operation that triggered it.
+func notifyUser(ctx context.Context, pushes repository.UserNotifier, logger core.Logger, userID uint, notificationType repository.UserNotificationType, data map[string]interface{}) {
+	if pushes == nil || userID == 0 {
+		return
+	}
+	notification := repository.UserNotification{Type: notificationType, Data: data, SentAt: time.Now()}
+	if err := pushes.NotifyUser(ctx, userID, notification); err != nil {
+		logger.Warn("Failed to push user notification", "error", err, "userId", userID, "type", notificationType)
+	}
+}
@@ -0,0 +1,28 @@
+-- +goose Up
+-- Keep a snapshot of every product price before it is overwritten
+
+CREATE TABLE product_price_history (
+    id INTEGER PRIMARY KEY,
+    price_id INTEGER NOT NULL,
+    product_id INTEGER,
+    product_variant_id INTEGER,
+    price_type TEXT NOT NULL,
+    currency TEXT NOT NULL,
+    amount REAL NOT NULL,
+    min_quantity INTEGER DEFAULT 1,
+    max_quantity INTEGER,
+    valid_from TEXT,
+    valid_until TEXT,
+    is_active INTEGER NOT NULL DEFAULT 1,
+    created_at TEXT NOT NULL,
+    changed_at TEXT NOT NULL DEFAULT (datetime('now')),
+    FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE,
+    FOREIGN KEY (product_variant_id) REFERENCES product_variants(id) ON DELETE CASCADE
+);
+
+-- Create indexes for product_price_history table
+CREATE INDEX idx_product_price_history_price_id ON product_price_history(price_id);
+CREATE INDEX idx_product_price_history_product_changed ON product_price_history(product_id, changed_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS product_price_history;
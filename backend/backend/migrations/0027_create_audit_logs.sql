@@ -0,0 +1,22 @@
+-- +goose Up
+-- Create audit_logs table recording repository mutations
+
+CREATE TABLE audit_logs (
+    id INTEGER PRIMARY KEY,
+    organization_id INTEGER NOT NULL,
+    actor_id INTEGER,
+    action TEXT NOT NULL CHECK (action IN ('create', 'update', 'delete')),
+    entity_type TEXT NOT NULL,
+    entity_id INTEGER NOT NULL,
+    diff TEXT, -- JSON object
+    created_at TEXT NOT NULL DEFAULT (datetime('now'))
+);
+
+-- Create indexes for audit_logs table
+CREATE INDEX idx_audit_logs_entity ON audit_logs(entity_type, entity_id);
+CREATE INDEX idx_audit_logs_organization_id ON audit_logs(organization_id);
+CREATE INDEX idx_audit_logs_actor_id ON audit_logs(actor_id);
+CREATE INDEX idx_audit_logs_created_at ON audit_logs(created_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS audit_logs;
@@ -0,0 +1,10 @@
+-- +goose Up
+-- Track the last chain hash produced by a VeriFactu export per organization
+CREATE TABLE IF NOT EXISTS verifactu_export_chain (
+    organization_id INTEGER PRIMARY KEY,
+    last_hash TEXT NOT NULL,
+    updated_at TEXT NOT NULL DEFAULT (datetime('now'))
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS verifactu_export_chain;
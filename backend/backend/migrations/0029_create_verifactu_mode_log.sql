@@ -0,0 +1,14 @@
+-- +goose Up
+-- Log every VeriFactu live mode transition and what triggered it
+CREATE TABLE IF NOT EXISTS verifactu_mode_log (
+    id INTEGER PRIMARY KEY,
+    fiscal_year INT NOT NULL,
+    live_mode BOOLEAN NOT NULL,
+    triggered_by TEXT NOT NULL,
+    changed_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX idx_verifactu_mode_log_year_changed ON verifactu_mode_log(fiscal_year, changed_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS verifactu_mode_log;
@@ -0,0 +1,35 @@
+-- +goose Up
+-- Create webhook tables for organization event subscriptions
+
+CREATE TABLE webhook_endpoints (
+    id INTEGER PRIMARY KEY,
+    organization_id INTEGER NOT NULL,
+    url TEXT NOT NULL,
+    secret TEXT NOT NULL,
+    events TEXT NOT NULL DEFAULT '', -- comma-separated, empty means all events
+    active INTEGER NOT NULL DEFAULT 1,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE webhook_deliveries (
+    id INTEGER PRIMARY KEY,
+    endpoint_id INTEGER NOT NULL REFERENCES webhook_endpoints(id) ON DELETE CASCADE,
+    organization_id INTEGER NOT NULL,
+    event TEXT NOT NULL,
+    payload TEXT NOT NULL, -- JSON object
+    attempt INTEGER NOT NULL,
+    status_code INTEGER,
+    error TEXT,
+    delivered INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create indexes for webhook tables
+CREATE INDEX idx_webhook_endpoints_organization_id ON webhook_endpoints(organization_id);
+CREATE INDEX idx_webhook_deliveries_endpoint_id ON webhook_deliveries(endpoint_id);
+CREATE INDEX idx_webhook_deliveries_created_at ON webhook_deliveries(created_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS webhook_deliveries;
+DROP TABLE IF EXISTS webhook_endpoints;
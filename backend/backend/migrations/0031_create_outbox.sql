@@ -0,0 +1,19 @@
+-- +goose Up
+-- Create outbox table for events written in the same transaction as their source
+
+CREATE TABLE outbox (
+    id INTEGER PRIMARY KEY,
+    organization_id INTEGER NOT NULL,
+    event TEXT NOT NULL,
+    payload TEXT NOT NULL, -- JSON object
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    delivered_at TIMESTAMP
+);
+
+-- Create indexes for outbox table
+CREATE INDEX idx_outbox_pending ON outbox(delivered_at, id);
+
+-- +goose Down
+DROP TABLE IF EXISTS outbox;
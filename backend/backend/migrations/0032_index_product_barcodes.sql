@@ -0,0 +1,7 @@
+-- +goose Up
+-- Barcode lookups from point-of-sale scanners are scoped to an organization
+
+CREATE INDEX idx_products_org_barcode ON products(organization_id, barcode);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_products_org_barcode;
@@ -0,0 +1,22 @@
+-- +goose Up
+-- Create the inventory ledger; every stock level change appends a signed movement
+
+CREATE TABLE inventory_movements (
+    id INTEGER PRIMARY KEY,
+    product_id INTEGER NOT NULL,
+    variant_id INTEGER,
+    warehouse_id INTEGER NOT NULL,
+    delta INTEGER NOT NULL,
+    reason TEXT NOT NULL,
+    reference TEXT,
+    created_by INTEGER,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create indexes for the inventory ledger
+CREATE INDEX idx_inventory_movements_product_id ON inventory_movements(product_id);
+CREATE INDEX idx_inventory_movements_warehouse_id ON inventory_movements(warehouse_id);
+CREATE INDEX idx_inventory_movements_created_at ON inventory_movements(created_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS inventory_movements;
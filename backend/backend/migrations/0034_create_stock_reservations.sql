@@ -0,0 +1,19 @@
+-- +goose Up
+-- Create stock reservations held for pending quotes and invoices
+
+CREATE TABLE stock_reservations (
+    id INTEGER PRIMARY KEY,
+    inventory_item_id INTEGER NOT NULL,
+    product_id INTEGER NOT NULL,
+    quantity INTEGER NOT NULL,
+    reference TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create indexes for stock reservations
+CREATE INDEX idx_stock_reservations_inventory_item_id ON stock_reservations(inventory_item_id);
+CREATE INDEX idx_stock_reservations_product_id ON stock_reservations(product_id);
+CREATE INDEX idx_stock_reservations_reference ON stock_reservations(reference);
+
+-- +goose Down
+DROP TABLE IF EXISTS stock_reservations;
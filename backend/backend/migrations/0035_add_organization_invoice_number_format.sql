@@ -0,0 +1,7 @@
+-- +goose Up
+-- Let organizations choose the template of their invoice numbers
+
+ALTER TABLE organizations ADD COLUMN invoice_number_format VARCHAR(100) NOT NULL DEFAULT '';
+
+-- +goose Down
+ALTER TABLE organizations DROP COLUMN invoice_number_format;
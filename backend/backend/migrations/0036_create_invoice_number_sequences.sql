@@ -0,0 +1,15 @@
+-- +goose Up
+-- Create the counters invoice numbers are allocated from, one per
+-- organization, invoice type and numbering period
+
+CREATE TABLE invoice_number_sequences (
+    organization_id INTEGER NOT NULL,
+    type TEXT NOT NULL,
+    period TEXT NOT NULL,
+    last_value INTEGER NOT NULL,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (organization_id, type, period)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS invoice_number_sequences;
@@ -0,0 +1,83 @@
+// @kthulu:core
+// Package migrations registers the migrations that cannot be written as
+// plain SQL shared by SQLite and PostgreSQL. Binaries that run migrations
+// import it for its side effects.
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/pressly/goose/v3"
+)
+
+// searchTrigramIndexes index the expressions the repositories search with
+// LOWER(column) LIKE '%term%', which a btree index cannot serve
+var searchTrigramIndexes = []struct {
+	name, table, column string
+}{
+	{"idx_invoices_invoice_number_trgm", "invoices", "invoice_number"},
+	{"idx_products_name_trgm", "products", "name"},
+	{"idx_products_sku_trgm", "products", "sku"},
+	{"idx_products_description_trgm", "products", "description"},
+	{"idx_contacts_company_name_trgm", "contacts", "company_name"},
+	{"idx_contacts_first_name_trgm", "contacts", "first_name"},
+	{"idx_contacts_last_name_trgm", "contacts", "last_name"},
+	{"idx_contacts_email_trgm", "contacts", "email"},
+}
+
+func init() {
+	goose.AddMigrationNoTxContext(upSearchTrigramIndexes, downSearchTrigramIndexes)
+}
+
+// upSearchTrigramIndexes creates pg_trgm indexes on the searched columns.
+// SQLite has no trigram indexes, so the migration does nothing there.
+func upSearchTrigramIndexes(ctx context.Context, db *sql.DB) error {
+	if isSQLite(db) {
+		return nil
+	}
+
+	if _, err := db.ExecContext(ctx, `CREATE EXTENSION IF NOT EXISTS pg_trgm`); err != nil {
+		return fmt.Errorf("failed to create pg_trgm extension: %w", err)
+	}
+	// Built concurrently so large tables stay writable while they index.
+	// Tables of modules a project was generated without are skipped.
+	for _, index := range searchTrigramIndexes {
+		var exists bool
+		if err := db.QueryRowContext(ctx, `SELECT to_regclass($1) IS NOT NULL`, index.table).Scan(&exists); err != nil {
+			return fmt.Errorf("failed to look up table %s: %w", index.table, err)
+		}
+		if !exists {
+			continue
+		}
+		query := fmt.Sprintf(
+			"CREATE INDEX CONCURRENTLY IF NOT EXISTS %s ON %s USING gin (LOWER(%s) gin_trgm_ops)",
+			index.name, index.table, index.column,
+		)
+		if _, err := db.ExecContext(ctx, query); err != nil {
+			return fmt.Errorf("failed to create index %s: %w", index.name, err)
+		}
+	}
+	return nil
+}
+
+// downSearchTrigramIndexes drops the indexes but keeps the extension, which
+// other objects may depend on
+func downSearchTrigramIndexes(ctx context.Context, db *sql.DB) error {
+	if isSQLite(db) {
+		return nil
+	}
+
+	for _, index := range searchTrigramIndexes {
+		if _, err := db.ExecContext(ctx, "DROP INDEX CONCURRENTLY IF EXISTS "+index.name); err != nil {
+			return fmt.Errorf("failed to drop index %s: %w", index.name, err)
+		}
+	}
+	return nil
+}
+
+func isSQLite(db *sql.DB) bool {
+	return strings.Contains(strings.ToLower(fmt.Sprintf("%T", db.Driver())), "sqlite")
+}
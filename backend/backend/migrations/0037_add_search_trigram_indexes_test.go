@@ -0,0 +1,134 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+	_ "modernc.org/sqlite"
+)
+
+func TestSearchTrigramIndexesSkipSQLite(t *testing.T) {
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	if err := upSearchTrigramIndexes(ctx, db); err != nil {
+		t.Fatalf("up: %v", err)
+	}
+	if err := downSearchTrigramIndexes(ctx, db); err != nil {
+		t.Fatalf("down: %v", err)
+	}
+}
+
+// TestSearchTrigramIndexesServeSearches documents that Postgres answers the
+// repositories' LOWER(column) LIKE '%term%' searches from the trigram indexes.
+// It runs against the database in KTHULU_TEST_POSTGRES_URL, inside a scratch
+// schema that is dropped afterwards.
+func TestSearchTrigramIndexesServeSearches(t *testing.T) {
+	url := os.Getenv("KTHULU_TEST_POSTGRES_URL")
+	if url == "" {
+		t.Skip("KTHULU_TEST_POSTGRES_URL is not set")
+	}
+
+	db, err := sql.Open("pgx", url)
+	if err != nil {
+		t.Fatalf("open postgres: %v", err)
+	}
+	defer db.Close()
+	// One connection, so the search path and planner settings stick
+	db.SetMaxOpenConns(1)
+
+	ctx := context.Background()
+	schema := fmt.Sprintf("kthulu_trgm_test_%d", time.Now().UnixNano())
+	exec := func(query string, args ...any) {
+		t.Helper()
+		if _, err := db.ExecContext(ctx, query, args...); err != nil {
+			t.Fatalf("%s: %v", query, err)
+		}
+	}
+	exec("CREATE SCHEMA " + schema)
+	defer db.ExecContext(ctx, "DROP SCHEMA "+schema+" CASCADE")
+	exec("SET search_path TO " + schema + ", public")
+
+	exec(`CREATE TABLE invoices (id SERIAL PRIMARY KEY, organization_id INTEGER NOT NULL, invoice_number TEXT NOT NULL)`)
+	exec(`CREATE TABLE products (id SERIAL PRIMARY KEY, organization_id INTEGER NOT NULL, name TEXT NOT NULL, sku TEXT NOT NULL, description TEXT NOT NULL DEFAULT '')`)
+	exec(`CREATE TABLE contacts (id SERIAL PRIMARY KEY, organization_id INTEGER NOT NULL, company_name TEXT, first_name TEXT, last_name TEXT, email TEXT)`)
+	for i := 0; i < 200; i++ {
+		exec(`INSERT INTO invoices (organization_id, invoice_number) VALUES (1, $1)`, fmt.Sprintf("INV-2024-%04d", i))
+		exec(`INSERT INTO products (organization_id, name, sku) VALUES (1, $1, $2)`, fmt.Sprintf("Widget %d", i), fmt.Sprintf("WID-%04d", i))
+		exec(`INSERT INTO contacts (organization_id, company_name, first_name, last_name, email) VALUES (1, $1, 'Jane', 'Doe', $2)`, fmt.Sprintf("Acme %d", i), fmt.Sprintf("jane%d@example.com", i))
+	}
+
+	if err := upSearchTrigramIndexes(ctx, db); err != nil {
+		t.Fatalf("up: %v", err)
+	}
+	exec("ANALYZE")
+	// Small tables are cheaper to scan; make the planner show it can use the index
+	exec("SET enable_seqscan = off")
+
+	for _, tc := range []struct {
+		query string
+		args  int
+		index []string
+	}{
+		{
+			`SELECT id FROM invoices WHERE organization_id = $1 AND LOWER(invoice_number) LIKE $2`,
+			1, []string{"idx_invoices_invoice_number_trgm"},
+		},
+		{
+			`SELECT id FROM products WHERE organization_id = $1 AND (LOWER(name) LIKE $2 OR LOWER(sku) LIKE $3 OR LOWER(description) LIKE $4)`,
+			3, []string{"idx_products_name_trgm", "idx_products_sku_trgm", "idx_products_description_trgm"},
+		},
+		{
+			`SELECT id FROM contacts WHERE organization_id = $1 AND (LOWER(company_name) LIKE $2 OR LOWER(first_name) LIKE $3 OR LOWER(last_name) LIKE $4 OR LOWER(email) LIKE $5)`,
+			4, []string{"idx_contacts_company_name_trgm", "idx_contacts_email_trgm"},
+		},
+	} {
+		args := []any{1}
+		for i := 0; i < tc.args; i++ {
+			args = append(args, "%acm%")
+		}
+		plan := explain(t, db, tc.query, args...)
+		for _, index := range tc.index {
+			if !strings.Contains(plan, index) {
+				t.Fatalf("expected %s in the plan of %s:\n%s", index, tc.query, plan)
+			}
+		}
+	}
+
+	if err := downSearchTrigramIndexes(ctx, db); err != nil {
+		t.Fatalf("down: %v", err)
+	}
+}
+
+func explain(t *testing.T, db *sql.DB, query string, args ...any) string {
+	t.Helper()
+	rows, err := db.Query("EXPLAIN "+query, args...)
+	if err != nil {
+		t.Fatalf("explain: %v", err)
+	}
+	defer rows.Close()
+
+	var plan strings.Builder
+	for rows.Next() {
+		var line string
+		if err := rows.Scan(&line); err != nil {
+			t.Fatalf("scan plan: %v", err)
+		}
+		plan.WriteString(line)
+		plan.WriteString("\n")
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatalf("read plan: %v", err)
+	}
+	return plan.String()
+}
@@ -0,0 +1,7 @@
+-- +goose Up
+-- Let administrators disable accounts without deleting them
+
+ALTER TABLE users ADD COLUMN disabled_at TIMESTAMP;
+
+-- +goose Down
+ALTER TABLE users DROP COLUMN disabled_at;
@@ -0,0 +1,20 @@
+-- +goose Up
+-- Create auth_events table recording login, confirmation and refresh attempts
+
+CREATE TABLE auth_events (
+    id INTEGER PRIMARY KEY,
+    user_id INTEGER,
+    email TEXT,
+    event_type TEXT NOT NULL,
+    ip_address TEXT,
+    user_agent TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create indexes for auth_events table
+CREATE INDEX idx_auth_events_user_id ON auth_events(user_id);
+CREATE INDEX idx_auth_events_email ON auth_events(email);
+CREATE INDEX idx_auth_events_created_at ON auth_events(created_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS auth_events;
@@ -0,0 +1,37 @@
+-- +goose Up
+-- Nested product categories. products.category stays for backward
+-- compatibility; products are linked to the tree through category_id.
+
+CREATE TABLE product_categories (
+    id INTEGER PRIMARY KEY,
+    organization_id INTEGER NOT NULL,
+    parent_id INTEGER,
+    name TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (organization_id) REFERENCES organizations(id) ON DELETE CASCADE,
+    FOREIGN KEY (parent_id) REFERENCES product_categories(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_product_categories_org_parent ON product_categories(organization_id, parent_id);
+
+ALTER TABLE products ADD COLUMN category_id INTEGER REFERENCES product_categories(id) ON DELETE SET NULL;
+CREATE INDEX idx_products_category_id ON products(category_id);
+
+-- Existing category names become root categories of their organization
+INSERT INTO product_categories (organization_id, name)
+SELECT DISTINCT organization_id, category FROM products
+WHERE category IS NOT NULL AND category <> '';
+
+UPDATE products SET category_id = (
+    SELECT c.id FROM product_categories c
+    WHERE c.organization_id = products.organization_id
+      AND c.parent_id IS NULL
+      AND c.name = products.category
+)
+WHERE category IS NOT NULL AND category <> '';
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_products_category_id;
+ALTER TABLE products DROP COLUMN category_id;
+DROP TABLE IF EXISTS product_categories;
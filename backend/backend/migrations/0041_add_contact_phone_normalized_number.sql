@@ -0,0 +1,10 @@
+-- +goose Up
+-- E.164 form of contact phone numbers, next to the number as entered.
+-- Existing rows stay empty until their phone is saved again.
+
+ALTER TABLE contact_phones ADD COLUMN normalized_number TEXT NOT NULL DEFAULT '';
+CREATE INDEX idx_contact_phones_normalized_number ON contact_phones(normalized_number);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_contact_phones_normalized_number;
+ALTER TABLE contact_phones DROP COLUMN normalized_number;
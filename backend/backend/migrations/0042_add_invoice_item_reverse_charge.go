@@ -0,0 +1,54 @@
+// @kthulu:module:invoices
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigrationNoTxContext(upInvoiceItemReverseCharge, downInvoiceItemReverseCharge)
+}
+
+// upInvoiceItemReverseCharge flags lines whose tax is reverse-charged to the
+// buyer, such as EU B2B sales across member states. They carry no tax
+// amount. Projects generated without invoices have no invoice_items table
+// and are skipped.
+func upInvoiceItemReverseCharge(ctx context.Context, db *sql.DB) error {
+	exists, err := tableExists(ctx, db, "invoice_items")
+	if err != nil || !exists {
+		return err
+	}
+	if _, err := db.ExecContext(ctx, `ALTER TABLE invoice_items ADD COLUMN reverse_charge BOOLEAN NOT NULL DEFAULT FALSE`); err != nil {
+		return fmt.Errorf("failed to add invoice_items.reverse_charge: %w", err)
+	}
+	return nil
+}
+
+func downInvoiceItemReverseCharge(ctx context.Context, db *sql.DB) error {
+	exists, err := tableExists(ctx, db, "invoice_items")
+	if err != nil || !exists {
+		return err
+	}
+	if _, err := db.ExecContext(ctx, `ALTER TABLE invoice_items DROP COLUMN reverse_charge`); err != nil {
+		return fmt.Errorf("failed to drop invoice_items.reverse_charge: %w", err)
+	}
+	return nil
+}
+
+// tableExists reports whether the table was created, by a module migration
+// or by a project generated with that module
+func tableExists(ctx context.Context, db *sql.DB, table string) (bool, error) {
+	query := `SELECT to_regclass($1) IS NOT NULL`
+	if isSQLite(db) {
+		query = `SELECT COUNT(*) > 0 FROM sqlite_master WHERE type = 'table' AND name = $1`
+	}
+	var exists bool
+	if err := db.QueryRowContext(ctx, query, table).Scan(&exists); err != nil {
+		return false, fmt.Errorf("failed to look up table %s: %w", table, err)
+	}
+	return exists, nil
+}
@@ -0,0 +1,41 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	_ "modernc.org/sqlite"
+)
+
+func TestInvoiceItemReverseChargeSkipsMissingTable(t *testing.T) {
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+
+	ctx := context.Background()
+	if err := upInvoiceItemReverseCharge(ctx, db); err != nil {
+		t.Fatalf("up without invoice_items: %v", err)
+	}
+
+	if _, err := db.Exec(`CREATE TABLE invoice_items (id INTEGER PRIMARY KEY)`); err != nil {
+		t.Fatalf("create invoice_items: %v", err)
+	}
+	if err := upInvoiceItemReverseCharge(ctx, db); err != nil {
+		t.Fatalf("up: %v", err)
+	}
+	if _, err := db.Exec(`INSERT INTO invoice_items (id) VALUES (1)`); err != nil {
+		t.Fatalf("insert: %v", err)
+	}
+	var reverseCharge bool
+	if err := db.QueryRow(`SELECT reverse_charge FROM invoice_items`).Scan(&reverseCharge); err != nil || reverseCharge {
+		t.Fatalf("expected lines to default to no reverse charge, got %v (%v)", reverseCharge, err)
+	}
+
+	if err := downInvoiceItemReverseCharge(ctx, db); err != nil {
+		t.Fatalf("down: %v", err)
+	}
+}
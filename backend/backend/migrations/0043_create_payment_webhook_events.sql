@@ -0,0 +1,13 @@
+-- +goose Up
+-- Gateway webhook events already processed. Gateways redeliver webhooks, so
+-- deliveries of an event recorded here are acknowledged and skipped.
+
+CREATE TABLE payment_webhook_events (
+    gateway VARCHAR(50) NOT NULL,
+    event_id VARCHAR(255) NOT NULL,
+    processed_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (gateway, event_id)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS payment_webhook_events;
@@ -0,0 +1,7 @@
+-- +goose Up
+-- Park outbox events that ran out of delivery attempts
+
+ALTER TABLE outbox ADD COLUMN dead_lettered_at TIMESTAMP;
+
+-- +goose Down
+ALTER TABLE outbox DROP COLUMN dead_lettered_at;
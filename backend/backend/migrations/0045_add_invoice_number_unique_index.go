@@ -0,0 +1,35 @@
+// @kthulu:module:invoices
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigrationNoTxContext(upInvoiceNumberUniqueIndex, downInvoiceNumberUniqueIndex)
+}
+
+// upInvoiceNumberUniqueIndex keeps two invoices of an organization from
+// sharing a number. Projects generated without invoices have no invoices
+// table and are skipped.
+func upInvoiceNumberUniqueIndex(ctx context.Context, db *sql.DB) error {
+	exists, err := tableExists(ctx, db, "invoices")
+	if err != nil || !exists {
+		return err
+	}
+	if _, err := db.ExecContext(ctx, `CREATE UNIQUE INDEX IF NOT EXISTS idx_invoices_organization_number ON invoices (organization_id, invoice_number)`); err != nil {
+		return fmt.Errorf("failed to create idx_invoices_organization_number: %w", err)
+	}
+	return nil
+}
+
+func downInvoiceNumberUniqueIndex(ctx context.Context, db *sql.DB) error {
+	if _, err := db.ExecContext(ctx, `DROP INDEX IF EXISTS idx_invoices_organization_number`); err != nil {
+		return fmt.Errorf("failed to drop idx_invoices_organization_number: %w", err)
+	}
+	return nil
+}
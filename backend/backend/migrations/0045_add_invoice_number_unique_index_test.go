@@ -0,0 +1,40 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	_ "modernc.org/sqlite"
+)
+
+func TestInvoiceNumberUniqueIndex(t *testing.T) {
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+
+	ctx := context.Background()
+	if err := upInvoiceNumberUniqueIndex(ctx, db); err != nil {
+		t.Fatalf("up without invoices: %v", err)
+	}
+
+	if _, err := db.Exec(`CREATE TABLE invoices (id INTEGER PRIMARY KEY, organization_id INTEGER, invoice_number TEXT)`); err != nil {
+		t.Fatalf("create invoices: %v", err)
+	}
+	if err := upInvoiceNumberUniqueIndex(ctx, db); err != nil {
+		t.Fatalf("up: %v", err)
+	}
+	if _, err := db.Exec(`INSERT INTO invoices (organization_id, invoice_number) VALUES (1, 'INV-1'), (2, 'INV-1')`); err != nil {
+		t.Fatalf("expected organizations to number independently: %v", err)
+	}
+	if _, err := db.Exec(`INSERT INTO invoices (organization_id, invoice_number) VALUES (1, 'INV-1')`); err == nil {
+		t.Fatal("expected a repeated number to be rejected")
+	}
+
+	if err := downInvoiceNumberUniqueIndex(ctx, db); err != nil {
+		t.Fatalf("down: %v", err)
+	}
+}
@@ -0,0 +1,8 @@
+-- +goose Up
+-- Remember the access token issued with each refresh token so ending the
+-- session can revoke it too
+
+ALTER TABLE refresh_tokens ADD COLUMN access_token_id TEXT NOT NULL DEFAULT '';
+
+-- +goose Down
+ALTER TABLE refresh_tokens DROP COLUMN access_token_id;
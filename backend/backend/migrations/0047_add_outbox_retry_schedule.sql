@@ -0,0 +1,9 @@
+-- +goose Up
+-- Schedule outbox retries with backoff and lease claimed events to one relay
+
+ALTER TABLE outbox ADD COLUMN next_attempt_at TIMESTAMP;
+ALTER TABLE outbox ADD COLUMN locked_until TIMESTAMP;
+
+-- +goose Down
+ALTER TABLE outbox DROP COLUMN locked_until;
+ALTER TABLE outbox DROP COLUMN next_attempt_at;
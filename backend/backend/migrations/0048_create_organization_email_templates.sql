@@ -0,0 +1,15 @@
+-- +goose Up
+-- Email templates organizations use in place of the built-in ones
+
+CREATE TABLE organization_email_templates (
+    organization_id INTEGER NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    notification_type VARCHAR(50) NOT NULL,
+    subject TEXT NOT NULL,
+    html TEXT NOT NULL,
+    text TEXT NOT NULL,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (organization_id, notification_type)
+);
+
+-- +goose Down
+DROP TABLE organization_email_templates;
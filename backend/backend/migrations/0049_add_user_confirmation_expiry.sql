@@ -0,0 +1,7 @@
+-- +goose Up
+-- Confirmation codes stop being accepted once they expire
+
+ALTER TABLE users ADD COLUMN confirmation_expires_at TIMESTAMP;
+
+-- +goose Down
+ALTER TABLE users DROP COLUMN confirmation_expires_at;